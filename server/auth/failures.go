@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// 认证失败限制：同一IP在时间窗口内认证失败达到上限后，窗口结束前拒绝其认证请求
+const (
+	MaxAuthFailures    = 10               // 时间窗口内允许的认证失败次数
+	AuthFailureWindow  = 15 * time.Minute // 失败次数的统计窗口
+	maxFailureTracking = 10000            // 最多跟踪的IP数，超出时丢弃最早开始计数的IP
+)
+
+// failureRecord 单个IP的认证失败记录
+type failureRecord struct {
+	count int       // 窗口内的失败次数
+	since time.Time // 窗口开始时间（首次失败时间）
+}
+
+// failureTracker 按IP统计认证失败次数
+type failureTracker struct {
+	records map[string]*failureRecord
+	handler AuthFailureHandler
+	mu      sync.Mutex
+}
+
+// AuthFailureHandler 接口认证失败回调（同一IP在每个统计窗口内只回调首次失败），用于记录登录审计
+type AuthFailureHandler func(ip, userAgent, reason string)
+
+// SetAuthFailureHandler 设置接口认证失败回调
+func (m *Manager) SetAuthFailureHandler(handler AuthFailureHandler) {
+	m.failures.mu.Lock()
+	defer m.failures.mu.Unlock()
+	m.failures.handler = handler
+}
+
+// AuthFailed 记录一次接口认证失败（Bearer访问密钥或Basic认证），IP在当前窗口内首次失败时异步调用回调
+func (m *Manager) AuthFailed(ip, userAgent, reason string) {
+	if !m.RecordAuthFailure(ip) {
+		return
+	}
+	m.failures.mu.Lock()
+	handler := m.failures.handler
+	m.failures.mu.Unlock()
+	if handler != nil {
+		go handler(ip, userAgent, reason)
+	}
+}
+
+// AuthBlocked 判断IP是否因认证失败次数过多被暂时拒绝，返回剩余的等待时间
+func (m *Manager) AuthBlocked(ip string) (bool, time.Duration) {
+	m.failures.mu.Lock()
+	defer m.failures.mu.Unlock()
+
+	record, ok := m.failures.records[ip]
+	if !ok {
+		return false, 0
+	}
+	remaining := AuthFailureWindow - time.Since(record.since)
+	if remaining <= 0 {
+		delete(m.failures.records, ip)
+		return false, 0
+	}
+	return record.count >= MaxAuthFailures, remaining
+}
+
+// RecordAuthFailure 记录一次认证失败，返回是否为该IP在当前窗口内的首次失败（供调用方去重记录日志和审计）
+func (m *Manager) RecordAuthFailure(ip string) bool {
+	m.failures.mu.Lock()
+	defer m.failures.mu.Unlock()
+
+	now := time.Now()
+	if m.failures.records == nil {
+		m.failures.records = make(map[string]*failureRecord)
+	}
+	record, ok := m.failures.records[ip]
+	if ok && now.Sub(record.since) < AuthFailureWindow {
+		record.count++
+		return false
+	}
+	if !ok && len(m.failures.records) >= maxFailureTracking {
+		m.failures.evict(now)
+	}
+	m.failures.records[ip] = &failureRecord{count: 1, since: now}
+	return true
+}
+
+// ClearAuthFailures 认证成功后清除IP的失败记录
+func (m *Manager) ClearAuthFailures(ip string) {
+	m.failures.mu.Lock()
+	defer m.failures.mu.Unlock()
+	delete(m.failures.records, ip)
+}
+
+// evict 清理过期记录，仍超出上限时丢弃窗口开始最早的记录（内部方法，调用方持有锁）
+func (t *failureTracker) evict(now time.Time) {
+	var oldestIP string
+	var oldest time.Time
+	for ip, record := range t.records {
+		if now.Sub(record.since) >= AuthFailureWindow {
+			delete(t.records, ip)
+			continue
+		}
+		if oldestIP == "" || record.since.Before(oldest) {
+			oldestIP, oldest = ip, record.since
+		}
+	}
+	if len(t.records) >= maxFailureTracking && oldestIP != "" {
+		delete(t.records, oldestIP)
+	}
+}
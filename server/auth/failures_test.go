@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestValidateKeyConstantTime(t *testing.T) {
+	m := &Manager{authKey: "secret-key", basicAuthEnabled: true}
+	if !m.ValidateKey("secret-key") {
+		t.Fatal("正确的密钥应验证通过")
+	}
+	for _, key := range []string{"", "secret", "secret-key2", "Secret-key"} {
+		if m.ValidateKey(key) {
+			t.Fatalf("错误的密钥 %q 不应验证通过", key)
+		}
+	}
+
+	header := "Basic " + base64.StdEncoding.EncodeToString([]byte("any:secret-key"))
+	if !m.ValidateBasicAuth(header) {
+		t.Fatal("正确的Basic认证应验证通过")
+	}
+}
+
+func TestAuthFailureLimit(t *testing.T) {
+	m := &Manager{}
+	const ip = "203.0.113.5"
+
+	if !m.RecordAuthFailure(ip) {
+		t.Fatal("首次失败应返回true")
+	}
+	for i := 1; i < MaxAuthFailures; i++ {
+		if blocked, _ := m.AuthBlocked(ip); blocked {
+			t.Fatalf("第%d次失败后不应被拒绝", i)
+		}
+		if m.RecordAuthFailure(ip) {
+			t.Fatal("窗口内的后续失败应返回false")
+		}
+	}
+	blocked, wait := m.AuthBlocked(ip)
+	if !blocked || wait <= 0 || wait > AuthFailureWindow {
+		t.Fatalf("失败次数达到上限后应被拒绝: blocked=%v wait=%v", blocked, wait)
+	}
+	if blocked, _ := m.AuthBlocked("203.0.113.6"); blocked {
+		t.Fatal("其他IP不应受影响")
+	}
+
+	// 窗口结束后自动解除
+	m.failures.records[ip].since = time.Now().Add(-AuthFailureWindow)
+	if blocked, _ := m.AuthBlocked(ip); blocked {
+		t.Fatal("窗口结束后应解除拒绝")
+	}
+
+	m.RecordAuthFailure(ip)
+	m.ClearAuthFailures(ip)
+	if _, ok := m.failures.records[ip]; ok {
+		t.Fatal("认证成功后应清除失败记录")
+	}
+}
+
+func TestAuthFailureTrackingBounded(t *testing.T) {
+	m := &Manager{}
+	for i := 0; i < maxFailureTracking+100; i++ {
+		m.RecordAuthFailure(time.Duration(i).String())
+	}
+	if n := len(m.failures.records); n > maxFailureTracking {
+		t.Fatalf("跟踪的IP数 %d 超出上限 %d", n, maxFailureTracking)
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ExportSessions 将未过期的会话写入w（平滑重启时交给新进程，避免已登录用户被登出）
+func (m *Manager) ExportSessions(w io.Writer) error {
+	now := time.Now()
+	sessions := make([]*Session, 0)
+	m.sessions.Range(func(_, value interface{}) bool {
+		if session, ok := value.(*Session); ok && now.Before(session.ExpiresAt) {
+			sessions = append(sessions, session)
+		}
+		return true
+	})
+	if err := json.NewEncoder(w).Encode(sessions); err != nil {
+		return fmt.Errorf("导出会话失败: %w", err)
+	}
+	return nil
+}
+
+// ImportSessions 读取旧进程导出的会话，返回导入的会话数
+func (m *Manager) ImportSessions(r io.Reader) (int, error) {
+	var sessions []*Session
+	if err := json.NewDecoder(r).Decode(&sessions); err != nil {
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("导入会话失败: %w", err)
+	}
+
+	now := time.Now()
+	count := 0
+	for _, session := range sessions {
+		if session == nil || session.ID == "" || !now.Before(session.ExpiresAt) {
+			continue
+		}
+		m.sessions.Store(session.ID, session)
+		count++
+	}
+	return count, nil
+}
@@ -1,20 +1,44 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
 // Session 会话信息
 type Session struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
-	ExpiresAt time.Time `json:"expiresAt"`
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RememberMe bool      `json:"rememberMe"` // 登录时是否勾选“记住我”（决定会话有效期）
+	LoginIP    string    `json:"loginIp"`    // 登录时的客户端IP
+	IP         string    `json:"ip"`         // 最近一次请求的客户端IP
+	UserAgent  string    `json:"userAgent"`  // 最近一次请求的User-Agent
+	LastSeenAt time.Time `json:"lastSeenAt"` // 最近一次请求时间
+}
+
+// SessionInfo 会话列表中展示的会话信息（只包含会话ID前缀）
+type SessionInfo struct {
+	ID         string    `json:"id"`         // 会话ID前8位
+	CreatedAt  time.Time `json:"createdAt"`  // 登录时间
+	ExpiresAt  time.Time `json:"expiresAt"`  // 过期时间
+	RememberMe bool      `json:"rememberMe"` // 是否为“记住我”长期会话
+	LoginIP    string    `json:"loginIp"`    // 登录时的客户端IP
+	IP         string    `json:"ip"`         // 最近一次请求的客户端IP
+	UserAgent  string    `json:"userAgent"`  // 最近一次请求的User-Agent
+	LastSeenAt time.Time `json:"lastSeenAt"` // 最近一次请求时间
+	Current    bool      `json:"current"`    // 是否为当前请求所用的会话
 }
 
 // SessionEventType 会话事件类型
@@ -37,12 +61,16 @@ type SessionEventHandler func(event SessionEvent)
 
 // Manager 认证管理器
 type Manager struct {
-	authKey        string
-	sessions       sync.Map
-	expireDuration time.Duration
-	authFilePath   string
-	eventHandlers  []SessionEventHandler
-	eventMutex     sync.RWMutex
+	authKey             string
+	sessions            sync.Map
+	expireDuration      time.Duration
+	shortExpireDuration time.Duration   // 未勾选“记住我”时的会话有效期
+	trustedProxies      *TrustedProxies // 可信反向代理（启用代理认证时非nil）
+	basicAuthEnabled    bool            // 是否允许HTTP Basic认证
+	authFilePath        string
+	failures            failureTracker // 按IP统计的认证失败次数
+	eventHandlers       []SessionEventHandler
+	eventMutex          sync.RWMutex
 }
 
 // NewManager 创建认证管理器
@@ -125,26 +153,71 @@ func (m *Manager) loadAuthKey() (string, error) {
 	return string(data), nil
 }
 
-// ValidateKey 验证密钥
+// ValidateKey 验证密钥（常量时间比较，避免通过响应时间逐位猜测密钥）
 func (m *Manager) ValidateKey(key string) bool {
-	return key == m.authKey
+	return subtle.ConstantTimeCompare([]byte(key), []byte(m.authKey)) == 1
+}
+
+// SetBasicAuthEnabled 设置是否允许以HTTP Basic认证（任意用户名 + 访问密钥）调用API
+func (m *Manager) SetBasicAuthEnabled(enabled bool) {
+	m.basicAuthEnabled = enabled
+}
+
+// BasicAuthEnabled 是否允许HTTP Basic认证
+func (m *Manager) BasicAuthEnabled() bool {
+	return m.basicAuthEnabled
+}
+
+// ValidateBasicAuth 验证HTTP Basic认证请求头，未启用Basic认证时始终返回false
+func (m *Manager) ValidateBasicAuth(header string) bool {
+	if !m.basicAuthEnabled || !strings.HasPrefix(header, "Basic ") {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+	if err != nil {
+		return false
+	}
+	_, password, ok := strings.Cut(string(decoded), ":")
+	return ok && password != "" && m.ValidateKey(password)
 }
 
-// CreateSession 创建会话
-func (m *Manager) CreateSession() (*Session, error) {
+// feedTokenContext 订阅令牌的派生上下文
+const feedTokenContext = "cccmu-feed"
+
+// FeedToken 获取订阅令牌（由访问密钥派生的只读令牌，用于RSS、iCal等无法携带会话的订阅地址；更换访问密钥后自动失效）
+func (m *Manager) FeedToken() string {
+	mac := hmac.New(sha256.New, []byte(m.authKey))
+	mac.Write([]byte(feedTokenContext))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// ValidateFeedToken 验证订阅令牌
+func (m *Manager) ValidateFeedToken(token string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(m.FeedToken()))
+}
+
+// CreateSession 创建会话，记录登录时的客户端IP和User-Agent
+// rememberMe为true时使用长期有效期，否则使用短期有效期
+func (m *Manager) CreateSession(ip, userAgent string, rememberMe bool) (*Session, error) {
 	sessionID, err := m.generateRandomKey(64)
 	if err != nil {
 		return nil, fmt.Errorf("生成会话ID失败: %v", err)
 	}
 
+	now := time.Now()
 	session := &Session{
-		ID:        sessionID,
-		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(m.expireDuration),
+		ID:         sessionID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(m.GetExpireDuration(rememberMe)),
+		RememberMe: rememberMe,
+		LoginIP:    ip,
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
 	}
 
 	m.sessions.Store(sessionID, session)
-	log.Printf("创建新会话: %s, 过期时间: %s", sessionID[:8]+"...", session.ExpiresAt.Format("2006-01-02 15:04:05"))
+	log.Printf("创建新会话: %s, 过期时间: %s, IP: %s", sessionID[:8]+"...", session.ExpiresAt.Format("2006-01-02 15:04:05"), ip)
 
 	return session, nil
 }
@@ -180,6 +253,54 @@ func (m *Manager) ValidateSession(sessionID string) (*Session, bool) {
 	return session, true
 }
 
+// TouchSession 记录会话最近一次请求的客户端IP、User-Agent和时间
+// 以替换副本的方式更新，已取出的会话对象不会被并发修改
+func (m *Manager) TouchSession(sessionID, ip, userAgent string) {
+	value, ok := m.sessions.Load(sessionID)
+	if !ok {
+		return
+	}
+	session, ok := value.(*Session)
+	if !ok {
+		return
+	}
+
+	updated := *session
+	updated.IP = ip
+	updated.UserAgent = userAgent
+	updated.LastSeenAt = time.Now()
+	m.sessions.CompareAndSwap(sessionID, session, &updated)
+}
+
+// ListSessions 列出未过期的会话（按最近请求时间倒序），currentID为当前请求所用的会话ID
+func (m *Manager) ListSessions(currentID string) []SessionInfo {
+	now := time.Now()
+	sessions := make([]SessionInfo, 0)
+	m.sessions.Range(func(key, value interface{}) bool {
+		session, ok := value.(*Session)
+		if !ok || now.After(session.ExpiresAt) {
+			return true
+		}
+		sessions = append(sessions, SessionInfo{
+			ID:         session.ID[:8],
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			RememberMe: session.RememberMe,
+			LoginIP:    session.LoginIP,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentID,
+		})
+		return true
+	})
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions
+}
+
 // DeleteSession 删除会话
 func (m *Manager) DeleteSession(sessionID string) {
 	m.sessions.Delete(sessionID)
@@ -191,9 +312,17 @@ func (m *Manager) DeleteSession(sessionID string) {
 	})
 }
 
-// GetExpireDuration 获取过期时间
-func (m *Manager) GetExpireDuration() time.Duration {
-	return m.expireDuration
+// SetShortExpireDuration 设置未勾选“记住我”时的会话有效期（未设置或长于长期有效期时使用长期有效期）
+func (m *Manager) SetShortExpireDuration(duration time.Duration) {
+	m.shortExpireDuration = duration
+}
+
+// GetExpireDuration 获取过期时间，rememberMe为false时返回短期有效期
+func (m *Manager) GetExpireDuration(rememberMe bool) time.Duration {
+	if rememberMe || m.shortExpireDuration <= 0 || m.shortExpireDuration > m.expireDuration {
+		return m.expireDuration
+	}
+	return m.shortExpireDuration
 }
 
 // AddSessionEventHandler 添加会话事件处理器
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ProxyUserHeaders 反向代理（如Authelia、oauth2-proxy）传递已认证用户名的请求头，按顺序取第一个非空值
+var ProxyUserHeaders = []string{"Remote-User", "X-Auth-Request-User"}
+
+// TrustedProxies 可信反向代理地址，只有来自这些地址的请求才信任代理传递的用户名
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// ParseTrustedProxies 解析逗号分隔的IP或CIDR列表（如 "127.0.0.1,10.0.0.0/8"）
+func ParseTrustedProxies(value string) (*TrustedProxies, error) {
+	proxies := &TrustedProxies{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的代理地址: %s", item)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			item = fmt.Sprintf("%s/%d", ip.String(), bits)
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的代理地址: %s", item)
+		}
+		proxies.nets = append(proxies.nets, ipNet)
+	}
+	if len(proxies.nets) == 0 {
+		return nil, fmt.Errorf("可信代理地址不能为空")
+	}
+	return proxies, nil
+}
+
+// Contains 判断IP是否属于可信代理
+func (p *TrustedProxies) Contains(ip net.IP) bool {
+	if p == nil || ip == nil {
+		return false
+	}
+	for _, ipNet := range p.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回可信代理列表（用于启动日志）
+func (p *TrustedProxies) String() string {
+	items := make([]string, len(p.nets))
+	for i, ipNet := range p.nets {
+		items[i] = ipNet.String()
+	}
+	return strings.Join(items, ", ")
+}
+
+// SetTrustedProxies 启用反向代理认证模式：来自可信代理且携带用户名请求头的请求无需登录
+func (m *Manager) SetTrustedProxies(proxies *TrustedProxies) {
+	m.trustedProxies = proxies
+}
+
+// ProxyUser 获取可信反向代理传递的已认证用户名，未启用代理认证、请求不是来自可信代理或未携带用户名时返回空字符串
+// remoteIP须为TCP连接的对端地址，不能取自可伪造的X-Forwarded-For等请求头
+func (m *Manager) ProxyUser(remoteIP net.IP, header func(name string) string) string {
+	if !m.trustedProxies.Contains(remoteIP) {
+		return ""
+	}
+	for _, name := range ProxyUserHeaders {
+		if user := strings.TrimSpace(header(name)); user != "" {
+			return user
+		}
+	}
+	return ""
+}
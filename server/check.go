@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/secrets"
+)
+
+// checkStatus 自检结果状态
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+// checkResult 单项自检结果
+type checkResult struct {
+	name   string
+	status checkStatus
+	detail string
+}
+
+// runCheck 执行启动自检并打印报告，全部通过（允许警告）时返回true
+func runCheck(dbPath, port, masterKey string) bool {
+	fmt.Println("🔍 启动自检")
+
+	var results []checkResult
+	results = append(results, checkTimezone())
+	results = append(results, checkPort(port))
+
+	dbResult, cookie := checkDatabase(dbPath, masterKey)
+	results = append(results, dbResult)
+	results = append(results, checkUpstream())
+	results = append(results, checkCookie(cookie, dbResult.status == checkFail))
+
+	passed := true
+	for _, r := range results {
+		icon := "✅"
+		switch r.status {
+		case checkWarn:
+			icon = "⚠️ "
+		case checkFail:
+			icon = "❌"
+			passed = false
+		}
+		fmt.Printf("%s %s: %s\n", icon, r.name, r.detail)
+	}
+
+	if passed {
+		fmt.Println("✅ 自检通过")
+	} else {
+		fmt.Println("❌ 自检未通过")
+	}
+	return passed
+}
+
+// checkTimezone 检查时区配置（每日统计和自动重置均按本地时间计算）
+func checkTimezone() checkResult {
+	result := checkResult{name: "时区"}
+
+	if tz := os.Getenv("TZ"); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			result.status = checkFail
+			result.detail = fmt.Sprintf("TZ=%s 无效: %v", tz, err)
+			return result
+		}
+	}
+
+	result.detail = fmt.Sprintf("%s (UTC%s)", time.Local.String(), time.Now().Format("-07:00"))
+	return result
+}
+
+// checkPort 检查服务端口是否可用
+func checkPort(port string) checkResult {
+	serverPort := getPort(port)
+	result := checkResult{name: "端口"}
+
+	listener, err := net.Listen("tcp", serverPort)
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("%s 不可用: %v", serverPort, err)
+		return result
+	}
+	listener.Close()
+
+	result.detail = fmt.Sprintf("%s 可用", serverPort)
+	return result
+}
+
+// checkDatabase 检查数据库可访问（未被其他进程锁定、配置可读取），返回已存储的Cookie
+func checkDatabase(dbPath, masterKey string) (checkResult, string) {
+	result := checkResult{name: "数据库"}
+
+	var secretBox *secrets.Box
+	if masterKey != "" {
+		box, err := secrets.NewBox(masterKey)
+		if err != nil {
+			result.status = checkFail
+			result.detail = fmt.Sprintf("初始化主密钥失败: %v", err)
+			return result, ""
+		}
+		secretBox = box
+	}
+
+	db, err := database.NewBadgerDB(dbPath)
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("%v（数据库可能已被运行中的服务锁定）", err)
+		return result, ""
+	}
+	defer db.Close()
+	db.SetSecretBox(secretBox)
+
+	config, err := db.GetConfig()
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("读取配置失败（如已启用加密，请检查主密钥）: %v", err)
+		return result, ""
+	}
+
+	result.detail = fmt.Sprintf("%s 可读写", dbPath)
+	// 上游检查使用与服务相同的连接和主机名解析设置
+	client.SetTransportConfig(config.HTTPTransport)
+	return result, config.Cookie
+}
+
+// checkUpstream 检查上游API是否可达
+func checkUpstream() checkResult {
+	result := checkResult{name: "上游API"}
+
+	status, latency, err := client.Probe(10 * time.Second)
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("%s 不可达: %v", client.BaseURL(), err)
+		return result
+	}
+
+	result.detail = fmt.Sprintf("%s 可达（HTTP %d，%dms）", client.BaseURL(), status, latency.Milliseconds())
+	return result
+}
+
+// checkCookie 检查已配置Cookie的有效性
+func checkCookie(cookie string, dbFailed bool) checkResult {
+	result := checkResult{name: "Cookie"}
+
+	if dbFailed {
+		result.status = checkWarn
+		result.detail = "数据库不可用，跳过检查"
+		return result
+	}
+
+	if cookie == "" {
+		result.status = checkWarn
+		result.detail = "未配置"
+		return result
+	}
+
+	balance, err := client.NewClaudeAPIClient(cookie).FetchCreditBalance()
+	if err != nil {
+		result.status = checkFail
+		result.detail = fmt.Sprintf("验证失败: %v", err)
+		return result
+	}
+
+	result.detail = fmt.Sprintf("有效（剩余积分: %d）", balance.Remaining)
+	return result
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// cliAuthKeyFile 访问密钥文件路径（与认证管理器保持一致）
+const cliAuthKeyFile = "./data/auth"
+
+// cliCommand 命令行子命令
+type cliCommand struct {
+	usage string
+	run   func(c *cliClient, opts cliOptions) error
+}
+
+// cliOptions 子命令参数
+type cliOptions struct {
+	days   int    // 导出天数
+	format string // 导出格式
+}
+
+// cliCommands 支持的子命令，通过本地HTTP API与运行中的服务交互
+var cliCommands = map[string]cliCommand{
+	"status":  {usage: "查看监控任务、维护模式和今日重置状态", run: runStatusCommand},
+	"balance": {usage: "查看积分余额", run: runBalanceCommand},
+	"reset":   {usage: "重置积分（每日一次）", run: runResetCommand},
+	"export":  {usage: "导出每日积分统计（--days 天数，--format csv|json）", run: runExportCommand},
+	"tui":     {usage: "终端实时监控积分余额、用量趋势和最近事件", run: runTUICommand},
+}
+
+// isCLICommand 判断参数是否为子命令
+func isCLICommand(arg string) bool {
+	_, ok := cliCommands[arg]
+	return ok
+}
+
+// runCLI 执行子命令，返回进程退出码
+func runCLI(name string, args []string) int {
+	command := cliCommands[name]
+
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	server := flags.StringP("server", "s", "", "服务地址（默认 http://127.0.0.1 加 PORT 环境变量端口、data/port 记录的端口或8080）")
+	key := flags.StringP("key", "k", "", "访问密钥（默认读取 ACCESS_KEY 环境变量或 "+cliAuthKeyFile+" 文件）")
+	days := flags.Int("days", models.DailyUsageRetentionDays, "导出天数（仅 export）")
+	format := flags.String("format", "csv", "导出格式 csv|json（仅 export）")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: cccmu %s [参数]\n%s\n\n", name, command.usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	c, err := newCLIClient(*server, *key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if err := command.run(c, cliOptions{days: *days, format: *format}); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// cliClient 本地API客户端，使用访问密钥直接认证
+type cliClient struct {
+	baseURL string
+	key     string
+	http    *http.Client
+}
+
+// newCLIClient 创建本地API客户端
+func newCLIClient(server, key string) (*cliClient, error) {
+	if server == "" {
+		port := ""
+		if os.Getenv("PORT") == "" {
+			port = readPortFile()
+		}
+		server = "http://127.0.0.1" + getPort(port)
+	}
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+
+	if key == "" {
+		key = os.Getenv("ACCESS_KEY")
+	}
+	if key == "" {
+		data, err := os.ReadFile(cliAuthKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取访问密钥失败（请在数据目录下执行或使用 --key 指定）: %w", err)
+		}
+		key = strings.TrimSpace(string(data))
+	}
+
+	return &cliClient{
+		baseURL: strings.TrimRight(server, "/") + apiV1Prefix,
+		key:     key,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// do 调用API并将响应中的data字段解析到out
+func (c *cliClient) do(method, path string, out interface{}) (string, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("连接服务失败（服务是否已启动？）: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp models.ErrorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			if errResp.Error != "" {
+				return "", fmt.Errorf("%s: %s", errResp.Message, errResp.Error)
+			}
+			return "", fmt.Errorf("%s", errResp.Message)
+		}
+		return "", fmt.Errorf("请求失败: HTTP %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	if out != nil && len(apiResp.Data) > 0 {
+		if err := json.Unmarshal(apiResp.Data, out); err != nil {
+			return "", fmt.Errorf("解析响应数据失败: %w", err)
+		}
+	}
+	return apiResp.Message, nil
+}
+
+// runStatusCommand 查看运行状态
+func runStatusCommand(c *cliClient, _ cliOptions) error {
+	var status struct {
+		Running bool `json:"running"`
+	}
+	if _, err := c.do(http.MethodGet, "/control/status", &status); err != nil {
+		return err
+	}
+
+	var config models.UserConfigResponse
+	if _, err := c.do(http.MethodGet, "/config", &config); err != nil {
+		return err
+	}
+
+	var maintenance models.MaintenanceStatus
+	if _, err := c.do(http.MethodGet, "/admin/maintenance", &maintenance); err != nil {
+		return err
+	}
+
+	fmt.Printf("版本:       %s\n", config.Version.Version)
+	fmt.Printf("监控任务:   %s\n", onOff(status.Running, "运行中", "已停止"))
+	fmt.Printf("Cookie:     %s\n", onOff(config.Cookie, "已配置", "未配置"))
+	fmt.Printf("订阅等级:   %s\n", valueOr(config.Plan, "-"))
+	fmt.Printf("今日重置:   %s\n", onOff(config.DailyResetUsed, "已使用", "未使用"))
+	fmt.Printf("自动调度:   %s\n", onOff(config.AutoSchedule.Enabled, "已启用", "未启用"))
+	fmt.Printf("自动重置:   %s\n", onOff(config.AutoReset.Enabled, "已启用", "未启用"))
+	if maintenance.Enabled {
+		expires := "-"
+		if maintenance.ExpiresAt != nil {
+			expires = maintenance.ExpiresAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("维护模式:   维护中（%s，至 %s）\n", valueOr(maintenance.Reason, "无原因"), expires)
+	} else {
+		fmt.Printf("维护模式:   未开启\n")
+	}
+	return nil
+}
+
+// runBalanceCommand 查看积分余额
+func runBalanceCommand(c *cliClient, _ cliOptions) error {
+	var balance *models.CreditBalance
+	if _, err := c.do(http.MethodGet, "/balance", &balance); err != nil {
+		return err
+	}
+	if balance == nil {
+		return fmt.Errorf("暂无积分余额数据（监控未启动或尚未获取）")
+	}
+
+	fmt.Printf("剩余积分:   %d\n", balance.Remaining)
+	fmt.Printf("订阅等级:   %s\n", valueOr(balance.Plan, "-"))
+	fmt.Printf("更新时间:   %s\n", balance.UpdatedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// runResetCommand 重置积分
+func runResetCommand(c *cliClient, _ cliOptions) error {
+	message, err := c.do(http.MethodPost, "/balance/reset", nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %s\n", message)
+	return nil
+}
+
+// runExportCommand 导出每日积分统计到标准输出
+func runExportCommand(c *cliClient, opts cliOptions) error {
+	if opts.format != "csv" && opts.format != "json" {
+		return fmt.Errorf("不支持的导出格式: %s（可选 csv、json）", opts.format)
+	}
+
+	var usageList models.DailyUsageList
+	if _, err := c.do(http.MethodGet, "/history/export?days="+strconv.Itoa(opts.days), &usageList); err != nil {
+		return err
+	}
+
+	if opts.format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(usageList)
+	}
+
+	// CSV：日期、总积分，以及每个模型一列
+	modelNames := usageList.GetAllModelList()
+	sort.Strings(modelNames)
+
+	writer := csv.NewWriter(os.Stdout)
+	header := append([]string{"date", "totalCredits"}, modelNames...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, usage := range usageList {
+		row := []string{usage.Date, strconv.Itoa(usage.TotalCredits)}
+		for _, model := range modelNames {
+			row = append(row, strconv.Itoa(usage.GetModelCredits(model)))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// printCLIUsage 打印子命令列表
+func printCLIUsage() {
+	names := make([]string, 0, len(cliCommands))
+	for name := range cliCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "\n子命令（与运行中的服务交互）:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, cliCommands[name].usage)
+	}
+}
+
+// onOff 根据布尔值选择显示文本
+func onOff(value bool, on, off string) string {
+	if value {
+		return on
+	}
+	return off
+}
+
+// valueOr 字符串为空时返回默认值
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -1,8 +1,9 @@
 package client
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -10,6 +11,22 @@ import (
 	"github.com/leafney/cccmu/server/utils"
 )
 
+// DefaultBaseURL 上游API默认地址
+const DefaultBaseURL = "https://www.aicodemirror.com"
+
+// baseURL 当前使用的上游API地址（开发模式下可指向内置模拟服务）
+var baseURL = DefaultBaseURL
+
+// SetBaseURL 设置上游API地址，需在创建客户端前调用
+func SetBaseURL(url string) {
+	baseURL = strings.TrimRight(url, "/")
+}
+
+// BaseURL 获取当前使用的上游API地址
+func BaseURL() string {
+	return baseURL
+}
+
 // CookieUpdateCallback Cookie更新回调函数类型
 type CookieUpdateCallback func()
 
@@ -24,12 +41,16 @@ type ClaudeAPIClient struct {
 // NewClaudeAPIClient 创建新的Claude API客户端
 func NewClaudeAPIClient(cookie string) *ClaudeAPIClient {
 	client := resty.New().
+		SetTransport(transport).
 		SetTimeout(30 * time.Second).
 		SetRetryCount(3).
 		SetRetryWaitTime(5 * time.Second).
 		SetRetryMaxWaitTime(20 * time.Second).
 		SetDebug(false) // 开启调试模式
 
+	trackLatency(client)
+	limitOutbound(client)
+
 	// 创建缓存管理器
 	cache := NewAPICache()
 
@@ -98,46 +119,57 @@ func matchesUsageFilter(data ClaudeUsageData) bool {
 
 // FetchUsageData 获取积分使用数据
 func (c *ClaudeAPIClient) FetchUsageData() ([]models.UsageData, error) {
+	return c.FetchUsageDataContext(context.Background())
+}
+
+// FetchUsageDataContext 获取积分使用数据，ctx取消或超时时中止请求（含重试和频率限制的等待）
+func (c *ClaudeAPIClient) FetchUsageDataContext(ctx context.Context) ([]models.UsageData, error) {
 	// 检查缓存
 	if cachedData, cachedErr, found := c.cache.GetCachedUsageData(); found {
 		return cachedData, cachedErr
 	}
 
 	if c.cookie == "" {
-		err := fmt.Errorf("Cookie为空")
-		c.cache.SetCachedUsageData(nil, err)
-		return nil, err
+		c.cache.SetCachedUsageData(nil, ErrCookieMissing)
+		return nil, ErrCookieMissing
+	}
+
+	if provider != nil {
+		if err := waitOutbound(ctx, "plugin:usage"); err != nil {
+			return nil, err
+		}
+		data, err := provider.FetchUsageData(ctx, c.cookie)
+		if err == nil {
+			c.notifySuccessfulRequest()
+		}
+		if err == nil || cacheableError(ctx, err) {
+			c.cache.SetCachedUsageData(data, err)
+		}
+		return data, err
 	}
 
 	utils.Logf("发起API请求: FetchUsageData - 请求使用量数据")
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetHeader("Cookie", c.cookie).
-		SetHeader("Referer", "https://www.aicodemirror.com/dashboard/usage").
+		SetHeader("Referer", baseURL+"/dashboard/usage").
 		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36").
 		SetHeader("Accept", "application/json, text/plain, */*").
-		Get("https://www.aicodemirror.com/api/user/usage")
-
-	if err != nil {
-		apiErr := fmt.Errorf("API请求失败: %w", err)
-		c.cache.SetCachedUsageData(nil, apiErr)
-		return nil, apiErr
-	}
-
-	if resp.StatusCode() == 401 {
-		// 401错误不缓存，直接返回
-		return nil, fmt.Errorf("Cookie无效或已过期")
-	}
+		Get(baseURL + "/api/user/usage")
 
-	if resp.StatusCode() != 200 {
-		apiErr := fmt.Errorf("API返回错误: %d %s", resp.StatusCode(), resp.Status())
-		c.cache.SetCachedUsageData(nil, apiErr)
-		return nil, apiErr
+	if err := checkResponse("获取使用数据", resp, err); err != nil {
+		// Cookie失效和调用方取消、超时的错误不缓存，可立即重试
+		if cacheableError(ctx, err) {
+			c.cache.SetCachedUsageData(nil, err)
+		}
+		return nil, err
 	}
 
-	var apiResp []ClaudeUsageData
-	if err := json.Unmarshal(resp.Body(), &apiResp); err != nil {
-		parseErr := fmt.Errorf("解析响应失败: %w", err)
+	// 按字段尽量解析，上游增减字段或改变类型时记录警告而不是整体失败
+	apiResp, err := decodeUsageResponse("/api/user/usage", resp.Body())
+	if err != nil {
+		parseErr := parseError("获取使用数据", err)
 		c.cache.SetCachedUsageData(nil, parseErr)
 		return nil, parseErr
 	}
@@ -167,50 +199,60 @@ type ClaudeCreditsResponse struct {
 
 // FetchCreditBalance 获取积分余额
 func (c *ClaudeAPIClient) FetchCreditBalance() (*models.CreditBalance, error) {
+	return c.FetchCreditBalanceContext(context.Background())
+}
+
+// FetchCreditBalanceContext 获取积分余额，ctx取消或超时时中止请求（含重试和频率限制的等待）
+func (c *ClaudeAPIClient) FetchCreditBalanceContext(ctx context.Context) (*models.CreditBalance, error) {
 	// 检查缓存
 	if cachedData, cachedErr, found := c.cache.GetCachedBalance(); found {
 		return cachedData, cachedErr
 	}
 
 	if c.cookie == "" {
-		err := fmt.Errorf("Cookie为空")
-		c.cache.SetCachedBalance(nil, err)
-		return nil, err
+		c.cache.SetCachedBalance(nil, ErrCookieMissing)
+		return nil, ErrCookieMissing
+	}
+
+	if provider != nil {
+		if err := waitOutbound(ctx, "plugin:credits"); err != nil {
+			return nil, err
+		}
+		balance, err := provider.FetchCreditBalance(ctx, c.cookie)
+		if err == nil {
+			c.notifySuccessfulRequest()
+		}
+		if err == nil || cacheableError(ctx, err) {
+			c.cache.SetCachedBalance(balance, err)
+		}
+		return balance, err
 	}
 
 	utils.Logf("发起API请求: FetchCreditBalance - 请求积分余额")
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetHeader("Cookie", c.cookie).
-		SetHeader("Referer", "https://www.aicodemirror.com/dashboard/usage").
+		SetHeader("Referer", baseURL+"/dashboard/usage").
 		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36").
 		SetHeader("Accept", "application/json, text/plain, */*").
-		Get("https://www.aicodemirror.com/api/user/credits")
+		Get(baseURL + "/api/user/credits")
 
-	if err != nil {
-		apiErr := fmt.Errorf("获取积分余额请求失败: %w", err)
-		c.cache.SetCachedBalance(nil, apiErr)
-		return nil, apiErr
-	}
-
-	if resp.StatusCode() == 401 {
-		// 401错误不缓存，直接返回
-		return nil, fmt.Errorf("Cookie无效或已过期")
-	}
-
-	if resp.StatusCode() != 200 {
-		apiErr := fmt.Errorf("获取积分余额失败: %d %s", resp.StatusCode(), resp.Status())
-		c.cache.SetCachedBalance(nil, apiErr)
-		return nil, apiErr
+	if err := checkResponse("获取积分余额", resp, err); err != nil {
+		// Cookie失效和调用方取消、超时的错误不缓存，可立即重试
+		if cacheableError(ctx, err) {
+			c.cache.SetCachedBalance(nil, err)
+		}
+		return nil, err
 	}
 
 	// 添加调试日志（可控制）
 	// utils.Logf("积分余额API原始响应: %s", string(resp.Body()))
 
 	// 解析API返回的数据格式
-	var creditsResp ClaudeCreditsResponse
-	if err := json.Unmarshal(resp.Body(), &creditsResp); err != nil {
-		parseErr := fmt.Errorf("解析积分数据失败: %w", err)
+	creditsResp, err := decodeCreditsResponse("/api/user/credits", resp.Body())
+	if err != nil {
+		parseErr := parseError("获取积分余额", err)
 		c.cache.SetCachedBalance(nil, parseErr)
 		return nil, parseErr
 	}
@@ -254,7 +296,8 @@ func (c *ClaudeAPIClient) convertToUsageData(apiData []ClaudeUsageData) []models
 			ID:          data.ID,
 			CreditsUsed: data.CreditsUsed,
 			CreatedAt:   createdAt,
-			Model:       data.Model,
+			Model:       models.NormalizeModel(data.Model),
+			StatusCode:  data.StatusCode,
 		}
 
 		usageData = append(usageData, usage)
@@ -276,42 +319,45 @@ type ClaudeResetCreditsResponse struct {
 
 // ResetCredits 重置积分
 func (c *ClaudeAPIClient) ResetCredits() (bool, string, error) {
+	return c.ResetCreditsContext(context.Background())
+}
+
+// ResetCreditsContext 重置积分，ctx取消或超时时中止请求（含重试和频率限制的等待）
+func (c *ClaudeAPIClient) ResetCreditsContext(ctx context.Context) (bool, string, error) {
 	if c.cookie == "" {
-		return false, "", fmt.Errorf("Cookie为空")
+		return false, "", ErrCookieMissing
+	}
+
+	if provider != nil {
+		if err := waitOutbound(ctx, "plugin:reset"); err != nil {
+			return false, "", err
+		}
+		success, info, err := provider.ResetCredits(ctx, c.cookie)
+		if err == nil {
+			c.notifySuccessfulRequest()
+		}
+		return success, info, err
 	}
 
 	resp, err := c.client.R().
+		SetContext(ctx).
 		SetHeader("Cookie", c.cookie).
-		SetHeader("Referer", "https://www.aicodemirror.com/dashboard").
+		SetHeader("Referer", baseURL+"/dashboard").
 		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36").
 		SetHeader("Accept", "application/json, text/plain, */*").
 		SetHeader("Content-Type", "application/json").
-		Post("https://www.aicodemirror.com/api/user/credit-reset")
-
-	if err != nil {
-		return false, "", fmt.Errorf("HTTP请求失败: %w", err)
-	}
+		Post(baseURL + "/api/user/credit-reset")
 
-	if resp.StatusCode() == 401 {
-		return false, "", fmt.Errorf("Cookie无效或已过期")
+	// 400表示今日已重置过，也视为成功状态
+	if err := checkResponse("重置积分", resp, err, 400); err != nil {
+		return false, "", err
 	}
 
 	// 通知成功请求，更新Cookie验证时间戳
 	c.notifySuccessfulRequest()
 
-	// 处理不同状态码
-	switch resp.StatusCode() {
-	case 200:
-		// 重置成功
-		resetInfo := fmt.Sprintf("重置成功，API响应: %s", string(resp.Body()))
-		return true, resetInfo, nil
-
-	case 400:
-		// 今日已重置过，也视为成功状态
-		resetInfo := "今日已重置过积分，重置状态有效"
-		return true, resetInfo, nil
-
-	default:
-		return false, "", fmt.Errorf("HTTP状态码错误: %d, 响应: %s", resp.StatusCode(), string(resp.Body()))
+	if resp.StatusCode() == 400 {
+		return true, "今日已重置过积分，重置状态有效", nil
 	}
+	return true, fmt.Sprintf("重置成功，API响应: %s", string(resp.Body())), nil
 }
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultRequiredCookies 默认上游站点的登录会话Cookie，"|"分隔的名称满足其一即可
+// （HTTPS下带__Secure-前缀，会话较大时拆分为.0、.1等分片）
+var DefaultRequiredCookies = []string{
+	"__Secure-next-auth.session-token|__Secure-next-auth.session-token.0|next-auth.session-token|next-auth.session-token.0",
+}
+
+// DisableRequiredCookies 配置为该值时不校验Cookie名称
+const DisableRequiredCookies = "-"
+
+// requiredCookies 上游登录所需的Cookie名称，设置Cookie时校验是否齐全
+// 未配置时使用默认上游站点的DefaultRequiredCookies，指向其他地址或使用插件数据来源时不校验
+var (
+	requiredCookies    []string
+	requiredCookiesSet bool
+)
+
+// SetRequiredCookies 设置上游登录所需的Cookie名称（逗号分隔的配置拆分后传入，忽略空白项）
+// 全部为空白时恢复默认，仅包含DisableRequiredCookies时关闭校验
+func SetRequiredCookies(names []string) {
+	requiredCookies = nil
+	requiredCookiesSet = false
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case DisableRequiredCookies:
+			requiredCookiesSet = true
+		default:
+			requiredCookies = append(requiredCookies, name)
+			requiredCookiesSet = true
+		}
+	}
+}
+
+// RequiredCookies 获取上游登录所需的Cookie名称，每项可用"|"分隔多个可选名称
+func RequiredCookies() []string {
+	if requiredCookiesSet {
+		return requiredCookies
+	}
+	if provider == nil && baseURL == DefaultBaseURL {
+		return DefaultRequiredCookies
+	}
+	return nil
+}
+
+// CookieHost 设置Cookie时用于过滤其他域名Cookie的主机名，使用插件数据来源时返回空（不按域名过滤）
+func CookieHost() string {
+	if provider != nil {
+		return ""
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
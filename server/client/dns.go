@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// DNS解析参数
+const (
+	dohTimeout     = 5 * time.Second // DoH查询超时
+	dnsMinCacheTTL = time.Minute     // 解析结果最短缓存时长（DoH返回的TTL过短时使用）
+)
+
+// dohClient DoH查询使用的客户端，不经过上游连接池，避免查询自身又走自定义解析
+var dohClient = &http.Client{Timeout: dohTimeout}
+
+// upstreamDialer 按配置解析上游主机名后建立连接：优先使用指定的IP，其次使用自定义DNS服务器
+type upstreamDialer struct {
+	config   models.HTTPTransportConfig
+	dialer   *net.Dialer
+	resolver *net.Resolver // 自定义UDP DNS服务器（使用DoH或未设置时为nil）
+
+	mu    sync.Mutex
+	cache map[string]dnsCacheEntry // DoH解析结果缓存
+}
+
+// dnsCacheEntry DoH解析结果
+type dnsCacheEntry struct {
+	ips     []string
+	expires time.Time
+}
+
+// newUpstreamDialer 创建按配置解析主机名的拨号器，未配置指定IP和DNS服务器时返回nil（使用默认解析）
+func newUpstreamDialer(config models.HTTPTransportConfig) *upstreamDialer {
+	if len(config.Hosts) == 0 && config.DNSServer == "" {
+		return nil
+	}
+	d := &upstreamDialer{
+		config: config,
+		dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		cache:  make(map[string]dnsCacheEntry),
+	}
+	if config.DNSServer != "" && !config.IsDoH() {
+		server := config.GetDNSServerAddr()
+		d.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return d.dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+	return d
+}
+
+// DialContext 解析主机名并依次尝试各IP，返回第一个成功的连接
+func (d *upstreamDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	ips := d.config.LookupHosts(host)
+	if len(ips) == 0 && d.config.DNSServer != "" {
+		if ips, err = d.lookup(ctx, host); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败（DNS服务器 %s）: %w", host, d.config.DNSServer, err)
+		}
+	}
+	if len(ips) == 0 {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// lookup 通过自定义DNS服务器解析主机名
+func (d *upstreamDialer) lookup(ctx context.Context, host string) ([]string, error) {
+	if d.resolver != nil {
+		return d.resolver.LookupHost(ctx, host)
+	}
+
+	d.mu.Lock()
+	entry, ok := d.cache[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	ips, ttl, err := lookupDoH(ctx, d.config.DNSServer, host)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.cache[host] = dnsCacheEntry{ips: ips, expires: time.Now().Add(max(ttl, dnsMinCacheTTL))}
+	d.mu.Unlock()
+	utils.Logf("[上游连接] DoH解析 %s -> %v", host, ips)
+	return ips, nil
+}
+
+// dohResponse DoH JSON接口响应（application/dns-json）
+type dohResponse struct {
+	Status int `json:"Status"`
+	Answer []struct {
+		Type int    `json:"type"`
+		TTL  int    `json:"TTL"`
+		Data string `json:"data"`
+	} `json:"Answer"`
+}
+
+// lookupDoH 通过DoH JSON接口查询主机名的IPv4地址，返回地址列表和最短TTL
+func lookupDoH(ctx context.Context, server, host string) ([]string, time.Duration, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := u.Query()
+	query.Set("name", host)
+	query.Set("type", "A")
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/dns-json")
+	resp, err := dohClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("DoH服务返回HTTP %d", resp.StatusCode)
+	}
+
+	var result dohResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("DoH响应解析失败: %w", err)
+	}
+	if result.Status != 0 {
+		return nil, 0, fmt.Errorf("DNS查询失败，响应码 %d", result.Status)
+	}
+
+	var ips []string
+	var ttl time.Duration
+	for _, answer := range result.Answer {
+		if answer.Type != 1 || net.ParseIP(answer.Data) == nil { // 只取A记录，跳过CNAME
+			continue
+		}
+		ips = append(ips, answer.Data)
+		if answerTTL := time.Duration(answer.TTL) * time.Second; ttl == 0 || answerTTL < ttl {
+			ttl = answerTTL
+		}
+	}
+	if len(ips) == 0 {
+		return nil, 0, errors.New("没有A记录")
+	}
+	return ips, ttl, nil
+}
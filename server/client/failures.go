@@ -0,0 +1,120 @@
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// failureCategory 上游请求失败类别
+type failureCategory int
+
+const (
+	failureTimeout failureCategory = iota
+	failureNetwork
+	failureUnauthorized
+	failureServerError
+	failureHTTPError
+	failureParse
+)
+
+// failureDailyRetention 按日统计保留的天数（含今日）
+const failureDailyRetention = 7
+
+// failureTracker 上游请求失败计数（所有客户端实例共享）
+// 仅统计实际发出的请求，命中缓存返回的错误不会重复计数
+type failureTracker struct {
+	since time.Time
+	total models.UpstreamFailureCounts
+	daily map[string]*models.UpstreamFailureCounts // 日期 → 当日计数
+	mu    sync.Mutex
+}
+
+var failures = &failureTracker{
+	since: time.Now(),
+	daily: make(map[string]*models.UpstreamFailureCounts),
+}
+
+// GetFailureStats 获取上游请求失败统计
+func GetFailureStats() models.UpstreamFailureStats {
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+
+	stats := models.UpstreamFailureStats{
+		Since: failures.since,
+		Total: failures.total,
+		Daily: make([]models.DailyUpstreamFailures, 0, len(failures.daily)),
+	}
+	if today, ok := failures.daily[time.Now().Format("2006-01-02")]; ok {
+		stats.Today = *today
+	}
+	for date, counts := range failures.daily {
+		stats.Daily = append(stats.Daily, models.DailyUpstreamFailures{Date: date, UpstreamFailureCounts: *counts})
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool { return stats.Daily[i].Date > stats.Daily[j].Date })
+	return stats
+}
+
+// record 记录一次上游请求失败
+func (t *failureTracker) record(category failureCategory) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	date := now.Format("2006-01-02")
+	day, ok := t.daily[date]
+	if !ok {
+		day = &models.UpstreamFailureCounts{}
+		t.daily[date] = day
+		// 新的一天开始时清理过期的按日统计
+		cutoff := now.AddDate(0, 0, -(failureDailyRetention - 1)).Format("2006-01-02")
+		for d := range t.daily {
+			if d < cutoff {
+				delete(t.daily, d)
+			}
+		}
+	}
+
+	incrementFailure(&t.total, category)
+	incrementFailure(day, category)
+}
+
+// incrementFailure 按类别累加计数
+func incrementFailure(counts *models.UpstreamFailureCounts, category failureCategory) {
+	switch category {
+	case failureTimeout:
+		counts.Timeout++
+	case failureNetwork:
+		counts.Network++
+	case failureUnauthorized:
+		counts.Unauthorized++
+	case failureServerError:
+		counts.ServerError++
+	case failureHTTPError:
+		counts.HTTPError++
+	case failureParse:
+		counts.Parse++
+	}
+	counts.Total++
+}
+
+// recordFailure 按错误归类记录一次上游请求失败，返回原错误
+func recordFailure(err *UpstreamError) *UpstreamError {
+	switch {
+	case err.Code == models.ErrUpstreamTimeout:
+		failures.record(failureTimeout)
+	case err.StatusCode == 0 && err.Code == models.ErrAPIRequest:
+		failures.record(failureNetwork)
+	case err.Code == models.ErrCookieInvalid:
+		failures.record(failureUnauthorized)
+	case err.Code == models.ErrDataParsing:
+		failures.record(failureParse)
+	case err.StatusCode >= 500:
+		failures.record(failureServerError)
+	default:
+		failures.record(failureHTTPError)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 上游响应耗时统计参数
+const (
+	latencySampleSize   = 100             // 每个接口保留的最近样本数
+	slowWarningCooldown = 5 * time.Minute // 同一接口慢响应告警的最小间隔
+)
+
+// DefaultSlowResponseThreshold 默认慢响应阈值
+const DefaultSlowResponseThreshold = 5 * time.Second
+
+// SlowResponseHandler 慢响应回调
+type SlowResponseHandler func(endpoint string, duration, threshold time.Duration)
+
+// endpointLatency 单个上游接口的耗时样本
+type endpointLatency struct {
+	samples  []time.Duration // 环形缓冲区
+	next     int
+	count    int64 // 累计请求数
+	last     time.Duration
+	lastAt   time.Time
+	warnedAt time.Time // 最近一次慢响应告警时间
+}
+
+// latencyTracker 上游响应耗时统计（所有客户端实例共享）
+type latencyTracker struct {
+	endpoints     map[string]*endpointLatency
+	slowThreshold time.Duration
+	onSlow        SlowResponseHandler
+	mu            sync.Mutex
+}
+
+var latency = &latencyTracker{
+	endpoints:     make(map[string]*endpointLatency),
+	slowThreshold: DefaultSlowResponseThreshold,
+}
+
+// SetSlowResponseThreshold 设置慢响应阈值，0表示不告警
+func SetSlowResponseThreshold(threshold time.Duration) {
+	latency.mu.Lock()
+	defer latency.mu.Unlock()
+	latency.slowThreshold = threshold
+}
+
+// SetSlowResponseHandler 设置慢响应回调（异步执行，同一接口每5分钟最多触发一次）
+func SetSlowResponseHandler(handler SlowResponseHandler) {
+	latency.mu.Lock()
+	defer latency.mu.Unlock()
+	latency.onSlow = handler
+}
+
+// GetLatencyStats 获取各上游接口的响应耗时统计
+func GetLatencyStats() []models.UpstreamLatency {
+	latency.mu.Lock()
+	defer latency.mu.Unlock()
+
+	stats := make([]models.UpstreamLatency, 0, len(latency.endpoints))
+	for endpoint, e := range latency.endpoints {
+		samples := append([]time.Duration(nil), e.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+		stats = append(stats, models.UpstreamLatency{
+			Endpoint: endpoint,
+			Count:    e.count,
+			Samples:  len(samples),
+			P50Ms:    durationMs(percentile(samples, 0.50)),
+			P95Ms:    durationMs(percentile(samples, 0.95)),
+			MaxMs:    durationMs(samples[len(samples)-1]),
+			LastMs:   durationMs(e.last),
+			LastAt:   e.lastAt,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Endpoint < stats[j].Endpoint })
+	return stats
+}
+
+// record 记录一次上游响应耗时，超过阈值时触发慢响应回调
+func (t *latencyTracker) record(endpoint string, duration time.Duration) {
+	t.mu.Lock()
+	e, ok := t.endpoints[endpoint]
+	if !ok {
+		e = &endpointLatency{samples: make([]time.Duration, 0, latencySampleSize)}
+		t.endpoints[endpoint] = e
+	}
+
+	if len(e.samples) < latencySampleSize {
+		e.samples = append(e.samples, duration)
+	} else {
+		e.samples[e.next] = duration
+	}
+	e.next = (e.next + 1) % latencySampleSize
+	e.count++
+	e.last = duration
+	e.lastAt = time.Now()
+
+	threshold := t.slowThreshold
+	var onSlow SlowResponseHandler
+	if threshold > 0 && duration > threshold && time.Since(e.warnedAt) >= slowWarningCooldown {
+		e.warnedAt = time.Now()
+		onSlow = t.onSlow
+	}
+	t.mu.Unlock()
+
+	// 异步回调，避免调用方持有锁时发起的上游请求产生死锁
+	if onSlow != nil {
+		go onSlow(endpoint, duration, threshold)
+	}
+}
+
+// trackLatency 为resty客户端注册响应耗时统计（按请求路径区分接口，仅统计收到响应的请求）
+func trackLatency(client *resty.Client) {
+	client.OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+		endpoint := resp.Request.URL
+		if u, err := url.Parse(resp.Request.URL); err == nil {
+			endpoint = u.Path
+		}
+		latency.record(endpoint, resp.Time())
+		return nil
+	})
+}
+
+// percentile 计算已排序样本的百分位数（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(float64(len(sorted))*p)) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+// durationMs 转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Probe 探测上游站点是否可达（请求首页，不携带Cookie），返回HTTP状态码和响应耗时
+func Probe(timeout time.Duration) (int, time.Duration, error) {
+	if err := waitOutbound(context.Background(), "probe"); err != nil {
+		return 0, 0, err
+	}
+	httpClient := &http.Client{Transport: transport, Timeout: timeout}
+	start := time.Now()
+	resp, err := httpClient.Get(BaseURL())
+	latency := time.Since(start)
+	if err != nil {
+		return 0, latency, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, latency, nil
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// Provider 替代内置上游API的数据来源（如插件提供的其他站点），ctx取消或超时时应中止调用
+type Provider interface {
+	Name() string
+	FetchUsageData(ctx context.Context, cookie string) ([]models.UsageData, error)
+	FetchCreditBalance(ctx context.Context, cookie string) (*models.CreditBalance, error)
+	ResetCredits(ctx context.Context, cookie string) (bool, string, error)
+}
+
+// provider 当前使用的数据来源，为nil时使用内置上游API
+var provider Provider
+
+// SetProvider 设置替代的数据来源，需在创建客户端前调用
+func SetProvider(p Provider) {
+	provider = p
+}
+
+// CurrentProvider 获取当前设置的数据来源，未设置时返回nil
+func CurrentProvider() Provider {
+	return provider
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// outboundWindow 上游请求频率上限的统计窗口
+const outboundWindow = time.Minute
+
+// outboundLimiter 全局上游请求频率限制（所有客户端实例、插件和可用性探测共享）
+// 记录最近一分钟内发出请求的时间，保证任意连续一分钟内的请求数不超过上限；超出时等待而不是失败
+type outboundLimiter struct {
+	perMinute int         // 每分钟请求数上限，0表示不限制
+	sent      []time.Time // 最近一分钟内发出请求的时间（升序）
+	delayed   int64       // 因达到上限而等待的请求数
+	mu        sync.Mutex
+}
+
+var outbound = &outboundLimiter{}
+
+// SetOutboundRateLimit 设置全局上游请求频率上限（每分钟请求数，0表示不限制）
+// 包括定时任务、阈值检查、手动刷新、每日统计、Cookie保活、重置和重试在内的所有上游请求
+func SetOutboundRateLimit(perMinute int) {
+	outbound.mu.Lock()
+	defer outbound.mu.Unlock()
+	outbound.perMinute = max(perMinute, 0)
+}
+
+// GetOutboundLimitState 获取上游请求频率限制状态
+func GetOutboundLimitState() models.OutboundLimitState {
+	outbound.mu.Lock()
+	defer outbound.mu.Unlock()
+	outbound.expire(time.Now())
+	return models.OutboundLimitState{
+		PerMinute:  outbound.perMinute,
+		LastMinute: len(outbound.sent),
+		Delayed:    outbound.delayed,
+	}
+}
+
+// waitOutbound 等待直到可以发出一次上游请求（未设置上限时立即返回），ctx取消或超时时返回其错误
+func waitOutbound(ctx context.Context, endpoint string) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		outbound.mu.Lock()
+		if outbound.perMinute == 0 {
+			outbound.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		outbound.expire(now)
+		if len(outbound.sent) < outbound.perMinute {
+			outbound.sent = append(outbound.sent, now)
+			outbound.mu.Unlock()
+			return nil
+		}
+		wait := outbound.sent[0].Add(outboundWindow).Sub(now)
+		perMinute := outbound.perMinute
+		outbound.delayed++
+		outbound.mu.Unlock()
+
+		utils.Logf("[上游限流] ⏳ 已达到每分钟 %d 次的上游请求上限，%s 等待 %v", perMinute, endpoint, wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// expire 移除统计窗口之外的请求时间（内部方法，调用方持有锁）
+func (l *outboundLimiter) expire(now time.Time) {
+	i := 0
+	for i < len(l.sent) && now.Sub(l.sent[i]) >= outboundWindow {
+		i++
+	}
+	l.sent = append(l.sent[:0], l.sent[i:]...)
+}
+
+// limitOutbound 为resty客户端注册全局上游请求频率限制（每次发送前执行，重试同样计数）
+func limitOutbound(client *resty.Client) {
+	client.OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+		endpoint := req.URL
+		if u, err := url.Parse(req.URL); err == nil {
+			endpoint = u.Path
+		}
+		return waitOutbound(req.Context(), endpoint)
+	})
+}
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// SchemaDriftHandler 上游响应结构变化回调
+type SchemaDriftHandler func(drift models.UpstreamSchemaDrift)
+
+// schemaTracker 记录各接口已报告的结构变化，同一变化只报告一次（所有客户端实例共享）
+type schemaTracker struct {
+	reported map[string]string // 接口路径 → 已报告变化的签名
+	onDrift  SchemaDriftHandler
+	mu       sync.Mutex
+}
+
+var schemas = &schemaTracker{reported: make(map[string]string)}
+
+// SetSchemaDriftHandler 设置上游响应结构变化回调（异步执行，同一接口的同一变化只触发一次）
+func SetSchemaDriftHandler(handler SchemaDriftHandler) {
+	schemas.mu.Lock()
+	defer schemas.mu.Unlock()
+	schemas.onDrift = handler
+}
+
+// schemaCheck 单次响应的结构比对结果
+type schemaCheck struct {
+	present  map[string]bool
+	mistyped map[string]bool
+	note     string
+}
+
+func newSchemaCheck() *schemaCheck {
+	return &schemaCheck{present: make(map[string]bool), mistyped: make(map[string]bool)}
+}
+
+// report 与预期字段比对，有变化时记录结构化警告，新的变化触发回调
+// checked为false（如响应为空数组）时无法判断缺少的字段，只报告类型和结构变化
+func (c *schemaCheck) report(endpoint string, expected []string, checked bool) {
+	drift := models.UpstreamSchemaDrift{Endpoint: endpoint, Note: c.note}
+	known := make(map[string]bool, len(expected))
+	for _, field := range expected {
+		known[field] = true
+		if checked && !c.present[field] {
+			drift.Missing = append(drift.Missing, field)
+		}
+	}
+	for field := range c.present {
+		if !known[field] {
+			drift.Added = append(drift.Added, field)
+		}
+	}
+	for field := range c.mistyped {
+		drift.Mistyped = append(drift.Mistyped, field)
+	}
+	sort.Strings(drift.Added)
+	sort.Strings(drift.Mistyped)
+
+	signature := fmt.Sprintf("missing=%v added=%v mistyped=%v note=%s", drift.Missing, drift.Added, drift.Mistyped, drift.Note)
+	if len(drift.Missing)+len(drift.Added)+len(drift.Mistyped) == 0 && drift.Note == "" {
+		signature = ""
+	}
+
+	schemas.mu.Lock()
+	previous, seen := schemas.reported[endpoint]
+	schemas.reported[endpoint] = signature
+	handler := schemas.onDrift
+	schemas.mu.Unlock()
+
+	if signature == "" {
+		if seen && previous != "" {
+			utils.Logf("[上游结构] ✅ endpoint=%s 响应结构已恢复预期", endpoint)
+		}
+		return
+	}
+	if previous == signature {
+		return
+	}
+
+	drift.DetectedAt = time.Now()
+	utils.Logf("[上游结构] ⚠️  endpoint=%s missing=%v added=%v mistyped=%v note=%q，已按现有字段尽量解析",
+		endpoint, drift.Missing, drift.Added, drift.Mistyped, drift.Note)
+	if handler != nil {
+		go handler(drift)
+	}
+}
+
+// jsonFieldNames 结构体的JSON字段名（按声明顺序）
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonFieldName 结构体字段的JSON名称，忽略的字段返回空
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" || !field.IsExported() {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// decodeLenient 将JSON对象按字段解码到target指向的结构体，类型不符时尽量转换（数字字符串与数字互转）
+// 记录出现的字段和类型不符的字段，无法转换的字段保留零值
+func (c *schemaCheck) decodeLenient(raw map[string]json.RawMessage, target any) {
+	for key := range raw {
+		c.present[key] = true
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		value, ok := raw[name]
+		if name == "" || !ok || string(value) == "null" {
+			continue
+		}
+		field := v.Field(i)
+		if err := json.Unmarshal(value, field.Addr().Interface()); err == nil {
+			continue
+		}
+		c.mistyped[name] = true
+		coerceJSONValue(value, field)
+	}
+}
+
+// coerceJSONValue 将类型不符的JSON值转换为字段类型：数字字符串转为整数，数字和布尔值转为字符串
+func coerceJSONValue(value json.RawMessage, field reflect.Value) {
+	text := string(value)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	text = strings.TrimSpace(text)
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			field.SetInt(int64(n))
+		}
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			field.SetBool(b)
+		}
+	}
+}
+
+// decodeUsageResponse 尽量解析使用记录响应：预期为数组，兼容旧格式 {"data": [...]}，并检查结构变化
+func decodeUsageResponse(endpoint string, body []byte) ([]ClaudeUsageData, error) {
+	check := newSchemaCheck()
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		var wrapper struct {
+			Data []map[string]json.RawMessage `json:"data"`
+		}
+		if wrapErr := json.Unmarshal(body, &wrapper); wrapErr != nil || wrapper.Data == nil {
+			return nil, err
+		}
+		items = wrapper.Data
+		check.note = "响应由数组变为包含data数组的对象"
+	}
+
+	result := make([]ClaudeUsageData, len(items))
+	for i, item := range items {
+		check.decodeLenient(item, &result[i])
+	}
+	check.report(endpoint, jsonFieldNames(reflect.TypeOf(ClaudeUsageData{})), len(items) > 0)
+	return result, nil
+}
+
+// decodeCreditsResponse 尽量解析积分余额响应并检查结构变化
+// 缺少credits字段时以normalCredits与bonusCredits之和代替，都没有时返回错误，避免把余额误报为0
+func decodeCreditsResponse(endpoint string, body []byte) (ClaudeCreditsResponse, error) {
+	var resp ClaudeCreditsResponse
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return resp, err
+	}
+
+	check := newSchemaCheck()
+	check.decodeLenient(raw, &resp)
+	if _, ok := raw["credits"]; !ok {
+		_, hasNormal := raw["normalCredits"]
+		_, hasBonus := raw["bonusCredits"]
+		if !hasNormal && !hasBonus {
+			check.note = "缺少积分余额字段"
+			check.report(endpoint, jsonFieldNames(reflect.TypeOf(resp)), true)
+			return resp, fmt.Errorf("响应缺少credits字段")
+		}
+		resp.Credits = resp.NormalCredits + resp.BonusCredits
+		check.note = "缺少credits字段，以normalCredits与bonusCredits之和代替"
+	}
+	check.report(endpoint, jsonFieldNames(reflect.TypeOf(resp)), true)
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// sharedTransport 所有上游客户端共享的连接池，配置变化时替换，已创建的客户端随即使用新连接池
+type sharedTransport struct {
+	current *http.Transport
+	config  models.HTTPTransportConfig
+	mu      sync.RWMutex
+}
+
+var transport = &sharedTransport{
+	current: newTransport(models.HTTPTransportConfig{}),
+}
+
+// SetTransportConfig 设置上游请求的HTTP连接池，配置无变化时保持现有连接
+func SetTransportConfig(config models.HTTPTransportConfig) {
+	transport.mu.Lock()
+	if reflect.DeepEqual(config, transport.config) {
+		transport.mu.Unlock()
+		return
+	}
+	previous := transport.current
+	transport.current = newTransport(config)
+	transport.config = config
+	transport.mu.Unlock()
+
+	// 旧连接池不再分配新请求，关闭其空闲连接（进行中的请求不受影响）
+	previous.CloseIdleConnections()
+	utils.Logf("[上游连接] 连接池已更新: 空闲连接=%d, 空闲保留=%v, 禁用复用=%v, 禁用HTTP/2=%v",
+		config.GetMaxIdleConns(), config.GetIdleConnTimeout(), config.DisableKeepAlives, config.DisableHTTP2)
+	if len(config.Hosts) > 0 || config.DNSServer != "" {
+		utils.Logf("[上游连接] 自定义解析: 指定IP=%v, DNS服务器=%q", config.Hosts, config.DNSServer)
+	}
+}
+
+// RoundTrip 使用当前连接池发送请求
+func (t *sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	current := t.current
+	t.mu.RUnlock()
+	return current.RoundTrip(req)
+}
+
+// newTransport 按配置创建连接池（基于标准库默认设置，保留代理和拨号超时）
+// 上游只有一个站点，每个主机的空闲连接数与总数相同，默认2条连接即可在定时请求之间复用
+func newTransport(config models.HTTPTransportConfig) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = config.GetMaxIdleConns()
+	t.MaxIdleConnsPerHost = config.GetMaxIdleConns()
+	t.IdleConnTimeout = config.GetIdleConnTimeout()
+	t.DisableKeepAlives = config.DisableKeepAlives
+	t.ForceAttemptHTTP2 = !config.DisableHTTP2
+	if dialer := newUpstreamDialer(config); dialer != nil {
+		t.DialContext = dialer.DialContext
+	}
+	return t
+}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+var (
+	// ErrCookieExpired 上游返回401，Cookie无效或已过期
+	ErrCookieExpired = errors.New("Cookie无效或已过期")
+	// ErrCookieMissing 未配置Cookie
+	ErrCookieMissing = errors.New("Cookie为空")
+	// ErrRateLimited 上游返回429，请求过于频繁
+	ErrRateLimited = errors.New("上游请求过于频繁")
+)
+
+// UpstreamError 上游请求失败，按错误码归类
+// 所有上游请求的结果都经checkResponse统一转换，重试、告警和接口响应根据Code区分处理，无需解析错误文本
+type UpstreamError struct {
+	Code       string // 错误码（models.Err*）
+	Op         string // 失败的操作，如"获取积分余额"
+	StatusCode int    // 上游返回的状态码，未收到响应时为0
+	Err        error  // 底层错误
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s失败: %v", e.Op, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// checkResponse 将请求结果转换为UpstreamError并计入失败统计，状态码为200或okStatus之一时返回nil
+func checkResponse(op string, resp *resty.Response, err error, okStatus ...int) error {
+	// 调用方取消（如客户端断开、服务关闭）不是上游故障，不计入失败统计
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%s已取消: %w", op, err)
+	}
+	if err != nil {
+		code := models.ErrAPIRequest
+		if isTimeout(err) {
+			code = models.ErrUpstreamTimeout
+		}
+		return recordFailure(&UpstreamError{Code: code, Op: op, Err: err})
+	}
+
+	status := resp.StatusCode()
+	if status == 200 {
+		return nil
+	}
+	for _, ok := range okStatus {
+		if status == ok {
+			return nil
+		}
+	}
+
+	upstreamErr := &UpstreamError{Code: models.ErrAPIRequest, Op: op, StatusCode: status}
+	switch status {
+	case 401:
+		upstreamErr.Code = models.ErrCookieInvalid
+		upstreamErr.Err = ErrCookieExpired
+	case 429:
+		upstreamErr.Code = models.ErrRateLimited
+		upstreamErr.Err = ErrRateLimited
+	default:
+		upstreamErr.Err = fmt.Errorf("HTTP %s", resp.Status())
+		if resp.Status() == "" {
+			upstreamErr.Err = fmt.Errorf("HTTP %d", status)
+		}
+	}
+	return recordFailure(upstreamErr)
+}
+
+// cacheableError 请求失败的结果是否可以缓存：Cookie失效或未配置（更新Cookie后应立即重试）和调用方取消、超时的错误不缓存
+func cacheableError(ctx context.Context, err error) bool {
+	code := ErrorCode(err)
+	return code != models.ErrCookieInvalid && code != models.ErrCookieMissing && ctx.Err() == nil
+}
+
+// ErrorMessage 生成操作失败的提示：UpstreamError的文本已包含操作名，不再重复添加
+func ErrorMessage(op string, err error) string {
+	var upstreamErr *UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s失败: %v", op, err)
+}
+
+// parseError 响应解析失败
+func parseError(op string, err error) error {
+	return recordFailure(&UpstreamError{Code: models.ErrDataParsing, Op: op, Err: fmt.Errorf("解析响应失败: %w", err)})
+}
+
+// isTimeout 判断是否为超时错误
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// ErrorCode 返回错误对应的错误码（models.Err*），无法归类时返回空字符串
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var upstreamErr *UpstreamError
+	switch {
+	case errors.As(err, &upstreamErr):
+		return upstreamErr.Code
+	case errors.Is(err, ErrCookieMissing):
+		return models.ErrCookieMissing
+	case errors.Is(err, ErrCookieExpired):
+		// 插件返回的Cookie失效错误
+		return models.ErrCookieInvalid
+	case errors.Is(err, ErrRateLimited):
+		return models.ErrRateLimited
+	case isTimeout(err):
+		return models.ErrUpstreamTimeout
+	}
+	return ""
+}
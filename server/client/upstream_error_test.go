@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"未配置Cookie", ErrCookieMissing, models.ErrCookieMissing},
+		{"包装后的未配置Cookie", fmt.Errorf("手动获取失败: %w", ErrCookieMissing), models.ErrCookieMissing},
+		{"插件返回Cookie失效", ErrCookieExpired, models.ErrCookieInvalid},
+		{"上游错误", &UpstreamError{Code: models.ErrDataParsing, Op: "获取使用数据", Err: errors.New("x")}, models.ErrDataParsing},
+		{"无法归类", errors.New("x"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.want {
+				t.Fatalf("ErrorCode = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorMessageNoDoublePrefix(t *testing.T) {
+	upstream := &UpstreamError{Code: models.ErrAPIRequest, Op: "获取使用数据", Err: errors.New("connection refused")}
+	if got, want := ErrorMessage("获取使用数据", upstream), "获取使用数据失败: connection refused"; got != want {
+		t.Fatalf("ErrorMessage = %q，期望 %q", got, want)
+	}
+	if got, want := ErrorMessage("获取使用数据", ErrCookieMissing), "获取使用数据失败: Cookie为空"; got != want {
+		t.Fatalf("ErrorMessage = %q，期望 %q", got, want)
+	}
+}
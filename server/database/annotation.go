@@ -0,0 +1,109 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// annotationPrefix 图表标注的键前缀（后接定长的标注ID，按创建时间排序）
+const annotationPrefix = "annotation:"
+
+// ErrAnnotationExists 相同ID的标注已存在
+var ErrAnnotationExists = errors.New("标注ID已存在")
+
+// SaveAnnotation 保存新标注，相同ID的标注已存在时返回ErrAnnotationExists；超出数量上限时删除最早创建的标注
+func (b *BadgerDB) SaveAnnotation(annotation *models.Annotation) error {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		key := []byte(annotationPrefix + annotation.ID)
+		if _, err := txn.Get(key); err == nil {
+			return ErrAnnotationExists
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		data, err := json.Marshal(annotation)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(key, data); err != nil {
+			return err
+		}
+		return trimPrefix(txn, annotationPrefix, models.MaxAnnotations)
+	})
+	if errors.Is(err, ErrAnnotationExists) {
+		return err
+	}
+	if err != nil {
+		return b.trackError(fmt.Errorf("保存标注失败: %w", err))
+	}
+	return nil
+}
+
+// DeleteAnnotation 删除标注，返回被删除的标注；标注不存在时返回nil
+func (b *BadgerDB) DeleteAnnotation(id string) (*models.Annotation, error) {
+	var deleted *models.Annotation
+	err := b.db.Update(func(txn *badger.Txn) error {
+		key := []byte(annotationPrefix + id)
+		item, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var annotation models.Annotation
+		if err := item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &annotation)
+		}); err != nil {
+			return err
+		}
+		deleted = &annotation
+		return txn.Delete(key)
+	})
+	if err != nil {
+		return nil, b.trackError(fmt.Errorf("删除标注失败: %w", err))
+	}
+	return deleted, nil
+}
+
+// GetAnnotations 获取标注时间点不早于since的标注（按标注时间升序）
+func (b *BadgerDB) GetAnnotations(since time.Time) ([]models.Annotation, error) {
+	annotations := make([]models.Annotation, 0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(annotationPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var annotation models.Annotation
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &annotation)
+			})
+			if err != nil {
+				log.Printf("解析标注失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			if !annotation.Time.Before(since) {
+				annotations = append(annotations, annotation)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+
+	// 标注时间点可由请求指定，与创建顺序不一定一致
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].Time.Before(annotations[j].Time)
+	})
+	return annotations, nil
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestSaveAnnotationRejectsDuplicateID(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Now()
+	annotation := &models.Annotation{ID: models.AnnotationID(now), Time: now, Text: "第一条", CreatedAt: now}
+	if err := db.SaveAnnotation(annotation); err != nil {
+		t.Fatal(err)
+	}
+
+	duplicate := *annotation
+	duplicate.Text = "第二条"
+	if err := db.SaveAnnotation(&duplicate); !errors.Is(err, ErrAnnotationExists) {
+		t.Fatalf("相同ID应返回ErrAnnotationExists，实际 %v", err)
+	}
+	annotations, err := db.GetAnnotations(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations) != 1 || annotations[0].Text != "第一条" {
+		t.Fatalf("已有标注不应被覆盖: %+v", annotations)
+	}
+}
@@ -8,23 +8,32 @@ import (
 
 	"github.com/dgraph-io/badger/v4"
 	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/secrets"
 )
 
 type BadgerDB struct {
-	db *badger.DB
+	db        *badger.DB
+	secretBox *secrets.Box   // 敏感值加解密器（未配置主密钥时为nil，按明文存储）
+	errors    *errorRecorder // 数据库错误记录（包括Badger后台错误）
+
+	dailyUsage *dailyUsageCache // 每日积分统计缓存
+
+	configSaved func(config *models.UserConfig) // 配置保存成功后的回调
 }
 
 // NewBadgerDB 创建新的BadgerDB实例
 func NewBadgerDB(path string) (*BadgerDB, error) {
+	recorder := &errorRecorder{}
 	opts := badger.DefaultOptions(path).
-		WithLoggingLevel(badger.WARNING)
+		WithLoggingLevel(badger.WARNING).
+		WithLogger(recorder)
 
 	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, fmt.Errorf("打开数据库失败: %w", err)
 	}
 
-	return &BadgerDB{db: db}, nil
+	return &BadgerDB{db: db, errors: recorder, dailyUsage: newDailyUsageCache()}, nil
 }
 
 // Close 关闭数据库
@@ -32,17 +41,39 @@ func (b *BadgerDB) Close() error {
 	return b.db.Close()
 }
 
+// SetSecretBox 设置敏感值加解密器
+func (b *BadgerDB) SetSecretBox(box *secrets.Box) {
+	b.secretBox = box
+}
+
+// SetConfigSavedHandler 设置配置保存成功后的回调（回调中不得再保存配置）
+func (b *BadgerDB) SetConfigSavedHandler(handler func(config *models.UserConfig)) {
+	b.configSaved = handler
+}
+
 // SaveConfig 保存用户配置
 func (b *BadgerDB) SaveConfig(config *models.UserConfig) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		data, err := json.Marshal(config)
+	err := b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		// 读取保存前的配置用于审计（读取失败不影响保存）
+		previous, found, err := b.readConfig(txn)
+		if err != nil {
+			log.Printf("读取原配置失败，跳过配置审计: %v", err)
+			found = false
+		}
+
+		// 敏感字段加密后再落盘
+		sealed, err := sealConfig(b.secretBox, config)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(sealed)
 		if err != nil {
 			return err
 		}
 
 		// 保存各个配置项
 		configs := map[string]any{
-			"config:cookie":                   config.Cookie,
+			"config:cookie":                   sealed.Cookie,
 			"config:interval":                 config.Interval,
 			"config:timerange":                config.TimeRange,
 			"config:enabled":                  config.Enabled,
@@ -58,54 +89,152 @@ func (b *BadgerDB) SaveConfig(config *models.UserConfig) error {
 			}
 		}
 
-		return txn.Set([]byte("config:full"), data)
-	})
+		if err := txn.Set([]byte("config:full"), data); err != nil {
+			return err
+		}
+
+		if found {
+			return b.saveConfigAudit(txn, models.DiffConfig(previous, config), time.Now())
+		}
+		return nil
+	}))
+	if err == nil && b.configSaved != nil {
+		b.configSaved(config)
+	}
+	return err
 }
 
 // GetConfig 获取用户配置
 func (b *BadgerDB) GetConfig() (*models.UserConfig, error) {
+	var config *models.UserConfig
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		config, _, err = b.readConfig(txn)
+		return err
+	})
+	return config, err
+}
+
+// readConfig 在事务中读取用户配置，尚未保存过配置时返回默认配置且found为false
+func (b *BadgerDB) readConfig(txn *badger.Txn) (*models.UserConfig, bool, error) {
+	return readConfigWith(txn, b.secretBox)
+}
+
+// readConfigWith 在事务中读取用户配置，并使用指定的加解密器解密敏感字段
+func readConfigWith(txn *badger.Txn, box *secrets.Box) (*models.UserConfig, bool, error) {
 	config := models.GetDefaultConfig()
 
-	err := b.db.View(func(txn *badger.Txn) error {
-		// 读取完整配置
-		item, err := txn.Get([]byte("config:full"))
+	// 读取完整配置
+	item, err := txn.Get([]byte("config:full"))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return config, false, nil // 返回默认配置
+		}
+		return config, false, err
+	}
+
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, config)
+	})
+	if err != nil {
+		return config, true, err
+	}
+
+	// 单独读取cookie字段（因为Cookie字段有json:"-"标签，不会被序列化）
+	cookieItem, err := txn.Get([]byte("config:cookie"))
+	if err != nil && err != badger.ErrKeyNotFound {
+		return config, true, err
+	}
+	if err == nil {
+		err = cookieItem.Value(func(val []byte) error {
+			return json.Unmarshal(val, &config.Cookie)
+		})
+		if err != nil {
+			return config, true, err
+		}
+	}
+
+	if err := openConfig(box, config); err != nil {
+		return config, true, err
+	}
+	return config, true, nil
+}
+
+// sealConfig 返回敏感字段已加密的配置副本（用于落盘），不修改原配置
+func sealConfig(box *secrets.Box, config *models.UserConfig) (*models.UserConfig, error) {
+	sealed := *config
+	err := sealed.MapSecrets(func(path, value string) (string, error) {
+		encrypted, err := box.Encrypt(value)
+		if err != nil {
+			return "", fmt.Errorf("加密 %s 失败: %w", path, err)
+		}
+		return encrypted, nil
+	})
+	return &sealed, err
+}
+
+// openConfig 就地解密配置中的敏感字段（明文字段保持不变，兼容未加密的旧数据）
+func openConfig(box *secrets.Box, config *models.UserConfig) error {
+	return config.MapSecrets(func(path, value string) (string, error) {
+		plain, err := box.Decrypt(value)
+		if err != nil {
+			return "", fmt.Errorf("解密 %s 失败: %w", path, err)
+		}
+		return plain, nil
+	})
+}
+
+// ReencryptSecrets 使用新的加解密器重新加密所有敏感值，返回重新加密的字段数
+// oldBox为nil表示当前为明文存储，newBox为nil表示解密回明文存储
+func (b *BadgerDB) ReencryptSecrets(oldBox, newBox *secrets.Box) (int, error) {
+	var count int
+
+	err := b.db.Update(func(txn *badger.Txn) error {
+		config, found, err := readConfigWith(txn, oldBox)
 		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return nil // 返回默认配置
-			}
 			return err
 		}
+		if !found {
+			return nil
+		}
 
-		err = item.Value(func(val []byte) error {
-			return json.Unmarshal(val, config)
-		})
+		sealed, err := sealConfig(newBox, config)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(sealed)
+		if err != nil {
+			return err
+		}
+		cookie, err := json.Marshal(sealed.Cookie)
 		if err != nil {
 			return err
 		}
+		if err := txn.Set([]byte("config:full"), data); err != nil {
+			return err
+		}
+		if err := txn.Set([]byte("config:cookie"), cookie); err != nil {
+			return err
+		}
 
-		// 单独读取cookie字段（因为Cookie字段有json:"-"标签，不会被序列化）
-		cookieItem, err := txn.Get([]byte("config:cookie"))
-		if err != nil && err != badger.ErrKeyNotFound {
+		// 撤销快照同样使用新密钥重新加密，否则轮换后无法撤销
+		snapshot, err := readConfigUndo(txn, oldBox)
+		if err != nil {
 			return err
 		}
-		if err == nil {
-			err = cookieItem.Value(func(val []byte) error {
-				var cookie string
-				if unmarshalErr := json.Unmarshal(val, &cookie); unmarshalErr != nil {
-					return unmarshalErr
-				}
-				config.Cookie = cookie
-				return nil
-			})
-			if err != nil {
+		if snapshot != nil {
+			if err := writeConfigUndo(txn, newBox, snapshot); err != nil {
 				return err
 			}
 		}
 
-		return nil
+		return config.MapSecrets(func(path, value string) (string, error) {
+			count++
+			return value, nil
+		})
 	})
 
-	return config, err
+	return count, err
 }
 
 // ClearCookie 清除Cookie
@@ -145,22 +274,63 @@ func (b *BadgerDB) ClearCookie() error {
 	})
 }
 
+// GetNotifiedVersion 获取已通知过的最新版本号
+func (b *BadgerDB) GetNotifiedVersion() (string, error) {
+	var version string
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte("update:notified"))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			version = string(val)
+			return nil
+		})
+	})
+	return version, err
+}
+
+// SaveNotifiedVersion 记录已通知过的最新版本号，避免重复通知
+func (b *BadgerDB) SaveNotifiedVersion(version string) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte("update:notified"), []byte(version))
+	}))
+}
+
+// usageKey 原始使用记录的存储键（键中包含记录ID，避免同一秒内的多条记录互相覆盖）
+func usageKey(usage models.UsageData) string {
+	return fmt.Sprintf("usage:%d:%d", usage.CreatedAt.Unix(), usage.ID)
+}
+
 // SaveUsageData 保存积分使用数据
+// 先序列化全部记录，再通过WriteBatch批量写入：不受单个事务大小限制，大批量记录也不会长时间阻塞调度任务
 func (b *BadgerDB) SaveUsageData(data []models.UsageData) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		for _, usage := range data {
-			key := fmt.Sprintf("usage:%d", usage.CreatedAt.Unix())
-			value, err := json.Marshal(usage)
-			if err != nil {
-				return err
-			}
+	if len(data) == 0 {
+		return nil
+	}
 
-			if err := txn.Set([]byte(key), value); err != nil {
-				return err
-			}
+	keys := make([][]byte, len(data))
+	values := make([][]byte, len(data))
+	for i, usage := range data {
+		value, err := json.Marshal(usage)
+		if err != nil {
+			return fmt.Errorf("序列化使用数据失败: %w", err)
 		}
-		return nil
-	})
+		keys[i] = []byte(usageKey(usage))
+		values[i] = value
+	}
+
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+	for i := range keys {
+		if err := batch.Set(keys[i], values[i]); err != nil {
+			return b.trackError(err)
+		}
+	}
+	return b.trackError(batch.Flush())
 }
 
 // GetUsageData 获取指定时间范围内的积分使用数据
@@ -214,56 +384,20 @@ func (b *BadgerDB) GetUsageData(minutes int) (models.UsageDataList, error) {
 	return usageList, err
 }
 
-// CleanOldData 清理过期数据
-func (b *BadgerDB) CleanOldData(keepHours int) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		cutoff := time.Now().Add(-time.Duration(keepHours) * time.Hour).Unix()
-
-		opts := badger.DefaultIteratorOptions
-		it := txn.NewIterator(opts)
-		defer it.Close()
-
-		prefix := []byte("usage:")
-		var keysToDelete [][]byte
-
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			key := item.Key()
-
-			var usage models.UsageData
-			err := item.Value(func(val []byte) error {
-				return json.Unmarshal(val, &usage)
-			})
-			if err != nil {
-				continue
-			}
-
-			if usage.CreatedAt.Unix() < cutoff {
-				keysToDelete = append(keysToDelete, append([]byte(nil), key...))
-			}
-		}
-
-		// 删除过期数据
-		for _, key := range keysToDelete {
-			if err := txn.Delete(key); err != nil {
-				return err
-			}
-		}
-
-		return nil
-	})
-}
-
-// SaveCreditBalance 保存积分余额信息
+// SaveCreditBalance 保存积分余额信息（同时追加一条余额历史）
 func (b *BadgerDB) SaveCreditBalance(balance *models.CreditBalance) error {
-	return b.db.Update(func(txn *badger.Txn) error {
+	keepDays := b.currentRetention().BalanceHistoryTTLDays()
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
 		data, err := json.Marshal(balance)
 		if err != nil {
 			return err
 		}
 
+		if err := saveBalanceHistory(txn, balance, data, keepDays); err != nil {
+			return err
+		}
 		return txn.Set([]byte("balance:latest"), data)
-	})
+	}))
 }
 
 // GetCreditBalance 获取积分余额信息
@@ -291,7 +425,9 @@ func (b *BadgerDB) GetCreditBalance() (*models.CreditBalance, error) {
 
 // SaveDailyUsage 保存或累加每日积分使用统计
 func (b *BadgerDB) SaveDailyUsage(date string, credits int) error {
-	return b.db.Update(func(txn *badger.Txn) error {
+	defer b.dailyUsage.invalidate(date)
+	keepDays := b.currentRetention().DailyUsageTTLDays()
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
 		key := []byte(models.GetDailyUsageKey(date))
 		
 		// 尝试获取现有数据
@@ -325,13 +461,15 @@ func (b *BadgerDB) SaveDailyUsage(date string, credits int) error {
 			return err
 		}
 		
-		return txn.Set(key, data)
-	})
+		return setDailyUsage(txn, date, data, keepDays)
+	}))
 }
 
-// SaveDailyUsageWithModels 保存或累加每日积分使用统计（支持按模型分组）
-func (b *BadgerDB) SaveDailyUsageWithModels(date string, credits int, modelCredits map[string]int) error {
-	return b.db.Update(func(txn *badger.Txn) error {
+// SaveDailyUsageWithModels 保存或累加每日积分使用统计（支持按模型分组，hourlyModelCredits为 小时 → 模型 → 积分）
+func (b *BadgerDB) SaveDailyUsageWithModels(date string, credits int, modelCredits map[string]int, hourlyModelCredits map[string]map[string]int) error {
+	defer b.dailyUsage.invalidate(date)
+	keepDays := b.currentRetention().DailyUsageTTLDays()
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
 		key := []byte(models.GetDailyUsageKey(date))
 		
 		// 尝试获取现有数据
@@ -374,6 +512,19 @@ func (b *BadgerDB) SaveDailyUsageWithModels(date string, credits int, modelCredi
 				currentUsage.ModelCredits[model] += modelCredit
 			}
 		}
+
+		// 按小时和模型累加积分
+		for hour, hourCredits := range hourlyModelCredits {
+			if currentUsage.HourlyModelCredits == nil {
+				currentUsage.HourlyModelCredits = make(map[string]map[string]int)
+			}
+			if currentUsage.HourlyModelCredits[hour] == nil {
+				currentUsage.HourlyModelCredits[hour] = make(map[string]int)
+			}
+			for model, modelCredit := range hourCredits {
+				currentUsage.HourlyModelCredits[hour][model] += modelCredit
+			}
+		}
 		
 		// 保存数据
 		data, err := json.Marshal(currentUsage)
@@ -381,132 +532,98 @@ func (b *BadgerDB) SaveDailyUsageWithModels(date string, credits int, modelCredi
 			return err
 		}
 		
-		return txn.Set(key, data)
-	})
+		return setDailyUsage(txn, date, data, keepDays)
+	}))
 }
 
-// GetDailyUsage 获取指定日期的积分使用统计
-func (b *BadgerDB) GetDailyUsage(date string) (*models.DailyUsage, error) {
-	var usage *models.DailyUsage
-	
-	err := b.db.View(func(txn *badger.Txn) error {
+// IncrementDailyResets 累加指定日期的积分重置次数
+func (b *BadgerDB) IncrementDailyResets(date string) error {
+	defer b.dailyUsage.invalidate(date)
+	keepDays := b.currentRetention().DailyUsageTTLDays()
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
 		key := []byte(models.GetDailyUsageKey(date))
+
+		usage := models.DailyUsage{Date: date, ModelCredits: make(map[string]int)}
 		item, err := txn.Get(key)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return nil // 返回nil表示未找到数据
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			if err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &usage)
+			}); err != nil {
+				return err
 			}
+		}
+
+		usage.Resets++
+		data, err := json.Marshal(usage)
+		if err != nil {
 			return err
 		}
-		
-		err = item.Value(func(val []byte) error {
-			usage = &models.DailyUsage{}
-			return json.Unmarshal(val, usage)
-		})
-		return err
-	})
+		return setDailyUsage(txn, date, data, keepDays)
+	}))
+}
+
+// GetDailyUsage 获取指定日期的积分使用统计
+func (b *BadgerDB) GetDailyUsage(date string) (*models.DailyUsage, error) {
+	data, err := b.readDailyUsage(date)
+	if err != nil || data == nil {
+		return nil, err // 返回nil表示未找到数据
+	}
+	
+	usage := &models.DailyUsage{}
+	if err := json.Unmarshal(data, usage); err != nil {
+		return nil, err
+	}
 	
 	// 确保 ModelCredits 字段不为 nil（兼容旧数据）
-	if usage != nil && usage.ModelCredits == nil {
+	if usage.ModelCredits == nil {
 		usage.ModelCredits = make(map[string]int)
 	}
 	
-	return usage, err
+	return usage, nil
 }
 
 // GetWeeklyUsage 获取最近一周的每日积分使用统计
 func (b *BadgerDB) GetWeeklyUsage() (models.DailyUsageList, error) {
+	return b.GetRecentDailyUsage(7)
+}
+
+// GetRecentDailyUsage 获取最近指定天数的每日积分使用统计（无数据的日期返回空记录）
+func (b *BadgerDB) GetRecentDailyUsage(days int) (models.DailyUsageList, error) {
 	var usageList models.DailyUsageList
 	
-	err := b.db.View(func(txn *badger.Txn) error {
-		weekDates := models.GetWeekDates()
-		
-		// 按日期获取数据
-		for _, date := range weekDates {
-			key := []byte(models.GetDailyUsageKey(date))
-			
-			item, err := txn.Get(key)
-			if err != nil {
-				if err == badger.ErrKeyNotFound {
-					// 该日期没有数据，创建空记录
-					usageList = append(usageList, models.DailyUsage{
-						Date:         date,
-						TotalCredits: 0,
-						ModelCredits: make(map[string]int),
-					})
-					continue
-				}
-				return err
-			}
-			
-			var usage models.DailyUsage
-			err = item.Value(func(val []byte) error {
-				return json.Unmarshal(val, &usage)
-			})
-			if err != nil {
-				log.Printf("解析每日使用统计失败 %s: %v", key, err)
-				continue
-			}
-			
-			// 确保 ModelCredits 字段不为 nil（兼容旧数据）
-			if usage.ModelCredits == nil {
-				usage.ModelCredits = make(map[string]int)
-			}
-			
-			usageList = append(usageList, usage)
+	// 按日期获取数据（已缓存的日期不读取数据库）
+	for _, date := range models.GetRecentDates(days) {
+		data, err := b.readDailyUsage(date)
+		if err != nil {
+			return nil, err
 		}
-		
-		log.Printf("获取一周积分统计完成: 共%d天数据", len(usageList))
-		return nil
-	})
-	
-	return usageList, err
-}
-
-// CleanupOldDailyUsage 清理超过指定天数的每日积分统计数据
-func (b *BadgerDB) CleanupOldDailyUsage(keepDays int) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		cutoffDate := time.Now().Local().AddDate(0, 0, -keepDays).Format("2006-01-02")
-		
-		opts := badger.DefaultIteratorOptions
-		it := txn.NewIterator(opts)
-		defer it.Close()
-		
-		prefix := []byte("daily_usage:")
-		var keysToDelete [][]byte
-		var deletedCount int
-		
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			key := item.Key()
-			
-			var usage models.DailyUsage
-			err := item.Value(func(val []byte) error {
-				return json.Unmarshal(val, &usage)
+		if data == nil {
+			// 该日期没有数据，创建空记录
+			usageList = append(usageList, models.DailyUsage{
+				Date:         date,
+				TotalCredits: 0,
+				ModelCredits: make(map[string]int),
 			})
-			if err != nil {
-				log.Printf("解析每日使用统计失败 %s: %v", key, err)
-				continue
-			}
-			
-			// 删除超过保留期限的数据
-			if usage.Date < cutoffDate {
-				keysToDelete = append(keysToDelete, append([]byte(nil), key...))
-				deletedCount++
-			}
+			continue
 		}
 		
-		// 执行删除操作
-		for _, key := range keysToDelete {
-			if err := txn.Delete(key); err != nil {
-				return err
-			}
+		var usage models.DailyUsage
+		if err := json.Unmarshal(data, &usage); err != nil {
+			log.Printf("解析每日使用统计失败 %s: %v", models.GetDailyUsageKey(date), err)
+			continue
 		}
 		
-		if deletedCount > 0 {
-			log.Printf("清理过期的每日积分统计: 删除%d条记录（保留%d天）", deletedCount, keepDays)
+		// 确保 ModelCredits 字段不为 nil（兼容旧数据）
+		if usage.ModelCredits == nil {
+			usage.ModelCredits = make(map[string]int)
 		}
 		
-		return nil
-	})
+		usageList = append(usageList, usage)
+	}
+	
+	log.Printf("获取积分统计完成: 共%d天数据", len(usageList))
+	return usageList, nil
 }
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// balanceHistoryPrefix 积分余额历史的键前缀（后接Unix秒级时间戳）
+const balanceHistoryPrefix = "balance_history:"
+
+// balanceHistoryKey 生成积分余额历史的存储键
+func balanceHistoryKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%d", balanceHistoryPrefix, t.Unix()))
+}
+
+// saveBalanceHistory 在事务中追加一条积分余额历史，保留keepDays天后过期
+func saveBalanceHistory(txn *badger.Txn, balance *models.CreditBalance, data []byte, keepDays int) error {
+	return setWithExpiry(txn, balanceHistoryKey(balance.UpdatedAt), data, balanceHistoryExpiresAt(balance.UpdatedAt, keepDays))
+}
+
+// GetBalanceHistoryBefore 获取指定时间之前（不含）最近的一条积分余额历史，没有时返回nil
+func (b *BadgerDB) GetBalanceHistoryBefore(t time.Time) (*models.CreditBalance, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	return b.findBalanceHistory(balanceHistoryKey(t.Add(-time.Second)), opts)
+}
+
+// GetFirstBalanceHistorySince 获取指定时间及之后最早的一条积分余额历史，没有时返回nil
+func (b *BadgerDB) GetFirstBalanceHistorySince(t time.Time) (*models.CreditBalance, error) {
+	return b.findBalanceHistory(balanceHistoryKey(t), badger.DefaultIteratorOptions)
+}
+
+// findBalanceHistory 从指定键开始按迭代方向查找第一条积分余额历史
+func (b *BadgerDB) findBalanceHistory(seek []byte, opts badger.IteratorOptions) (*models.CreditBalance, error) {
+	var balance *models.CreditBalance
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(balanceHistoryPrefix)
+		it.Seek(seek)
+		if !it.ValidForPrefix(prefix) {
+			return nil
+		}
+		balance = &models.CreditBalance{}
+		return it.Item().Value(func(val []byte) error {
+			return json.Unmarshal(val, balance)
+		})
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return balance, nil
+}
+
+// GetBalanceHistorySince 获取指定时间及之后的全部积分余额历史（按时间升序）
+func (b *BadgerDB) GetBalanceHistorySince(t time.Time) ([]models.CreditBalance, error) {
+	var history []models.CreditBalance
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(balanceHistoryPrefix)
+		for it.Seek(balanceHistoryKey(t)); it.ValidForPrefix(prefix); it.Next() {
+			var balance models.CreditBalance
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &balance)
+			}); err != nil {
+				return err
+			}
+			history = append(history, balance)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return history, nil
+}
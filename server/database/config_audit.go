@@ -0,0 +1,66 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// configAuditPrefix 配置审计记录的键前缀（后接定长的Unix纳秒时间戳，保证按时间排序）
+const configAuditPrefix = "config_audit:"
+
+// configAuditKey 生成配置审计记录的存储键
+func configAuditKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%020d", configAuditPrefix, t.UnixNano()))
+}
+
+// saveConfigAudit 在事务中追加一条配置审计记录（没有变更时不记录），超出数量上限时删除最早的记录
+func (b *BadgerDB) saveConfigAudit(txn *badger.Txn, changes []models.ConfigChange, now time.Time) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(models.ConfigAuditEntry{Time: now, Changes: changes})
+	if err != nil {
+		return err
+	}
+	if err := txn.Set(configAuditKey(now), data); err != nil {
+		return err
+	}
+	return trimPrefix(txn, configAuditPrefix, models.MaxConfigAuditEntries)
+}
+
+// GetConfigAudit 获取最近的配置审计记录（按时间倒序）
+// field不为空时只返回包含该字段（或其子字段）变更的记录，且只保留匹配的变更
+func (b *BadgerDB) GetConfigAudit(field string, limit int) ([]models.ConfigAuditEntry, error) {
+	entries := make([]models.ConfigAuditEntry, 0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(configAuditPrefix)
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix) && len(entries) < limit; it.Next() {
+			var entry models.ConfigAuditEntry
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			})
+			if err != nil {
+				log.Printf("解析配置审计记录失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			if filtered, ok := entry.RedactSecrets().FilterField(field); ok {
+				entries = append(entries, filtered)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return entries, nil
+}
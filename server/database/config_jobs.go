@@ -0,0 +1,148 @@
+package database
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// configJobPrefix 待处理异步配置任务的键前缀
+const configJobPrefix = "configjob:"
+
+// SavePendingConfigJob 保存待处理的异步配置任务
+func (b *BadgerDB) SavePendingConfigJob(job *models.PendingConfigJob) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(configJobPrefix+job.ID), data)
+	}))
+}
+
+// DeletePendingConfigJob 删除已处理的异步配置任务
+func (b *BadgerDB) DeletePendingConfigJob(id string) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		err := txn.Delete([]byte(configJobPrefix + id))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}))
+}
+
+// GetPendingConfigJobs 获取全部待处理的异步配置任务（按提交时间升序）
+func (b *BadgerDB) GetPendingConfigJobs() ([]models.PendingConfigJob, error) {
+	var jobs []models.PendingConfigJob
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(configJobPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var job models.PendingConfigJob
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &job)
+			})
+			if err != nil {
+				log.Printf("解析待处理配置任务失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// deadConfigJobPrefix 死信配置任务的键前缀
+const deadConfigJobPrefix = "deadjob:"
+
+// MoveConfigJobToDeadLetter 将任务从待处理列表移入死信列表
+func (b *BadgerDB) MoveConfigJobToDeadLetter(job *models.DeadConfigJob) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		if err := txn.Delete([]byte(configJobPrefix + job.ID)); err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		return txn.Set([]byte(deadConfigJobPrefix+job.ID), data)
+	}))
+}
+
+// GetDeadConfigJobs 获取死信列表中的全部任务（按失败时间倒序）
+func (b *BadgerDB) GetDeadConfigJobs() ([]models.DeadConfigJob, error) {
+	jobs := []models.DeadConfigJob{}
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(deadConfigJobPrefix)
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var job models.DeadConfigJob
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &job)
+			})
+			if err != nil {
+				log.Printf("解析死信配置任务失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.After(jobs[j].FailedAt)
+	})
+	return jobs, nil
+}
+
+// GetDeadConfigJob 获取死信列表中的指定任务，不存在时返回nil
+func (b *BadgerDB) GetDeadConfigJob(id string) (*models.DeadConfigJob, error) {
+	var job *models.DeadConfigJob
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(deadConfigJobPrefix + id))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			job = &models.DeadConfigJob{}
+			return json.Unmarshal(val, job)
+		})
+	})
+
+	return job, b.trackError(err)
+}
+
+// DeleteDeadConfigJob 从死信列表中删除任务
+func (b *BadgerDB) DeleteDeadConfigJob(id string) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		err := txn.Delete([]byte(deadConfigJobPrefix + id))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}))
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/secrets"
+)
+
+// openTestDB 在临时目录中打开数据库
+func openTestDB(t *testing.T) *BadgerDB {
+	t.Helper()
+	db, err := NewBadgerDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// rawValue 读取键的原始存储值
+func rawValue(t *testing.T, db *BadgerDB, key string) string {
+	t.Helper()
+	var value string
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		value = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// secretTestConfig 所有敏感字段均已设置的配置
+func secretTestConfig() *models.UserConfig {
+	config := models.GetDefaultConfig()
+	config.Cookie = "session=cookie-secret"
+	config.AutoReset.ThresholdWebhookURL = "https://hooks.example.com/threshold-secret"
+	config.Exhaustion.WebhookURL = "https://hooks.example.com/exhaustion-secret"
+	config.ExternalUsage.WebhookURL = "https://hooks.example.com/external-secret"
+	config.LoginAlert.WebhookURL = "https://hooks.example.com/login-secret"
+	return config
+}
+
+func TestConfigSecretsEncrypted(t *testing.T) {
+	db := openTestDB(t)
+	oldBox, _ := secrets.NewBox("old-key")
+	db.SetSecretBox(oldBox)
+
+	config := secretTestConfig()
+	if err := db.SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := rawValue(t, db, "config:full") + rawValue(t, db, "config:cookie")
+	if strings.Contains(stored, "-secret") {
+		t.Fatalf("敏感字段以明文存储: %s", stored)
+	}
+	if config.Exhaustion.WebhookURL != "https://hooks.example.com/exhaustion-secret" {
+		t.Fatal("保存配置时修改了传入的配置")
+	}
+
+	loaded, err := db.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Fatalf("读取的配置与保存的不一致: %+v", loaded)
+	}
+
+	// 更换主密钥后未迁移，读取失败
+	newBox, _ := secrets.NewBox("new-key")
+	db.SetSecretBox(newBox)
+	if _, err := db.GetConfig(); err == nil {
+		t.Fatal("主密钥更换后读取配置应失败")
+	}
+
+	count, err := db.ReencryptSecrets(oldBox, newBox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(models.SecretFields) {
+		t.Fatalf("重新加密字段数 = %d, want %d", count, len(models.SecretFields))
+	}
+	loaded, err = db.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.LoginAlert.WebhookURL != config.LoginAlert.WebhookURL || loaded.Cookie != config.Cookie {
+		t.Fatalf("重新加密后读取的配置不一致: %+v", loaded)
+	}
+}
+
+func TestConfigSecretsPlainMigration(t *testing.T) {
+	db := openTestDB(t)
+	config := secretTestConfig()
+	if err := db.SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rawValue(t, db, "config:full"), "threshold-secret") {
+		t.Fatal("未配置主密钥时应以明文存储")
+	}
+
+	box, _ := secrets.NewBox("master-key")
+	if _, err := db.ReencryptSecrets(nil, box); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(rawValue(t, db, "config:full"), "-secret") {
+		t.Fatal("迁移后敏感字段仍为明文")
+	}
+
+	db.SetSecretBox(box)
+	loaded, err := db.GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AutoReset.ThresholdWebhookURL != config.AutoReset.ThresholdWebhookURL {
+		t.Fatalf("迁移后读取的配置不一致: %+v", loaded)
+	}
+}
+
+func TestConfigUndoSnapshotEncrypted(t *testing.T) {
+	db := openTestDB(t)
+	oldBox, _ := secrets.NewBox("old-key")
+	db.SetSecretBox(oldBox)
+
+	config := secretTestConfig()
+	if err := db.SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveConfigUndoSnapshot(config); err != nil {
+		t.Fatal(err)
+	}
+	if raw := rawValue(t, db, configUndoKey); strings.Contains(raw, "-secret") {
+		t.Fatalf("撤销快照中不应包含明文: %s", raw)
+	}
+
+	// 轮换密钥后撤销快照仍可读取
+	newBox, _ := secrets.NewBox("new-key")
+	if _, err := db.ReencryptSecrets(oldBox, newBox); err != nil {
+		t.Fatal(err)
+	}
+	db.SetSecretBox(newBox)
+
+	snapshot, err := db.GetConfigUndoSnapshot()
+	if err != nil {
+		t.Fatalf("读取撤销快照失败: %v", err)
+	}
+	if snapshot.LoginAlert.WebhookURL != config.LoginAlert.WebhookURL || snapshot.Exhaustion.WebhookURL != config.Exhaustion.WebhookURL {
+		t.Fatalf("撤销快照解密结果不正确: %+v", snapshot)
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"encoding/json"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/secrets"
+)
+
+// configUndoKey 配置撤销快照的存储键
+const configUndoKey = "config:undo"
+
+// SaveConfigUndoSnapshot 保存最近一次配置修改前的配置，供撤销使用（Cookie不会被保存，Webhook地址与配置一样加密存储）
+func (b *BadgerDB) SaveConfigUndoSnapshot(config *models.UserConfig) error {
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		return writeConfigUndo(txn, b.secretBox, config)
+	}))
+}
+
+// GetConfigUndoSnapshot 获取配置撤销快照，没有时返回nil
+func (b *BadgerDB) GetConfigUndoSnapshot() (*models.UserConfig, error) {
+	var config *models.UserConfig
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		config, err = readConfigUndo(txn, b.secretBox)
+		return err
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return config, nil
+}
+
+// writeConfigUndo 在事务中加密敏感字段后保存配置撤销快照
+func writeConfigUndo(txn *badger.Txn, box *secrets.Box, config *models.UserConfig) error {
+	sealed, err := sealConfig(box, config)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		return err
+	}
+	return txn.Set([]byte(configUndoKey), data)
+}
+
+// readConfigUndo 在事务中读取并解密配置撤销快照，没有时返回nil
+func readConfigUndo(txn *badger.Txn, box *secrets.Box) (*models.UserConfig, error) {
+	item, err := txn.Get([]byte(configUndoKey))
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	config := models.GetDefaultConfig()
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, config)
+	}); err != nil {
+		return nil, err
+	}
+	if err := openConfig(box, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
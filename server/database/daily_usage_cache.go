@@ -0,0 +1,79 @@
+package database
+
+import (
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// dailyUsageCache 每日积分统计的内存缓存（按日期保存数据库中的原始JSON，读取时解码为新对象，调用方可随意修改）
+// 反复打开积分历史、导出和同星期几对比时不必每次读取数据库；每日统计的写入方法提交后使该日期失效，
+// 更新过期时间后全部失效。缓存的日期不超过保留天数
+type dailyUsageCache struct {
+	mu         sync.RWMutex
+	entries    map[string][]byte // 日期 → JSON（nil表示该日期没有数据）
+	generation uint64            // 每次失效递增，读取期间发生写入时不缓存读到的旧数据
+}
+
+// newDailyUsageCache 创建每日积分统计缓存
+func newDailyUsageCache() *dailyUsageCache {
+	return &dailyUsageCache{entries: make(map[string][]byte)}
+}
+
+// get 获取日期的统计JSON，未缓存时调用load读取并缓存
+func (c *dailyUsageCache) get(date string, load func() ([]byte, error)) ([]byte, error) {
+	c.mu.RLock()
+	data, ok := c.entries[date]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return data, nil
+	}
+
+	data, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[date] = data
+	}
+	c.mu.Unlock()
+	return data, nil
+}
+
+// invalidate 使指定日期的缓存失效
+func (c *dailyUsageCache) invalidate(date string) {
+	c.mu.Lock()
+	delete(c.entries, date)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// clear 使全部缓存失效
+func (c *dailyUsageCache) clear() {
+	c.mu.Lock()
+	c.entries = make(map[string][]byte)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// readDailyUsage 读取指定日期的统计JSON（优先使用缓存），没有数据时返回nil
+func (b *BadgerDB) readDailyUsage(date string) ([]byte, error) {
+	return b.dailyUsage.get(date, func() ([]byte, error) {
+		var data []byte
+		err := b.db.View(func(txn *badger.Txn) error {
+			item, err := txn.Get([]byte(models.GetDailyUsageKey(date)))
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			data, err = item.ValueCopy(nil)
+			return err
+		})
+		return data, b.trackError(err)
+	})
+}
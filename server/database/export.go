@@ -0,0 +1,63 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// ExportReader 在同一个只读事务中遍历各类数据，导出期间的写入不影响结果
+type ExportReader struct {
+	txn *badger.Txn
+}
+
+// ExportSnapshot 以数据库当前快照调用fn导出数据
+func (b *BadgerDB) ExportSnapshot(fn func(r *ExportReader) error) error {
+	return b.trackError(b.db.View(func(txn *badger.Txn) error {
+		return fn(&ExportReader{txn: txn})
+	}))
+}
+
+// Usage 按时间升序遍历原始使用记录
+func (r *ExportReader) Usage(fn func(models.UsageData) error) error {
+	return forEachJSON(r.txn, "usage:", fn)
+}
+
+// UsageAggregates 按时间升序遍历降采样数据
+func (r *ExportReader) UsageAggregates(fn func(models.UsageAggregate) error) error {
+	return forEachJSON(r.txn, usageAggregatePrefix, fn)
+}
+
+// DailyUsage 按日期升序遍历每日积分统计（只包含有数据的日期）
+func (r *ExportReader) DailyUsage(fn func(models.DailyUsage) error) error {
+	return forEachJSON(r.txn, "daily_usage:", fn)
+}
+
+// BalanceHistory 按时间升序遍历积分余额历史
+func (r *ExportReader) BalanceHistory(fn func(models.CreditBalance) error) error {
+	return forEachJSON(r.txn, balanceHistoryPrefix, fn)
+}
+
+// forEachJSON 按键顺序遍历前缀下的记录并解码，无法解析的记录跳过
+func forEachJSON[T any](txn *badger.Txn, prefix string, fn func(T) error) error {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		var record T
+		err := it.Item().Value(func(val []byte) error {
+			return json.Unmarshal(val, &record)
+		})
+		if err != nil {
+			log.Printf("解析导出数据失败 %s: %v", it.Item().Key(), err)
+			continue
+		}
+		if err := fn(record); err != nil {
+			return fmt.Errorf("导出 %s 失败: %w", prefix, err)
+		}
+	}
+	return nil
+}
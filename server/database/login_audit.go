@@ -0,0 +1,159 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 登录审计的键前缀
+const (
+	loginAuditPrefix = "login_audit:" // 登录记录（后接定长的Unix纳秒时间戳，保证按时间排序）
+	loginIPPrefix    = "login_ip:"    // 成功登录过的IP（后接IP，值为首次登录时间），不随登录记录删除，超出数量上限时删除首次登录最早的IP
+)
+
+// loginAuditKey 生成登录记录的存储键
+func loginAuditKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%020d", loginAuditPrefix, t.UnixNano()))
+}
+
+// SaveLoginAudit 追加一条登录记录，超出数量上限时删除最早的记录
+// 登录成功时记录IP，IP从未成功登录过且之前已有其他IP登录成功时将entry.NewIP置为true（第一个登录的IP不视为新IP）
+func (b *BadgerDB) SaveLoginAudit(entry *models.LoginAuditEntry) error {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		if entry.Success {
+			known, err := recordLoginIP(txn, entry.IP, entry.Time)
+			if err != nil {
+				return err
+			}
+			entry.NewIP = !known
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if err := txn.Set(loginAuditKey(entry.Time), data); err != nil {
+			return err
+		}
+		return trimPrefix(txn, loginAuditPrefix, models.MaxLoginAuditEntries)
+	})
+	if err != nil {
+		return b.trackError(fmt.Errorf("保存登录记录失败: %w", err))
+	}
+	return nil
+}
+
+// recordLoginIP 记录成功登录的IP，返回该IP是否无需告警（已登录过，或是第一个登录的IP）
+func recordLoginIP(txn *badger.Txn, ip string, t time.Time) (bool, error) {
+	key := []byte(loginIPPrefix + ip)
+	if _, err := txn.Get(key); err == nil {
+		return true, nil
+	} else if err != badger.ErrKeyNotFound {
+		return false, err
+	}
+
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	it.Seek([]byte(loginIPPrefix))
+	first := !it.ValidForPrefix([]byte(loginIPPrefix))
+	it.Close()
+
+	if err := txn.Set(key, []byte(t.Format(time.RFC3339))); err != nil {
+		return false, err
+	}
+	return first, trimLoginIPs(txn, models.MaxKnownLoginIPs)
+}
+
+// trimLoginIPs 已登录IP超出数量上限时删除首次登录最早的IP（键按IP排序，需读取值中的时间比较）
+func trimLoginIPs(txn *badger.Txn, max int) error {
+	type knownIP struct {
+		key   []byte
+		first time.Time
+	}
+	var known []knownIP
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	prefix := []byte(loginIPPrefix)
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		value, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return err
+		}
+		first, _ := time.Parse(time.RFC3339, string(value)) // 无法解析时视为最早，优先删除
+		known = append(known, knownIP{key: it.Item().KeyCopy(nil), first: first})
+	}
+	it.Close()
+	if len(known) <= max {
+		return nil
+	}
+
+	sort.Slice(known, func(i, j int) bool { return known[i].first.Before(known[j].first) })
+	for _, ip := range known[:len(known)-max] {
+		if err := txn.Delete(ip.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLoginAudit 获取最近的登录记录（按时间倒序），success不为nil时只返回登录成功或失败的记录
+func (b *BadgerDB) GetLoginAudit(success *bool, limit int) ([]models.LoginAuditEntry, error) {
+	entries := make([]models.LoginAuditEntry, 0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(loginAuditPrefix)
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix) && len(entries) < limit; it.Next() {
+			var entry models.LoginAuditEntry
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &entry)
+			})
+			if err != nil {
+				log.Printf("解析登录记录失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			if success == nil || entry.Success == *success {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return entries, nil
+}
+
+// trimPrefix 从最新的记录向前数，删除前缀下超出数量上限的记录（本事务中新写入的记录可见）
+func trimPrefix(txn *badger.Txn, prefix string, max int) error {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Reverse = true
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var keysToDelete [][]byte
+	count := 0
+	for it.Seek(append([]byte(prefix), 0xFF)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		count++
+		if count > max {
+			keysToDelete = append(keysToDelete, it.Item().KeyCopy(nil))
+		}
+	}
+	for _, key := range keysToDelete {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+func TestTrimLoginIPs(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := db.db.Update(func(txn *badger.Txn) error {
+		// 键按IP排序与首次登录时间顺序相反，确认按时间而不是按键删除
+		for i := 0; i < 5; i++ {
+			if _, err := recordLoginIP(txn, fmt.Sprintf("10.0.0.%d", 9-i), start.Add(time.Duration(i)*time.Hour)); err != nil {
+				return err
+			}
+		}
+		return trimLoginIPs(txn, 3)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.db.View(func(txn *badger.Txn) error {
+		for i := 0; i < 5; i++ {
+			_, err := txn.Get([]byte(loginIPPrefix + fmt.Sprintf("10.0.0.%d", 9-i)))
+			if kept := err == nil; kept != (i >= 2) {
+				t.Errorf("IP 10.0.0.%d 保留状态 = %v，应只保留首次登录最晚的3个", 9-i, kept)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// 值日志GC的丢弃比例阈值（文件中超过该比例的数据可回收时才重写）
+const valueLogGCDiscardRatio = 0.5
+
+// DBStats 数据库统计信息
+type DBStats struct {
+	LSMSize     int64          `json:"lsmSize"`               // LSM树占用字节数
+	VlogSize    int64          `json:"vlogSize"`              // 值日志占用字节数
+	TotalKeys   int            `json:"totalKeys"`             // 键总数
+	KeyCounts   []PrefixCount  `json:"keyCounts"`             // 按前缀统计的键数量
+	LastError   string         `json:"lastError,omitempty"`   // 最近一次数据库错误
+	LastErrorAt *time.Time     `json:"lastErrorAt,omitempty"` // 最近一次数据库错误时间
+	ErrorCount  int64          `json:"errorCount"`            // 启动以来的数据库错误次数
+	Levels      []DBLevelStats `json:"levels"`                // LSM各层信息
+
+	SchemaVersion int `json:"schemaVersion"` // 数据库结构版本
+}
+
+// PrefixCount 单个键前缀的数量统计
+type PrefixCount struct {
+	Prefix string `json:"prefix"`
+	Count  int    `json:"count"`
+}
+
+// DBLevelStats LSM单层统计信息
+type DBLevelStats struct {
+	Level     int   `json:"level"`
+	NumTables int   `json:"numTables"`
+	Size      int64 `json:"size"`
+}
+
+// CompactResult 数据库压缩结果
+type CompactResult struct {
+	LSMSizeBefore  int64 `json:"lsmSizeBefore"`
+	VlogSizeBefore int64 `json:"vlogSizeBefore"`
+	LSMSizeAfter   int64 `json:"lsmSizeAfter"`
+	VlogSizeAfter  int64 `json:"vlogSizeAfter"`
+	GCRounds       int   `json:"gcRounds"`   // 成功回收的值日志文件数
+	DurationMs     int64 `json:"durationMs"` // 耗时（毫秒）
+}
+
+// errorRecorder 记录数据库错误，同时作为Badger的日志输出
+// Badger内部的后台错误（压缩、刷盘、磁盘写满等）不会返回给调用方，需通过日志捕获
+type errorRecorder struct {
+	mu        sync.RWMutex
+	lastError string
+	lastAt    time.Time
+	count     int64
+}
+
+func (r *errorRecorder) record(err string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastError = err
+	r.lastAt = time.Now()
+	r.count++
+}
+
+func (r *errorRecorder) snapshot() (string, time.Time, int64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastError, r.lastAt, r.count
+}
+
+// Errorf 实现badger.Logger
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+	r.record(msg)
+	log.Printf("[数据库] ❌ %s", msg)
+}
+
+// Warningf 实现badger.Logger
+func (r *errorRecorder) Warningf(format string, args ...any) {
+	log.Printf("[数据库] ⚠️  %s", strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+// Infof 实现badger.Logger（忽略）
+func (r *errorRecorder) Infof(string, ...any) {}
+
+// Debugf 实现badger.Logger（忽略）
+func (r *errorRecorder) Debugf(string, ...any) {}
+
+// trackError 记录操作返回的错误并原样返回
+func (b *BadgerDB) trackError(err error) error {
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		b.errors.record(err.Error())
+	}
+	return err
+}
+
+// LastError 获取最近一次数据库错误及发生时间
+func (b *BadgerDB) LastError() (string, time.Time) {
+	lastError, lastAt, _ := b.errors.snapshot()
+	return lastError, lastAt
+}
+
+// Ping 检查数据库是否可正常读取
+func (b *BadgerDB) Ping() error {
+	if b.db.IsClosed() {
+		return fmt.Errorf("数据库已关闭")
+	}
+	err := b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte("config:full"))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	})
+	return b.trackError(err)
+}
+
+// GetStats 获取数据库统计信息
+func (b *BadgerDB) GetStats() (*DBStats, error) {
+	lsm, vlog := b.db.Size()
+	stats := &DBStats{
+		LSMSize:  lsm,
+		VlogSize: vlog,
+	}
+
+	counts := make(map[string]int)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := string(it.Item().Key())
+			prefix := key
+			if idx := strings.Index(key, ":"); idx >= 0 {
+				prefix = key[:idx]
+			}
+			counts[prefix]++
+			stats.TotalKeys++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(fmt.Errorf("统计数据库键失败: %w", err))
+	}
+
+	stats.KeyCounts = make([]PrefixCount, 0, len(counts))
+	for prefix, count := range counts {
+		stats.KeyCounts = append(stats.KeyCounts, PrefixCount{Prefix: prefix, Count: count})
+	}
+	sort.Slice(stats.KeyCounts, func(i, j int) bool {
+		return stats.KeyCounts[i].Prefix < stats.KeyCounts[j].Prefix
+	})
+
+	for _, level := range b.db.Levels() {
+		stats.Levels = append(stats.Levels, DBLevelStats{
+			Level:     level.Level,
+			NumTables: level.NumTables,
+			Size:      level.Size,
+		})
+	}
+
+	if stats.SchemaVersion, err = b.SchemaVersion(); err != nil {
+		return nil, err
+	}
+
+	lastError, lastAt, count := b.errors.snapshot()
+	stats.LastError = lastError
+	stats.ErrorCount = count
+	if !lastAt.IsZero() {
+		stats.LastErrorAt = &lastAt
+	}
+
+	return stats, nil
+}
+
+// Compact 压缩数据库：合并LSM各层并回收值日志空间
+func (b *BadgerDB) Compact() (*CompactResult, error) {
+	start := time.Now()
+	result := &CompactResult{}
+	result.LSMSizeBefore, result.VlogSizeBefore = b.db.Size()
+
+	if err := b.db.Flatten(2); err != nil {
+		return nil, b.trackError(fmt.Errorf("合并LSM失败: %w", err))
+	}
+
+	// 反复执行直到没有可回收的值日志文件
+	for {
+		err := b.db.RunValueLogGC(valueLogGCDiscardRatio)
+		if err != nil {
+			if errors.Is(err, badger.ErrNoRewrite) {
+				break
+			}
+			return nil, b.trackError(fmt.Errorf("值日志GC失败: %w", err))
+		}
+		result.GCRounds++
+	}
+
+	result.LSMSizeAfter, result.VlogSizeAfter = b.db.Size()
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	log.Printf("[数据库] 压缩完成: LSM %d → %d 字节，值日志 %d → %d 字节，回收%d个文件，耗时%dms",
+		result.LSMSizeBefore, result.LSMSizeAfter, result.VlogSizeBefore, result.VlogSizeAfter,
+		result.GCRounds, result.DurationMs)
+
+	return result, nil
+}
+
+// Backup 将数据库全量快照以Badger原生备份格式写入w（可用badger restore或DB.Load恢复），返回快照的版本号
+// 备份在只读事务中进行，不影响正常读写；失败多由写入端（如客户端断开）引起，不计入数据库错误统计
+func (b *BadgerDB) Backup(w io.Writer) (uint64, error) {
+	version, err := b.db.Backup(w, 0)
+	if err != nil {
+		return 0, fmt.Errorf("备份数据库失败: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// schemaVersionKey 数据库结构版本（已应用的最后一个迁移的版本号，未记录时为0）
+const schemaVersionKey = "meta:schema_version"
+
+// Migration 一次数据库结构迁移（如修改键格式、补充新字段）
+type Migration struct {
+	Version     int    // 迁移后的结构版本（从1开始连续递增）
+	Description string // 迁移内容说明
+	// Apply 执行迁移，返回涉及的键数；dryRun时只检查和统计，不写入数据
+	// 迁移中断后会从头重新执行，实现需可重复执行（已迁移的数据跳过）
+	Apply func(b *BadgerDB, dryRun bool) (int, error)
+}
+
+// MigrationResult 单个迁移的执行结果
+type MigrationResult struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Affected    int    `json:"affected"` // 涉及的键数（dry-run时为将要修改的键数）
+}
+
+// migrations 按版本升序的迁移列表，新的迁移追加到末尾，已发布的迁移不再修改
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "记录数据库结构版本（初始版本，数据无需修改）",
+		Apply: func(*BadgerDB, bool) (int, error) {
+			return 0, nil
+		},
+	},
+}
+
+// LatestSchemaVersion 当前程序支持的数据库结构版本
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion 获取数据库当前的结构版本（未记录时为0，即引入迁移机制之前的数据库）
+func (b *BadgerDB) SchemaVersion() (int, error) {
+	version := 0
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(schemaVersionKey))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			version, err = strconv.Atoi(string(val))
+			return err
+		})
+	})
+	if err != nil {
+		return 0, b.trackError(fmt.Errorf("读取数据库结构版本失败: %w", err))
+	}
+	return version, nil
+}
+
+// PendingMigrations 获取尚未应用的迁移
+// 数据库版本高于程序支持的版本（使用旧版本程序打开新版本数据）时返回错误，避免旧程序写入无法识别的数据
+func (b *BadgerDB) PendingMigrations() ([]Migration, error) {
+	version, err := b.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > LatestSchemaVersion() {
+		return nil, fmt.Errorf("数据库结构版本 %d 高于当前程序支持的版本 %d，请使用新版本程序", version, LatestSchemaVersion())
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate 按版本顺序执行尚未应用的迁移，每个迁移完成后立即记录版本号（中断后从未完成的迁移继续）
+// dryRun时只执行各迁移的检查并返回将要修改的键数，不写入数据和版本号；多个迁移待执行时各自基于当前数据统计
+func (b *BadgerDB) Migrate(dryRun bool) ([]MigrationResult, error) {
+	pending, err := b.PendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]MigrationResult, 0, len(pending))
+	for _, migration := range pending {
+		affected, err := migration.Apply(b, dryRun)
+		if err != nil {
+			return results, b.trackError(fmt.Errorf("执行迁移 v%d（%s）失败: %w", migration.Version, migration.Description, err))
+		}
+		results = append(results, MigrationResult{Version: migration.Version, Description: migration.Description, Affected: affected})
+		if dryRun {
+			continue
+		}
+
+		err = b.db.Update(func(txn *badger.Txn) error {
+			return txn.Set([]byte(schemaVersionKey), []byte(strconv.Itoa(migration.Version)))
+		})
+		if err != nil {
+			return results, b.trackError(fmt.Errorf("记录数据库结构版本 v%d 失败: %w", migration.Version, err))
+		}
+		log.Printf("[数据库迁移] ✅ v%d %s（涉及 %d 个键）", migration.Version, migration.Description, affected)
+	}
+	return results, nil
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// useTestMigrations 在测试期间替换迁移列表
+func useTestMigrations(t *testing.T, list []Migration) {
+	t.Helper()
+	original := migrations
+	migrations = list
+	t.Cleanup(func() { migrations = original })
+}
+
+// markerMigration 写入一个标记键的迁移，dry-run时只统计
+func markerMigration(version int, key string, calls *int) Migration {
+	return Migration{
+		Version:     version,
+		Description: "写入" + key,
+		Apply: func(b *BadgerDB, dryRun bool) (int, error) {
+			*calls++
+			if dryRun {
+				return 1, nil
+			}
+			return 1, b.db.Update(func(txn *badger.Txn) error {
+				return txn.Set([]byte(key), []byte("1"))
+			})
+		},
+	}
+}
+
+func TestMigrateDryRunAndApply(t *testing.T) {
+	db := openTestDB(t)
+	var calls int
+	useTestMigrations(t, []Migration{markerMigration(1, "test:a", &calls), markerMigration(2, "test:b", &calls)})
+
+	results, err := db.Migrate(true)
+	if err != nil || len(results) != 2 || results[1].Affected != 1 {
+		t.Fatalf("dry-run应返回两个迁移的统计: %+v, %v", results, err)
+	}
+	if version, _ := db.SchemaVersion(); version != 0 {
+		t.Fatalf("dry-run不应记录版本号，实际 %d", version)
+	}
+	if err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte("test:a"))
+		return err
+	}); !errors.Is(err, badger.ErrKeyNotFound) {
+		t.Fatalf("dry-run不应写入数据: %v", err)
+	}
+
+	if _, err := db.Migrate(false); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := db.SchemaVersion(); version != 2 {
+		t.Fatalf("迁移后版本应为2，实际 %d", version)
+	}
+	if rawValue(t, db, "test:b") != "1" {
+		t.Fatal("迁移应写入数据")
+	}
+
+	calls = 0
+	if results, err := db.Migrate(false); err != nil || len(results) != 0 || calls != 0 {
+		t.Fatalf("已是最新版本时不应再执行迁移: %+v, %v, 调用 %d 次", results, err, calls)
+	}
+}
+
+func TestMigrateResumesAfterFailure(t *testing.T) {
+	db := openTestDB(t)
+	var calls int
+	failing := Migration{
+		Version:     2,
+		Description: "失败的迁移",
+		Apply: func(*BadgerDB, bool) (int, error) {
+			return 0, errors.New("磁盘已满")
+		},
+	}
+	useTestMigrations(t, []Migration{markerMigration(1, "test:a", &calls), failing})
+
+	if _, err := db.Migrate(false); err == nil || !strings.Contains(err.Error(), "v2") {
+		t.Fatalf("迁移失败时应返回包含版本号的错误，实际 %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != 1 {
+		t.Fatalf("失败前已完成的迁移应记录版本号，实际 %d", version)
+	}
+
+	calls = 0
+	useTestMigrations(t, []Migration{markerMigration(1, "test:a", &calls), markerMigration(2, "test:b", &calls)})
+	if results, err := db.Migrate(false); err != nil || len(results) != 1 || results[0].Version != 2 || calls != 1 {
+		t.Fatalf("应从未完成的迁移继续: %+v, %v, 调用 %d 次", results, err, calls)
+	}
+}
+
+func TestPendingMigrationsRejectsNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	var calls int
+	useTestMigrations(t, []Migration{markerMigration(1, "test:a", &calls)})
+
+	if err := db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(schemaVersionKey), []byte("5"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.PendingMigrations(); err == nil {
+		t.Fatal("数据库版本高于程序支持的版本时应返回错误")
+	}
+	if _, err := db.Migrate(false); err == nil || calls != 0 {
+		t.Fatalf("数据库版本更高时不应执行迁移: %v, 调用 %d 次", err, calls)
+	}
+}
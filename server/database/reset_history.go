@@ -0,0 +1,70 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// resetHistoryPrefix 积分重置记录的键前缀（后接定长的Unix纳秒时间戳，保证按时间排序）
+const resetHistoryPrefix = "reset_history:"
+
+// resetHistoryKey 生成积分重置记录的存储键
+func resetHistoryKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%020d", resetHistoryPrefix, t.UnixNano()))
+}
+
+// resetHistoryExpiresAt 积分重置记录的过期时间：与重置当日的每日积分统计同时过期
+func resetHistoryExpiresAt(t time.Time, keepDays int) time.Time {
+	expiresAt, _ := dailyUsageExpiresAt(models.GetLocalDate(t), keepDays)
+	return expiresAt
+}
+
+// SaveResetRecord 追加一条积分重置记录，保留天数与每日积分统计相同
+func (b *BadgerDB) SaveResetRecord(record models.ResetRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	keepDays := b.currentRetention().DailyUsageTTLDays()
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		return setWithExpiry(txn, resetHistoryKey(record.Time), data, resetHistoryExpiresAt(record.Time, keepDays))
+	}))
+}
+
+// GetResetRecordsSince 获取指定时间及之后的积分重置记录（按时间倒序）
+func (b *BadgerDB) GetResetRecordsSince(t time.Time) ([]models.ResetRecord, error) {
+	records := make([]models.ResetRecord, 0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefix := []byte(resetHistoryPrefix)
+		since := resetHistoryKey(t)
+		for it.Seek(append(prefix, 0xFF)); it.ValidForPrefix(prefix); it.Next() {
+			if string(it.Item().Key()) < string(since) {
+				break
+			}
+			var record models.ResetRecord
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &record)
+			})
+			if err != nil {
+				log.Printf("解析积分重置记录失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return records, nil
+}
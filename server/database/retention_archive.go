@@ -0,0 +1,110 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 到期归档的数据类型（同时作为归档文件名前缀）
+const (
+	ArchiveKindDailyUsage = "daily"     // 每日积分统计
+	ArchiveKindResets     = "resets"    // 积分重置记录
+	ArchiveKindBalance    = "balance"   // 积分余额历史
+	ArchiveKindAggregate  = "aggregate" // 降采样数据
+)
+
+// ExpiredRecord 已过保留期、在宽限期内等待写入归档的记录
+type ExpiredRecord struct {
+	Key   []byte
+	Time  time.Time       // 记录对应的时间（用于按月归档）
+	Value json.RawMessage // 数据库中保存的JSON
+}
+
+// expiringKind 可到期归档的数据类型
+type expiringKind struct {
+	kind    string
+	prefix  string
+	enabled bool
+	// parse 根据键后缀解析记录时间和按保留天数（不含宽限期）计算的到期时间
+	parse func(suffix string) (t, expiresAt time.Time, ok bool)
+}
+
+// expiringKinds 按保留策略列出可到期归档的数据类型
+func expiringKinds(retention models.RetentionConfig) []expiringKind {
+	unixSeconds := func(keepDays int, expiresAt func(time.Time, int) time.Time) func(string) (time.Time, time.Time, bool) {
+		return func(suffix string) (time.Time, time.Time, bool) {
+			unix, err := strconv.ParseInt(suffix, 10, 64)
+			t := time.Unix(unix, 0)
+			return t, expiresAt(t, keepDays), err == nil
+		}
+	}
+	return []expiringKind{
+		{ArchiveKindDailyUsage, "daily_usage:", retention.ArchiveDailyUsage, func(suffix string) (time.Time, time.Time, bool) {
+			t, err := time.ParseInLocation("2006-01-02", suffix, time.Local)
+			expiresAt, ok := dailyUsageExpiresAt(suffix, retention.DailyUsageDays)
+			return t, expiresAt, err == nil && ok
+		}},
+		{ArchiveKindResets, resetHistoryPrefix, retention.ArchiveDailyUsage, func(suffix string) (time.Time, time.Time, bool) {
+			nano, err := strconv.ParseInt(suffix, 10, 64)
+			t := time.Unix(0, nano)
+			return t, resetHistoryExpiresAt(t, retention.DailyUsageDays), err == nil
+		}},
+		{ArchiveKindBalance, balanceHistoryPrefix, retention.ArchiveBalanceHistory, unixSeconds(retention.BalanceHistoryDays, balanceHistoryExpiresAt)},
+		{ArchiveKindAggregate, usageAggregatePrefix, retention.ArchiveAggregates, unixSeconds(retention.AggregateDays, usageAggregateExpiresAt)},
+	}
+}
+
+// GetExpiredRecords 获取启用到期归档的数据类型中已超过保留天数的记录（按数据类型分组，各类型内按时间升序）
+func (b *BadgerDB) GetExpiredRecords(retention models.RetentionConfig, now time.Time) (map[string][]ExpiredRecord, error) {
+	retention.Validate()
+	expired := make(map[string][]ExpiredRecord)
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, kind := range expiringKinds(retention) {
+			if !kind.enabled {
+				continue
+			}
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			for it.Seek([]byte(kind.prefix)); it.ValidForPrefix([]byte(kind.prefix)); it.Next() {
+				item := it.Item()
+				t, expiresAt, ok := kind.parse(strings.TrimPrefix(string(item.Key()), kind.prefix))
+				if !ok || expiresAt.After(now) {
+					continue
+				}
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					it.Close()
+					return err
+				}
+				expired[kind.kind] = append(expired[kind.kind], ExpiredRecord{Key: item.KeyCopy(nil), Time: t, Value: value})
+			}
+			it.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(fmt.Errorf("读取到期数据失败: %w", err))
+	}
+	return expired, nil
+}
+
+// DeleteExpiredRecords 删除已写入归档的到期记录
+func (b *BadgerDB) DeleteExpiredRecords(records []ExpiredRecord) error {
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, record := range records {
+		if err := batch.Delete(record.Key); err != nil {
+			return b.trackError(fmt.Errorf("删除已归档数据失败: %w", err))
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return b.trackError(fmt.Errorf("删除已归档数据失败: %w", err))
+	}
+	b.dailyUsage.clear()
+	return nil
+}
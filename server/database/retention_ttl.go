@@ -0,0 +1,209 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// retentionTTLKey 记录当前数据的过期时间所依据的保留策略
+const retentionTTLKey = "retention:ttl"
+
+// retentionTTL 决定过期时间的保留天数
+type retentionTTL struct {
+	DailyUsageDays     int `json:"dailyUsageDays"`
+	BalanceHistoryDays int `json:"balanceHistoryDays"`
+	AggregateDays      int `json:"aggregateDays"`
+}
+
+// currentRetention 读取生效的数据保留策略（未保存配置时使用默认值）
+// 在单独的只读事务中读取，避免写入数据的事务因配置同时被修改而冲突
+func (b *BadgerDB) currentRetention() models.RetentionConfig {
+	var config struct {
+		Retention models.RetentionConfig `json:"retention"`
+	}
+	b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte("config:full"))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &config)
+		})
+	})
+	config.Retention.Validate()
+	return config.Retention
+}
+
+// dailyUsageExpiresAt 每日积分统计的过期时间：保留keepDays天后的次日零点
+func dailyUsageExpiresAt(date string, keepDays int) (time.Time, bool) {
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day.AddDate(0, 0, keepDays+1), true
+}
+
+// balanceHistoryExpiresAt 积分余额历史的过期时间
+func balanceHistoryExpiresAt(updatedAt time.Time, keepDays int) time.Time {
+	return updatedAt.AddDate(0, 0, keepDays)
+}
+
+// usageAggregateExpiresAt 降采样数据的过期时间
+func usageAggregateExpiresAt(start time.Time, keepDays int) time.Time {
+	return start.AddDate(0, 0, keepDays)
+}
+
+// setWithExpiry 写入在expiresAt过期的数据，已过期时删除
+func setWithExpiry(txn *badger.Txn, key, value []byte, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return txn.Delete(key)
+	}
+	return txn.SetEntry(badger.NewEntry(key, value).WithTTL(ttl))
+}
+
+// ApplyRetentionTTL 保留策略变化后（或首次升级时）按新的保留天数重新设置每日积分统计、积分重置记录、积分余额历史和降采样数据的过期时间
+// 启用到期归档的数据类型按包含宽限期的天数设置
+// 平时数据在写入时即设置过期时间，由Badger自动删除，无需定期遍历清理；返回更新的记录数，策略未变化时返回0
+func (b *BadgerDB) ApplyRetentionTTL(retention models.RetentionConfig) (int, error) {
+	retention.Validate()
+	target := retentionTTL{
+		DailyUsageDays:     retention.DailyUsageTTLDays(),
+		BalanceHistoryDays: retention.BalanceHistoryTTLDays(),
+		AggregateDays:      retention.AggregateTTLDays(),
+	}
+	targetData, err := json.Marshal(target)
+	if err != nil {
+		return 0, err
+	}
+
+	var applied []byte
+	err = b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(retentionTTLKey))
+		if err != nil {
+			return err
+		}
+		applied, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil && err != badger.ErrKeyNotFound {
+		return 0, b.trackError(err)
+	}
+	if string(applied) == string(targetData) {
+		return 0, nil
+	}
+
+	// 每日积分统计会被累加更新，在事务中读写以免覆盖并发写入的数据（记录数不超过保留天数）
+	updated := 0
+	err = b.db.Update(func(txn *badger.Txn) error {
+		entries, err := collectTTLEntries(txn, "daily_usage:", func(suffix string) (time.Time, bool) {
+			return dailyUsageExpiresAt(suffix, target.DailyUsageDays)
+		})
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := setWithExpiry(txn, entry.key, entry.value, entry.expiresAt); err != nil {
+				return err
+			}
+		}
+		updated = len(entries)
+		return nil
+	})
+	b.dailyUsage.clear()
+	if err != nil {
+		return 0, b.trackError(fmt.Errorf("更新每日积分统计过期时间失败: %w", err))
+	}
+
+	// 积分重置记录、积分余额历史和降采样数据写入后不再修改，记录较多，批量写入不受单个事务大小限制
+	var entries []ttlEntry
+	err = b.db.View(func(txn *badger.Txn) error {
+		resets, err := collectTTLEntries(txn, resetHistoryPrefix, func(suffix string) (time.Time, bool) {
+			nano, err := strconv.ParseInt(suffix, 10, 64)
+			return resetHistoryExpiresAt(time.Unix(0, nano), target.DailyUsageDays), err == nil
+		})
+		if err != nil {
+			return err
+		}
+		history, err := collectTTLEntries(txn, balanceHistoryPrefix, func(suffix string) (time.Time, bool) {
+			unix, err := strconv.ParseInt(suffix, 10, 64)
+			return balanceHistoryExpiresAt(time.Unix(unix, 0), target.BalanceHistoryDays), err == nil
+		})
+		if err != nil {
+			return err
+		}
+		aggregates, err := collectTTLEntries(txn, usageAggregatePrefix, func(suffix string) (time.Time, bool) {
+			unix, err := strconv.ParseInt(suffix, 10, 64)
+			return usageAggregateExpiresAt(time.Unix(unix, 0), target.AggregateDays), err == nil
+		})
+		entries = append(append(resets, history...), aggregates...)
+		return err
+	})
+	if err != nil {
+		return 0, b.trackError(err)
+	}
+
+	batch := b.db.NewWriteBatch()
+	defer batch.Cancel()
+	now := time.Now()
+	for _, entry := range entries {
+		if !entry.expiresAt.After(now) {
+			err = batch.Delete(entry.key)
+		} else {
+			err = batch.SetEntry(badger.NewEntry(entry.key, entry.value).WithTTL(entry.expiresAt.Sub(now)))
+		}
+		if err != nil {
+			return 0, b.trackError(fmt.Errorf("更新数据过期时间失败: %w", err))
+		}
+	}
+	if err := batch.Set([]byte(retentionTTLKey), targetData); err != nil {
+		return 0, b.trackError(err)
+	}
+	if err := batch.Flush(); err != nil {
+		return 0, b.trackError(fmt.Errorf("更新数据过期时间失败: %w", err))
+	}
+	return updated + len(entries), nil
+}
+
+// ttlEntry 需要重新设置过期时间的记录
+type ttlEntry struct {
+	key, value []byte
+	expiresAt  time.Time
+}
+
+// collectTTLEntries 收集指定前缀的记录，expiresAt根据键的后缀计算过期时间（无法解析时跳过）
+func collectTTLEntries(txn *badger.Txn, prefix string, expiresAt func(suffix string) (time.Time, bool)) ([]ttlEntry, error) {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	var entries []ttlEntry
+	for it.Seek([]byte(prefix)); it.ValidForPrefix([]byte(prefix)); it.Next() {
+		item := it.Item()
+		t, ok := expiresAt(strings.TrimPrefix(string(item.Key()), prefix))
+		if !ok {
+			continue
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ttlEntry{key: item.KeyCopy(nil), value: value, expiresAt: t})
+	}
+	return entries, nil
+}
+
+// setDailyUsage 在事务中写入每日积分统计，保留keepDays天后过期
+func setDailyUsage(txn *badger.Txn, date string, data []byte, keepDays int) error {
+	key := []byte(models.GetDailyUsageKey(date))
+	expiresAt, ok := dailyUsageExpiresAt(date, keepDays)
+	if !ok {
+		return txn.Set(key, data)
+	}
+	return setWithExpiry(txn, key, data, expiresAt)
+}
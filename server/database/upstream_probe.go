@@ -0,0 +1,57 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// upstreamProbePrefix 上游可用性探测记录的键前缀（后接定长的Unix秒级时间戳，保证按时间排序）
+const upstreamProbePrefix = "upstream_probe:"
+
+// upstreamProbeKey 生成上游可用性探测记录的存储键
+func upstreamProbeKey(t time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%020d", upstreamProbePrefix, t.Unix()))
+}
+
+// SaveUpstreamProbe 追加一条上游可用性探测记录，保留固定天数后过期
+func (b *BadgerDB) SaveUpstreamProbe(probe models.UpstreamProbe) error {
+	data, err := json.Marshal(probe)
+	if err != nil {
+		return err
+	}
+	return b.trackError(b.db.Update(func(txn *badger.Txn) error {
+		return setWithExpiry(txn, upstreamProbeKey(probe.Time), data, probe.Time.AddDate(0, 0, models.UpstreamProbeDays))
+	}))
+}
+
+// GetUpstreamProbesSince 获取指定时间及之后的上游可用性探测记录（按时间升序）
+func (b *BadgerDB) GetUpstreamProbesSince(t time.Time) ([]models.UpstreamProbe, error) {
+	probes := make([]models.UpstreamProbe, 0)
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(upstreamProbePrefix)
+		for it.Seek(upstreamProbeKey(t)); it.ValidForPrefix(prefix); it.Next() {
+			var probe models.UpstreamProbe
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &probe)
+			})
+			if err != nil {
+				log.Printf("解析上游探测记录失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			probes = append(probes, probe)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return probes, nil
+}
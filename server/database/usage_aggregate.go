@@ -0,0 +1,269 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 降采样数据的键
+const (
+	usageAggregatePrefix = "usage_agg:"          // 后接聚合区间起始Unix时间戳
+	usageWatermarkKey    = "usage_agg_watermark" // 降采样水位
+)
+
+// usageWatermark 降采样水位：截止时间之前且ID不超过MaxID的记录均已聚合过
+type usageWatermark struct {
+	Time  time.Time `json:"time"`  // 已降采样的截止时间
+	MaxID int       `json:"maxId"` // 已聚合记录的最大ID
+}
+
+// covers 判断记录是否已被聚合过（上游重复返回的旧记录），截止时间之前新出现的记录（ID更大）仍需聚合
+func (w usageWatermark) covers(usage models.UsageData) bool {
+	return usage.CreatedAt.Before(w.Time) && usage.ID <= w.MaxID
+}
+
+// usageKeyUnix 解析原始使用记录键（usage:<Unix时间戳>:<记录ID>）中的时间戳
+func usageKeyUnix(key []byte) (int64, bool) {
+	rest := strings.TrimPrefix(string(key), "usage:")
+	if i := strings.IndexByte(rest, ':'); i >= 0 {
+		rest = rest[:i]
+	}
+	unix, err := strconv.ParseInt(rest, 10, 64)
+	return unix, err == nil
+}
+
+// usageAggregateKey 生成聚合数据的存储键
+func usageAggregateKey(start time.Time) []byte {
+	return []byte(fmt.Sprintf("%s%d", usageAggregatePrefix, start.Unix()))
+}
+
+// DownsampleUsage 将早于cutoff的原始使用记录按15分钟聚合后删除，返回处理的原始记录数
+// 已聚合过的原始记录被上游重复返回并重新写入时只删除不重复累加，避免重复统计
+func (b *BadgerDB) DownsampleUsage(cutoff time.Time) (int, error) {
+	return b.downsampleUsage(cutoff, nil)
+}
+
+// DownsampleArchivedUsage 与DownsampleUsage相同，但只聚合并删除archived中的记录（已写入归档）
+// 读取待归档记录之后才写入的记录保留在数据库中，留待下次归档，避免未归档就被删除
+func (b *BadgerDB) DownsampleArchivedUsage(cutoff time.Time, archived models.UsageDataList) (int, error) {
+	keys := make(map[string]bool, len(archived))
+	for _, usage := range archived {
+		keys[usageKey(usage)] = true
+	}
+	return b.downsampleUsage(cutoff, keys)
+}
+
+// downsampleUsage 降采样早于cutoff的原始使用记录，only不为nil时只处理其中的记录（已聚合过的重复记录始终删除）
+func (b *BadgerDB) downsampleUsage(cutoff time.Time, only map[string]bool) (int, error) {
+	var processed int
+	keepDays := b.currentRetention().AggregateTTLDays()
+
+	err := b.db.Update(func(txn *badger.Txn) error {
+		watermark, err := getUsageWatermark(txn)
+		if err != nil {
+			return err
+		}
+
+		aggregates := make(map[int64]*models.UsageAggregate)
+		var keysToDelete [][]byte
+		limit := cutoff // 水位截止时间，不超过未处理记录中最早的时间，保证其下次仍会被处理
+
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		prefix := []byte("usage:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			// 键按时间戳排序，只需遍历到cutoff为止
+			if unix, ok := usageKeyUnix(item.Key()); ok && unix > cutoff.Unix() {
+				break
+			}
+
+			var usage models.UsageData
+			err := item.Value(func(val []byte) error {
+				return json.Unmarshal(val, &usage)
+			})
+			if err != nil || !usage.CreatedAt.Before(cutoff) {
+				continue
+			}
+			if watermark.covers(usage) {
+				keysToDelete = append(keysToDelete, item.KeyCopy(nil))
+				continue
+			}
+			if only != nil && !only[string(item.Key())] {
+				if usage.CreatedAt.Before(limit) {
+					limit = usage.CreatedAt
+				}
+				continue
+			}
+			keysToDelete = append(keysToDelete, item.KeyCopy(nil))
+
+			if usage.ID > watermark.MaxID {
+				watermark.MaxID = usage.ID
+			}
+			start := usage.CreatedAt.Truncate(models.UsageAggregateInterval)
+			aggregate, ok := aggregates[start.Unix()]
+			if !ok {
+				aggregate = &models.UsageAggregate{Start: start}
+				aggregates[start.Unix()] = aggregate
+			}
+			aggregate.Add(usage)
+		}
+		it.Close()
+
+		// 合并到已有的聚合数据中
+		for _, aggregate := range aggregates {
+			key := usageAggregateKey(aggregate.Start)
+			item, err := txn.Get(key)
+			if err != nil && err != badger.ErrKeyNotFound {
+				return err
+			}
+			if err == nil {
+				var existing models.UsageAggregate
+				if err := item.Value(func(val []byte) error {
+					return json.Unmarshal(val, &existing)
+				}); err != nil {
+					return err
+				}
+				aggregate.Credits += existing.Credits
+				aggregate.Count += existing.Count
+				for model, credits := range existing.ModelCredits {
+					aggregate.ModelCredits[model] += credits
+				}
+			}
+
+			data, err := json.Marshal(aggregate)
+			if err != nil {
+				return err
+			}
+			if err := setWithExpiry(txn, key, data, usageAggregateExpiresAt(aggregate.Start, keepDays)); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range keysToDelete {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		processed = len(keysToDelete)
+
+		if limit.After(watermark.Time) {
+			watermark.Time = limit
+		}
+		data, err := json.Marshal(watermark)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(usageWatermarkKey), data)
+	})
+	return processed, b.trackError(err)
+}
+
+// GetUnaggregatedUsage 获取早于cutoff且尚未降采样的原始使用记录（按时间升序），即下一次降采样将要聚合的记录
+func (b *BadgerDB) GetUnaggregatedUsage(cutoff time.Time) (models.UsageDataList, error) {
+	var usageList models.UsageDataList
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		watermark, err := getUsageWatermark(txn)
+		if err != nil {
+			return err
+		}
+
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte("usage:")
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if unix, ok := usageKeyUnix(it.Item().Key()); ok && unix > cutoff.Unix() {
+				break
+			}
+
+			var usage models.UsageData
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &usage)
+			})
+			if err != nil {
+				continue
+			}
+			if usage.CreatedAt.Before(cutoff) && !watermark.covers(usage) {
+				usageList = append(usageList, usage)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+
+	sort.Slice(usageList, func(i, j int) bool {
+		return usageList[i].CreatedAt.Before(usageList[j].CreatedAt)
+	})
+	return usageList, nil
+}
+
+// getUsageWatermark 读取降采样水位，未降采样过时返回零值
+func getUsageWatermark(txn *badger.Txn) (usageWatermark, error) {
+	var watermark usageWatermark
+	item, err := txn.Get([]byte(usageWatermarkKey))
+	if err == badger.ErrKeyNotFound {
+		return watermark, nil
+	}
+	if err != nil {
+		return watermark, err
+	}
+
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &watermark)
+	})
+	return watermark, err
+}
+
+// GetUsageAggregatedFunc 返回按当前降采样水位判断使用记录是否已被聚合过的函数
+func (b *BadgerDB) GetUsageAggregatedFunc() (func(models.UsageData) bool, error) {
+	var watermark usageWatermark
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		watermark, err = getUsageWatermark(txn)
+		return err
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return watermark.covers, nil
+}
+
+// GetUsageAggregates 获取指定时间之后的降采样聚合数据（按时间升序）
+func (b *BadgerDB) GetUsageAggregates(since time.Time) ([]models.UsageAggregate, error) {
+	var aggregates []models.UsageAggregate
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		prefix := []byte(usageAggregatePrefix)
+		start := usageAggregateKey(since.Truncate(models.UsageAggregateInterval))
+		for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+			var aggregate models.UsageAggregate
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &aggregate)
+			})
+			if err != nil {
+				log.Printf("解析降采样数据失败 %s: %v", it.Item().Key(), err)
+				continue
+			}
+			aggregates = append(aggregates, aggregate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, b.trackError(err)
+	}
+	return aggregates, nil
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestDownsampleArchivedUsage(t *testing.T) {
+	db := openTestDB(t)
+	cutoff := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+
+	archived := models.UsageDataList{
+		{ID: 10, CreditsUsed: 5, CreatedAt: cutoff.Add(-2 * time.Hour), Model: "claude"},
+		{ID: 11, CreditsUsed: 7, CreatedAt: cutoff.Add(-time.Hour), Model: "claude"},
+	}
+	// 读取待归档记录之后才写入的记录（ID较小，时间也在截止时间之前）
+	late := models.UsageData{ID: 9, CreditsUsed: 3, CreatedAt: cutoff.Add(-90 * time.Minute), Model: "claude"}
+	current := models.UsageData{ID: 12, CreditsUsed: 1, CreatedAt: cutoff.Add(time.Hour), Model: "claude"}
+
+	if err := db.SaveUsageData(append(archived, late, current)); err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err := db.DownsampleArchivedUsage(cutoff, archived)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed != len(archived) {
+		t.Fatalf("处理记录数 = %d, want %d", processed, len(archived))
+	}
+
+	// 未归档的记录保留，下次归档时仍会读取到
+	pending, err := db.GetUnaggregatedUsage(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != late.ID {
+		t.Fatalf("待归档记录 = %+v, want 仅ID %d", pending, late.ID)
+	}
+
+	// 再次归档后全部处理完毕，截止时间之后的记录不受影响
+	if _, err := db.DownsampleArchivedUsage(cutoff, pending); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := db.GetUnaggregatedUsage(cutoff); len(pending) != 0 {
+		t.Fatalf("仍有待归档记录: %+v", pending)
+	}
+	remaining, err := db.GetUnaggregatedUsage(cutoff.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != current.ID {
+		t.Fatalf("截止时间之后的记录 = %+v", remaining)
+	}
+}
@@ -0,0 +1,1214 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: cccmu.proto
+
+// CCCMU gRPC API（v1）
+// 与 /api/v1 的 REST + SSE 接口共享同一套服务，提供强类型契约和服务端流式推送
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_cccmu_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{0}
+}
+
+// AutoScheduleConfig 自动调度配置
+type AutoScheduleConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`                               // 是否启用自动调度
+	StartTime     string                 `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`           // 开启时间 "HH:MM"
+	EndTime       string                 `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`                 // 关闭时间 "HH:MM"
+	MonitoringOn  bool                   `protobuf:"varint,4,opt,name=monitoring_on,json=monitoringOn,proto3" json:"monitoring_on,omitempty"` // 时间范围内是开启还是关闭监控
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AutoScheduleConfig) Reset() {
+	*x = AutoScheduleConfig{}
+	mi := &file_cccmu_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutoScheduleConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutoScheduleConfig) ProtoMessage() {}
+
+func (x *AutoScheduleConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutoScheduleConfig.ProtoReflect.Descriptor instead.
+func (*AutoScheduleConfig) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AutoScheduleConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *AutoScheduleConfig) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *AutoScheduleConfig) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *AutoScheduleConfig) GetMonitoringOn() bool {
+	if x != nil {
+		return x.MonitoringOn
+	}
+	return false
+}
+
+// AutoResetConfig 自动重置配置
+type AutoResetConfig struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Enabled              bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`                                                         // 是否启用自动重置
+	TimeEnabled          bool                   `protobuf:"varint,2,opt,name=time_enabled,json=timeEnabled,proto3" json:"time_enabled,omitempty"`                              // 时间触发条件是否启用
+	ResetTime            string                 `protobuf:"bytes,3,opt,name=reset_time,json=resetTime,proto3" json:"reset_time,omitempty"`                                     // 重置时间 "HH:MM"
+	ThresholdEnabled     bool                   `protobuf:"varint,4,opt,name=threshold_enabled,json=thresholdEnabled,proto3" json:"threshold_enabled,omitempty"`               // 积分阈值触发是否启用
+	Threshold            int32                  `protobuf:"varint,5,opt,name=threshold,proto3" json:"threshold,omitempty"`                                                     // 积分阈值
+	ThresholdTimeEnabled bool                   `protobuf:"varint,6,opt,name=threshold_time_enabled,json=thresholdTimeEnabled,proto3" json:"threshold_time_enabled,omitempty"` // 阈值时间范围是否启用
+	ThresholdStartTime   string                 `protobuf:"bytes,7,opt,name=threshold_start_time,json=thresholdStartTime,proto3" json:"threshold_start_time,omitempty"`        // 阈值检查开始时间 "HH:MM"
+	ThresholdEndTime     string                 `protobuf:"bytes,8,opt,name=threshold_end_time,json=thresholdEndTime,proto3" json:"threshold_end_time,omitempty"`              // 阈值检查结束时间 "HH:MM"
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *AutoResetConfig) Reset() {
+	*x = AutoResetConfig{}
+	mi := &file_cccmu_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutoResetConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutoResetConfig) ProtoMessage() {}
+
+func (x *AutoResetConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutoResetConfig.ProtoReflect.Descriptor instead.
+func (*AutoResetConfig) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AutoResetConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *AutoResetConfig) GetTimeEnabled() bool {
+	if x != nil {
+		return x.TimeEnabled
+	}
+	return false
+}
+
+func (x *AutoResetConfig) GetResetTime() string {
+	if x != nil {
+		return x.ResetTime
+	}
+	return ""
+}
+
+func (x *AutoResetConfig) GetThresholdEnabled() bool {
+	if x != nil {
+		return x.ThresholdEnabled
+	}
+	return false
+}
+
+func (x *AutoResetConfig) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *AutoResetConfig) GetThresholdTimeEnabled() bool {
+	if x != nil {
+		return x.ThresholdTimeEnabled
+	}
+	return false
+}
+
+func (x *AutoResetConfig) GetThresholdStartTime() string {
+	if x != nil {
+		return x.ThresholdStartTime
+	}
+	return ""
+}
+
+func (x *AutoResetConfig) GetThresholdEndTime() string {
+	if x != nil {
+		return x.ThresholdEndTime
+	}
+	return ""
+}
+
+// KeepAliveConfig Cookie保活配置
+type KeepAliveConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`                                  // 是否启用Cookie保活
+	IntervalHours int32                  `protobuf:"varint,2,opt,name=interval_hours,json=intervalHours,proto3" json:"interval_hours,omitempty"` // 保活请求间隔(小时)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeepAliveConfig) Reset() {
+	*x = KeepAliveConfig{}
+	mi := &file_cccmu_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeepAliveConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeepAliveConfig) ProtoMessage() {}
+
+func (x *KeepAliveConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeepAliveConfig.ProtoReflect.Descriptor instead.
+func (*KeepAliveConfig) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *KeepAliveConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *KeepAliveConfig) GetIntervalHours() int32 {
+	if x != nil {
+		return x.IntervalHours
+	}
+	return 0
+}
+
+// Config 用户配置
+type Config struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	CookieConfigured         bool                   `protobuf:"varint,1,opt,name=cookie_configured,json=cookieConfigured,proto3" json:"cookie_configured,omitempty"`                           // Cookie是否已配置
+	Interval                 int32                  `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`                                                                   // 数据获取间隔(秒)
+	TimeRange                int32                  `protobuf:"varint,3,opt,name=time_range,json=timeRange,proto3" json:"time_range,omitempty"`                                                // 显示时间范围(分钟)
+	Enabled                  bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`                                                                     // 任务是否启用
+	LastCookieValidTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_cookie_valid_time,json=lastCookieValidTime,proto3" json:"last_cookie_valid_time,omitempty"`               // 最后一次Cookie验证成功时间
+	CookieValidationInterval int32                  `protobuf:"varint,6,opt,name=cookie_validation_interval,json=cookieValidationInterval,proto3" json:"cookie_validation_interval,omitempty"` // Cookie验证间隔(分钟)
+	DailyResetUsed           bool                   `protobuf:"varint,7,opt,name=daily_reset_used,json=dailyResetUsed,proto3" json:"daily_reset_used,omitempty"`                               // 当日重置是否已使用
+	DailyUsageEnabled        bool                   `protobuf:"varint,8,opt,name=daily_usage_enabled,json=dailyUsageEnabled,proto3" json:"daily_usage_enabled,omitempty"`                      // 是否启用每日积分使用量统计
+	AutoSchedule             *AutoScheduleConfig    `protobuf:"bytes,9,opt,name=auto_schedule,json=autoSchedule,proto3" json:"auto_schedule,omitempty"`                                        // 自动调度配置
+	AutoReset                *AutoResetConfig       `protobuf:"bytes,10,opt,name=auto_reset,json=autoReset,proto3" json:"auto_reset,omitempty"`                                                // 自动重置配置
+	KeepAlive                *KeepAliveConfig       `protobuf:"bytes,11,opt,name=keep_alive,json=keepAlive,proto3" json:"keep_alive,omitempty"`                                                // Cookie保活配置
+	Plan                     string                 `protobuf:"bytes,12,opt,name=plan,proto3" json:"plan,omitempty"`                                                                           // 订阅等级
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_cccmu_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Config) GetCookieConfigured() bool {
+	if x != nil {
+		return x.CookieConfigured
+	}
+	return false
+}
+
+func (x *Config) GetInterval() int32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *Config) GetTimeRange() int32 {
+	if x != nil {
+		return x.TimeRange
+	}
+	return 0
+}
+
+func (x *Config) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Config) GetLastCookieValidTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastCookieValidTime
+	}
+	return nil
+}
+
+func (x *Config) GetCookieValidationInterval() int32 {
+	if x != nil {
+		return x.CookieValidationInterval
+	}
+	return 0
+}
+
+func (x *Config) GetDailyResetUsed() bool {
+	if x != nil {
+		return x.DailyResetUsed
+	}
+	return false
+}
+
+func (x *Config) GetDailyUsageEnabled() bool {
+	if x != nil {
+		return x.DailyUsageEnabled
+	}
+	return false
+}
+
+func (x *Config) GetAutoSchedule() *AutoScheduleConfig {
+	if x != nil {
+		return x.AutoSchedule
+	}
+	return nil
+}
+
+func (x *Config) GetAutoReset() *AutoResetConfig {
+	if x != nil {
+		return x.AutoReset
+	}
+	return nil
+}
+
+func (x *Config) GetKeepAlive() *KeepAliveConfig {
+	if x != nil {
+		return x.KeepAlive
+	}
+	return nil
+}
+
+func (x *Config) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_cccmu_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{5}
+}
+
+type StartMonitoringRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartMonitoringRequest) Reset() {
+	*x = StartMonitoringRequest{}
+	mi := &file_cccmu_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartMonitoringRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartMonitoringRequest) ProtoMessage() {}
+
+func (x *StartMonitoringRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartMonitoringRequest.ProtoReflect.Descriptor instead.
+func (*StartMonitoringRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{6}
+}
+
+type StopMonitoringRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopMonitoringRequest) Reset() {
+	*x = StopMonitoringRequest{}
+	mi := &file_cccmu_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopMonitoringRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopMonitoringRequest) ProtoMessage() {}
+
+func (x *StopMonitoringRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopMonitoringRequest.ProtoReflect.Descriptor instead.
+func (*StopMonitoringRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{7}
+}
+
+// Status 监控任务状态
+type Status struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Running             bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`                                                        // 监控任务是否运行中
+	AutoScheduleEnabled bool                   `protobuf:"varint,2,opt,name=auto_schedule_enabled,json=autoScheduleEnabled,proto3" json:"auto_schedule_enabled,omitempty"`   // 是否启用自动调度
+	InAutoScheduleRange bool                   `protobuf:"varint,3,opt,name=in_auto_schedule_range,json=inAutoScheduleRange,proto3" json:"in_auto_schedule_range,omitempty"` // 当前是否处于自动调度时间范围内
+	Maintenance         bool                   `protobuf:"varint,4,opt,name=maintenance,proto3" json:"maintenance,omitempty"`                                                // 是否处于维护模式
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_cccmu_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Status) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *Status) GetAutoScheduleEnabled() bool {
+	if x != nil {
+		return x.AutoScheduleEnabled
+	}
+	return false
+}
+
+func (x *Status) GetInAutoScheduleRange() bool {
+	if x != nil {
+		return x.InAutoScheduleRange
+	}
+	return false
+}
+
+func (x *Status) GetMaintenance() bool {
+	if x != nil {
+		return x.Maintenance
+	}
+	return false
+}
+
+type RefreshRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
+	mi := &file_cccmu_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshRequest) ProtoMessage() {}
+
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{9}
+}
+
+type RefreshResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshResponse) Reset() {
+	*x = RefreshResponse{}
+	mi := &file_cccmu_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshResponse) ProtoMessage() {}
+
+func (x *RefreshResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshResponse.ProtoReflect.Descriptor instead.
+func (*RefreshResponse) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{10}
+}
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_cccmu_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{11}
+}
+
+// CreditBalance 积分余额
+type CreditBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Remaining     int32                  `protobuf:"varint,1,opt,name=remaining,proto3" json:"remaining,omitempty"`                 // 剩余积分
+	Plan          string                 `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`                            // 订阅等级
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // 更新时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreditBalance) Reset() {
+	*x = CreditBalance{}
+	mi := &file_cccmu_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditBalance) ProtoMessage() {}
+
+func (x *CreditBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditBalance.ProtoReflect.Descriptor instead.
+func (*CreditBalance) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreditBalance) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *CreditBalance) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *CreditBalance) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ResetCreditsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetCreditsRequest) Reset() {
+	*x = ResetCreditsRequest{}
+	mi := &file_cccmu_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetCreditsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetCreditsRequest) ProtoMessage() {}
+
+func (x *ResetCreditsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetCreditsRequest.ProtoReflect.Descriptor instead.
+func (*ResetCreditsRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{13}
+}
+
+type ResetCreditsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DailyResetUsed bool                   `protobuf:"varint,1,opt,name=daily_reset_used,json=dailyResetUsed,proto3" json:"daily_reset_used,omitempty"` // 当日重置是否已使用（成功后恒为true）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ResetCreditsResponse) Reset() {
+	*x = ResetCreditsResponse{}
+	mi := &file_cccmu_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetCreditsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetCreditsResponse) ProtoMessage() {}
+
+func (x *ResetCreditsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetCreditsResponse.ProtoReflect.Descriptor instead.
+func (*ResetCreditsResponse) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ResetCreditsResponse) GetDailyResetUsed() bool {
+	if x != nil {
+		return x.DailyResetUsed
+	}
+	return false
+}
+
+type StreamUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Minutes       int32                  `protobuf:"varint,1,opt,name=minutes,proto3" json:"minutes,omitempty"` // 时间范围(分钟)，未设置时默认60
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamUsageRequest) Reset() {
+	*x = StreamUsageRequest{}
+	mi := &file_cccmu_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamUsageRequest) ProtoMessage() {}
+
+func (x *StreamUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamUsageRequest.ProtoReflect.Descriptor instead.
+func (*StreamUsageRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamUsageRequest) GetMinutes() int32 {
+	if x != nil {
+		return x.Minutes
+	}
+	return 0
+}
+
+// UsageRecord 单条积分使用记录
+type UsageRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                      // 记录ID
+	CreditsUsed   int32                  `protobuf:"varint,2,opt,name=credits_used,json=creditsUsed,proto3" json:"credits_used,omitempty"` // 使用积分
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`        // 使用时间
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`                                 // 模型名称
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageRecord) Reset() {
+	*x = UsageRecord{}
+	mi := &file_cccmu_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageRecord) ProtoMessage() {}
+
+func (x *UsageRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageRecord.ProtoReflect.Descriptor instead.
+func (*UsageRecord) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *UsageRecord) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UsageRecord) GetCreditsUsed() int32 {
+	if x != nil {
+		return x.CreditsUsed
+	}
+	return 0
+}
+
+func (x *UsageRecord) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *UsageRecord) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+// UsageSnapshot 指定时间范围内的使用数据快照
+type UsageSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*UsageRecord         `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UsageSnapshot) Reset() {
+	*x = UsageSnapshot{}
+	mi := &file_cccmu_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageSnapshot) ProtoMessage() {}
+
+func (x *UsageSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageSnapshot.ProtoReflect.Descriptor instead.
+func (*UsageSnapshot) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UsageSnapshot) GetRecords() []*UsageRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type StreamBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBalanceRequest) Reset() {
+	*x = StreamBalanceRequest{}
+	mi := &file_cccmu_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBalanceRequest) ProtoMessage() {}
+
+func (x *StreamBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cccmu_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBalanceRequest.ProtoReflect.Descriptor instead.
+func (*StreamBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_cccmu_proto_rawDescGZIP(), []int{18}
+}
+
+var File_cccmu_proto protoreflect.FileDescriptor
+
+const file_cccmu_proto_rawDesc = "" +
+	"\n" +
+	"\vcccmu.proto\x12\bcccmu.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x12\n" +
+	"\x10GetConfigRequest\"\x8d\x01\n" +
+	"\x12AutoScheduleConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x03 \x01(\tR\aendTime\x12#\n" +
+	"\rmonitoring_on\x18\x04 \x01(\bR\fmonitoringOn\"\xce\x02\n" +
+	"\x0fAutoResetConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12!\n" +
+	"\ftime_enabled\x18\x02 \x01(\bR\vtimeEnabled\x12\x1d\n" +
+	"\n" +
+	"reset_time\x18\x03 \x01(\tR\tresetTime\x12+\n" +
+	"\x11threshold_enabled\x18\x04 \x01(\bR\x10thresholdEnabled\x12\x1c\n" +
+	"\tthreshold\x18\x05 \x01(\x05R\tthreshold\x124\n" +
+	"\x16threshold_time_enabled\x18\x06 \x01(\bR\x14thresholdTimeEnabled\x120\n" +
+	"\x14threshold_start_time\x18\a \x01(\tR\x12thresholdStartTime\x12,\n" +
+	"\x12threshold_end_time\x18\b \x01(\tR\x10thresholdEndTime\"R\n" +
+	"\x0fKeepAliveConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12%\n" +
+	"\x0einterval_hours\x18\x02 \x01(\x05R\rintervalHours\"\xbe\x04\n" +
+	"\x06Config\x12+\n" +
+	"\x11cookie_configured\x18\x01 \x01(\bR\x10cookieConfigured\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\x05R\binterval\x12\x1d\n" +
+	"\n" +
+	"time_range\x18\x03 \x01(\x05R\ttimeRange\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\x12O\n" +
+	"\x16last_cookie_valid_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x13lastCookieValidTime\x12<\n" +
+	"\x1acookie_validation_interval\x18\x06 \x01(\x05R\x18cookieValidationInterval\x12(\n" +
+	"\x10daily_reset_used\x18\a \x01(\bR\x0edailyResetUsed\x12.\n" +
+	"\x13daily_usage_enabled\x18\b \x01(\bR\x11dailyUsageEnabled\x12A\n" +
+	"\rauto_schedule\x18\t \x01(\v2\x1c.cccmu.v1.AutoScheduleConfigR\fautoSchedule\x128\n" +
+	"\n" +
+	"auto_reset\x18\n" +
+	" \x01(\v2\x19.cccmu.v1.AutoResetConfigR\tautoReset\x128\n" +
+	"\n" +
+	"keep_alive\x18\v \x01(\v2\x19.cccmu.v1.KeepAliveConfigR\tkeepAlive\x12\x12\n" +
+	"\x04plan\x18\f \x01(\tR\x04plan\"\x12\n" +
+	"\x10GetStatusRequest\"\x18\n" +
+	"\x16StartMonitoringRequest\"\x17\n" +
+	"\x15StopMonitoringRequest\"\xad\x01\n" +
+	"\x06Status\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x122\n" +
+	"\x15auto_schedule_enabled\x18\x02 \x01(\bR\x13autoScheduleEnabled\x123\n" +
+	"\x16in_auto_schedule_range\x18\x03 \x01(\bR\x13inAutoScheduleRange\x12 \n" +
+	"\vmaintenance\x18\x04 \x01(\bR\vmaintenance\"\x10\n" +
+	"\x0eRefreshRequest\"\x11\n" +
+	"\x0fRefreshResponse\"\x13\n" +
+	"\x11GetBalanceRequest\"|\n" +
+	"\rCreditBalance\x12\x1c\n" +
+	"\tremaining\x18\x01 \x01(\x05R\tremaining\x12\x12\n" +
+	"\x04plan\x18\x02 \x01(\tR\x04plan\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x15\n" +
+	"\x13ResetCreditsRequest\"@\n" +
+	"\x14ResetCreditsResponse\x12(\n" +
+	"\x10daily_reset_used\x18\x01 \x01(\bR\x0edailyResetUsed\".\n" +
+	"\x12StreamUsageRequest\x12\x18\n" +
+	"\aminutes\x18\x01 \x01(\x05R\aminutes\"\x91\x01\n" +
+	"\vUsageRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12!\n" +
+	"\fcredits_used\x18\x02 \x01(\x05R\vcreditsUsed\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\"@\n" +
+	"\rUsageSnapshot\x12/\n" +
+	"\arecords\x18\x01 \x03(\v2\x15.cccmu.v1.UsageRecordR\arecords\"\x16\n" +
+	"\x14StreamBalanceRequest2\xf7\x04\n" +
+	"\fCCCMUService\x129\n" +
+	"\tGetConfig\x12\x1a.cccmu.v1.GetConfigRequest\x1a\x10.cccmu.v1.Config\x129\n" +
+	"\tGetStatus\x12\x1a.cccmu.v1.GetStatusRequest\x1a\x10.cccmu.v1.Status\x12E\n" +
+	"\x0fStartMonitoring\x12 .cccmu.v1.StartMonitoringRequest\x1a\x10.cccmu.v1.Status\x12C\n" +
+	"\x0eStopMonitoring\x12\x1f.cccmu.v1.StopMonitoringRequest\x1a\x10.cccmu.v1.Status\x12>\n" +
+	"\aRefresh\x12\x18.cccmu.v1.RefreshRequest\x1a\x19.cccmu.v1.RefreshResponse\x12B\n" +
+	"\n" +
+	"GetBalance\x12\x1b.cccmu.v1.GetBalanceRequest\x1a\x17.cccmu.v1.CreditBalance\x12M\n" +
+	"\fResetCredits\x12\x1d.cccmu.v1.ResetCreditsRequest\x1a\x1e.cccmu.v1.ResetCreditsResponse\x12F\n" +
+	"\vStreamUsage\x12\x1c.cccmu.v1.StreamUsageRequest\x1a\x17.cccmu.v1.UsageSnapshot0\x01\x12J\n" +
+	"\rStreamBalance\x12\x1e.cccmu.v1.StreamBalanceRequest\x1a\x17.cccmu.v1.CreditBalance0\x01B,Z*github.com/leafney/cccmu/server/grpcapi/pbb\x06proto3"
+
+var (
+	file_cccmu_proto_rawDescOnce sync.Once
+	file_cccmu_proto_rawDescData []byte
+)
+
+func file_cccmu_proto_rawDescGZIP() []byte {
+	file_cccmu_proto_rawDescOnce.Do(func() {
+		file_cccmu_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cccmu_proto_rawDesc), len(file_cccmu_proto_rawDesc)))
+	})
+	return file_cccmu_proto_rawDescData
+}
+
+var file_cccmu_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_cccmu_proto_goTypes = []any{
+	(*GetConfigRequest)(nil),       // 0: cccmu.v1.GetConfigRequest
+	(*AutoScheduleConfig)(nil),     // 1: cccmu.v1.AutoScheduleConfig
+	(*AutoResetConfig)(nil),        // 2: cccmu.v1.AutoResetConfig
+	(*KeepAliveConfig)(nil),        // 3: cccmu.v1.KeepAliveConfig
+	(*Config)(nil),                 // 4: cccmu.v1.Config
+	(*GetStatusRequest)(nil),       // 5: cccmu.v1.GetStatusRequest
+	(*StartMonitoringRequest)(nil), // 6: cccmu.v1.StartMonitoringRequest
+	(*StopMonitoringRequest)(nil),  // 7: cccmu.v1.StopMonitoringRequest
+	(*Status)(nil),                 // 8: cccmu.v1.Status
+	(*RefreshRequest)(nil),         // 9: cccmu.v1.RefreshRequest
+	(*RefreshResponse)(nil),        // 10: cccmu.v1.RefreshResponse
+	(*GetBalanceRequest)(nil),      // 11: cccmu.v1.GetBalanceRequest
+	(*CreditBalance)(nil),          // 12: cccmu.v1.CreditBalance
+	(*ResetCreditsRequest)(nil),    // 13: cccmu.v1.ResetCreditsRequest
+	(*ResetCreditsResponse)(nil),   // 14: cccmu.v1.ResetCreditsResponse
+	(*StreamUsageRequest)(nil),     // 15: cccmu.v1.StreamUsageRequest
+	(*UsageRecord)(nil),            // 16: cccmu.v1.UsageRecord
+	(*UsageSnapshot)(nil),          // 17: cccmu.v1.UsageSnapshot
+	(*StreamBalanceRequest)(nil),   // 18: cccmu.v1.StreamBalanceRequest
+	(*timestamppb.Timestamp)(nil),  // 19: google.protobuf.Timestamp
+}
+var file_cccmu_proto_depIdxs = []int32{
+	19, // 0: cccmu.v1.Config.last_cookie_valid_time:type_name -> google.protobuf.Timestamp
+	1,  // 1: cccmu.v1.Config.auto_schedule:type_name -> cccmu.v1.AutoScheduleConfig
+	2,  // 2: cccmu.v1.Config.auto_reset:type_name -> cccmu.v1.AutoResetConfig
+	3,  // 3: cccmu.v1.Config.keep_alive:type_name -> cccmu.v1.KeepAliveConfig
+	19, // 4: cccmu.v1.CreditBalance.updated_at:type_name -> google.protobuf.Timestamp
+	19, // 5: cccmu.v1.UsageRecord.created_at:type_name -> google.protobuf.Timestamp
+	16, // 6: cccmu.v1.UsageSnapshot.records:type_name -> cccmu.v1.UsageRecord
+	0,  // 7: cccmu.v1.CCCMUService.GetConfig:input_type -> cccmu.v1.GetConfigRequest
+	5,  // 8: cccmu.v1.CCCMUService.GetStatus:input_type -> cccmu.v1.GetStatusRequest
+	6,  // 9: cccmu.v1.CCCMUService.StartMonitoring:input_type -> cccmu.v1.StartMonitoringRequest
+	7,  // 10: cccmu.v1.CCCMUService.StopMonitoring:input_type -> cccmu.v1.StopMonitoringRequest
+	9,  // 11: cccmu.v1.CCCMUService.Refresh:input_type -> cccmu.v1.RefreshRequest
+	11, // 12: cccmu.v1.CCCMUService.GetBalance:input_type -> cccmu.v1.GetBalanceRequest
+	13, // 13: cccmu.v1.CCCMUService.ResetCredits:input_type -> cccmu.v1.ResetCreditsRequest
+	15, // 14: cccmu.v1.CCCMUService.StreamUsage:input_type -> cccmu.v1.StreamUsageRequest
+	18, // 15: cccmu.v1.CCCMUService.StreamBalance:input_type -> cccmu.v1.StreamBalanceRequest
+	4,  // 16: cccmu.v1.CCCMUService.GetConfig:output_type -> cccmu.v1.Config
+	8,  // 17: cccmu.v1.CCCMUService.GetStatus:output_type -> cccmu.v1.Status
+	8,  // 18: cccmu.v1.CCCMUService.StartMonitoring:output_type -> cccmu.v1.Status
+	8,  // 19: cccmu.v1.CCCMUService.StopMonitoring:output_type -> cccmu.v1.Status
+	10, // 20: cccmu.v1.CCCMUService.Refresh:output_type -> cccmu.v1.RefreshResponse
+	12, // 21: cccmu.v1.CCCMUService.GetBalance:output_type -> cccmu.v1.CreditBalance
+	14, // 22: cccmu.v1.CCCMUService.ResetCredits:output_type -> cccmu.v1.ResetCreditsResponse
+	17, // 23: cccmu.v1.CCCMUService.StreamUsage:output_type -> cccmu.v1.UsageSnapshot
+	12, // 24: cccmu.v1.CCCMUService.StreamBalance:output_type -> cccmu.v1.CreditBalance
+	16, // [16:25] is the sub-list for method output_type
+	7,  // [7:16] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_cccmu_proto_init() }
+func file_cccmu_proto_init() {
+	if File_cccmu_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cccmu_proto_rawDesc), len(file_cccmu_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cccmu_proto_goTypes,
+		DependencyIndexes: file_cccmu_proto_depIdxs,
+		MessageInfos:      file_cccmu_proto_msgTypes,
+	}.Build()
+	File_cccmu_proto = out.File
+	file_cccmu_proto_goTypes = nil
+	file_cccmu_proto_depIdxs = nil
+}
@@ -0,0 +1,459 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: cccmu.proto
+
+// CCCMU gRPC API（v1）
+// 与 /api/v1 的 REST + SSE 接口共享同一套服务，提供强类型契约和服务端流式推送
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CCCMUService_GetConfig_FullMethodName       = "/cccmu.v1.CCCMUService/GetConfig"
+	CCCMUService_GetStatus_FullMethodName       = "/cccmu.v1.CCCMUService/GetStatus"
+	CCCMUService_StartMonitoring_FullMethodName = "/cccmu.v1.CCCMUService/StartMonitoring"
+	CCCMUService_StopMonitoring_FullMethodName  = "/cccmu.v1.CCCMUService/StopMonitoring"
+	CCCMUService_Refresh_FullMethodName         = "/cccmu.v1.CCCMUService/Refresh"
+	CCCMUService_GetBalance_FullMethodName      = "/cccmu.v1.CCCMUService/GetBalance"
+	CCCMUService_ResetCredits_FullMethodName    = "/cccmu.v1.CCCMUService/ResetCredits"
+	CCCMUService_StreamUsage_FullMethodName     = "/cccmu.v1.CCCMUService/StreamUsage"
+	CCCMUService_StreamBalance_FullMethodName   = "/cccmu.v1.CCCMUService/StreamBalance"
+)
+
+// CCCMUServiceClient is the client API for CCCMUService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CCCMUService 积分监控核心操作
+// 所有调用均需在 metadata 中携带访问密钥：authorization: Bearer <访问密钥>
+type CCCMUServiceClient interface {
+	// GetConfig 获取当前配置（Cookie仅返回是否已配置）
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*Config, error)
+	// GetStatus 获取监控任务状态
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// StartMonitoring 启动监控任务
+	StartMonitoring(ctx context.Context, in *StartMonitoringRequest, opts ...grpc.CallOption) (*Status, error)
+	// StopMonitoring 停止监控任务
+	StopMonitoring(ctx context.Context, in *StopMonitoringRequest, opts ...grpc.CallOption) (*Status, error)
+	// Refresh 立即刷新使用数据和积分余额
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error)
+	// GetBalance 获取最新积分余额
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*CreditBalance, error)
+	// ResetCredits 重置积分（每日一次）
+	ResetCredits(ctx context.Context, in *ResetCreditsRequest, opts ...grpc.CallOption) (*ResetCreditsResponse, error)
+	// StreamUsage 订阅使用数据，连接后立即推送当前数据，之后每次数据更新时推送
+	StreamUsage(ctx context.Context, in *StreamUsageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UsageSnapshot], error)
+	// StreamBalance 订阅积分余额，连接后立即推送当前余额，之后每次余额更新时推送
+	StreamBalance(ctx context.Context, in *StreamBalanceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CreditBalance], error)
+}
+
+type cCCMUServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCCCMUServiceClient(cc grpc.ClientConnInterface) CCCMUServiceClient {
+	return &cCCMUServiceClient{cc}
+}
+
+func (c *cCCMUServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*Config, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Config)
+	err := c.cc.Invoke(ctx, CCCMUService_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, CCCMUService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) StartMonitoring(ctx context.Context, in *StartMonitoringRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, CCCMUService_StartMonitoring_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) StopMonitoring(ctx context.Context, in *StopMonitoringRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, CCCMUService_StopMonitoring_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*RefreshResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshResponse)
+	err := c.cc.Invoke(ctx, CCCMUService_Refresh_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*CreditBalance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreditBalance)
+	err := c.cc.Invoke(ctx, CCCMUService_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) ResetCredits(ctx context.Context, in *ResetCreditsRequest, opts ...grpc.CallOption) (*ResetCreditsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetCreditsResponse)
+	err := c.cc.Invoke(ctx, CCCMUService_ResetCredits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cCCMUServiceClient) StreamUsage(ctx context.Context, in *StreamUsageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[UsageSnapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CCCMUService_ServiceDesc.Streams[0], CCCMUService_StreamUsage_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUsageRequest, UsageSnapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CCCMUService_StreamUsageClient = grpc.ServerStreamingClient[UsageSnapshot]
+
+func (c *cCCMUServiceClient) StreamBalance(ctx context.Context, in *StreamBalanceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CreditBalance], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CCCMUService_ServiceDesc.Streams[1], CCCMUService_StreamBalance_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamBalanceRequest, CreditBalance]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CCCMUService_StreamBalanceClient = grpc.ServerStreamingClient[CreditBalance]
+
+// CCCMUServiceServer is the server API for CCCMUService service.
+// All implementations must embed UnimplementedCCCMUServiceServer
+// for forward compatibility.
+//
+// CCCMUService 积分监控核心操作
+// 所有调用均需在 metadata 中携带访问密钥：authorization: Bearer <访问密钥>
+type CCCMUServiceServer interface {
+	// GetConfig 获取当前配置（Cookie仅返回是否已配置）
+	GetConfig(context.Context, *GetConfigRequest) (*Config, error)
+	// GetStatus 获取监控任务状态
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// StartMonitoring 启动监控任务
+	StartMonitoring(context.Context, *StartMonitoringRequest) (*Status, error)
+	// StopMonitoring 停止监控任务
+	StopMonitoring(context.Context, *StopMonitoringRequest) (*Status, error)
+	// Refresh 立即刷新使用数据和积分余额
+	Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error)
+	// GetBalance 获取最新积分余额
+	GetBalance(context.Context, *GetBalanceRequest) (*CreditBalance, error)
+	// ResetCredits 重置积分（每日一次）
+	ResetCredits(context.Context, *ResetCreditsRequest) (*ResetCreditsResponse, error)
+	// StreamUsage 订阅使用数据，连接后立即推送当前数据，之后每次数据更新时推送
+	StreamUsage(*StreamUsageRequest, grpc.ServerStreamingServer[UsageSnapshot]) error
+	// StreamBalance 订阅积分余额，连接后立即推送当前余额，之后每次余额更新时推送
+	StreamBalance(*StreamBalanceRequest, grpc.ServerStreamingServer[CreditBalance]) error
+	mustEmbedUnimplementedCCCMUServiceServer()
+}
+
+// UnimplementedCCCMUServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCCCMUServiceServer struct{}
+
+func (UnimplementedCCCMUServiceServer) GetConfig(context.Context, *GetConfigRequest) (*Config, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedCCCMUServiceServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedCCCMUServiceServer) StartMonitoring(context.Context, *StartMonitoringRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartMonitoring not implemented")
+}
+func (UnimplementedCCCMUServiceServer) StopMonitoring(context.Context, *StopMonitoringRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopMonitoring not implemented")
+}
+func (UnimplementedCCCMUServiceServer) Refresh(context.Context, *RefreshRequest) (*RefreshResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
+func (UnimplementedCCCMUServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*CreditBalance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedCCCMUServiceServer) ResetCredits(context.Context, *ResetCreditsRequest) (*ResetCreditsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetCredits not implemented")
+}
+func (UnimplementedCCCMUServiceServer) StreamUsage(*StreamUsageRequest, grpc.ServerStreamingServer[UsageSnapshot]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUsage not implemented")
+}
+func (UnimplementedCCCMUServiceServer) StreamBalance(*StreamBalanceRequest, grpc.ServerStreamingServer[CreditBalance]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBalance not implemented")
+}
+func (UnimplementedCCCMUServiceServer) mustEmbedUnimplementedCCCMUServiceServer() {}
+func (UnimplementedCCCMUServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeCCCMUServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CCCMUServiceServer will
+// result in compilation errors.
+type UnsafeCCCMUServiceServer interface {
+	mustEmbedUnimplementedCCCMUServiceServer()
+}
+
+func RegisterCCCMUServiceServer(s grpc.ServiceRegistrar, srv CCCMUServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCCCMUServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CCCMUService_ServiceDesc, srv)
+}
+
+func _CCCMUService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_StartMonitoring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartMonitoringRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).StartMonitoring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_StartMonitoring_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).StartMonitoring(ctx, req.(*StartMonitoringRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_StopMonitoring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopMonitoringRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).StopMonitoring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_StopMonitoring_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).StopMonitoring(ctx, req.(*StopMonitoringRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_Refresh_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).Refresh(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_ResetCredits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetCreditsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CCCMUServiceServer).ResetCredits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CCCMUService_ResetCredits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CCCMUServiceServer).ResetCredits(ctx, req.(*ResetCreditsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CCCMUService_StreamUsage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUsageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CCCMUServiceServer).StreamUsage(m, &grpc.GenericServerStream[StreamUsageRequest, UsageSnapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CCCMUService_StreamUsageServer = grpc.ServerStreamingServer[UsageSnapshot]
+
+func _CCCMUService_StreamBalance_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBalanceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CCCMUServiceServer).StreamBalance(m, &grpc.GenericServerStream[StreamBalanceRequest, CreditBalance]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CCCMUService_StreamBalanceServer = grpc.ServerStreamingServer[CreditBalance]
+
+// CCCMUService_ServiceDesc is the grpc.ServiceDesc for CCCMUService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CCCMUService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cccmu.v1.CCCMUService",
+	HandlerType: (*CCCMUServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler:    _CCCMUService_GetConfig_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _CCCMUService_GetStatus_Handler,
+		},
+		{
+			MethodName: "StartMonitoring",
+			Handler:    _CCCMUService_StartMonitoring_Handler,
+		},
+		{
+			MethodName: "StopMonitoring",
+			Handler:    _CCCMUService_StopMonitoring_Handler,
+		},
+		{
+			MethodName: "Refresh",
+			Handler:    _CCCMUService_Refresh_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _CCCMUService_GetBalance_Handler,
+		},
+		{
+			MethodName: "ResetCredits",
+			Handler:    _CCCMUService_ResetCredits_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUsage",
+			Handler:       _CCCMUService_StreamUsage_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamBalance",
+			Handler:       _CCCMUService_StreamBalance_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cccmu.proto",
+}
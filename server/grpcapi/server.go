@@ -0,0 +1,424 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/grpcapi/pb"
+	"github.com/leafney/cccmu/server/middleware"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// defaultStreamMinutes 使用数据流未指定时间范围时的默认值（与SSE接口一致）
+const defaultStreamMinutes = 60
+
+// Server gRPC API服务
+// 与REST接口共享调度器和数据库，流式接口基于调度器的监听器实现
+type Server struct {
+	pb.UnimplementedCCCMUServiceServer
+
+	db          *database.BadgerDB
+	scheduler   *services.SchedulerService
+	authManager *auth.Manager
+	limiter     *middleware.RateLimiter // 变更类调用限流（与HTTP接口共享）
+	grpcServer  *grpc.Server
+	shutdownCh  chan struct{} // 关闭时通知流式调用结束
+}
+
+// NewServer 创建gRPC API服务，limiter 为与HTTP变更类接口共享的限流器
+func NewServer(db *database.BadgerDB, scheduler *services.SchedulerService, authManager *auth.Manager, limiter *middleware.RateLimiter) *Server {
+	s := &Server{
+		db:          db,
+		scheduler:   scheduler,
+		authManager: authManager,
+		limiter:     limiter,
+		shutdownCh:  make(chan struct{}),
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuthInterceptor),
+		grpc.StreamInterceptor(s.streamAuthInterceptor),
+	)
+	pb.RegisterCCCMUServiceServer(s.grpcServer, s)
+
+	return s
+}
+
+// Start 在指定地址上启动gRPC监听（非阻塞）
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听gRPC端口失败: %w", err)
+	}
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			log.Printf("[gRPC] 服务异常退出: %v", err)
+		}
+	}()
+
+	log.Printf("[gRPC] 服务已启动: %s", listener.Addr())
+	return nil
+}
+
+// Stop 优雅停止gRPC服务：先结束流式调用，再等待普通调用完成，超时后强制关闭
+func (s *Server) Stop(timeout time.Duration) {
+	close(s.shutdownCh)
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("[gRPC] 服务已停止")
+	case <-time.After(timeout):
+		s.grpcServer.Stop()
+		log.Println("[gRPC] 等待调用结束超时，已强制停止")
+	}
+}
+
+// GetConfig 获取当前配置
+func (s *Server) GetConfig(ctx context.Context, _ *pb.GetConfigRequest) (*pb.Config, error) {
+	config, err := s.db.GetConfig()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "获取配置失败: %v", err)
+	}
+
+	result := &pb.Config{
+		CookieConfigured:         config.Cookie != "",
+		Interval:                 int32(config.Interval),
+		TimeRange:                int32(config.TimeRange),
+		Enabled:                  config.Enabled,
+		CookieValidationInterval: int32(config.CookieValidationInterval),
+		DailyResetUsed:           config.IsDailyResetUsed(time.Now()),
+		DailyUsageEnabled:        config.DailyUsageEnabled,
+		AutoSchedule: &pb.AutoScheduleConfig{
+			Enabled:      config.AutoSchedule.Enabled,
+			StartTime:    config.AutoSchedule.StartTime,
+			EndTime:      config.AutoSchedule.EndTime,
+			MonitoringOn: config.AutoSchedule.MonitoringOn,
+		},
+		AutoReset: &pb.AutoResetConfig{
+			Enabled:              config.AutoReset.Enabled,
+			TimeEnabled:          config.AutoReset.TimeEnabled,
+			ResetTime:            config.AutoReset.ResetTime,
+			ThresholdEnabled:     config.AutoReset.ThresholdEnabled,
+			Threshold:            int32(config.AutoReset.Threshold),
+			ThresholdTimeEnabled: config.AutoReset.ThresholdTimeEnabled,
+			ThresholdStartTime:   config.AutoReset.ThresholdStartTime,
+			ThresholdEndTime:     config.AutoReset.ThresholdEndTime,
+		},
+		KeepAlive: &pb.KeepAliveConfig{
+			Enabled:       config.KeepAlive.Enabled,
+			IntervalHours: int32(config.KeepAlive.IntervalHours),
+		},
+	}
+	if !config.LastCookieValidTime.IsZero() {
+		result.LastCookieValidTime = timestamppb.New(config.LastCookieValidTime)
+	}
+
+	// 订阅等级优先从BadgerDB获取持久化数据
+	if balance, err := s.db.GetCreditBalance(); err == nil && balance != nil {
+		result.Plan = balance.Plan
+	} else if balance := s.scheduler.GetLatestBalance(); balance != nil {
+		result.Plan = balance.Plan
+	}
+
+	return result, nil
+}
+
+// GetStatus 获取监控任务状态
+func (s *Server) GetStatus(ctx context.Context, _ *pb.GetStatusRequest) (*pb.Status, error) {
+	return s.currentStatus(), nil
+}
+
+// StartMonitoring 启动监控任务
+func (s *Server) StartMonitoring(ctx context.Context, _ *pb.StartMonitoringRequest) (*pb.Status, error) {
+	if err := s.scheduler.Start(); err != nil {
+		log.Printf("[gRPC] 启动任务失败: %v", err)
+		return nil, status.Errorf(codes.FailedPrecondition, "启动任务失败: %v", err)
+	}
+
+	log.Println("[gRPC] 定时任务已启动")
+	return s.currentStatus(), nil
+}
+
+// StopMonitoring 停止监控任务
+func (s *Server) StopMonitoring(ctx context.Context, _ *pb.StopMonitoringRequest) (*pb.Status, error) {
+	if err := s.scheduler.Stop(); err != nil {
+		log.Printf("[gRPC] 停止任务失败: %v", err)
+		return nil, status.Errorf(codes.FailedPrecondition, "停止任务失败: %v", err)
+	}
+
+	log.Println("[gRPC] 定时任务已停止")
+	return s.currentStatus(), nil
+}
+
+// Refresh 立即刷新使用数据和积分余额
+func (s *Server) Refresh(ctx context.Context, _ *pb.RefreshRequest) (*pb.RefreshResponse, error) {
+	if err := s.rateLimit(); err != nil {
+		return nil, err
+	}
+
+	// 客户端断开或超过时限时取消进行中的上游请求
+	ctx, cancel := context.WithTimeout(ctx, services.ManualUpstreamTimeout)
+	defer cancel()
+	if err := s.scheduler.FetchAllDataManually(ctx); err != nil {
+		log.Printf("[gRPC] 手动刷新所有数据失败: %v", err)
+		return nil, schedulerError("刷新数据失败", err)
+	}
+
+	return &pb.RefreshResponse{}, nil
+}
+
+// GetBalance 获取最新积分余额
+func (s *Server) GetBalance(ctx context.Context, _ *pb.GetBalanceRequest) (*pb.CreditBalance, error) {
+	balance := s.scheduler.GetLatestBalance()
+	if balance == nil {
+		return nil, status.Error(codes.NotFound, "暂无积分余额数据")
+	}
+
+	return toPBBalance(balance), nil
+}
+
+// ResetCredits 重置积分
+func (s *Server) ResetCredits(ctx context.Context, _ *pb.ResetCreditsRequest) (*pb.ResetCreditsResponse, error) {
+	if err := s.rateLimit(); err != nil {
+		return nil, err
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "获取配置失败: %v", err)
+	}
+	if config.Cookie == "" {
+		return nil, status.Error(codes.FailedPrecondition, "请先配置Cookie")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, services.ManualUpstreamTimeout)
+	defer cancel()
+	if err := s.scheduler.ResetCreditsManually(ctx); err != nil {
+		log.Printf("[gRPC] 重置积分失败: %v", err)
+		return nil, schedulerError("重置积分失败", err)
+	}
+
+	return &pb.ResetCreditsResponse{DailyResetUsed: true}, nil
+}
+
+// StreamUsage 推送使用数据，直到客户端断开或服务关闭
+func (s *Server) StreamUsage(req *pb.StreamUsageRequest, stream grpc.ServerStreamingServer[pb.UsageSnapshot]) error {
+	minutes := int(req.GetMinutes())
+	if minutes <= 0 {
+		minutes = defaultStreamMinutes
+	}
+
+	listener := s.scheduler.AddDataListener()
+	defer s.scheduler.RemoveDataListener(listener)
+
+	// 立即发送当前数据
+	if err := stream.Send(toPBSnapshot(s.scheduler.GetLatestData(), minutes)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case data, ok := <-listener:
+			if !ok {
+				return nil // 监听器已关闭
+			}
+			if err := stream.Send(toPBSnapshot(data, minutes)); err != nil {
+				return err
+			}
+
+		case <-s.shutdownCh:
+			return status.Error(codes.Unavailable, "服务正在关闭")
+
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// StreamBalance 推送积分余额，直到客户端断开或服务关闭
+func (s *Server) StreamBalance(_ *pb.StreamBalanceRequest, stream grpc.ServerStreamingServer[pb.CreditBalance]) error {
+	listener := s.scheduler.AddBalanceListener()
+	defer s.scheduler.RemoveBalanceListener(listener)
+
+	// 立即发送当前余额
+	if balance := s.scheduler.GetLatestBalance(); balance != nil {
+		if err := stream.Send(toPBBalance(balance)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case balance, ok := <-listener:
+			if !ok {
+				return nil // 监听器已关闭
+			}
+			if balance == nil {
+				continue
+			}
+			if err := stream.Send(toPBBalance(balance)); err != nil {
+				return err
+			}
+
+		case <-s.shutdownCh:
+			return status.Error(codes.Unavailable, "服务正在关闭")
+
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// currentStatus 汇总当前监控任务状态
+func (s *Server) currentStatus() *pb.Status {
+	return &pb.Status{
+		Running:             s.scheduler.IsRunning(),
+		AutoScheduleEnabled: s.scheduler.IsAutoScheduleEnabled(),
+		InAutoScheduleRange: s.scheduler.IsInAutoScheduleTimeRange(),
+		Maintenance:         s.scheduler.IsInMaintenance(),
+	}
+}
+
+// unaryAuthInterceptor 普通调用的访问密钥校验
+func (s *Server) unaryAuthInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuthInterceptor 流式调用的访问密钥校验
+func (s *Server) streamAuthInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authorize 从metadata中读取访问密钥（authorization: Bearer <key>）并校验
+// 与HTTP接口共用按IP的认证失败计数，失败次数过多时暂时拒绝，避免借gRPC暴力猜测访问密钥
+func (s *Server) authorize(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "缺少访问密钥")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "缺少访问密钥")
+	}
+
+	ip := peerIP(ctx)
+	if blocked, wait := s.authManager.AuthBlocked(ip); blocked {
+		return status.Errorf(codes.ResourceExhausted, "认证失败次数过多，请在%d秒后重试", int(math.Ceil(wait.Seconds())))
+	}
+
+	key := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+	if key == "" || !s.authManager.ValidateKey(key) {
+		var userAgent string
+		if agents := md.Get("user-agent"); len(agents) > 0 {
+			userAgent = agents[0]
+		}
+		s.authManager.AuthFailed(ip, userAgent, models.LoginFailureGRPC)
+		return status.Error(codes.Unauthenticated, "访问密钥无效")
+	}
+
+	s.authManager.ClearAuthFailures(ip)
+	return nil
+}
+
+// rateLimit 变更类调用限流，gRPC调用均使用访问密钥认证，与HTTP接口的访问密钥调用共享计数
+func (s *Server) rateLimit() error {
+	if s.limiter == nil {
+		return nil
+	}
+	if allowed, wait := s.limiter.Allow(middleware.RateLimitKeyAuth); !allowed {
+		return status.Errorf(codes.ResourceExhausted, "请求过于频繁，请在%d秒后重试", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// peerIP 获取调用方IP（取不到时返回空字符串）
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// schedulerError 将调度器错误转换为gRPC状态，上游请求错误按错误码选择状态码
+func schedulerError(message string, err error) error {
+	if errors.Is(err, services.ErrMaintenanceMode) {
+		return status.Errorf(codes.Unavailable, "%s: %v", message, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Errorf(codes.Canceled, "%s: %v", message, err)
+	}
+	switch client.ErrorCode(err) {
+	case models.ErrCookieInvalid, models.ErrCookieMissing:
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", message, err)
+	case models.ErrUpstreamTimeout:
+		return status.Errorf(codes.DeadlineExceeded, "%s: %v", message, err)
+	case models.ErrRateLimited:
+		return status.Errorf(codes.ResourceExhausted, "%s: %v", message, err)
+	case models.ErrAPIRequest, models.ErrDataParsing:
+		return status.Errorf(codes.Unavailable, "%s: %v", message, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+// toPBBalance 转换积分余额
+func toPBBalance(balance *models.CreditBalance) *pb.CreditBalance {
+	return &pb.CreditBalance{
+		Remaining: int32(balance.Remaining),
+		Plan:      balance.Plan,
+		UpdatedAt: timestamppb.New(balance.UpdatedAt),
+	}
+}
+
+// toPBSnapshot 按时间范围过滤并转换使用数据
+func toPBSnapshot(data []models.UsageData, minutes int) *pb.UsageSnapshot {
+	filtered := models.UsageDataList(data).FilterByTimeRange(minutes)
+
+	snapshot := &pb.UsageSnapshot{
+		Records: make([]*pb.UsageRecord, 0, len(filtered)),
+	}
+	for _, record := range filtered {
+		snapshot.Records = append(snapshot.Records, &pb.UsageRecord{
+			Id:          int64(record.ID),
+			CreditsUsed: int32(record.CreditsUsed),
+			CreatedAt:   timestamppb.New(record.CreatedAt),
+			Model:       record.Model,
+		})
+	}
+	return snapshot
+}
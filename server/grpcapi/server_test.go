@@ -0,0 +1,93 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/middleware"
+)
+
+// newTestManager 在临时工作目录中创建访问密钥为 secret-key 的认证管理器
+func newTestManager(t *testing.T) *auth.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data", "auth"), []byte("secret-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return auth.NewManager(time.Hour)
+}
+
+// callContext 构造来自指定IP、携带指定访问密钥的调用上下文
+func callContext(ip, key string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+key))
+}
+
+func TestAuthorizeBlocksRepeatedFailures(t *testing.T) {
+	manager := newTestManager(t)
+	reasons := make(chan string, auth.MaxAuthFailures)
+	manager.SetAuthFailureHandler(func(ip, userAgent, reason string) {
+		reasons <- reason
+	})
+	s := &Server{authManager: manager}
+
+	for i := 0; i < auth.MaxAuthFailures; i++ {
+		if code := status.Code(s.authorize(callContext("192.0.2.1", "wrong"))); code != codes.Unauthenticated {
+			t.Fatalf("第%d次错误密钥应返回 Unauthenticated，实际 %v", i+1, code)
+		}
+	}
+	select {
+	case reason := <-reasons:
+		if reason != "grpc" {
+			t.Errorf("审计失败原因应为 grpc，实际 %q", reason)
+		}
+	case <-time.After(time.Second):
+		t.Error("认证失败应写入登录审计")
+	}
+
+	// 失败次数达到上限后，正确的密钥同样被拒绝
+	if code := status.Code(s.authorize(callContext("192.0.2.1", "secret-key"))); code != codes.ResourceExhausted {
+		t.Fatalf("失败次数过多后应返回 ResourceExhausted，实际 %v", code)
+	}
+	// 其他IP不受影响，认证成功后清除失败记录
+	if err := s.authorize(callContext("192.0.2.2", "wrong")); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("其他IP的错误密钥应返回 Unauthenticated，实际 %v", err)
+	}
+	if err := s.authorize(callContext("192.0.2.2", "secret-key")); err != nil {
+		t.Fatalf("其他IP的正确密钥应通过认证: %v", err)
+	}
+	if blocked, _ := manager.AuthBlocked("192.0.2.2"); blocked {
+		t.Error("认证成功后不应再被拒绝")
+	}
+}
+
+func TestRateLimitSharesLimiter(t *testing.T) {
+	limiter := middleware.NewRateLimiter(1, time.Hour)
+	s := &Server{limiter: limiter}
+
+	// HTTP接口的访问密钥调用已用完额度
+	if allowed, _ := limiter.Allow(middleware.RateLimitKeyAuth); !allowed {
+		t.Fatal("首次请求应被允许")
+	}
+	if code := status.Code(s.rateLimit()); code != codes.ResourceExhausted {
+		t.Fatalf("额度用完后应返回 ResourceExhausted，实际 %v", code)
+	}
+}
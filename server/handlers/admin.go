@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/middleware"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// AdminHandler 运维管理处理器
+type AdminHandler struct {
+	db           *database.BadgerDB
+	scheduler    *services.SchedulerService
+	asyncUpdater *services.AsyncConfigUpdater
+	requestLog   *middleware.RequestLog
+	archiver     *services.UsageArchiver
+	probe        *services.UpstreamProbeService
+}
+
+// NewAdminHandler 创建运维管理处理器
+func NewAdminHandler(db *database.BadgerDB, scheduler *services.SchedulerService) *AdminHandler {
+	return &AdminHandler{
+		db:        db,
+		scheduler: scheduler,
+	}
+}
+
+// SetAsyncConfigUpdater 设置异步配置更新服务引用（用于死信任务管理）
+func (h *AdminHandler) SetAsyncConfigUpdater(asyncUpdater *services.AsyncConfigUpdater) {
+	h.asyncUpdater = asyncUpdater
+}
+
+// SetRequestLog 设置请求日志引用
+func (h *AdminHandler) SetRequestLog(requestLog *middleware.RequestLog) {
+	h.requestLog = requestLog
+}
+
+// SetUsageArchiver 设置归档服务引用
+func (h *AdminHandler) SetUsageArchiver(archiver *services.UsageArchiver) {
+	h.archiver = archiver
+}
+
+// SetUpstreamProbeService 设置上游可用性探测服务引用
+func (h *AdminHandler) SetUpstreamProbeService(probe *services.UpstreamProbeService) {
+	h.probe = probe
+}
+
+// GetDBStats 获取数据库统计信息
+func (h *AdminHandler) GetDBStats(c *fiber.Ctx) error {
+	stats, err := h.db.GetStats()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取数据库统计失败"), err))
+	}
+
+	return c.JSON(models.Success(stats))
+}
+
+// CompactDB 手动触发数据库压缩和值日志回收
+func (h *AdminHandler) CompactDB(c *fiber.Ctx) error {
+	result, err := h.db.Compact()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "数据库压缩失败"), err))
+	}
+
+	return c.JSON(models.Success(result))
+}
+
+// Backup 以流式下载返回数据库全量备份，不在内存或磁盘中缓存整个快照
+func (h *AdminHandler) Backup(c *fiber.Ctx) error {
+	filename := fmt.Sprintf("cccmu-backup-%s.bak", time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	// 通过管道边备份边发送：备份失败时以错误关闭管道，连接随之中断而不会发送分块结束标记，
+	// 客户端不会把不完整的文件当作成功；客户端断开时管道被关闭，备份随即停止
+	reader, writer := io.Pipe()
+	go func() {
+		start := time.Now()
+		counter := &countingWriter{w: writer}
+		version, err := h.db.Backup(counter)
+		if err != nil {
+			log.Printf("[备份] 数据库备份中断（已发送%d字节）: %v", counter.n, err)
+			writer.CloseWithError(err)
+			return
+		}
+		writer.Close()
+		log.Printf("[备份] 数据库备份完成: %s，%d字节，版本%d，耗时%dms",
+			filename, counter.n, version, time.Since(start).Milliseconds())
+	}()
+
+	c.Response().SetBodyStream(reader, -1)
+	return nil
+}
+
+// countingWriter 统计写入字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// ExportSQLite 导出SQLite数据库文件（使用记录、降采样数据、每日统计和余额历史），可在本地用SQL任意查询
+// 先写入临时文件（SQLite文件头需在最后写入），再以已知长度发送，发送完成或连接中断后删除临时文件
+func (h *AdminHandler) ExportSQLite(c *fiber.Ctx) error {
+	start := time.Now()
+	file, err := os.CreateTemp("", "cccmu-export-*.db")
+	if err != nil {
+		log.Printf("[SQLite导出] 创建临时文件失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "导出SQLite失败"), err))
+	}
+	tmp := &tempFile{File: file}
+
+	result, err := services.ExportSQLite(h.db, file)
+	var size int64
+	if err == nil {
+		size, err = file.Seek(0, io.SeekEnd)
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		log.Printf("[SQLite导出] 导出失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "导出SQLite失败"), err))
+	}
+
+	filename := fmt.Sprintf("cccmu-%s.db", start.Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/vnd.sqlite3")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	log.Printf("[SQLite导出] %s: 使用记录%d条，降采样%d条，每日统计%d天，余额历史%d条，%d字节，耗时%dms",
+		filename, result.Usage, result.Aggregates, result.Daily, result.Balance, size, time.Since(start).Milliseconds())
+
+	c.Response().SetBodyStream(tmp, int(size))
+	return nil
+}
+
+// tempFile 关闭时删除的临时文件（作为响应体时由fasthttp在发送结束后关闭）
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// GetMaintenance 获取维护模式状态
+func (h *AdminHandler) GetMaintenance(c *fiber.Ctx) error {
+	return c.JSON(models.Success(h.scheduler.GetMaintenanceStatus()))
+}
+
+// SetMaintenance 开启或关闭维护模式
+func (h *AdminHandler) SetMaintenance(c *fiber.Ctx) error {
+	var req models.MaintenanceRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+
+	if !req.Enabled {
+		h.scheduler.ExitMaintenance("手动结束")
+		return c.JSON(models.Success(h.scheduler.GetMaintenanceStatus()))
+	}
+
+	req.Validate()
+	status := h.scheduler.EnterMaintenance(time.Duration(req.DurationMinutes)*time.Minute, req.Reason)
+	return c.JSON(models.Success(status))
+}
+
+// GetDeadJobs 获取多次重试仍失败的异步配置任务
+func (h *AdminHandler) GetDeadJobs(c *fiber.Ctx) error {
+	if h.asyncUpdater == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "异步配置更新服务不可用"), nil))
+	}
+
+	jobs, err := h.asyncUpdater.GetDeadJobs()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取死信任务失败"), err))
+	}
+
+	return c.JSON(models.Success(jobs))
+}
+
+// RequeueDeadJob 将死信任务重新加入队列
+func (h *AdminHandler) RequeueDeadJob(c *fiber.Ctx) error {
+	if h.asyncUpdater == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "异步配置更新服务不可用"), nil))
+	}
+
+	if err := h.asyncUpdater.RequeueDeadJob(c.Params("id")); err != nil {
+		if errors.Is(err, services.ErrDeadJobNotFound) {
+			return c.Status(404).JSON(models.Error(404, i18n.T(c, "死信任务不存在"), nil))
+		}
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "任务重新入队失败"), err))
+	}
+
+	return c.JSON(models.SuccessMessage(i18n.T(c, "任务已重新入队")))
+}
+
+// DiscardDeadJob 丢弃死信任务
+func (h *AdminHandler) DiscardDeadJob(c *fiber.Ctx) error {
+	if h.asyncUpdater == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "异步配置更新服务不可用"), nil))
+	}
+
+	if err := h.asyncUpdater.DiscardDeadJob(c.Params("id")); err != nil {
+		if errors.Is(err, services.ErrDeadJobNotFound) {
+			return c.Status(404).JSON(models.Error(404, i18n.T(c, "死信任务不存在"), nil))
+		}
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "丢弃任务失败"), err))
+	}
+
+	return c.JSON(models.SuccessMessage(i18n.T(c, "任务已丢弃")))
+}
+
+// GetRuntimeState 获取运行时状态快照（不含Cookie、会话等敏感信息），用于远程排查任务协调问题
+func (h *AdminHandler) GetRuntimeState(c *fiber.Ctx) error {
+	state := h.scheduler.GetRuntimeState()
+	if h.asyncUpdater != nil {
+		configJobs := h.asyncUpdater.GetRuntimeState()
+		state.ConfigJobs = &configJobs
+	}
+	if h.probe != nil {
+		probeState := h.probe.GetRuntimeState()
+		state.Probe = &probeState
+	}
+
+	return c.JSON(models.Success(state))
+}
+
+// GetUpstreamAvailability 获取最近hours小时（默认24）的上游可用率、不可用时段和探测记录
+func (h *AdminHandler) GetUpstreamAvailability(c *fiber.Ctx) error {
+	if h.probe == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "上游可用性探测未启用"), nil))
+	}
+
+	maxHours := models.UpstreamProbeDays * 24
+	hours := c.QueryInt("hours", models.DefaultUpstreamProbeHours)
+	if hours <= 0 || hours > maxHours {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "hours取值范围为1-%d", maxHours), nil))
+	}
+
+	availability, err := h.probe.GetAvailability(hours)
+	if err != nil {
+		log.Printf("获取上游可用性失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取上游可用性失败"), err))
+	}
+
+	return c.JSON(models.Success(availability))
+}
+
+// GetRequestLog 获取最近的API请求记录（按时间倒序，可通过limit参数限制条数）
+func (h *AdminHandler) GetRequestLog(c *fiber.Ctx) error {
+	if h.requestLog == nil {
+		return c.JSON(models.Success([]models.RequestLogEntry{}))
+	}
+
+	return c.JSON(models.Success(h.requestLog.Entries(c.QueryInt("limit", 0))))
+}
+
+// GetArchives 列出月度使用记录归档文件
+func (h *AdminHandler) GetArchives(c *fiber.Ctx) error {
+	if h.archiver == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "归档服务不可用"), nil))
+	}
+
+	files, err := h.archiver.ListArchives()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取归档文件失败"), err))
+	}
+
+	return c.JSON(models.Success(files))
+}
+
+// ArchiveUsage 立即归档所有已结束月份的原始使用记录并从数据库移除
+func (h *AdminHandler) ArchiveUsage(c *fiber.Ctx) error {
+	if h.archiver == nil {
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "归档服务不可用"), nil))
+	}
+
+	result, err := h.archiver.ArchiveCompletedMonths()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "归档失败"), err))
+	}
+
+	return c.JSON(models.Success(result))
+}
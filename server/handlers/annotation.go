@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// AnnotationHandler 图表标注处理器
+type AnnotationHandler struct {
+	db        *database.BadgerDB
+	scheduler *services.SchedulerService
+}
+
+// NewAnnotationHandler 创建图表标注处理器
+func NewAnnotationHandler(db *database.BadgerDB, scheduler *services.SchedulerService) *AnnotationHandler {
+	return &AnnotationHandler{
+		db:        db,
+		scheduler: scheduler,
+	}
+}
+
+// GetAnnotations 获取最近指定天数内的图表标注（按标注时间升序）
+func (h *AnnotationHandler) GetAnnotations(c *fiber.Ctx) error {
+	days := c.QueryInt("days", models.DefaultAnnotationDays)
+	if days <= 0 || days > models.MaxAnnotationDays {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", models.MaxAnnotationDays), nil))
+	}
+
+	annotations, err := h.db.GetAnnotations(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("获取标注失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取标注失败"), err))
+	}
+
+	return c.JSON(models.Success(annotations))
+}
+
+// CreateAnnotation 新建图表标注（如切换模型、开始批量任务），保存后推送给所有SSE连接
+func (h *AnnotationHandler) CreateAnnotation(c *fiber.Ctx) error {
+	var req models.AnnotationRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+
+	annotation, err := h.scheduler.AddAnnotation(req)
+	if err != nil {
+		log.Printf("保存标注失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "保存标注失败"), err))
+	}
+
+	return c.JSON(models.Success(annotation))
+}
+
+// DeleteAnnotation 删除图表标注，删除后推送给所有SSE连接
+func (h *AnnotationHandler) DeleteAnnotation(c *fiber.Ctx) error {
+	if err := h.scheduler.DeleteAnnotation(c.Params("id")); err != nil {
+		if errors.Is(err, services.ErrAnnotationNotFound) {
+			return c.Status(404).JSON(models.Error(404, i18n.T(c, "标注不存在"), nil))
+		}
+		log.Printf("删除标注失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "删除标注失败"), err))
+	}
+
+	return c.JSON(models.SuccessMessage(i18n.T(c, "标注已删除")))
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/leafney/cccmu/server/auth"
 	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/middleware"
 	"github.com/leafney/cccmu/server/models"
 	"github.com/leafney/cccmu/server/services"
 )
@@ -28,19 +30,32 @@ func NewAuthHandler(authManager *auth.Manager, scheduler *services.SchedulerServ
 	}
 }
 
+// LoginRequest 登录请求体（可选，访问密钥通过Authorization头传递）
+type LoginRequest struct {
+	RememberMe *bool `json:"rememberMe"` // 是否记住登录，未传时默认为true（兼容旧客户端）
+}
+
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Message   string    `json:"message"`
-	ExpiresAt time.Time `json:"expiresAt"`
+	Message    string    `json:"message"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RememberMe bool      `json:"rememberMe"`
 }
 
 // Login 用户登录
+// 与接口认证共用按IP的失败计数，失败次数过多时返回429，不再校验密钥和记录审计
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	ip := strings.Clone(c.IP())
+	if blocked, wait := h.authManager.AuthBlocked(ip); blocked {
+		return middleware.AuthRateLimited(c, wait)
+	}
+
 	// 从 Authorization 头获取密钥
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
 		log.Printf("登录失败: 缺少Authorization头")
-		return c.Status(401).JSON(models.Error(401, "缺少访问密钥", nil))
+		h.loginFailed(c, ip, models.LoginFailureMissingKey)
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "缺少访问密钥"), nil))
 	}
 
 	// 检查Bearer格式
@@ -54,20 +69,33 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 
 	if key == "" {
 		log.Printf("登录失败: 空密钥")
-		return c.Status(401).JSON(models.Error(401, "访问密钥不能为空", nil))
+		h.loginFailed(c, ip, models.LoginFailureEmptyKey)
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "访问密钥不能为空"), nil))
 	}
 
 	// 验证密钥
 	if !h.authManager.ValidateKey(key) {
-		log.Printf("登录失败: 密钥错误")
-		return c.Status(401).JSON(models.Error(401, "访问密钥错误", nil))
+		log.Printf("登录失败: 密钥错误, IP: %s", ip)
+		h.loginFailed(c, ip, models.LoginFailureInvalidKey)
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "访问密钥错误"), nil))
+	}
+
+	// 读取“记住我”选项，请求体为空或无法解析时按记住登录处理
+	rememberMe := true
+	if len(c.Body()) > 0 {
+		var request LoginRequest
+		if err := c.BodyParser(&request); err == nil && request.RememberMe != nil {
+			rememberMe = *request.RememberMe
+		}
 	}
 
 	// 创建会话
-	session, err := h.authManager.CreateSession()
+	h.authManager.ClearAuthFailures(ip)
+	session, err := h.authManager.CreateSession(ip, strings.Clone(c.Get("User-Agent")), rememberMe)
 	if err != nil {
 		log.Printf("创建会话失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "创建会话失败", err))
+		h.recordLogin(c, models.LoginFailureSession)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "创建会话失败"), err))
 	}
 
 	// 设置cookie
@@ -82,7 +110,8 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 	c.Cookie(cookie)
 
-	log.Printf("用户登录成功，会话: %s", session.ID[:8]+"...")
+	log.Printf("用户登录成功，会话: %s, IP: %s, 记住登录: %v", session.ID[:8]+"...", session.LoginIP, rememberMe)
+	h.recordLogin(c, "")
 
 	// 登录成功后检查配置并恢复监控状态
 	go func() {
@@ -100,13 +129,55 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}()
 
 	response := LoginResponse{
-		Message:   "登录成功",
-		ExpiresAt: session.ExpiresAt,
+		Message:    i18n.T(c, "登录成功"),
+		ExpiresAt:  session.ExpiresAt,
+		RememberMe: rememberMe,
 	}
 
 	return c.JSON(models.Success(response))
 }
 
+// loginFailed 记录登录失败：计入IP的认证失败次数并记录审计
+func (h *AuthHandler) loginFailed(c *fiber.Ctx, ip, reason string) {
+	h.authManager.RecordAuthFailure(ip)
+	h.recordLogin(c, reason)
+}
+
+// recordLogin 记录登录审计，reason为空表示登录成功
+func (h *AuthHandler) recordLogin(c *fiber.Ctx, reason string) {
+	h.scheduler.RecordLogin(models.LoginAuditEntry{
+		Time:      time.Now(),
+		Success:   reason == "",
+		Reason:    reason,
+		IP:        strings.Clone(c.IP()),
+		UserAgent: strings.Clone(c.Get("User-Agent")),
+	})
+}
+
+// GetLoginAudit 获取登录记录（按时间倒序），result参数为success或failure时只返回登录成功或失败的记录
+func (h *AuthHandler) GetLoginAudit(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", models.DefaultLoginAuditLimit)
+	if limit <= 0 || limit > models.MaxLoginAuditQueryLimit {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "limit取值范围为1-%d", models.MaxLoginAuditQueryLimit), nil))
+	}
+
+	var success *bool
+	if result := c.Query("result"); result != "" {
+		if result != "success" && result != "failure" {
+			return c.Status(400).JSON(models.Error(400, i18n.T(c, "result参数无效"), nil))
+		}
+		ok := result == "success"
+		success = &ok
+	}
+
+	entries, err := h.db.GetLoginAudit(success, limit)
+	if err != nil {
+		log.Printf("获取登录记录失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取登录记录失败"), err))
+	}
+	return c.JSON(models.Success(entries))
+}
+
 // Logout 用户登出
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// 获取session ID
@@ -140,11 +211,19 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 
 	log.Printf("用户登出成功")
 
-	return c.JSON(models.SuccessMessage("登出成功"))
+	return c.JSON(models.SuccessMessage(i18n.T(c, "登出成功")))
 }
 
 // Status 检查认证状态
 func (h *AuthHandler) Status(c *fiber.Ctx) error {
+	// 反向代理已完成认证时无需登录
+	if user := middleware.ProxyUser(h.authManager, c); user != "" {
+		return c.JSON(models.Success(map[string]any{
+			"authenticated": true,
+			"proxyUser":     user,
+		}))
+	}
+
 	sessionID := c.Cookies("cccmu_session")
 	if sessionID == "" {
 		return c.JSON(models.Success(map[string]any{
@@ -164,3 +243,8 @@ func (h *AuthHandler) Status(c *fiber.Ctx) error {
 		"expiresAt":     session.ExpiresAt,
 	}))
 }
+
+// ListSessions 列出当前有效的登录会话（包括登录IP、最近请求的IP、User-Agent和时间）
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	return c.JSON(models.Success(h.authManager.ListSessions(c.Cookies("cccmu_session"))))
+}
@@ -3,9 +3,13 @@ package handlers
 import (
 	"log"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/client"
 	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
 	"github.com/leafney/cccmu/server/models"
 	"github.com/leafney/cccmu/server/services"
 )
@@ -30,6 +34,9 @@ type ConfigHandler struct {
 	scheduler        *services.SchedulerService
 	autoResetService *services.AutoResetService
 	asyncUpdater     *services.AsyncConfigUpdater
+	keepAliveService *services.KeepAliveService
+	updateChecker    *services.UpdateCheckerService
+	configSync       *services.ConfigSyncService
 }
 
 // NewConfigHandler 创建配置处理器
@@ -42,12 +49,59 @@ func NewConfigHandler(db *database.BadgerDB, scheduler *services.SchedulerServic
 	}
 }
 
+// SetKeepAliveService 设置Cookie保活服务引用
+func (h *ConfigHandler) SetKeepAliveService(keepAliveService *services.KeepAliveService) {
+	h.keepAliveService = keepAliveService
+}
+
+// SetUpdateChecker 设置新版本检查服务引用
+func (h *ConfigHandler) SetUpdateChecker(updateChecker *services.UpdateCheckerService) {
+	h.updateChecker = updateChecker
+}
+
+// SetConfigSync 设置配置同步服务（从主实例同步配置时使用）
+func (h *ConfigHandler) SetConfigSync(configSync *services.ConfigSyncService) {
+	h.configSync = configSync
+}
+
+// GetSyncConfig 发布供其他实例同步的配置及其版本
+func (h *ConfigHandler) GetSyncConfig(c *fiber.Ctx) error {
+	config, err := h.db.GetConfig()
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置失败"), err))
+	}
+	return c.JSON(models.Success(config.Synced().Snapshot()))
+}
+
+// GetSyncStatus 获取从主实例同步配置的状态
+func (h *ConfigHandler) GetSyncStatus(c *fiber.Ctx) error {
+	if h.configSync == nil {
+		return c.JSON(models.Success(models.ConfigSyncStatus{Enabled: false}))
+	}
+	return c.JSON(models.Success(h.configSync.GetStatus()))
+}
+
+// GetConfigAudit 获取配置变更审计记录（按时间倒序），field参数按字段路径过滤，如 autoReset.threshold
+func (h *ConfigHandler) GetConfigAudit(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", models.DefaultConfigAuditLimit)
+	if limit <= 0 || limit > models.MaxConfigAuditQueryLimit {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "limit取值范围为1-%d", models.MaxConfigAuditQueryLimit), nil))
+	}
+
+	entries, err := h.db.GetConfigAudit(strings.TrimSpace(c.Query("field")), limit)
+	if err != nil {
+		log.Printf("获取配置审计记录失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置审计记录失败"), err))
+	}
+	return c.JSON(models.Success(entries))
+}
+
 // GetConfig 获取配置
 func (h *ConfigHandler) GetConfig(c *fiber.Ctx) error {
 	config, err := h.db.GetConfig()
 	if err != nil {
 		log.Printf("获取配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "获取配置失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置失败"), err))
 	}
 
 	// 转换为API响应格式，Cookie字段自动转为布尔值
@@ -60,6 +114,9 @@ func (h *ConfigHandler) GetConfig(c *fiber.Ctx) error {
 		BuildTime: BuildTime,
 		GoVersion: runtime.Version(),
 	}
+	if h.updateChecker != nil {
+		responseConfig.Version.UpdateAvailable, responseConfig.Version.LatestVersion, responseConfig.Version.ReleaseURL = h.updateChecker.GetStatus()
+	}
 
 	// 添加订阅等级信息，优先从BadgerDB获取持久化数据
 	if balance, err := h.db.GetCreditBalance(); err == nil && balance != nil {
@@ -69,6 +126,7 @@ func (h *ConfigHandler) GetConfig(c *fiber.Ctx) error {
 	} else {
 		responseConfig.Plan = ""
 	}
+	responseConfig.Warnings = configWarnings(c, config, h.scheduler.IsRunning())
 
 	return c.JSON(models.Success(responseConfig))
 }
@@ -76,10 +134,38 @@ func (h *ConfigHandler) GetConfig(c *fiber.Ctx) error {
 // UpdateConfig 更新配置
 func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 	var requestConfig models.UserConfigRequest
-	if err := c.BodyParser(&requestConfig); err != nil {
-		return c.Status(400).JSON(models.Error(400, "请求参数错误", err))
+	if err := parseJSONBody(c, &requestConfig); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+	return h.applyConfigRequest(c, &requestConfig, i18n.T(c, "配置更新成功"))
+}
+
+// UndoConfig 撤销最近一次配置修改：通过常规更新流程恢复修改前的配置（Cookie和监控开关保持当前状态）
+// 撤销本身也是一次配置修改，再次撤销即恢复到撤销前的配置
+func (h *ConfigHandler) UndoConfig(c *fiber.Ctx) error {
+	snapshot, err := h.db.GetConfigUndoSnapshot()
+	if err != nil {
+		log.Printf("获取撤销快照失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "撤销配置失败"), err))
+	}
+	if snapshot == nil {
+		return c.Status(404).JSON(models.Error(404, i18n.T(c, "没有可撤销的配置修改"), nil))
+	}
+
+	currentConfig, err := h.db.GetConfig()
+	if err != nil {
+		log.Printf("获取当前配置失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置失败"), err))
 	}
 
+	requestConfig := snapshot.ToRequest()
+	requestConfig.Enabled = currentConfig.Enabled
+	log.Printf("[配置更新] 撤销最近一次配置修改")
+	return h.applyConfigRequest(c, requestConfig, i18n.T(c, "配置已撤销"))
+}
+
+// applyConfigRequest 将更新请求合并到当前配置，校验并保存后重建相关任务，成功时返回指定提示
+func (h *ConfigHandler) applyConfigRequest(c *fiber.Ctx, requestConfig *models.UserConfigRequest, successMessage string) error {
 	// 获取当前配置
 	currentConfig, err := h.db.GetConfig()
 	if err != nil {
@@ -96,14 +182,36 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 		LastCookieValidTime:      currentConfig.LastCookieValidTime,
 		CookieValidationInterval: currentConfig.CookieValidationInterval,
 		DailyResetUsed:           currentConfig.DailyResetUsed,
+		DailyResetUsedAt:         currentConfig.DailyResetUsedAt,
 		DailyUsageEnabled:        currentConfig.DailyUsageEnabled, // 默认保持原有每日统计配置
 		AutoSchedule:             currentConfig.AutoSchedule,      // 默认保持原有自动调度配置
 		AutoReset:                currentConfig.AutoReset,         // 默认保持原有自动重置配置
+		ResetClock:               currentConfig.ResetClock,        // 默认保持原有每日重置周期配置
+		KeepAlive:                currentConfig.KeepAlive,         // 默认保持原有Cookie保活配置
+		AdaptiveInterval:         currentConfig.AdaptiveInterval,  // 默认保持原有自适应获取间隔配置
+		Retention:                currentConfig.Retention,         // 默认保持原有数据保留策略
+		ModelAliases:             currentConfig.ModelAliases,      // 默认保持原有模型别名
+		ModelGroups:              currentConfig.ModelGroups,       // 默认保持原有模型分组
+		Hooks:                    currentConfig.Hooks,             // 默认保持原有事件Hook
+		Account:                  currentConfig.Account,           // 默认保持原有账户标签
+		Exhaustion:               currentConfig.Exhaustion,        // 默认保持原有积分耗尽告警配置
+		ExternalUsage:            currentConfig.ExternalUsage,     // 默认保持原有外部使用告警配置
+		LoginAlert:               currentConfig.LoginAlert,        // 默认保持原有新IP登录告警配置
+		HTTPTransport:            currentConfig.HTTPTransport,     // 默认保持原有上游HTTP连接池配置
 	}
 
-	// 如果请求中包含新的Cookie，则更新（使用指针判断是否设置了Cookie字段）
+	// 如果请求中包含新的Cookie，则解析后更新（使用指针判断是否设置了Cookie字段）
+	// 支持请求头、浏览器导出的JSON和 name=value 对等格式，统一规范化为请求头值
 	if requestConfig.Cookie != nil {
-		newConfig.Cookie = *requestConfig.Cookie
+		newConfig.Cookie = ""
+		if strings.TrimSpace(*requestConfig.Cookie) != "" {
+			parsed, err := models.ParseCookie(*requestConfig.Cookie, client.CookieHost(), client.RequiredCookies(), time.Now())
+			if err != nil {
+				return c.Status(400).JSON(models.Error(400, i18n.T(c, "配置验证失败"), err))
+			}
+			newConfig.Cookie = parsed.Cookie
+			log.Printf("[配置更新] Cookie已解析（%s格式）: 保留 %s，去除 %s", parsed.Format, models.FormatCookieNames(parsed.Names), models.FormatCookieNames(parsed.Ignored))
+		}
 	}
 
 	// 如果请求中包含每日积分统计配置，则更新
@@ -145,15 +253,104 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 		}
 	}
 
+	// 如果请求中包含每日重置周期配置，则更新
+	if requestConfig.ResetClock != nil {
+		newConfig.ResetClock = *requestConfig.ResetClock
+		log.Printf("[配置更新] 每日重置周期变更: %s %s -> %s %s",
+			currentConfig.ResetClock.Time, currentConfig.ResetClock.Timezone, newConfig.ResetClock.Time, newConfig.ResetClock.Timezone)
+	}
+
+	// 如果请求中包含Cookie保活配置，则更新
+	if requestConfig.KeepAlive != nil {
+		oldKeepAlive := currentConfig.KeepAlive
+		newConfig.KeepAlive = *requestConfig.KeepAlive
+
+		log.Printf("[配置更新] Cookie保活配置变更: %v -> %v (间隔: %d小时)",
+			oldKeepAlive.Enabled, newConfig.KeepAlive.Enabled, newConfig.KeepAlive.IntervalHours)
+	}
+
+	// 如果请求中包含自适应获取间隔配置，则更新
+	if requestConfig.AdaptiveInterval != nil {
+		newConfig.AdaptiveInterval = *requestConfig.AdaptiveInterval
+		log.Printf("[配置更新] 自适应获取间隔变更: 启用=%v, 活跃%d秒, 空闲%d秒, 空闲判定%d分钟",
+			newConfig.AdaptiveInterval.Enabled, newConfig.AdaptiveInterval.ActiveInterval,
+			newConfig.AdaptiveInterval.IdleInterval, newConfig.AdaptiveInterval.IdleMinutes)
+	}
+
+	// 如果请求中包含数据保留策略，则更新
+	if requestConfig.Retention != nil {
+		newConfig.Retention = *requestConfig.Retention
+		log.Printf("[配置更新] 数据保留策略变更: 原始记录%d小时, 每日统计%d天, 余额历史%d天",
+			newConfig.Retention.UsageHours, newConfig.Retention.DailyUsageDays, newConfig.Retention.BalanceHistoryDays)
+	}
+
+	// 如果请求中包含模型别名映射，则整体替换
+	if requestConfig.ModelAliases != nil {
+		newConfig.ModelAliases = requestConfig.ModelAliases
+		log.Printf("[配置更新] 模型别名变更: %d -> %d条", len(currentConfig.ModelAliases), len(newConfig.ModelAliases))
+	}
+
+	// 如果请求中包含模型分组，则整体替换
+	if requestConfig.ModelGroups != nil {
+		newConfig.ModelGroups = *requestConfig.ModelGroups
+		log.Printf("[配置更新] 模型分组变更: %d -> %d个", len(currentConfig.ModelGroups), len(newConfig.ModelGroups))
+	}
+
+	// 如果请求中包含事件Hook，则整体替换
+	if requestConfig.Hooks != nil {
+		newConfig.Hooks = *requestConfig.Hooks
+		log.Printf("[配置更新] 事件Hook变更: %d -> %d个", len(currentConfig.Hooks), len(newConfig.Hooks))
+	}
+
+	// 如果请求中包含账户标签，则更新
+	if requestConfig.Account != nil {
+		newConfig.Account = *requestConfig.Account
+		log.Printf("[配置更新] 账户标签变更: %q -> %q", currentConfig.Account.Name, newConfig.Account.Name)
+	}
+
+	// 如果请求中包含积分耗尽告警配置，则更新
+	if requestConfig.Exhaustion != nil {
+		newConfig.Exhaustion = *requestConfig.Exhaustion
+		log.Printf("[配置更新] 积分耗尽告警变更: 启用=%v, 下限=%d", newConfig.Exhaustion.Enabled, newConfig.Exhaustion.Floor)
+	}
+
+	// 如果请求中包含外部使用告警配置，则更新
+	if requestConfig.ExternalUsage != nil {
+		newConfig.ExternalUsage = *requestConfig.ExternalUsage
+		log.Printf("[配置更新] 外部使用告警变更: 启用=%v, 阈值=%d", newConfig.ExternalUsage.Enabled, newConfig.ExternalUsage.GetThreshold())
+	}
+
+	// 如果请求中包含新IP登录告警配置，则更新
+	if requestConfig.LoginAlert != nil {
+		newConfig.LoginAlert = *requestConfig.LoginAlert
+		log.Printf("[配置更新] 新IP登录告警变更: 启用=%v", newConfig.LoginAlert.Enabled)
+	}
+
+	// 如果请求中包含上游HTTP连接池配置，则更新
+	if requestConfig.HTTPTransport != nil {
+		newConfig.HTTPTransport = *requestConfig.HTTPTransport
+		log.Printf("[配置更新] 上游HTTP连接池变更: %+v -> %+v", currentConfig.HTTPTransport, newConfig.HTTPTransport)
+	}
+
+	// 配置响应中的Webhook地址为占位值，原样提交时保留原有地址
+	newConfig.RestoreRedactedSecrets(currentConfig)
+
 	// 验证配置
 	if err := newConfig.Validate(); err != nil {
-		return c.Status(400).JSON(models.Error(400, "配置验证失败", err))
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "配置验证失败"), err))
+	}
+
+	// 保存修改前的配置作为撤销快照（配置无变化时保留原快照）
+	if len(models.DiffConfig(currentConfig, newConfig)) > 0 {
+		if err := h.db.SaveConfigUndoSnapshot(currentConfig); err != nil {
+			log.Printf("保存撤销快照失败: %v", err)
+		}
 	}
 
 	// 先同步保存配置到数据库（快速操作）
 	if err := h.scheduler.UpdateConfigSync(newConfig); err != nil {
 		log.Printf("同步保存配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "保存配置失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "保存配置失败"), err))
 	}
 
 	// 异步提交重型操作任务
@@ -166,7 +363,7 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 				// 降级到同步模式
 				if err := h.scheduler.UpdateConfig(newConfig); err != nil {
 					log.Printf("降级同步更新调度器配置失败: %v", err)
-					return c.Status(500).JSON(models.Error(500, "更新配置失败", err))
+					return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新配置失败"), err))
 				}
 			} else {
 				log.Printf("调度器异步更新任务已提交: %s", jobID)
@@ -191,12 +388,27 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 				// 降级到同步模式
 				if err := h.autoResetService.UpdateConfig(&newConfig.AutoReset); err != nil {
 					log.Printf("降级同步更新自动重置配置失败: %v", err)
-					return c.Status(500).JSON(models.Error(500, "更新自动重置配置失败", err))
+					return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新自动重置配置失败"), err))
 				}
 			} else {
 				log.Printf("自动重置异步更新任务已提交: %s", jobID)
 			}
 		}
+
+		// 提交Cookie保活配置更新任务
+		if requestConfig.KeepAlive != nil && h.keepAliveService != nil {
+			jobID, err := h.asyncUpdater.SubmitJob(services.JobTypeKeepAlive, &currentConfig.KeepAlive, &newConfig.KeepAlive)
+			if err != nil {
+				log.Printf("提交Cookie保活异步更新任务失败: %v", err)
+				// 降级到同步模式
+				if err := h.keepAliveService.UpdateConfig(&newConfig.KeepAlive); err != nil {
+					log.Printf("降级同步更新Cookie保活配置失败: %v", err)
+					return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新Cookie保活配置失败"), err))
+				}
+			} else {
+				log.Printf("Cookie保活异步更新任务已提交: %s", jobID)
+			}
+		}
 	} else {
 		// 异步服务不可用，降级到同步模式
 		log.Printf("异步配置更新服务不可用，降级到同步模式")
@@ -204,14 +416,22 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 		// 更新调度器配置
 		if err := h.scheduler.UpdateConfig(newConfig); err != nil {
 			log.Printf("更新调度器配置失败: %v", err)
-			return c.Status(500).JSON(models.Error(500, "更新配置失败", err))
+			return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新配置失败"), err))
 		}
 
 		// 更新自动重置服务配置
 		if h.autoResetService != nil {
 			if err := h.autoResetService.UpdateConfig(&newConfig.AutoReset); err != nil {
 				log.Printf("更新自动重置服务配置失败: %v", err)
-				return c.Status(500).JSON(models.Error(500, "更新自动重置配置失败", err))
+				return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新自动重置配置失败"), err))
+			}
+		}
+
+		// 更新Cookie保活服务配置
+		if h.keepAliveService != nil {
+			if err := h.keepAliveService.UpdateConfig(&newConfig.KeepAlive); err != nil {
+				log.Printf("更新Cookie保活服务配置失败: %v", err)
+				return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新Cookie保活配置失败"), err))
 			}
 		}
 	}
@@ -223,6 +443,7 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 	log.Printf("[配置更新] - 每日积分统计: %v", newConfig.DailyUsageEnabled)
 	log.Printf("[配置更新] - 自动调度: %v", newConfig.AutoSchedule.Enabled)
 	log.Printf("[配置更新] - 自动重置: %v", newConfig.AutoReset.Enabled)
+	log.Printf("[配置更新] - Cookie保活: %v", newConfig.KeepAlive.Enabled)
 
 	// 通过SSE通知前端配置已更新
 	log.Printf("[配置更新] 通知前端配置变更...")
@@ -232,7 +453,21 @@ func (h *ConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 	log.Printf("[配置更新] 通知前端自动调度状态变更...")
 	h.scheduler.NotifyAutoScheduleChange()
 
-	return c.JSON(models.SuccessMessage("配置更新成功"))
+	response := models.SuccessMessage(successMessage)
+	if warnings := configWarnings(c, newConfig, newConfig.Enabled); len(warnings) > 0 {
+		response.Data = fiber.Map{"warnings": warnings}
+	}
+	return c.JSON(response)
+}
+
+// configWarnings 检查配置中不会按预期生效的组合，monitoring为监控任务是否运行（更新配置时为新配置的监控开关）
+func configWarnings(c *fiber.Ctx, config *models.UserConfig, monitoring bool) []string {
+	var warnings []string
+	// 阈值检查和自定义策略随监控任务取得的积分余额执行，不单独请求上游
+	if config.AutoReset.NeedsBalanceCheck() && !monitoring {
+		warnings = append(warnings, i18n.T(c, "已启用阈值重置，但监控未运行，不会检查积分余额"))
+	}
+	return warnings
 }
 
 // ClearCookie 清除Cookie
@@ -241,7 +476,7 @@ func (h *ConfigHandler) ClearCookie(c *fiber.Ctx) error {
 	config, err := h.db.GetConfig()
 	if err != nil {
 		log.Printf("获取配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "获取配置失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置失败"), err))
 	}
 
 	// 清除Cookie
@@ -250,7 +485,7 @@ func (h *ConfigHandler) ClearCookie(c *fiber.Ctx) error {
 	// 保存更新的配置
 	if err := h.db.SaveConfig(config); err != nil {
 		log.Printf("保存清除后的配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "清除Cookie失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "清除Cookie失败"), err))
 	}
 
 	// 更新调度器，停止当前任务
@@ -259,5 +494,5 @@ func (h *ConfigHandler) ClearCookie(c *fiber.Ctx) error {
 	}
 
 	log.Printf("Cookie已清除，监控任务已停止")
-	return c.JSON(models.SuccessMessage("Cookie已清除"))
+	return c.JSON(models.SuccessMessage(i18n.T(c, "Cookie已清除")))
 }
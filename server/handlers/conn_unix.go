@@ -0,0 +1,30 @@
+//go:build !windows
+
+package handlers
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connClosed 非阻塞地窥探连接是否已被客户端关闭（读到EOF或连接被重置），不消耗缓冲区中的数据
+func connClosed(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	closed := false
+	buf := make([]byte, 1)
+	raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		closed = (n == 0 && err == nil) || errors.Is(err, syscall.ECONNRESET)
+		return true
+	})
+	return closed
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package handlers
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnClosed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听本地端口: %v", err)
+	}
+	defer listener.Close()
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	serverConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("接受连接失败: %v", err)
+	}
+	defer serverConn.Close()
+
+	if connClosed(serverConn) {
+		t.Fatal("连接未关闭时不应判定为已断开")
+	}
+
+	// 已到达的数据不应被窥探消耗
+	clientConn.Write([]byte("x"))
+	time.Sleep(50 * time.Millisecond)
+	if connClosed(serverConn) {
+		t.Fatal("有未读数据时不应判定为已断开")
+	}
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(serverConn, buf); err != nil || buf[0] != 'x' {
+		t.Fatalf("窥探后数据应仍可读取: %q, %v", buf, err)
+	}
+
+	clientConn.Close()
+	deadline := time.Now().Add(time.Second)
+	for !connClosed(serverConn) {
+		if time.Now().After(deadline) {
+			t.Fatal("客户端关闭后应判定为已断开")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,8 @@
+package handlers
+
+import "net"
+
+// connClosed Windows不检测客户端断开，由总时限保证卡住的上游请求不会堆积
+func connClosed(net.Conn) bool {
+	return false
+}
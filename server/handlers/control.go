@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/leafney/cccmu/server/client"
 	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
 	"github.com/leafney/cccmu/server/models"
 	"github.com/leafney/cccmu/server/services"
 )
@@ -29,28 +33,29 @@ func NewControlHandler(scheduler *services.SchedulerService, db *database.Badger
 func (h *ControlHandler) StartTask(c *fiber.Ctx) error {
 	if err := h.scheduler.Start(); err != nil {
 		log.Printf("启动任务失败: %v", err)
-		return c.Status(400).JSON(models.Error(400, "启动任务失败", err))
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "启动任务失败"), err))
 	}
 
 	log.Println("定时任务已启动")
-	return c.JSON(models.SuccessMessage("任务启动成功"))
+	return c.JSON(models.SuccessMessage(i18n.T(c, "任务启动成功")))
 }
 
 // StopTask 停止任务
 func (h *ControlHandler) StopTask(c *fiber.Ctx) error {
 	if err := h.scheduler.Stop(); err != nil {
 		log.Printf("停止任务失败: %v", err)
-		return c.Status(400).JSON(models.Error(400, "停止任务失败", err))
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "停止任务失败"), err))
 	}
 
 	log.Println("定时任务已停止")
-	return c.JSON(models.SuccessMessage("任务停止成功"))
+	return c.JSON(models.SuccessMessage(i18n.T(c, "任务停止成功")))
 }
 
 // GetTaskStatus 获取任务状态
 func (h *ControlHandler) GetTaskStatus(c *fiber.Ctx) error {
 	status := map[string]interface{}{
-		"running": h.scheduler.IsRunning(),
+		"running":          h.scheduler.IsRunning(),
+		"upstreamFailures": client.GetFailureStats(),
 	}
 
 	return c.JSON(models.Success(status))
@@ -58,71 +63,248 @@ func (h *ControlHandler) GetTaskStatus(c *fiber.Ctx) error {
 
 // GetCreditBalance 获取积分余额
 func (h *ControlHandler) GetCreditBalance(c *fiber.Ctx) error {
-	balance := h.scheduler.GetLatestBalance()
+	balance := h.scheduler.BalanceChange(h.scheduler.GetLatestBalance())
 
 	return c.JSON(models.Success(balance))
 }
 
+// GetBalanceCurve 获取最近几小时的实际余额与按积分恢复模型推算的预期余额，两者相差较大说明有本机以外的使用
+func (h *ControlHandler) GetBalanceCurve(c *fiber.Ctx) error {
+	// 预期余额需扣除本机的使用记录，小时数受原始记录保留时长限制
+	maxHours := services.RetentionOf(h.scheduler.GetConfig()).UsageHours
+	hours := c.QueryInt("hours", min(24, maxHours))
+	if hours <= 0 || hours > maxHours {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "hours取值范围为1-%d", maxHours), nil))
+	}
+
+	curve, err := h.scheduler.GetBalanceCurve(hours)
+	if err != nil {
+		log.Printf("获取积分余额曲线失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取积分余额曲线失败"), err))
+	}
+
+	return c.JSON(models.Success(curve))
+}
+
 // ResetCredits 重置积分
 func (h *ControlHandler) ResetCredits(c *fiber.Ctx) error {
 	// 获取当前配置
 	config, err := h.db.GetConfig()
 	if err != nil {
 		log.Printf("获取配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "获取配置失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取配置失败"), err))
 	}
 
 	// 检查Cookie是否配置
 	if config.Cookie == "" {
-		return c.Status(400).JSON(models.Error(400, "请先配置Cookie", nil))
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请先配置Cookie"), nil))
+	}
+
+	// 手动重置由用户明确发起，默认不受重置标记限制（标记可能未同步上游的实际状态，由上游决定能否重置）；
+	// 脚本可通过 enforceLimit=true 要求本周期已使用过重置时直接返回409，不调用上游
+	if c.QueryBool("enforceLimit") && config.IsDailyResetUsed(time.Now()) {
+		return c.Status(409).JSON(models.Error(409, i18n.T(c, "当前重置周期已使用过重置"), nil).WithCode(models.ErrResetLimitReached))
 	}
 
 	// 调用积分重置API，通过状态码判断重置状态
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
 	apiClient := client.NewClaudeAPIClient(config.Cookie)
-	resetSuccess, resetInfo, err := apiClient.ResetCredits()
+	resetSuccess, resetInfo, err := apiClient.ResetCreditsContext(ctx)
 	if err != nil {
 		log.Printf("调用重置积分API失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "重置积分失败", err))
+		return upstreamFailure(c, "重置积分失败", err)
 	}
 
 	if !resetSuccess {
 		log.Printf("重置积分API返回失败")
-		return c.Status(400).JSON(models.Error(400, "重置积分失败，请稍后重试", nil))
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "重置积分失败，请稍后重试"), nil))
 	}
 
 	// API调用成功后，标记今日已使用重置
-	config.DailyResetUsed = true
+	config.MarkDailyResetUsed(time.Now())
 
 	// 保存配置
 	if err := h.db.SaveConfig(config); err != nil {
 		log.Printf("保存配置失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "保存配置失败", err))
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "保存配置失败"), err))
 	}
 
 	log.Printf("积分重置成功，已标记今日已使用重置。重置信息: %s", resetInfo)
 
+	// 记录当日重置次数（用于每日统计和订阅报告）
+	if err := h.db.IncrementDailyResets(models.GetLocalDate(time.Now())); err != nil {
+		log.Printf("记录重置次数失败: %v", err)
+	}
+	// 记录重置时间（用于重置效果报告）
+	if err := h.db.SaveResetRecord(models.ResetRecord{Time: time.Now(), Info: resetInfo}); err != nil {
+		log.Printf("保存重置记录失败: %v", err)
+	}
+
 	// 通过调度器通知重置状态变化（SSE推送给前端）
 	h.scheduler.NotifyResetStatusChange(true)
+	h.scheduler.FireHook(models.HookEventResetExecuted, map[string]string{"info": resetInfo})
 
 	// 触发数据刷新，获取最新的积分余额
-	// 延迟2秒后查询，确保服务端处理完重置操作
+	// 延迟2秒后查询，确保服务端处理完重置操作；此时响应已返回，使用独立的时限
 	go func() {
 		time.Sleep(2 * time.Second)
-		if err := h.scheduler.FetchBalanceManually(); err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), services.ManualUpstreamTimeout)
+		defer cancel()
+		if err := h.scheduler.FetchBalanceManually(ctx); err != nil {
 			log.Printf("重置后刷新积分余额失败: %v", err)
 		}
 	}()
 
-	return c.JSON(models.SuccessMessage("积分重置成功"))
+	return c.JSON(models.SuccessMessage(i18n.T(c, "积分重置成功")))
+}
+
+// resetFlagRequest 手动设置重置标记的请求
+type resetFlagRequest struct {
+	Used *bool `json:"used"` // true表示今日已使用重置，false表示清除标记
+}
+
+// SetResetFlag 手动设置或清除今日已使用重置标记（如已在网站上直接重置）
+func (h *ControlHandler) SetResetFlag(c *fiber.Ctx) error {
+	var req resetFlagRequest
+	if err := parseJSONBody(c, &req); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+	if req.Used == nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), models.ValidationErrors{{Field: "used", Message: "不能为空"}}))
+	}
+
+	if err := h.scheduler.SetDailyResetFlag(*req.Used); err != nil {
+		log.Printf("更新重置标记失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "更新重置标记失败"), err))
+	}
+
+	return c.JSON(models.Success(fiber.Map{"dailyResetUsed": *req.Used}))
 }
 
-// RefreshAll 手动刷新所有数据（使用数据 + 积分余额）
+// GetResetSuggestion 获取根据历史使用曲线和积分恢复速度计算的建议每日重置时间
+func (h *ControlHandler) GetResetSuggestion(c *fiber.Ctx) error {
+	suggestion, err := h.scheduler.SuggestResetTime()
+	if errors.Is(err, services.ErrResetSuggestionUnavailable) {
+		return c.Status(404).JSON(models.Error(404, i18n.T(c, "历史数据不足，暂无建议重置时间"), nil))
+	}
+	if err != nil {
+		log.Printf("计算建议重置时间失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "计算建议重置时间失败"), err))
+	}
+
+	return c.JSON(models.Success(suggestion))
+}
+
+// GetResetReport 获取最近几天各次重置获得的积分和重置后实际使用的积分，用于判断重置是否值得
+func (h *ControlHandler) GetResetReport(c *fiber.Ctx) error {
+	maxDays := services.RetentionOf(h.scheduler.GetConfig()).DailyUsageDays
+	days := c.QueryInt("days", min(models.DefaultResetReportDays, maxDays))
+	if days <= 0 || days > maxDays {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", maxDays), nil))
+	}
+
+	report, err := h.scheduler.GetResetReport(days)
+	if err != nil {
+		log.Printf("生成重置效果报告失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "生成重置效果报告失败"), err))
+	}
+
+	return c.JSON(models.Success(report))
+}
+
+// 手动刷新的数据范围
+const (
+	refreshScopeUsage   = "usage"   // 仅使用数据
+	refreshScopeBalance = "balance" // 仅积分余额
+	refreshScopeAll     = "all"     // 使用数据 + 积分余额
+)
+
+// RefreshAll 手动刷新数据，scope指定刷新范围（默认全部）
+// 指定minutes时直接返回该时间窗口内的数据，用于临时查看，不修改已保存的显示时间范围，也不重启定时任务
 func (h *ControlHandler) RefreshAll(c *fiber.Ctx) error {
-	if err := h.scheduler.FetchAllDataManually(); err != nil {
-		log.Printf("手动刷新所有数据失败: %v", err)
-		return c.Status(500).JSON(models.Error(500, "刷新数据失败", err))
+	scope := c.Query("scope", refreshScopeAll)
+	minutes := c.QueryInt("minutes", 0)
+
+	var invalid models.ValidationErrors
+	if scope != refreshScopeUsage && scope != refreshScopeBalance && scope != refreshScopeAll {
+		invalid.Addf("scope", "取值为 usage、balance 或 all")
+	}
+	if c.Query("minutes") != "" && (minutes <= 0 || minutes > models.MaxSeriesMinutes) {
+		invalid.Addf("minutes", "取值范围为1-%d", models.MaxSeriesMinutes)
 	}
+	if err := invalid.Err(); err != nil {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "请求参数错误"), err))
+	}
+
+	ctx, cancel := upstreamContext(c)
+	defer cancel()
 
-	log.Println("所有数据已手动刷新")
-	return c.JSON(models.SuccessMessage("数据刷新成功"))
+	var err error
+	switch scope {
+	case refreshScopeUsage:
+		err = h.scheduler.FetchDataManually(ctx)
+	case refreshScopeBalance:
+		err = h.scheduler.FetchBalanceManually(ctx)
+	default:
+		err = h.scheduler.FetchAllDataManually(ctx)
+	}
+	if err != nil {
+		log.Printf("手动刷新数据失败 [%s]: %v", scope, err)
+		return upstreamFailure(c, "刷新数据失败", err)
+	}
+
+	log.Printf("数据已手动刷新 [%s]", scope)
+	if minutes == 0 {
+		return c.JSON(models.SuccessMessage(i18n.T(c, "数据刷新成功")))
+	}
+
+	result := fiber.Map{"scope": scope, "minutes": minutes}
+	if scope != refreshScopeBalance {
+		result["usage"] = models.UsageDataList(h.scheduler.GetLatestData()).FilterByTimeRange(minutes).WithGroups()
+	}
+	if scope != refreshScopeUsage {
+		result["balance"] = h.scheduler.GetLatestBalance()
+	}
+	return c.JSON(models.Success(result))
+}
+
+// disconnectPollInterval 等待上游响应期间检测客户端断开的间隔
+const disconnectPollInterval = 500 * time.Millisecond
+
+// upstreamContext 为接口触发的上游请求创建上下文：超过总时限、服务关闭或客户端断开时取消进行中的请求
+// （fasthttp不感知请求处理期间的客户端断开，定期窥探连接状态；调用方须在处理结束时调用cancel停止检测）
+func upstreamContext(c *fiber.Ctx) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(c.Context(), services.ManualUpstreamTimeout)
+	conn := c.Context().Conn()
+	if conn == nil {
+		return ctx, cancel
+	}
+	path := strings.Clone(c.Path()) // fiber复用请求缓冲区，检测协程中不能再访问c
+
+	go func() {
+		ticker := time.NewTicker(disconnectPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if connClosed(conn) {
+					log.Printf("客户端已断开，取消进行中的上游请求: %s", path)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// upstreamFailure 上游请求错误交给上游错误中间件统一转换为带错误码的响应，其余错误按message返回500
+func upstreamFailure(c *fiber.Ctx, message string, err error) error {
+	if client.ErrorCode(err) != "" {
+		return err
+	}
+	return c.Status(500).JSON(models.Error(500, i18n.T(c, message), err))
 }
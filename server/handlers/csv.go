@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// mimeTextCSV CSV响应类型
+const mimeTextCSV = "text/csv"
+
+// wantsCSV 判断客户端是否要求CSV格式：?format=csv优先，其次按Accept协商（未指定时返回JSON）
+func wantsCSV(c *fiber.Ctx) bool {
+	c.Vary(fiber.HeaderAccept)
+	if format := c.Query("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return c.Accepts(fiber.MIMEApplicationJSON, mimeTextCSV) == mimeTextCSV
+}
+
+// sendCSV 以CSV附件流式返回数据，writeRows逐行写入，不在内存中构建完整响应
+// 开头写入UTF-8 BOM，使Excel正确识别中文模型名
+func sendCSV(c *fiber.Ctx, name string, header []string, writeRows func(w *csv.Writer) error) error {
+	filename := fmt.Sprintf("cccmu-%s-%s.csv", name, time.Now().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, mimeTextCSV+"; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	c.Response().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		bw.WriteString("\ufeff")
+		w := csv.NewWriter(bw)
+		err := w.Write(header)
+		if err == nil {
+			err = writeRows(w)
+		}
+		w.Flush()
+		if err == nil {
+			err = w.Error()
+		}
+		if err != nil {
+			log.Printf("[CSV导出] %s 写入中断: %v", filename, err)
+		}
+	})
+	return nil
+}
+
+// sortedModels 汇总各行的模型名称（排序后作为CSV的模型列）
+func sortedModels(modelCredits ...map[string]int) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, credits := range modelCredits {
+		for model := range credits {
+			if !seen[model] {
+				seen[model] = true
+				names = append(names, model)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// modelColumns 按模型列顺序取出各模型的积分
+func modelColumns(row []string, names []string, credits map[string]int) []string {
+	for _, model := range names {
+		row = append(row, strconv.Itoa(credits[model]))
+	}
+	return row
+}
+
+// sendUsageDataCSV 以CSV返回使用记录（每条记录一行）
+func sendUsageDataCSV(c *fiber.Ctx, data models.UsageDataList) error {
+	header := []string{"id", "createdAt", "model", "group", "creditsUsed", "statusCode"}
+	return sendCSV(c, "usage", header, func(w *csv.Writer) error {
+		for _, record := range data {
+			row := []string{
+				strconv.Itoa(record.ID),
+				record.CreatedAt.Format(time.RFC3339),
+				record.Model,
+				record.Group,
+				strconv.Itoa(record.CreditsUsed),
+				strconv.Itoa(record.StatusCode),
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sendDailyUsageCSV 以CSV返回每日积分统计（每天一行，各模型积分各占一列）
+func sendDailyUsageCSV(c *fiber.Ctx, usageList models.DailyUsageList) error {
+	credits := make([]map[string]int, len(usageList))
+	for i, usage := range usageList {
+		credits[i] = usage.ModelCredits
+	}
+	modelNames := sortedModels(credits...)
+
+	header := append([]string{"date", "totalCredits", "resets"}, modelNames...)
+	return sendCSV(c, "history", header, func(w *csv.Writer) error {
+		for _, usage := range usageList {
+			row := []string{usage.Date, strconv.Itoa(usage.TotalCredits), strconv.Itoa(usage.Resets)}
+			if err := w.Write(modelColumns(row, modelNames, usage.ModelCredits)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// sendUsageAggregatesCSV 以CSV返回15分钟降采样数据（每个区间一行，各模型积分各占一列）
+func sendUsageAggregatesCSV(c *fiber.Ctx, aggregates []models.UsageAggregate) error {
+	credits := make([]map[string]int, len(aggregates))
+	for i, aggregate := range aggregates {
+		credits[i] = aggregate.ModelCredits
+	}
+	modelNames := sortedModels(credits...)
+
+	header := append([]string{"start", "credits", "count"}, modelNames...)
+	return sendCSV(c, "trend", header, func(w *csv.Writer) error {
+		for _, aggregate := range aggregates {
+			row := []string{aggregate.Start.Format(time.RFC3339), strconv.Itoa(aggregate.Credits), strconv.Itoa(aggregate.Count)}
+			if err := w.Write(modelColumns(row, modelNames, aggregate.ModelCredits)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"log"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/i18n"
 	"github.com/leafney/cccmu/server/models"
 	"github.com/leafney/cccmu/server/services"
 )
@@ -21,33 +24,23 @@ func NewDailyUsageHandler(scheduler *services.SchedulerService, authManager *aut
 	}
 }
 
-// GetWeeklyUsage 触发积分历史统计数据获取（通过SSE推送）
+// GetWeeklyUsage 触发积分历史统计数据获取（通过SSE推送）；要求CSV格式时直接返回最近一周的统计
 func (h *DailyUsageHandler) GetWeeklyUsage(c *fiber.Ctx) error {
-	// 验证认证状态
+	// 验证认证状态（使用访问密钥或反向代理认证的请求没有会话，如只读副本转发的请求）
+	keyAuth, _ := c.Locals("keyAuth").(bool)
+	proxyUser, _ := c.Locals("proxyUser").(string)
 	sessionID := c.Cookies("cccmu_session")
-	if _, valid := h.authManager.ValidateSession(sessionID); !valid {
-		return c.Status(401).JSON(models.Error(401, "认证无效", nil))
+	if _, valid := h.authManager.ValidateSession(sessionID); !valid && !keyAuth && proxyUser == "" {
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "认证无效"), nil))
+	}
+
+	if wantsCSV(c) {
+		return sendDailyUsageCSV(c, h.scheduler.GetWeeklyUsageFilled())
 	}
 
 	// 获取数据并通过SSE推送
 	go func() {
-		// 获取一周数据，如果失败则返回7天0数据
-		weeklyUsage, err := h.scheduler.GetWeeklyUsage()
-		if err != nil || len(weeklyUsage) == 0 {
-			// 生成7天的0数据
-			weekDates := models.GetWeekDates()
-			weeklyUsage = make([]models.DailyUsage, len(weekDates))
-			for i, date := range weekDates {
-				weeklyUsage[i] = models.DailyUsage{
-					Date:         date,
-					TotalCredits: 0,
-				}
-			}
-		} else {
-			// 填充缺失日期
-			weeklyUsageList := models.DailyUsageList(weeklyUsage)
-			weeklyUsage = weeklyUsageList.FillMissingDates()
-		}
+		weeklyUsage := h.scheduler.GetWeeklyUsageFilled()
 
 		// 推送数据
 		h.scheduler.BroadcastDailyUsage(weeklyUsage)
@@ -56,3 +49,41 @@ func (h *DailyUsageHandler) GetWeeklyUsage(c *fiber.Ctx) error {
 	// 立即返回成功响应
 	return c.JSON(models.Success("ok"))
 }
+
+// ExportDailyUsage 导出最近指定天数的每日积分统计（days默认7，最多保留天数）
+func (h *DailyUsageHandler) ExportDailyUsage(c *fiber.Ctx) error {
+	maxDays := services.RetentionOf(h.scheduler.GetConfig()).DailyUsageDays
+	days := c.QueryInt("days", models.DailyUsageRetentionDays)
+	if days <= 0 || days > maxDays {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", maxDays), nil))
+	}
+
+	usageList, err := h.scheduler.GetRecentDailyUsage(days)
+	if err != nil {
+		log.Printf("导出积分统计失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "导出积分统计失败"), err))
+	}
+	if wantsCSV(c) {
+		return sendDailyUsageCSV(c, usageList)
+	}
+
+	return c.JSON(models.Success(usageList))
+}
+
+// GetSameWeekdayComparison 获取今日按小时的积分曲线与之前几周同一星期几的对比（weeks默认4，受每日统计保留天数限制）
+func (h *DailyUsageHandler) GetSameWeekdayComparison(c *fiber.Ctx) error {
+	// 保留天数不足7天时仍允许对比1周（缺失的历史数据按空曲线返回），避免取值范围变为1-0
+	maxWeeks := max(1, services.RetentionOf(h.scheduler.GetConfig()).DailyUsageDays/7)
+	weeks := c.QueryInt("weeks", min(models.DefaultSameDayWeeks, maxWeeks))
+	if weeks <= 0 || weeks > maxWeeks {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "weeks取值范围为1-%d", maxWeeks), nil))
+	}
+
+	comparison, err := h.scheduler.GetSameWeekdayComparison(weeks)
+	if err != nil {
+		log.Printf("获取同星期几积分对比失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取积分统计失败"), err))
+	}
+
+	return c.JSON(models.Success(comparison))
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// 订阅地址（不在API前缀下，通过URL中的令牌认证）
+const (
+	DailyFeedPath    = "/feeds/daily.xml"    // 每日汇总RSS
+	ScheduleFeedPath = "/feeds/schedule.ics" // 自动化计划iCal日历
+)
+
+// feedTopModels 每日汇总中列出的模型数量
+const feedTopModels = 3
+
+// FeedHandler 订阅源处理器
+type FeedHandler struct {
+	scheduler   *services.SchedulerService
+	authManager *auth.Manager
+}
+
+// NewFeedHandler 创建订阅源处理器
+func NewFeedHandler(scheduler *services.SchedulerService, authManager *auth.Manager) *FeedHandler {
+	return &FeedHandler{
+		scheduler:   scheduler,
+		authManager: authManager,
+	}
+}
+
+// rssFeed RSS 2.0文档
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel RSS频道
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem RSS条目
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+// rssGUID 条目唯一标识
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// GetFeedInfo 获取订阅地址（含订阅令牌，需登录）
+func (h *FeedHandler) GetFeedInfo(c *fiber.Ctx) error {
+	token := h.authManager.FeedToken()
+	return c.JSON(models.Success(fiber.Map{
+		"daily":    c.BaseURL() + DailyFeedPath + "?token=" + token,
+		"schedule": c.BaseURL() + ScheduleFeedPath + "?token=" + token,
+	}))
+}
+
+// DailyFeed 每日积分汇总RSS订阅（每个已结束的自然日一条，包含总积分、主要模型和重置次数）
+func (h *FeedHandler) DailyFeed(c *fiber.Ctx) error {
+	if !h.authManager.ValidateFeedToken(c.Query("token")) {
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "订阅令牌无效"), nil))
+	}
+
+	days := services.RetentionOf(h.scheduler.GetConfig()).DailyUsageDays
+	usageList, err := h.scheduler.GetRecentDailyUsage(days)
+	if err != nil {
+		log.Printf("[订阅] 获取每日统计失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取每日统计失败"), err))
+	}
+
+	// 仅输出已结束的日期，避免当日条目内容随时间变化
+	today := models.GetLocalDate(time.Now())
+	entries := make([]models.DailyUsage, 0, len(usageList))
+	for _, usage := range usageList {
+		if usage.Date < today {
+			entries = append(entries, usage)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date > entries[j].Date
+	})
+
+	lang := i18n.Lang(c)
+	link := c.BaseURL() + "/"
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         h.withAccountName(i18n.Translate(lang, "CCCMU 每日积分汇总")),
+			Link:          link,
+			Description:   i18n.Translate(lang, "Claude Code 每日积分使用汇总"),
+			LastBuildDate: time.Now().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(entries)),
+		},
+	}
+	for _, usage := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, newDailyFeedItem(lang, link, usage))
+	}
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "生成订阅失败"), err))
+	}
+
+	c.Set(fiber.HeaderContentType, "application/rss+xml; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	return c.Send(append([]byte(xml.Header), data...))
+}
+
+// newDailyFeedItem 生成单日汇总条目
+func newDailyFeedItem(lang, link string, usage models.DailyUsage) rssItem {
+	// 发布时间取次日零点（即当日统计结束的时间）
+	pubDate := time.Now()
+	if date, err := time.ParseInLocation("2006-01-02", usage.Date, time.Local); err == nil {
+		pubDate = date.AddDate(0, 0, 1)
+	}
+
+	topModels := make([]string, 0, feedTopModels)
+	for _, model := range usage.TopModels(feedTopModels) {
+		topModels = append(topModels, fmt.Sprintf("%s (%d)", model, usage.ModelCredits[model]))
+	}
+	modelsText := i18n.Translate(lang, "无")
+	if len(topModels) > 0 {
+		modelsText = strings.Join(topModels, ", ")
+	}
+
+	lines := []string{
+		fmt.Sprintf(i18n.Translate(lang, "总积分: %d"), usage.TotalCredits),
+		fmt.Sprintf(i18n.Translate(lang, "主要模型: %s"), modelsText),
+		fmt.Sprintf(i18n.Translate(lang, "重置次数: %d"), usage.Resets),
+	}
+
+	return rssItem{
+		Title:       fmt.Sprintf(i18n.Translate(lang, "%s 使用 %d 积分"), usage.Date, usage.TotalCredits),
+		Link:        link,
+		Description: strings.Join(lines, "<br/>"),
+		PubDate:     pubDate.Format(time.RFC1123Z),
+		GUID:        rssGUID{Value: "cccmu-daily-" + usage.Date},
+	}
+}
+
+// ScheduleFeed 自动化计划iCal日历订阅（定时自动重置时间和自动调度的监控开启/关闭时段）
+func (h *FeedHandler) ScheduleFeed(c *fiber.Ctx) error {
+	if !h.authManager.ValidateFeedToken(c.Query("token")) {
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "订阅令牌无效"), nil))
+	}
+
+	days := models.DefaultCalendarDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > models.MaxCalendarDays {
+			return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", models.MaxCalendarDays), err))
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	events := models.UpcomingCalendarEvents(h.scheduler.GetConfig(), now, days)
+	lang := i18n.Lang(c)
+	summaries := map[string]string{
+		models.CalendarEventAutoReset:     i18n.Translate(lang, "CCCMU 自动重置积分"),
+		models.CalendarEventMonitoringOn:  i18n.Translate(lang, "CCCMU 监控开启"),
+		models.CalendarEventMonitoringOff: i18n.Translate(lang, "CCCMU 监控关闭"),
+	}
+
+	// iCal要求以CRLF换行，时间统一使用UTC表示
+	const layout = "20060102T150405Z"
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//cccmu//schedule//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:" + escapeICalText(h.withAccountName(i18n.Translate(lang, "CCCMU 自动化计划"))))
+	for _, event := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine(fmt.Sprintf("UID:cccmu-%s-%s@cccmu", event.Kind, event.Start.UTC().Format(layout)))
+		writeLine("DTSTAMP:" + now.UTC().Format(layout))
+		writeLine("DTSTART:" + event.Start.UTC().Format(layout))
+		writeLine("DTEND:" + event.End.UTC().Format(layout))
+		writeLine("SUMMARY:" + escapeICalText(summaries[event.Kind]))
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	return c.SendString(b.String())
+}
+
+// withAccountName 设置了账户名称时在订阅标题后附加账户名称，便于区分多个实例的订阅
+func (h *FeedHandler) withAccountName(title string) string {
+	if config := h.scheduler.GetConfig(); config != nil && config.Account.Name != "" {
+		return title + " - " + config.Account.Name
+	}
+	return title
+}
+
+// escapeICalText 转义iCal文本值中的特殊字符
+func escapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// HealthHandler 存活与就绪检查处理器
+type HealthHandler struct {
+	db               *database.BadgerDB
+	scheduler        *services.SchedulerService
+	autoResetService *services.AutoResetService
+	startedAt        time.Time
+}
+
+// NewHealthHandler 创建健康检查处理器
+func NewHealthHandler(db *database.BadgerDB, scheduler *services.SchedulerService, autoResetService *services.AutoResetService) *HealthHandler {
+	return &HealthHandler{
+		db:               db,
+		scheduler:        scheduler,
+		autoResetService: autoResetService,
+		startedAt:        time.Now(),
+	}
+}
+
+// Liveness 存活检查：进程能够响应请求即视为存活
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(h.newReport())
+}
+
+// Readiness 就绪检查：数据库可读、调度服务已初始化，可选探测上游（?upstream=true）
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.newReport()
+	report.Components = make(map[string]models.ComponentHealth)
+
+	// 数据库
+	if err := h.db.Ping(); err != nil {
+		report.Components["database"] = componentDown(err.Error())
+	} else {
+		report.Components["database"] = componentUp("")
+	}
+
+	// 调度服务
+	if h.scheduler == nil || h.scheduler.GetConfig() == nil {
+		report.Components["scheduler"] = componentDown("调度服务未初始化")
+	} else {
+		report.Components["scheduler"] = componentUp(fmt.Sprintf("监控%s", runningText(h.scheduler.IsRunning())))
+	}
+
+	// 自动重置服务
+	if h.autoResetService == nil {
+		report.Components["autoReset"] = componentDown("自动重置服务未初始化")
+	} else {
+		report.Components["autoReset"] = componentUp(fmt.Sprintf("自动重置%s", enabledText(h.autoResetService.IsEnabled())))
+	}
+
+	// 上游探测（可选，避免编排系统频繁探测时产生外部请求）
+	if c.QueryBool("upstream") {
+		report.Components["upstream"] = probeUpstream()
+	}
+
+	status := fiber.StatusOK
+	for _, component := range report.Components {
+		if component.Status != models.HealthStatusUp {
+			report.Status = models.HealthStatusDown
+			status = fiber.StatusServiceUnavailable
+			break
+		}
+	}
+
+	return c.Status(status).JSON(report)
+}
+
+// newReport 创建基础健康报告
+func (h *HealthHandler) newReport() *models.HealthReport {
+	return &models.HealthReport{
+		Status:    models.HealthStatusUp,
+		Version:   Version,
+		Uptime:    time.Since(h.startedAt).Truncate(time.Second).String(),
+		Timestamp: time.Now(),
+	}
+}
+
+// probeUpstream 探测上游API是否可达
+func probeUpstream() models.ComponentHealth {
+	statusCode, latency, err := client.Probe(5 * time.Second)
+	if err != nil {
+		return componentDown(err.Error())
+	}
+	return componentUp(fmt.Sprintf("HTTP %d，%dms", statusCode, latency.Milliseconds()))
+}
+
+// componentUp 构造正常状态的组件
+func componentUp(detail string) models.ComponentHealth {
+	return models.ComponentHealth{Status: models.HealthStatusUp, Detail: detail}
+}
+
+// componentDown 构造异常状态的组件
+func componentDown(detail string) models.ComponentHealth {
+	return models.ComponentHealth{Status: models.HealthStatusDown, Detail: detail}
+}
+
+// runningText 运行状态描述
+func runningText(running bool) string {
+	if running {
+		return "运行中"
+	}
+	return "已停止"
+}
+
+// enabledText 启用状态描述
+func enabledText(enabled bool) string {
+	if enabled {
+		return "已启用"
+	}
+	return "已禁用"
+}
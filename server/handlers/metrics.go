@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/services"
+)
+
+// MetricsPath Prometheus指标接口路径
+const MetricsPath = "/metrics"
+
+// MetricsHandler Prometheus指标处理器（文本格式，供Prometheus等监控系统抓取）
+type MetricsHandler struct {
+	probe *services.UpstreamProbeService // 上游可用性探测服务（未启用探测时为nil）
+}
+
+// NewMetricsHandler 创建指标处理器
+func NewMetricsHandler() *MetricsHandler {
+	return &MetricsHandler{}
+}
+
+// SetUpstreamProbeService 设置上游可用性探测服务引用
+func (h *MetricsHandler) SetUpstreamProbeService(probe *services.UpstreamProbeService) {
+	h.probe = probe
+}
+
+// Metrics 输出Prometheus文本格式的指标
+func (h *MetricsHandler) Metrics(c *fiber.Ctx) error {
+	var m metricsWriter
+	writeLatencyMetrics(&m)
+	writeFailureMetrics(&m)
+	if h.probe != nil {
+		writeProbeMetrics(&m, h.probe.GetRuntimeState())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return c.SendString(m.String())
+}
+
+// writeLatencyMetrics 上游接口响应耗时
+func writeLatencyMetrics(m *metricsWriter) {
+	stats := client.GetLatencyStats()
+
+	m.family("cccmu_upstream_requests_total", "counter", "收到响应的上游请求数（按接口）")
+	for _, s := range stats {
+		m.sample("cccmu_upstream_requests_total", float64(s.Count), "endpoint", s.Endpoint)
+	}
+
+	m.family("cccmu_upstream_latency_seconds", "gauge", "最近100次上游响应耗时的分位数（按接口）")
+	for _, s := range stats {
+		m.sample("cccmu_upstream_latency_seconds", s.P50Ms/1000, "endpoint", s.Endpoint, "quantile", "0.5")
+		m.sample("cccmu_upstream_latency_seconds", s.P95Ms/1000, "endpoint", s.Endpoint, "quantile", "0.95")
+		m.sample("cccmu_upstream_latency_seconds", s.MaxMs/1000, "endpoint", s.Endpoint, "quantile", "1")
+	}
+
+	m.family("cccmu_upstream_last_latency_seconds", "gauge", "最近一次上游响应耗时（按接口）")
+	for _, s := range stats {
+		m.sample("cccmu_upstream_last_latency_seconds", s.LastMs/1000, "endpoint", s.Endpoint)
+	}
+}
+
+// writeFailureMetrics 上游请求失败计数（启动以来，按类别）
+func writeFailureMetrics(m *metricsWriter) {
+	stats := client.GetFailureStats()
+	categories := []struct {
+		name  string
+		count int64
+	}{
+		{"timeout", stats.Total.Timeout},
+		{"network", stats.Total.Network},
+		{"unauthorized", stats.Total.Unauthorized},
+		{"serverError", stats.Total.ServerError},
+		{"httpError", stats.Total.HTTPError},
+		{"parse", stats.Total.Parse},
+	}
+
+	m.family("cccmu_upstream_failures_total", "counter", "启动以来的上游请求失败次数（按类别）")
+	for _, category := range categories {
+		m.sample("cccmu_upstream_failures_total", float64(category.count), "category", category.name)
+	}
+
+	m.family("cccmu_upstream_failures_since_seconds", "gauge", "上游请求失败计数的起始时间（Unix时间戳）")
+	m.sample("cccmu_upstream_failures_since_seconds", float64(stats.Since.Unix()))
+}
+
+// writeProbeMetrics 上游可用性探测结果（还没有探测记录时不输出样本）
+func writeProbeMetrics(m *metricsWriter, state models.UpstreamProbeState) {
+	m.family("cccmu_upstream_up", "gauge", "最近一次上游可用性探测是否可用（1可用，0不可用）")
+	if state.LastProbe != nil {
+		up := 0.0
+		if state.LastProbe.Up {
+			up = 1
+		}
+		m.sample("cccmu_upstream_up", up)
+	}
+
+	m.family("cccmu_upstream_last_probe_seconds", "gauge", "最近一次上游可用性探测时间（Unix时间戳）")
+	if state.LastProbe != nil {
+		m.sample("cccmu_upstream_last_probe_seconds", float64(state.LastProbe.Time.Unix()))
+	}
+
+	m.family("cccmu_upstream_availability_ratio", "gauge", "最近24小时上游可用率（0-1）")
+	if state.Availability != nil {
+		m.sample("cccmu_upstream_availability_ratio", *state.Availability/100)
+	}
+}
+
+// metricsWriter Prometheus文本格式输出
+type metricsWriter struct {
+	b strings.Builder
+}
+
+// family 输出指标的说明和类型
+func (m *metricsWriter) family(name, metricType, help string) {
+	fmt.Fprintf(&m.b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sample 输出一个样本，labels为标签名和标签值交替排列
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.b.WriteString(name)
+	if len(labels) > 0 {
+		m.b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.b.WriteByte(',')
+			}
+			fmt.Fprintf(&m.b, "%s=\"%s\"", labels[i], metricsLabelEscaper.Replace(labels[i+1]))
+		}
+		m.b.WriteByte('}')
+	}
+	m.b.WriteByte(' ')
+	m.b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	m.b.WriteByte('\n')
+}
+
+// String 获取输出内容
+func (m *metricsWriter) String() string {
+	return m.b.String()
+}
+
+// metricsLabelEscaper 标签值转义（反斜杠、双引号和换行）
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	var m metricsWriter
+	m.family("cccmu_test_total", "counter", "测试指标")
+	m.sample("cccmu_test_total", 3)
+	m.sample("cccmu_test_total", 0.25, "endpoint", `/api/"x"\y`+"\n", "quantile", "0.5")
+
+	want := "# HELP cccmu_test_total 测试指标\n" +
+		"# TYPE cccmu_test_total counter\n" +
+		"cccmu_test_total 3\n" +
+		`cccmu_test_total{endpoint="/api/\"x\"\\y\n",quantile="0.5"} 0.25` + "\n"
+	if got := m.String(); got != want {
+		t.Fatalf("输出不一致:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteProbeMetrics(t *testing.T) {
+	var empty metricsWriter
+	writeProbeMetrics(&empty, models.UpstreamProbeState{})
+	if strings.Contains(empty.String(), "\ncccmu_upstream_") {
+		t.Errorf("没有探测记录时不应输出样本:\n%s", empty.String())
+	}
+
+	availability := 99.5
+	var m metricsWriter
+	writeProbeMetrics(&m, models.UpstreamProbeState{
+		LastProbe:    &models.UpstreamProbe{Time: time.Unix(1700000000, 0), Up: true},
+		Availability: &availability,
+	})
+	for _, want := range []string{
+		"cccmu_upstream_up 1\n",
+		"cccmu_upstream_last_probe_seconds 1.7e+09\n",
+		"cccmu_upstream_availability_ratio 0.995\n",
+	} {
+		if !strings.Contains(m.String(), want) {
+			t.Errorf("输出应包含 %q:\n%s", want, m.String())
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// replicaRequestTimeout 转发普通请求的超时时间（SSE数据流不受限制）
+const replicaRequestTimeout = 30 * time.Second
+
+// replicaForwardHeaders 转发到主实例的请求头
+var replicaForwardHeaders = []string{
+	fiber.HeaderAccept,
+	fiber.HeaderAcceptLanguage,
+	fiber.HeaderIfNoneMatch,
+	fiber.HeaderIfModifiedSince,
+	fiber.HeaderCacheControl,
+	"Last-Event-ID",
+}
+
+// replicaAllowedPaths 副本转发的仪表盘只读接口，其余接口（配置、导出、运维信息和订阅令牌）仅在主实例上可见
+var replicaAllowedPaths = map[string]bool{
+	"/control/status":        true,
+	"/balance":               true,
+	"/balance/curve":         true,
+	"/reset/suggestion":      true,
+	"/reset/report":          true,
+	"/usage/stream":          true,
+	"/usage/data":            true,
+	"/usage/series":          true,
+	"/usage/trend":           true,
+	"/usage/errors":          true,
+	"/sessions":              true,
+	"/snapshot":              true,
+	"/annotations":           true,
+	"/history":               true,
+	"/history/export":        true,
+	"/history/same-day":      true,
+	"/upstream/availability": true,
+}
+
+// replicaDroppedEvents 副本不转发的SSE事件（配置仅在主实例上可见）
+var replicaDroppedEvents = map[string]bool{"config": true}
+
+// ReplicaProxy 只读副本代理：将读取类API请求转发到主实例（含SSE数据流），自身不访问上游
+type ReplicaProxy struct {
+	primaryURL string       // 主实例地址
+	accessKey  string       // 主实例访问密钥
+	client     *http.Client // 转发使用的HTTP客户端
+}
+
+// NewReplicaProxy 创建只读副本代理
+func NewReplicaProxy(primaryURL, accessKey string) (*ReplicaProxy, error) {
+	parsed, err := url.Parse(primaryURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("主实例地址无效: %s", primaryURL)
+	}
+	if accessKey == "" {
+		return nil, fmt.Errorf("缺少主实例访问密钥")
+	}
+	return &ReplicaProxy{
+		primaryURL: strings.TrimRight(primaryURL, "/"),
+		accessKey:  accessKey,
+		client:     &http.Client{},
+	}, nil
+}
+
+// PrimaryURL 获取主实例地址
+func (p *ReplicaProxy) PrimaryURL() string {
+	return p.primaryURL
+}
+
+// Handle 转发API请求到主实例的v1接口（注册在 /* 路由下，修改类请求一律拒绝）
+func (p *ReplicaProxy) Handle(c *fiber.Ctx) error {
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return c.Status(403).JSON(models.Error(403, i18n.T(c, "只读副本不支持此操作"), nil))
+	}
+
+	path := "/" + c.Params("*")
+	if !replicaAllowedPaths[path] {
+		return c.Status(403).JSON(models.Error(403, i18n.T(c, "只读副本不支持此操作"), nil))
+	}
+
+	target := p.primaryURL + "/api/v1" + path
+	if query := string(c.Request().URI().QueryString()); query != "" {
+		target += "?" + query
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, c.Method(), target, nil)
+	if err != nil {
+		cancel()
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "主实例不可用"), err))
+	}
+	for _, header := range replicaForwardHeaders {
+		if value := c.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+p.accessKey)
+
+	// 普通请求设置超时，SSE数据流在客户端断开前持续转发
+	timer := time.AfterFunc(replicaRequestTimeout, cancel)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		timer.Stop()
+		cancel()
+		log.Printf("[只读副本] 请求主实例失败: %s %v", path, err)
+		return c.Status(502).JSON(models.Error(502, i18n.T(c, "主实例不可用"), err))
+	}
+
+	c.Status(resp.StatusCode)
+	for _, header := range []string{fiber.HeaderContentType, fiber.HeaderCacheControl, fiber.HeaderETag, fiber.HeaderLastModified, fiber.HeaderContentDisposition, fiber.HeaderRetryAfter} {
+		if value := resp.Header.Get(header); value != "" {
+			c.Set(header, value)
+		}
+	}
+
+	if !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/event-stream") {
+		defer cancel()
+		defer timer.Stop()
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return c.Status(502).JSON(models.Error(502, i18n.T(c, "主实例不可用"), err))
+		}
+		return c.Send(body)
+	}
+
+	timer.Stop()
+	c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer resp.Body.Close()
+
+		// 按事件（空行分隔）转发，跳过副本不可见的事件
+		reader := bufio.NewReader(resp.Body)
+		var block strings.Builder
+		dropped := false
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				block.WriteString(line)
+				if name, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "event:"); ok && replicaDroppedEvents[strings.TrimSpace(name)] {
+					dropped = true
+				}
+				if strings.TrimRight(line, "\r\n") == "" {
+					if !dropped {
+						if _, writeErr := w.WriteString(block.String()); writeErr != nil {
+							return
+						}
+						// 客户端断开时Flush失败，结束转发并关闭到主实例的连接
+						if flushErr := w.Flush(); flushErr != nil {
+							return
+						}
+					}
+					block.Reset()
+					dropped = false
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("[只读副本] 数据流转发中断: %v", err)
+				}
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newReplicaTestApp 创建转发到指定主实例的副本应用
+func newReplicaTestApp(t *testing.T, primary *httptest.Server) *fiber.App {
+	t.Helper()
+	proxy, err := NewReplicaProxy(primary.URL, "primary-key")
+	if err != nil {
+		t.Fatalf("创建副本代理失败: %v", err)
+	}
+	app := fiber.New()
+	app.All("/api/v1/*", proxy.Handle)
+	return app
+}
+
+func TestReplicaRejectsNonDashboardPaths(t *testing.T) {
+	var hits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"code":200}`))
+	}))
+	defer primary.Close()
+	app := newReplicaTestApp(t, primary)
+
+	for _, path := range []string{"/export/sqlite", "/config", "/config/sync", "/admin/backup", "/feeds"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1"+path, nil))
+		if err != nil {
+			t.Fatalf("请求 %s 失败: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusForbidden {
+			t.Errorf("%s 应返回 403，实际 %d", path, resp.StatusCode)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("被拒绝的请求不应转发到主实例，实际转发 %d 次", n)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/balance", nil))
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK || hits.Load() != 1 {
+		t.Errorf("看板接口应转发到主实例，状态码 %d，转发 %d 次", resp.StatusCode, hits.Load())
+	}
+}
+
+func TestReplicaDropsConfigEvents(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "event: connected\ndata: {}\n\n"+
+			"event: config\ndata: {\"cookie\":true}\n\n"+
+			"event: balance\ndata: {\"remaining\":1}\n\n")
+	}))
+	defer primary.Close()
+	app := newReplicaTestApp(t, primary)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/usage/stream", nil), -1)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	want := "event: connected\ndata: {}\n\nevent: balance\ndata: {\"remaining\":1}\n\n"
+	if string(body) != want {
+		t.Errorf("转发的数据流不一致:\n%q\nwant:\n%q", body, want)
+	}
+	if strings.Contains(string(body), "event: config") {
+		t.Error("副本不应转发 config 事件")
+	}
+}
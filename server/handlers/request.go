@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// parseJSONBody 严格解析JSON请求体：拒绝未知字段（及早发现字段名拼写错误）和类型不符的值
+// 解析失败时返回models.ValidationErrors，响应中会列出出错的字段
+func parseJSONBody(c *fiber.Ctx, out any) error {
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+
+	var errs models.ValidationErrors
+	if err := decoder.Decode(out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		var syntaxErr *json.SyntaxError
+		switch {
+		case errors.Is(err, io.EOF):
+			errs.Addf("", "请求体不能为空")
+		case errors.As(err, &typeErr):
+			errs.Addf(typeErr.Field, "类型错误，应为%s", jsonTypeName(typeErr.Type))
+		case errors.As(err, &syntaxErr):
+			errs.Addf("", "JSON格式错误（位置%d）: %v", syntaxErr.Offset, err)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			errs.Addf(strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`), "未知字段")
+		default:
+			errs.Addf("", "%v", err)
+		}
+		return errs
+	}
+	if decoder.More() {
+		errs.Addf("", "请求体只能包含一个JSON对象")
+		return errs
+	}
+	return nil
+}
+
+// jsonTypeName 将Go类型转换为JSON类型名称
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "数字"
+	case reflect.Bool:
+		return "布尔值"
+	case reflect.String:
+		return "字符串"
+	case reflect.Slice, reflect.Array:
+		return "数组"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	}
+	return "对象"
+}
@@ -5,20 +5,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/leafney/cccmu/server/auth"
 	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/i18n"
 	"github.com/leafney/cccmu/server/models"
 	"github.com/leafney/cccmu/server/services"
 )
 
+// usageResyncInterval 增量推送模式下推送全部使用记录的间隔，校正客户端累积的数据
+const usageResyncInterval = 10 * time.Minute
+
 // SSEHandler SSE处理器
 type SSEHandler struct {
 	db          *database.BadgerDB
 	scheduler   *services.SchedulerService
 	authManager *auth.Manager
+	draining    bool           // 是否正在关闭（不再接受新连接）
+	handoff     bool           // 是否为平滑重启（连接推送server_handoff事件，客户端立即重连到新进程）
+	shutdownCh  chan struct{}  // 关闭信号，关闭后所有连接推送server_shutdown（或server_handoff）事件并退出
+	streams     sync.WaitGroup // 活跃的SSE连接
+	mu          sync.Mutex
 }
 
 // NewSSEHandler 创建SSE处理器
@@ -27,6 +37,7 @@ func NewSSEHandler(db *database.BadgerDB, scheduler *services.SchedulerService,
 		db:          db,
 		scheduler:   scheduler,
 		authManager: authManager,
+		shutdownCh:  make(chan struct{}),
 	}
 
 	// 注册会话事件监听器
@@ -47,14 +58,71 @@ func (h *SSEHandler) handleSessionEvent(event auth.SessionEvent) {
 	}
 }
 
+// Drain 优雅关闭所有SSE连接：拒绝新连接，向现有连接推送server_shutdown事件，并等待写入完成（最多timeout）
+func (h *SSEHandler) Drain(timeout time.Duration) {
+	h.closeStreams(timeout, false)
+}
+
+// Handoff 平滑重启时结束所有SSE连接：推送server_handoff事件，客户端不等待、立即重连，
+// 由继承了监听套接字的新进程接受连接（调用前应已停止接受新连接）
+func (h *SSEHandler) Handoff(timeout time.Duration) {
+	h.closeStreams(timeout, true)
+}
+
+// closeStreams 通知所有连接退出并等待写入完成（最多timeout）
+func (h *SSEHandler) closeStreams(timeout time.Duration, handoff bool) {
+	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		return
+	}
+	h.draining = true
+	h.handoff = handoff
+	close(h.shutdownCh)
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.streams.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("SSE: 所有连接已关闭")
+	case <-time.After(timeout):
+		log.Printf("SSE: 等待连接关闭超时（%v），继续关闭服务", timeout)
+	}
+}
+
 // StreamUsageData SSE数据流端点
 func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 	// 验证认证状态（由于已经通过中间件，这里再次检查以确保安全）
+	// 使用访问密钥直接认证的连接（如终端监控）和反向代理认证的连接没有会话，不受会话过期影响
 	sessionID := c.Cookies("cccmu_session")
-	if _, valid := h.authManager.ValidateSession(sessionID); !valid {
-		return c.Status(401).JSON(models.Error(401, "认证无效", nil))
+	keyAuth, _ := c.Locals("keyAuth").(bool)
+	proxyUser, _ := c.Locals("proxyUser").(string)
+	sessionValid := func() bool {
+		if keyAuth || proxyUser != "" {
+			return true
+		}
+		_, valid := h.authManager.ValidateSession(sessionID)
+		return valid
+	}
+	if !sessionValid() {
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "认证无效"), nil))
 	}
 
+	// 服务关闭中不再接受新连接
+	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		c.Set("Retry-After", "5")
+		return c.Status(503).JSON(models.Error(503, i18n.T(c, "服务正在关闭"), nil))
+	}
+	h.streams.Add(1)
+	h.mu.Unlock()
+
 	// 设置SSE响应头
 	c.Set("Content-Type", "text/event-stream")
 	c.Set("Cache-Control", "no-cache")
@@ -68,66 +136,85 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 		minutes = 60
 	}
 
+	// 增量模式：连接建立和定期校正时推送全部记录（usage），其余时候只推送新增记录（usage_delta）
+	delta := c.QueryBool("delta")
+
+	// 推送事件中的提示信息使用的语言
+	lang := i18n.Lang(c)
+
 	// 获取上下文，避免在goroutine中访问可能已释放的context
 	ctx := c.Context()
 
 	// 使用Fiber的流式响应
 	c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
-		// 立即发送连接确认
-		fmt.Fprintf(w, "event: connected\ndata: {\"status\":\"connected\"}\n\n")
+		defer h.streams.Done()
+
+		// 立即发送连接确认（附带账户标签，便于客户端区分账户）
+		connectedData, _ := json.Marshal(map[string]any{
+			"status":  "connected",
+			"account": h.accountLabel(),
+		})
+		fmt.Fprintf(w, "event: connected\ndata: %s\n\n", connectedData)
 		w.Flush()
 
-		// 立即发送当前数据
-		allData := h.scheduler.GetLatestData()
-		filteredData := models.UsageDataList(allData).FilterByTimeRange(minutes)
-
-		if len(filteredData) > 0 {
-			jsonData, err := json.Marshal(filteredData)
-			if err != nil {
-				return
-			}
-			fmt.Fprintf(w, "event: usage\ndata: %s\n\n", jsonData)
-			w.Flush()
+		// 增量模式下记录已推送的使用记录，使用数据由sendUsage推送
+		var cursor *services.UsageCursor
+		var lastResync time.Time
+		if delta {
+			cursor = services.NewUsageCursor()
 		}
+		sendUsage := func() error {
+			if !delta {
+				// 按时间范围取缓冲中已编码的数据发送，无需重新序列化全部记录
+				if jsonData := h.scheduler.GetLatestDataJSON(minutes); jsonData != nil {
+					fmt.Fprintf(w, "event: usage\ndata: %s\n\n", jsonData)
+					return w.Flush()
+				}
+				return nil
+			}
 
-		// 立即发送当前积分余额
-		balance := h.scheduler.GetLatestBalance()
-		if balance != nil {
-			jsonData, err := json.Marshal(balance)
-			if err == nil {
-				fmt.Fprintf(w, "event: balance\ndata: %s\n\n", jsonData)
-				w.Flush()
+			if time.Since(lastResync) >= usageResyncInterval {
+				cursor.Reset()
+				lastResync = time.Now()
+				jsonData := h.scheduler.GetNewDataJSON(minutes, cursor)
+				if jsonData == nil {
+					jsonData = []byte("[]")
+				}
+				fmt.Fprintf(w, "event: usage\ndata: %s\n\n", jsonData)
+				return w.Flush()
 			}
-		}
 
-		// 立即发送当前重置状态
-		config, err := h.db.GetConfig()
-		if err == nil {
-			resetData := map[string]any{
-				"type":      "reset_status",
-				"resetUsed": config.DailyResetUsed,
-				"timestamp": time.Now().Format(time.RFC3339),
+			// 客户端将新增记录并入已有数据，并移除早于cutoff的记录
+			records := h.scheduler.GetNewDataJSON(minutes, cursor)
+			if records == nil {
+				records = []byte("[]")
 			}
-			jsonData, err := json.Marshal(resetData)
-			if err == nil {
-				fmt.Fprintf(w, "event: reset_status\ndata: %s\n\n", jsonData)
-				w.Flush()
+			jsonData, err := json.Marshal(map[string]any{
+				"records": json.RawMessage(records),
+				"cutoff":  time.Now().Add(-time.Duration(minutes) * time.Minute).Format(time.RFC3339),
+			})
+			if err != nil {
+				return nil
 			}
+			fmt.Fprintf(w, "event: usage_delta\ndata: %s\n\n", jsonData)
+			return w.Flush()
 		}
 
-		// 立即发送当前监控状态和自动调度状态
-		statusData := map[string]any{
-			"type":                "monitoring_status",
-			"isMonitoring":        h.scheduler.IsRunning(),
-			"autoScheduleEnabled": h.scheduler.IsAutoScheduleEnabled(),
-			"autoScheduleActive":  h.scheduler.IsInAutoScheduleTimeRange(),
-			"timestamp":           time.Now().Format(time.RFC3339),
-		}
-		jsonData, err := json.Marshal(statusData)
-		if err == nil {
-			fmt.Fprintf(w, "event: monitoring_status\ndata: %s\n\n", jsonData)
+		// 立即发送当前状态（与快照接口内容一致）
+		for _, event := range h.initialEvents(minutes) {
+			if delta && event.name == "usage" {
+				continue
+			}
+			jsonData, err := json.Marshal(event.data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, jsonData)
 			w.Flush()
 		}
+		if delta {
+			sendUsage()
+		}
 
 		// 添加数据监听器
 		listener := h.scheduler.AddDataListener()
@@ -136,6 +223,11 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 		resetStatusListener := h.scheduler.AddResetStatusListener()
 		autoScheduleListener := h.scheduler.AddAutoScheduleListener()
 		dailyUsageListener := h.scheduler.AddDailyUsageListener()
+		notificationListener := h.scheduler.AddNotificationListener()
+		healthListener := h.scheduler.AddHealthListener()
+		configListener := h.scheduler.AddConfigListener()
+		tickListener := h.scheduler.AddTickListener()
+		annotationListener := h.scheduler.AddAnnotationListener()
 		defer func() {
 			h.scheduler.RemoveDataListener(listener)
 			h.scheduler.RemoveBalanceListener(balanceListener)
@@ -143,6 +235,11 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 			h.scheduler.RemoveResetStatusListener(resetStatusListener)
 			h.scheduler.RemoveAutoScheduleListener(autoScheduleListener)
 			h.scheduler.RemoveDailyUsageListener(dailyUsageListener)
+			h.scheduler.RemoveNotificationListener(notificationListener)
+			h.scheduler.RemoveHealthListener(healthListener)
+			h.scheduler.RemoveConfigListener(configListener)
+			h.scheduler.RemoveTickListener(tickListener)
+			h.scheduler.RemoveAnnotationListener(annotationListener)
 		}()
 
 		// 设置连接保活
@@ -152,23 +249,13 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 		// 监听新数据和保活
 		for {
 			select {
-			case data, ok := <-listener:
+			case _, ok := <-listener:
 				if !ok {
 					return // 监听器已关闭
 				}
 
-				// 按时间范围过滤数据后发送
-				filteredData := models.UsageDataList(data).FilterByTimeRange(minutes)
-
-				if len(filteredData) > 0 {
-					jsonData, err := json.Marshal(filteredData)
-					if err != nil {
-						continue
-					}
-					fmt.Fprintf(w, "event: usage\ndata: %s\n\n", jsonData)
-					if err := w.Flush(); err != nil {
-						return
-					}
+				if err := sendUsage(); err != nil {
+					return
 				}
 
 			case balance, ok := <-balanceListener:
@@ -176,8 +263,8 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 					return // 监听器已关闭
 				}
 
-				// 发送积分余额数据
-				jsonData, err := json.Marshal(balance)
+				// 发送积分余额数据（附带变化量）
+				jsonData, err := json.Marshal(h.scheduler.BalanceChange(balance))
 				if err != nil {
 					continue
 				}
@@ -186,17 +273,20 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 					return
 				}
 
-			case errorMsg, ok := <-errorListener:
+			case errorEvent, ok := <-errorListener:
 				if !ok {
 					return // 监听器已关闭
 				}
 
-				// 发送错误信息
+				// 发送错误信息（附带错误码，便于客户端区分错误类型）
 				errorData := map[string]any{
 					"type":      "error",
-					"message":   errorMsg,
+					"message":   errorEvent.Message,
 					"timestamp": time.Now().Format(time.RFC3339),
 				}
+				if errorEvent.Code != "" {
+					errorData["code"] = errorEvent.Code
+				}
 				jsonData, err := json.Marshal(errorData)
 				if err != nil {
 					continue
@@ -233,6 +323,8 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 					"isMonitoring":        h.scheduler.IsRunning(),
 					"autoScheduleEnabled": h.scheduler.IsAutoScheduleEnabled(),
 					"autoScheduleActive":  h.scheduler.IsInAutoScheduleTimeRange(),
+					"maintenance":         h.scheduler.GetMaintenanceStatus(),
+					"account":             h.accountLabel(),
 					"timestamp":           time.Now().Format(time.RFC3339),
 				}
 				jsonData, err := json.Marshal(statusData)
@@ -259,13 +351,91 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 					return
 				}
 
+			case notification, ok := <-notificationListener:
+				if !ok {
+					return // 监听器已关闭
+				}
+
+				// 发送通知消息
+				jsonData, err := json.Marshal(notification)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: notification\ndata: %s\n\n", jsonData)
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+			case health, ok := <-healthListener:
+				if !ok {
+					return // 监听器已关闭
+				}
+
+				// 发送健康状态
+				jsonData, err := json.Marshal(health)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: health\ndata: %s\n\n", jsonData)
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+			case config, ok := <-configListener:
+				if !ok {
+					return // 监听器已关闭
+				}
+
+				// 模型分组可能变化，增量模式下次推送全部记录
+				lastResync = time.Time{}
+
+				// 发送变更后的配置（Cookie已脱敏为布尔值）
+				jsonData, err := json.Marshal(config)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: config\ndata: %s\n\n", jsonData)
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+			case tick, ok := <-tickListener:
+				if !ok {
+					return // 监听器已关闭
+				}
+
+				// 发送定时任务执行事件
+				jsonData, err := json.Marshal(tick)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: tick\ndata: %s\n\n", jsonData)
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+			case annotation, ok := <-annotationListener:
+				if !ok {
+					return // 监听器已关闭
+				}
+
+				// 发送标注变化（新建或删除）
+				jsonData, err := json.Marshal(annotation)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: annotation\ndata: %s\n\n", jsonData)
+				if err := w.Flush(); err != nil {
+					return
+				}
+
 			case <-ticker.C:
 				// 检查认证状态
-				if _, valid := h.authManager.ValidateSession(sessionID); !valid {
+				if !sessionValid() {
 					// 发送认证过期事件
 					authExpired := map[string]any{
 						"type":      "auth_expired",
-						"message":   "登录已过期",
+						"message":   i18n.Translate(lang, "登录已过期"),
 						"timestamp": time.Now().Format(time.RFC3339),
 					}
 					jsonData, err := json.Marshal(authExpired)
@@ -290,6 +460,33 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 					return
 				}
 
+			case <-h.shutdownCh:
+				// 平滑重启：新进程已在同一套接字上等待，通知客户端立即重连
+				if h.handoff {
+					handoffData := map[string]any{
+						"type":      "server_handoff",
+						"timestamp": time.Now().Format(time.RFC3339),
+					}
+					if jsonData, err := json.Marshal(handoffData); err == nil {
+						fmt.Fprintf(w, "retry: 0\nevent: server_handoff\ndata: %s\n\n", jsonData)
+						w.Flush()
+					}
+					return
+				}
+				// 服务关闭，通知客户端稍后重连，避免连接中断后频繁重试
+				shutdownData := map[string]any{
+					"type":       "server_shutdown",
+					"message":    i18n.Translate(lang, "服务正在重启"),
+					"retryAfter": 5000,
+					"timestamp":  time.Now().Format(time.RFC3339),
+				}
+				jsonData, err := json.Marshal(shutdownData)
+				if err == nil {
+					fmt.Fprintf(w, "retry: 5000\nevent: server_shutdown\ndata: %s\n\n", jsonData)
+					w.Flush()
+				}
+				return
+
 			case <-ctx.Done():
 				return
 			}
@@ -299,6 +496,100 @@ func (h *SSEHandler) StreamUsageData(c *fiber.Ctx) error {
 	return nil
 }
 
+// sseEvent 一条SSE事件
+type sseEvent struct {
+	name string
+	data any
+}
+
+// initialEvents 连接建立时推送的当前状态事件（按推送顺序），SSE连接和快照接口共用
+// 无数据的使用记录、积分余额和健康状态不推送
+func (h *SSEHandler) initialEvents(minutes int) []sseEvent {
+	var events []sseEvent
+
+	// 当前时间范围内的使用数据
+	if usage := h.scheduler.GetLatestDataJSON(minutes); usage != nil {
+		events = append(events, sseEvent{"usage", json.RawMessage(usage)})
+	}
+
+	// 当前积分余额
+	if balance := h.scheduler.GetLatestBalance(); balance != nil {
+		events = append(events, sseEvent{"balance", h.scheduler.BalanceChange(balance)})
+	}
+
+	// 当前重置状态
+	if config, err := h.db.GetConfig(); err == nil {
+		events = append(events, sseEvent{"reset_status", map[string]any{
+			"type":      "reset_status",
+			"resetUsed": config.IsDailyResetUsed(time.Now()),
+			"timestamp": time.Now().Format(time.RFC3339),
+		}})
+	}
+
+	// 当前监控状态和自动调度状态
+	events = append(events, sseEvent{"monitoring_status", map[string]any{
+		"type":                "monitoring_status",
+		"isMonitoring":        h.scheduler.IsRunning(),
+		"autoScheduleEnabled": h.scheduler.IsAutoScheduleEnabled(),
+		"autoScheduleActive":  h.scheduler.IsInAutoScheduleTimeRange(),
+		"maintenance":         h.scheduler.GetMaintenanceStatus(),
+		"account":             h.accountLabel(),
+		"timestamp":           time.Now().Format(time.RFC3339),
+	}})
+
+	// 最近一周的积分历史统计
+	events = append(events, sseEvent{"daily_usage", h.scheduler.GetWeeklyUsageFilled()})
+
+	// 当前健康状态
+	if health := h.scheduler.GetLatestHealthState(); health != nil {
+		events = append(events, sseEvent{"health", health})
+	}
+
+	// 当前时间范围内的图表标注
+	if annotations, err := h.db.GetAnnotations(time.Now().Add(-time.Duration(minutes) * time.Minute)); err == nil {
+		events = append(events, sseEvent{"annotations", annotations})
+	}
+
+	return events
+}
+
+// GetSnapshot 获取当前状态快照：内容与SSE连接建立时推送的事件一致，以事件名为键合并为一个JSON文档
+// 供不使用SSE的客户端和测试一次性获取一致的状态
+func (h *SSEHandler) GetSnapshot(c *fiber.Ctx) error {
+	// 与SSE连接一致：使用访问密钥或反向代理认证的请求没有会话
+	keyAuth, _ := c.Locals("keyAuth").(bool)
+	proxyUser, _ := c.Locals("proxyUser").(string)
+	if _, valid := h.authManager.ValidateSession(c.Cookies("cccmu_session")); !valid && !keyAuth && proxyUser == "" {
+		return c.Status(401).JSON(models.Error(401, i18n.T(c, "认证无效"), nil))
+	}
+
+	minutes := c.QueryInt("minutes", 60)
+	if minutes <= 0 {
+		minutes = 60
+	}
+
+	// 未推送的事件以空值占位，保证文档结构固定
+	snapshot := map[string]any{
+		"usage":   models.UsageDataList{},
+		"balance": nil,
+		"health":  nil,
+	}
+	for _, event := range h.initialEvents(minutes) {
+		snapshot[event.name] = event.data
+	}
+
+	return c.JSON(models.Success(snapshot))
+}
+
+// accountLabel 获取当前账户标签
+func (h *SSEHandler) accountLabel() models.AccountLabel {
+	config, err := h.db.GetConfig()
+	if err != nil {
+		return models.AccountLabel{}
+	}
+	return config.Account
+}
+
 // GetUsageData 获取历史数据
 func (h *SSEHandler) GetUsageData(c *fiber.Ctx) error {
 	// 获取时间范围参数
@@ -309,7 +600,100 @@ func (h *SSEHandler) GetUsageData(c *fiber.Ctx) error {
 
 	// 从调度器获取最新数据并按时间范围过滤
 	allData := h.scheduler.GetLatestData()
-	filteredData := models.UsageDataList(allData).FilterByTimeRange(minutes)
+	filteredData := models.UsageDataList(allData).FilterByTimeRange(minutes).WithGroups()
+	if wantsCSV(c) {
+		return sendUsageDataCSV(c, filteredData)
+	}
 
 	return c.JSON(models.Success(filteredData))
 }
+
+// GetUsageTrend 获取最近指定天数的15分钟降采样积分使用数据（原始记录超过保留时长后的长期趋势）
+func (h *SSEHandler) GetUsageTrend(c *fiber.Ctx) error {
+	maxDays := services.RetentionOf(h.scheduler.GetConfig()).AggregateDays
+	days := c.QueryInt("days", 30)
+	if days <= 0 || days > maxDays {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", maxDays), nil))
+	}
+
+	aggregates, err := h.db.GetUsageAggregates(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Printf("获取降采样数据失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取使用趋势失败"), err))
+	}
+	if aggregates == nil {
+		aggregates = []models.UsageAggregate{}
+	}
+	if wantsCSV(c) {
+		return sendUsageAggregatesCSV(c, aggregates)
+	}
+
+	return c.JSON(models.Success(aggregates))
+}
+
+// GetUsageSessions 获取最近指定天数的使用会话（相邻记录间隔小于gap分钟的归为同一会话）
+func (h *SSEHandler) GetUsageSessions(c *fiber.Ctx) error {
+	// 会话基于原始使用记录划分，天数受原始记录保留时长限制
+	maxDays := (services.RetentionOf(h.scheduler.GetConfig()).UsageHours + 23) / 24
+	days := c.QueryInt("days", 1)
+	if days <= 0 || days > maxDays {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "days取值范围为1-%d", maxDays), nil))
+	}
+
+	gap := c.QueryInt("gap", models.DefaultSessionGapMinutes)
+	if gap <= 0 || gap > models.MaxSessionGapMinutes {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "gap取值范围为1-%d", models.MaxSessionGapMinutes), nil))
+	}
+
+	usageList, err := h.db.GetUsageData(days * 24 * 60)
+	if err != nil {
+		log.Printf("获取使用记录失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取使用会话失败"), err))
+	}
+
+	return c.JSON(models.Success(usageList.Sessions(time.Duration(gap) * time.Minute)))
+}
+
+// GetRelayErrors 获取最近指定小时数内使用记录的429/5xx统计
+func (h *SSEHandler) GetRelayErrors(c *fiber.Ctx) error {
+	maxHours := services.RetentionOf(h.scheduler.GetConfig()).UsageHours
+	hours := c.QueryInt("hours", 24)
+	if hours <= 0 || hours > maxHours {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "hours取值范围为1-%d", maxHours), nil))
+	}
+
+	usageList, err := h.db.GetUsageData(hours * 60)
+	if err != nil {
+		log.Printf("获取使用记录失败: %v", err)
+		return c.Status(500).JSON(models.Error(500, i18n.T(c, "获取错误率统计失败"), err))
+	}
+
+	return c.JSON(models.Success(usageList.RelayErrors(hours, time.Now())))
+}
+
+// GetUsageSeries 获取单个模型（或模型分组）的分桶积分使用时间序列
+func (h *SSEHandler) GetUsageSeries(c *fiber.Ctx) error {
+	model := c.Query("model")
+	if model == "" {
+		return c.Status(400).JSON(models.Error(400, i18n.T(c, "缺少model参数"), nil))
+	}
+
+	minutes := c.QueryInt("minutes", 60)
+	if minutes <= 0 || minutes > models.MaxSeriesMinutes {
+		return c.Status(400).JSON(models.Error(400, i18n.Tf(c, "minutes取值范围为1-%d", models.MaxSeriesMinutes), nil))
+	}
+
+	bucket := models.DefaultSeriesBucket
+	if raw := c.Query("bucket"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < models.MinSeriesBucket || parsed > models.MaxSeriesBucket {
+			return c.Status(400).JSON(models.Error(400, i18n.T(c, "bucket取值范围为1m-1h"), err))
+		}
+		bucket = parsed
+	}
+
+	allData := h.scheduler.GetLatestData()
+	series := models.UsageDataList(allData).Series(model, bucket, minutes, time.Now())
+
+	return c.JSON(models.Success(series))
+}
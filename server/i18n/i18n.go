@@ -0,0 +1,63 @@
+// Package i18n API提示信息的多语言支持
+// 消息目录以中文原文作为键，未收录的消息原样返回，因此新增提示信息时不翻译也不影响使用
+package i18n
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// 支持的语言
+const (
+	LangZH = "zh" // 中文（默认，即消息原文）
+	LangEN = "en" // 英文
+)
+
+// DefaultLang 请求未声明或声明了不支持的语言时使用的语言
+const DefaultLang = LangZH
+
+// SupportedLangs 按优先级排列的支持语言（Accept-Language权重相同时靠前者优先）
+var SupportedLangs = []string{LangZH, LangEN}
+
+// localsKey 协商结果在请求上下文中的键
+const localsKey = "lang"
+
+// Negotiate 根据Accept-Language请求头协商响应语言
+func Negotiate(c *fiber.Ctx) string {
+	if lang := c.AcceptsLanguages(SupportedLangs...); lang != "" {
+		return lang
+	}
+	return DefaultLang
+}
+
+// SetLang 将协商结果保存到请求上下文
+func SetLang(c *fiber.Ctx, lang string) {
+	c.Locals(localsKey, lang)
+}
+
+// Lang 获取当前请求的响应语言，未经语言中间件处理时现场协商
+func Lang(c *fiber.Ctx) string {
+	if lang, ok := c.Locals(localsKey).(string); ok {
+		return lang
+	}
+	return Negotiate(c)
+}
+
+// Translate 将中文消息翻译为指定语言，未收录时返回原文
+func Translate(lang, message string) string {
+	if translated, ok := catalog[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// T 将中文消息翻译为当前请求的响应语言
+func T(c *fiber.Ctx, message string) string {
+	return Translate(Lang(c), message)
+}
+
+// Tf 翻译格式化消息（以中文格式串作为键）后填充参数
+func Tf(c *fiber.Ctx, format string, args ...any) string {
+	return fmt.Sprintf(T(c, format), args...)
+}
@@ -0,0 +1,132 @@
+package i18n
+
+// catalog 消息目录：语言 → 中文原文 → 译文
+var catalog = map[string]map[string]string{
+	LangEN: {
+		// 通用
+		"请求参数错误":       "Invalid request parameters",
+		"请求过于频繁，请稍后再试": "Too many requests, please try again later",
+		"服务正在关闭":       "Server is shutting down",
+		"服务正在重启":       "Server is restarting",
+
+		// 上游请求错误
+		"Cookie未配置或已失效，请更新Cookie": "Cookie is missing or expired, please update it",
+		"上游请求超时":                  "Upstream request timed out",
+		"上游请求过于频繁，请稍后再试":          "Upstream is rate limiting requests, please try again later",
+		"上游请求失败":                  "Upstream request failed",
+		"上游响应解析失败":                "Failed to parse upstream response",
+
+		// 认证
+		"未授权访问": "Unauthorized",
+		"认证失败次数过多，请稍后再试":   "Too many failed authentication attempts, please try again later",
+		"此操作仅限登录会话或访问密钥调用": "This operation requires a login session or the access key",
+		"会话无效或已过期":         "Session is invalid or has expired",
+		"认证无效":             "Authentication is invalid",
+		"缺少访问密钥":           "Access key is required",
+		"访问密钥不能为空":         "Access key must not be empty",
+		"访问密钥错误":           "Incorrect access key",
+		"创建会话失败":           "Failed to create session",
+		"登录成功":             "Login successful",
+		"登出成功":             "Logged out",
+		"登录已过期":            "Login has expired",
+
+		// 登录记录
+		"获取登录记录失败":   "Failed to load login history",
+		"result参数无效": "result must be success or failure",
+
+		// 配置
+		"获取配置失败":         "Failed to load configuration",
+		"配置验证失败":         "Configuration validation failed",
+		"保存配置失败":         "Failed to save configuration",
+		"更新配置失败":         "Failed to update configuration",
+		"更新自动重置配置失败":     "Failed to update auto-reset configuration",
+		"更新Cookie保活配置失败": "Failed to update cookie keep-alive configuration",
+		"配置更新成功":         "Configuration updated",
+		"清除Cookie失败":     "Failed to clear cookie",
+		"Cookie已清除":      "Cookie cleared",
+		"limit取值范围为1-%d": "limit must be between 1 and %d",
+		"获取配置审计记录失败":     "Failed to load configuration audit log",
+		"撤销配置失败":         "Failed to undo configuration change",
+		"没有可撤销的配置修改":     "No configuration change to undo",
+		"配置已撤销":          "Configuration change undone",
+		"已启用阈值重置，但监控未运行，不会检查积分余额": "Threshold reset is enabled, but monitoring is not running, so the balance will not be checked",
+
+		// 监控任务与积分
+		"启动任务失败":          "Failed to start monitoring",
+		"任务启动成功":          "Monitoring started",
+		"停止任务失败":          "Failed to stop monitoring",
+		"任务停止成功":          "Monitoring stopped",
+		"刷新数据失败":          "Failed to refresh data",
+		"数据刷新成功":          "Data refreshed",
+		"请先配置Cookie":      "Please configure the cookie first",
+		"获取积分余额曲线失败":      "Failed to load the balance curve",
+		"当前重置周期已使用过重置":    "Reset already used in the current period",
+		"历史数据不足，暂无建议重置时间": "Not enough history to suggest a reset time yet",
+		"计算建议重置时间失败":      "Failed to compute the suggested reset time",
+		"生成重置效果报告失败":      "Failed to build the reset report",
+		"重置积分失败":          "Failed to reset credits",
+		"重置积分失败，请稍后重试":    "Failed to reset credits, please try again later",
+		"积分重置成功":          "Credits reset",
+		"更新重置标记失败":        "Failed to update the reset flag",
+
+		// 使用数据
+		"minutes取值范围为1-%d": "minutes must be between 1 and %d",
+		"获取使用趋势失败":         "Failed to load usage trend",
+		"缺少model参数":        "The model parameter is required",
+		"bucket取值范围为1m-1h": "bucket must be between 1m and 1h",
+		"gap取值范围为1-%d":     "gap must be between 1 and %d",
+		"获取使用会话失败":         "Failed to load usage sessions",
+		"hours取值范围为1-%d":   "hours must be between 1 and %d",
+		"获取错误率统计失败":        "Failed to load error-rate statistics",
+
+		// 积分历史
+		"days取值范围为1-%d":  "days must be between 1 and %d",
+		"导出积分统计失败":       "Failed to export credit statistics",
+		"weeks取值范围为1-%d": "weeks must be between 1 and %d",
+		"获取积分统计失败":       "Failed to get credit statistics",
+
+		// 运维管理
+		"获取数据库统计失败":   "Failed to load database statistics",
+		"数据库压缩失败":     "Database compaction failed",
+		"异步配置更新服务不可用": "Async config updater is unavailable",
+		"获取死信任务失败":    "Failed to load dead-letter jobs",
+		"死信任务不存在":     "Dead-letter job not found",
+		"任务重新入队失败":    "Failed to re-queue job",
+		"任务已重新入队":     "Job re-queued",
+		"丢弃任务失败":      "Failed to discard job",
+		"任务已丢弃":       "Job discarded",
+		"归档服务不可用":     "Archive service is unavailable",
+		"上游可用性探测未启用":  "Upstream availability probing is disabled",
+		"获取上游可用性失败":   "Failed to load upstream availability",
+		"获取归档文件失败":    "Failed to list archives",
+		"归档失败":        "Archiving failed",
+		"导出SQLite失败":  "SQLite export failed",
+
+		// 订阅源
+		"订阅令牌无效":               "Invalid feed token",
+		"获取每日统计失败":             "Failed to load daily statistics",
+		"生成订阅失败":               "Failed to generate feed",
+		"CCCMU 每日积分汇总":         "CCCMU daily credit summary",
+		"Claude Code 每日积分使用汇总": "Daily Claude Code credit usage summary",
+		"无":                    "none",
+		"总积分: %d":              "Total credits: %d",
+		"主要模型: %s":             "Top models: %s",
+		"重置次数: %d":             "Resets: %d",
+		"%s 使用 %d 积分":          "%s: %d credits used",
+		"CCCMU 自动化计划":          "CCCMU automation schedule",
+		"CCCMU 自动重置积分":         "CCCMU automatic credit reset",
+		"CCCMU 监控开启":           "CCCMU monitoring on",
+		"CCCMU 监控关闭":           "CCCMU monitoring off",
+
+		// 只读副本
+		"只读副本不支持此操作": "This operation is not available on a read-only replica",
+		"主实例不可用":     "Primary instance is unavailable",
+
+		// 图表标注
+		"获取标注失败": "Failed to load annotations",
+		"保存标注失败": "Failed to save annotation",
+		"删除标注失败": "Failed to delete annotation",
+		"标注不存在":  "Annotation not found",
+		"标注已删除":  "Annotation deleted",
+	},
+}
@@ -4,24 +4,33 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/spf13/pflag"
 
 	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/client"
 	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/grpcapi"
 	"github.com/leafney/cccmu/server/handlers"
 	"github.com/leafney/cccmu/server/middleware"
+	"github.com/leafney/cccmu/server/mock"
+	"github.com/leafney/cccmu/server/plugin"
+	"github.com/leafney/cccmu/server/secrets"
+	"github.com/leafney/cccmu/server/service"
 	"github.com/leafney/cccmu/server/services"
 	"github.com/leafney/cccmu/server/utils"
 	"github.com/leafney/cccmu/server/web"
@@ -55,6 +64,21 @@ func getBoolFromEnv(key string, defaultValue bool) bool {
 	}
 }
 
+// getIntFromEnv 从环境变量获取整数值
+func getIntFromEnv(key string, defaultValue int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("警告: 无效的整数环境变量 %s=%s，使用默认值 %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
 // getStringFromEnv 从环境变量获取字符串值
 func getStringFromEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -63,19 +87,120 @@ func getStringFromEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parseSessionExpire 解析Session过期时间：包含时间单位时直接解析，否则按小时处理
+func parseSessionExpire(value string) (time.Duration, error) {
+	if strings.Contains(value, "h") || strings.Contains(value, "m") || strings.Contains(value, "s") {
+		return time.ParseDuration(value)
+	}
+	return time.ParseDuration(value + "h")
+}
+
 func main() {
+	// 子命令：通过本地HTTP API与运行中的服务交互
+	if len(os.Args) > 1 && isCLICommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1], os.Args[2:]))
+	}
+
+	// 子命令：安装和控制系统后台服务
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		os.Exit(runServiceCommand(os.Args[2:]))
+	}
+
 	// 解析命令行参数
 	var port string
 	var enableLog bool
 	var showVersion bool
 	var sessionExpire string
+	var sessionShortExpire string
+	var masterKey string
+	var oldMasterKey string
+	var reencrypt bool
+	var migrateDryRun bool
+	var mockUpstream bool
+	var selfCheck bool
+	var disableUpdateCheck bool
+	var pidFile string
+	var grpcPort string
+	var csp string
+	var hstsMaxAge int
+	var slowUpstreamMs int
+	var upstreamProbeMinutes int
+	var jitterSeconds int
+	var upstreamRateLimit int
+	var cookieRequired string
+	var relayErrorRate int
+	var resetLockDir string
+	var replicaURL string
+	var replicaKey string
+	var syncFrom string
+	var syncKey string
+	var syncInterval int
+	var hooksDir string
+	var pluginDir string
+	var trustedProxies string
+	var basicAuth bool
+	var workDir string
+	var portAuto bool
+	var usageBufferSize int
+	var usageBufferMinutes int
 
 	pflag.StringVarP(&port, "port", "p", "", "服务器端口号（例如: 8080 或 :8080）")
 	pflag.BoolVarP(&enableLog, "log", "l", false, "启用详细日志输出")
 	pflag.BoolVarP(&showVersion, "version", "v", false, "显示版本信息")
-	pflag.StringVarP(&sessionExpire, "expire", "e", "", "Session过期时间（小时，如: 24, 168）")
+	pflag.StringVarP(&sessionExpire, "expire", "e", "", "Session过期时间（登录时勾选“记住我”，小时，如: 24, 168）")
+	pflag.StringVar(&sessionShortExpire, "short-expire", "", "未勾选“记住我”时的Session过期时间（小时，默认12）")
+	pflag.StringVar(&masterKey, "master-key", "", "敏感数据加密主密钥（Cookie等仅以密文存储）")
+	pflag.StringVar(&oldMasterKey, "old-master-key", "", "重新加密时使用的旧主密钥（旧数据为明文时留空）")
+	pflag.BoolVar(&reencrypt, "reencrypt", false, "使用新主密钥重新加密已存储的敏感数据后退出")
+	pflag.BoolVar(&migrateDryRun, "migrate-dry-run", false, "列出待执行的数据库结构迁移及将要修改的数据后退出（不修改数据）")
+	pflag.BoolVar(&mockUpstream, "mock-upstream", false, "启用内置模拟上游API（仅用于开发调试）")
+	pflag.BoolVar(&selfCheck, "check", false, "执行启动自检（数据库、Cookie、上游、时区、端口）后退出，未通过时返回非零退出码")
+	pflag.BoolVar(&disableUpdateCheck, "disable-update-check", false, "禁用每日新版本检查")
+	pflag.StringVar(&pidFile, "pid-file", "", "PID文件路径（可选）")
+	pflag.StringVar(&grpcPort, "grpc-port", "", "gRPC API端口号（例如: 9090，留空则不启用）")
+	pflag.StringVar(&csp, "csp", "", "自定义Content-Security-Policy响应头（默认使用内置策略，off 表示不设置）")
+	pflag.IntVar(&hstsMaxAge, "hsts-max-age", middleware.DefaultHSTSMaxAge, "HTTPS访问时的HSTS有效期（秒，0表示不设置）")
+	pflag.StringVar(&replicaURL, "replica", "", "只读副本模式：从主实例（例如: http://primary:8080）转发数据，不直接访问上游")
+	pflag.StringVar(&replicaKey, "replica-key", "", "只读副本访问主实例使用的访问密钥")
+	pflag.StringVar(&syncFrom, "sync-from", "", "从主实例（例如: http://primary:8080）同步调度、阈值等配置")
+	pflag.StringVar(&syncKey, "sync-key", "", "同步配置时访问主实例使用的访问密钥")
+	pflag.IntVar(&syncInterval, "sync-interval", int(services.DefaultConfigSyncInterval/time.Second), "配置同步拉取间隔（秒，最少10秒）")
+	pflag.StringVar(&resetLockDir, "reset-lock-dir", "", "多实例共享的重置锁目录（多个实例监控同一账户时，仅获得锁的实例执行自动重置）")
+	pflag.StringVar(&hooksDir, "hooks-dir", "", "事件Hook程序目录（仅允许执行该目录内的程序，留空则不执行Hook）")
+	pflag.StringVar(&pluginDir, "plugin-dir", "", "插件目录（加载其中的可执行文件作为通知渠道或上游站点插件）")
+	pflag.StringVar(&trustedProxies, "trusted-proxies", "", "反向代理认证模式：信任来自这些地址（逗号分隔的IP或CIDR）的Remote-User/X-Auth-Request-User请求头，无需访问密钥登录")
+	pflag.StringVar(&workDir, "workdir", "", "启动前切换到指定工作目录（数据目录 data 位于其下，作为系统服务运行时使用）")
+	pflag.BoolVar(&portAuto, "port-auto", false, "端口被占用时自动尝试后续端口，并将实际端口记录到 data/port")
+	pflag.BoolVar(&basicAuth, "basic-auth", false, "允许API使用HTTP Basic认证（任意用户名，密码为访问密钥），便于curl等脚本调用")
+	pflag.IntVar(&usageBufferSize, "usage-buffer-size", services.DefaultUsageBufferSize, "内存中保留的最近使用记录条数上限")
+	pflag.IntVar(&usageBufferMinutes, "usage-buffer-minutes", int(services.DefaultUsageBufferMaxAge/time.Minute), "内存中保留的最近使用记录时长上限（分钟）")
+	pflag.IntVar(&relayErrorRate, "relay-error-rate", services.DefaultRelayErrorRate, "中转站错误率告警阈值（最近1小时使用记录中429/5xx的百分比，0表示不告警）")
+	pflag.StringVar(&cookieRequired, "cookie-required", "", "上游登录所需的Cookie名称（逗号分隔，\"|\"分隔可选名称，\"-\"关闭校验），默认为上游站点的会话Cookie")
+	pflag.IntVar(&slowUpstreamMs, "slow-upstream-ms", int(client.DefaultSlowResponseThreshold/time.Millisecond), "上游响应慢告警阈值（毫秒，0表示不告警）")
+	pflag.IntVar(&upstreamRateLimit, "upstream-rate-limit", 0, "全局上游请求频率上限（每分钟请求数，0表示不限制）")
+	pflag.IntVar(&jitterSeconds, "jitter", 0, "上游请求任务的随机抖动（秒，每次执行间隔在设定间隔±该值内随机，0表示不抖动）")
+	pflag.IntVar(&upstreamProbeMinutes, "upstream-probe-minutes", int(services.DefaultUpstreamProbeInterval/time.Minute), "上游可用性探测间隔（分钟，0表示不探测）")
+	pflag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: %s [参数]\n       %s <子命令> [参数]\n\n参数:\n", os.Args[0], os.Args[0])
+		pflag.PrintDefaults()
+		printCLIUsage()
+	}
 	pflag.Parse()
 
+	// 切换工作目录（系统服务启动时的当前目录不是安装目录）
+	if workDir != "" {
+		if err := os.Chdir(workDir); err != nil {
+			log.Fatalf("切换工作目录失败: %v", err)
+		}
+	}
+
+	// 以Windows服务运行时接收服务管理器的停止请求，程序完成关闭后（最后执行的defer）通知服务管理器
+	serviceStop, err := service.Run()
+	if err != nil {
+		log.Fatalf("初始化系统服务失败: %v", err)
+	}
+	defer service.Done()
+
 	// 应用环境变量配置（优先级：命令行参数 > 环境变量 > 默认值）
 
 	// 如果命令行没有设置日志开关，则检查环境变量
@@ -88,6 +213,128 @@ func main() {
 		sessionExpire = getStringFromEnv("SESSION_EXPIRE", "168")
 	}
 
+	// 如果命令行没有设置短期Session过期时间，则检查环境变量
+	if !pflag.Lookup("short-expire").Changed {
+		sessionShortExpire = getStringFromEnv("SESSION_SHORT_EXPIRE", "12")
+	}
+
+	// 如果命令行没有设置主密钥，则检查环境变量
+	if !pflag.Lookup("master-key").Changed {
+		masterKey = getStringFromEnv("MASTER_KEY", "")
+	}
+	if !pflag.Lookup("old-master-key").Changed {
+		oldMasterKey = getStringFromEnv("OLD_MASTER_KEY", "")
+	}
+
+	// 如果命令行没有禁用版本检查，则检查环境变量
+	if !pflag.Lookup("disable-update-check").Changed {
+		disableUpdateCheck = getBoolFromEnv("DISABLE_UPDATE_CHECK", false)
+	}
+
+	// 如果命令行没有设置PID文件，则检查环境变量
+	if !pflag.Lookup("pid-file").Changed {
+		pidFile = getStringFromEnv("PID_FILE", "")
+	}
+
+	// 如果命令行没有设置gRPC端口，则检查环境变量
+	if !pflag.Lookup("grpc-port").Changed {
+		grpcPort = getStringFromEnv("GRPC_PORT", "")
+	}
+
+	// 如果命令行没有设置安全响应头，则检查环境变量
+	if !pflag.Lookup("csp").Changed {
+		csp = getStringFromEnv("CSP", "")
+	}
+	if !pflag.Lookup("hsts-max-age").Changed {
+		hstsMaxAge = getIntFromEnv("HSTS_MAX_AGE", middleware.DefaultHSTSMaxAge)
+	}
+
+	// 如果命令行没有设置可信反向代理，则检查环境变量
+	if !pflag.Lookup("trusted-proxies").Changed {
+		trustedProxies = getStringFromEnv("TRUSTED_PROXIES", "")
+	}
+
+	// 如果命令行没有设置Basic认证开关，则检查环境变量
+	if !pflag.Lookup("basic-auth").Changed {
+		basicAuth = getBoolFromEnv("BASIC_AUTH", false)
+	}
+
+	// 如果命令行没有设置端口自动选择，则检查环境变量
+	if !pflag.Lookup("port-auto").Changed {
+		portAuto = getBoolFromEnv("PORT_AUTO", false)
+	}
+
+	// 如果命令行没有设置使用记录缓冲上限，则检查环境变量
+	if !pflag.Lookup("usage-buffer-size").Changed {
+		usageBufferSize = getIntFromEnv("USAGE_BUFFER_SIZE", services.DefaultUsageBufferSize)
+	}
+	if !pflag.Lookup("usage-buffer-minutes").Changed {
+		usageBufferMinutes = getIntFromEnv("USAGE_BUFFER_MINUTES", int(services.DefaultUsageBufferMaxAge/time.Minute))
+	}
+
+	// 如果命令行没有设置中转站错误率告警阈值，则检查环境变量
+	if !pflag.Lookup("relay-error-rate").Changed {
+		relayErrorRate = getIntFromEnv("RELAY_ERROR_RATE", services.DefaultRelayErrorRate)
+	}
+
+	// 如果命令行没有设置上游慢响应阈值，则检查环境变量
+	if !pflag.Lookup("slow-upstream-ms").Changed {
+		slowUpstreamMs = getIntFromEnv("SLOW_UPSTREAM_MS", int(client.DefaultSlowResponseThreshold/time.Millisecond))
+	}
+	if !pflag.Lookup("upstream-rate-limit").Changed {
+		upstreamRateLimit = getIntFromEnv("UPSTREAM_RATE_LIMIT", 0)
+	}
+	if !pflag.Lookup("jitter").Changed {
+		jitterSeconds = getIntFromEnv("JITTER_SECONDS", 0)
+	}
+	if !pflag.Lookup("upstream-probe-minutes").Changed {
+		upstreamProbeMinutes = getIntFromEnv("UPSTREAM_PROBE_MINUTES", int(services.DefaultUpstreamProbeInterval/time.Minute))
+	}
+
+	// 如果命令行没有设置必需的Cookie名称，则检查环境变量
+	if !pflag.Lookup("cookie-required").Changed {
+		cookieRequired = getStringFromEnv("COOKIE_REQUIRED", "")
+	}
+
+	// 如果命令行没有设置重置锁目录，则检查环境变量
+	if !pflag.Lookup("reset-lock-dir").Changed {
+		resetLockDir = getStringFromEnv("RESET_LOCK_DIR", "")
+	}
+
+	// 如果命令行没有设置事件Hook目录，则检查环境变量
+	if !pflag.Lookup("hooks-dir").Changed {
+		hooksDir = getStringFromEnv("HOOKS_DIR", "")
+	}
+
+	// 如果命令行没有设置插件目录，则检查环境变量
+	if !pflag.Lookup("plugin-dir").Changed {
+		pluginDir = getStringFromEnv("PLUGIN_DIR", "")
+	}
+
+	// 如果命令行没有设置只读副本参数，则检查环境变量
+	if !pflag.Lookup("replica").Changed {
+		replicaURL = getStringFromEnv("REPLICA_URL", "")
+	}
+	if !pflag.Lookup("replica-key").Changed {
+		replicaKey = getStringFromEnv("REPLICA_KEY", "")
+	}
+
+	// 如果命令行没有设置配置同步参数，则检查环境变量
+	if !pflag.Lookup("sync-from").Changed {
+		syncFrom = getStringFromEnv("SYNC_FROM", "")
+	}
+	if !pflag.Lookup("sync-key").Changed {
+		syncKey = getStringFromEnv("SYNC_KEY", "")
+	}
+	if !pflag.Lookup("sync-interval").Changed {
+		syncInterval = getIntFromEnv("SYNC_INTERVAL", int(services.DefaultConfigSyncInterval/time.Second))
+	}
+
+	// 如果命令行没有设置模拟上游，则检查环境变量
+	if !pflag.Lookup("mock-upstream").Changed {
+		mockUpstream = getBoolFromEnv("MOCK_UPSTREAM", false)
+	}
+
 	// 如果请求版本信息，显示并退出
 	if showVersion {
 		fmt.Printf("Version:   %s\n", Version)
@@ -104,19 +351,40 @@ func main() {
 	handlers.SetVersionInfo(Version, GitCommit, BuildTime)
 
 	// 解析会话过期时间（默认以小时为单位）
-	var expireDuration time.Duration
-	var err error
+	expireDuration, err := parseSessionExpire(sessionExpire)
+	if err != nil {
+		log.Fatalf("解析Session过期时间失败: %v", err)
+	}
+	shortExpireDuration, err := parseSessionExpire(sessionShortExpire)
+	if err != nil {
+		log.Fatalf("解析短期Session过期时间失败: %v", err)
+	}
 
-	// 如果包含时间单位，直接解析；否则当作小时处理
-	if strings.Contains(sessionExpire, "h") || strings.Contains(sessionExpire, "m") || strings.Contains(sessionExpire, "s") {
-		expireDuration, err = time.ParseDuration(sessionExpire)
-	} else {
-		// 默认按小时处理
-		expireDuration, err = time.ParseDuration(sessionExpire + "h")
+	// 启动模拟上游服务，所有上游请求改为指向本地
+	if mockUpstream {
+		upstream := mock.NewUpstream()
+		if err := upstream.Start(); err != nil {
+			log.Fatalf("启动模拟上游服务失败: %v", err)
+		}
+		defer upstream.Stop()
+		client.SetBaseURL(upstream.URL())
+		fmt.Printf("🧪 模拟上游已启用: %s（任意非空Cookie均可通过验证，Cookie为 expired 时模拟失效）\n", upstream.URL())
 	}
 
-	if err != nil {
-		log.Fatalf("解析Session过期时间失败: %v", err)
+	// 执行启动自检并退出
+	if selfCheck {
+		if !runCheck("./data/.b", port, masterKey) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 写入PID文件
+	if pidFile != "" {
+		if err := utils.WritePIDFile(pidFile); err != nil {
+			log.Fatalf("写入PID文件失败: %v", err)
+		}
+		defer utils.RemovePIDFile(pidFile)
 	}
 
 	// 确保数据目录存在
@@ -124,23 +392,178 @@ func main() {
 		log.Fatalf("创建数据目录失败: %v", err)
 	}
 
+	// 由平滑重启启动时接管旧进程的监听套接字
+	inherited, sessionsPipe, err := inheritedListener()
+	if err != nil {
+		log.Fatalf("平滑重启失败: %v", err)
+	}
+
 	// 初始化认证管理器
 	authManager := auth.NewManager(expireDuration)
-	fmt.Printf("⏰ Session过期时间: %s\n", expireDuration)
+	authManager.SetShortExpireDuration(shortExpireDuration)
+	if trustedProxies != "" {
+		proxies, err := auth.ParseTrustedProxies(trustedProxies)
+		if err != nil {
+			log.Fatalf("解析可信反向代理失败: %v", err)
+		}
+		authManager.SetTrustedProxies(proxies)
+		fmt.Printf("🛡️  反向代理认证已启用，可信代理: %s\n", proxies)
+	}
+	if basicAuth {
+		authManager.SetBasicAuthEnabled(true)
+		fmt.Printf("🔓 API已允许HTTP Basic认证（密码为访问密钥）\n")
+	}
+	fmt.Printf("⏰ Session过期时间: %s（未勾选“记住我”时 %s）\n", expireDuration, shortExpireDuration)
+
+	// 旧进程处理完请求并释放数据库后才交出会话，读取完成时即可打开数据库
+	if sessionsPipe != nil {
+		count, err := authManager.ImportSessions(sessionsPipe)
+		sessionsPipe.Close()
+		if err != nil {
+			log.Printf("平滑重启: %v", err)
+		} else {
+			log.Printf("平滑重启: 已接管 %d 个会话", count)
+		}
+	}
 
 	// 初始化数据库
 	db, err := database.NewBadgerDB("./data/.b")
 	if err != nil {
 		log.Fatalf("初始化数据库失败: %v", err)
 	}
-	defer db.Close()
+	// 后台服务在函数返回时按阶段有序关闭，数据库最后关闭
+	var shutdown shutdownSequence
+	shutdown.add(shutdownDatabase, "数据库", db.Close)
+	defer shutdown.run()
+
+	// 初始化敏感数据加密
+	var secretBox *secrets.Box
+	if masterKey != "" {
+		secretBox, err = secrets.NewBox(masterKey)
+		if err != nil {
+			log.Fatalf("初始化主密钥失败: %v", err)
+		}
+		fmt.Println("🔒 敏感数据加密已启用")
+	}
+
+	// 重新加密敏感数据并退出
+	if reencrypt {
+		if err := runReencrypt(db, oldMasterKey, secretBox); err != nil {
+			fmt.Printf("❌ 重新加密失败: %v\n", err)
+			db.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	db.SetSecretBox(secretBox)
+
+	// 升级后首次启动时迁移数据库结构
+	if migrateDryRun {
+		if err := runMigrations(db, "./data", true); err != nil {
+			fmt.Printf("❌ 检查数据库迁移失败: %v\n", err)
+			db.Close()
+			os.Exit(1)
+		}
+		return
+	}
+	if err := runMigrations(db, "./data", false); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	// 提前检查配置可读，避免主密钥错误时静默回退为默认配置
+	localConfig, err := db.GetConfig()
+	if err != nil {
+		log.Fatalf("读取配置失败（如已启用加密，请检查主密钥）: %v", err)
+	}
+
+	// 只读副本模式：数据全部来自主实例，本地不保存Cookie，因此不会访问上游
+	var replicaProxy *handlers.ReplicaProxy
+	if replicaURL != "" {
+		if syncFrom != "" {
+			log.Fatalf("只读副本的配置由主实例决定，不能同时启用配置同步")
+		}
+		if localConfig.Cookie != "" {
+			log.Fatalf("只读副本模式下本地配置不能包含Cookie，请在单独的工作目录中运行副本")
+		}
+		replicaProxy, err = handlers.NewReplicaProxy(replicaURL, replicaKey)
+		if err != nil {
+			log.Fatalf("初始化只读副本失败: %v", err)
+		}
+		fmt.Printf("🪞 只读副本模式: 数据来自 %s\n", replicaProxy.PrimaryURL())
+	}
+
+	// 加载插件：上游站点插件替代内置上游API，通知渠道插件在调度服务创建后注册
+	var plugins []*plugin.Plugin
+	if pluginDir != "" {
+		plugins, err = plugin.LoadDir(pluginDir)
+		if err != nil {
+			log.Fatalf("加载插件失败: %v", err)
+		}
+		shutdown.add(shutdownJobs, "插件", func() error {
+			for _, p := range plugins {
+				p.Close()
+			}
+			return nil
+		})
+		for _, p := range plugins {
+			if p.Info().Kind != plugin.KindProvider {
+				continue
+			}
+			if client.CurrentProvider() != nil {
+				log.Fatalf("只能加载一个上游站点插件: %s 与 %s 冲突", client.CurrentProvider().Name(), p.Info().Name)
+			}
+			client.SetProvider(plugin.NewProvider(p))
+			fmt.Printf("🔌 上游站点插件: %s %s\n", p.Info().Name, p.Info().Version)
+		}
+	}
+
+	// 全局上游请求频率上限，所有功能的上游请求共享
+	client.SetOutboundRateLimit(upstreamRateLimit)
+
+	// 上游请求任务的随机抖动，须在创建任务之前设置
+	services.SetJobJitter(time.Duration(jitterSeconds) * time.Second)
 
 	// 初始化调度服务
 	scheduler, err := services.NewSchedulerService(db)
 	if err != nil {
 		log.Fatalf("初始化调度服务失败: %v", err)
 	}
-	defer scheduler.Shutdown()
+	for _, p := range plugins {
+		if p.Info().Kind == plugin.KindNotifier {
+			scheduler.AddNotificationSink(plugin.NewNotifier(p))
+			fmt.Printf("🔌 通知渠道插件: %s %s\n", p.Info().Name, p.Info().Version)
+		}
+	}
+
+	// 设置Cookie时校验上游登录所需的Cookie名称
+	client.SetRequiredCookies(strings.Split(cookieRequired, ","))
+
+	// 上游响应超过阈值时通过SSE推送告警
+	client.SetSlowResponseThreshold(time.Duration(slowUpstreamMs) * time.Millisecond)
+	client.SetSlowResponseHandler(scheduler.NotifyUpstreamSlow)
+	// 上游响应结构变化时通过SSE推送一次性通知
+	client.SetSchemaDriftHandler(scheduler.NotifySchemaDrift)
+	scheduler.SetRelayErrorRate(relayErrorRate)
+	scheduler.SetUsageBufferLimits(usageBufferSize, time.Duration(usageBufferMinutes)*time.Minute)
+	shutdown.add(shutdownJobs, "调度服务", func() error {
+		scheduler.Shutdown()
+		return nil
+	})
+	shutdown.add(shutdownListeners, "事件监听器", func() error {
+		scheduler.CloseListeners()
+		return nil
+	})
+
+	// 事件Hook仅执行指定目录内的程序
+	if hooksDir != "" {
+		hookRunner, err := services.NewHookRunner(hooksDir)
+		if err != nil {
+			log.Fatalf("初始化事件Hook失败: %v", err)
+		}
+		scheduler.SetHookRunner(hookRunner)
+		log.Printf("已启用事件Hook，目录: %s", hookRunner.Dir())
+	}
 
 	// 初始化自动重置服务
 	autoResetService := services.NewAutoResetService(db, scheduler)
@@ -148,29 +571,105 @@ func main() {
 		log.Fatalf("初始化自动重置服务失败")
 	}
 
+	// 多实例部署时通过共享目录协调自动重置
+	if resetLockDir != "" {
+		resetLock, err := services.NewFileResetLock(resetLockDir, "")
+		if err != nil {
+			log.Fatalf("初始化重置锁失败: %v", err)
+		}
+		autoResetService.SetResetLock(resetLock)
+		log.Printf("已启用多实例重置锁: %s (实例: %s)", resetLockDir, resetLock.Owner())
+	}
+
 	// 设置互相引用，用于任务协调
 	scheduler.SetAutoResetService(autoResetService)
-	defer func() {
-		if err := autoResetService.Stop(); err != nil {
-			log.Printf("停止自动重置服务失败: %v", err)
-		}
-	}()
+	shutdown.add(shutdownProducers, "自动重置服务", autoResetService.Stop)
 
 	// 启动自动重置服务
 	if err := autoResetService.Start(); err != nil {
 		log.Printf("启动自动重置服务失败: %v", err)
 	}
 
+	// 初始化健康监督服务
+	healthSupervisor, err := services.NewHealthSupervisor(db, scheduler)
+	if err != nil {
+		log.Fatalf("初始化健康监督服务失败: %v", err)
+	}
+	scheduler.SetHealthSupervisor(healthSupervisor)
+	if err := healthSupervisor.Start(); err != nil {
+		log.Printf("启动健康监督服务失败: %v", err)
+	}
+	shutdown.add(shutdownProducers, "健康监督服务", healthSupervisor.Stop)
+
+	// 初始化Cookie保活服务
+	keepAliveService, err := services.NewKeepAliveService(db, scheduler)
+	if err != nil {
+		log.Fatalf("初始化Cookie保活服务失败: %v", err)
+	}
+	if err := keepAliveService.Start(); err != nil {
+		log.Printf("启动Cookie保活服务失败: %v", err)
+	}
+	shutdown.add(shutdownProducers, "Cookie保活服务", keepAliveService.Stop)
+
+	// 初始化上游可用性探测服务（与监控开关无关，持续记录上游可用性；只读副本不访问上游）
+	var upstreamProbeService *services.UpstreamProbeService
+	if upstreamProbeMinutes > 0 && replicaProxy == nil {
+		upstreamProbeService, err = services.NewUpstreamProbeService(db, time.Duration(upstreamProbeMinutes)*time.Minute)
+		if err != nil {
+			log.Fatalf("初始化上游探测服务失败: %v", err)
+		}
+		if err := upstreamProbeService.Start(); err != nil {
+			log.Printf("启动上游探测服务失败: %v", err)
+		}
+		shutdown.add(shutdownProducers, "上游探测服务", upstreamProbeService.Stop)
+	}
+
+	// 初始化新版本检查服务
+	var updateChecker *services.UpdateCheckerService
+	if !disableUpdateCheck {
+		updateChecker, err = services.NewUpdateCheckerService(Version, db, scheduler)
+		if err != nil {
+			log.Fatalf("初始化版本检查服务失败: %v", err)
+		}
+		if err := updateChecker.Start(); err != nil {
+			log.Printf("启动版本检查服务失败: %v", err)
+		}
+		shutdown.add(shutdownProducers, "版本检查服务", updateChecker.Stop)
+	}
+
+	// 初始化数据清理服务
+	housekeepingService, err := services.NewHousekeepingService(db)
+	if err != nil {
+		log.Fatalf("初始化数据清理服务失败: %v", err)
+	}
+	usageArchiver := services.NewUsageArchiver(db, services.DefaultArchiveDir)
+	housekeepingService.SetArchiver(usageArchiver)
+	if err := housekeepingService.Start(); err != nil {
+		log.Printf("启动数据清理服务失败: %v", err)
+	}
+	shutdown.add(shutdownProducers, "数据清理服务", housekeepingService.Stop)
+
 	// 初始化异步配置更新服务
 	asyncConfigUpdater := services.NewAsyncConfigUpdater(scheduler, scheduler.GetAutoScheduler(), autoResetService, db)
+	asyncConfigUpdater.SetKeepAliveService(keepAliveService)
 	if err := asyncConfigUpdater.Start(); err != nil {
 		log.Fatalf("启动异步配置更新服务失败: %v", err)
 	}
-	defer func() {
-		if err := asyncConfigUpdater.Stop(); err != nil {
-			log.Printf("停止异步配置更新服务失败: %v", err)
+	shutdown.add(shutdownProducers, "异步配置更新服务", asyncConfigUpdater.Stop)
+
+	// 从主实例同步配置（可选）
+	var configSync *services.ConfigSyncService
+	if syncFrom != "" {
+		configSync, err = services.NewConfigSyncService(syncFrom, syncKey, time.Duration(syncInterval)*time.Second, db, scheduler, asyncConfigUpdater)
+		if err != nil {
+			log.Fatalf("初始化配置同步服务失败: %v", err)
 		}
-	}()
+		if err := configSync.Start(); err != nil {
+			log.Printf("启动配置同步服务失败: %v", err)
+		}
+		shutdown.add(shutdownProducers, "配置同步服务", configSync.Stop)
+		fmt.Printf("🔄 配置同步已启用: %s\n", syncFrom)
+	}
 
 	// 初始化Fiber应用
 	app := fiber.New(fiber.Config{
@@ -189,54 +688,85 @@ func main() {
 
 	// 中间件
 	app.Use(logger.New())
+
+	// 响应压缩（gzip/brotli，按Accept-Encoding协商）；SSE数据流需逐条推送，不参与压缩
+	app.Use(compress.New(compress.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/usage/stream")
+		},
+	}))
+
+	// 安全响应头
+	securityConfig := middleware.DefaultSecurityHeadersConfig()
+	switch {
+	case strings.EqualFold(csp, "off"):
+		securityConfig.ContentSecurityPolicy = ""
+	case csp != "":
+		securityConfig.ContentSecurityPolicy = csp
+	}
+	securityConfig.HSTSMaxAge = hstsMaxAge
+	app.Use(middleware.SecurityHeadersMiddleware(securityConfig))
+
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, " + middleware.IdempotencyKeyHeader,
 	}))
 
+	// 提示信息语言协商（Accept-Language）
+	app.Use(middleware.LanguageMiddleware())
+
+	// 记录最近的API请求，供运维接口查看
+	requestLog := middleware.NewRequestLog(middleware.DefaultRequestLogSize)
+	app.Use(middleware.RequestLogMiddleware(requestLog))
+
 	// 初始化处理器
 	configHandler := handlers.NewConfigHandler(db, scheduler, autoResetService, asyncConfigUpdater)
+	configHandler.SetKeepAliveService(keepAliveService)
+	configHandler.SetUpdateChecker(updateChecker)
+	configHandler.SetConfigSync(configSync)
 	controlHandler := handlers.NewControlHandler(scheduler, db)
 	sseHandler := handlers.NewSSEHandler(db, scheduler, authManager)
 	authHandler := handlers.NewAuthHandler(authManager, scheduler, db)
+	// 接口认证失败记入登录审计（同一IP在每个统计窗口内只记录首次失败）
+	authManager.SetAuthFailureHandler(scheduler.RecordAuthFailure)
 	dailyUsageHandler := handlers.NewDailyUsageHandler(scheduler, authManager)
-
-	// API路由
-	api := app.Group("/api")
-
-	// 认证相关API（不需要认证）
-	authGroup := api.Group("/auth")
-	{
-		authGroup.Post("/login", authHandler.Login)
-		authGroup.Get("/logout", authHandler.Logout)
-		authGroup.Get("/status", authHandler.Status)
+	adminHandler := handlers.NewAdminHandler(db, scheduler)
+	adminHandler.SetAsyncConfigUpdater(asyncConfigUpdater)
+	adminHandler.SetRequestLog(requestLog)
+	adminHandler.SetUsageArchiver(usageArchiver)
+	adminHandler.SetUpstreamProbeService(upstreamProbeService)
+	healthHandler := handlers.NewHealthHandler(db, scheduler, autoResetService)
+	feedHandler := handlers.NewFeedHandler(scheduler, authManager)
+	annotationHandler := handlers.NewAnnotationHandler(db, scheduler)
+	metricsHandler := handlers.NewMetricsHandler()
+	metricsHandler.SetUpstreamProbeService(upstreamProbeService)
+
+	// 变更类接口限流器（HTTP接口与gRPC共享）
+	mutationLimiter := middleware.NewRateLimiter(mutationRateBurst, mutationRateInterval)
+
+	routeHandlers := &apiHandlers{
+		config:     configHandler,
+		control:    controlHandler,
+		sse:        sseHandler,
+		auth:       authHandler,
+		dailyUsage: dailyUsageHandler,
+		admin:      adminHandler,
+		feed:       feedHandler,
+		annotation: annotationHandler,
+
+		mutationLimit:     middleware.RateLimitMiddleware(mutationLimiter),
+		configIdempotency: middleware.IdempotencyMiddleware(configIdempotencyLifetime),
+		conditionalGet:    middleware.ConditionalGetMiddleware(),
 	}
 
-	// 需要认证的API路由
-	api.Use(middleware.AuthMiddleware(authManager))
-	{
-		// 配置相关
-		api.Get("/config", configHandler.GetConfig)
-		api.Put("/config", configHandler.UpdateConfig)
-		api.Delete("/config/cookie", configHandler.ClearCookie)
-
-		// 控制相关
-		api.Post("/control/start", controlHandler.StartTask)
-		api.Post("/control/stop", controlHandler.StopTask)
-		api.Get("/control/status", controlHandler.GetTaskStatus)
-		api.Post("/refresh", controlHandler.RefreshAll)
-
-		// 积分余额相关
-		api.Get("/balance", controlHandler.GetCreditBalance)
-		api.Post("/balance/reset", controlHandler.ResetCredits)
-
-		// 数据相关
-		api.Get("/usage/stream", sseHandler.StreamUsageData)
-		api.Get("/usage/data", sseHandler.GetUsageData)
-
-		// 积分历史统计
-		api.Get("/history", dailyUsageHandler.GetWeeklyUsage)
+	// API路由（v1须先于旧版路径注册，避免被旧版前缀的中间件拦截）
+	if replicaProxy != nil {
+		registerReplicaRoutes(app.Group(apiV1Prefix), authManager, routeHandlers, replicaProxy)
+		registerReplicaRoutes(app.Group(apiLegacyPrefix, middleware.LegacyAPIMiddleware(apiLegacyPrefix, apiV1Prefix)), authManager, routeHandlers, replicaProxy)
+	} else {
+		registerAPIRoutes(app.Group(apiV1Prefix), authManager, routeHandlers)
+		registerAPIRoutes(app.Group(apiLegacyPrefix, middleware.LegacyAPIMiddleware(apiLegacyPrefix, apiV1Prefix)), authManager, routeHandlers)
 	}
 
 	// 健康检查接口
@@ -249,6 +779,21 @@ func main() {
 		})
 	})
 
+	// 存活与就绪检查接口（供容器编排使用）
+	app.Get("/healthz", healthHandler.Liveness)
+	app.Get("/readyz", healthHandler.Readiness)
+
+	// 订阅源（通过URL中的订阅令牌认证，便于RSS阅读器直接订阅；只读副本不提供）
+	if replicaProxy == nil {
+		app.Get(handlers.DailyFeedPath, feedHandler.DailyFeed)
+		app.Get(handlers.ScheduleFeedPath, feedHandler.ScheduleFeed)
+	}
+
+	// Prometheus指标（需认证，抓取时使用 Authorization: Bearer <访问密钥>；只读副本不访问上游，不提供）
+	if replicaProxy == nil {
+		app.Get(handlers.MetricsPath, middleware.AuthMiddleware(authManager), metricsHandler.Metrics)
+	}
+
 	// 静态文件服务 - 使用embed嵌入的静态文件
 	log.Println("使用embed嵌入的静态文件")
 
@@ -258,6 +803,9 @@ func main() {
 		log.Fatalf("获取embed静态文件系统失败: %v", err)
 	}
 
+	// 静态资源缓存头：带哈希的资源长期缓存，index.html每次重新验证
+	app.Use(middleware.StaticCacheMiddleware(staticFS, apiLegacyPrefix))
+
 	// 使用filesystem中间件服务静态文件
 	app.Use("/", filesystem.New(filesystem.Config{
 		Root:   http.FS(staticFS),
@@ -290,28 +838,155 @@ func main() {
 		return c.SendStream(indexFile)
 	})
 
+	// systemd就绪通知：端口监听成功后发送READY
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		if ok, err := utils.SdNotify(utils.SdNotifyReady); err != nil {
+			log.Printf("发送systemd就绪通知失败: %v", err)
+		} else if ok {
+			log.Println("已通知systemd服务就绪")
+		}
+		return nil
+	})
+
+	// systemd看门狗：按超时时间的一半从调度器中发送心跳
+	if watchdogInterval := utils.SdWatchdogInterval(); watchdogInterval > 0 {
+		err := scheduler.StartWatchdog(watchdogInterval/2, func() {
+			if _, err := utils.SdNotify(utils.SdNotifyWatchdog); err != nil {
+				log.Printf("发送systemd看门狗心跳失败: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("启动systemd看门狗失败: %v", err)
+		}
+	}
+
+	// 启动gRPC API服务（可选）
+	var grpcServer *grpcapi.Server
+	if grpcPort != "" && replicaProxy != nil {
+		log.Printf("只读副本模式不支持gRPC API，已忽略 --grpc-port")
+	} else if grpcPort != "" {
+		grpcAddr := grpcPort
+		if !strings.Contains(grpcAddr, ":") {
+			grpcAddr = ":" + grpcAddr
+		}
+		grpcServer = grpcapi.NewServer(db, scheduler, authManager, mutationLimiter)
+		if err := grpcServer.Start(grpcAddr); err != nil {
+			log.Fatalf("启动gRPC服务失败: %v", err)
+		}
+		fmt.Printf("🔌 gRPC服务已启动: %s\n", grpcAddr)
+	}
+
 	// 启动服务器
-	serverPort := getPort(port)
+	listener := inherited
+	if listener == nil {
+		listener, err = listenPort(getPort(port), portAuto)
+		if err != nil {
+			log.Fatalf("服务器启动失败: %v", err)
+		}
+	}
+	if portAuto {
+		savePortFile(listener)
+	} else {
+		os.Remove(portFile)
+	}
+	serverPort := fmt.Sprintf(":%d", listener.Addr().(*net.TCPAddr).Port)
 	log.Printf("服务器启动在端口 %s", serverPort)
 	fmt.Printf("🌐 服务已启动: http://localhost%s\n", serverPort)
 
 	// 优雅关闭
 	go func() {
-		if err := app.Listen(serverPort); err != nil {
+		if err := app.Listener(listener); err != nil {
 			log.Fatalf("服务器启动失败: %v", err)
 		}
 	}()
 
-	// 等待中断信号
+	// 等待中断信号、服务管理器的停止请求或平滑重启信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	restart := notifyRestart()
+	var sessionsOut *os.File
+	for waiting := true; waiting; {
+		select {
+		case <-quit:
+			waiting = false
+		case <-serviceStop:
+			waiting = false
+		case <-restart:
+			successor, pipe, err := startSuccessor(listener)
+			if err != nil {
+				log.Printf("平滑重启失败，继续运行: %v", err)
+				continue
+			}
+			log.Printf("平滑重启: 新进程已启动 (PID: %d)，当前进程开始退出", successor.Pid)
+			utils.SdNotify(fmt.Sprintf("MAINPID=%d", successor.Pid))
+			sessionsOut = pipe
+			waiting = false
+		}
+	}
 
 	log.Println("正在关闭服务器...")
-	if err := app.Shutdown(); err != nil {
-		log.Printf("服务器关闭失败: %v", err)
+	if sessionsOut == nil {
+		utils.SdNotify(utils.SdNotifyStopping)
+	}
+
+	if sessionsOut != nil {
+		// 平滑重启：先停止接受新连接（新进程继承的套接字仍在监听），再让SSE客户端立即重连，由新进程接受
+		shutdownErr := make(chan error, 1)
+		go func() { shutdownErr <- app.Shutdown() }()
+		sseHandler.Handoff(3 * time.Second)
+		if grpcServer != nil {
+			grpcServer.Stop(3 * time.Second)
+		}
+		if err := <-shutdownErr; err != nil {
+			log.Printf("服务器关闭失败: %v", err)
+		}
+	} else {
+		// 先通知并关闭SSE连接和gRPC流，避免客户端在部署期间频繁重连
+		sseHandler.Drain(3 * time.Second)
+		if grpcServer != nil {
+			grpcServer.Stop(3 * time.Second)
+		}
+		if err := app.Shutdown(); err != nil {
+			log.Printf("服务器关闭失败: %v", err)
+		}
 	}
 	log.Println("服务器已关闭")
+
+	// 后台服务按阶段停止：配置同步、自动重置等后台服务 → 调度任务 → 事件监听器 → 数据库
+	log.Println("正在停止后台服务...")
+	shutdown.run()
+
+	// 数据库已释放、请求处理完毕后会话不再变化，交给新进程；新进程读取完会话即可直接打开数据库
+	if sessionsOut != nil {
+		if err := authManager.ExportSessions(sessionsOut); err != nil {
+			log.Printf("平滑重启: %v", err)
+		}
+		sessionsOut.Close()
+	}
+}
+
+// runReencrypt 使用新主密钥重新加密已存储的敏感数据
+func runReencrypt(db *database.BadgerDB, oldMasterKey string, newBox *secrets.Box) error {
+	var oldBox *secrets.Box
+	if oldMasterKey != "" {
+		box, err := secrets.NewBox(oldMasterKey)
+		if err != nil {
+			return fmt.Errorf("初始化旧主密钥失败: %w", err)
+		}
+		oldBox = box
+	}
+
+	count, err := db.ReencryptSecrets(oldBox, newBox)
+	if err != nil {
+		return err
+	}
+
+	if newBox == nil {
+		fmt.Printf("✅ 已将 %d 项敏感数据解密为明文存储\n", count)
+	} else {
+		fmt.Printf("✅ 已重新加密 %d 项敏感数据\n", count)
+	}
+	return nil
 }
 
 // getPort 获取端口，优先级：命令行参数 > 环境变量 > 默认端口
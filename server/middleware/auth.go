@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/i18n"
 	"github.com/leafney/cccmu/server/models"
 )
 
@@ -14,7 +18,7 @@ func AuthMiddleware(authManager *auth.Manager) fiber.Handler {
 		path := c.Path()
 
 		// 跳过认证API路径
-		if strings.HasPrefix(path, "/api/auth/") {
+		if strings.HasPrefix(path, "/api/auth/") || strings.HasPrefix(path, "/api/v1/auth/") {
 			return c.Next()
 		}
 
@@ -26,31 +30,95 @@ func AuthMiddleware(authManager *auth.Manager) fiber.Handler {
 			return c.Next()
 		}
 
+		// 可信反向代理已完成认证（SSO代理模式），无需登录
+		if user := ProxyUser(authManager, c); user != "" {
+			c.Locals("proxyUser", user)
+			return c.Next()
+		}
+
 		// 获取session cookie
 		sessionID := c.Cookies("cccmu_session")
 		if sessionID == "" {
-			return c.Status(401).JSON(models.Error(401, "未授权访问", nil))
+			// 命令行等程序化调用可直接携带访问密钥（Authorization: Bearer <key>），
+			// 启用Basic认证时也可使用 Authorization: Basic <任意用户名:访问密钥>；
+			// 同一IP认证失败次数过多时暂时拒绝，避免借接口认证暴力猜测访问密钥
+			header := c.Get("Authorization")
+			basic := strings.HasPrefix(header, "Basic ")
+			if header == "" || (basic && !authManager.BasicAuthEnabled()) {
+				return c.Status(401).JSON(models.Error(401, i18n.T(c, "未授权访问"), nil))
+			}
+
+			ip := strings.Clone(c.IP())
+			if blocked, wait := authManager.AuthBlocked(ip); blocked {
+				return AuthRateLimited(c, wait)
+			}
+			reason := models.LoginFailureAPIKey
+			if basic {
+				reason = models.LoginFailureBasicAuth
+				if authManager.ValidateBasicAuth(header) {
+					authManager.ClearAuthFailures(ip)
+					c.Locals("keyAuth", true)
+					c.Locals("basicAuth", true)
+					return c.Next()
+				}
+			} else if key := strings.TrimPrefix(header, "Bearer "); key != "" && authManager.ValidateKey(key) {
+				authManager.ClearAuthFailures(ip)
+				c.Locals("keyAuth", true)
+				return c.Next()
+			}
+			authManager.AuthFailed(ip, strings.Clone(c.Get("User-Agent")), reason)
+			return c.Status(401).JSON(models.Error(401, i18n.T(c, "未授权访问"), nil))
 		}
 
 		// 验证session
 		session, valid := authManager.ValidateSession(sessionID)
 		if !valid {
-			return c.Status(401).JSON(models.Error(401, "会话无效或已过期", nil))
+			return c.Status(401).JSON(models.Error(401, i18n.T(c, "会话无效或已过期"), nil))
 		}
 
-		// 将session信息存储到context中
+		// 记录会话的客户端信息，将session信息存储到context中
+		authManager.TouchSession(sessionID, strings.Clone(c.IP()), strings.Clone(c.Get("User-Agent")))
 		c.Locals("session", session)
 
 		return c.Next()
 	}
 }
 
+// AuthRateLimited 同一IP认证失败次数过多时的响应：429并通过Retry-After给出剩余等待秒数
+func AuthRateLimited(c *fiber.Ctx, wait time.Duration) error {
+	c.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.Status(429).JSON(models.Error(429, i18n.T(c, "认证失败次数过多，请稍后再试"), nil).WithCode(models.ErrRateLimited))
+}
+
+// AdminOnlyMiddleware 仅允许登录会话和Bearer访问密钥调用，用于备份、导出全部数据等高敏感接口
+// 反向代理认证的用户（身份由代理决定，可能是任意SSO用户）和HTTP Basic认证的调用被拒绝，须放在AuthMiddleware之后
+func AdminOnlyMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("session").(*auth.Session); ok {
+			return c.Next()
+		}
+		keyAuth, _ := c.Locals("keyAuth").(bool)
+		basicAuth, _ := c.Locals("basicAuth").(bool)
+		if keyAuth && !basicAuth {
+			return c.Next()
+		}
+		return c.Status(403).JSON(models.Error(403, i18n.T(c, "此操作仅限登录会话或访问密钥调用"), nil))
+	}
+}
+
 // OptionalAuthMiddleware 可选认证中间件（用于首页等）
 func OptionalAuthMiddleware(authManager *auth.Manager) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if user := ProxyUser(authManager, c); user != "" {
+			c.Locals("proxyUser", user)
+			c.Locals("authenticated", true)
+			return c.Next()
+		}
+
 		sessionID := c.Cookies("cccmu_session")
 		if sessionID != "" {
 			if session, valid := authManager.ValidateSession(sessionID); valid {
+				authManager.TouchSession(sessionID, strings.Clone(c.IP()), strings.Clone(c.Get("User-Agent")))
 				c.Locals("session", session)
 				c.Locals("authenticated", true)
 			}
@@ -58,3 +126,11 @@ func OptionalAuthMiddleware(authManager *auth.Manager) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// ProxyUser 获取可信反向代理传递的已认证用户名（按TCP对端地址判断是否为可信代理），非代理认证请求返回空字符串
+func ProxyUser(authManager *auth.Manager, c *fiber.Ctx) string {
+	user := authManager.ProxyUser(c.Context().RemoteIP(), func(name string) string {
+		return c.Get(name)
+	})
+	return strings.Clone(user)
+}
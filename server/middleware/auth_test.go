@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestAdminOnlyMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		locals map[string]interface{}
+		want   int
+	}{
+		{"登录会话", map[string]interface{}{"session": &auth.Session{ID: "s"}}, 200},
+		{"Bearer访问密钥", map[string]interface{}{"keyAuth": true}, 200},
+		{"Basic认证", map[string]interface{}{"keyAuth": true, "basicAuth": true}, 403},
+		{"反向代理认证", map[string]interface{}{"proxyUser": "alice"}, 403},
+		{"未认证", nil, 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/backup", func(c *fiber.Ctx) error {
+				for key, value := range tt.locals {
+					c.Locals(key, value)
+				}
+				return c.Next()
+			}, AdminOnlyMiddleware(), func(c *fiber.Ctx) error {
+				return c.SendString("ok")
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/backup", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Fatalf("状态码 = %d，期望 %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRecordsBearerFailures(t *testing.T) {
+	// 认证管理器从工作目录下的 data/auth 读取访问密钥
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data", "auth"), []byte("secret-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	manager := auth.NewManager(time.Hour)
+	var mu sync.Mutex
+	var reasons []string
+	manager.SetAuthFailureHandler(func(ip, userAgent, reason string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reasons = append(reasons, reason)
+	})
+
+	app := fiber.New()
+	app.Use(AuthMiddleware(manager))
+	app.Get("/api/v1/data", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	request := func(key string) int {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/v1/data", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	for i := 0; i < auth.MaxAuthFailures; i++ {
+		if status := request("wrong"); status != 401 {
+			t.Fatalf("第%d次错误密钥应返回401，实际 %d", i+1, status)
+		}
+	}
+	if status := request("secret-key"); status != 429 {
+		t.Fatalf("失败次数达到上限后即使密钥正确也应返回429，实际 %d", status)
+	}
+
+	time.Sleep(50 * time.Millisecond) // 回调异步执行
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reasons) != 1 || reasons[0] != models.LoginFailureAPIKey {
+		t.Fatalf("同一IP在窗口内只应记录一次审计，实际 %v", reasons)
+	}
+}
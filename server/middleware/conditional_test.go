@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestConditionalGetMiddleware(t *testing.T) {
+	body := "v1"
+	app := fiber.New()
+	app.Use(ConditionalGetMiddleware())
+	app.Get("/usage", func(c *fiber.Ctx) error {
+		return c.SendString(body)
+	})
+	app.Get("/empty", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	get := func(path string, headers ...string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	first := get("/usage")
+	etag := first.Header.Get(fiber.HeaderETag)
+	lastModified := first.Header.Get(fiber.HeaderLastModified)
+	if first.StatusCode != 200 || etag == "" || lastModified == "" {
+		t.Fatalf("首次请求应返回200并带ETag和Last-Modified，实际: %d %q %q", first.StatusCode, etag, lastModified)
+	}
+
+	if resp := get("/usage", fiber.HeaderIfNoneMatch, etag); resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("ETag匹配时应返回304，实际: %d", resp.StatusCode)
+	}
+	if resp := get("/usage", fiber.HeaderIfNoneMatch, `W/"other"`, fiber.HeaderIfModifiedSince, lastModified); resp.StatusCode != 200 {
+		t.Errorf("ETag不匹配时应忽略If-Modified-Since返回200，实际: %d", resp.StatusCode)
+	}
+	if resp := get("/usage", fiber.HeaderIfModifiedSince, lastModified); resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("内容未变化且不早于Last-Modified时应返回304，实际: %d", resp.StatusCode)
+	}
+
+	body = "v2"
+	changed := get("/usage", fiber.HeaderIfNoneMatch, etag)
+	if changed.StatusCode != 200 || changed.Header.Get(fiber.HeaderETag) == etag {
+		t.Errorf("内容变化后应返回200和新的ETag，实际: %d %q", changed.StatusCode, changed.Header.Get(fiber.HeaderETag))
+	}
+	earlier := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	if resp := get("/usage", fiber.HeaderIfModifiedSince, earlier); resp.StatusCode != 200 {
+		t.Errorf("If-Modified-Since早于内容变化时间时应返回200，实际: %d", resp.StatusCode)
+	}
+
+	if resp := get("/empty"); resp.Header.Get(fiber.HeaderETag) != "" {
+		t.Errorf("非200响应不应设置ETag")
+	}
+}
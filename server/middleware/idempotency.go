@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/idempotency"
+)
+
+// IdempotencyKeyHeader 幂等键请求头
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength 幂等键最大长度
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware 幂等键中间件
+// 携带相同Idempotency-Key的重复请求（如网络不稳定时客户端重试）直接返回首次请求的结果，不再重复执行；
+// 未携带该请求头时正常处理。首次请求处理期间到达的重复请求会等待其完成
+func IdempotencyMiddleware(lifetime time.Duration) fiber.Handler {
+	handler := idempotency.New(idempotency.Config{
+		Lifetime:  lifetime,
+		KeyHeader: IdempotencyKeyHeader,
+		KeyHeaderValidate: func(key string) error {
+			if len(key) > maxIdempotencyKeyLength {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s长度不能超过%d", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			}
+			return nil
+		},
+		KeepResponseHeaders: []string{fiber.HeaderContentType},
+	})
+
+	return func(c *fiber.Ctx) error {
+		err := handler(c)
+		if idempotency.IsFromCache(c) {
+			log.Printf("[幂等] 重复请求 %s %s，返回首次请求的结果", c.Method(), c.Path())
+		}
+		return err
+	}
+}
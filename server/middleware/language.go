@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/leafney/cccmu/server/i18n"
+)
+
+// LanguageMiddleware 响应语言协商中间件
+// 按Accept-Language选择提示信息的语言（目前支持中文和英文，默认中文），并通过Content-Language告知客户端
+func LanguageMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		lang := i18n.Negotiate(c)
+		i18n.SetLang(c, lang)
+		c.Set(fiber.HeaderContentLanguage, lang)
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Next()
+	}
+}
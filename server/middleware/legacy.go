@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LegacyAPIMiddleware 旧版API路径中间件，通过Deprecation/Link响应头提示迁移到新版路径
+func LegacyAPIMiddleware(legacyPrefix, successorPrefix string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		if strings.HasPrefix(path, successorPrefix) {
+			return c.Next()
+		}
+
+		c.Set("Deprecation", "true")
+		c.Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successorPrefix, strings.TrimPrefix(path, legacyPrefix)))
+		return c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// rateLimitIdleTTL 令牌桶闲置多久后被清理
+const rateLimitIdleTTL = 10 * time.Minute
+
+// RateLimitKeyAuth 访问密钥认证调用方的限流键（HTTP接口与gRPC共用）
+const RateLimitKeyAuth = "key"
+
+// tokenBucket 单个调用方的令牌桶
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter 令牌桶限流器，按会话、访问密钥或IP分别计数
+type RateLimiter struct {
+	capacity   float64                 // 桶容量（允许的突发请求数）
+	refillRate float64                 // 每秒补充的令牌数
+	buckets    map[string]*tokenBucket // 各调用方的令牌桶
+	lastSweep  time.Time               // 上次清理闲置令牌桶的时间
+	mu         sync.Mutex
+}
+
+// NewRateLimiter 创建限流器：最多突发burst次请求，之后每隔refillInterval恢复一次
+func NewRateLimiter(burst int, refillInterval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		capacity:   float64(burst),
+		refillRate: 1 / refillInterval.Seconds(),
+		buckets:    make(map[string]*tokenBucket),
+		lastSweep:  time.Now(),
+	}
+}
+
+// Allow 尝试消耗一个令牌，失败时返回需要等待的时间
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.capacity, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	// 按经过的时间补充令牌
+	bucket.tokens = math.Min(l.capacity, bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*l.refillRate)
+	bucket.lastSeen = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - bucket.tokens) / l.refillRate * float64(time.Second))
+	return false, wait
+}
+
+// sweep 定期清理闲置的令牌桶（内部方法，调用方持有锁）
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitIdleTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) > rateLimitIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimitMiddleware 限流中间件，用于会触发上游请求或修改状态的接口
+// 调用方优先按会话区分，其次为访问密钥认证和反向代理认证的用户，最后按客户端IP
+func RateLimitMiddleware(limiter *RateLimiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := "ip:" + c.IP()
+		if sessionID := c.Cookies("cccmu_session"); sessionID != "" {
+			key = "session:" + sessionID
+		} else if keyAuth, _ := c.Locals("keyAuth").(bool); keyAuth {
+			key = RateLimitKeyAuth
+		} else if user, _ := c.Locals("proxyUser").(string); user != "" {
+			key = "proxy:" + user
+		}
+
+		allowed, wait := limiter.Allow(key)
+		if !allowed {
+			c.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return c.Status(429).JSON(models.Error(429, i18n.T(c, "请求过于频繁，请稍后再试"), nil).WithCode(models.ErrRateLimited))
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// DefaultRequestLogSize 默认保留的最近请求数
+const DefaultRequestLogSize = 200
+
+// RequestLog 最近API请求的环形缓冲区
+type RequestLog struct {
+	entries []models.RequestLogEntry
+	next    int  // 下一条写入位置
+	full    bool // 缓冲区是否已写满一轮
+	mu      sync.Mutex
+}
+
+// NewRequestLog 创建请求日志，最多保留size条
+func NewRequestLog(size int) *RequestLog {
+	if size <= 0 {
+		size = DefaultRequestLogSize
+	}
+	return &RequestLog{entries: make([]models.RequestLogEntry, size)}
+}
+
+// add 写入一条记录，缓冲区满时覆盖最旧的记录
+func (l *RequestLog) add(entry models.RequestLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Entries 获取最近的请求记录（按时间倒序），limit<=0时返回全部
+func (l *RequestLog) Entries(limit int) []models.RequestLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	result := make([]models.RequestLogEntry, 0, limit)
+	for i := 1; i <= limit; i++ {
+		result = append(result, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return result
+}
+
+// RequestLogMiddleware 记录API请求的中间件（静态资源请求不记录）
+func RequestLogMiddleware(requestLog *RequestLog) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !strings.HasPrefix(c.Path(), "/api") {
+			return c.Next()
+		}
+
+		start := time.Now()
+		// 路径和方法在处理过程中可能被改写（如旧版路径转发），提前复制
+		method := c.Method()
+		path := strings.Clone(c.Path())
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		entry := models.RequestLogEntry{
+			Time:       start,
+			Method:     method,
+			Path:       path,
+			Status:     status,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Auth:       "none",
+			IP:         strings.Clone(c.IP()),
+		}
+		if sessionID := c.Cookies("cccmu_session"); sessionID != "" {
+			entry.Auth = "session"
+			if len(sessionID) > 8 {
+				sessionID = sessionID[:8]
+			}
+			entry.Session = sessionID + "..."
+		} else if keyAuth, _ := c.Locals("keyAuth").(bool); keyAuth {
+			entry.Auth = "key"
+		} else if user, _ := c.Locals("proxyUser").(string); user != "" {
+			entry.Auth = "proxy"
+		}
+		requestLog.add(entry)
+
+		return err
+	}
+}
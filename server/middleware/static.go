@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// 静态资源缓存策略
+const (
+	// StaticImmutableCacheControl 带内容哈希的构建产物（/assets/）长期缓存，文件名变化即视为新资源
+	StaticImmutableCacheControl = "public, max-age=31536000, immutable"
+	// StaticRevalidateCacheControl index.html等无哈希文件每次使用前都需通过ETag重新验证
+	StaticRevalidateCacheControl = "no-cache"
+)
+
+// staticHashedPrefix Vite输出的带哈希文件目录
+const staticHashedPrefix = "assets/"
+
+// StaticCacheMiddleware 内嵌静态资源缓存中间件
+// 启动时为每个文件计算内容哈希作为ETag，命中If-None-Match时直接返回304；
+// 未匹配到文件的前端路由按index.html处理（与SPA回退保持一致）
+func StaticCacheMiddleware(staticFS fs.FS, apiPrefix string) fiber.Handler {
+	etags := make(map[string]string)
+	err := fs.WalkDir(staticFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(staticFS, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		// 响应可能被压缩，使用弱ETag
+		etags[path] = `W/"` + hex.EncodeToString(sum[:8]) + `"`
+		return nil
+	})
+	if err != nil {
+		log.Printf("[静态资源] 计算ETag失败: %v", err)
+	}
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		if strings.HasPrefix(c.Path(), apiPrefix) {
+			return c.Next()
+		}
+
+		path := strings.TrimPrefix(c.Path(), "/")
+		if path == "" || strings.HasSuffix(path, "/") {
+			path += "index.html"
+		}
+		etag, ok := etags[path]
+		if !ok {
+			path = "index.html"
+			etag, ok = etags[path]
+		}
+		if !ok {
+			return c.Next()
+		}
+
+		if strings.HasPrefix(path, staticHashedPrefix) {
+			c.Set(fiber.HeaderCacheControl, StaticImmutableCacheControl)
+		} else {
+			c.Set(fiber.HeaderCacheControl, StaticRevalidateCacheControl)
+		}
+		c.Set(fiber.HeaderETag, etag)
+
+		if match := c.Get(fiber.HeaderIfNoneMatch); match != "" && etagMatches(match, etag) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		return c.Next()
+	}
+}
+
+// etagMatches 判断If-None-Match头是否包含指定ETag（弱比较）
+func etagMatches(header, etag string) bool {
+	target := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == target {
+			return true
+		}
+	}
+	return false
+}
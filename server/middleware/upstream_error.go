@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/i18n"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// upstreamErrorResponse 各错误码对应的响应状态码和提示
+var upstreamErrorResponse = map[string]struct {
+	status  int
+	message string
+}{
+	models.ErrCookieInvalid:   {fiber.StatusBadGateway, "Cookie未配置或已失效，请更新Cookie"},
+	models.ErrCookieMissing:   {fiber.StatusPreconditionFailed, "请先配置Cookie"},
+	models.ErrUpstreamTimeout: {fiber.StatusGatewayTimeout, "上游请求超时"},
+	models.ErrRateLimited:     {fiber.StatusTooManyRequests, "上游请求过于频繁，请稍后再试"},
+	models.ErrAPIRequest:      {fiber.StatusBadGateway, "上游请求失败"},
+	models.ErrDataParsing:     {fiber.StatusBadGateway, "上游响应解析失败"},
+}
+
+// UpstreamErrorMiddleware 上游错误转换中间件
+// 处理器直接返回上游请求错误，由此统一转换为带错误码的响应，其余错误交给全局错误处理
+func UpstreamErrorMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err == nil {
+			return nil
+		}
+
+		code := client.ErrorCode(err)
+		resp, ok := upstreamErrorResponse[code]
+		if !ok {
+			return err
+		}
+
+		log.Printf("上游请求错误 [%s] %s: %v", code, c.Path(), err)
+		return c.Status(resp.status).JSON(models.Error(resp.status, i18n.T(c, resp.message), err).WithCode(code))
+	}
+}
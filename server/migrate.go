@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leafney/cccmu/server/database"
+)
+
+// runMigrations 执行尚未应用的数据库结构迁移；dryRun时只列出待执行的迁移及将要修改的键数
+// 已记录结构版本的数据库在迁移前先完整备份到数据目录，迁移结果不符合预期时可用备份恢复
+func runMigrations(db *database.BadgerDB, dataDir string, dryRun bool) error {
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		if dryRun {
+			fmt.Printf("✅ 数据库结构已是最新版本（v%d），无需迁移\n", database.LatestSchemaVersion())
+		}
+		return nil
+	}
+
+	if !dryRun {
+		version, err := db.SchemaVersion()
+		if err != nil {
+			return err
+		}
+		// 版本为0的数据库（引入迁移机制之前）只需记录初始版本，不必备份
+		if version > 0 {
+			path, err := backupBeforeMigration(db, dataDir, version)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("💾 迁移前已备份数据库: %s\n", path)
+		}
+	}
+
+	results, err := db.Migrate(dryRun)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		for _, result := range results {
+			fmt.Printf("  v%d %s：将修改 %d 个键\n", result.Version, result.Description, result.Affected)
+		}
+		fmt.Printf("🔍 共 %d 个待执行的迁移（dry-run，未修改数据）\n", len(results))
+	}
+	return nil
+}
+
+// backupBeforeMigration 将数据库备份到数据目录，文件名包含迁移前的结构版本和时间
+func backupBeforeMigration(db *database.BadgerDB, dataDir string, version int) (string, error) {
+	path := filepath.Join(dataDir, fmt.Sprintf("backup-v%d-%s.bak", version, time.Now().Format("20060102-150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("创建迁移前备份失败: %w", err)
+	}
+	if _, err := db.Backup(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("写入迁移前备份失败: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,228 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+const (
+	defaultCreditLimit = 20000 // 模拟账户积分上限
+	maxUsageRecords    = 200   // 保留的最大使用记录数
+	usageInterval      = 20 * time.Second
+	expiredCookie      = "expired" // 使用该Cookie时模拟会话失效（401）
+)
+
+// 模拟调用的模型列表
+var mockModels = []string{
+	"claude-sonnet-4-20250514",
+	"claude-opus-4-1-20250805",
+	"claude-3-5-haiku-20241022",
+}
+
+// Upstream 进程内模拟的上游API服务，用于离线开发和端到端调试
+type Upstream struct {
+	server        *http.Server
+	listener      net.Listener
+	credits       int                      // 当前剩余积分
+	creditLimit   int                      // 积分上限
+	records       []client.ClaudeUsageData // 使用记录（最新在前）
+	nextID        int                      // 下一条记录ID
+	lastTick      time.Time                // 上次生成使用记录的时间
+	lastResetDate string                   // 最后一次重置日期
+	mu            sync.Mutex
+}
+
+// NewUpstream 创建模拟上游服务
+func NewUpstream() *Upstream {
+	return &Upstream{
+		credits:     defaultCreditLimit,
+		creditLimit: defaultCreditLimit,
+		nextID:      1,
+		lastTick:    time.Now().Add(-time.Hour), // 预先生成最近一小时的使用记录
+	}
+}
+
+// Start 在本地随机端口启动模拟服务
+func (u *Upstream) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("监听模拟上游端口失败: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/user/usage", u.handleUsage)
+	mux.HandleFunc("/api/user/credits", u.handleCredits)
+	mux.HandleFunc("/api/user/credit-reset", u.handleCreditReset)
+
+	u.listener = listener
+	u.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := u.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			utils.Logf("[模拟上游] ❌ 服务异常退出: %v", err)
+		}
+	}()
+
+	utils.Logf("[模拟上游] 服务已启动: %s", u.URL())
+	return nil
+}
+
+// Stop 停止模拟服务
+func (u *Upstream) Stop() error {
+	if u.server == nil {
+		return nil
+	}
+	return u.server.Close()
+}
+
+// URL 获取模拟服务地址
+func (u *Upstream) URL() string {
+	if u.listener == nil {
+		return ""
+	}
+	return "http://" + u.listener.Addr().String()
+}
+
+// authorize 校验Cookie，未授权时写入401响应
+func (u *Upstream) authorize(w http.ResponseWriter, r *http.Request) bool {
+	cookie := r.Header.Get("Cookie")
+	if cookie == "" || cookie == expiredCookie {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// advance 按流逝时间生成模拟使用记录并扣减积分（调用方需持有锁）
+func (u *Upstream) advance() {
+	now := time.Now()
+	for t := u.lastTick.Add(usageInterval); !t.After(now); t = t.Add(usageInterval) {
+		u.lastTick = t
+
+		// 约一半的时间片内产生调用
+		if rand.Intn(2) == 0 {
+			continue
+		}
+
+		used := 10 + rand.Intn(290)
+		if used > u.credits {
+			used = u.credits
+		}
+		if used == 0 {
+			continue
+		}
+		u.credits -= used
+
+		record := client.ClaudeUsageData{
+			ID:          u.nextID,
+			Type:        "USAGE",
+			Endpoint:    "v1/messages",
+			StatusCode:  200,
+			CreditsUsed: used,
+			CreatedAt:   t.UTC().Format(time.RFC3339),
+			Model:       mockModels[rand.Intn(len(mockModels))],
+		}
+		u.nextID++
+
+		u.records = append([]client.ClaudeUsageData{record}, u.records...)
+		if len(u.records) > maxUsageRecords {
+			u.records = u.records[:maxUsageRecords]
+		}
+	}
+}
+
+// handleUsage 模拟 GET /api/user/usage
+func (u *Upstream) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.authorize(w, r) {
+		return
+	}
+
+	u.mu.Lock()
+	u.advance()
+	records := append([]client.ClaudeUsageData(nil), u.records...)
+	u.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, records)
+}
+
+// handleCredits 模拟 GET /api/user/credits
+func (u *Upstream) handleCredits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.authorize(w, r) {
+		return
+	}
+
+	u.mu.Lock()
+	u.advance()
+	resp := client.ClaudeCreditsResponse{
+		UserID:        1,
+		Email:         "mock@example.com",
+		Credits:       u.credits,
+		NormalCredits: u.credits,
+		CreditLimit:   u.creditLimit,
+		Plan:          "MOCK",
+	}
+	u.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCreditReset 模拟 POST /api/user/credit-reset，每天仅允许重置一次，重复重置返回400
+func (u *Upstream) handleCreditReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !u.authorize(w, r) {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	today := time.Now().Format("2006-01-02")
+	if u.lastResetDate == today {
+		utils.Logf("[模拟上游] 今日已重置过积分，返回400")
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "今日已重置"})
+		return
+	}
+
+	u.advance()
+	before := u.credits
+	u.credits = u.creditLimit
+	u.lastResetDate = today
+
+	utils.Logf("[模拟上游] 积分已重置: %d → %d", before, u.credits)
+	writeJSON(w, http.StatusOK, client.ClaudeResetCreditsResponse{
+		Success:        true,
+		BalanceBefore:  strconv.Itoa(before),
+		BalanceAfter:   strconv.Itoa(u.credits),
+		ResetAmount:    strconv.Itoa(u.credits - before),
+		UsedCount:      1,
+		MaxCount:       1,
+		RemainingCount: 0,
+	})
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 账户标签长度限制
+const (
+	MaxAccountNameLength  = 50
+	MaxAccountNotesLength = 500
+)
+
+// accountColorPattern 账户颜色格式（#RGB 或 #RRGGBB）
+var accountColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// AccountLabel 当前监控账户的展示信息，便于在多个实例和客户端中区分账户
+type AccountLabel struct {
+	Name  string `json:"name"`  // 显示名称（如 "公司号"）
+	Color string `json:"color"` // 标识颜色 "#RRGGBB"
+	Notes string `json:"notes"` // 备注
+}
+
+// Validate 校验并清理账户标签（去除首尾空白）
+func (a *AccountLabel) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Color = strings.TrimSpace(a.Color)
+	a.Notes = strings.TrimSpace(a.Notes)
+
+	var errs ValidationErrors
+	if utf8.RuneCountInString(a.Name) > MaxAccountNameLength {
+		errs.Addf("name", "账户名称不能超过%d个字符", MaxAccountNameLength)
+	}
+	if a.Color != "" && !accountColorPattern.MatchString(a.Color) {
+		errs.Addf("color", "账户颜色格式无效: %s（应为 #RGB 或 #RRGGBB）", a.Color)
+	}
+	if utf8.RuneCountInString(a.Notes) > MaxAccountNotesLength {
+		errs.Addf("notes", "账户备注不能超过%d个字符", MaxAccountNotesLength)
+	}
+	return errs.Err()
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 图表标注数量限制
+const (
+	MaxAnnotations          = 1000 // 最多保留的标注数，超出时删除最早创建的标注
+	MaxAnnotationTextLength = 200  // 标注文字的最大字符数
+	DefaultAnnotationDays   = 7    // 查询默认返回最近几天的标注
+	MaxAnnotationDays       = 365  // 单次查询最多返回最近几天的标注
+)
+
+// 标注变化类型
+const (
+	AnnotationCreated = "created" // 新建标注
+	AnnotationDeleted = "deleted" // 删除标注
+)
+
+// annotationColorPattern 标注颜色格式（#RGB或#RRGGBB）
+var annotationColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Annotation 使用图表上的事件标注（如"切换到opus"、"开始批量任务"），用于对照积分消耗的变化
+type Annotation struct {
+	ID        string    `json:"id"`              // 标注ID（创建时的Unix纳秒时间戳，补零为20位）
+	Time      time.Time `json:"time"`            // 标注的时间点
+	Text      string    `json:"text"`            // 标注文字
+	Color     string    `json:"color,omitempty"` // 颜色，为空时使用页面默认颜色
+	CreatedAt time.Time `json:"createdAt"`       // 创建时间
+}
+
+// AnnotationIDLength 标注ID的长度，定长使存储键的字典序与创建时间一致
+const AnnotationIDLength = 20
+
+// AnnotationID 根据创建时间生成标注ID
+func AnnotationID(t time.Time) string {
+	return fmt.Sprintf("%0*d", AnnotationIDLength, t.UnixNano())
+}
+
+// AnnotationRequest 创建标注的请求
+type AnnotationRequest struct {
+	Time  *time.Time `json:"time"`  // 标注的时间点，为空表示当前时间
+	Text  string     `json:"text"`  // 标注文字
+	Color string     `json:"color"` // 颜色（#RGB或#RRGGBB，可选）
+}
+
+// Validate 校验创建标注的请求（去除文字和颜色首尾空白）
+func (r *AnnotationRequest) Validate() error {
+	var errs ValidationErrors
+	r.Text = strings.TrimSpace(r.Text)
+	r.Color = strings.TrimSpace(r.Color)
+
+	if r.Text == "" {
+		errs.Addf("text", "不能为空")
+	} else if utf8.RuneCountInString(r.Text) > MaxAnnotationTextLength {
+		errs.Addf("text", "不能超过%d个字符", MaxAnnotationTextLength)
+	}
+	// 0001年（零值）和9999年通常是客户端的占位值，不是真实的标注时间
+	if r.Time != nil && (r.Time.Year() <= 1 || r.Time.Year() >= 9999) {
+		errs.Addf("time", "时间无效")
+	}
+	if r.Color != "" && !annotationColorPattern.MatchString(r.Color) {
+		errs.Addf("color", "格式应为#RGB或#RRGGBB")
+	}
+	return errs.Err()
+}
+
+// AnnotationEvent 标注变化事件（SSE annotation 事件）
+type AnnotationEvent struct {
+	Action     string     `json:"action"`     // created 或 deleted
+	Annotation Annotation `json:"annotation"` // 新建或被删除的标注
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnnotationID(t *testing.T) {
+	early := AnnotationID(time.Unix(0, 999))
+	late := AnnotationID(time.Unix(1767225600, 0))
+	if len(early) != AnnotationIDLength || len(late) != AnnotationIDLength {
+		t.Fatalf("ID应为%d位: %q %q", AnnotationIDLength, early, late)
+	}
+	if early >= late {
+		t.Fatalf("ID的字典序应与时间顺序一致: %q >= %q", early, late)
+	}
+}
+
+func TestAnnotationRequestValidateTime(t *testing.T) {
+	valid := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		time    *time.Time
+		wantErr bool
+	}{
+		{"未指定", nil, false},
+		{"正常时间", &valid, false},
+		{"零值", &time.Time{}, true},
+		{"9999年", func() *time.Time { t := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC); return &t }(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := AnnotationRequest{Time: tt.time, Text: "切换到opus"}
+			if err := req.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望出错: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// UsageArchiveFile 月度归档文件
+type UsageArchiveFile struct {
+	Name       string    `json:"name"`       // 文件名（<数据类型>-YYYY-MM.ndjson.gz）
+	Kind       string    `json:"kind"`       // 数据类型：usage、daily、resets、balance、aggregate
+	Month      string    `json:"month"`      // 归档月份（YYYY-MM）
+	Size       int64     `json:"size"`       // 文件大小（字节）
+	ModifiedAt time.Time `json:"modifiedAt"` // 最近写入时间
+}
+
+// UsageArchiveResult 一次归档的结果
+type UsageArchiveResult struct {
+	Records int      `json:"records"` // 写入归档并从数据库移除的原始记录数
+	Months  []string `json:"months"`  // 涉及的月份
+}
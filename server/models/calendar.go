@@ -0,0 +1,88 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// 日历事件类型
+const (
+	CalendarEventAutoReset     = "autoReset"     // 定时自动重置
+	CalendarEventMonitoringOn  = "monitoringOn"  // 自动调度：监控开启时段
+	CalendarEventMonitoringOff = "monitoringOff" // 自动调度：监控关闭时段
+)
+
+// 日历订阅包含的天数
+const (
+	DefaultCalendarDays = 14
+	MaxCalendarDays     = 60
+)
+
+// autoResetEventDuration 定时重置事件在日历中的展示时长（重置本身是瞬时动作）
+const autoResetEventDuration = 15 * time.Minute
+
+// CalendarEvent 自动化计划中的一个日历事件
+type CalendarEvent struct {
+	Kind  string    `json:"kind"`  // 事件类型
+	Start time.Time `json:"start"` // 开始时间
+	End   time.Time `json:"end"`   // 结束时间
+}
+
+// UpcomingCalendarEvents 根据配置计算从now起days天内的定时重置和自动调度时段（按开始时间排序）
+// 已跨过零点且尚未结束的调度时段也会包含在内；当日重置已使用时不再列出当日的定时重置
+func UpcomingCalendarEvents(config *UserConfig, now time.Time, days int) []CalendarEvent {
+	events := make([]CalendarEvent, 0)
+	if config == nil || days <= 0 {
+		return events
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	reset := config.AutoReset
+	if reset.Enabled && reset.TimeEnabled && validateTimeFormat(reset.ResetTime) == nil {
+		for i := 0; i < days; i++ {
+			start := atClock(today.AddDate(0, 0, i), reset.ResetTime)
+			if i == 0 && (config.IsDailyResetUsed(now) || start.Before(now)) {
+				continue
+			}
+			events = append(events, CalendarEvent{
+				Kind:  CalendarEventAutoReset,
+				Start: start,
+				End:   start.Add(autoResetEventDuration),
+			})
+		}
+	}
+
+	schedule := config.AutoSchedule
+	if schedule.Enabled && schedule.StartTime != schedule.EndTime &&
+		validateTimeFormat(schedule.StartTime) == nil && validateTimeFormat(schedule.EndTime) == nil {
+		kind := CalendarEventMonitoringOff
+		if schedule.MonitoringOn {
+			kind = CalendarEventMonitoringOn
+		}
+		// 从前一天开始计算，以包含跨日且仍在进行中的时段
+		for i := -1; i < days; i++ {
+			day := today.AddDate(0, 0, i)
+			start := atClock(day, schedule.StartTime)
+			end := atClock(day, schedule.EndTime)
+			if schedule.StartTime > schedule.EndTime {
+				end = atClock(day.AddDate(0, 0, 1), schedule.EndTime)
+			}
+			if !end.After(now) {
+				continue
+			}
+			events = append(events, CalendarEvent{Kind: kind, Start: start, End: end})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events
+}
+
+// atClock 返回指定日期在给定时刻(HH:MM)的时间，调用前须已校验格式
+func atClock(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
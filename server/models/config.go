@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/leafney/cccmu/server/strategy"
 )
 
 // AutoScheduleConfig 自动调度配置
@@ -17,17 +19,14 @@ type AutoScheduleConfig struct {
 
 // ValidateTime 验证时间格式是否正确 (HH:MM)
 func (a *AutoScheduleConfig) ValidateTime() error {
+	var errs ValidationErrors
 	if a.StartTime != "" {
-		if err := validateTimeFormat(a.StartTime); err != nil {
-			return fmt.Errorf("开始时间格式错误: %v", err)
-		}
+		errs.Add("startTime", validateTimeFormat(a.StartTime))
 	}
 	if a.EndTime != "" {
-		if err := validateTimeFormat(a.EndTime); err != nil {
-			return fmt.Errorf("结束时间格式错误: %v", err)
-		}
+		errs.Add("endTime", validateTimeFormat(a.EndTime))
 	}
-	return nil
+	return errs.Err()
 }
 
 // IsInTimeRange 检查当前时间是否在设置的时间范围内
@@ -92,31 +91,93 @@ type AutoResetConfig struct {
 	ThresholdTimeEnabled bool   `json:"thresholdTimeEnabled"` // 阈值时间范围是否启用
 	ThresholdStartTime   string `json:"thresholdStartTime"`   // 阈值检查开始时间 "HH:MM"
 	ThresholdEndTime     string `json:"thresholdEndTime"`     // 阈值检查结束时间 "HH:MM"
+	ThresholdAction      string `json:"thresholdAction"`      // 积分低于阈值时执行的动作，为空表示重置积分
+	ThresholdWebhookURL  string `json:"thresholdWebhookUrl"`  // 动作为webhook时调用的地址
+	ThresholdRearm       bool   `json:"thresholdRearm"`       // 已重置或已执行动作后，余额回升到阈值以上时重新启用阈值检查
+	Strategy             string `json:"strategy"`             // 自定义重置策略表达式，设置后替代阈值判断
+	RecoveryPerHour      int    `json:"recoveryPerHour"`      // 每小时恢复的积分（用于建议重置时间和预期余额），0表示根据积分余额历史估算
+	RecoveryCap          int    `json:"recoveryCap"`          // 积分上限（恢复和重置后的余额），0表示取积分余额历史中的最大值
+	AdoptSuggestion      bool   `json:"adoptSuggestion"`      // 每个重置周期开始时自动采用建议的重置时间（需启用时间触发）
+}
+
+// HasStrategy 是否设置了自定义重置策略
+func (a *AutoResetConfig) HasStrategy() bool {
+	return strings.TrimSpace(a.Strategy) != ""
+}
+
+// NeedsBalanceCheck 是否需要运行积分检查任务（启用阈值触发或设置了自定义策略）
+func (a *AutoResetConfig) NeedsBalanceCheck() bool {
+	return a.Enabled && (a.ThresholdEnabled || a.HasStrategy())
+}
+
+// ValidateStrategy 验证自定义重置策略
+func (a *AutoResetConfig) ValidateStrategy() error {
+	if !a.HasStrategy() {
+		return nil
+	}
+	var errs ValidationErrors
+	errs.Add("strategy", strategy.Validate(a.Strategy))
+	return errs.Err()
+}
+
+// 积分低于阈值时可执行的动作
+const (
+	ThresholdActionReset   = "reset"   // 重置积分（默认）
+	ThresholdActionNotify  = "notify"  // 仅推送通知
+	ThresholdActionStop    = "stop"    // 停止监控
+	ThresholdActionWebhook = "webhook" // 调用Webhook
+)
+
+// GetThresholdAction 获取阈值触发动作（未设置时为重置积分，兼容旧配置）
+func (a *AutoResetConfig) GetThresholdAction() string {
+	if a.ThresholdAction == "" {
+		return ThresholdActionReset
+	}
+	return a.ThresholdAction
 }
 
-// ValidateTime 验证自动重置时间格式
+// ValidateThresholdAction 验证积分阈值和阈值触发动作
+func (a *AutoResetConfig) ValidateThresholdAction() error {
+	var errs ValidationErrors
+	if a.Threshold < 0 {
+		errs.Addf("threshold", "积分阈值不能为负数")
+	}
+	switch a.GetThresholdAction() {
+	case ThresholdActionReset, ThresholdActionNotify, ThresholdActionStop:
+	case ThresholdActionWebhook:
+		if a.Enabled && a.ThresholdEnabled {
+			errs.Add("thresholdWebhookUrl", validateWebhookURL(a.ThresholdWebhookURL))
+		}
+	default:
+		errs.Addf("thresholdAction", "不支持的阈值触发动作: %s", a.ThresholdAction)
+	}
+	return errs.Err()
+}
+
+// ValidateTime 验证自动重置时间格式和积分恢复模型
 func (a *AutoResetConfig) ValidateTime() error {
+	var errs ValidationErrors
 	if a.Enabled && a.TimeEnabled && a.ResetTime != "" {
-		if err := validateTimeFormat(a.ResetTime); err != nil {
-			return fmt.Errorf("重置时间格式错误: %v", err)
-		}
+		errs.Add("resetTime", validateTimeFormat(a.ResetTime))
+	}
+	if a.RecoveryPerHour < 0 {
+		errs.Addf("recoveryPerHour", "不能小于0")
+	}
+	if a.RecoveryCap < 0 {
+		errs.Addf("recoveryCap", "不能小于0")
 	}
 
 	// 验证阈值时间范围格式
 	if a.Enabled && a.ThresholdEnabled && a.ThresholdTimeEnabled {
 		if a.ThresholdStartTime != "" {
-			if err := validateTimeFormat(a.ThresholdStartTime); err != nil {
-				return fmt.Errorf("阈值检查开始时间格式错误: %v", err)
-			}
+			errs.Add("thresholdStartTime", validateTimeFormat(a.ThresholdStartTime))
 		}
 		if a.ThresholdEndTime != "" {
-			if err := validateTimeFormat(a.ThresholdEndTime); err != nil {
-				return fmt.Errorf("阈值检查结束时间格式错误: %v", err)
-			}
+			errs.Add("thresholdEndTime", validateTimeFormat(a.ThresholdEndTime))
 		}
 	}
 
-	return nil
+	return errs.Err()
 }
 
 // IsInThresholdTimeRange 检查当前时间是否在阈值检查时间范围内
@@ -145,18 +206,237 @@ func (a *AutoResetConfig) IsInThresholdTimeRange(now time.Time) bool {
 	return currentTime >= a.ThresholdStartTime || currentTime <= a.ThresholdEndTime
 }
 
+// DefaultResetClockTime 默认的每日重置标记清除时刻
+const DefaultResetClockTime = "00:00"
+
+// ResetClockConfig 每日重置周期配置
+// 决定"今日已使用重置"标记的清除时刻，部分中转站的额度不在本地零点刷新
+type ResetClockConfig struct {
+	Time     string `json:"time"`     // 每日清除时刻 "HH:MM"
+	Timezone string `json:"timezone"` // IANA时区名（如 Asia/Shanghai），为空表示服务器本地时区
+}
+
+// Validate 验证每日重置周期配置（时刻为空时使用默认值）
+func (r *ResetClockConfig) Validate() error {
+	if r.Time == "" {
+		r.Time = DefaultResetClockTime
+	}
+	var errs ValidationErrors
+	errs.Add("time", validateTimeFormat(r.Time))
+	if r.Timezone != "" {
+		if _, err := time.LoadLocation(r.Timezone); err != nil {
+			errs.Addf("timezone", "时区无效: %s", r.Timezone)
+		}
+	}
+	return errs.Err()
+}
+
+// Location 获取重置周期使用的时区（未设置或无效时使用服务器本地时区）
+func (r ResetClockConfig) Location() *time.Location {
+	if r.Timezone != "" {
+		if loc, err := time.LoadLocation(r.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// clock 获取清除时刻的小时和分钟（未设置或无效时为0点）
+func (r ResetClockConfig) clock() (int, int) {
+	t, err := time.Parse("15:04", r.Time)
+	if err != nil {
+		return 0, 0
+	}
+	return t.Hour(), t.Minute()
+}
+
+// CronExpression 生成清除标记任务的Cron表达式（指定时区时带 CRON_TZ 前缀）
+func (r ResetClockConfig) CronExpression() string {
+	hour, minute := r.clock()
+	expr := fmt.Sprintf("%d %d * * *", minute, hour)
+	if r.Timezone != "" && r.Location() != time.Local {
+		expr = "CRON_TZ=" + r.Timezone + " " + expr
+	}
+	return expr
+}
+
+// PeriodStart 获取now所在重置周期的开始时间（最近一次到达清除时刻的时间）
+func (r ResetClockConfig) PeriodStart(now time.Time) time.Time {
+	loc := r.Location()
+	local := now.In(loc)
+	hour, minute := r.clock()
+	start := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// periodKeyLayout 重置周期标识的格式（UTC，可按字符串排序，可用作文件名）
+const periodKeyLayout = "2006-01-02T1504Z"
+
+// PeriodKey 获取now所在重置周期的标识（周期开始时间的UTC表示），按相同配置运行的多个实例得到相同的标识
+func (r ResetClockConfig) PeriodKey(now time.Time) string {
+	return r.PeriodStart(now).UTC().Format(periodKeyLayout)
+}
+
+// ParsePeriodKey 解析重置周期标识
+func ParsePeriodKey(key string) (time.Time, error) {
+	return time.Parse(periodKeyLayout, key)
+}
+
+// KeepAliveConfig Cookie保活配置
+type KeepAliveConfig struct {
+	Enabled       bool `json:"enabled"`       // 是否启用Cookie保活
+	IntervalHours int  `json:"intervalHours"` // 保活请求间隔(小时)
+}
+
+// Validate 验证Cookie保活配置
+func (k *KeepAliveConfig) Validate() {
+	if k.IntervalHours < 1 || k.IntervalHours > 24 {
+		k.IntervalHours = 4 // 最少1小时，最多24小时，默认4小时
+	}
+}
+
+// 自适应获取间隔默认值与取值范围
+const (
+	DefaultAdaptiveActiveInterval = 30  // 活跃时的获取间隔(秒)
+	DefaultAdaptiveIdleInterval   = 600 // 空闲时的获取间隔(秒)
+	DefaultAdaptiveIdleMinutes    = 30  // 无新记录多久后视为空闲(分钟)
+	MinAdaptiveInterval           = 30
+	MaxAdaptiveInterval           = 3600
+	MaxAdaptiveIdleMinutes        = 720
+)
+
+// AdaptiveIntervalConfig 按使用活跃度自动调整数据获取间隔
+// 启用后不再使用固定的获取间隔：持续出现新使用记录（正在编码）时缩短间隔，超过空闲时长没有新记录后延长间隔，减少夜间等空闲时段的上游请求
+type AdaptiveIntervalConfig struct {
+	Enabled        bool `json:"enabled"`        // 是否启用自适应获取间隔
+	ActiveInterval int  `json:"activeInterval"` // 活跃时的获取间隔(秒)
+	IdleInterval   int  `json:"idleInterval"`   // 空闲时的获取间隔(秒)
+	IdleMinutes    int  `json:"idleMinutes"`    // 最近一条使用记录距今超过该时长(分钟)视为空闲
+}
+
+// Validate 修正自适应获取间隔配置（超出范围时使用默认值，空闲间隔不小于活跃间隔）
+func (a *AdaptiveIntervalConfig) Validate() {
+	if a.ActiveInterval < MinAdaptiveInterval || a.ActiveInterval > MaxAdaptiveInterval {
+		a.ActiveInterval = DefaultAdaptiveActiveInterval
+	}
+	if a.IdleInterval < MinAdaptiveInterval || a.IdleInterval > MaxAdaptiveInterval {
+		a.IdleInterval = DefaultAdaptiveIdleInterval
+	}
+	if a.IdleInterval < a.ActiveInterval {
+		a.IdleInterval = a.ActiveInterval
+	}
+	if a.IdleMinutes < 1 || a.IdleMinutes > MaxAdaptiveIdleMinutes {
+		a.IdleMinutes = DefaultAdaptiveIdleMinutes
+	}
+}
+
+// IntervalFor 根据最近一条使用记录的时间计算获取间隔(秒)，未启用时返回固定间隔base
+func (a AdaptiveIntervalConfig) IntervalFor(base int, lastActivity, now time.Time) int {
+	if !a.Enabled {
+		return base
+	}
+	if !lastActivity.IsZero() && now.Sub(lastActivity) < time.Duration(a.IdleMinutes)*time.Minute {
+		return a.ActiveInterval
+	}
+	return a.IdleInterval
+}
+
+// 数据保留策略默认值与取值范围
+const (
+	DefaultUsageRetentionHours         = 48
+	MaxUsageRetentionHours             = 30 * 24
+	MaxDailyUsageRetentionDays         = 365
+	DefaultBalanceHistoryRetentionDays = 30
+	MaxBalanceHistoryRetentionDays     = 365
+	DefaultUsageAggregateRetentionDays = 365
+	MaxUsageAggregateRetentionDays     = 3650
+
+	// RetentionArchiveGraceDays 启用到期归档的数据在保留期满后额外保留的天数，
+	// 数据清理任务在此期间将其写入归档后删除，服务停止不超过该天数时不会丢失
+	RetentionArchiveGraceDays = 7
+)
+
+// RetentionConfig 数据保留策略
+type RetentionConfig struct {
+	UsageHours         int  `json:"usageHours"`         // 原始使用记录保留时长(小时)
+	DailyUsageDays     int  `json:"dailyUsageDays"`     // 每日积分统计保留天数
+	BalanceHistoryDays int  `json:"balanceHistoryDays"` // 积分余额历史保留天数
+	AggregateDays      int  `json:"aggregateDays"`      // 原始记录降采样后的15分钟聚合数据保留天数
+	ArchiveEnabled     bool `json:"archiveEnabled"`     // 移除原始记录前是否按月写入压缩归档，并在月份结束后归档整月数据
+
+	// 以下数据到期后写入按月压缩归档再删除，而不是直接删除
+	ArchiveDailyUsage     bool `json:"archiveDailyUsage"`     // 每日积分统计（及同期的积分重置记录）
+	ArchiveBalanceHistory bool `json:"archiveBalanceHistory"` // 积分余额历史
+	ArchiveAggregates     bool `json:"archiveAggregates"`     // 降采样的15分钟聚合数据
+}
+
+// DailyUsageTTLDays 每日积分统计在数据库中的实际保留天数（启用到期归档时包含宽限期）
+func (r RetentionConfig) DailyUsageTTLDays() int {
+	return r.DailyUsageDays + r.archiveGraceDays(r.ArchiveDailyUsage)
+}
+
+// BalanceHistoryTTLDays 积分余额历史在数据库中的实际保留天数（启用到期归档时包含宽限期）
+func (r RetentionConfig) BalanceHistoryTTLDays() int {
+	return r.BalanceHistoryDays + r.archiveGraceDays(r.ArchiveBalanceHistory)
+}
+
+// AggregateTTLDays 降采样数据在数据库中的实际保留天数（启用到期归档时包含宽限期）
+func (r RetentionConfig) AggregateTTLDays() int {
+	return r.AggregateDays + r.archiveGraceDays(r.ArchiveAggregates)
+}
+
+// archiveGraceDays 启用到期归档时的宽限天数
+func (r RetentionConfig) archiveGraceDays(archive bool) int {
+	if archive {
+		return RetentionArchiveGraceDays
+	}
+	return 0
+}
+
+// Validate 修正数据保留策略（超出范围时使用默认值，每日统计至少保留一周以满足周统计展示）
+func (r *RetentionConfig) Validate() {
+	if r.UsageHours < 1 || r.UsageHours > MaxUsageRetentionHours {
+		r.UsageHours = DefaultUsageRetentionHours
+	}
+	if r.DailyUsageDays < DailyUsageRetentionDays || r.DailyUsageDays > MaxDailyUsageRetentionDays {
+		r.DailyUsageDays = DailyUsageRetentionDays
+	}
+	if r.BalanceHistoryDays < 1 || r.BalanceHistoryDays > MaxBalanceHistoryRetentionDays {
+		r.BalanceHistoryDays = DefaultBalanceHistoryRetentionDays
+	}
+	if r.AggregateDays < 1 || r.AggregateDays > MaxUsageAggregateRetentionDays {
+		r.AggregateDays = DefaultUsageAggregateRetentionDays
+	}
+}
+
 // UserConfig 用户配置
 type UserConfig struct {
-	Cookie                   string             `json:"-"`                        // Claude API Cookie (内部存储，不直接序列化)
-	Interval                 int                `json:"interval"`                 // 数据获取间隔(秒)
-	TimeRange                int                `json:"timeRange"`                // 显示时间范围(分钟)
-	Enabled                  bool               `json:"enabled"`                  // 任务是否启用
-	LastCookieValidTime      time.Time          `json:"lastCookieValidTime"`      // 最后一次Cookie验证成功时间
-	CookieValidationInterval int                `json:"cookieValidationInterval"` // Cookie验证间隔(分钟)
-	DailyResetUsed           bool               `json:"dailyResetUsed"`           // 当日重置是否已使用
-	DailyUsageEnabled        bool               `json:"dailyUsageEnabled"`        // 是否启用每日积分使用量统计
-	AutoSchedule             AutoScheduleConfig `json:"autoSchedule"`             // 自动调度配置
-	AutoReset                AutoResetConfig    `json:"autoReset"`                // 自动重置配置
+	Cookie                   string                 `json:"-"`                        // Claude API Cookie (内部存储，不直接序列化)
+	Interval                 int                    `json:"interval"`                 // 数据获取间隔(秒)
+	TimeRange                int                    `json:"timeRange"`                // 显示时间范围(分钟)
+	Enabled                  bool                   `json:"enabled"`                  // 任务是否启用
+	LastCookieValidTime      time.Time              `json:"lastCookieValidTime"`      // 最后一次Cookie验证成功时间
+	CookieValidationInterval int                    `json:"cookieValidationInterval"` // Cookie验证间隔(分钟)
+	DailyResetUsed           bool                   `json:"dailyResetUsed"`           // 当日重置是否已使用
+	DailyResetUsedAt         time.Time              `json:"dailyResetUsedAt"`         // 标记当日重置已使用的时间
+	DailyUsageEnabled        bool                   `json:"dailyUsageEnabled"`        // 是否启用每日积分使用量统计
+	AutoSchedule             AutoScheduleConfig     `json:"autoSchedule"`             // 自动调度配置
+	AutoReset                AutoResetConfig        `json:"autoReset"`                // 自动重置配置
+	ResetClock               ResetClockConfig       `json:"resetClock"`               // 每日重置周期配置
+	KeepAlive                KeepAliveConfig        `json:"keepAlive"`                // Cookie保活配置
+	AdaptiveInterval         AdaptiveIntervalConfig `json:"adaptiveInterval"`         // 自适应获取间隔配置
+	Retention                RetentionConfig        `json:"retention"`                // 数据保留策略
+	ModelAliases             map[string]string      `json:"modelAliases,omitempty"`   // 模型别名映射（原始模型名 → 统一名称）
+	ModelGroups              []ModelGroup           `json:"modelGroups,omitempty"`    // 模型统计分组
+	Hooks                    []HookConfig           `json:"hooks,omitempty"`          // 事件Hook
+	Account                  AccountLabel           `json:"account"`                  // 账户标签
+	Exhaustion               ExhaustionConfig       `json:"exhaustion"`               // 积分耗尽告警
+	ExternalUsage            ExternalUsageConfig    `json:"externalUsage"`            // 外部使用告警
+	LoginAlert               LoginAlertConfig       `json:"loginAlert"`               // 新IP登录告警
+	HTTPTransport            HTTPTransportConfig    `json:"httpTransport"`            // 上游HTTP连接池
 }
 
 // VersionInfo 版本信息结构
@@ -165,33 +445,62 @@ type VersionInfo struct {
 	GitCommit string `json:"gitCommit"` // Git提交短哈希
 	BuildTime string `json:"buildTime"` // 构建时间
 	GoVersion string `json:"goVersion"` // Go版本
+
+	UpdateAvailable bool   `json:"updateAvailable"`         // 是否有新版本
+	LatestVersion   string `json:"latestVersion,omitempty"` // 最新发布版本
+	ReleaseURL      string `json:"releaseUrl,omitempty"`    // 最新发布页面地址
 }
 
 // UserConfigResponse API响应用的用户配置结构
 type UserConfigResponse struct {
-	Cookie                   bool               `json:"cookie"`                   // Cookie配置状态
-	Interval                 int                `json:"interval"`                 // 数据获取间隔(秒)
-	TimeRange                int                `json:"timeRange"`                // 显示时间范围(分钟)
-	Enabled                  bool               `json:"enabled"`                  // 任务是否启用
-	LastCookieValidTime      time.Time          `json:"lastCookieValidTime"`      // 最后一次Cookie验证成功时间
-	CookieValidationInterval int                `json:"cookieValidationInterval"` // Cookie验证间隔(分钟)
-	DailyResetUsed           bool               `json:"dailyResetUsed"`           // 当日重置是否已使用
-	DailyUsageEnabled        bool               `json:"dailyUsageEnabled"`        // 是否启用每日积分使用量统计
-	AutoSchedule             AutoScheduleConfig `json:"autoSchedule"`             // 自动调度配置
-	AutoReset                AutoResetConfig    `json:"autoReset"`                // 自动重置配置
-	Version                  VersionInfo        `json:"version"`                  // 版本信息
-	Plan                     string             `json:"plan"`                     // 订阅等级
+	Cookie                   bool                   `json:"cookie"`                   // Cookie配置状态
+	Interval                 int                    `json:"interval"`                 // 数据获取间隔(秒)
+	TimeRange                int                    `json:"timeRange"`                // 显示时间范围(分钟)
+	Enabled                  bool                   `json:"enabled"`                  // 任务是否启用
+	LastCookieValidTime      time.Time              `json:"lastCookieValidTime"`      // 最后一次Cookie验证成功时间
+	CookieValidationInterval int                    `json:"cookieValidationInterval"` // Cookie验证间隔(分钟)
+	DailyResetUsed           bool                   `json:"dailyResetUsed"`           // 当日重置是否已使用
+	DailyUsageEnabled        bool                   `json:"dailyUsageEnabled"`        // 是否启用每日积分使用量统计
+	AutoSchedule             AutoScheduleConfig     `json:"autoSchedule"`             // 自动调度配置
+	AutoReset                AutoResetConfig        `json:"autoReset"`                // 自动重置配置
+	ResetClock               ResetClockConfig       `json:"resetClock"`               // 每日重置周期配置
+	KeepAlive                KeepAliveConfig        `json:"keepAlive"`                // Cookie保活配置
+	AdaptiveInterval         AdaptiveIntervalConfig `json:"adaptiveInterval"`         // 自适应获取间隔配置
+	Retention                RetentionConfig        `json:"retention"`                // 数据保留策略
+	ModelAliases             map[string]string      `json:"modelAliases"`             // 模型别名映射
+	ModelGroups              []ModelGroup           `json:"modelGroups"`              // 模型统计分组
+	Hooks                    []HookConfig           `json:"hooks"`                    // 事件Hook
+	Account                  AccountLabel           `json:"account"`                  // 账户标签
+	Exhaustion               ExhaustionConfig       `json:"exhaustion"`               // 积分耗尽告警
+	ExternalUsage            ExternalUsageConfig    `json:"externalUsage"`            // 外部使用告警
+	LoginAlert               LoginAlertConfig       `json:"loginAlert"`               // 新IP登录告警
+	HTTPTransport            HTTPTransportConfig    `json:"httpTransport"`            // 上游HTTP连接池
+	Version                  VersionInfo            `json:"version"`                  // 版本信息
+	Plan                     string                 `json:"plan"`                     // 订阅等级
+	Warnings                 []string               `json:"warnings,omitempty"`       // 配置提示（如阈值重置依赖的监控未运行）
 }
 
 // UserConfigRequest API请求用的用户配置结构
 type UserConfigRequest struct {
-	Cookie            *string             `json:"cookie,omitempty"`            // Cookie内容（设置时使用，使用指针类型区分未设置和空字符串）
-	Interval          int                 `json:"interval"`                    // 数据获取间隔(秒)
-	TimeRange         int                 `json:"timeRange"`                   // 显示时间范围(分钟)
-	Enabled           bool                `json:"enabled"`                     // 任务是否启用
-	DailyUsageEnabled *bool               `json:"dailyUsageEnabled,omitempty"` // 是否启用每日积分使用量统计（可选）
-	AutoSchedule      *AutoScheduleConfig `json:"autoSchedule,omitempty"`      // 自动调度配置（可选）
-	AutoReset         *AutoResetConfig    `json:"autoReset,omitempty"`         // 自动重置配置（可选）
+	Cookie            *string                 `json:"cookie,omitempty"`            // Cookie内容（设置时使用，使用指针类型区分未设置和空字符串）
+	Interval          int                     `json:"interval"`                    // 数据获取间隔(秒)
+	TimeRange         int                     `json:"timeRange"`                   // 显示时间范围(分钟)
+	Enabled           bool                    `json:"enabled"`                     // 任务是否启用
+	DailyUsageEnabled *bool                   `json:"dailyUsageEnabled,omitempty"` // 是否启用每日积分使用量统计（可选）
+	AutoSchedule      *AutoScheduleConfig     `json:"autoSchedule,omitempty"`      // 自动调度配置（可选）
+	AutoReset         *AutoResetConfig        `json:"autoReset,omitempty"`         // 自动重置配置（可选）
+	ResetClock        *ResetClockConfig       `json:"resetClock,omitempty"`        // 每日重置周期配置（可选）
+	KeepAlive         *KeepAliveConfig        `json:"keepAlive,omitempty"`         // Cookie保活配置（可选）
+	AdaptiveInterval  *AdaptiveIntervalConfig `json:"adaptiveInterval,omitempty"`  // 自适应获取间隔配置（可选）
+	Retention         *RetentionConfig        `json:"retention,omitempty"`         // 数据保留策略（可选）
+	ModelAliases      map[string]string       `json:"modelAliases,omitempty"`      // 模型别名映射（可选，传空对象表示清空）
+	ModelGroups       *[]ModelGroup           `json:"modelGroups,omitempty"`       // 模型统计分组（可选，传空数组表示清空）
+	Hooks             *[]HookConfig           `json:"hooks,omitempty"`             // 事件Hook（可选，传空数组表示清空）
+	Account           *AccountLabel           `json:"account,omitempty"`           // 账户标签（可选）
+	Exhaustion        *ExhaustionConfig       `json:"exhaustion,omitempty"`        // 积分耗尽告警（可选）
+	ExternalUsage     *ExternalUsageConfig    `json:"externalUsage,omitempty"`     // 外部使用告警（可选）
+	LoginAlert        *LoginAlertConfig       `json:"loginAlert,omitempty"`        // 新IP登录告警（可选）
+	HTTPTransport     *HTTPTransportConfig    `json:"httpTransport,omitempty"`     // 上游HTTP连接池（可选）
 }
 
 // GetDefaultConfig 获取默认配置
@@ -221,11 +530,33 @@ func GetDefaultConfig() *UserConfig {
 			ThresholdStartTime:   "",
 			ThresholdEndTime:     "",
 		},
+		ResetClock: ResetClockConfig{
+			Time: DefaultResetClockTime, // 默认本地0点清除标记
+		},
+		KeepAlive: KeepAliveConfig{
+			Enabled:       false,
+			IntervalHours: 4, // 默认每4小时保活一次
+		},
+		AdaptiveInterval: AdaptiveIntervalConfig{
+			Enabled:        false,
+			ActiveInterval: DefaultAdaptiveActiveInterval,
+			IdleInterval:   DefaultAdaptiveIdleInterval,
+			IdleMinutes:    DefaultAdaptiveIdleMinutes,
+		},
+		Retention: RetentionConfig{
+			UsageHours:         DefaultUsageRetentionHours,
+			DailyUsageDays:     DailyUsageRetentionDays,
+			BalanceHistoryDays: DefaultBalanceHistoryRetentionDays,
+			AggregateDays:      DefaultUsageAggregateRetentionDays,
+		},
 	}
 }
 
 // ToResponse 转换为API响应格式
 func (c *UserConfig) ToResponse() *UserConfigResponse {
+	// Webhook地址中通常带有令牌，只返回占位值
+	redacted := *c
+	redacted.redactSecrets()
 	return &UserConfigResponse{
 		Cookie:                   c.Cookie != "", // 布尔值表示是否已配置
 		Interval:                 c.Interval,
@@ -233,14 +564,60 @@ func (c *UserConfig) ToResponse() *UserConfigResponse {
 		Enabled:                  c.Enabled,
 		LastCookieValidTime:      c.LastCookieValidTime,
 		CookieValidationInterval: c.CookieValidationInterval,
-		DailyResetUsed:           c.DailyResetUsed,
+		DailyResetUsed:           c.IsDailyResetUsed(time.Now()), // 按当前重置周期判断，停机错过清除时刻时不显示为已使用
 		DailyUsageEnabled:        c.DailyUsageEnabled,
-		AutoSchedule:             c.AutoSchedule, // 包含自动调度配置
-		AutoReset:                c.AutoReset,    // 包含自动重置配置
+		AutoSchedule:             c.AutoSchedule,     // 包含自动调度配置
+		AutoReset:                redacted.AutoReset, // 包含自动重置配置
+		ResetClock:               c.ResetClock,       // 包含每日重置周期配置
+		KeepAlive:                c.KeepAlive,        // 包含Cookie保活配置
+		AdaptiveInterval:         c.AdaptiveInterval,
+		Retention:                c.Retention,
+		ModelAliases:             c.ModelAliases,
+		ModelGroups:              c.ModelGroups,
+		Hooks:                    c.Hooks,
+		Account:                  c.Account,
+		Exhaustion:               redacted.Exhaustion,
+		ExternalUsage:            redacted.ExternalUsage,
+		LoginAlert:               redacted.LoginAlert,
+		HTTPTransport:            c.HTTPTransport,
 	}
 }
 
-// Validate 验证配置有效性
+// ToRequest 转换为包含全部可设置项的更新请求（不含Cookie），用于通过常规更新流程整体应用一份配置
+func (c *UserConfig) ToRequest() *UserConfigRequest {
+	config := *c
+	if config.ModelAliases == nil {
+		config.ModelAliases = map[string]string{} // 空对象表示清空
+	}
+	if config.ModelGroups == nil {
+		config.ModelGroups = []ModelGroup{}
+	}
+	if config.Hooks == nil {
+		config.Hooks = []HookConfig{}
+	}
+	return &UserConfigRequest{
+		Interval:          config.Interval,
+		TimeRange:         config.TimeRange,
+		Enabled:           config.Enabled,
+		DailyUsageEnabled: &config.DailyUsageEnabled,
+		AutoSchedule:      &config.AutoSchedule,
+		AutoReset:         &config.AutoReset,
+		ResetClock:        &config.ResetClock,
+		KeepAlive:         &config.KeepAlive,
+		AdaptiveInterval:  &config.AdaptiveInterval,
+		Retention:         &config.Retention,
+		ModelAliases:      config.ModelAliases,
+		ModelGroups:       &config.ModelGroups,
+		Hooks:             &config.Hooks,
+		Account:           &config.Account,
+		Exhaustion:        &config.Exhaustion,
+		ExternalUsage:     &config.ExternalUsage,
+		LoginAlert:        &config.LoginAlert,
+		HTTPTransport:     &config.HTTPTransport,
+	}
+}
+
+// Validate 验证配置有效性，无效时返回ValidationErrors，字段路径与请求JSON一致
 func (c *UserConfig) Validate() error {
 	if c.Interval < 30 {
 		c.Interval = 60 // 最少30秒，默认60秒
@@ -252,15 +629,82 @@ func (c *UserConfig) Validate() error {
 		c.CookieValidationInterval = 10 // 最少5分钟，默认10分钟
 	}
 
-	// 验证自动调度配置
-	if err := c.AutoSchedule.ValidateTime(); err != nil {
-		return fmt.Errorf("自动调度配置无效: %v", err)
+	// 修正Cookie保活配置
+	c.KeepAlive.Validate()
+
+	// 修正自适应获取间隔配置
+	c.AdaptiveInterval.Validate()
+
+	// 修正数据保留策略
+	c.Retention.Validate()
+
+	// 逐项验证并收集全部无效字段，一次返回
+	var errs ValidationErrors
+
+	// 验证模型别名
+	if aliases, err := ValidateModelAliases(c.ModelAliases); err != nil {
+		errs.Add("modelAliases", err)
+	} else {
+		c.ModelAliases = aliases
 	}
 
-	// 验证自动重置配置
-	if err := c.AutoReset.ValidateTime(); err != nil {
-		return fmt.Errorf("自动重置配置无效: %v", err)
+	// 验证模型分组
+	if groups, err := ValidateModelGroups(c.ModelGroups); err != nil {
+		errs.Add("modelGroups", err)
+	} else {
+		c.ModelGroups = groups
 	}
 
-	return nil
+	// 验证事件Hook
+	if hooks, err := ValidateHooks(c.Hooks); err != nil {
+		errs.Add("hooks", err)
+	} else {
+		c.Hooks = hooks
+	}
+
+	// 验证账户标签
+	errs.Add("account", c.Account.Validate())
+
+	// 验证积分耗尽告警配置
+	errs.Add("exhaustion", c.Exhaustion.Validate())
+
+	// 验证外部使用告警配置
+	errs.Add("externalUsage", c.ExternalUsage.Validate())
+
+	// 验证新IP登录告警配置
+	errs.Add("loginAlert", c.LoginAlert.Validate())
+
+	// 验证上游HTTP连接池配置
+	errs.Add("httpTransport", c.HTTPTransport.Validate())
+
+	// 验证自动调度配置
+	errs.Add("autoSchedule", c.AutoSchedule.ValidateTime())
+
+	// 验证自动重置配置
+	errs.Add("autoReset", c.AutoReset.ValidateTime())
+	errs.Add("autoReset", c.AutoReset.ValidateThresholdAction())
+	errs.Add("autoReset", c.AutoReset.ValidateStrategy())
+
+	// 验证每日重置周期配置
+	errs.Add("resetClock", c.ResetClock.Validate())
+
+	return errs.Err()
+}
+
+// MarkDailyResetUsed 标记当前重置周期已使用重置
+func (c *UserConfig) MarkDailyResetUsed(now time.Time) {
+	c.DailyResetUsed = true
+	c.DailyResetUsedAt = now
+}
+
+// IsDailyResetUsed 判断当前重置周期是否已使用重置
+// 标记时间早于当前周期开始（如服务停机错过了清除时刻）时视为未使用；旧版本数据没有标记时间，以标记为准
+func (c *UserConfig) IsDailyResetUsed(now time.Time) bool {
+	if !c.DailyResetUsed {
+		return false
+	}
+	if c.DailyResetUsedAt.IsZero() {
+		return true
+	}
+	return !c.DailyResetUsedAt.Before(c.ResetClock.PeriodStart(now))
 }
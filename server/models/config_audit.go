@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 配置审计记录数量限制
+const (
+	MaxConfigAuditEntries    = 500 // 最多保留的审计记录数，超出时删除最早的记录
+	DefaultConfigAuditLimit  = 50  // 查询默认返回的记录数
+	MaxConfigAuditQueryLimit = 500 // 单次查询最多返回的记录数
+)
+
+// configAuditIgnoredFields 不记录审计的字段（随运行状态频繁变化，不属于用户设置）
+var configAuditIgnoredFields = map[string]bool{
+	"lastCookieValidTime": true,
+}
+
+// ConfigChange 配置中单个字段的变更
+type ConfigChange struct {
+	Field string      `json:"field"` // 字段路径，如 autoReset.threshold
+	Old   interface{} `json:"old"`   // 变更前的值（新增字段为null）
+	New   interface{} `json:"new"`   // 变更后的值（删除字段为null）
+}
+
+// ConfigAuditEntry 一次配置保存产生的审计记录
+type ConfigAuditEntry struct {
+	Time    time.Time      `json:"time"`    // 保存时间
+	Changes []ConfigChange `json:"changes"` // 字段级变更（按字段路径排序）
+}
+
+// DiffConfig 比较两份配置，返回字段级变更列表
+// 嵌套对象展开为以点分隔的字段路径，数组整体比较；敏感字段（SecretFields）只记录是否已配置，不记录内容
+func DiffConfig(previous, current *UserConfig) []ConfigChange {
+	oldFields := flattenConfig(previous)
+	newFields := flattenConfig(current)
+
+	changes := make([]ConfigChange, 0)
+	for field, oldValue := range oldFields {
+		newValue, ok := newFields[field]
+		if !ok {
+			changes = append(changes, ConfigChange{Field: field, Old: oldValue})
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, ConfigChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, ConfigChange{Field: field, New: newValue})
+		}
+	}
+
+	for _, secret := range SecretFields {
+		oldValue, newValue := *secret.field(previous), *secret.field(current)
+		if oldValue != newValue {
+			changes = append(changes, ConfigChange{
+				Field: secret.Path,
+				Old:   oldValue != "",
+				New:   newValue != "",
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// RedactSecrets 将敏感字段变更的值替换为是否已配置（兼容旧版本记录中保存的Webhook地址明文）
+func (e ConfigAuditEntry) RedactSecrets() ConfigAuditEntry {
+	for i, change := range e.Changes {
+		if !IsSecretField(change.Field) {
+			continue
+		}
+		if value, ok := change.Old.(string); ok {
+			e.Changes[i].Old = value != ""
+		}
+		if value, ok := change.New.(string); ok {
+			e.Changes[i].New = value != ""
+		}
+	}
+	return e
+}
+
+// FilterField 只保留指定字段（含其子字段）的变更，没有匹配的变更时返回false
+func (e ConfigAuditEntry) FilterField(field string) (ConfigAuditEntry, bool) {
+	if field == "" {
+		return e, true
+	}
+	filtered := ConfigAuditEntry{Time: e.Time, Changes: make([]ConfigChange, 0)}
+	for _, change := range e.Changes {
+		if change.Field == field || strings.HasPrefix(change.Field, field+".") {
+			filtered.Changes = append(filtered.Changes, change)
+		}
+	}
+	return filtered, len(filtered.Changes) > 0
+}
+
+// flattenConfig 将配置按JSON字段展开为字段路径到值的映射
+func flattenConfig(config *UserConfig) map[string]interface{} {
+	fields := make(map[string]interface{})
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fields
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fields
+	}
+	for key, value := range root {
+		if !configAuditIgnoredFields[key] {
+			flattenValue(key, value, fields)
+		}
+	}
+	// 敏感字段单独比较，不保留内容
+	for _, secret := range SecretFields {
+		delete(fields, secret.Path)
+	}
+	return fields
+}
+
+// flattenValue 递归展开嵌套对象
+func flattenValue(path string, value interface{}, fields map[string]interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		fields[path] = value
+		return
+	}
+	for key, child := range object {
+		flattenValue(path+"."+key, child, fields)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffConfigRedactsSecrets(t *testing.T) {
+	previous := GetDefaultConfig()
+	current := GetDefaultConfig()
+	current.Cookie = "sessionKey=secret-cookie"
+	current.AutoReset.ThresholdWebhookURL = "https://hooks.example.com/secret-threshold"
+	current.Exhaustion.WebhookURL = "https://hooks.example.com/secret-exhaustion"
+	current.ExternalUsage.WebhookURL = "https://hooks.example.com/secret-external"
+	current.LoginAlert.WebhookURL = "https://hooks.example.com/secret-login"
+	current.Interval = previous.Interval + 30
+
+	changes := DiffConfig(previous, current)
+	data, _ := json.Marshal(changes)
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("审计记录中不应包含敏感字段明文: %s", data)
+	}
+
+	byField := make(map[string]ConfigChange)
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+	for _, secret := range SecretFields {
+		change, ok := byField[secret.Path]
+		if !ok {
+			t.Fatalf("缺少敏感字段 %s 的变更记录", secret.Path)
+		}
+		if change.Old != false || change.New != true {
+			t.Fatalf("%s 应只记录是否已配置: %+v", secret.Path, change)
+		}
+	}
+	if _, ok := byField["interval"]; !ok {
+		t.Fatal("普通字段的变更应照常记录")
+	}
+
+	// 地址变化但均已配置时仍记录一次变更
+	next := *current
+	next.LoginAlert.WebhookURL = "https://hooks.example.com/another"
+	changes = DiffConfig(current, &next)
+	if len(changes) != 1 || changes[0].Field != "loginAlert.webhookUrl" || changes[0].Old != true || changes[0].New != true {
+		t.Fatalf("Webhook地址变更记录不正确: %+v", changes)
+	}
+}
+
+func TestRedactSecretsLegacyEntry(t *testing.T) {
+	entry := ConfigAuditEntry{Changes: []ConfigChange{
+		{Field: "exhaustion.webhookUrl", Old: "", New: "https://hooks.example.com/secret"},
+		{Field: "interval", Old: 60.0, New: 90.0},
+	}}
+	redacted := entry.RedactSecrets()
+	if redacted.Changes[0].Old != false || redacted.Changes[0].New != true {
+		t.Fatalf("旧记录中的Webhook地址应被脱敏: %+v", redacted.Changes[0])
+	}
+	if redacted.Changes[1].New != 90.0 {
+		t.Fatalf("普通字段不应被修改: %+v", redacted.Changes[1])
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PendingConfigJob 待处理的异步配置更新任务（持久化记录）
+// 只记录任务类型，重放时以数据库中已保存的配置为准，保证重复执行的结果一致
+type PendingConfigJob struct {
+	ID        string    `json:"id"`        // 任务ID
+	Type      string    `json:"type"`      // 任务类型
+	CreatedAt time.Time `json:"createdAt"` // 提交时间
+	Attempts  int       `json:"attempts"`  // 已失败的次数
+}
+
+// DeadConfigJob 多次重试仍失败、已移入死信列表的异步配置任务
+type DeadConfigJob struct {
+	ID        string    `json:"id"`        // 任务ID
+	Type      string    `json:"type"`      // 任务类型
+	CreatedAt time.Time `json:"createdAt"` // 提交时间
+	Attempts  int       `json:"attempts"`  // 累计执行次数
+	LastError string    `json:"lastError"` // 最后一次失败原因
+	FailedAt  time.Time `json:"failedAt"`  // 移入死信列表的时间
+}
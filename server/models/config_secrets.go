@@ -0,0 +1,61 @@
+package models
+
+// SecretField 配置中的敏感字段：落盘时加密，配置审计和撤销快照中不保留明文
+type SecretField struct {
+	Path  string                      // 字段路径（与配置审计的字段路径一致）
+	field func(c *UserConfig) *string // 字段地址
+}
+
+// SecretFields 配置中的所有敏感字段（Webhook地址中通常带有令牌）
+var SecretFields = []SecretField{
+	{Path: "cookie", field: func(c *UserConfig) *string { return &c.Cookie }},
+	{Path: "autoReset.thresholdWebhookUrl", field: func(c *UserConfig) *string { return &c.AutoReset.ThresholdWebhookURL }},
+	{Path: "exhaustion.webhookUrl", field: func(c *UserConfig) *string { return &c.Exhaustion.WebhookURL }},
+	{Path: "externalUsage.webhookUrl", field: func(c *UserConfig) *string { return &c.ExternalUsage.WebhookURL }},
+	{Path: "loginAlert.webhookUrl", field: func(c *UserConfig) *string { return &c.LoginAlert.WebhookURL }},
+}
+
+// RedactedSecret 配置响应中已配置的敏感字段的占位值，更新请求中原样提交时保留原值
+const RedactedSecret = "******"
+
+// IsSecretField 判断字段路径是否为敏感字段
+func IsSecretField(path string) bool {
+	for _, secret := range SecretFields {
+		if secret.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// MapSecrets 依次转换配置中的所有非空敏感字段（如加密、解密），出错时立即返回
+func (c *UserConfig) MapSecrets(transform func(path, value string) (string, error)) error {
+	for _, secret := range SecretFields {
+		value := secret.field(c)
+		if *value == "" {
+			continue
+		}
+		mapped, err := transform(secret.Path, *value)
+		if err != nil {
+			return err
+		}
+		*value = mapped
+	}
+	return nil
+}
+
+// redactSecrets 将非空敏感字段替换为占位值（用于配置响应）
+func (c *UserConfig) redactSecrets() {
+	c.MapSecrets(func(path, value string) (string, error) {
+		return RedactedSecret, nil
+	})
+}
+
+// RestoreRedactedSecrets 将仍为占位值的敏感字段恢复为当前配置中的值（客户端提交配置响应中的占位值时不修改原值）
+func (c *UserConfig) RestoreRedactedSecrets(current *UserConfig) {
+	for _, secret := range SecretFields {
+		if value := secret.field(c); *value == RedactedSecret {
+			*value = *secret.field(current)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// SyncedConfig 多实例间同步的配置项
+// 仅包含调度、阈值和统计相关设置；Cookie、监控开关和当日重置标记等实例自身状态不参与同步
+type SyncedConfig struct {
+	Interval          int                    `json:"interval"`               // 数据获取间隔(秒)
+	TimeRange         int                    `json:"timeRange"`              // 显示时间范围(分钟)
+	DailyUsageEnabled bool                   `json:"dailyUsageEnabled"`      // 是否启用每日积分使用量统计
+	AutoSchedule      AutoScheduleConfig     `json:"autoSchedule"`           // 自动调度配置
+	AutoReset         AutoResetConfig        `json:"autoReset"`              // 自动重置配置
+	ResetClock        ResetClockConfig       `json:"resetClock"`             // 每日重置周期配置
+	KeepAlive         KeepAliveConfig        `json:"keepAlive"`              // Cookie保活配置
+	AdaptiveInterval  AdaptiveIntervalConfig `json:"adaptiveInterval"`       // 自适应获取间隔配置
+	Retention         RetentionConfig        `json:"retention"`              // 数据保留策略
+	ModelAliases      map[string]string      `json:"modelAliases,omitempty"` // 模型别名映射
+	ModelGroups       []ModelGroup           `json:"modelGroups,omitempty"`  // 模型统计分组
+}
+
+// ConfigSyncSnapshot 主实例发布的同步配置快照
+type ConfigSyncSnapshot struct {
+	Version string       `json:"version"` // 配置版本（同步配置内容的哈希，内容不变则版本不变）
+	Config  SyncedConfig `json:"config"`  // 同步配置
+}
+
+// ConfigSyncStatus 从主实例同步配置的状态
+type ConfigSyncStatus struct {
+	Enabled       bool   `json:"enabled"`                 // 是否启用配置同步
+	Source        string `json:"source,omitempty"`        // 主实例地址
+	Version       string `json:"version,omitempty"`       // 最近一次应用的配置版本
+	LastSyncAt    string `json:"lastSyncAt,omitempty"`    // 最近一次成功拉取的时间
+	LastAppliedAt string `json:"lastAppliedAt,omitempty"` // 最近一次应用配置变更的时间
+	LastError     string `json:"lastError,omitempty"`     // 最近一次同步失败的原因
+}
+
+// Synced 提取参与同步的配置项
+func (c *UserConfig) Synced() SyncedConfig {
+	return SyncedConfig{
+		Interval:          c.Interval,
+		TimeRange:         c.TimeRange,
+		DailyUsageEnabled: c.DailyUsageEnabled,
+		AutoSchedule:      c.AutoSchedule,
+		AutoReset:         c.AutoReset,
+		ResetClock:        c.ResetClock,
+		KeepAlive:         c.KeepAlive,
+		AdaptiveInterval:  c.AdaptiveInterval,
+		Retention:         c.Retention,
+		ModelAliases:      c.ModelAliases,
+		ModelGroups:       c.ModelGroups,
+	}
+}
+
+// Snapshot 生成同步配置快照
+func (s SyncedConfig) Snapshot() ConfigSyncSnapshot {
+	// 结构体字段顺序固定、map按键排序序列化，相同内容得到相同版本
+	data, _ := json.Marshal(s)
+	sum := sha256.Sum256(data)
+	return ConfigSyncSnapshot{
+		Version: hex.EncodeToString(sum[:8]),
+		Config:  s,
+	}
+}
+
+// WithSynced 返回应用同步配置项后的配置副本，其余字段保持不变
+func (c *UserConfig) WithSynced(s SyncedConfig) *UserConfig {
+	updated := *c
+	updated.Interval = s.Interval
+	updated.TimeRange = s.TimeRange
+	updated.DailyUsageEnabled = s.DailyUsageEnabled
+	updated.AutoSchedule = s.AutoSchedule
+	updated.AutoReset = s.AutoReset
+	updated.ResetClock = s.ResetClock
+	updated.KeepAlive = s.KeepAlive
+	updated.AdaptiveInterval = s.AdaptiveInterval
+	updated.Retention = s.Retention
+	updated.ModelAliases = s.ModelAliases
+	updated.ModelGroups = s.ModelGroups
+
+	// 与页面修改配置一致：启用自动调度时强制开启监控开关
+	if updated.AutoSchedule.Enabled {
+		updated.Enabled = true
+	}
+	return &updated
+}
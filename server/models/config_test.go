@@ -0,0 +1,189 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResetClockPeriodStart(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("缺少时区数据: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		clock ResetClockConfig
+		now   time.Time
+		want  time.Time
+	}{
+		{
+			name:  "清除时刻之后属于当天周期",
+			clock: ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"},
+			now:   time.Date(2025, 1, 2, 9, 0, 0, 0, shanghai),
+			want:  time.Date(2025, 1, 2, 8, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "清除时刻之前属于前一天周期",
+			clock: ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"},
+			now:   time.Date(2025, 1, 2, 7, 59, 0, 0, shanghai),
+			want:  time.Date(2025, 1, 1, 8, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "恰好到达清除时刻开始新周期",
+			clock: ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"},
+			now:   time.Date(2025, 1, 2, 8, 0, 0, 0, shanghai),
+			want:  time.Date(2025, 1, 2, 8, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "按配置时区而不是now的时区计算",
+			clock: ResetClockConfig{Time: "00:00", Timezone: "Asia/Shanghai"},
+			now:   time.Date(2025, 1, 1, 17, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 1, 2, 0, 0, 0, 0, shanghai),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.clock.PeriodStart(tt.now); !got.Equal(tt.want) {
+				t.Fatalf("周期开始时间 = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetClockPeriodKey(t *testing.T) {
+	clock := ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"}
+	shanghai := clock.Location()
+
+	morning := clock.PeriodKey(time.Date(2025, 1, 2, 9, 0, 0, 0, shanghai))
+	night := clock.PeriodKey(time.Date(2025, 1, 3, 7, 0, 0, 0, shanghai))
+	next := clock.PeriodKey(time.Date(2025, 1, 3, 8, 0, 0, 0, shanghai))
+
+	if morning != night {
+		t.Fatalf("同一周期的标识不一致: %s / %s", morning, night)
+	}
+	if morning == next {
+		t.Fatalf("跨周期的标识相同: %s", morning)
+	}
+	if !(morning < next) {
+		t.Fatalf("周期标识应可按字符串排序: %s / %s", morning, next)
+	}
+
+	start, err := ParsePeriodKey(morning)
+	if err != nil {
+		t.Fatalf("解析周期标识失败: %v", err)
+	}
+	if want := time.Date(2025, 1, 2, 8, 0, 0, 0, shanghai); !start.Equal(want) {
+		t.Fatalf("解析结果 = %v，期望 %v", start, want)
+	}
+}
+
+func TestIsDailyResetUsed(t *testing.T) {
+	clock := ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"}
+	shanghai := clock.Location()
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, shanghai)
+
+	tests := []struct {
+		name   string
+		used   bool
+		usedAt time.Time
+		want   bool
+	}{
+		{name: "未使用", used: false, want: false},
+		{name: "本周期内使用", used: true, usedAt: time.Date(2025, 1, 2, 9, 0, 0, 0, shanghai), want: true},
+		{name: "上一周期使用（错过清除时刻）", used: true, usedAt: time.Date(2025, 1, 2, 7, 0, 0, 0, shanghai), want: false},
+		{name: "旧版本数据没有标记时间", used: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := UserConfig{ResetClock: clock, DailyResetUsed: tt.used, DailyResetUsedAt: tt.usedAt}
+			if got := config.IsDailyResetUsed(now); got != tt.want {
+				t.Fatalf("IsDailyResetUsed = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+
+	var config UserConfig
+	config.ResetClock = clock
+	config.MarkDailyResetUsed(now)
+	if !config.IsDailyResetUsed(now) {
+		t.Fatal("标记后本周期应视为已使用")
+	}
+	if config.IsDailyResetUsed(now.Add(24 * time.Hour)) {
+		t.Fatal("下一周期应视为未使用")
+	}
+}
+
+func TestToResponseDailyResetUsedFollowsPeriod(t *testing.T) {
+	config := GetDefaultConfig()
+	config.DailyResetUsed = true
+	config.DailyResetUsedAt = config.ResetClock.PeriodStart(time.Now()).Add(-time.Minute)
+	if config.ToResponse().DailyResetUsed {
+		t.Fatal("上一周期的重置标记不应显示为已使用")
+	}
+
+	config.MarkDailyResetUsed(time.Now())
+	if !config.ToResponse().DailyResetUsed {
+		t.Fatal("本周期的重置标记应显示为已使用")
+	}
+}
+
+func TestToResponseRedactsWebhookURLs(t *testing.T) {
+	config := GetDefaultConfig()
+	config.AutoReset.ThresholdWebhookURL = "https://hooks.example.com/reset?token=secret1"
+	config.Exhaustion.WebhookURL = "https://hooks.example.com/exhaustion?token=secret2"
+	config.ExternalUsage.WebhookURL = "https://hooks.example.com/external?token=secret3"
+	config.LoginAlert.WebhookURL = "https://hooks.example.com/login?token=secret4"
+
+	response := config.ToResponse()
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if strings.Contains(string(data), "token=secret") {
+		t.Fatalf("配置响应不应包含Webhook地址明文: %s", data)
+	}
+	for name, value := range map[string]string{
+		"autoReset.thresholdWebhookUrl": response.AutoReset.ThresholdWebhookURL,
+		"exhaustion.webhookUrl":         response.Exhaustion.WebhookURL,
+		"externalUsage.webhookUrl":      response.ExternalUsage.WebhookURL,
+		"loginAlert.webhookUrl":         response.LoginAlert.WebhookURL,
+	} {
+		if value != RedactedSecret {
+			t.Errorf("%s 应为占位值，实际 %q", name, value)
+		}
+	}
+	if config.Exhaustion.WebhookURL != "https://hooks.example.com/exhaustion?token=secret2" {
+		t.Error("生成响应不应修改原配置")
+	}
+
+	// 未配置的地址保持为空
+	if got := GetDefaultConfig().ToResponse().Exhaustion.WebhookURL; got != "" {
+		t.Errorf("未配置的Webhook地址应为空，实际 %q", got)
+	}
+}
+
+func TestRestoreRedactedSecrets(t *testing.T) {
+	current := GetDefaultConfig()
+	current.Exhaustion.WebhookURL = "https://hooks.example.com/exhaustion?token=secret"
+	current.LoginAlert.WebhookURL = "https://hooks.example.com/login?token=secret"
+
+	// 客户端提交配置响应中的占位值时保留原值，修改或清空时使用新值
+	updated := *current
+	updated.Exhaustion = current.ToResponse().Exhaustion
+	updated.LoginAlert.WebhookURL = ""
+	updated.ExternalUsage.WebhookURL = "https://hooks.example.com/new"
+	updated.RestoreRedactedSecrets(current)
+
+	if updated.Exhaustion.WebhookURL != current.Exhaustion.WebhookURL {
+		t.Errorf("占位值应恢复为原地址，实际 %q", updated.Exhaustion.WebhookURL)
+	}
+	if updated.LoginAlert.WebhookURL != "" {
+		t.Errorf("清空的地址不应恢复，实际 %q", updated.LoginAlert.WebhookURL)
+	}
+	if updated.ExternalUsage.WebhookURL != "https://hooks.example.com/new" {
+		t.Errorf("新地址不应被替换，实际 %q", updated.ExternalUsage.WebhookURL)
+	}
+}
@@ -0,0 +1,256 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Cookie输入格式
+const (
+	CookieFormatHeader   = "header"   // 请求头或 name=value 对（分号或换行分隔）
+	CookieFormatJSON     = "json"     // 浏览器扩展导出的JSON（数组或包含cookies的对象）
+	CookieFormatDevTools = "devtools" // 开发者工具Cookie表格复制的制表符分隔行
+)
+
+// cookieAttributes Set-Cookie属性名，粘贴响应头时忽略
+var cookieAttributes = map[string]bool{
+	"path": true, "domain": true, "expires": true, "max-age": true,
+	"secure": true, "httponly": true, "samesite": true, "priority": true, "partitioned": true,
+}
+
+// ignoredCookiePrefixes 统计分析、广告和客服组件的Cookie前缀，与登录无关，解析时去除
+var ignoredCookiePrefixes = []string{
+	"_ga", "_gid", "_gat", "_gcl_", // Google Analytics / Ads
+	"_fbp", "_fbc", // Facebook
+	"_clck", "_clsk", // Microsoft Clarity
+	"_hj",                // Hotjar
+	"ph_", "mp_", "ajs_", // PostHog、Mixpanel、Segment
+	"intercom-", "__stripe_", // Intercom、Stripe
+}
+
+// ParsedCookie 解析后的Cookie
+type ParsedCookie struct {
+	Cookie  string   // 规范化的请求头值 "name=value; name2=value2"
+	Format  string   // 识别出的输入格式
+	Names   []string // 保留的Cookie名称
+	Ignored []string // 去除的Cookie名称（其他域名、统计分析等）
+}
+
+// cookieEntry 解析出的单个Cookie
+type cookieEntry struct {
+	name, value, domain string
+	expires             time.Time // 零值表示会话Cookie或未知
+}
+
+// jsonCookie 浏览器扩展导出的Cookie（兼容 Cookie-Editor、EditThisCookie 和 Playwright 的字段名）
+type jsonCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	ExpirationDate *float64 `json:"expirationDate"`
+	Expires        *float64 `json:"expires"`
+}
+
+// ParseCookie 解析多种格式的Cookie输入并规范化为请求头值
+// 去除不属于host（为空时不按域名过滤）的Cookie和统计分析类Cookie，同名Cookie保留第一个；
+// required中的名称缺失或已过期时返回字段级验证错误，逐一列出缺少的名称；
+// required的每一项可用"|"分隔多个可选名称，存在其中任意一个即满足
+func ParseCookie(input, host string, required []string, now time.Time) (ParsedCookie, error) {
+	var errs ValidationErrors
+	input = strings.TrimSpace(input)
+
+	var entries []cookieEntry
+	var parsed ParsedCookie
+	switch {
+	case strings.HasPrefix(input, "[") || strings.HasPrefix(input, "{"):
+		parsed.Format = CookieFormatJSON
+		var err error
+		if entries, err = parseJSONCookies(input); err != nil {
+			errs.Addf("cookie", "Cookie JSON格式无效: %v", err)
+			return parsed, errs
+		}
+	case strings.Contains(input, "\t"):
+		parsed.Format = CookieFormatDevTools
+		entries = parseDevToolsCookies(input)
+	default:
+		parsed.Format = CookieFormatHeader
+		var invalid []string
+		entries, invalid = parseHeaderCookies(input)
+		for _, part := range invalid {
+			errs.Addf("cookie", "无法解析的内容: %q", part)
+		}
+	}
+
+	seen := make(map[string]bool)
+	expired := make(map[string]bool)
+	var pairs []string
+	for _, entry := range entries {
+		switch {
+		case !validCookieName(entry.name):
+			errs.Addf("cookie", "Cookie名称无效: %q", entry.name)
+			continue
+		case seen[entry.name]:
+			continue
+		case !cookieDomainMatches(entry.domain, host) || isIgnoredCookie(entry.name):
+			parsed.Ignored = append(parsed.Ignored, entry.name)
+			continue
+		case !entry.expires.IsZero() && entry.expires.Before(now):
+			expired[entry.name] = true
+			parsed.Ignored = append(parsed.Ignored, entry.name)
+			continue
+		}
+		seen[entry.name] = true
+		parsed.Names = append(parsed.Names, entry.name)
+		pairs = append(pairs, entry.name+"="+entry.value)
+	}
+	parsed.Cookie = strings.Join(pairs, "; ")
+
+	var missing, expiredRequired []string
+	for _, item := range required {
+		names := strings.Split(item, "|")
+		if slices.ContainsFunc(names, func(name string) bool { return seen[name] }) {
+			continue
+		}
+		if i := slices.IndexFunc(names, func(name string) bool { return expired[name] }); i >= 0 {
+			expiredRequired = append(expiredRequired, names[i])
+			continue
+		}
+		missing = append(missing, strings.Join(names, " | "))
+	}
+	if len(missing) > 0 {
+		errs.Addf("cookie", "缺少必需的Cookie: %s", strings.Join(missing, ", "))
+	}
+	if len(expiredRequired) > 0 {
+		errs.Addf("cookie", "Cookie已过期: %s", strings.Join(expiredRequired, ", "))
+	}
+	if len(errs) == 0 && len(pairs) == 0 {
+		errs.Addf("cookie", "未能从输入中解析出有效的Cookie")
+	}
+	return parsed, errs.Err()
+}
+
+// parseJSONCookies 解析JSON数组或包含cookies数组的对象
+func parseJSONCookies(input string) ([]cookieEntry, error) {
+	var list []jsonCookie
+	if strings.HasPrefix(input, "{") {
+		var wrapper struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal([]byte(input), &wrapper); err != nil {
+			return nil, err
+		}
+		list = wrapper.Cookies
+	} else if err := json.Unmarshal([]byte(input), &list); err != nil {
+		return nil, err
+	}
+
+	entries := make([]cookieEntry, 0, len(list))
+	for _, c := range list {
+		entry := cookieEntry{name: strings.TrimSpace(c.Name), value: strings.TrimSpace(c.Value), domain: c.Domain}
+		// 过期时间为Unix秒，会话Cookie不设置或为-1
+		for _, expires := range []*float64{c.ExpirationDate, c.Expires} {
+			if expires != nil && *expires > 0 {
+				entry.expires = time.Unix(int64(*expires), 0)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseDevToolsCookies 解析开发者工具Cookie表格复制的行：名称、值、域名、路径、过期时间……（制表符分隔）
+func parseDevToolsCookies(input string) []cookieEntry {
+	var entries []cookieEntry
+	for _, line := range strings.Split(input, "\n") {
+		columns := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(columns) < 2 || strings.TrimSpace(columns[0]) == "" {
+			continue
+		}
+		// 连同表头一起复制时跳过表头行
+		if strings.EqualFold(strings.TrimSpace(columns[0]), "name") && strings.EqualFold(strings.TrimSpace(columns[1]), "value") {
+			continue
+		}
+		entry := cookieEntry{name: strings.TrimSpace(columns[0]), value: strings.TrimSpace(columns[1])}
+		if len(columns) > 2 {
+			entry.domain = strings.TrimSpace(columns[2])
+		}
+		if len(columns) > 4 {
+			if expires, err := time.Parse(time.RFC3339, strings.TrimSpace(columns[4])); err == nil {
+				entry.expires = expires
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseHeaderCookies 解析 "Cookie: a=1; b=2" 请求头或分号、换行分隔的 name=value 对，返回无法解析的片段
+func parseHeaderCookies(input string) ([]cookieEntry, []string) {
+	if len(input) >= 7 && strings.EqualFold(input[:7], "cookie:") {
+		input = input[7:]
+	}
+
+	var entries []cookieEntry
+	var invalid []string
+	for _, part := range strings.FieldsFunc(input, func(r rune) bool { return r == ';' || r == '\n' || r == '\r' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if cookieAttributes[strings.ToLower(name)] {
+			continue
+		}
+		if !ok {
+			invalid = append(invalid, part)
+			continue
+		}
+		entries = append(entries, cookieEntry{name: name, value: strings.TrimSpace(value)})
+	}
+	return entries, invalid
+}
+
+// validCookieName 校验Cookie名称（RFC 6265 token：非空，不含空白、分隔符和控制字符）
+func validCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// cookieDomainMatches 判断Cookie域名是否适用于host（未知域名时视为适用）
+func cookieDomainMatches(domain, host string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+	host = strings.ToLower(host)
+	if domain == "" || host == "" {
+		return true
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// isIgnoredCookie 是否为统计分析等与登录无关的Cookie
+func isIgnoredCookie(name string) bool {
+	for _, prefix := range ignoredCookiePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatCookieNames 用于日志的名称列表（不含Cookie值）
+func FormatCookieNames(names []string) string {
+	if len(names) == 0 {
+		return "无"
+	}
+	return fmt.Sprintf("%d 个（%s）", len(names), strings.Join(names, ", "))
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCookieRequiredAlternatives(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	required := []string{"__Secure-next-auth.session-token|next-auth.session-token"}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "带前缀", input: "__Secure-next-auth.session-token=abc; theme=dark"},
+		{name: "不带前缀", input: "next-auth.session-token=abc"},
+		{name: "均缺少", input: "theme=dark", wantErr: "缺少必需的Cookie: __Secure-next-auth.session-token | next-auth.session-token"},
+		{
+			name:    "已过期",
+			input:   `[{"name":"next-auth.session-token","value":"abc","expirationDate":1}]`,
+			wantErr: "Cookie已过期: next-auth.session-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCookie(tt.input, "", required, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("不应返回错误: %v", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("应返回字段验证错误，实际: %v", err)
+			}
+			if !strings.Contains(errs.Error(), tt.wantErr) {
+				t.Errorf("错误信息应包含 %q，实际: %v", tt.wantErr, errs.Error())
+			}
+		})
+	}
+}
@@ -2,14 +2,18 @@ package models
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
 // DailyUsage 每日积分使用统计
 type DailyUsage struct {
-	Date         string         `json:"date"`         // 日期 (YYYY-MM-DD)
-	TotalCredits int            `json:"totalCredits"` // 当日总积分使用量
-	ModelCredits map[string]int `json:"modelCredits"` // 按模型分组的积分使用量
+	Date               string                    `json:"date"`                         // 日期 (YYYY-MM-DD)
+	TotalCredits       int                       `json:"totalCredits"`                 // 当日总积分使用量
+	ModelCredits       map[string]int            `json:"modelCredits"`                 // 按模型分组的积分使用量
+	HourlyModelCredits map[string]map[string]int `json:"hourlyModelCredits,omitempty"` // 按小时(00-23)和模型分组的积分使用量
+	Resets             int                       `json:"resets,omitempty"`             // 当日成功重置积分的次数
+	GroupCredits       map[string]int            `json:"groupCredits,omitempty"`       // 按自定义模型分组汇总的积分使用量（读取时计算，不持久化）
 }
 
 // DailyUsageList 每日使用统计数据列表
@@ -36,16 +40,24 @@ func IsToday(date string) bool {
 	return date == today
 }
 
+// DailyUsageRetentionDays 每日积分统计数据默认保留天数（同时也是最少保留天数）
+const DailyUsageRetentionDays = 7
+
 // GetWeekDates 获取最近一周的日期列表（包括今天）
 func GetWeekDates() []string {
-	dates := make([]string, 7)
+	return GetRecentDates(7)
+}
+
+// GetRecentDates 获取最近指定天数的日期列表（包括今天，按日期升序）
+func GetRecentDates(days int) []string {
+	dates := make([]string, days)
 	now := time.Now().Local()
-	
-	for i := 0; i < 7; i++ {
-		date := now.AddDate(0, 0, -6+i)
+
+	for i := 0; i < days; i++ {
+		date := now.AddDate(0, 0, -(days-1)+i)
 		dates[i] = date.Format("2006-01-02")
 	}
-	
+
 	return dates
 }
 
@@ -152,6 +164,29 @@ func (d *DailyUsage) GetModelCredits(model string) int {
 	return d.ModelCredits[model]
 }
 
+// GetHourlyModelCredits 获取指定小时（00-23）按模型分组的积分使用量
+func (d *DailyUsage) GetHourlyModelCredits(hour string) map[string]int {
+	if d.HourlyModelCredits == nil {
+		return map[string]int{}
+	}
+	return d.HourlyModelCredits[hour]
+}
+
+// TopModels 获取当日积分使用量最多的前n个模型（按积分降序）
+func (d *DailyUsage) TopModels(n int) []string {
+	models := d.GetModelList()
+	sort.Slice(models, func(i, j int) bool {
+		if d.ModelCredits[models[i]] != d.ModelCredits[models[j]] {
+			return d.ModelCredits[models[i]] > d.ModelCredits[models[j]]
+		}
+		return models[i] < models[j]
+	})
+	if len(models) > n {
+		models = models[:n]
+	}
+	return models
+}
+
 // AddModelCredits 累加指定模型的积分使用量
 func (d *DailyUsage) AddModelCredits(model string, credits int) {
 	if d.ModelCredits == nil {
@@ -180,4 +215,16 @@ func (d DailyUsageList) GetAllModelList() []string {
 		models = append(models, model)
 	}
 	return models
-}
\ No newline at end of file
+}
+// WithToday 用今日的实时统计替换列表中同日期的记录（按当前模型别名和分组重新汇总）
+func (d DailyUsageList) WithToday(today DailyUsage) DailyUsageList {
+	result := make(DailyUsageList, len(d))
+	copy(result, d)
+	for i := range result {
+		if result[i].Date == today.Date {
+			result[i] = today
+			return result.NormalizeModels().WithGroups()
+		}
+	}
+	return result
+}
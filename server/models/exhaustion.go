@@ -0,0 +1,29 @@
+package models
+
+import "strings"
+
+// ExhaustionConfig 积分耗尽告警配置
+// 余额从下限以上降至下限时触发，独立于低于阈值告警，便于外部工具（如CI）自动暂停消耗积分的任务
+type ExhaustionConfig struct {
+	Enabled    bool   `json:"enabled"`              // 是否启用
+	Floor      int    `json:"floor"`                // 耗尽下限，余额小于等于该值视为耗尽（默认0）
+	WebhookURL string `json:"webhookUrl,omitempty"` // 耗尽时调用的Webhook地址（可选）
+}
+
+// IsExhausted 判断余额是否已降至耗尽下限
+func (e ExhaustionConfig) IsExhausted(remaining int) bool {
+	return remaining <= e.Floor
+}
+
+// Validate 校验积分耗尽告警配置（去除Webhook地址首尾空白）
+func (e *ExhaustionConfig) Validate() error {
+	var errs ValidationErrors
+	if e.Floor < 0 {
+		errs.Addf("floor", "耗尽下限不能为负数")
+	}
+	e.WebhookURL = strings.TrimSpace(e.WebhookURL)
+	if e.WebhookURL != "" {
+		errs.Add("webhookUrl", validateWebhookURL(e.WebhookURL))
+	}
+	return errs.Err()
+}
@@ -0,0 +1,35 @@
+package models
+
+import "strings"
+
+// DefaultExternalUsageThreshold 默认告警阈值：实际余额比预期余额低出的积分
+const DefaultExternalUsageThreshold = 100
+
+// ExternalUsageConfig 外部使用告警配置
+// 实际余额比按积分恢复模型和本机使用记录推算的预期余额明显偏低时触发，提示Cookie或账户可能在其他地方被使用
+type ExternalUsageConfig struct {
+	Enabled    bool   `json:"enabled"`              // 是否启用
+	Threshold  int    `json:"threshold"`            // 告警阈值，两次读数之间无法解释的余额下降达到该值时告警，0表示默认值
+	WebhookURL string `json:"webhookUrl,omitempty"` // 告警时调用的Webhook地址（可选）
+}
+
+// GetThreshold 获取告警阈值（未设置时为默认值）
+func (e ExternalUsageConfig) GetThreshold() int {
+	if e.Threshold <= 0 {
+		return DefaultExternalUsageThreshold
+	}
+	return e.Threshold
+}
+
+// Validate 校验外部使用告警配置（去除Webhook地址首尾空白）
+func (e *ExternalUsageConfig) Validate() error {
+	var errs ValidationErrors
+	if e.Threshold < 0 {
+		errs.Addf("threshold", "告警阈值不能为负数")
+	}
+	e.WebhookURL = strings.TrimSpace(e.WebhookURL)
+	if e.WebhookURL != "" {
+		errs.Add("webhookUrl", validateWebhookURL(e.WebhookURL))
+	}
+	return errs.Err()
+}
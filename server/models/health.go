@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 健康状态
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// ComponentHealth 单个组件的健康状态
+type ComponentHealth struct {
+	Status string `json:"status"`           // up / down
+	Detail string `json:"detail,omitempty"` // 状态说明或错误信息
+}
+
+// HealthReport 健康检查报告
+type HealthReport struct {
+	Status     string                     `json:"status"`               // 整体状态，任一组件down时为down
+	Version    string                     `json:"version"`              // 版本号
+	Uptime     string                     `json:"uptime"`               // 运行时长
+	Components map[string]ComponentHealth `json:"components,omitempty"` // 各组件状态
+	Timestamp  time.Time                  `json:"timestamp"`            // 检查时间
+}
+
+// 系统整体健康状态
+const (
+	HealthStateOK       = "ok"
+	HealthStateDegraded = "degraded"
+	HealthStateError    = "error"
+)
+
+// HealthState 系统整体健康状态（由健康监督服务汇总上游、Cookie、数据库和任务状态）
+type HealthState struct {
+	State     string    `json:"state"`     // ok / degraded / error
+	Reasons   []string  `json:"reasons"`   // 非ok状态的原因
+	Since     time.Time `json:"since"`     // 进入当前状态的时间
+	UpdatedAt time.Time `json:"updatedAt"` // 最后评估时间
+}
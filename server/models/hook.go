@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 可触发Hook的事件
+const (
+	HookEventBalanceLow    = "balance_low"       // 积分余额跌破自动重置阈值
+	HookEventResetExecuted = "reset_executed"    // 积分重置成功
+	HookEventCookieInvalid = "cookie_invalid"    // 上游返回Cookie无效或已过期
+	HookEventExhausted     = "balance_exhausted" // 积分余额降至耗尽下限
+	HookEventExternalUsage = "external_usage"    // 余额下降无法由本机使用解释（可能在其他地方被使用）
+	HookEventLoginNewIP    = "login_new_ip"      // 从未登录过的IP登录成功
+)
+
+// Hook数量和超时限制
+const (
+	MaxHooks              = 20
+	DefaultHookTimeoutSec = 30
+	MaxHookTimeoutSec     = 300
+)
+
+// HookConfig 事件Hook配置：事件发生时执行Hook目录中的程序，事件内容以JSON写入标准输入
+type HookConfig struct {
+	Event   string   `json:"event"`          // 触发事件
+	Path    string   `json:"path"`           // 程序路径（相对Hook目录，或位于Hook目录内的绝对路径）
+	Args    []string `json:"args,omitempty"` // 命令行参数
+	Timeout int      `json:"timeout"`        // 超时时间(秒)，0表示默认值
+}
+
+// HookPayload 写入Hook标准输入的事件内容
+type HookPayload struct {
+	Event     string      `json:"event"`          // 事件类型
+	Timestamp time.Time   `json:"timestamp"`      // 事件时间
+	Data      interface{} `json:"data,omitempty"` // 事件数据
+}
+
+// GetTimeout 获取Hook超时时间
+func (h HookConfig) GetTimeout() time.Duration {
+	if h.Timeout <= 0 {
+		return DefaultHookTimeoutSec * time.Second
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// isHookEvent 判断是否为支持的Hook事件
+func isHookEvent(event string) bool {
+	switch event {
+	case HookEventBalanceLow, HookEventResetExecuted, HookEventCookieInvalid, HookEventExhausted, HookEventExternalUsage, HookEventLoginNewIP:
+		return true
+	}
+	return false
+}
+
+// ValidateHooks 校验并清理Hook配置（去除首尾空白）
+func ValidateHooks(hooks []HookConfig) ([]HookConfig, error) {
+	if len(hooks) > MaxHooks {
+		return nil, fmt.Errorf("Hook最多%d个", MaxHooks)
+	}
+
+	var errs ValidationErrors
+	cleaned := make([]HookConfig, 0, len(hooks))
+	for i, hook := range hooks {
+		hook.Event = strings.TrimSpace(hook.Event)
+		hook.Path = strings.TrimSpace(hook.Path)
+		if !isHookEvent(hook.Event) {
+			errs.Addf(fmt.Sprintf("[%d].event", i), "不支持的Hook事件: %s", hook.Event)
+		}
+		if hook.Path == "" {
+			errs.Addf(fmt.Sprintf("[%d].path", i), "Hook %s 的程序路径不能为空", hook.Event)
+		}
+		if hook.Timeout < 0 || hook.Timeout > MaxHookTimeoutSec {
+			errs.Addf(fmt.Sprintf("[%d].timeout", i), "Hook超时时间须在0-%d秒之间", MaxHookTimeoutSec)
+		}
+		cleaned = append(cleaned, hook)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cleaned, nil
+}
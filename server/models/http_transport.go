@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 上游HTTP连接池默认值和取值上限
+const (
+	DefaultMaxIdleConns    = 2    // 默认保留的空闲连接数（使用数据和积分余额请求并发时各复用一条）
+	DefaultIdleConnTimeout = 90   // 默认空闲连接保留时长（秒），大于常用的获取间隔以复用连接、减少TLS握手
+	MaxIdleConnsLimit      = 100  // 空闲连接数上限
+	MaxIdleConnTimeout     = 3600 // 空闲连接保留时长上限（秒）
+)
+
+// HTTPTransportConfig 上游请求的HTTP连接池配置（所有上游请求共享同一个连接池）
+type HTTPTransportConfig struct {
+	MaxIdleConns      int  `json:"maxIdleConns"`      // 保留的空闲连接数，0表示默认值
+	IdleConnTimeout   int  `json:"idleConnTimeout"`   // 空闲连接保留时长（秒），0表示默认值
+	DisableKeepAlives bool `json:"disableKeepAlives"` // 禁用连接复用，每次请求新建连接
+	DisableHTTP2      bool `json:"disableHTTP2"`      // 禁用HTTP/2，只使用HTTP/1.1（默认与上游协商HTTP/2）
+
+	// 上游主机名解析（部分网络环境DNS被污染时使用，无需修改系统hosts）
+	Hosts     map[string][]string `json:"hosts,omitempty"` // 指定主机名对应的IP（按顺序尝试），优先于DNS解析
+	DNSServer string              `json:"dnsServer"`       // 自定义DNS服务器："IP[:端口]"（UDP）或 "https://..."（DoH JSON接口），为空时使用系统解析
+}
+
+// LookupHosts 获取主机名指定的IP（主机名不区分大小写）
+func (t HTTPTransportConfig) LookupHosts(host string) []string {
+	for name, ips := range t.Hosts {
+		if strings.EqualFold(name, host) {
+			return ips
+		}
+	}
+	return nil
+}
+
+// IsDoH 自定义DNS服务器是否为DoH地址
+func (t HTTPTransportConfig) IsDoH() bool {
+	return strings.HasPrefix(t.DNSServer, "https://")
+}
+
+// GetDNSServerAddr 获取UDP DNS服务器地址（未指定端口时为53）
+func (t HTTPTransportConfig) GetDNSServerAddr() string {
+	if _, _, err := net.SplitHostPort(t.DNSServer); err == nil {
+		return t.DNSServer
+	}
+	return net.JoinHostPort(strings.Trim(t.DNSServer, "[]"), "53")
+}
+
+// GetMaxIdleConns 获取空闲连接数（未设置时为默认值）
+func (t HTTPTransportConfig) GetMaxIdleConns() int {
+	if t.MaxIdleConns <= 0 {
+		return DefaultMaxIdleConns
+	}
+	return t.MaxIdleConns
+}
+
+// GetIdleConnTimeout 获取空闲连接保留时长（未设置时为默认值）
+func (t HTTPTransportConfig) GetIdleConnTimeout() time.Duration {
+	if t.IdleConnTimeout <= 0 {
+		return DefaultIdleConnTimeout * time.Second
+	}
+	return time.Duration(t.IdleConnTimeout) * time.Second
+}
+
+// Validate 校验HTTP连接池配置
+func (t HTTPTransportConfig) Validate() error {
+	var errs ValidationErrors
+	if t.MaxIdleConns < 0 || t.MaxIdleConns > MaxIdleConnsLimit {
+		errs.Addf("maxIdleConns", "取值范围为0-%d", MaxIdleConnsLimit)
+	}
+	if t.IdleConnTimeout < 0 || t.IdleConnTimeout > MaxIdleConnTimeout {
+		errs.Addf("idleConnTimeout", "取值范围为0-%d秒", MaxIdleConnTimeout)
+	}
+	for host, ips := range t.Hosts {
+		if strings.TrimSpace(host) == "" || len(ips) == 0 {
+			errs.Addf("hosts", "主机名和IP列表不能为空")
+			continue
+		}
+		for _, ip := range ips {
+			if net.ParseIP(ip) == nil {
+				errs.Addf("hosts", "%s 的IP无效: %q", host, ip)
+			}
+		}
+	}
+	if err := validateDNSServer(t); err != nil {
+		errs.Addf("dnsServer", "%v", err)
+	}
+	return errs.Err()
+}
+
+// validateDNSServer 校验自定义DNS服务器地址
+func validateDNSServer(t HTTPTransportConfig) error {
+	switch {
+	case t.DNSServer == "":
+		return nil
+	case t.IsDoH():
+		if u, err := url.Parse(t.DNSServer); err != nil || u.Host == "" {
+			return fmt.Errorf("DoH地址无效: %s", t.DNSServer)
+		}
+		return nil
+	}
+	host, port, err := net.SplitHostPort(t.GetDNSServerAddr())
+	if err != nil || net.ParseIP(host) == nil || port == "" {
+		return fmt.Errorf("应为IP[:端口]或https://开头的DoH地址: %s", t.DNSServer)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// 定时任务名称
+const (
+	JobNameUsage   = "usage"   // 使用数据获取任务
+	JobNameBalance = "balance" // 积分余额获取任务
+)
+
+// JobTick 定时任务执行事件（SSE tick 事件），用于显示距下次刷新的倒计时
+type JobTick struct {
+	Job      string     `json:"job"`               // 任务名称
+	RanAt    time.Time  `json:"ranAt"`             // 本次执行完成时间
+	NextRun  *time.Time `json:"nextRun,omitempty"` // 下次计划执行时间（任务未调度时为空）
+	Interval int        `json:"interval"`          // 执行间隔(秒)
+	Error    string     `json:"error,omitempty"`   // 本次执行失败的原因
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 登录审计记录数量限制
+const (
+	MaxLoginAuditEntries    = 1000 // 最多保留的登录记录数，超出时删除最早的记录
+	DefaultLoginAuditLimit  = 50   // 查询默认返回的记录数
+	MaxLoginAuditQueryLimit = 1000 // 单次查询最多返回的记录数
+	MaxKnownLoginIPs        = 1000 // 最多记录的已登录IP数，超出时删除首次登录最早的IP
+)
+
+// 登录失败原因
+const (
+	LoginFailureMissingKey = "missing_key" // 缺少Authorization头
+	LoginFailureEmptyKey   = "empty_key"   // 访问密钥为空
+	LoginFailureInvalidKey = "invalid_key" // 访问密钥错误
+	LoginFailureSession    = "session"     // 密钥正确但创建会话失败
+	LoginFailureAPIKey     = "api_key"     // 接口调用携带的Bearer访问密钥错误
+	LoginFailureBasicAuth  = "basic_auth"  // 接口调用的HTTP Basic认证失败
+	LoginFailureGRPC       = "grpc"        // gRPC调用携带的访问密钥错误
+)
+
+// LoginAuditEntry 一次登录尝试的审计记录
+type LoginAuditEntry struct {
+	Time      time.Time `json:"time"`             // 登录时间
+	Success   bool      `json:"success"`          // 是否登录成功
+	Reason    string    `json:"reason,omitempty"` // 失败原因
+	IP        string    `json:"ip"`               // 客户端IP
+	UserAgent string    `json:"userAgent"`        // 客户端User-Agent
+	NewIP     bool      `json:"newIp,omitempty"`  // 是否为新IP（该IP首次登录成功，且之前已有其他IP登录成功过）
+}
+
+// LoginAlertConfig 新IP登录告警：从未成功登录过的IP登录成功时推送通知、触发Hook并调用Webhook
+// 登录记录始终保存，不受该配置影响；首次启用前没有已知IP时，第一次登录的IP只记录不告警
+type LoginAlertConfig struct {
+	Enabled    bool   `json:"enabled"`              // 是否启用
+	WebhookURL string `json:"webhookUrl,omitempty"` // 告警时调用的Webhook地址（可选）
+}
+
+// Validate 校验新IP登录告警配置（去除Webhook地址首尾空白）
+func (l *LoginAlertConfig) Validate() error {
+	var errs ValidationErrors
+	l.WebhookURL = strings.TrimSpace(l.WebhookURL)
+	if l.WebhookURL != "" {
+		errs.Add("webhookUrl", validateWebhookURL(l.WebhookURL))
+	}
+	return errs.Err()
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// 维护模式时长限制（分钟）
+const (
+	DefaultMaintenanceMinutes = 60
+	MaxMaintenanceMinutes     = 7 * 24 * 60
+)
+
+// MaintenanceStatus 维护模式状态
+type MaintenanceStatus struct {
+	Enabled   bool       `json:"enabled"`             // 是否处于维护模式
+	Reason    string     `json:"reason,omitempty"`    // 维护原因
+	StartedAt *time.Time `json:"startedAt,omitempty"` // 开始时间
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // 自动结束时间
+}
+
+// MaintenanceRequest 维护模式切换请求
+type MaintenanceRequest struct {
+	Enabled         bool   `json:"enabled"`         // 开启或关闭维护模式
+	DurationMinutes int    `json:"durationMinutes"` // 自动结束时长（分钟），默认60
+	Reason          string `json:"reason"`          // 维护原因
+}
+
+// Validate 验证维护模式请求，时长超出范围时使用默认值
+func (r *MaintenanceRequest) Validate() {
+	if r.DurationMinutes <= 0 || r.DurationMinutes > MaxMaintenanceMinutes {
+		r.DurationMinutes = DefaultMaintenanceMinutes
+	}
+}
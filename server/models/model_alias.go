@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MaxModelAliases 模型别名映射的最大条目数
+const MaxModelAliases = 100
+
+// modelAliasWildcard 别名键以此结尾时按前缀匹配（如 "claude-3-5-sonnet-*"）
+const modelAliasWildcard = "*"
+
+// activeModelAliases 当前生效的模型别名映射（原始模型名 → 统一名称）
+var (
+	activeModelAliases map[string]string
+	modelAliasMu       sync.RWMutex
+)
+
+// SetModelAliases 设置当前生效的模型别名映射
+func SetModelAliases(aliases map[string]string) {
+	copied := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		copied[from] = to
+	}
+
+	modelAliasMu.Lock()
+	defer modelAliasMu.Unlock()
+	activeModelAliases = copied
+}
+
+// NormalizeModel 规范化模型名称：去除首尾空白后按别名映射转换
+// 精确匹配优先；其次匹配最长的通配前缀；均未命中时返回原名称
+func NormalizeModel(model string) string {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return model
+	}
+
+	modelAliasMu.RLock()
+	defer modelAliasMu.RUnlock()
+
+	if alias, ok := activeModelAliases[model]; ok {
+		return alias
+	}
+
+	matched, alias := 0, ""
+	for from, to := range activeModelAliases {
+		prefix, ok := strings.CutSuffix(from, modelAliasWildcard)
+		if ok && prefix != "" && strings.HasPrefix(model, prefix) && len(prefix) > matched {
+			matched, alias = len(prefix), to
+		}
+	}
+	if alias != "" {
+		return alias
+	}
+	return model
+}
+
+// matchModelPattern 判断模型名是否匹配模式（精确匹配，或以*结尾时按前缀匹配）
+func matchModelPattern(pattern, model string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, modelAliasWildcard); ok {
+		return prefix != "" && strings.HasPrefix(model, prefix)
+	}
+	return pattern == model
+}
+
+// ValidateModelAliases 校验并清理模型别名映射（去除首尾空白，忽略映射到自身的条目）
+func ValidateModelAliases(aliases map[string]string) (map[string]string, error) {
+	if len(aliases) > MaxModelAliases {
+		return nil, fmt.Errorf("模型别名最多%d条", MaxModelAliases)
+	}
+
+	cleaned := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" || from == modelAliasWildcard || to == "" {
+			return nil, fmt.Errorf("模型别名的原名称和统一名称均不能为空")
+		}
+		if from == to {
+			continue
+		}
+		cleaned[from] = to
+	}
+	return cleaned, nil
+}
+
+// NormalizeModels 按当前别名映射合并每日统计中的模型积分（用于别名生效前保存的历史数据）
+func (d DailyUsageList) NormalizeModels() DailyUsageList {
+	result := make(DailyUsageList, len(d))
+	for i, usage := range d {
+		result[i] = usage
+		if len(usage.ModelCredits) == 0 {
+			continue
+		}
+		result[i].ModelCredits = normalizeModelCredits(usage.ModelCredits)
+
+		if len(usage.HourlyModelCredits) > 0 {
+			hourly := make(map[string]map[string]int, len(usage.HourlyModelCredits))
+			for hour, modelCredits := range usage.HourlyModelCredits {
+				hourly[hour] = normalizeModelCredits(modelCredits)
+			}
+			result[i].HourlyModelCredits = hourly
+		}
+	}
+	return result
+}
+
+// normalizeModelCredits 按当前别名映射合并模型积分
+func normalizeModelCredits(modelCredits map[string]int) map[string]int {
+	merged := make(map[string]int, len(modelCredits))
+	for model, credits := range modelCredits {
+		merged[NormalizeModel(model)] += credits
+	}
+	return merged
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MaxModelGroups 模型分组的最大数量
+const MaxModelGroups = 20
+
+// ModelGroup 模型分组，将多个模型名称归入同一统计分组（如 Opus、Sonnet、Codex）
+type ModelGroup struct {
+	Name   string   `json:"name"`   // 分组名称
+	Models []string `json:"models"` // 归入该分组的模型名，以*结尾时按前缀匹配
+}
+
+// activeModelGroups 当前生效的模型分组（按顺序匹配，先命中者优先）
+var (
+	activeModelGroups []ModelGroup
+	modelGroupMu      sync.RWMutex
+)
+
+// SetModelGroups 设置当前生效的模型分组
+func SetModelGroups(groups []ModelGroup) {
+	copied := make([]ModelGroup, len(groups))
+	for i, group := range groups {
+		copied[i] = ModelGroup{Name: group.Name, Models: append([]string(nil), group.Models...)}
+	}
+
+	modelGroupMu.Lock()
+	defer modelGroupMu.Unlock()
+	activeModelGroups = copied
+}
+
+// ModelGroupOf 获取模型所属分组名称（按别名规范化后的名称匹配），未归入任何分组时返回空字符串
+func ModelGroupOf(model string) string {
+	if model == "" {
+		return ""
+	}
+
+	modelGroupMu.RLock()
+	defer modelGroupMu.RUnlock()
+
+	for _, group := range activeModelGroups {
+		for _, pattern := range group.Models {
+			if matchModelPattern(pattern, model) {
+				return group.Name
+			}
+		}
+	}
+	return ""
+}
+
+// ValidateModelGroups 校验并清理模型分组（去除首尾空白和空模型名）
+func ValidateModelGroups(groups []ModelGroup) ([]ModelGroup, error) {
+	if len(groups) > MaxModelGroups {
+		return nil, fmt.Errorf("模型分组最多%d个", MaxModelGroups)
+	}
+
+	var errs ValidationErrors
+	cleaned := make([]ModelGroup, 0, len(groups))
+	names := make(map[string]bool, len(groups))
+	for i, group := range groups {
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			errs.Addf(fmt.Sprintf("[%d].name", i), "模型分组名称不能为空")
+		} else if names[name] {
+			errs.Addf(fmt.Sprintf("[%d].name", i), "模型分组名称重复: %s", name)
+		}
+		names[name] = true
+
+		var patterns []string
+		for _, pattern := range group.Models {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+		if len(patterns) == 0 {
+			errs.Addf(fmt.Sprintf("[%d].models", i), "模型分组 %s 至少需要包含一个模型", name)
+		}
+		cleaned = append(cleaned, ModelGroup{Name: name, Models: patterns})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return cleaned, nil
+}
+
+// WithGroups 为使用数据标注所属模型分组
+func (u UsageDataList) WithGroups() UsageDataList {
+	result := make(UsageDataList, len(u))
+	for i, data := range u {
+		result[i] = data
+		result[i].Group = ModelGroupOf(data.Model)
+	}
+	return result
+}
+
+// WithGroups 按当前模型分组汇总每日统计的分组积分
+func (d DailyUsageList) WithGroups() DailyUsageList {
+	result := make(DailyUsageList, len(d))
+	for i, usage := range d {
+		result[i] = usage
+		result[i].GroupCredits = nil
+		for model, credits := range usage.ModelCredits {
+			if group := ModelGroupOf(model); group != "" {
+				if result[i].GroupCredits == nil {
+					result[i].GroupCredits = make(map[string]int)
+				}
+				result[i].GroupCredits[group] += credits
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// 通知类型
+const (
+	NotificationTypeUpdate           = "update_available"   // 有新版本可用
+	NotificationTypeMaintenance      = "maintenance"        // 维护模式开启/结束
+	NotificationTypeUpstreamSlow     = "upstream_slow"      // 上游响应缓慢
+	NotificationTypeThreshold        = "threshold"          // 积分低于阈值
+	NotificationTypeRelayErrors      = "relay_errors"       // 中转站限流或故障比例过高
+	NotificationTypeExhausted        = "exhausted"          // 积分耗尽
+	NotificationTypeResetTimeAdopted = "reset_time_adopted" // 自动采用建议的重置时间
+	NotificationTypeExternalUsage    = "external_usage"     // 余额下降无法由本机使用解释
+	NotificationTypeSchemaDrift      = "schema_drift"       // 上游响应结构变化
+	NotificationTypeLoginNewIP       = "login_new_ip"       // 从未登录过的IP登录成功
+)
+
+// Notification 推送给前端的通知消息
+type Notification struct {
+	Type      string    `json:"type"`          // 通知类型
+	Title     string    `json:"title"`         // 标题
+	Message   string    `json:"message"`       // 内容
+	URL       string    `json:"url,omitempty"` // 相关链接
+	Timestamp time.Time `json:"timestamp"`     // 通知时间
+}
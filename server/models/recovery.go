@@ -0,0 +1,150 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// minRecoverySamples 估算恢复速度所需的最少读数对
+const minRecoverySamples = 3
+
+// 恢复速度来源
+const (
+	RecoverySourceConfig  = "config"  // 自动重置配置中的recoveryPerHour
+	RecoverySourceHistory = "history" // 根据积分余额历史估算
+	RecoverySourceNone    = "none"    // 无法估算，按不恢复计算
+)
+
+// RecoveryModel 积分被动恢复模型：每小时恢复固定积分，不超过积分上限；重置后余额回到上限
+type RecoveryModel struct {
+	PerHour  int    `json:"recoveryPerHour"` // 每小时恢复的积分
+	Capacity int    `json:"capacity"`        // 积分上限
+	Source   string `json:"recoverySource"`  // 恢复速度来源
+}
+
+// NewRecoveryModel 根据配置和按时间升序的积分余额历史确定恢复模型
+// perHour、capacity为配置值，为0时分别根据历史估算和取历史最大值
+func NewRecoveryModel(perHour, capacity int, history []CreditBalance) RecoveryModel {
+	model := RecoveryModel{PerHour: perHour, Capacity: capacity, Source: RecoverySourceConfig}
+	if model.Capacity == 0 {
+		model.Capacity = BalanceCapacity(history)
+	}
+	if model.PerHour == 0 {
+		model.Source = RecoverySourceHistory
+		if estimated, ok := EstimateRecoveryPerHour(history, model.Capacity); ok {
+			model.PerHour = estimated
+		} else {
+			model.Source = RecoverySourceNone
+		}
+	}
+	return model
+}
+
+// BalanceCapacity 积分上限：取积分余额历史中的最大值（每次重置都会回到上限）
+func BalanceCapacity(history []CreditBalance) int {
+	capacity := 0
+	for _, balance := range history {
+		capacity = max(capacity, balance.Remaining)
+	}
+	return capacity
+}
+
+// EstimateRecoveryPerHour 根据按时间升序的积分余额历史估算每小时恢复的积分
+// 只使用余额增加且未达上限的相邻读数（排除重置造成的跳变），读数间隔内的使用会抵消部分恢复，因此取较高的分位数
+func EstimateRecoveryPerHour(history []CreditBalance, capacity int) (int, bool) {
+	var rates []float64
+	for i := 1; i < len(history); i++ {
+		previous, current := history[i-1], history[i]
+		elapsed := current.UpdatedAt.Sub(previous.UpdatedAt)
+		gained := current.Remaining - previous.Remaining
+		if elapsed < time.Minute || elapsed > 3*time.Hour || gained <= 0 {
+			continue
+		}
+		if current.Remaining >= capacity || gained*2 > capacity {
+			continue
+		}
+		rates = append(rates, float64(gained)/elapsed.Hours())
+	}
+	if len(rates) < minRecoverySamples {
+		return 0, false
+	}
+	sort.Float64s(rates)
+	return int(math.Round(rates[(len(rates)-1)*9/10])), true
+}
+
+// BalancePoint 某次余额读数时的实际余额与预期余额
+type BalancePoint struct {
+	Time       time.Time `json:"time"`       // 读数时间
+	Actual     int       `json:"actual"`     // 实际余额
+	Expected   int       `json:"expected"`   // 按恢复模型和本机记录的使用量推算的预期余额
+	Divergence int       `json:"divergence"` // 实际余额减预期余额，明显为负说明有未记录的使用
+}
+
+// BalanceCurve 实际余额与预期余额曲线
+type BalanceCurve struct {
+	RecoveryModel
+	Points       []BalancePoint `json:"points"`       // 各次读数（按时间升序）
+	MaxShortfall int            `json:"maxShortfall"` // 实际余额低于预期余额的最大差值
+}
+
+// NewBalanceCurve 从第一条读数出发，按时间顺序推算每次读数时的预期余额
+// 两次事件之间按恢复速度恢复（不超过上限），重置时回到上限，每条使用记录扣除其积分（不低于0）
+// history需按时间升序；usage、resets按时间排序后使用，早于第一条读数的记录忽略
+func NewBalanceCurve(history []CreditBalance, usage UsageDataList, resets []ResetRecord, model RecoveryModel) BalanceCurve {
+	curve := BalanceCurve{RecoveryModel: model, Points: make([]BalancePoint, 0, len(history))}
+	if len(history) == 0 {
+		return curve
+	}
+
+	usage = append(UsageDataList(nil), usage...)
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].CreatedAt.Before(usage[j].CreatedAt)
+	})
+	resets = append([]ResetRecord(nil), resets...)
+	sort.Slice(resets, func(i, j int) bool {
+		return resets[i].Time.Before(resets[j].Time)
+	})
+
+	capacity := float64(model.Capacity)
+	expected := float64(history[0].Remaining)
+	last := history[0].UpdatedAt
+	advance := func(t time.Time) {
+		if t.After(last) {
+			expected = math.Min(capacity, expected+float64(model.PerHour)*t.Sub(last).Hours())
+			last = t
+		}
+	}
+
+	u, r := 0, 0
+	for i, balance := range history {
+		if i > 0 {
+			for {
+				nextUsage := u < len(usage) && !usage[u].CreatedAt.After(balance.UpdatedAt)
+				nextReset := r < len(resets) && !resets[r].Time.After(balance.UpdatedAt)
+				if nextReset && (!nextUsage || resets[r].Time.Before(usage[u].CreatedAt)) {
+					if resets[r].Time.After(history[0].UpdatedAt) {
+						advance(resets[r].Time)
+						expected = capacity
+					}
+					r++
+				} else if nextUsage {
+					if usage[u].CreatedAt.After(history[0].UpdatedAt) {
+						advance(usage[u].CreatedAt)
+						expected = math.Max(0, expected-float64(usage[u].CreditsUsed))
+					}
+					u++
+				} else {
+					break
+				}
+			}
+			advance(balance.UpdatedAt)
+		}
+
+		point := BalancePoint{Time: balance.UpdatedAt, Actual: balance.Remaining, Expected: int(math.Round(expected))}
+		point.Divergence = point.Actual - point.Expected
+		curve.MaxShortfall = max(curve.MaxShortfall, -point.Divergence)
+		curve.Points = append(curve.Points, point)
+	}
+	return curve
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RequestLogEntry 请求日志条目
+type RequestLogEntry struct {
+	Time       time.Time `json:"time"`              // 请求开始时间
+	Method     string    `json:"method"`            // 请求方法
+	Path       string    `json:"path"`              // 请求路径（不含查询参数）
+	Status     int       `json:"status"`            // 响应状态码
+	DurationMs float64   `json:"durationMs"`        // 处理耗时（毫秒）
+	Auth       string    `json:"auth"`              // 认证方式：session / key / proxy / none
+	Session    string    `json:"session,omitempty"` // 会话ID前缀（仅用于区分调用方）
+	IP         string    `json:"ip"`                // 客户端IP
+}
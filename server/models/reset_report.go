@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+)
+
+// 重置效果报告参数
+const (
+	DefaultResetReportDays = 14        // 默认统计的最近天数
+	resetBalanceWindow     = time.Hour // 重置前后的余额读数距重置时间不超过该时长才计算获得的积分
+)
+
+// ResetRecord 一次成功的积分重置
+type ResetRecord struct {
+	Time time.Time `json:"time"`           // 重置时间
+	Info string    `json:"info,omitempty"` // 上游返回的重置信息
+}
+
+// ResetEffect 单次重置的效果
+type ResetEffect struct {
+	Time            time.Time      `json:"time"`                    // 重置时间
+	BalanceBefore   *CreditBalance `json:"balanceBefore,omitempty"` // 重置前最近的余额读数
+	BalanceAfter    *CreditBalance `json:"balanceAfter,omitempty"`  // 重置后首次的余额读数
+	CreditsGained   *int           `json:"creditsGained,omitempty"` // 获得的积分（重置后余额减重置前余额），前后一小时内没有读数时为空
+	CreditsConsumed int            `json:"creditsConsumed"`         // 重置后至当日结束实际使用的积分
+	Approximate     bool           `json:"approximate"`             // 重置所在小时没有原始使用记录，该小时的使用量按整小时计入
+	Partial         bool           `json:"partial"`                 // 重置发生在今日，使用量只统计到当前
+}
+
+// ResetReport 重置效果报告（按重置时间倒序）
+type ResetReport struct {
+	Days            int           `json:"days"`            // 统计的天数
+	Resets          []ResetEffect `json:"resets"`          // 各次重置的效果
+	CreditsGained   int           `json:"creditsGained"`   // 获得的积分合计（只计有余额读数的重置）
+	CreditsConsumed int           `json:"creditsConsumed"` // 重置后使用的积分合计
+}
+
+// NewResetEffect 计算单次重置的效果
+// usage为重置当日的每日统计（可为nil），records为内存中的原始使用记录，用于精确统计重置所在小时内重置之后的使用量
+func NewResetEffect(record ResetRecord, before, after *CreditBalance, usage *DailyUsage, records UsageDataList, now time.Time) ResetEffect {
+	resetAt := record.Time.Local()
+	date := GetLocalDate(resetAt)
+	effect := ResetEffect{
+		Time:          record.Time,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+		Partial:       date == GetLocalDate(now),
+	}
+
+	if before != nil && after != nil &&
+		resetAt.Sub(before.UpdatedAt) <= resetBalanceWindow && after.UpdatedAt.Sub(resetAt) <= resetBalanceWindow {
+		gained := after.Remaining - before.Remaining
+		effect.CreditsGained = &gained
+	}
+
+	// 按小时统计写入前的部分由原始记录补充，两者取较大值（与同星期几对比一致）
+	var hourly [24]int
+	if usage != nil {
+		hourly = usage.hourlyCredits()
+	}
+	var recordHourly [24]int
+	resetHourRecords, sinceReset := 0, 0
+	for _, r := range records {
+		createdAt := r.CreatedAt.Local()
+		if GetLocalDate(createdAt) != date {
+			continue
+		}
+		recordHourly[createdAt.Hour()] += r.CreditsUsed
+		if createdAt.Hour() == resetAt.Hour() {
+			resetHourRecords++
+			if !createdAt.Before(resetAt) {
+				sinceReset += r.CreditsUsed
+			}
+		}
+	}
+
+	if resetHourRecords > 0 {
+		effect.CreditsConsumed = sinceReset
+	} else {
+		effect.CreditsConsumed = hourly[resetAt.Hour()]
+		effect.Approximate = effect.CreditsConsumed > 0
+	}
+	for hour := resetAt.Hour() + 1; hour < 24; hour++ {
+		effect.CreditsConsumed += max(hourly[hour], recordHourly[hour])
+	}
+	return effect
+}
+
+// NewResetReport 汇总各次重置的效果
+func NewResetReport(days int, effects []ResetEffect) ResetReport {
+	report := ResetReport{Days: days, Resets: effects}
+	for _, effect := range effects {
+		if effect.CreditsGained != nil {
+			report.CreditsGained += *effect.CreditsGained
+		}
+		report.CreditsConsumed += effect.CreditsConsumed
+	}
+	return report
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ResetSuggestionDays 参与计算建议重置时间的最近天数（受每日统计保留天数限制）
+const ResetSuggestionDays = 14
+
+// ResetCandidate 每天在某一时间重置时的模拟结果
+type ResetCandidate struct {
+	Time      string `json:"time"`      // 重置时间 "HH:MM"
+	Usable    int    `json:"usable"`    // 预计每日可满足的积分使用量
+	ResetGain int    `json:"resetGain"` // 重置时补充的积分（重置前余额与上限之差）
+}
+
+// ResetSuggestion 建议的每日重置时间
+type ResetSuggestion struct {
+	RecoveryModel
+	Suggested  ResetCandidate   `json:"suggested"`         // 建议的重置时间及其模拟结果
+	Current    *ResetCandidate  `json:"current,omitempty"` // 当前配置的定时重置时间的模拟结果（按所在整点计算）
+	Demand     int              `json:"demand"`            // 平均每日积分使用量
+	Days       int              `json:"days"`              // 参与计算的天数
+	Candidates []ResetCandidate `json:"candidates"`        // 各整点重置的模拟结果（00:00-23:00）
+}
+
+// AverageHourlyCredits 计算有按小时统计的日期中各小时的平均积分使用量，跳过exclude日期（今日数据不完整），返回参与计算的天数
+func (d DailyUsageList) AverageHourlyCredits(exclude string) ([24]float64, int) {
+	var average [24]float64
+	days := 0
+	for i := range d {
+		if d[i].Date == exclude || len(d[i].HourlyModelCredits) == 0 {
+			continue
+		}
+		hourly := d[i].hourlyCredits()
+		for hour, credits := range hourly {
+			average[hour] += float64(credits)
+		}
+		days++
+	}
+	if days > 0 {
+		for hour := range average {
+			average[hour] /= float64(days)
+		}
+	}
+	return average, days
+}
+
+// simulateReset 模拟每天在resetHour点重置时一个周期（24小时）内可满足的使用量，以及下次重置时补充的积分
+// 重置后余额回到上限，之后每小时先恢复（不超过上限）再扣除该小时的平均使用量，余额不足时只能满足部分使用
+func simulateReset(hourly [24]float64, capacity, recovery float64, resetHour int) (usable, gain float64) {
+	balance := capacity
+	for i := 0; i < 24; i++ {
+		balance = math.Min(capacity, balance+recovery)
+		used := math.Min(balance, hourly[(resetHour+i)%24])
+		balance -= used
+		usable += used
+	}
+	return usable, capacity - balance
+}
+
+// SuggestResetTime 模拟每个整点重置，选出可满足使用量最多的时间；相同时选重置补充积分最多（恢复浪费最少）的时间
+func SuggestResetTime(hourly [24]float64, days int, model RecoveryModel) ResetSuggestion {
+	suggestion := ResetSuggestion{
+		RecoveryModel: model,
+		Days:          days,
+		Candidates:    make([]ResetCandidate, 24),
+	}
+
+	var demand float64
+	for _, credits := range hourly {
+		demand += credits
+	}
+	suggestion.Demand = int(math.Round(demand))
+
+	bestHour := 0
+	var bestUsable, bestGain float64
+	for hour := 0; hour < 24; hour++ {
+		usable, gain := simulateReset(hourly, float64(model.Capacity), float64(model.PerHour), hour)
+		suggestion.Candidates[hour] = ResetCandidate{
+			Time:      fmt.Sprintf("%02d:00", hour),
+			Usable:    int(math.Round(usable)),
+			ResetGain: int(math.Round(gain)),
+		}
+		if hour == 0 || usable > bestUsable+0.5 || (usable > bestUsable-0.5 && gain > bestGain) {
+			bestHour, bestUsable, bestGain = hour, usable, gain
+		}
+	}
+	suggestion.Suggested = suggestion.Candidates[bestHour]
+	return suggestion
+}
+
+// WithCurrent 附带当前配置的重置时间（"HH:MM"）的模拟结果，按所在整点计算
+func (r ResetSuggestion) WithCurrent(resetTime string) ResetSuggestion {
+	hour, err := strconv.Atoi(strings.SplitN(resetTime, ":", 2)[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return r
+	}
+	current := r.Candidates[hour]
+	current.Time = resetTime
+	r.Current = &current
+	return r
+}
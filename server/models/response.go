@@ -9,9 +9,17 @@ type APIResponse struct {
 
 // ErrorResponse 错误响应
 type ErrorResponse struct {
-	Code    int    `json:"code"`
+	Code      int          `json:"code"`
+	Message   string       `json:"message"`
+	Error     string       `json:"error,omitempty"`
+	ErrorCode string       `json:"errorCode,omitempty"` // 机器可读的错误码，见下方错误码常量
+	Errors    []FieldError `json:"errors,omitempty"`    // 字段级验证错误
+}
+
+// ErrorEvent 通过SSE推送的错误事件
+type ErrorEvent struct {
+	Code    string `json:"code,omitempty"` // 机器可读的错误码，无法归类时为空
 	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
 }
 
 // 常用响应构造函数
@@ -38,14 +46,32 @@ func Error(code int, message string, err error) *ErrorResponse {
 	if err != nil {
 		resp.Error = err.Error()
 	}
+	// 验证错误附带各字段的错误说明
+	if fields := FieldErrors(err); fields != nil {
+		resp.Errors = fields
+		resp.ErrorCode = ErrValidationFailed
+	}
 	return resp
 }
 
-// 错误类型常量
+// WithCode 设置机器可读的错误码，code为空时不变
+func (r *ErrorResponse) WithCode(code string) *ErrorResponse {
+	if code != "" {
+		r.ErrorCode = code
+	}
+	return r
+}
+
+// 错误码常量：提示文本会随语言和措辞变化，客户端应根据错误码判断错误类型
 const (
-	ErrInvalidCookie      = "INVALID_COOKIE"
-	ErrAPIRequest         = "API_REQUEST_ERROR"
-	ErrDataParsing        = "DATA_PARSING_ERROR"
+	ErrCookieInvalid      = "COOKIE_INVALID"      // Cookie无效或已过期
+	ErrCookieMissing      = "COOKIE_MISSING"      // 尚未配置Cookie
+	ErrUpstreamTimeout    = "UPSTREAM_TIMEOUT"    // 上游请求超时
+	ErrRateLimited        = "RATE_LIMITED"        // 请求过于频繁（本服务限流或上游返回429）
+	ErrResetLimitReached  = "RESET_LIMIT_REACHED" // 当前重置周期的重置次数已用完
+	ErrValidationFailed   = "VALIDATION_FAILED"   // 请求参数验证失败，详见errors字段
+	ErrAPIRequest         = "API_REQUEST_ERROR"   // 上游请求失败（网络错误或非预期的状态码）
+	ErrDataParsing        = "DATA_PARSING_ERROR"  // 上游响应解析失败
 	ErrDatabaseOperation  = "DATABASE_ERROR"
 	ErrTaskNotRunning     = "TASK_NOT_RUNNING"
 	ErrTaskAlreadyRunning = "TASK_ALREADY_RUNNING"
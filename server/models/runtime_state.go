@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// RuntimeState 运行时状态快照（用于远程排查任务协调问题，不包含Cookie、会话等敏感信息）
+type RuntimeState struct {
+	Scheduler   SchedulerState       `json:"scheduler"`
+	AutoReset   *AutoResetState      `json:"autoReset,omitempty"`
+	Health      *HealthDebugState    `json:"health,omitempty"`
+	ConfigJobs  *ConfigJobsState     `json:"configJobs,omitempty"`
+	Probe       *UpstreamProbeState  `json:"probe,omitempty"` // 上游可用性探测
+	Listeners   map[string]int       `json:"listeners"`       // 各类SSE监听器数量
+	Cache       CacheState           `json:"cache"`           // 内存缓存数据的时效
+	Upstream    []UpstreamLatency    `json:"upstream"`        // 各上游接口的响应耗时
+	Failures    UpstreamFailureStats `json:"failures"`        // 上游请求失败统计
+	Outbound    OutboundLimitState   `json:"outbound"`        // 全局上游请求频率限制
+	Maintenance MaintenanceStatus    `json:"maintenance"`     // 维护模式
+	Database    DatabaseState        `json:"database"`
+	Goroutines  int                  `json:"goroutines"`
+	Timestamp   time.Time            `json:"timestamp"`
+}
+
+// SchedulerState 监控调度器状态
+type SchedulerState struct {
+	Running             bool `json:"running"`             // 监控任务是否运行
+	BalanceTaskRunning  bool `json:"balanceTaskRunning"`  // 积分余额任务是否在运行
+	AutoScheduleEnabled bool `json:"autoScheduleEnabled"` // 是否启用自动调度
+	InAutoScheduleRange bool `json:"inAutoScheduleRange"` // 当前是否在自动调度时间范围内
+	DailyUsageActive    bool `json:"dailyUsageActive"`    // 每日积分统计任务是否激活
+	DailyResetUsed      bool `json:"dailyResetUsed"`      // 今日是否已使用重置
+	FetchInterval       int  `json:"fetchInterval"`       // 当前生效的获取间隔(秒)，启用自适应获取间隔时随活跃度变化
+}
+
+// AutoResetState 自动重置服务状态
+type AutoResetState struct {
+	Enabled          bool `json:"enabled"`          // 是否启用自动重置
+	TasksCreated     bool `json:"tasksCreated"`     // 定时任务是否已创建
+	TasksRunning     bool `json:"tasksRunning"`     // 定时任务是否运行
+	ThresholdRunning bool `json:"thresholdRunning"` // 阈值检查是否已订阅积分余额
+	ThresholdActive  bool `json:"thresholdActive"`  // 当前是否会执行阈值检查（已订阅、监控运行中且在检查时间范围内）
+}
+
+// HealthDebugState 健康监督服务内部状态
+type HealthDebugState struct {
+	HealthState
+	ConsecutiveFailures int        `json:"consecutiveFailures"`         // 上游连续失败次数
+	LastUpstreamError   string     `json:"lastUpstreamError,omitempty"` // 最近一次上游错误
+	LastSuccessAt       *time.Time `json:"lastSuccessAt,omitempty"`     // 最近一次上游请求成功时间
+	CookieExpired       bool       `json:"cookieExpired"`               // 上游是否返回Cookie失效
+}
+
+// ConfigJobsState 异步配置任务状态
+type ConfigJobsState struct {
+	Running   bool `json:"running"`   // 异步配置更新服务是否运行
+	QueueSize int  `json:"queueSize"` // 队列中等待处理的任务数
+	Pending   int  `json:"pending"`   // 已持久化、尚未完成的任务数（含等待重试）
+	Dead      int  `json:"dead"`      // 死信列表中的任务数
+}
+
+// CacheState 内存缓存数据的时效
+type CacheState struct {
+	UsageRecords      int        `json:"usageRecords"`                // 缓存的积分使用记录数
+	LatestUsageAt     *time.Time `json:"latestUsageAt,omitempty"`     // 最新一条使用记录的时间
+	BalanceUpdatedAt  *time.Time `json:"balanceUpdatedAt,omitempty"`  // 积分余额更新时间
+	BalanceAgeSeconds *int64     `json:"balanceAgeSeconds,omitempty"` // 积分余额距今秒数
+}
+
+// DatabaseState 数据库错误状态
+type DatabaseState struct {
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
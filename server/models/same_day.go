@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultSameDayWeeks 同星期几对比默认包含的周数
+const DefaultSameDayWeeks = 4
+
+// SameDayCurve 某一天按小时的积分使用曲线
+type SameDayCurve struct {
+	Date       string `json:"date"`       // 日期 (YYYY-MM-DD)
+	Total      int    `json:"total"`      // 曲线范围内的积分合计
+	Hourly     []int  `json:"hourly"`     // 各小时（下标为0-23点）的积分使用量，今日只到当前小时
+	Cumulative []int  `json:"cumulative"` // 截至各小时结束的累计积分
+	HasData    bool   `json:"hasData"`    // 是否有按小时统计（升级前保存的数据没有，不计入平均值）
+}
+
+// SameDayComparison 今日与之前几周同一星期几的按小时积分对比
+type SameDayComparison struct {
+	Weekday       string         `json:"weekday"`       // 星期几（如 Tuesday）
+	Hour          int            `json:"hour"`          // 当前小时（0-23）
+	Today         SameDayCurve   `json:"today"`         // 今日截至当前小时的曲线
+	Previous      []SameDayCurve `json:"previous"`      // 之前各周同一天的全天曲线（由近到远）
+	Average       []int          `json:"average"`       // 之前各周（有数据的）各小时的平均积分
+	AverageToHour int            `json:"averageToHour"` // 之前各周截至当前小时的平均累计积分，与today.total对比即可判断今日消耗是否异常
+}
+
+// SameWeekdayDates 获取now之前weeks周中与今日同一星期几的日期（由近到远）
+func SameWeekdayDates(now time.Time, weeks int) []string {
+	dates := make([]string, weeks)
+	for i := range dates {
+		dates[i] = GetLocalDate(now.AddDate(0, 0, -7*(i+1)))
+	}
+	return dates
+}
+
+// newSameDayCurve 由按小时的积分生成曲线，只保留前hours个小时
+func newSameDayCurve(date string, hourly [24]int, hours int, hasData bool) SameDayCurve {
+	curve := SameDayCurve{
+		Date:       date,
+		Hourly:     make([]int, hours),
+		Cumulative: make([]int, hours),
+		HasData:    hasData,
+	}
+	for hour := 0; hour < hours; hour++ {
+		curve.Total += hourly[hour]
+		curve.Hourly[hour] = hourly[hour]
+		curve.Cumulative[hour] = curve.Total
+	}
+	return curve
+}
+
+// hourlyCredits 汇总每日统计中各小时的积分（不区分模型）
+func (d *DailyUsage) hourlyCredits() [24]int {
+	var hourly [24]int
+	for hour := range hourly {
+		for _, credits := range d.GetHourlyModelCredits(fmt.Sprintf("%02d", hour)) {
+			hourly[hour] += credits
+		}
+	}
+	return hourly
+}
+
+// NewSameDayComparison 生成同星期几对比
+// today的按小时统计每小时写入一次，当前小时尚未写入的部分由todayRecords（今日的原始使用记录）补充，两者取较大值
+// previous为之前各周同一天的每日统计（由近到远），没有记录的日期传入只含日期的空统计
+func NewSameDayComparison(today DailyUsage, todayRecords UsageDataList, previous []DailyUsage, now time.Time) SameDayComparison {
+	now = now.Local()
+	hours := now.Hour() + 1
+
+	todayHourly := today.hourlyCredits()
+	var recordHourly [24]int
+	for _, record := range todayRecords {
+		createdAt := record.CreatedAt.Local()
+		if GetLocalDate(createdAt) == today.Date {
+			recordHourly[createdAt.Hour()] += record.CreditsUsed
+		}
+	}
+	for hour := range todayHourly {
+		todayHourly[hour] = max(todayHourly[hour], recordHourly[hour])
+	}
+
+	comparison := SameDayComparison{
+		Weekday:  now.Weekday().String(),
+		Hour:     now.Hour(),
+		Today:    newSameDayCurve(today.Date, todayHourly, hours, true),
+		Previous: make([]SameDayCurve, len(previous)),
+		Average:  make([]int, 24),
+	}
+
+	var sums [24]int
+	counted, sumToHour := 0, 0
+	for i, usage := range previous {
+		// 没有积分使用的日期不保存记录，视为全天为0
+		hasData := usage.TotalCredits == 0 || len(usage.HourlyModelCredits) > 0
+		curve := newSameDayCurve(usage.Date, usage.hourlyCredits(), 24, hasData)
+		comparison.Previous[i] = curve
+		if !hasData {
+			continue
+		}
+		counted++
+		sumToHour += curve.Cumulative[now.Hour()]
+		for hour, credits := range curve.Hourly {
+			sums[hour] += credits
+		}
+	}
+	if counted > 0 {
+		for hour := range sums {
+			comparison.Average[hour] = sums[hour] / counted
+		}
+		comparison.AverageToHour = sumToHour / counted
+	}
+	return comparison
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// UpstreamLatency 单个上游接口的响应耗时统计（基于最近的样本）
+type UpstreamLatency struct {
+	Endpoint string    `json:"endpoint"` // 接口路径
+	Count    int64     `json:"count"`    // 启动以来的请求数
+	Samples  int       `json:"samples"`  // 参与统计的最近样本数
+	P50Ms    float64   `json:"p50Ms"`    // 中位数耗时（毫秒）
+	P95Ms    float64   `json:"p95Ms"`    // P95耗时（毫秒）
+	MaxMs    float64   `json:"maxMs"`    // 样本中的最大耗时（毫秒）
+	LastMs   float64   `json:"lastMs"`   // 最近一次耗时（毫秒）
+	LastAt   time.Time `json:"lastAt"`   // 最近一次请求时间
+}
+
+// OutboundLimitState 全局上游请求频率限制状态
+type OutboundLimitState struct {
+	PerMinute  int   `json:"perMinute"`  // 每分钟请求数上限，0表示不限制
+	LastMinute int   `json:"lastMinute"` // 最近一分钟内发出的请求数
+	Delayed    int64 `json:"delayed"`    // 启动以来因达到上限而等待的请求数
+}
+
+// UpstreamSchemaDrift 上游响应结构与预期不一致（字段增减或类型变化）
+type UpstreamSchemaDrift struct {
+	Endpoint   string    `json:"endpoint"`           // 接口路径
+	Missing    []string  `json:"missing,omitempty"`  // 缺少的预期字段
+	Added      []string  `json:"added,omitempty"`    // 新增的未知字段
+	Mistyped   []string  `json:"mistyped,omitempty"` // 类型与预期不符（已尽量转换）的字段
+	Note       string    `json:"note,omitempty"`     // 其他结构变化说明
+	DetectedAt time.Time `json:"detectedAt"`         // 首次发现时间
+}
+
+// UpstreamFailureCounts 按类别统计的上游请求失败次数
+type UpstreamFailureCounts struct {
+	Timeout      int64 `json:"timeout"`      // 请求超时
+	Network      int64 `json:"network"`      // 连接失败等其他网络错误
+	Unauthorized int64 `json:"unauthorized"` // 401（Cookie无效或已过期）
+	ServerError  int64 `json:"serverError"`  // 5xx
+	HTTPError    int64 `json:"httpError"`    // 其他非预期状态码
+	Parse        int64 `json:"parse"`        // 响应解析失败
+	Total        int64 `json:"total"`        // 合计
+}
+
+// DailyUpstreamFailures 单日上游请求失败次数
+type DailyUpstreamFailures struct {
+	Date string `json:"date"` // 日期（本地时区，YYYY-MM-DD）
+	UpstreamFailureCounts
+}
+
+// UpstreamFailureStats 上游请求失败统计
+type UpstreamFailureStats struct {
+	Since time.Time               `json:"since"` // 统计起始时间（服务启动时间）
+	Total UpstreamFailureCounts   `json:"total"` // 启动以来累计
+	Today UpstreamFailureCounts   `json:"today"` // 今日
+	Daily []DailyUpstreamFailures `json:"daily"` // 最近几日（按日期倒序，含今日）
+}
+
+// RelayErrorBucket 单个小时内使用记录的状态码统计
+type RelayErrorBucket struct {
+	Start        time.Time `json:"start"`        // 小时起始时间
+	Total        int       `json:"total"`        // 携带状态码的记录数
+	Throttled    int       `json:"throttled"`    // 429记录数
+	ServerErrors int       `json:"serverErrors"` // 5xx记录数
+}
+
+// RelayErrorStats 使用记录中429/5xx状态码的统计
+// 反映中转站对请求的限流或故障，与本服务访问上游接口是否成功无关
+type RelayErrorStats struct {
+	Hours        int                `json:"hours"`        // 统计时长(小时)
+	Total        int                `json:"total"`        // 携带状态码的记录数（旧记录没有状态码，不参与统计）
+	Throttled    int                `json:"throttled"`    // 429记录数
+	ServerErrors int                `json:"serverErrors"` // 5xx记录数
+	ErrorRate    float64            `json:"errorRate"`    // (429+5xx)/Total，0-1
+	StatusCounts map[string]int     `json:"statusCounts"` // 各状态码的记录数
+	Hourly       []RelayErrorBucket `json:"hourly"`       // 按小时统计（按时间升序，无记录的小时计数为0）
+}
+
+// IsRelayError 判断状态码是否为中转站限流(429)或故障(5xx)
+func IsRelayError(statusCode int) bool {
+	return statusCode == 429 || statusCode >= 500
+}
+
+// RelayErrors 统计最近hours小时内使用记录的429/5xx占比
+func (u UsageDataList) RelayErrors(hours int, now time.Time) RelayErrorStats {
+	start := now.Add(-time.Duration(hours) * time.Hour).Truncate(time.Hour)
+	stats := RelayErrorStats{
+		Hours:        hours,
+		StatusCounts: make(map[string]int),
+		Hourly:       make([]RelayErrorBucket, 0, hours+1),
+	}
+	for t := start; !t.After(now); t = t.Add(time.Hour) {
+		stats.Hourly = append(stats.Hourly, RelayErrorBucket{Start: t})
+	}
+
+	cutoff := now.Add(-time.Duration(hours) * time.Hour)
+	for _, data := range u {
+		if data.StatusCode == 0 || data.CreatedAt.Before(cutoff) || data.CreatedAt.After(now) {
+			continue
+		}
+		bucket := &stats.Hourly[int(data.CreatedAt.Sub(start)/time.Hour)]
+		stats.Total++
+		bucket.Total++
+		stats.StatusCounts[strconv.Itoa(data.StatusCode)]++
+		switch {
+		case data.StatusCode == 429:
+			stats.Throttled++
+			bucket.Throttled++
+		case data.StatusCode >= 500:
+			stats.ServerErrors++
+			bucket.ServerErrors++
+		}
+	}
+	if stats.Total > 0 {
+		stats.ErrorRate = float64(stats.Throttled+stats.ServerErrors) / float64(stats.Total)
+	}
+	return stats
+}
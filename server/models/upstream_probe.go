@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// 上游可用性探测参数
+const (
+	UpstreamProbeDays         = 7  // 探测记录保留天数
+	DefaultUpstreamProbeHours = 24 // 可用性统计默认的最近小时数
+)
+
+// UpstreamProbe 一次上游可用性探测结果
+type UpstreamProbe struct {
+	Time       time.Time `json:"time"`                 // 探测时间
+	Up         bool      `json:"up"`                   // 上游是否可用（有响应且不是5xx）
+	StatusCode int       `json:"statusCode,omitempty"` // HTTP状态码，请求失败时为空
+	LatencyMs  int64     `json:"latencyMs"`            // 响应耗时（毫秒）
+	Error      string    `json:"error,omitempty"`      // 不可用原因
+}
+
+// UpstreamOutage 一段上游不可用时段（连续探测失败）
+type UpstreamOutage struct {
+	Start     time.Time  `json:"start"`         // 首次探测失败时间
+	End       *time.Time `json:"end,omitempty"` // 恢复后首次探测成功时间，仍不可用时为空
+	Minutes   int        `json:"minutes"`       // 持续分钟数（仍不可用时统计到当前）
+	Probes    int        `json:"probes"`        // 失败的探测次数
+	LastError string     `json:"lastError"`     // 最后一次失败原因
+}
+
+// UpstreamAvailability 最近一段时间的上游可用性
+type UpstreamAvailability struct {
+	Hours        int              `json:"hours"`        // 统计的最近小时数
+	Total        int              `json:"total"`        // 探测次数
+	Up           int              `json:"up"`           // 可用的探测次数
+	Availability *float64         `json:"availability"` // 可用率（百分比），没有探测记录时为空
+	Outages      []UpstreamOutage `json:"outages"`      // 不可用时段（按时间升序）
+	Probes       []UpstreamProbe  `json:"probes"`       // 探测记录（按时间升序）
+}
+
+// UpstreamProbeState 上游可用性探测状态（用于运行状态快照）
+type UpstreamProbeState struct {
+	IntervalMinutes int             `json:"intervalMinutes"`         // 探测间隔（分钟）
+	LastProbe       *UpstreamProbe  `json:"lastProbe,omitempty"`     // 最近一次探测结果
+	Availability    *float64        `json:"availability"`            // 最近24小时可用率（百分比）
+	Outages         int             `json:"outages"`                 // 最近24小时不可用时段数
+	CurrentOutage   *UpstreamOutage `json:"currentOutage,omitempty"` // 当前持续中的不可用时段
+}
+
+// NewUpstreamAvailability 根据按时间升序的探测记录统计可用率和不可用时段
+func NewUpstreamAvailability(hours int, probes []UpstreamProbe, now time.Time) UpstreamAvailability {
+	availability := UpstreamAvailability{
+		Hours:   hours,
+		Total:   len(probes),
+		Outages: make([]UpstreamOutage, 0),
+		Probes:  probes,
+	}
+
+	var outage *UpstreamOutage
+	for _, probe := range probes {
+		if probe.Up {
+			availability.Up++
+			if outage != nil {
+				end := probe.Time
+				outage.End = &end
+				outage.Minutes = int(end.Sub(outage.Start).Minutes())
+				availability.Outages = append(availability.Outages, *outage)
+				outage = nil
+			}
+			continue
+		}
+		if outage == nil {
+			outage = &UpstreamOutage{Start: probe.Time}
+		}
+		outage.Probes++
+		outage.LastError = probe.Error
+	}
+	if outage != nil {
+		outage.Minutes = int(now.Sub(outage.Start).Minutes())
+		availability.Outages = append(availability.Outages, *outage)
+	}
+
+	if availability.Total > 0 {
+		percent := float64(availability.Up) * 100 / float64(availability.Total)
+		availability.Availability = &percent
+	}
+	return availability
+}
+
+// CurrentOutage 仍在持续的不可用时段，上游当前可用时返回nil
+func (a UpstreamAvailability) CurrentOutage() *UpstreamOutage {
+	if len(a.Outages) == 0 {
+		return nil
+	}
+	last := a.Outages[len(a.Outages)-1]
+	if last.End != nil {
+		return nil
+	}
+	return &last
+}
@@ -8,6 +8,8 @@ type UsageData struct {
 	CreditsUsed int       `json:"creditsUsed"`
 	CreatedAt   time.Time `json:"createdAt"`
 	Model       string    `json:"model"`
+	StatusCode  int       `json:"statusCode,omitempty"` // 中转站记录的请求状态码（旧记录为0）
+	Group       string    `json:"group,omitempty"`      // 所属模型分组（推送时按当前分组计算，不持久化）
 }
 
 // UsageDataList 积分使用数据列表
@@ -20,6 +22,14 @@ type CreditBalance struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// CreditBalanceChange 积分余额及其变化量（由服务端根据积分余额历史计算）
+type CreditBalanceChange struct {
+	CreditBalance
+	SincePrevious *int `json:"sincePrevious"` // 与上一次读数相比的变化量，没有上一次读数时为null
+	SinceMidnight *int `json:"sinceMidnight"` // 与今日零点相比的变化量，零点前没有读数时以今日首次读数为基准
+	UsedToday     int  `json:"usedToday"`     // 今日已使用积分（实时累计）
+}
+
 // FilterByTimeRange 根据时间范围过滤数据
 func (u UsageDataList) FilterByTimeRange(minutes int) UsageDataList {
 	if minutes <= 0 {
@@ -47,7 +57,8 @@ func (u UsageDataList) GroupByModel() map[string]UsageDataList {
 
 	for _, data := range u {
 		if data.CreditsUsed > 0 {
-			groups[data.Model] = append(groups[data.Model], data)
+			model := NormalizeModel(data.Model)
+			groups[model] = append(groups[model], data)
 		}
 	}
 
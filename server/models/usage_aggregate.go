@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// UsageAggregateInterval 原始使用记录降采样的聚合粒度
+const UsageAggregateInterval = 15 * time.Minute
+
+// UsageAggregate 降采样后的积分使用聚合（原始记录超过保留时长后按15分钟聚合保存）
+type UsageAggregate struct {
+	Start        time.Time      `json:"start"`        // 聚合区间起始时间
+	Credits      int            `json:"credits"`      // 区间内的积分使用量
+	Count        int            `json:"count"`        // 区间内的原始记录数
+	ModelCredits map[string]int `json:"modelCredits"` // 按模型分组的积分使用量
+}
+
+// Add 将一条原始使用记录累加到聚合中
+func (a *UsageAggregate) Add(data UsageData) {
+	if a.ModelCredits == nil {
+		a.ModelCredits = make(map[string]int)
+	}
+	a.Credits += data.CreditsUsed
+	a.Count++
+	if data.Model != "" && data.CreditsUsed > 0 {
+		a.ModelCredits[data.Model] += data.CreditsUsed
+	}
+}
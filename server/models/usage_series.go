@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// 时间序列分桶参数
+const (
+	MinSeriesBucket     = time.Minute // 最小分桶间隔
+	MaxSeriesBucket     = time.Hour   // 最大分桶间隔
+	DefaultSeriesBucket = 5 * time.Minute
+	MaxSeriesMinutes    = 24 * 60 // 最大时间范围（分钟）
+)
+
+// UsageSeriesPoint 时间序列中的单个分桶
+type UsageSeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"` // 分桶起始时间
+	Credits   int       `json:"credits"`   // 分桶内的积分使用量
+	Count     int       `json:"count"`     // 分桶内的记录数
+}
+
+// UsageSeries 单个模型（或模型分组）的积分使用时间序列
+type UsageSeries struct {
+	Model   string             `json:"model"`   // 模型名或分组名
+	Bucket  string             `json:"bucket"`  // 分桶间隔（如 5m0s）
+	Minutes int                `json:"minutes"` // 时间范围（分钟）
+	Total   int                `json:"total"`   // 时间范围内的积分合计
+	Points  []UsageSeriesPoint `json:"points"`  // 按时间升序的分桶，无数据的分桶积分为0
+}
+
+// Series 按固定间隔统计指定模型（模型名或分组名均可匹配）在最近minutes分钟内的积分使用量
+func (u UsageDataList) Series(model string, bucket time.Duration, minutes int, now time.Time) UsageSeries {
+	start := now.Add(-time.Duration(minutes) * time.Minute).Truncate(bucket)
+	end := now.Truncate(bucket)
+
+	points := make([]UsageSeriesPoint, 0, int(end.Sub(start)/bucket)+1)
+	for t := start; !t.After(end); t = t.Add(bucket) {
+		points = append(points, UsageSeriesPoint{Timestamp: t})
+	}
+
+	series := UsageSeries{
+		Model:   model,
+		Bucket:  bucket.String(),
+		Minutes: minutes,
+		Points:  points,
+	}
+	for _, data := range u {
+		if data.Model != model && ModelGroupOf(data.Model) != model {
+			continue
+		}
+		index := int(data.CreatedAt.Sub(start) / bucket)
+		if data.CreatedAt.Before(start) || index >= len(points) {
+			continue
+		}
+		points[index].Credits += data.CreditsUsed
+		points[index].Count++
+		series.Total += data.CreditsUsed
+	}
+	return series
+}
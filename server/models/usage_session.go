@@ -0,0 +1,61 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// 会话划分参数（分钟）
+const (
+	DefaultSessionGapMinutes = 30
+	MaxSessionGapMinutes     = 240
+)
+
+// UsageSession 一段连续使用：相邻使用记录的间隔小于阈值时归为同一会话
+type UsageSession struct {
+	Start           time.Time      `json:"start"`           // 第一条记录的时间
+	End             time.Time      `json:"end"`             // 最后一条记录的时间
+	DurationSeconds int            `json:"durationSeconds"` // 持续时长(秒)，只有一条记录时为0
+	Credits         int            `json:"credits"`         // 会话内的积分使用量
+	Records         int            `json:"records"`         // 会话内的记录数
+	DominantModel   string         `json:"dominantModel"`   // 积分使用最多的模型（按别名归并）
+	ModelCredits    map[string]int `json:"modelCredits"`    // 按模型分组的积分使用量（按别名归并）
+}
+
+// Sessions 将使用记录按时间排序后划分为会话（相邻记录间隔小于gap），按开始时间升序返回
+func (u UsageDataList) Sessions(gap time.Duration) []UsageSession {
+	records := make(UsageDataList, len(u))
+	copy(records, u)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	sessions := make([]UsageSession, 0)
+	for i, data := range records {
+		if i == 0 || data.CreatedAt.Sub(records[i-1].CreatedAt) >= gap {
+			sessions = append(sessions, UsageSession{
+				Start:        data.CreatedAt,
+				ModelCredits: make(map[string]int),
+			})
+		}
+		session := &sessions[len(sessions)-1]
+		session.End = data.CreatedAt
+		session.Credits += data.CreditsUsed
+		session.Records++
+		if data.Model != "" && data.CreditsUsed > 0 {
+			session.ModelCredits[NormalizeModel(data.Model)] += data.CreditsUsed
+		}
+	}
+
+	for i := range sessions {
+		sessions[i].DurationSeconds = int(sessions[i].End.Sub(sessions[i].Start).Seconds())
+		for model, credits := range sessions[i].ModelCredits {
+			best := sessions[i].ModelCredits[sessions[i].DominantModel]
+			// 积分相同时取名称靠前的模型，保证结果稳定
+			if sessions[i].DominantModel == "" || credits > best || (credits == best && model < sessions[i].DominantModel) {
+				sessions[i].DominantModel = model
+			}
+		}
+	}
+	return sessions
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError 字段级验证错误
+type FieldError struct {
+	Field   string `json:"field"`   // 请求JSON中的字段路径，如 autoReset.resetTime、hooks[0].path
+	Message string `json:"message"` // 错误说明
+}
+
+// ValidationErrors 字段级验证错误集合，作为error返回时可一次报告所有无效字段
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, e := range v {
+		if e.Field == "" {
+			parts[i] = e.Message
+		} else {
+			parts[i] = e.Field + ": " + e.Message
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Add 记录字段错误（err为nil时忽略）；err本身是ValidationErrors时，其中的字段路径接在field之后
+func (v *ValidationErrors) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+	var nested ValidationErrors
+	if errors.As(err, &nested) {
+		for _, e := range nested {
+			*v = append(*v, FieldError{Field: joinFieldPath(field, e.Field), Message: e.Message})
+		}
+		return
+	}
+	*v = append(*v, FieldError{Field: field, Message: err.Error()})
+}
+
+// Addf 按格式记录字段错误
+func (v *ValidationErrors) Addf(field, format string, args ...any) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err 有错误时返回自身，否则返回nil（避免返回非nil的空切片）
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// FieldErrors 提取错误中的字段级验证错误，不是验证错误时返回nil
+func FieldErrors(err error) []FieldError {
+	var v ValidationErrors
+	if errors.As(err, &v) {
+		return v
+	}
+	return nil
+}
+
+// joinFieldPath 拼接字段路径（数组下标直接拼接，如 hooks + [0].path → hooks[0].path）
+func joinFieldPath(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	case strings.HasPrefix(field, "["):
+		return prefix + field
+	}
+	return prefix + "." + field
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook事件类型
+const (
+	WebhookEventThreshold     = "threshold"      // 积分低于阈值
+	WebhookEventExhausted     = "exhausted"      // 积分耗尽（降至下限）
+	WebhookEventExternalUsage = "external_usage" // 余额下降无法由本机使用解释
+	WebhookEventLoginNewIP    = "login_new_ip"   // 从未登录过的IP登录成功
+)
+
+// WebhookPayload 调用Webhook时发送的JSON内容
+type WebhookPayload struct {
+	Event     string      `json:"event"`          // 事件类型
+	Message   string      `json:"message"`        // 事件描述
+	Data      interface{} `json:"data,omitempty"` // 事件数据
+	Timestamp time.Time   `json:"timestamp"`      // 事件时间
+}
+
+// validateWebhookURL 校验Webhook地址（须为http/https绝对地址，且不能指向本机或链路本地地址）
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("Webhook地址无效: %s", raw)
+	}
+	host := parsed.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("Webhook地址不能指向本机: %s", raw)
+	}
+	if ip := net.ParseIP(host); ip != nil && IsBlockedWebhookIP(ip) {
+		return fmt.Errorf("Webhook地址不能指向本机或链路本地地址: %s", raw)
+	}
+	return nil
+}
+
+// IsBlockedWebhookIP 判断Webhook是否禁止访问该地址（回环、链路本地、未指定地址），防止借Webhook访问本机服务或云主机元数据接口
+func IsBlockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://example.com/hooks/cccmu", true},
+		{"http://192.168.1.10:8080/hook", true},
+		{"ftp://example.com/hook", false},
+		{"/hooks/cccmu", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://0.0.0.0/hook", false},
+	}
+	for _, tt := range tests {
+		if err := validateWebhookURL(tt.url); (err == nil) != tt.valid {
+			t.Errorf("validateWebhookURL(%q) = %v，期望有效: %v", tt.url, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package plugin
+
+import "github.com/leafney/cccmu/server/models"
+
+// Notifier 通知渠道插件：推送给前端的通知同时转发给插件
+type Notifier struct {
+	plugin *Plugin
+}
+
+// NewNotifier 创建通知渠道插件
+func NewNotifier(p *Plugin) *Notifier {
+	return &Notifier{plugin: p}
+}
+
+// Name 获取插件名称
+func (n *Notifier) Name() string {
+	return n.plugin.Info().Name
+}
+
+// Notify 转发通知（notifier.notify）
+func (n *Notifier) Notify(notification models.Notification) error {
+	return n.plugin.Call("notifier.notify", notification, nil)
+}
@@ -0,0 +1,279 @@
+// Package plugin 基于子进程JSON-RPC的插件系统
+// 插件是独立的可执行程序，通过标准输入输出逐行交换JSON-RPC 2.0消息，可提供通知渠道或替代的上游站点
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// 插件类型
+const (
+	KindNotifier = "notifier" // 通知渠道
+	KindProvider = "provider" // 上游站点
+)
+
+// 调用超时和消息大小限制
+const (
+	callTimeout    = 30 * time.Second
+	maxMessageSize = 16 * 1024 * 1024
+)
+
+// CodeCookieExpired 插件返回此错误码表示Cookie无效或已过期
+const CodeCookieExpired = -32001
+
+// Info 插件自述信息（plugin.info 方法的返回值）
+type Info struct {
+	Name    string `json:"name"`    // 插件名称
+	Kind    string `json:"kind"`    // 插件类型
+	Version string `json:"version"` // 插件版本
+}
+
+// rpcRequest JSON-RPC请求
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse JSON-RPC响应
+type rpcResponse struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError 插件返回的错误
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("插件错误(%d): %s", e.Code, e.Message)
+}
+
+// Plugin 插件进程，调用串行执行；进程退出或调用超时后在下次调用时自动重启
+type Plugin struct {
+	path string
+	info Info
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte // 插件标准输出的行
+	nextID int64
+}
+
+// Load 启动插件并读取插件信息
+func Load(path string) (*Plugin, error) {
+	p := &Plugin{path: path}
+	var info Info
+	if err := p.Call("plugin.info", nil, &info); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("加载插件 %s 失败: %w", filepath.Base(path), err)
+	}
+	if info.Kind != KindNotifier && info.Kind != KindProvider {
+		p.Close()
+		return nil, fmt.Errorf("加载插件 %s 失败: 不支持的插件类型 %q", filepath.Base(path), info.Kind)
+	}
+	if info.Name == "" {
+		info.Name = filepath.Base(path)
+	}
+	p.info = info
+	return p, nil
+}
+
+// LoadDir 加载目录中的所有可执行文件作为插件（按文件名排序）
+func LoadDir(dir string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件目录失败: %w", err)
+	}
+
+	var plugins []*Plugin
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		p, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			for _, loaded := range plugins {
+				loaded.Close()
+			}
+			return nil, err
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// Info 获取插件信息
+func (p *Plugin) Info() Info {
+	return p.info
+}
+
+// Call 调用插件方法，result为nil时忽略返回值
+func (p *Plugin) Call(method string, params, result interface{}) error {
+	return p.CallContext(context.Background(), method, params, result)
+}
+
+// CallContext 调用插件方法，ctx取消或超时时停止等待返回值（插件稍后返回的结果按请求ID忽略）
+func (p *Plugin) CallContext(ctx context.Context, method string, params, result interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("调用插件方法 %s 已取消: %w", method, err)
+	}
+
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return err
+		}
+	}
+
+	p.nextID++
+	id := p.nextID
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		p.stop()
+		return fmt.Errorf("写入插件失败: %w", err)
+	}
+
+	timer := time.NewTimer(callTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				p.stop()
+				return fmt.Errorf("插件已退出")
+			}
+			var resp rpcResponse
+			if err := json.Unmarshal(line, &resp); err != nil {
+				utils.Logf("[插件] %s: 忽略无法解析的输出: %s", p.name(), truncate(line))
+				continue
+			}
+			if resp.ID != id {
+				continue
+			}
+			if resp.Error != nil {
+				return resp.Error
+			}
+			if result != nil {
+				if err := json.Unmarshal(resp.Result, result); err != nil {
+					return fmt.Errorf("解析插件返回值失败: %w", err)
+				}
+			}
+			return nil
+		case <-timer.C:
+			p.stop()
+			return fmt.Errorf("调用插件方法 %s 超时", method)
+		case <-ctx.Done():
+			return fmt.Errorf("调用插件方法 %s 已取消: %w", method, ctx.Err())
+		}
+	}
+}
+
+// Close 停止插件进程
+func (p *Plugin) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stop()
+}
+
+// start 启动插件进程（调用方持有锁）
+func (p *Plugin) start() error {
+	cmd := exec.Command(p.path)
+	cmd.Dir = filepath.Dir(p.path)
+	cmd.Env = utils.ChildEnv() // 与事件Hook一致，不继承主密钥等敏感环境变量
+	cmd.Stderr = &stderrLogger{plugin: p}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("创建插件输入管道失败: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("创建插件输出管道失败: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("启动插件失败: %w", err)
+	}
+
+	lines := make(chan []byte, 16)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+	}()
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.lines = lines
+	utils.Logf("[插件] %s 已启动 (PID: %d)", p.name(), cmd.Process.Pid)
+	return nil
+}
+
+// stop 停止插件进程（调用方持有锁）
+func (p *Plugin) stop() {
+	if p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	// 先排空输出使读取协程退出，再回收进程
+	for range p.lines {
+	}
+	p.cmd.Wait()
+	p.cmd = nil
+	p.stdin = nil
+	p.lines = nil
+}
+
+// name 插件文件名（用于日志）
+func (p *Plugin) name() string {
+	return filepath.Base(p.path)
+}
+
+// stderrLogger 将插件的标准错误输出写入日志
+type stderrLogger struct {
+	plugin *Plugin
+}
+
+func (w *stderrLogger) Write(data []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line != "" {
+			utils.Logf("[插件] %s: %s", w.plugin.name(), line)
+		}
+	}
+	return len(data), nil
+}
+
+// truncate 截断输出用于日志记录
+func truncate(data []byte) string {
+	const limit = 200
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
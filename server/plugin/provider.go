@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// providerParams 上游站点插件方法的参数
+type providerParams struct {
+	Cookie string `json:"cookie"` // 用户配置的Cookie
+}
+
+// resetResult provider.resetCredits 的返回值
+type resetResult struct {
+	Success bool   `json:"success"` // 是否重置成功
+	Info    string `json:"info"`    // 重置信息
+}
+
+// Provider 上游站点插件：替代内置的上游API获取使用数据、积分余额和重置积分
+type Provider struct {
+	plugin *Plugin
+}
+
+// NewProvider 创建上游站点插件
+func NewProvider(p *Plugin) *Provider {
+	return &Provider{plugin: p}
+}
+
+// Name 获取插件名称
+func (p *Provider) Name() string {
+	return p.plugin.Info().Name
+}
+
+// FetchUsageData 获取积分使用数据（provider.fetchUsage）
+func (p *Provider) FetchUsageData(ctx context.Context, cookie string) ([]models.UsageData, error) {
+	var data []models.UsageData
+	if err := p.call(ctx, "provider.fetchUsage", cookie, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// FetchCreditBalance 获取积分余额（provider.fetchBalance）
+func (p *Provider) FetchCreditBalance(ctx context.Context, cookie string) (*models.CreditBalance, error) {
+	var balance models.CreditBalance
+	if err := p.call(ctx, "provider.fetchBalance", cookie, &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// ResetCredits 重置积分（provider.resetCredits）
+func (p *Provider) ResetCredits(ctx context.Context, cookie string) (bool, string, error) {
+	var result resetResult
+	if err := p.call(ctx, "provider.resetCredits", cookie, &result); err != nil {
+		return false, "", err
+	}
+	return result.Success, result.Info, nil
+}
+
+// call 调用插件方法，Cookie失效错误转换为client.ErrCookieExpired
+func (p *Provider) call(ctx context.Context, method, cookie string, result interface{}) error {
+	err := p.plugin.CallContext(ctx, method, providerParams{Cookie: cookie}, result)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == CodeCookieExpired {
+		return client.ErrCookieExpired
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// portFile 自动选择端口时记录实际监听端口的文件（命令行子命令据此连接本机服务）
+const portFile = "./data/port"
+
+// portAutoAttempts 自动选择端口时在配置端口之后最多尝试的端口数
+const portAutoAttempts = 10
+
+// listenPort 监听服务端口；auto为true且端口不可用时依次尝试后续端口
+func listenPort(addr string, auto bool) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err == nil || !auto {
+		return listener, err
+	}
+
+	host, portStr, splitErr := net.SplitHostPort(addr)
+	first, convErr := strconv.Atoi(portStr)
+	if splitErr != nil || convErr != nil || first == 0 {
+		return nil, err
+	}
+	for p := first + 1; p <= first+portAutoAttempts && p <= 65535; p++ {
+		candidate := net.JoinHostPort(host, strconv.Itoa(p))
+		if listener, tryErr := net.Listen("tcp", candidate); tryErr == nil {
+			log.Printf("端口 %s 不可用（%v），已自动改用 %s", addr, err, candidate)
+			return listener, nil
+		}
+	}
+	return nil, fmt.Errorf("%w（已尝试后续%d个端口均不可用）", err, portAutoAttempts)
+}
+
+// savePortFile 记录实际监听的端口
+func savePortFile(listener net.Listener) {
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return
+	}
+	if err := os.WriteFile(portFile, []byte(strconv.Itoa(addr.Port)+"\n"), 0644); err != nil {
+		log.Printf("保存端口文件失败: %v", err)
+	}
+}
+
+// readPortFile 读取记录的端口，不存在或无效时返回空字符串
+func readPortFile() string {
+	data, err := os.ReadFile(portFile)
+	if err != nil {
+		return ""
+	}
+	port := strings.TrimSpace(string(data))
+	if _, err := strconv.Atoi(port); err != nil {
+		return ""
+	}
+	return ":" + port
+}
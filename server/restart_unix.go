@@ -0,0 +1,89 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// 平滑重启：收到SIGUSR2时以相同参数启动新进程（通常是已替换的新版本二进制），
+// 并通过继承的文件描述符交出监听套接字和已登录会话；旧进程完成请求、释放数据库后退出，
+// 新进程随即接管，期间到达的连接在套接字队列中等待，不会被拒绝
+const (
+	envInherit          = "CCCMU_INHERIT" // 由旧进程启动时设置
+	inheritedListenerFD = 3               // ExtraFiles[0]：监听套接字
+	inheritedSessionsFD = 4               // ExtraFiles[1]：会话交接管道的读端
+)
+
+// notifyRestart 返回平滑重启信号通道
+func notifyRestart() <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR2)
+	return ch
+}
+
+// inheritedListener 获取旧进程交出的监听套接字和会话管道，不是由平滑重启启动时返回nil
+func inheritedListener() (net.Listener, *os.File, error) {
+	if os.Getenv(envInherit) == "" {
+		return nil, nil, nil
+	}
+	// 避免再由本进程启动的子进程（如Hook程序）误认为继承了套接字
+	os.Unsetenv(envInherit)
+
+	file := os.NewFile(inheritedListenerFD, "listener")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("接管监听套接字失败: %w", err)
+	}
+	return listener, os.NewFile(inheritedSessionsFD, "sessions"), nil
+}
+
+// startSuccessor 启动新进程并交出监听套接字，返回新进程和会话交接管道的写端
+func startSuccessor(listener net.Listener) (*os.Process, *os.File, error) {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return nil, nil, fmt.Errorf("不支持的监听类型 %T", listener)
+	}
+	listenerFile, err := tcpListener.File()
+	if err != nil {
+		return nil, nil, fmt.Errorf("复制监听套接字失败: %w", err)
+	}
+	defer listenerFile.Close()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建会话交接管道失败: %w", err)
+	}
+	defer reader.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		writer.Close()
+		return nil, nil, fmt.Errorf("获取程序路径失败: %w", err)
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	// 看门狗指定的是旧进程，新进程接管后需由自己发送心跳
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "WATCHDOG_PID=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Env = append(cmd.Env, envInherit+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{listenerFile, reader}
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		return nil, nil, fmt.Errorf("启动新进程失败: %w", err)
+	}
+	return cmd.Process, writer, nil
+}
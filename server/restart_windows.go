@@ -0,0 +1,22 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+)
+
+// notifyRestart Windows不支持平滑重启，返回的通道永远不会收到信号
+func notifyRestart() <-chan os.Signal {
+	return nil
+}
+
+// inheritedListener Windows不支持平滑重启
+func inheritedListener() (net.Listener, *os.File, error) {
+	return nil, nil, nil
+}
+
+// startSuccessor Windows不支持平滑重启
+func startSuccessor(net.Listener) (*os.Process, *os.File, error) {
+	return nil, nil, errors.New("Windows不支持平滑重启")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/leafney/cccmu/server/auth"
+	"github.com/leafney/cccmu/server/handlers"
+	"github.com/leafney/cccmu/server/middleware"
+)
+
+// API路由前缀
+const (
+	apiV1Prefix     = "/api/v1" // 当前版本
+	apiLegacyPrefix = "/api"    // 旧版路径，作为v1的别名暂时保留
+)
+
+// 变更类接口限流：每个会话（或访问密钥、IP）允许突发10次，之后每6秒恢复一次
+const (
+	mutationRateBurst    = 10
+	mutationRateInterval = 6 * time.Second
+)
+
+// configIdempotencyLifetime 配置更新幂等键的有效期
+const configIdempotencyLifetime = 30 * time.Minute
+
+// apiHandlers API路由处理器集合
+type apiHandlers struct {
+	config     *handlers.ConfigHandler
+	control    *handlers.ControlHandler
+	sse        *handlers.SSEHandler
+	auth       *handlers.AuthHandler
+	dailyUsage *handlers.DailyUsageHandler
+	admin      *handlers.AdminHandler
+	feed       *handlers.FeedHandler
+	annotation *handlers.AnnotationHandler
+
+	mutationLimit     fiber.Handler // 变更类接口限流（v1与旧版路径共享同一限流器）
+	configIdempotency fiber.Handler // 配置更新幂等键（v1与旧版路径共享同一存储）
+	conditionalGet    fiber.Handler // 数据接口条件请求（ETag/Last-Modified）
+}
+
+// registerReplicaRoutes 只读副本模式下注册API路由：认证接口由本地处理，其余接口转发到主实例
+func registerReplicaRoutes(api fiber.Router, authManager *auth.Manager, h *apiHandlers, proxy *handlers.ReplicaProxy) {
+	authGroup := api.Group("/auth")
+	{
+		authGroup.Post("/login", h.auth.Login)
+		authGroup.Get("/logout", h.auth.Logout)
+		authGroup.Get("/status", h.auth.Status)
+	}
+
+	api.Use(middleware.AuthMiddleware(authManager))
+	api.Get("/admin/sessions", h.auth.ListSessions) // 登录会话和登录记录保存在本实例
+	api.Get("/admin/logins", h.auth.GetLoginAudit)
+	api.All("/*", proxy.Handle)
+}
+
+// registerAPIRoutes 在指定路由组下注册全部API路由
+func registerAPIRoutes(api fiber.Router, authManager *auth.Manager, h *apiHandlers) {
+	// 认证相关API（不需要认证）
+	authGroup := api.Group("/auth")
+	{
+		authGroup.Post("/login", h.auth.Login)
+		authGroup.Get("/logout", h.auth.Logout)
+		authGroup.Get("/status", h.auth.Status)
+	}
+
+	// 需要认证的API路由
+	api.Use(middleware.AuthMiddleware(authManager))
+	api.Use(middleware.UpstreamErrorMiddleware())
+	{
+		// 配置相关
+		api.Get("/config", h.config.GetConfig)
+		api.Get("/config/sync", h.config.GetSyncConfig)
+		api.Get("/config/sync/status", h.config.GetSyncStatus)
+		api.Get("/config/audit", h.config.GetConfigAudit)
+		api.Put("/config", h.mutationLimit, h.configIdempotency, h.config.UpdateConfig)
+		api.Post("/config/undo", h.mutationLimit, h.config.UndoConfig)
+		api.Delete("/config/cookie", h.mutationLimit, h.config.ClearCookie)
+
+		// 控制相关
+		api.Post("/control/start", h.mutationLimit, h.control.StartTask)
+		api.Post("/control/stop", h.mutationLimit, h.control.StopTask)
+		api.Get("/control/status", h.control.GetTaskStatus)
+		api.Post("/refresh", h.mutationLimit, h.control.RefreshAll)
+
+		// 积分余额相关
+		api.Get("/balance", h.conditionalGet, h.control.GetCreditBalance)
+		api.Get("/balance/curve", h.conditionalGet, h.control.GetBalanceCurve)
+		api.Post("/balance/reset", h.mutationLimit, h.control.ResetCredits)
+		api.Put("/reset/flag", h.mutationLimit, h.control.SetResetFlag)
+		api.Get("/reset/suggestion", h.control.GetResetSuggestion)
+		api.Get("/reset/report", h.control.GetResetReport)
+
+		// 数据相关
+		api.Get("/usage/stream", h.sse.StreamUsageData)
+		api.Get("/usage/data", h.conditionalGet, h.sse.GetUsageData)
+		api.Get("/usage/series", h.conditionalGet, h.sse.GetUsageSeries)
+		api.Get("/usage/trend", h.conditionalGet, h.sse.GetUsageTrend)
+		api.Get("/usage/errors", h.conditionalGet, h.sse.GetRelayErrors)
+		api.Get("/sessions", h.conditionalGet, h.sse.GetUsageSessions)
+		api.Get("/snapshot", h.sse.GetSnapshot)
+
+		// 图表标注
+		api.Get("/annotations", h.annotation.GetAnnotations)
+		api.Post("/annotations", h.mutationLimit, h.annotation.CreateAnnotation)
+		api.Delete("/annotations/:id", h.mutationLimit, h.annotation.DeleteAnnotation)
+
+		// 积分历史统计
+		api.Get("/history", h.conditionalGet, h.dailyUsage.GetWeeklyUsage)
+		api.Get("/history/export", h.conditionalGet, h.dailyUsage.ExportDailyUsage)
+		api.Get("/history/same-day", h.conditionalGet, h.dailyUsage.GetSameWeekdayComparison)
+
+		// 订阅地址
+		api.Get("/feeds", h.feed.GetFeedInfo)
+
+		// 运维管理
+		api.Get("/admin/db/stats", h.admin.GetDBStats)
+		api.Post("/admin/db/compact", h.mutationLimit, h.admin.CompactDB)
+		api.Get("/admin/backup", middleware.AdminOnlyMiddleware(), h.admin.Backup)
+		api.Get("/admin/state", h.admin.GetRuntimeState)
+		api.Get("/admin/requests", h.admin.GetRequestLog)
+		api.Get("/upstream/availability", h.admin.GetUpstreamAvailability)
+		api.Get("/admin/sessions", h.auth.ListSessions)
+		api.Get("/admin/logins", h.auth.GetLoginAudit)
+		api.Get("/admin/maintenance", h.admin.GetMaintenance)
+		api.Post("/admin/maintenance", h.mutationLimit, h.admin.SetMaintenance)
+		api.Get("/admin/jobs/dead", h.admin.GetDeadJobs)
+		api.Post("/admin/jobs/dead/:id/requeue", h.mutationLimit, h.admin.RequeueDeadJob)
+		api.Delete("/admin/jobs/dead/:id", h.mutationLimit, h.admin.DiscardDeadJob)
+		api.Get("/admin/archives", h.admin.GetArchives)
+		api.Post("/admin/archives", h.mutationLimit, h.admin.ArchiveUsage)
+
+		// 数据导出
+		api.Get("/export/sqlite", middleware.AdminOnlyMiddleware(), h.admin.ExportSQLite)
+	}
+}
@@ -0,0 +1,150 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix 密文前缀，用于区分明文与密文（便于从明文存储平滑迁移）
+// 格式为 enc:v2:<密钥ID>:<base64>，密钥由HKDF派生，密钥ID用于识别主密钥是否已更换
+const encryptedPrefix = "enc:v2:"
+
+// 密钥派生参数
+var (
+	hkdfSalt      = []byte("cccmu/secrets")
+	hkdfKeyInfo   = []byte("cccmu aes-256-gcm key v2")
+	hkdfKeyIDInfo = []byte("cccmu key id v2")
+)
+
+// Box 基于主密钥的敏感值加解密器（AES-256-GCM）
+// 敏感值仅以密文形式落盘，明文只存在于内存中
+type Box struct {
+	aead  cipher.AEAD // AES-256-GCM加解密器
+	keyID string      // 主密钥ID（不可逆推主密钥）
+}
+
+// NewBox 根据主密钥创建加解密器
+func NewBox(masterKey string) (*Box, error) {
+	if masterKey == "" {
+		return nil, fmt.Errorf("主密钥不能为空")
+	}
+
+	// 使用HKDF-SHA256将任意长度的主密钥派生为32字节AES密钥
+	aead, err := newAEAD(hkdfSHA256([]byte(masterKey), hkdfSalt, hkdfKeyInfo, 32))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{
+		aead:  aead,
+		keyID: hex.EncodeToString(hkdfSHA256([]byte(masterKey), hkdfSalt, hkdfKeyIDInfo, 4)),
+	}, nil
+}
+
+// newAEAD 创建AES-256-GCM加解密器
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建GCM失败: %w", err)
+	}
+	return aead, nil
+}
+
+// hkdfSHA256 按RFC 5869（HKDF-SHA256）派生指定长度的密钥
+func hkdfSHA256(secret, salt, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	var okm, block []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(block)
+		expand.Write(info)
+		expand.Write([]byte{counter})
+		block = expand.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length]
+}
+
+// KeyID 主密钥ID，写入密文用于识别加密时使用的主密钥
+func (b *Box) KeyID() string {
+	if b == nil {
+		return ""
+	}
+	return b.keyID
+}
+
+// IsEncrypted 检查值是否为密文
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt 加密敏感值，空值保持为空；未配置主密钥时原样返回明文
+func (b *Box) Encrypt(plain string) (string, error) {
+	if b == nil || plain == "" {
+		return plain, nil
+	}
+
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	// 密钥ID作为附加数据参与认证，防止被篡改
+	sealed := b.aead.Seal(nonce, nonce, []byte(plain), []byte(b.keyID))
+	return encryptedPrefix + b.keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密敏感值，明文值原样返回（兼容未加密的旧数据）
+func (b *Box) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	if b == nil {
+		return "", fmt.Errorf("数据已加密，但未提供主密钥")
+	}
+
+	keyID, payload, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", fmt.Errorf("密文格式错误")
+	}
+	if keyID != b.keyID {
+		return "", fmt.Errorf("数据使用其他主密钥加密（密钥ID %s，当前 %s），更换主密钥后请使用 --reencrypt 迁移", keyID, b.keyID)
+	}
+	return open(b.aead, payload, []byte(keyID))
+}
+
+// open 解码并解密密文
+func open(aead cipher.AEAD, payload string, additionalData []byte) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("密文格式错误: %w", err)
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("密文长度错误")
+	}
+
+	plain, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+	if err != nil {
+		return "", fmt.Errorf("解密失败，主密钥可能不正确: %w", err)
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestHKDFSHA256(t *testing.T) {
+	// RFC 5869 附录A.1 测试向量
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	want := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+
+	if got := hex.EncodeToString(hkdfSHA256(ikm, salt, info, 42)); got != want {
+		t.Fatalf("hkdfSHA256 = %s, want %s", got, want)
+	}
+}
+
+func TestBoxRoundTrip(t *testing.T) {
+	box, err := NewBox("master-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		plain string
+	}{
+		{"空值", ""},
+		{"Cookie", "session=abc; token=def"},
+		{"Webhook", "https://hooks.example.com/T000/B000/XXXX"},
+		{"中文", "测试数据"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := box.Encrypt(tt.plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.plain != "" && (!IsEncrypted(encrypted) || strings.Contains(encrypted, tt.plain)) {
+				t.Fatalf("Encrypt(%q) = %q，未加密", tt.plain, encrypted)
+			}
+			plain, err := box.Decrypt(encrypted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain != tt.plain {
+				t.Fatalf("Decrypt = %q, want %q", plain, tt.plain)
+			}
+		})
+	}
+}
+
+func TestBoxKeyID(t *testing.T) {
+	oldBox, _ := NewBox("old-key")
+	newBox, _ := NewBox("new-key")
+	sameBox, _ := NewBox("old-key")
+
+	if oldBox.KeyID() != sameBox.KeyID() {
+		t.Fatalf("相同主密钥的密钥ID不一致: %s != %s", oldBox.KeyID(), sameBox.KeyID())
+	}
+	if oldBox.KeyID() == newBox.KeyID() {
+		t.Fatalf("不同主密钥的密钥ID相同: %s", oldBox.KeyID())
+	}
+
+	encrypted, _ := oldBox.Encrypt("secret")
+	if !strings.HasPrefix(encrypted, encryptedPrefix+oldBox.KeyID()+":") {
+		t.Fatalf("密文未包含密钥ID: %s", encrypted)
+	}
+
+	_, err := newBox.Decrypt(encrypted)
+	if err == nil || !strings.Contains(err.Error(), "--reencrypt") {
+		t.Fatalf("更换主密钥后解密应提示重新加密，实际: %v", err)
+	}
+
+	// 篡改密钥ID后认证失败
+	tampered := strings.Replace(encrypted, oldBox.KeyID(), newBox.KeyID(), 1)
+	if _, err := newBox.Decrypt(tampered); err == nil {
+		t.Fatal("篡改密钥ID的密文不应解密成功")
+	}
+}
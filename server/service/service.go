@@ -0,0 +1,52 @@
+// Package service 将cccmu安装为系统后台服务：Windows使用服务管理器，macOS使用launchd
+// Linux请使用systemd托管（参见README）
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 服务名称
+const (
+	Name        = "cccmu"
+	DisplayName = "CCCMU 积分监控"
+	Description = "Claude Code 积分使用监控服务"
+)
+
+// Config 服务安装配置
+type Config struct {
+	Executable string   // 可执行文件绝对路径
+	WorkingDir string   // 工作目录（数据目录 data 位于其下）
+	Args       []string // 启动参数
+}
+
+// NewConfig 以当前可执行文件创建服务安装配置，workingDir为空时使用当前目录
+func NewConfig(workingDir string, args []string) (Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return Config{}, fmt.Errorf("获取可执行文件路径失败: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	if workingDir == "" {
+		workingDir = "."
+	}
+	dir, err := filepath.Abs(workingDir)
+	if err != nil {
+		return Config{}, fmt.Errorf("工作目录无效: %w", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return Config{}, fmt.Errorf("工作目录无效: %s 不是目录", dir)
+	}
+
+	return Config{Executable: exe, WorkingDir: dir, Args: args}, nil
+}
+
+// ServiceArgs 服务启动时的完整参数：先切换到工作目录，再附加用户指定的参数
+func (c Config) ServiceArgs() []string {
+	return append([]string{"--workdir", c.WorkingDir}, c.Args...)
+}
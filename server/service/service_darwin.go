@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// launchdLabel launchd任务标识
+const launchdLabel = "com.leafney.cccmu"
+
+// plistPath 当前用户的LaunchAgent配置文件路径
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户目录失败: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+// Install 安装为当前用户的LaunchAgent（登录后自动启动，退出后自动重启），输出写入工作目录下的 cccmu.log
+func Install(config Config) error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("服务已安装: %s", path)
+	}
+
+	var args strings.Builder
+	for _, arg := range append([]string{config.Executable}, config.ServiceArgs()...) {
+		args.WriteString("\t\t<string>" + xmlEscape(arg) + "</string>\n")
+	}
+	logPath := xmlEscape(filepath.Join(config.WorkingDir, "cccmu.log"))
+
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchdLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+` + args.String() + `	</array>
+	<key>WorkingDirectory</key>
+	<string>` + xmlEscape(config.WorkingDir) + `</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>` + logPath + `</string>
+	<key>StandardErrorPath</key>
+	<string>` + logPath + `</string>
+</dict>
+</plist>
+`
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建LaunchAgents目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return fmt.Errorf("写入服务配置失败: %w", err)
+	}
+	return nil
+}
+
+// Uninstall 停止并删除LaunchAgent
+func Uninstall() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("服务未安装")
+	}
+	_ = launchctl("unload", path)
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("删除服务配置失败: %w", err)
+	}
+	return nil
+}
+
+// Start 加载并启动LaunchAgent
+func Start() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("服务未安装")
+	}
+	return launchctl("load", "-w", path)
+}
+
+// Stop 停止并卸载LaunchAgent（KeepAlive下仅停止进程会被立即重启）
+func Stop() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	return launchctl("unload", path)
+}
+
+// Run launchd通过SIGTERM停止服务，无需额外处理
+func Run() (<-chan struct{}, error) {
+	return nil, nil
+}
+
+// Done launchd下无需通知
+func Done() {}
+
+// launchctl 执行launchctl命令
+func launchctl(args ...string) error {
+	output, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s 失败: %v %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// xmlEscape 转义plist中的字符串值
+func xmlEscape(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
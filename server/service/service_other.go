@@ -0,0 +1,36 @@
+//go:build !windows && !darwin
+
+package service
+
+import "fmt"
+
+// errUnsupported 当前系统不支持service命令
+var errUnsupported = fmt.Errorf("当前系统不支持service命令，Linux请使用systemd托管（参见README）")
+
+// Install 当前系统不支持
+func Install(config Config) error {
+	return errUnsupported
+}
+
+// Uninstall 当前系统不支持
+func Uninstall() error {
+	return errUnsupported
+}
+
+// Start 当前系统不支持
+func Start() error {
+	return errUnsupported
+}
+
+// Stop 当前系统不支持
+func Stop() error {
+	return errUnsupported
+}
+
+// Run 非Windows系统通过信号停止服务，无需额外处理
+func Run() (<-chan struct{}, error) {
+	return nil, nil
+}
+
+// Done 无需通知
+func Done() {}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// 服务控制超时
+const (
+	stopTimeout     = 30 * time.Second // 等待服务停止的最长时间
+	shutdownTimeout = 30 * time.Second // 收到停止请求后等待程序完成关闭的最长时间
+)
+
+// done 程序完成关闭后关闭，通知服务控制处理器上报已停止
+var (
+	done     = make(chan struct{})
+	doneOnce sync.Once
+)
+
+// Install 注册为自动启动的Windows服务（需管理员权限）
+func Install(config Config) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需以管理员身份运行）: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(Name); err == nil {
+		s.Close()
+		return fmt.Errorf("服务已安装")
+	}
+
+	s, err := m.CreateService(Name, config.Executable, mgr.Config{
+		DisplayName: DisplayName,
+		Description: Description,
+		StartType:   mgr.StartAutomatic,
+	}, config.ServiceArgs()...)
+	if err != nil {
+		return fmt.Errorf("创建服务失败: %w", err)
+	}
+	defer s.Close()
+
+	// 异常退出后自动重启
+	recovery := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+	}
+	if err := s.SetRecoveryActions(recovery, 24*60*60); err != nil {
+		return fmt.Errorf("设置服务恢复策略失败: %w", err)
+	}
+	return nil
+}
+
+// Uninstall 停止并删除Windows服务
+func Uninstall() error {
+	_ = Stop()
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需以管理员身份运行）: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return fmt.Errorf("服务未安装")
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("删除服务失败: %w", err)
+	}
+	return nil
+}
+
+// Start 启动Windows服务
+func Start() error {
+	return withService(func(s *mgr.Service) error {
+		if err := s.Start(); err != nil {
+			return fmt.Errorf("启动服务失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Stop 停止Windows服务并等待其退出
+func Stop() error {
+	return withService(func(s *mgr.Service) error {
+		status, err := s.Control(svc.Stop)
+		if err != nil {
+			return fmt.Errorf("停止服务失败: %w", err)
+		}
+		deadline := time.Now().Add(stopTimeout)
+		for status.State != svc.Stopped {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("等待服务停止超时")
+			}
+			time.Sleep(300 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				return fmt.Errorf("查询服务状态失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Run 以Windows服务运行时接管服务控制请求，返回收到停止请求时关闭的通道；非服务方式运行时返回nil
+func Run() (<-chan struct{}, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return nil, fmt.Errorf("检测服务运行方式失败: %w", err)
+	}
+	if !isService {
+		return nil, nil
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		_ = svc.Run(Name, &handler{stop: stop})
+	}()
+	return stop, nil
+}
+
+// Done 程序完成关闭后调用，通知服务管理器服务已停止
+func Done() {
+	doneOnce.Do(func() { close(done) })
+}
+
+// handler Windows服务控制处理器
+type handler struct {
+	stop chan struct{}
+}
+
+// Execute 上报运行状态，收到停止或关机请求时通知程序关闭并等待完成
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+	changes <- svc.Status{State: svc.StartPending}
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for request := range requests {
+		switch request.Cmd {
+		case svc.Interrogate:
+			changes <- request.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			changes <- svc.Status{State: svc.StopPending, WaitHint: uint32(shutdownTimeout / time.Millisecond)}
+			close(h.stop)
+			select {
+			case <-done:
+			case <-time.After(shutdownTimeout):
+			}
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// withService 打开已安装的服务并执行操作
+func withService(fn func(s *mgr.Service) error) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败（需以管理员身份运行）: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return fmt.Errorf("服务未安装")
+	}
+	defer s.Close()
+	return fn(s)
+}
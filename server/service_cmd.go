@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/leafney/cccmu/server/service"
+)
+
+// serviceCommandUsage service子命令用法
+const serviceCommandUsage = `用法: cccmu service <install|uninstall|start|stop> [参数] [-- 服务启动参数]
+
+将cccmu作为后台服务运行（Windows服务、macOS launchd），Linux请使用systemd托管。
+  install    安装服务（Windows需管理员权限），数据目录 data 位于 --dir 指定的工作目录下
+  uninstall  停止并删除服务
+  start      启动服务
+  stop       停止服务
+
+示例:
+  cccmu service install --dir /opt/cccmu -- -p 9090 --log
+`
+
+// runServiceCommand 执行service子命令，返回进程退出码
+func runServiceCommand(args []string) int {
+	flags := pflag.NewFlagSet("service", pflag.ContinueOnError)
+	dir := flags.String("dir", "", "服务工作目录（默认当前目录，仅 install）")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, serviceCommandUsage+"\n参数:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	// "--" 之后的参数作为服务启动参数
+	positional := flags.Args()
+	var serviceArgs []string
+	if dash := flags.ArgsLenAtDash(); dash >= 0 {
+		serviceArgs = positional[dash:]
+		positional = positional[:dash]
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var err error
+	switch action := positional[0]; action {
+	case "install":
+		var config service.Config
+		if config, err = service.NewConfig(*dir, serviceArgs); err == nil {
+			if err = service.Install(config); err == nil {
+				fmt.Printf("✅ 服务已安装，工作目录: %s\n", config.WorkingDir)
+				fmt.Println("💡 执行 cccmu service start 启动服务")
+			}
+		}
+	case "uninstall":
+		if err = service.Uninstall(); err == nil {
+			fmt.Println("✅ 服务已删除")
+		}
+	case "start":
+		if err = service.Start(); err == nil {
+			fmt.Println("✅ 服务已启动")
+		}
+	case "stop":
+		if err = service.Stop(); err == nil {
+			fmt.Println("✅ 服务已停止")
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
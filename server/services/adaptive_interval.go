@@ -0,0 +1,71 @@
+package services
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// targetInterval 按配置和最近一条使用记录的时间计算应使用的获取间隔（秒）（调用方持有锁）
+func (s *SchedulerService) targetInterval() int {
+	_, latest := s.usage.Stats()
+	return s.config.AdaptiveInterval.IntervalFor(s.config.Interval, latest, time.Now())
+}
+
+// currentInterval 当前生效的获取间隔（秒）（调用方持有锁）
+func (s *SchedulerService) currentInterval() int {
+	if s.fetchInterval > 0 {
+		return s.fetchInterval
+	}
+	return s.config.Interval
+}
+
+// adaptInterval 按最新的使用活跃度更新使用数据和积分余额任务的获取间隔，间隔未变化时不处理
+// 每次获取使用数据后调用，也在自适应配置变化后调用；直接更新任务而不重启调度器
+func (s *SchedulerService) adaptInterval() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isRunning || s.usageJob == nil {
+		return
+	}
+	interval := s.targetInterval()
+	if interval == s.fetchInterval {
+		return
+	}
+
+	duration := time.Duration(interval) * time.Second
+	usageJob, err := s.scheduler.Update(
+		s.usageJob.ID(),
+		intervalJob(duration),
+		gocron.NewTask(s.fetchAndSaveData),
+		gocron.WithName(models.JobNameUsage),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		utils.Logf("[自适应间隔] ❌ 更新使用数据任务间隔失败: %v", err)
+		return
+	}
+	s.usageJob = usageJob
+
+	if s.balanceJob != nil {
+		balanceJob, err := s.scheduler.Update(
+			s.balanceJob.ID(),
+			intervalJob(duration),
+			gocron.NewTask(s.fetchAndSaveBalance),
+			gocron.WithName(models.JobNameBalance),
+			gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			gocron.WithStartAt(gocron.WithStartDateTime(time.Now().Add(20*time.Second))),
+		)
+		if err != nil {
+			utils.Logf("[自适应间隔] ⚠️  更新积分余额任务间隔失败: %v", err)
+		} else {
+			s.balanceJob = balanceJob
+		}
+	}
+
+	utils.Logf("[自适应间隔] 🔄 获取间隔 %d秒 -> %d秒", s.fetchInterval, interval)
+	s.fetchInterval = interval
+}
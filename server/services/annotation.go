@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// ErrAnnotationNotFound 指定的标注不存在
+var ErrAnnotationNotFound = errors.New("标注不存在")
+
+// annotationIDAttempts 标注ID冲突（同一纳秒内创建）时顺延重试的次数
+const annotationIDAttempts = 10
+
+// AddAnnotation 保存一条图表标注并推送给所有连接
+func (s *SchedulerService) AddAnnotation(req models.AnnotationRequest) (*models.Annotation, error) {
+	now := time.Now()
+	annotation := &models.Annotation{
+		Time:      now,
+		Text:      req.Text,
+		Color:     req.Color,
+		CreatedAt: now,
+	}
+	if req.Time != nil {
+		annotation.Time = *req.Time
+	}
+
+	var err error
+	for i := 0; i < annotationIDAttempts; i++ {
+		annotation.ID = models.AnnotationID(now.Add(time.Duration(i)))
+		if err = s.db.SaveAnnotation(annotation); !errors.Is(err, database.ErrAnnotationExists) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	utils.Logf("[图表标注] 📌 新建标注 %s: %s", annotation.Time.Local().Format("2006-01-02 15:04:05"), annotation.Text)
+
+	s.notifyAnnotationListeners(models.AnnotationEvent{Action: models.AnnotationCreated, Annotation: *annotation})
+	return annotation, nil
+}
+
+// DeleteAnnotation 删除图表标注并推送给所有连接，标注不存在时返回ErrAnnotationNotFound
+func (s *SchedulerService) DeleteAnnotation(id string) error {
+	annotation, err := s.db.DeleteAnnotation(id)
+	if err != nil {
+		return err
+	}
+	if annotation == nil {
+		return ErrAnnotationNotFound
+	}
+	utils.Logf("[图表标注] 🗑️  删除标注 %s: %s", annotation.Time.Local().Format("2006-01-02 15:04:05"), annotation.Text)
+
+	s.notifyAnnotationListeners(models.AnnotationEvent{Action: models.AnnotationDeleted, Annotation: *annotation})
+	return nil
+}
+
+// AddAnnotationListener 添加标注变化监听器
+func (s *SchedulerService) AddAnnotationListener() chan models.AnnotationEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener := make(chan models.AnnotationEvent, 10)
+	s.annotationListeners = append(s.annotationListeners, listener)
+	return listener
+}
+
+// RemoveAnnotationListener 移除标注变化监听器
+func (s *SchedulerService) RemoveAnnotationListener(listener chan models.AnnotationEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.annotationListeners {
+		if l == listener {
+			close(l)
+			s.annotationListeners = append(s.annotationListeners[:i], s.annotationListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// notifyAnnotationListeners 通知所有标注变化监听器
+func (s *SchedulerService) notifyAnnotationListeners(event models.AnnotationEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, listener := range s.annotationListeners {
+		select {
+		case listener <- event:
+			// 事件发送成功
+		default:
+			// 通道已满，跳过通知
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// DefaultArchiveDir 默认归档目录（位于数据目录下）
+const DefaultArchiveDir = "./data/archive"
+
+// 归档文件名格式：<数据类型>-YYYY-MM.ndjson.gz
+const (
+	archiveKindUsage  = "usage" // 原始使用记录
+	archiveFileSuffix = ".ndjson.gz"
+)
+
+// archiveKinds 归档文件的数据类型
+var archiveKinds = []string{
+	archiveKindUsage,
+	database.ArchiveKindDailyUsage,
+	database.ArchiveKindResets,
+	database.ArchiveKindBalance,
+	database.ArchiveKindAggregate,
+}
+
+// UsageArchiver 数据归档服务
+// 将即将从数据库移除的原始记录（以及启用到期归档的其他数据）按月追加写入gzip压缩的NDJSON文件
+// （每次追加一个gzip成员，标准工具可直接解压），写入成功后再从数据库删除，保证数据不丢失
+type UsageArchiver struct {
+	db  *database.BadgerDB
+	dir string
+	mu  sync.Mutex
+}
+
+// NewUsageArchiver 创建归档服务
+func NewUsageArchiver(db *database.BadgerDB, dir string) *UsageArchiver {
+	return &UsageArchiver{
+		db:  db,
+		dir: dir,
+	}
+}
+
+// ArchiveCompletedMonths 归档所有已结束月份的原始使用记录，随后降采样并从数据库删除
+// 当月记录留在数据库中，月份结束后整月归档，归档文件不会包含未结束的月份
+func (a *UsageArchiver) ArchiveCompletedMonths() (*models.UsageArchiveResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := currentMonthStart(time.Now())
+
+	records, err := a.db.GetUnaggregatedUsage(cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("读取待归档记录失败: %w", err)
+	}
+
+	byMonth := make(map[string]models.UsageDataList)
+	for _, record := range records {
+		month := record.CreatedAt.Local().Format("2006-01")
+		byMonth[month] = append(byMonth[month], record)
+	}
+
+	result := &models.UsageArchiveResult{Records: len(records), Months: make([]string, 0, len(byMonth))}
+	for month := range byMonth {
+		result.Months = append(result.Months, month)
+	}
+	sort.Strings(result.Months)
+
+	if len(records) > 0 {
+		if err := os.MkdirAll(a.dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建归档目录失败: %w", err)
+		}
+		for _, month := range result.Months {
+			if err := appendArchive(a.dir, archiveKindUsage, month, byMonth[month]); err != nil {
+				return nil, fmt.Errorf("写入%s归档失败: %w", month, err)
+			}
+		}
+	}
+
+	// 归档写入成功后再从数据库移除（同时保留15分钟聚合用于长期趋势），只删除已写入归档的记录
+	if _, err := a.db.DownsampleArchivedUsage(cutoff, records); err != nil {
+		return nil, fmt.Errorf("移除已归档记录失败: %w", err)
+	}
+
+	if len(records) > 0 {
+		utils.Logf("[数据归档] 已归档%d条原始记录，月份: %s", len(records), strings.Join(result.Months, ", "))
+	}
+	return result, nil
+}
+
+// ArchiveExpired 将启用到期归档的数据类型中已超过保留天数的记录按月写入归档后从数据库删除，返回归档的记录数
+func (a *UsageArchiver) ArchiveExpired(retention models.RetentionConfig) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	expired, err := a.db.GetExpiredRecords(retention, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, kind := range archiveKinds {
+		records := expired[kind]
+		if len(records) == 0 {
+			continue
+		}
+		if err := os.MkdirAll(a.dir, 0755); err != nil {
+			return total, fmt.Errorf("创建归档目录失败: %w", err)
+		}
+
+		byMonth := make(map[string][]json.RawMessage)
+		var months []string
+		for _, record := range records {
+			month := record.Time.Local().Format("2006-01")
+			if _, ok := byMonth[month]; !ok {
+				months = append(months, month)
+			}
+			byMonth[month] = append(byMonth[month], record.Value)
+		}
+		sort.Strings(months)
+		for _, month := range months {
+			if err := appendArchive(a.dir, kind, month, byMonth[month]); err != nil {
+				return total, fmt.Errorf("写入%s归档%s失败: %w", kind, month, err)
+			}
+		}
+
+		// 归档写入成功后再删除
+		if err := a.db.DeleteExpiredRecords(records); err != nil {
+			return total, err
+		}
+		total += len(records)
+		utils.Logf("[数据归档] 已归档%d条到期的%s数据，月份: %s", len(records), kind, strings.Join(months, ", "))
+	}
+	return total, nil
+}
+
+// ListArchives 列出已有的归档文件（按月份升序）
+func (a *UsageArchiver) ListArchives() ([]models.UsageArchiveFile, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.UsageArchiveFile{}, nil
+		}
+		return nil, err
+	}
+
+	files := make([]models.UsageArchiveFile, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		kind, month, ok := parseArchiveName(name)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, models.UsageArchiveFile{
+			Name:       name,
+			Kind:       kind,
+			Month:      month,
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Month != files[j].Month {
+			return files[i].Month < files[j].Month
+		}
+		return files[i].Kind < files[j].Kind
+	})
+	return files, nil
+}
+
+// parseArchiveName 从归档文件名解析数据类型和月份
+func parseArchiveName(name string) (string, string, bool) {
+	if !strings.HasSuffix(name, archiveFileSuffix) {
+		return "", "", false
+	}
+	for _, kind := range archiveKinds {
+		if month, ok := strings.CutPrefix(strings.TrimSuffix(name, archiveFileSuffix), kind+"-"); ok {
+			return kind, month, true
+		}
+	}
+	return "", "", false
+}
+
+// appendArchive 将一个月的记录作为新的gzip成员追加到该数据类型的月度归档文件
+func appendArchive[T any](dir, kind, month string, records []T) error {
+	path := filepath.Join(dir, kind+"-"+month+archiveFileSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	writer := bufio.NewWriter(gz)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// currentMonthStart 获取本地时区当月第一天零点
+func currentMonthStart(now time.Time) time.Time {
+	now = now.Local()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -18,8 +19,18 @@ const (
 	JobTypeScheduler    ConfigUpdateJobType = "scheduler"
 	JobTypeAutoSchedule ConfigUpdateJobType = "auto_schedule"
 	JobTypeAutoReset    ConfigUpdateJobType = "auto_reset"
+	JobTypeKeepAlive    ConfigUpdateJobType = "keep_alive"
 )
 
+// 失败重试参数：最多执行3次，重试间隔从2秒开始逐次翻倍
+const (
+	maxJobAttempts    = 3
+	jobRetryBaseDelay = 2 * time.Second
+)
+
+// ErrDeadJobNotFound 死信列表中不存在指定任务
+var ErrDeadJobNotFound = errors.New("死信任务不存在")
+
 // ConfigUpdateJob 配置更新任务
 type ConfigUpdateJob struct {
 	ID        string              `json:"id"`
@@ -27,6 +38,7 @@ type ConfigUpdateJob struct {
 	OldConfig interface{}         `json:"-"`
 	NewConfig interface{}         `json:"-"`
 	CreatedAt time.Time           `json:"created_at"`
+	Attempts  int                 `json:"attempts"` // 已失败的次数
 }
 
 // AsyncConfigUpdater 异步配置更新服务
@@ -36,6 +48,7 @@ type AsyncConfigUpdater struct {
 	scheduler        *SchedulerService
 	autoScheduler    *AutoSchedulerService
 	autoResetService *AutoResetService
+	keepAliveService *KeepAliveService
 	db               *database.BadgerDB
 
 	// 错误通知回调
@@ -87,6 +100,13 @@ func NewAsyncConfigUpdater(
 	return updater
 }
 
+// SetKeepAliveService 设置Cookie保活服务引用
+func (a *AsyncConfigUpdater) SetKeepAliveService(keepAliveService *KeepAliveService) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keepAliveService = keepAliveService
+}
+
 // SetErrorCallback 设置错误回调
 func (a *AsyncConfigUpdater) SetErrorCallback(callback func(ConfigUpdateJobType, string, error)) {
 	a.onError = callback
@@ -114,9 +134,98 @@ func (a *AsyncConfigUpdater) Start() error {
 
 	a.isRunning = true
 	log.Printf("[异步配置] 异步配置更新服务已启动，工作协程数: %d", a.workers)
+
+	// 重放上次退出前尚未处理完的任务
+	a.replayPendingJobs()
 	return nil
 }
 
+// replayPendingJobs 重放持久化的待处理任务（内部方法，调用方持有锁）
+// 同类任务只需执行最新的一个：重放时统一应用数据库中已保存的配置，重复执行结果一致
+func (a *AsyncConfigUpdater) replayPendingJobs() {
+	pending, err := a.db.GetPendingConfigJobs()
+	if err != nil {
+		log.Printf("[异步配置] 读取待处理任务失败: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	latest := make(map[string]models.PendingConfigJob)
+	for _, record := range pending {
+		if previous, ok := latest[record.Type]; ok {
+			a.removePersistedJob(previous.ID)
+		}
+		latest[record.Type] = record
+	}
+
+	config, err := a.db.GetConfig()
+	if err != nil {
+		log.Printf("[异步配置] 读取配置失败，放弃重放 %d 个待处理任务: %v", len(latest), err)
+		return
+	}
+
+	for _, record := range pending {
+		if latest[record.Type].ID != record.ID {
+			continue
+		}
+
+		job, err := jobFromSavedConfig(record, config)
+		if err != nil {
+			log.Printf("[异步配置] 无法重放任务 %s: %v", record.ID, err)
+			a.removePersistedJob(record.ID)
+			continue
+		}
+
+		select {
+		case a.jobQueue <- job:
+			log.Printf("[异步配置] 重放待处理任务: %s (类型: %s)", job.ID, job.Type)
+		default:
+			log.Printf("[异步配置] 任务队列已满，任务 %s 将在下次启动时重放", job.ID)
+		}
+	}
+}
+
+// jobFromSavedConfig 根据持久化记录和已保存的配置重建任务
+func jobFromSavedConfig(record models.PendingConfigJob, config *models.UserConfig) (ConfigUpdateJob, error) {
+	job := ConfigUpdateJob{
+		ID:        record.ID,
+		Type:      ConfigUpdateJobType(record.Type),
+		CreatedAt: record.CreatedAt,
+		Attempts:  record.Attempts,
+	}
+
+	switch job.Type {
+	case JobTypeScheduler:
+		job.OldConfig, job.NewConfig = config, config
+	case JobTypeAutoSchedule:
+		job.OldConfig, job.NewConfig = &config.AutoSchedule, &config.AutoSchedule
+	case JobTypeAutoReset:
+		job.OldConfig, job.NewConfig = &config.AutoReset, &config.AutoReset
+	case JobTypeKeepAlive:
+		job.OldConfig, job.NewConfig = &config.KeepAlive, &config.KeepAlive
+	default:
+		return job, fmt.Errorf("未知的任务类型: %s", record.Type)
+	}
+	return job, nil
+}
+
+// persistJob 持久化任务，服务重启后可重放
+func (a *AsyncConfigUpdater) persistJob(job ConfigUpdateJob) {
+	record := &models.PendingConfigJob{ID: job.ID, Type: string(job.Type), CreatedAt: job.CreatedAt, Attempts: job.Attempts}
+	if err := a.db.SavePendingConfigJob(record); err != nil {
+		log.Printf("[异步配置] 持久化任务 %s 失败: %v", job.ID, err)
+	}
+}
+
+// removePersistedJob 删除已处理任务的持久化记录
+func (a *AsyncConfigUpdater) removePersistedJob(jobID string) {
+	if err := a.db.DeletePendingConfigJob(jobID); err != nil {
+		log.Printf("[异步配置] 删除任务 %s 的持久化记录失败: %v", jobID, err)
+	}
+}
+
 // Stop 停止异步更新服务
 func (a *AsyncConfigUpdater) Stop() error {
 	a.mu.Lock()
@@ -160,11 +269,16 @@ func (a *AsyncConfigUpdater) SubmitJob(jobType ConfigUpdateJobType, oldConfig, n
 		CreatedAt: time.Now(),
 	}
 
+	// 先持久化再入队，避免进程在任务处理前退出导致任务丢失
+	a.persistJob(job)
+
 	select {
 	case a.jobQueue <- job:
 		log.Printf("[异步配置] 任务已提交: %s (类型: %s)", jobID, jobType)
 		return jobID, nil
 	case <-time.After(5 * time.Second):
+		// 提交失败时调用方会降级为同步处理，无需重放
+		a.removePersistedJob(jobID)
 		return "", fmt.Errorf("提交任务超时，任务队列可能已满")
 	}
 }
@@ -192,48 +306,166 @@ func (a *AsyncConfigUpdater) worker(workerID int) {
 	}
 }
 
-// processJob 处理配置更新任务
+// processJob 处理配置更新任务，失败时按退避间隔重试，多次失败后移入死信列表
 func (a *AsyncConfigUpdater) processJob(workerID int, job ConfigUpdateJob) {
 	startTime := time.Now()
+	err := a.runJob(workerID, job)
+	duration := time.Since(startTime)
 
+	if err != nil {
+		log.Printf("[异步配置] 工作协程 #%d 任务 %s 第%d次处理失败 (耗时: %v): %v",
+			workerID, job.ID, job.Attempts+1, duration, err)
+		a.handleJobFailure(job, err)
+		return
+	}
+
+	log.Printf("[异步配置] 工作协程 #%d 任务 %s 处理成功 (耗时: %v)",
+		workerID, job.ID, duration)
+	a.removePersistedJob(job.ID)
+	if a.onSuccess != nil {
+		a.onSuccess(job.Type, job.ID)
+	}
+}
+
+// runJob 执行任务，panic视为处理失败
+func (a *AsyncConfigUpdater) runJob(workerID int, job ConfigUpdateJob) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err := fmt.Errorf("任务处理发生panic: %v", r)
 			log.Printf("[异步配置] 工作协程 #%d 任务 %s 发生panic: %v", workerID, job.ID, r)
-			if a.onError != nil {
-				a.onError(job.Type, job.ID, err)
-			}
+			err = fmt.Errorf("任务处理发生panic: %v", r)
 		}
 	}()
 
-	var err error
-
 	switch job.Type {
 	case JobTypeScheduler:
-		err = a.processSchedulerJob(job)
+		return a.processSchedulerJob(job)
 	case JobTypeAutoSchedule:
-		err = a.processAutoScheduleJob(job)
+		return a.processAutoScheduleJob(job)
 	case JobTypeAutoReset:
-		err = a.processAutoResetJob(job)
+		return a.processAutoResetJob(job)
+	case JobTypeKeepAlive:
+		return a.processKeepAliveJob(job)
 	default:
-		err = fmt.Errorf("未知的任务类型: %s", job.Type)
+		return fmt.Errorf("未知的任务类型: %s", job.Type)
 	}
+}
 
-	duration := time.Since(startTime)
+// handleJobFailure 安排任务重试；达到最大次数后移入死信列表并通知前端
+func (a *AsyncConfigUpdater) handleJobFailure(job ConfigUpdateJob, jobErr error) {
+	job.Attempts++
 
-	if err != nil {
-		log.Printf("[异步配置] 工作协程 #%d 任务 %s 处理失败 (耗时: %v): %v",
-			workerID, job.ID, duration, err)
-		if a.onError != nil {
-			a.onError(job.Type, job.ID, err)
-		}
+	if job.Attempts < maxJobAttempts {
+		// 记录失败次数，重试前退出时下次启动从该次数继续
+		a.persistJob(job)
+
+		delay := jobRetryBaseDelay << (job.Attempts - 1)
+		log.Printf("[异步配置] 任务 %s 将在 %v 后重试 (%d/%d)", job.ID, delay, job.Attempts+1, maxJobAttempts)
+		time.AfterFunc(delay, func() { a.requeue(job) })
+		return
+	}
+
+	deadJob := &models.DeadConfigJob{
+		ID:        job.ID,
+		Type:      string(job.Type),
+		CreatedAt: job.CreatedAt,
+		Attempts:  job.Attempts,
+		LastError: jobErr.Error(),
+		FailedAt:  time.Now(),
+	}
+	if err := a.db.MoveConfigJobToDeadLetter(deadJob); err != nil {
+		log.Printf("[异步配置] 任务 %s 移入死信列表失败: %v", job.ID, err)
 	} else {
-		log.Printf("[异步配置] 工作协程 #%d 任务 %s 处理成功 (耗时: %v)",
-			workerID, job.ID, duration)
-		if a.onSuccess != nil {
-			a.onSuccess(job.Type, job.ID)
+		log.Printf("[异步配置] 任务 %s 已失败%d次，移入死信列表", job.ID, job.Attempts)
+	}
+
+	if a.onError != nil {
+		a.onError(job.Type, job.ID, jobErr)
+	}
+}
+
+// requeue 将待重试的任务重新放入队列；服务已停止或队列已满时保留持久化记录，下次启动时重放
+func (a *AsyncConfigUpdater) requeue(job ConfigUpdateJob) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning {
+		return
+	}
+
+	select {
+	case a.jobQueue <- job:
+	default:
+		log.Printf("[异步配置] 任务队列已满，任务 %s 将在下次启动时重放", job.ID)
+	}
+}
+
+// GetDeadJobs 获取死信列表中的任务
+func (a *AsyncConfigUpdater) GetDeadJobs() ([]models.DeadConfigJob, error) {
+	return a.db.GetDeadConfigJobs()
+}
+
+// RequeueDeadJob 将死信任务重新加入队列（按当前已保存的配置执行，失败次数清零）
+func (a *AsyncConfigUpdater) RequeueDeadJob(id string) error {
+	deadJob, err := a.db.GetDeadConfigJob(id)
+	if err != nil {
+		return err
+	}
+	if deadJob == nil {
+		return ErrDeadJobNotFound
+	}
+
+	config, err := a.db.GetConfig()
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+
+	job, err := jobFromSavedConfig(models.PendingConfigJob{ID: deadJob.ID, Type: deadJob.Type, CreatedAt: deadJob.CreatedAt}, config)
+	if err != nil {
+		return err
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning {
+		return fmt.Errorf("异步配置更新服务未运行")
+	}
+
+	// 先移出死信列表再入队，避免任务再次失败后写入的死信记录被误删
+	a.persistJob(job)
+	if err := a.db.DeleteDeadConfigJob(id); err != nil {
+		a.removePersistedJob(job.ID)
+		return err
+	}
+
+	select {
+	case a.jobQueue <- job:
+	default:
+		if err := a.db.MoveConfigJobToDeadLetter(deadJob); err != nil {
+			log.Printf("[异步配置] 恢复死信任务 %s 失败: %v", id, err)
 		}
+		return fmt.Errorf("任务队列已满，请稍后重试")
+	}
+
+	log.Printf("[异步配置] 死信任务已重新入队: %s (类型: %s)", job.ID, job.Type)
+	return nil
+}
+
+// DiscardDeadJob 从死信列表中删除任务
+func (a *AsyncConfigUpdater) DiscardDeadJob(id string) error {
+	deadJob, err := a.db.GetDeadConfigJob(id)
+	if err != nil {
+		return err
+	}
+	if deadJob == nil {
+		return ErrDeadJobNotFound
+	}
+
+	if err := a.db.DeleteDeadConfigJob(id); err != nil {
+		return err
 	}
+	log.Printf("[异步配置] 死信任务已丢弃: %s (类型: %s)", id, deadJob.Type)
+	return nil
 }
 
 // processSchedulerJob 处理调度器配置更新任务
@@ -281,6 +513,25 @@ func (a *AsyncConfigUpdater) processAutoResetJob(job ConfigUpdateJob) error {
 	return nil
 }
 
+// processKeepAliveJob 处理Cookie保活配置更新任务
+func (a *AsyncConfigUpdater) processKeepAliveJob(job ConfigUpdateJob) error {
+	newConfig, ok := job.NewConfig.(*models.KeepAliveConfig)
+	if !ok {
+		return fmt.Errorf("Cookie保活任务配置类型错误")
+	}
+
+	log.Printf("[异步配置] 开始处理Cookie保活配置更新任务")
+
+	a.mu.RLock()
+	keepAliveService := a.keepAliveService
+	a.mu.RUnlock()
+
+	if keepAliveService != nil {
+		return keepAliveService.UpdateConfig(newConfig)
+	}
+	return nil
+}
+
 // GetQueueSize 获取当前队列大小
 func (a *AsyncConfigUpdater) GetQueueSize() int {
 	return len(a.jobQueue)
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strconv"
@@ -9,30 +10,29 @@ import (
 	"time"
 
 	"github.com/go-co-op/gocron/v2"
-	"github.com/leafney/cccmu/server/client"
 	"github.com/leafney/cccmu/server/database"
 	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/strategy"
 	"github.com/leafney/cccmu/server/utils"
 )
 
 // AutoResetService 自动重置服务
 type AutoResetService struct {
-	scheduler          gocron.Scheduler        // 时间任务调度器
-	resetJob           gocron.Job              // 重置任务
-	config             *models.AutoResetConfig // 当前配置
-	db                 *database.BadgerDB      // 数据库访问
-	schedulerSvc       *SchedulerService       // 调度器服务（用于通知和重置）
-	mu                 sync.RWMutex            // 并发保护
-	tasksCreated       bool                    // 标记任务是否已创建
-	tasksRunning       bool                    // 标记任务是否正在运行
-	thresholdScheduler gocron.Scheduler        // 阈值检查专用调度器
-	thresholdJob       gocron.Job              // 阈值检查任务
-	thresholdRunning   bool                    // 阈值任务运行状态
-	apiClient          *client.ClaudeAPIClient // API客户端实例
-
-	// 动态时间范围管理
-	thresholdTimerJob gocron.Job // 时间范围管理任务
-	thresholdActive   bool       // 当前是否在阈值检查时间范围内
+	scheduler        gocron.Scheduler           // 时间任务调度器
+	resetJob         gocron.Job                 // 重置任务
+	config           *models.AutoResetConfig    // 当前配置
+	program          *strategy.Program          // 当前配置中编译后的自定义策略（未设置策略时为nil）
+	db               *database.BadgerDB         // 数据库访问
+	schedulerSvc     *SchedulerService          // 调度器服务（用于通知和重置）
+	mu               sync.RWMutex               // 并发保护
+	tasksCreated     bool                       // 标记任务是否已创建
+	tasksRunning     bool                       // 标记任务是否正在运行
+	thresholdRunning bool                       // 阈值检查是否已订阅积分余额
+	balanceListener  chan *models.CreditBalance // 阈值检查订阅的积分余额通道
+
+	resetLock ResetLock // 多实例重置锁（可选，未设置时不做协调）
+
+	thresholdFiredAt time.Time // 非重置类阈值动作最近一次执行时间（每个重置周期仅执行一次）
 }
 
 // NewAutoResetService 创建自动重置服务
@@ -43,24 +43,30 @@ func NewAutoResetService(db *database.BadgerDB, schedulerSvc *SchedulerService)
 		return nil
 	}
 
-	// 创建阈值检查专用调度器
-	thresholdScheduler, err := gocron.NewScheduler()
-	if err != nil {
-		utils.Logf("[自动重置] 创建阈值调度器失败: %v", err)
-		return nil
-	}
-
 	return &AutoResetService{
-		scheduler:          scheduler,
-		thresholdScheduler: thresholdScheduler,
-		db:                 db,
-		schedulerSvc:       schedulerSvc,
-		tasksCreated:       false,
-		tasksRunning:       false,
-		thresholdRunning:   false,
+		scheduler:        scheduler,
+		db:               db,
+		schedulerSvc:     schedulerSvc,
+		tasksCreated:     false,
+		tasksRunning:     false,
+		thresholdRunning: false,
 	}
 }
 
+// SetResetLock 设置多实例重置锁，设置后仅获得当日重置权的实例执行自动重置
+func (s *AutoResetService) SetResetLock(lock ResetLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLock = lock
+}
+
+// getResetLock 获取多实例重置锁
+func (s *AutoResetService) getResetLock() ResetLock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resetLock
+}
+
 // UpdateConfig 更新自动重置配置
 func (s *AutoResetService) UpdateConfig(config *models.AutoResetConfig) error {
 	s.mu.Lock()
@@ -68,11 +74,23 @@ func (s *AutoResetService) UpdateConfig(config *models.AutoResetConfig) error {
 
 	oldConfig := s.config
 	s.config = config
+	s.program = nil
+	if config.HasStrategy() {
+		// 保存配置时已校验，此处编译一次供每次阈值检查复用
+		program, err := strategy.Compile(config.Strategy)
+		if err != nil {
+			utils.Logf("[自动重置] 编译自定义策略失败: %v", err)
+		}
+		s.program = program
+	}
 
 	utils.Logf("[自动重置] 配置更新:")
 	utils.Logf("[自动重置] - 启用状态: %v", config.Enabled)
 	utils.Logf("[自动重置] - 时间触发条件: %v", config.TimeEnabled)
 	utils.Logf("[自动重置] - 阈值触发条件: %v", config.ThresholdEnabled)
+	if config.HasStrategy() {
+		utils.Logf("[自动重置] - 自定义策略: %s", config.Strategy)
+	}
 
 	if config.Enabled && config.TimeEnabled && config.ResetTime != "" {
 		utils.Logf("[自动重置] - 重置时间: %s", config.ResetTime)
@@ -83,6 +101,9 @@ func (s *AutoResetService) UpdateConfig(config *models.AutoResetConfig) error {
 		if config.ThresholdTimeEnabled && config.ThresholdStartTime != "" && config.ThresholdEndTime != "" {
 			utils.Logf("[自动重置] - 阈值检查时间: %s-%s", config.ThresholdStartTime, config.ThresholdEndTime)
 		}
+		if config.ThresholdRearm {
+			utils.Logf("[自动重置] - 余额回升后重新启用阈值检查")
+		}
 	}
 
 	// 判断启用状态是否变化
@@ -97,10 +118,11 @@ func (s *AutoResetService) UpdateConfig(config *models.AutoResetConfig) error {
 		oldConfig.Threshold != config.Threshold ||
 		oldConfig.ThresholdTimeEnabled != config.ThresholdTimeEnabled ||
 		oldConfig.ThresholdStartTime != config.ThresholdStartTime ||
-		oldConfig.ThresholdEndTime != config.ThresholdEndTime)
+		oldConfig.ThresholdEndTime != config.ThresholdEndTime ||
+		oldConfig.Strategy != config.Strategy)
 
-	// 处理阈值触发任务
-	if config.Enabled && config.ThresholdEnabled {
+	// 处理阈值触发任务（自定义策略复用阈值检查任务）
+	if config.NeedsBalanceCheck() {
 		if !s.thresholdRunning || thresholdConfigChanged {
 			utils.Logf("[自动重置] 启动/重启阈值触发任务")
 			if s.thresholdRunning {
@@ -160,6 +182,13 @@ func (s *AutoResetService) Start() error {
 		log.Printf("[自动重置] 启动时自动重置未启用")
 	}
 
+	// 启用了阈值触发或自定义策略时订阅积分余额
+	if s.config.NeedsBalanceCheck() {
+		if err := s.startThresholdTask(); err != nil {
+			log.Printf("[自动重置] 启动阈值检查失败: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -170,10 +199,8 @@ func (s *AutoResetService) Stop() error {
 
 	s.stopTasks()
 
-	// 停止阈值任务
-	if s.thresholdRunning {
-		s.stopThresholdTaskInternal()
-	}
+	// 停止阈值检查
+	s.stopThresholdTask()
 
 	// 关闭调度器
 	if s.scheduler != nil {
@@ -181,12 +208,6 @@ func (s *AutoResetService) Stop() error {
 		s.scheduler.Shutdown()
 	}
 
-	// 关闭阈值调度器
-	if s.thresholdScheduler != nil {
-		utils.Logf("[自动重置] 关闭阈值调度器")
-		s.thresholdScheduler.Shutdown()
-	}
-
 	return nil
 }
 
@@ -209,14 +230,6 @@ func (s *AutoResetService) GetConfig() *models.AutoResetConfig {
 	return s.config
 }
 
-// IsThresholdTaskRunning 检查阈值触发任务是否正在运行
-func (s *AutoResetService) IsThresholdTaskRunning() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	return s.thresholdRunning
-}
-
 // generateCronExpression 根据时间字符串生成cron表达式
 // timeStr格式: "HH:MM" (如 "18:30")
 // 返回格式: "MM HH * * *" (分 时 日 月 星期)
@@ -240,14 +253,14 @@ func (s *AutoResetService) generateCronExpression(timeStr string) (string, error
 	return fmt.Sprintf("%d %d * * *", minute, hour), nil
 }
 
-// isAlreadyReset 检查今日是否已重置过（复用现有的DailyResetUsed字段）
+// isAlreadyReset 检查当前重置周期内是否已重置过（复用现有的DailyResetUsed字段，按配置的重置周期判断）
 func (s *AutoResetService) isAlreadyReset() bool {
 	config, err := s.db.GetConfig()
 	if err != nil {
 		log.Printf("[自动重置] 获取配置失败: %v", err)
 		return true // 获取失败时跳过重置
 	}
-	return config.DailyResetUsed
+	return config.IsDailyResetUsed(time.Now())
 }
 
 // createTimeJob 创建时间触发任务
@@ -345,7 +358,12 @@ func (s *AutoResetService) handleTimeResetTask() {
 	now := time.Now()
 	log.Printf("[自动重置] 🚀 时间触发任务执行!")
 	log.Printf("[自动重置]   ⏰ 触发时间: %s", now.Format("2006-01-02 15:04:05"))
-	log.Printf("[自动重置]   📋 配置时间: %s", s.config.ResetTime)
+	log.Printf("[自动重置]   📋 配置时间: %s", s.GetConfig().ResetTime)
+
+	if s.schedulerSvc.IsInMaintenance() {
+		log.Printf("[自动重置]   🚧 维护模式中，跳过时间触发的自动重置")
+		return
+	}
 
 	// 检查今日是否已重置（手动或自动）
 	if s.isAlreadyReset() {
@@ -357,276 +375,248 @@ func (s *AutoResetService) handleTimeResetTask() {
 	s.executeAutoReset("time_trigger")
 }
 
-// handleThresholdCheckTask 处理阈值检查任务（仅在时间范围内执行的版本）
-func (s *AutoResetService) handleThresholdCheckTask() {
+// handleThresholdCheck 对监控任务取得的积分余额进行阈值检查（设置了检查时间范围时仅在范围内检查）
+func (s *AutoResetService) handleThresholdCheck(balance *models.CreditBalance) {
+	s.mu.RLock()
+	config := s.config
+	program := s.program
+	s.mu.RUnlock()
+	if config == nil || !config.NeedsBalanceCheck() {
+		return
+	}
+
 	now := time.Now()
-	utils.Logf("[阈值触发] 🔍 执行阈值检查任务")
-	utils.Logf("[阈值触发]   ⏰ 检查时间: %s", now.Format("2006-01-02 15:04:05"))
+	if config.ThresholdTimeEnabled && !config.IsInThresholdTimeRange(now) {
+		return
+	}
 
-	// 检查今日是否已重置
-	if s.isAlreadyReset() {
-		utils.Logf("[阈值触发] ✅ 今日已重置，任务目标达成，提前结束阈值检查")
-		s.deactivateThresholdCheck()
+	if s.schedulerSvc.IsInMaintenance() {
+		utils.Logf("[阈值触发] 🚧 维护模式中，跳过本次阈值检查")
 		return
 	}
 
-	// 获取积分余额（使用现有缓存逻辑）
-	balance, err := s.apiClient.FetchCreditBalance()
-	if err != nil {
-		utils.Logf("[阈值触发]   ❌ 获取积分余额失败: %v", err)
+	// 检查今日是否已重置（自定义策略可能在重置后仍需通知，启用重新布防时需等待余额回升，继续检查）
+	if !config.HasStrategy() && !config.ThresholdRearm && s.isAlreadyReset() {
 		return
 	}
 
+	utils.Logf("[阈值触发] 🔍 执行阈值检查")
 	utils.Logf("[阈值触发]   💰 当前积分余额: %d", balance.Remaining)
-	utils.Logf("[阈值触发]   🎯 设定阈值: %d", s.config.Threshold)
+	utils.Logf("[阈值触发]   🎯 设定阈值: %d", config.Threshold)
 
-	// 通过SchedulerService推送积分到前端（SSE）
-	s.schedulerSvc.NotifyBalanceUpdate(balance)
-	utils.Logf("[阈值触发]   📡 已推送积分余额到前端")
+	// 设置了自定义策略时由策略决定，不再比较阈值
+	if config.HasStrategy() {
+		s.executeStrategy(config, program, balance)
+		return
+	}
 
 	// 判断是否低于阈值
-	if balance.Remaining > s.config.Threshold {
-		utils.Logf("[阈值触发]   ✅ 积分余额充足，无需重置 (%d > %d)", balance.Remaining, s.config.Threshold)
+	if balance.Remaining > config.Threshold {
+		utils.Logf("[阈值触发]   ✅ 积分余额充足，无需重置 (%d > %d)", balance.Remaining, config.Threshold)
+		if config.ThresholdRearm {
+			s.rearmThreshold()
+		}
 		return
 	}
 
-	utils.Logf("[阈值触发]   🚨 积分余额低于阈值 (%d <= %d)，准备触发重置", balance.Remaining, s.config.Threshold)
-	s.executeAutoReset("threshold_trigger")
+	s.executeThresholdAction(config, balance)
 }
 
-// createThresholdJob 创建阈值检查任务
-func (s *AutoResetService) createThresholdJob() error {
-	utils.Logf("[阈值触发] 🔨 创建阈值检查任务")
-	utils.Logf("[阈值触发]   📋 检查间隔: 30秒")
-	utils.Logf("[阈值触发]   🎯 触发阈值: %d", s.config.Threshold)
-
-	job, err := s.thresholdScheduler.NewJob(
-		gocron.DurationJob(30*time.Second),
-		gocron.NewTask(s.handleThresholdCheckTask),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-	)
-	if err != nil {
-		utils.Logf("[阈值触发] ❌ 创建阈值检查任务失败: %v", err)
-		return err
+// executeThresholdAction 执行积分低于阈值时配置的动作
+// 重置积分受每日重置限制约束；通知、停止监控和Webhook在每个重置周期内仅执行一次
+// config为阈值检查开始时取得的配置快照，执行期间配置更新不影响本次动作
+func (s *AutoResetService) executeThresholdAction(config *models.AutoResetConfig, balance *models.CreditBalance) {
+	action := config.GetThresholdAction()
+	if action == models.ThresholdActionReset && config.ThresholdRearm && s.isAlreadyReset() {
+		// 重新布防后再次低于阈值，但本周期已没有剩余的重置次数，改为推送通知
+		now := time.Now()
+		if !s.claimThresholdAction(now) {
+			utils.Logf("[阈值触发]   ⏭️  本周期重置次数已用完，且已推送过通知，跳过")
+			return
+		}
+		utils.Logf("[阈值触发]   🚨 积分余额再次低于阈值 (%d <= %d)，本周期重置次数已用完，推送通知", balance.Remaining, config.Threshold)
+		s.notifyThreshold("积分再次低于阈值", fmt.Sprintf("当前积分余额 %d，低于阈值 %d，本周期已没有剩余的重置次数", balance.Remaining, config.Threshold), now)
+		return
 	}
-
-	s.thresholdJob = job
-	utils.Logf("[阈值触发] ✅ 阈值检查任务创建成功, ID: %v", job.ID())
-	return nil
-}
-
-// startThresholdTask 启动阈值检查任务
-func (s *AutoResetService) startThresholdTask() error {
-	utils.Logf("[阈值触发] 🚀 启动阈值检查任务")
-	utils.Logf("[阈值触发]   🎯 阈值设置: %d", s.config.Threshold)
-
-	// 获取API客户端实例
-	config, err := s.db.GetConfig()
-	if err != nil {
-		utils.Logf("[阈值触发] ❌ 获取配置失败: %v", err)
-		return err
+	if action == models.ThresholdActionReset {
+		utils.Logf("[阈值触发]   🚨 积分余额低于阈值 (%d <= %d)，准备触发重置", balance.Remaining, config.Threshold)
+		s.executeAutoReset("threshold_trigger")
+		return
 	}
-	s.apiClient = client.NewClaudeAPIClient(config.Cookie)
 
-	if s.config.ThresholdTimeEnabled {
-		utils.Logf("[阈值触发]   📅 时间范围: %s-%s", s.config.ThresholdStartTime, s.config.ThresholdEndTime)
-		// 启动时间范围管理任务
-		if err := s.startTimeRangeManager(); err != nil {
-			return err
-		}
-	} else {
-		utils.Logf("[阈值触发]   📅 时间范围: 全天检查")
-		// 全天检查，直接启动阈值检查任务
-		if err := s.activateThresholdCheck(); err != nil {
-			return err
-		}
+	now := time.Now()
+	if !s.claimThresholdAction(now) {
+		utils.Logf("[阈值触发]   ⏭️  本周期已执行过阈值动作(%s)，跳过", action)
+		return
 	}
 
-	// 启动调度器
-	s.thresholdScheduler.Start()
-	s.thresholdRunning = true
+	utils.Logf("[阈值触发]   🚨 积分余额低于阈值 (%d <= %d)，执行动作: %s", balance.Remaining, config.Threshold, action)
+	message := fmt.Sprintf("当前积分余额 %d，低于阈值 %d", balance.Remaining, config.Threshold)
 
-	utils.Logf("[阈值触发] ✅ 阈值检查任务已启动")
-	return nil
-}
-
-// stopThresholdTask 停止阈值检查任务（采用彻底清理策略）
-func (s *AutoResetService) stopThresholdTask() {
-	utils.Logf("[阈值触发] 🔴 停止阈值检查任务 (彻底清理所有相关任务)")
-
-	if s.thresholdRunning {
-		// 停用阈值检查任务
-		if s.thresholdActive {
-			s.removeThresholdCheckTask()
+	switch action {
+	case models.ThresholdActionNotify:
+		s.notifyThreshold("积分低于阈值", message, now)
+	case models.ThresholdActionStop:
+		if err := s.disableMonitoring(); err != nil {
+			utils.Logf("[阈值触发]   ❌ 停止监控失败: %v", err)
+			return
 		}
-
-		// 完全停止并关闭调度器，确保所有任务都被清理
-		utils.Logf("[阈值触发] 🗑️  完全清理阈值调度器")
-		s.thresholdScheduler.StopJobs()
-		if err := s.thresholdScheduler.Shutdown(); err != nil {
-			utils.Logf("[阈值触发] ❌ 关闭阈值调度器失败: %v", err)
+		utils.Logf("[阈值触发]   ⏹️  已停止监控并关闭监控开关")
+		s.notifyThreshold("积分低于阈值，已停止监控", message, now)
+	case models.ThresholdActionWebhook:
+		payload := models.WebhookPayload{
+			Event:   models.WebhookEventThreshold,
+			Message: message,
+			Data: map[string]int{
+				"remaining": balance.Remaining,
+				"threshold": config.Threshold,
+			},
+			Timestamp: now,
 		}
-
-		// 重新创建调度器以确保完全清理
-		newScheduler, err := gocron.NewScheduler()
-		if err != nil {
-			utils.Logf("[阈值触发] ❌ 重新创建阈值调度器失败: %v", err)
-		} else {
-			s.thresholdScheduler = newScheduler
-			utils.Logf("[阈值触发] ✅ 阈值调度器已重新创建")
+		if err := SendWebhook(config.ThresholdWebhookURL, payload); err != nil {
+			utils.Logf("[阈值触发]   ❌ %v", err)
+			// 调用失败时允许下次检查重试
+			s.mu.Lock()
+			s.thresholdFiredAt = time.Time{}
+			s.mu.Unlock()
+			return
 		}
-
-		// 清理所有任务引用
-		s.thresholdJob = nil
-		s.thresholdTimerJob = nil
-		s.thresholdRunning = false
-		s.thresholdActive = false
-
-		utils.Logf("[阈值触发] ⏹️  阈值检查任务已完全停止")
+		utils.Logf("[阈值触发]   📤 已调用Webhook")
 	}
-
-	utils.Logf("[阈值触发] ✅ 阈值检查任务已停止")
 }
 
-// stopThresholdTaskInternal 停止阈值检查任务（内部方法，无任务协调）
-func (s *AutoResetService) stopThresholdTaskInternal() {
-	if s.thresholdRunning {
-		s.thresholdScheduler.StopJobs()
-		s.thresholdRunning = false
-		s.thresholdActive = false
-		utils.Logf("[阈值触发] ⏹️  阈值检查任务已停止 (内部)")
+// disableMonitoring 停止监控任务并保存关闭的监控开关，服务重启后不会自动恢复监控
+func (s *AutoResetService) disableMonitoring() error {
+	config, err := s.db.GetConfig()
+	if err != nil {
+		return fmt.Errorf("获取配置失败: %w", err)
+	}
+	if !config.Enabled && !s.schedulerSvc.IsRunning() {
+		return nil
 	}
+	config.Enabled = false
+	// 监控开关变化时调度器停止正在运行的任务
+	return s.schedulerSvc.UpdateConfig(config)
 }
 
-// startTimeRangeManager 启动时间范围管理任务
-func (s *AutoResetService) startTimeRangeManager() error {
-	utils.Logf("[阈值触发] 🕐 启动时间范围管理器")
-
-	// 创建每分钟检查的定时任务来管理时间范围
-	job, err := s.thresholdScheduler.NewJob(
-		gocron.DurationJob(1*time.Minute),
-		gocron.NewTask(s.manageTimeRange),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-	)
+// claimThresholdAction 占用本重置周期内唯一一次非重置类动作的执行机会，本周期已执行过时返回false
+func (s *AutoResetService) claimThresholdAction(now time.Time) bool {
+	config, err := s.db.GetConfig()
 	if err != nil {
-		utils.Logf("[阈值触发] ❌ 创建时间范围管理任务失败: %v", err)
-		return err
+		utils.Logf("[阈值触发]   ❌ 获取配置失败: %v", err)
+		return false
 	}
+	periodStart := config.ResetClock.PeriodStart(now)
 
-	s.thresholdTimerJob = job
-	utils.Logf("[阈值触发] ✅ 时间范围管理任务创建成功, ID: %v", job.ID())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.thresholdFiredAt.Before(periodStart) {
+		return false
+	}
+	s.thresholdFiredAt = now
+	return true
+}
 
-	// 立即检查当前时间是否在范围内
-	s.manageTimeRange()
+// rearmThreshold 积分余额回升到阈值以上时重新布防：清除本周期的动作执行记录，再次低于阈值时重新执行动作
+func (s *AutoResetService) rearmThreshold() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return nil
+	if s.thresholdFiredAt.IsZero() {
+		return
+	}
+	s.thresholdFiredAt = time.Time{}
+	utils.Logf("[阈值触发] 🔁 积分余额已回升到阈值以上，重新启用阈值检查")
 }
 
-// manageTimeRange 管理时间范围，动态启动和停止阈值检查
-func (s *AutoResetService) manageTimeRange() {
-	// 检查阈值任务是否仍在运行，如果已停用则直接返回
-	if !s.thresholdRunning {
-		utils.Logf("[阈值触发] ⚠️  阈值任务已停用，跳过时间范围检查")
+// executeStrategy 按自定义重置策略的决策执行重置或通知
+func (s *AutoResetService) executeStrategy(config *models.AutoResetConfig, program *strategy.Program, balance *models.CreditBalance) {
+	if program == nil {
+		utils.Logf("[重置策略]   ❌ 策略未编译成功，跳过")
 		return
 	}
 
 	now := time.Now()
-	inRange := s.config.IsInThresholdTimeRange(now)
-
-	utils.Logf("[阈值触发] 🕐 检查时间范围状态")
-	utils.Logf("[阈值触发]   ⏰ 当前时间: %s", now.Format("15:04:05"))
-	utils.Logf("[阈值触发]   📅 检查范围: %s-%s", s.config.ThresholdStartTime, s.config.ThresholdEndTime)
-	utils.Logf("[阈值触发]   🔍 当前活跃: %v", s.thresholdActive)
-	utils.Logf("[阈值触发]   🎯 在范围内: %v", inRange)
-
-	if inRange && !s.thresholdActive {
-		// 进入时间范围，启动阈值检查
-		utils.Logf("[阈值触发] 🟢 进入检查时间范围，启动阈值检查任务")
-		if err := s.activateThresholdCheck(); err != nil {
-			utils.Logf("[阈值触发] ❌ 启动阈值检查失败: %v", err)
+	usedToday := s.isAlreadyReset()
+	resetRemaining := 1
+	if usedToday {
+		resetRemaining = 0
+	}
+	decision, err := program.Decide(strategy.Input{
+		Balance:        balance.Remaining,
+		Threshold:      config.Threshold,
+		Time:           now,
+		UsedToday:      usedToday,
+		ResetRemaining: resetRemaining,
+	})
+	if err != nil {
+		utils.Logf("[重置策略]   ❌ %v", err)
+		return
+	}
+	utils.Logf("[重置策略]   🧮 策略决策: %s (余额: %d, 今日已重置: %v)", decision, balance.Remaining, usedToday)
+
+	switch decision {
+	case strategy.DecisionReset:
+		if usedToday {
+			utils.Logf("[重置策略]   ⚠️  今日已重置过，忽略重置决策")
+			return
 		}
-	} else if !inRange && s.thresholdActive {
-		// 离开时间范围，停止阈值检查
-		utils.Logf("[阈值触发] 🔴 离开检查时间范围，停止阈值检查任务")
-		s.deactivateThresholdCheck()
-	} else if inRange && s.thresholdActive {
-		utils.Logf("[阈值触发] 🟢 仍在检查时间范围内，继续阈值检查")
-	} else {
-		utils.Logf("[阈值触发] ⏸️  仍在检查时间范围外，保持等待状态")
+		s.executeAutoReset("strategy_trigger")
+	case strategy.DecisionNotify:
+		if !s.claimThresholdAction(now) {
+			return
+		}
+		s.notifyThreshold("重置策略提醒", fmt.Sprintf("当前积分余额 %d，已满足重置策略的提醒条件", balance.Remaining), now)
 	}
 }
 
-// activateThresholdCheck 激活阈值检查任务
-func (s *AutoResetService) activateThresholdCheck() error {
-	if s.thresholdActive {
-		utils.Logf("[阈值触发] ⚠️  阈值检查已经激活，跳过")
-		return nil
-	}
+// notifyThreshold 推送阈值触发通知到前端
+func (s *AutoResetService) notifyThreshold(title, message string, now time.Time) {
+	s.schedulerSvc.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeThreshold,
+		Title:     title,
+		Message:   message,
+		Timestamp: now,
+	})
+}
 
-	// 检查今日是否已重置，如果已重置则无需创建检查任务
-	if s.isAlreadyReset() {
-		utils.Logf("[阈值触发] ⚠️  今日已重置过，跳过阈值检查任务创建")
-		utils.Logf("[阈值触发] 📋 任务目标已达成，无需继续检查")
+// startThresholdTask 启动阈值检查：订阅监控任务取得的积分余额，每次取得余额时检查一次，不单独请求上游
+func (s *AutoResetService) startThresholdTask() error {
+	if s.thresholdRunning {
 		return nil
 	}
 
-	utils.Logf("[阈值触发] 🔨 创建30秒阈值检查任务")
-
-	// 创建30秒定时检查任务
-	if err := s.createThresholdJob(); err != nil {
-		return err
+	utils.Logf("[阈值触发] 🚀 启动阈值检查")
+	utils.Logf("[阈值触发]   🎯 阈值设置: %d", s.config.Threshold)
+	if s.config.ThresholdTimeEnabled {
+		utils.Logf("[阈值触发]   📅 时间范围: %s-%s", s.config.ThresholdStartTime, s.config.ThresholdEndTime)
+	} else {
+		utils.Logf("[阈值触发]   📅 时间范围: 全天检查")
 	}
 
-	// 启动阈值检查时暂停SchedulerService积分获取任务（整个检查期间）
-	utils.Logf("[阈值触发] ⏸️  暂停SchedulerService积分获取任务 (整个检查期间)")
-	s.schedulerSvc.PauseBalanceTask()
-
-	s.thresholdActive = true
-	utils.Logf("[阈值触发] ✅ 阈值检查任务已激活")
+	listener := s.schedulerSvc.AddInternalBalanceListener()
+	go func() {
+		for balance := range listener {
+			s.handleThresholdCheck(balance)
+		}
+	}()
 
+	s.balanceListener = listener
+	s.thresholdRunning = true
+	utils.Logf("[阈值触发] ✅ 阈值检查已启动，随积分余额获取任务执行")
 	return nil
 }
 
-// removeThresholdCheckTask 移除阈值检查任务（内部方法）
-func (s *AutoResetService) removeThresholdCheckTask() {
-	if !s.thresholdActive {
-		utils.Logf("[阈值触发] ⚠️  阈值检查已经停用，跳过")
+// stopThresholdTask 停止阈值检查（取消积分余额订阅）
+func (s *AutoResetService) stopThresholdTask() {
+	if !s.thresholdRunning {
 		return
 	}
 
-	utils.Logf("[阈值触发] 🗑️  移除阈值检查任务")
-
-	// 移除阈值检查任务
-	if s.thresholdJob != nil {
-		if err := s.thresholdScheduler.RemoveJob(s.thresholdJob.ID()); err != nil {
-			utils.Logf("[阈值触发] ❌ 移除阈值检查任务失败: %v", err)
-		} else {
-			utils.Logf("[阈值触发] ✅ 阈值检查任务已移除")
-		}
-		s.thresholdJob = nil
-	}
-
-	// 移除时间范围管理任务
-	if s.thresholdTimerJob != nil {
-		if err := s.thresholdScheduler.RemoveJob(s.thresholdTimerJob.ID()); err != nil {
-			utils.Logf("[阈值触发] ❌ 移除时间范围管理任务失败: %v", err)
-		} else {
-			utils.Logf("[阈值触发] ✅ 时间范围管理任务已移除")
-		}
-		s.thresholdTimerJob = nil
-	}
-
-	// 恢复SchedulerService积分获取任务（采用重建策略）
-	utils.Logf("[阈值触发] ▶️  恢复SchedulerService积分获取任务 (阈值检查已结束)")
-	s.schedulerSvc.RebuildBalanceTask()
-
-	s.thresholdActive = false
-	utils.Logf("[阈值触发] ⏹️  阈值检查任务已停用")
-}
-
-// deactivateThresholdCheck 停用阈值检查任务（保持兼容性）
-func (s *AutoResetService) deactivateThresholdCheck() {
-	s.removeThresholdCheckTask()
+	s.schedulerSvc.RemoveInternalBalanceListener(s.balanceListener)
+	s.balanceListener = nil
+	s.thresholdRunning = false
+	utils.Logf("[阈值触发] ⏹️  阈值检查已停止")
 }
 
 // executeAutoReset 执行自动重置
@@ -644,21 +634,47 @@ func (s *AutoResetService) executeAutoReset(trigger string) {
 
 	utils.Logf("[自动重置]   ✅ 今日未重置，继续执行重置操作")
 
+	// 多实例部署时先争取本周期的重置权，避免多个实例重复重置同一账户
+	// 按重置周期（而不是日历日期）加锁，自定义重置时刻时各实例对"本周期"的判断与重置标记一致
+	var period string
+	lock := s.getResetLock()
+	if lock != nil {
+		config, err := s.db.GetConfig()
+		if err != nil {
+			utils.Logf("[自动重置]   ❌ 获取配置失败，跳过执行: %v", err)
+			return
+		}
+		period = config.ResetClock.PeriodKey(time.Now())
+		acquired, holder, err := lock.TryAcquire(period)
+		if err != nil {
+			utils.Logf("[自动重置]   ❌ 获取重置锁失败，跳过执行: %v", err)
+			return
+		}
+		if !acquired {
+			utils.Logf("[自动重置]   🔒 本周期重置权已由其他实例(%s)获得，跳过执行", holder)
+			s.markResetUsedByPeer()
+			return
+		}
+		utils.Logf("[自动重置]   🔓 已获得本周期重置权 (%s)", period)
+	}
+
 	// 调用现有的重置积分API
 	success := s.callExistingResetAPI()
+	if !success && lock != nil {
+		// 重置失败时释放重置权，允许其他实例重试
+		if err := lock.Release(period); err != nil {
+			utils.Logf("[自动重置]   ⚠️  释放重置锁失败: %v", err)
+		}
+	}
 	if success {
 		utils.Logf("[自动重置] ✅ 自动重置执行成功")
 
-		// 如果是阈值触发，延迟获取最新积分确认重置效果
-		if trigger == "threshold_trigger" {
+		// 如果是阈值触发，延迟获取最新积分确认重置效果（经由积分余额任务保存并推送）
+		if trigger == "threshold_trigger" || trigger == "strategy_trigger" {
 			go func() {
 				time.Sleep(10 * time.Second)
 				utils.Logf("[阈值触发] 🔄 重置后验证积分余额...")
-				if balance, err := s.apiClient.FetchCreditBalance(); err == nil {
-					utils.Logf("[阈值触发] ✅ 重置后积分余额: %d", balance.Remaining)
-					utils.Logf("[阈值触发] 📊 阈值对比: %d > %d (阈值)", balance.Remaining, s.config.Threshold)
-					s.schedulerSvc.NotifyBalanceUpdate(balance)
-				} else {
+				if err := s.schedulerSvc.FetchBalanceManually(context.Background()); err != nil {
 					utils.Logf("[阈值触发] ❌ 重置后获取积分余额失败: %v", err)
 				}
 			}()
@@ -668,6 +684,25 @@ func (s *AutoResetService) executeAutoReset(trigger string) {
 	}
 }
 
+// markResetUsedByPeer 其他实例已执行当日重置时，同步标记本实例今日已使用重置，避免阈值检查反复尝试
+func (s *AutoResetService) markResetUsedByPeer() {
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[自动重置] 获取配置失败: %v", err)
+		return
+	}
+	if config.IsDailyResetUsed(time.Now()) {
+		return
+	}
+
+	config.MarkDailyResetUsed(time.Now())
+	if err := s.db.SaveConfig(config); err != nil {
+		utils.Logf("[自动重置] 保存配置失败: %v", err)
+		return
+	}
+	s.schedulerSvc.NotifyResetStatusChange(true)
+}
+
 // callExistingResetAPI 调用现有的重置积分API逻辑
 func (s *AutoResetService) callExistingResetAPI() bool {
 	// 获取当前配置
@@ -688,7 +723,7 @@ func (s *AutoResetService) callExistingResetAPI() bool {
 	// 这会复用现有的重置逻辑，包括API调用、状态更新和SSE通知
 
 	// 调用真实的重置API
-	err = s.schedulerSvc.ResetCreditsManually()
+	err = s.schedulerSvc.ResetCreditsManually(context.Background())
 	if err != nil {
 		utils.Logf("[自动重置] 调用重置API失败: %v", err)
 		return false
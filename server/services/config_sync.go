@@ -0,0 +1,220 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// 配置同步拉取间隔
+const (
+	DefaultConfigSyncInterval = time.Minute
+	MinConfigSyncInterval     = 10 * time.Second
+)
+
+// configSyncPath 主实例发布同步配置的接口路径
+const configSyncPath = "/api/v1/config/sync"
+
+// ConfigSyncService 配置同步服务
+// 定期从主实例拉取同步配置，版本变化时保存到本地并通过异步配置更新服务使调度、自动重置等任务生效
+type ConfigSyncService struct {
+	scheduler    gocron.Scheduler
+	db           *database.BadgerDB
+	schedulerSvc *SchedulerService
+	asyncUpdater *AsyncConfigUpdater
+	client       *resty.Client
+	source       string        // 主实例地址
+	accessKey    string        // 主实例访问密钥
+	interval     time.Duration // 拉取间隔
+	status       models.ConfigSyncStatus
+	mu           sync.RWMutex
+}
+
+// NewConfigSyncService 创建配置同步服务
+func NewConfigSyncService(source, accessKey string, interval time.Duration, db *database.BadgerDB, schedulerSvc *SchedulerService, asyncUpdater *AsyncConfigUpdater) (*ConfigSyncService, error) {
+	parsed, err := url.Parse(source)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("主实例地址无效: %s", source)
+	}
+	if accessKey == "" {
+		return nil, fmt.Errorf("缺少主实例访问密钥")
+	}
+	if interval < MinConfigSyncInterval {
+		interval = MinConfigSyncInterval
+	}
+
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建配置同步调度器失败: %w", err)
+	}
+
+	source = strings.TrimRight(source, "/")
+	return &ConfigSyncService{
+		scheduler:    scheduler,
+		db:           db,
+		schedulerSvc: schedulerSvc,
+		asyncUpdater: asyncUpdater,
+		client:       resty.New().SetTimeout(15 * time.Second),
+		source:       source,
+		accessKey:    accessKey,
+		interval:     interval,
+		status:       models.ConfigSyncStatus{Enabled: true, Source: source},
+	}, nil
+}
+
+// Start 启动配置同步任务（启动时立即拉取一次）
+func (s *ConfigSyncService) Start() error {
+	_, err := s.scheduler.NewJob(
+		gocron.DurationJob(s.interval),
+		gocron.NewTask(s.run),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("创建配置同步任务失败: %w", err)
+	}
+
+	s.scheduler.Start()
+	utils.Logf("[配置同步] ✅ 配置同步任务已启动，主实例: %s，间隔: %v", s.source, s.interval)
+	return nil
+}
+
+// Stop 停止配置同步服务
+func (s *ConfigSyncService) Stop() error {
+	if err := s.scheduler.Shutdown(); err != nil {
+		return fmt.Errorf("关闭配置同步调度器失败: %w", err)
+	}
+	return nil
+}
+
+// GetStatus 获取配置同步状态
+func (s *ConfigSyncService) GetStatus() models.ConfigSyncStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// run 执行一次同步并记录结果
+func (s *ConfigSyncService) run() {
+	applied, err := s.Sync()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.status.LastError = err.Error()
+		utils.Logf("[配置同步] ❌ 同步失败: %v", err)
+		return
+	}
+	now := time.Now().Format(time.RFC3339)
+	s.status.LastError = ""
+	s.status.LastSyncAt = now
+	if applied {
+		s.status.LastAppliedAt = now
+	}
+}
+
+// Sync 从主实例拉取同步配置，版本与最近一次应用的版本不同时应用到本地，返回是否应用了变更
+func (s *ConfigSyncService) Sync() (bool, error) {
+	snapshot, err := s.fetch()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	lastVersion := s.status.Version
+	s.mu.RUnlock()
+	if snapshot.Version == lastVersion {
+		return false, nil
+	}
+
+	current, err := s.db.GetConfig()
+	if err != nil {
+		return false, fmt.Errorf("获取本地配置失败: %w", err)
+	}
+
+	// 本地配置已与主实例一致时只记录版本
+	applied := false
+	if current.Synced().Snapshot().Version != snapshot.Version {
+		if err := s.apply(current, snapshot.Config); err != nil {
+			return false, err
+		}
+		applied = true
+		utils.Logf("[配置同步] 🔄 已应用主实例配置，版本: %s", snapshot.Version)
+	}
+
+	s.mu.Lock()
+	s.status.Version = snapshot.Version
+	s.mu.Unlock()
+	return applied, nil
+}
+
+// fetch 拉取主实例发布的同步配置快照
+func (s *ConfigSyncService) fetch() (*models.ConfigSyncSnapshot, error) {
+	resp, err := s.client.R().
+		SetAuthToken(s.accessKey).
+		Get(s.source + configSyncPath)
+	if err != nil {
+		return nil, fmt.Errorf("请求主实例失败: %w", err)
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("请求主实例失败: HTTP %d", resp.StatusCode())
+	}
+
+	var body struct {
+		Data models.ConfigSyncSnapshot `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body(), &body); err != nil {
+		return nil, fmt.Errorf("解析主实例配置失败: %w", err)
+	}
+	if body.Data.Version == "" {
+		return nil, fmt.Errorf("主实例配置缺少版本信息")
+	}
+	return &body.Data, nil
+}
+
+// apply 保存同步配置并通过异步配置更新服务使相关任务生效（与页面修改配置的流程一致）
+func (s *ConfigSyncService) apply(current *models.UserConfig, synced models.SyncedConfig) error {
+	newConfig := current.WithSynced(synced)
+	if err := newConfig.Validate(); err != nil {
+		return fmt.Errorf("主实例配置验证失败: %w", err)
+	}
+
+	if err := s.schedulerSvc.UpdateConfigSync(newConfig); err != nil {
+		return fmt.Errorf("保存同步配置失败: %w", err)
+	}
+
+	if s.schedulerSvc.NeedsTaskRestart(current, newConfig) {
+		if _, err := s.asyncUpdater.SubmitJob(JobTypeScheduler, current, newConfig); err != nil {
+			utils.Logf("[配置同步] ⚠️  提交调度器更新任务失败: %v", err)
+		}
+	}
+	if current.AutoSchedule != newConfig.AutoSchedule {
+		if _, err := s.asyncUpdater.SubmitJob(JobTypeAutoSchedule, &current.AutoSchedule, &newConfig.AutoSchedule); err != nil {
+			utils.Logf("[配置同步] ⚠️  提交自动调度更新任务失败: %v", err)
+		}
+	}
+	if current.AutoReset != newConfig.AutoReset {
+		if _, err := s.asyncUpdater.SubmitJob(JobTypeAutoReset, &current.AutoReset, &newConfig.AutoReset); err != nil {
+			utils.Logf("[配置同步] ⚠️  提交自动重置更新任务失败: %v", err)
+		}
+	}
+	if current.KeepAlive != newConfig.KeepAlive {
+		if _, err := s.asyncUpdater.SubmitJob(JobTypeKeepAlive, &current.KeepAlive, &newConfig.KeepAlive); err != nil {
+			utils.Logf("[配置同步] ⚠️  提交Cookie保活更新任务失败: %v", err)
+		}
+	}
+
+	// 通知前端配置已更新
+	s.schedulerSvc.NotifyConfigChange()
+	s.schedulerSvc.NotifyAutoScheduleChange()
+	return nil
+}
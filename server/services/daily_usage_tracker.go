@@ -20,6 +20,7 @@ type DailyUsageTracker struct {
 	job           gocron.Job       // 定时任务引用
 	isActive      bool             // 任务是否激活状态
 	isInitialized bool             // 是否已初始化
+	pausedFunc    func() bool      // 是否暂停上游请求（维护模式）
 	mu            sync.RWMutex
 }
 
@@ -182,8 +183,28 @@ func (d *DailyUsageTracker) Stop() error {
 	return nil
 }
 
+// SetPausedFunc 设置上游请求暂停判断函数（维护模式期间跳过统计）
+func (d *DailyUsageTracker) SetPausedFunc(pausedFunc func() bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pausedFunc = pausedFunc
+}
+
 // collectHourlyUsage 收集最近一小时的积分使用量
 func (d *DailyUsageTracker) collectHourlyUsage() error {
+	d.mu.RLock()
+	pausedFunc := d.pausedFunc
+	d.mu.RUnlock()
+	if pausedFunc != nil && pausedFunc() {
+		utils.Logf("[每日积分统计] 🚧 维护模式中，跳过本次统计")
+		return nil
+	}
+
+	// 整点任务按抖动随机延后，避免多个实例同时请求上游（统计范围以实际执行时间为准）
+	if delay := jitterDelay(); delay > 0 {
+		time.Sleep(delay)
+	}
+
 	startTime := time.Now()
 	utils.Logf("[每日积分统计] 📊 开始执行积分统计任务 (%s)", startTime.Format("15:04:05"))
 
@@ -208,7 +229,8 @@ func (d *DailyUsageTracker) collectHourlyUsage() error {
 	var hourlyCredits int
 	var recordCount int
 	var oldestRecord, newestRecord time.Time
-	modelCredits := make(map[string]int) // 按模型分组的积分统计
+	modelCredits := make(map[string]int)                  // 按模型分组的积分统计
+	hourlyModelCredits := make(map[string]map[string]int) // 按小时和模型分组的积分统计
 
 	utils.Logf("[每日积分统计] 🔍 分析时间范围: %s 至 %s",
 		oneHourAgo.In(time.Local).Format("15:04:05"), time.Now().Format("15:04:05"))
@@ -235,6 +257,12 @@ func (d *DailyUsageTracker) collectHourlyUsage() error {
 			// 按模型统计积分
 			if data.Model != "" && data.CreditsUsed > 0 {
 				modelCredits[data.Model] += data.CreditsUsed
+
+				hour := data.CreatedAt.In(time.Local).Format("15")
+				if hourlyModelCredits[hour] == nil {
+					hourlyModelCredits[hour] = make(map[string]int)
+				}
+				hourlyModelCredits[hour][data.Model] += data.CreditsUsed
 			}
 		}
 	}
@@ -271,7 +299,7 @@ func (d *DailyUsageTracker) collectHourlyUsage() error {
 	}
 
 	// 累加到当日总积分使用量（包含按模型分组的数据）
-	if err := d.db.SaveDailyUsageWithModels(localDate, hourlyCredits, modelCredits); err != nil {
+	if err := d.db.SaveDailyUsageWithModels(localDate, hourlyCredits, modelCredits, hourlyModelCredits); err != nil {
 		utils.Logf("[每日积分统计] ❌ 保存每日积分统计失败: %v", err)
 		return err
 	}
@@ -292,14 +320,7 @@ func (d *DailyUsageTracker) collectHourlyUsage() error {
 		afterCredits,
 		elapsedTime)
 
-	// 执行数据清理任务（保留7天数据）
-	utils.Logf("[每日积分统计] 🧹 开始清理过期数据...")
-	if err := d.db.CleanupOldDailyUsage(7); err != nil {
-		utils.Logf("[每日积分统计] ⚠️  清理过期数据失败: %v", err)
-		// 清理失败不影响主要功能，继续运行
-	} else {
-		utils.Logf("[每日积分统计] ✅ 过期数据清理完成")
-	}
+	// 过期数据由数据清理服务按保留策略统一清理
 
 	// 计算下次执行时间（下一个整点）
 	now := time.Now()
@@ -323,7 +344,7 @@ func (d *DailyUsageTracker) GetWeeklyUsage() (models.DailyUsageList, error) {
 	utils.Logf("[每日积分统计] 📈 数据库中找到 %d 天的统计数据", rawCount)
 
 	// 确保返回完整的7天数据（包括缺失的日期）
-	completeList := usageList.FillMissingDates()
+	completeList := usageList.FillMissingDates().NormalizeModels().WithGroups()
 
 	// 计算统计信息
 	var totalCredits int
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// checkBalanceExhausted 积分余额从耗尽下限以上降至下限时推送通知、触发balance_exhausted事件并调用耗尽Webhook
+func (s *SchedulerService) checkBalanceExhausted(previous, balance *models.CreditBalance) {
+	if balance == nil {
+		return
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[积分耗尽] 获取配置失败: %v", err)
+		return
+	}
+	exhaustion := config.Exhaustion
+	if !exhaustion.Enabled || !exhaustion.IsExhausted(balance.Remaining) {
+		return
+	}
+	if previous != nil && exhaustion.IsExhausted(previous.Remaining) {
+		return
+	}
+
+	now := time.Now()
+	message := fmt.Sprintf("当前积分余额 %d，已降至耗尽下限 %d", balance.Remaining, exhaustion.Floor)
+	data := map[string]int{
+		"remaining": balance.Remaining,
+		"floor":     exhaustion.Floor,
+	}
+	utils.Logf("[积分耗尽] ⛔ %s", message)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeExhausted,
+		Title:     "积分已耗尽",
+		Message:   message,
+		Timestamp: now,
+	})
+
+	s.mu.RLock()
+	runner := s.hookRunner
+	s.mu.RUnlock()
+	if runner != nil {
+		runner.Run(config.Hooks, models.HookEventExhausted, data)
+	}
+
+	if exhaustion.WebhookURL != "" {
+		payload := models.WebhookPayload{
+			Event:     models.WebhookEventExhausted,
+			Message:   message,
+			Data:      data,
+			Timestamp: now,
+		}
+		go func() {
+			if err := SendWebhook(exhaustion.WebhookURL, payload); err != nil {
+				utils.Logf("[积分耗尽] ❌ %v", err)
+				return
+			}
+			utils.Logf("[积分耗尽] 📤 已调用Webhook")
+		}()
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// externalUsageAlertCooldown 外部使用告警间隔，持续被外部使用时避免每次读数都告警
+const externalUsageAlertCooldown = time.Hour
+
+// checkExternalUsage 按积分恢复模型和本机使用记录推算上一段读数间隔的预期余额，实际余额低出告警阈值时推送通知、触发external_usage事件并调用Webhook
+// 判断延后一次读数（检查previous之前的间隔），使该间隔内本机的使用记录已获取完整，避免使用数据尚未获取造成误报
+func (s *SchedulerService) checkExternalUsage(previous *models.CreditBalance) {
+	if previous == nil {
+		return
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[外部使用] 获取配置失败: %v", err)
+		return
+	}
+	externalUsage := config.ExternalUsage
+	if !externalUsage.Enabled {
+		return
+	}
+
+	earlier, err := s.db.GetBalanceHistoryBefore(previous.UpdatedAt)
+	if err != nil || earlier == nil {
+		return
+	}
+	resets, err := s.db.GetResetRecordsSince(earlier.UpdatedAt)
+	if err != nil {
+		utils.Logf("[外部使用] 获取积分重置记录失败: %v", err)
+		return
+	}
+	// 间隔内有重置时余额跳变，无法可靠推算
+	for _, reset := range resets {
+		if !reset.Time.After(previous.UpdatedAt) {
+			return
+		}
+	}
+
+	var local models.UsageDataList
+	localCredits := 0
+	for _, record := range s.usage.Records() {
+		if record.CreatedAt.After(earlier.UpdatedAt) && !record.CreatedAt.After(previous.UpdatedAt) {
+			local = append(local, record)
+			localCredits += record.CreditsUsed
+		}
+	}
+
+	// 只用两次读数确定恢复模型：未配置上限和恢复速度时按不恢复计算，告警更保守
+	history := []models.CreditBalance{*earlier, *previous}
+	model := models.NewRecoveryModel(config.AutoReset.RecoveryPerHour, config.AutoReset.RecoveryCap, history)
+	curve := models.NewBalanceCurve(history, local, nil, model)
+	unexplained := curve.MaxShortfall
+	threshold := externalUsage.GetThreshold()
+	if unexplained < threshold {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if now.Sub(s.externalUsageAlertAt) < externalUsageAlertCooldown {
+		s.mu.Unlock()
+		return
+	}
+	s.externalUsageAlertAt = now
+	s.mu.Unlock()
+
+	message := fmt.Sprintf("%s 至 %s 积分余额由 %d 降至 %d，本机仅记录使用 %d，有 %d 积分的下降无法解释，账户可能在其他地方被使用",
+		earlier.UpdatedAt.Local().Format("15:04"), previous.UpdatedAt.Local().Format("15:04"),
+		earlier.Remaining, previous.Remaining, localCredits, unexplained)
+	data := map[string]int{
+		"before":      earlier.Remaining,
+		"after":       previous.Remaining,
+		"localUsage":  localCredits,
+		"unexplained": unexplained,
+		"threshold":   threshold,
+	}
+	utils.Logf("[外部使用] 🕵️ %s", message)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeExternalUsage,
+		Title:     "检测到外部使用",
+		Message:   message,
+		Timestamp: now,
+	})
+
+	s.mu.RLock()
+	runner := s.hookRunner
+	s.mu.RUnlock()
+	if runner != nil {
+		runner.Run(config.Hooks, models.HookEventExternalUsage, data)
+	}
+
+	if externalUsage.WebhookURL != "" {
+		payload := models.WebhookPayload{
+			Event:     models.WebhookEventExternalUsage,
+			Message:   message,
+			Data:      data,
+			Timestamp: now,
+		}
+		go func() {
+			if err := SendWebhook(externalUsage.WebhookURL, payload); err != nil {
+				utils.Logf("[外部使用] ❌ %v", err)
+				return
+			}
+			utils.Logf("[外部使用] 📤 已调用Webhook")
+		}()
+	}
+}
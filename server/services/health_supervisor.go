@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+const (
+	healthEvaluateInterval    = 30 * time.Second // 定期评估间隔
+	upstreamErrorThreshold    = 5                // 连续失败达到该次数视为error
+	dbErrorWindow             = 5 * time.Minute  // 数据库错误影响健康状态的时间窗口
+	minStaleDataThreshold     = 5 * time.Minute  // 监控数据过期判定的最小时长
+	staleDataIntervalMultiple = 3                // 超过N个获取间隔未成功视为数据过期
+)
+
+// HealthSupervisor 系统健康监督服务
+// 汇总上游请求结果、Cookie有效性、数据库错误和任务状态，维护统一的健康状态并通过SSE推送
+type HealthSupervisor struct {
+	scheduler           gocron.Scheduler
+	db                  *database.BadgerDB
+	schedulerSvc        *SchedulerService
+	consecutiveFailures int       // 上游连续失败次数
+	lastUpstreamError   string    // 最近一次上游错误
+	lastSuccessAt       time.Time // 最近一次上游请求成功时间
+	cookieExpired       bool      // 上游是否返回Cookie失效
+	state               *models.HealthState
+	evaluateCh          chan struct{} // 评估请求（容量为1，评估进行中收到的多次请求合并为一次）
+	done                chan struct{} // 关闭时通知评估协程退出
+	mu                  sync.Mutex
+}
+
+// NewHealthSupervisor 创建健康监督服务
+func NewHealthSupervisor(db *database.BadgerDB, schedulerSvc *SchedulerService) (*HealthSupervisor, error) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建健康监督调度器失败: %w", err)
+	}
+
+	scheduler.Start()
+
+	h := &HealthSupervisor{
+		scheduler:    scheduler,
+		db:           db,
+		schedulerSvc: schedulerSvc,
+		evaluateCh:   make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+	go h.evaluateLoop()
+	return h, nil
+}
+
+// Start 启动定期健康评估
+func (h *HealthSupervisor) Start() error {
+	_, err := h.scheduler.NewJob(
+		gocron.DurationJob(healthEvaluateInterval),
+		gocron.NewTask(h.requestEvaluate),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("创建健康评估任务失败: %w", err)
+	}
+
+	utils.Logf("[健康监督] ✅ 健康评估任务已启动，间隔: %v", healthEvaluateInterval)
+	return nil
+}
+
+// Stop 停止健康监督服务
+func (h *HealthSupervisor) Stop() error {
+	if err := h.scheduler.Shutdown(); err != nil {
+		return fmt.Errorf("关闭健康监督调度器失败: %w", err)
+	}
+	close(h.done)
+	return nil
+}
+
+// requestEvaluate 请求重新评估健康状态（不阻塞调用方）
+func (h *HealthSupervisor) requestEvaluate() {
+	select {
+	case h.evaluateCh <- struct{}{}:
+	default:
+		// 已有待处理的评估请求，合并
+	}
+}
+
+// evaluateLoop 串行执行评估，保证推送的健康状态按评估顺序且不会被过期的结果覆盖
+func (h *HealthSupervisor) evaluateLoop() {
+	for {
+		select {
+		case <-h.evaluateCh:
+			h.evaluate()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// RecordUpstreamResult 记录一次上游请求结果，并请求重新评估健康状态
+func (h *HealthSupervisor) RecordUpstreamResult(err error) {
+	h.mu.Lock()
+	wasExpired := h.cookieExpired
+	if err != nil {
+		h.consecutiveFailures++
+		h.lastUpstreamError = err.Error()
+		h.cookieExpired = errors.Is(err, client.ErrCookieExpired)
+	} else {
+		h.consecutiveFailures = 0
+		h.lastUpstreamError = ""
+		h.lastSuccessAt = time.Now()
+		h.cookieExpired = false
+	}
+	expired := h.cookieExpired
+	h.mu.Unlock()
+
+	// Cookie刚失效时触发Hook（持续失效期间不重复触发）
+	if expired && !wasExpired {
+		go h.schedulerSvc.FireHook(models.HookEventCookieInvalid, map[string]string{"error": err.Error()})
+	}
+
+	// 由评估协程异步执行，避免在调用方持有锁时产生死锁
+	h.requestEvaluate()
+}
+
+// GetState 获取当前健康状态
+func (h *HealthSupervisor) GetState() models.HealthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.state == nil {
+		return models.HealthState{State: models.HealthStateOK, Reasons: []string{}, UpdatedAt: time.Now()}
+	}
+	return *h.state
+}
+
+// evaluate 汇总各项指标计算健康状态，状态变化时推送
+func (h *HealthSupervisor) evaluate() {
+	var errorReasons, degradedReasons []string
+
+	// 数据库
+	if err := h.db.Ping(); err != nil {
+		errorReasons = append(errorReasons, fmt.Sprintf("数据库不可用: %v", err))
+	} else if lastErr, lastAt := h.db.LastError(); lastErr != "" && time.Since(lastAt) < dbErrorWindow {
+		degradedReasons = append(degradedReasons, fmt.Sprintf("数据库近期出现错误: %s", lastErr))
+	}
+
+	// Cookie
+	config := h.schedulerSvc.GetConfig()
+	if config != nil && config.Cookie == "" {
+		degradedReasons = append(degradedReasons, "Cookie未配置")
+	}
+
+	h.mu.Lock()
+	failures := h.consecutiveFailures
+	lastUpstreamError := h.lastUpstreamError
+	lastSuccessAt := h.lastSuccessAt
+	cookieExpired := h.cookieExpired
+	h.mu.Unlock()
+
+	// 上游请求
+	switch {
+	case cookieExpired:
+		errorReasons = append(errorReasons, "Cookie无效或已过期")
+	case failures >= upstreamErrorThreshold:
+		errorReasons = append(errorReasons, fmt.Sprintf("上游连续失败%d次: %s", failures, lastUpstreamError))
+	case failures > 0:
+		degradedReasons = append(degradedReasons, fmt.Sprintf("上游请求失败%d次: %s", failures, lastUpstreamError))
+	}
+
+	// 维护模式期间上游任务暂停，不判定数据过期
+	inMaintenance := h.schedulerSvc.IsInMaintenance()
+	if inMaintenance {
+		degradedReasons = append(degradedReasons, "维护模式中，上游请求已暂停")
+	}
+
+	// 任务状态：监控运行中但数据长时间未更新
+	if !inMaintenance && h.schedulerSvc.IsRunning() && config != nil && !lastSuccessAt.IsZero() {
+		threshold := time.Duration(config.Interval*staleDataIntervalMultiple) * time.Second
+		if threshold < minStaleDataThreshold {
+			threshold = minStaleDataThreshold
+		}
+		if stale := time.Since(lastSuccessAt); stale > threshold {
+			degradedReasons = append(degradedReasons, fmt.Sprintf("监控数据已%v未更新", stale.Truncate(time.Second)))
+		}
+	}
+
+	state := models.HealthStateOK
+	reasons := []string{}
+	if len(errorReasons) > 0 {
+		state = models.HealthStateError
+		reasons = append(errorReasons, degradedReasons...)
+	} else if len(degradedReasons) > 0 {
+		state = models.HealthStateDegraded
+		reasons = degradedReasons
+	}
+
+	h.update(state, reasons)
+}
+
+// update 更新健康状态，状态或原因变化时推送
+func (h *HealthSupervisor) update(state string, reasons []string) {
+	now := time.Now()
+
+	h.mu.Lock()
+	changed := h.state == nil || h.state.State != state || !equalStrings(h.state.Reasons, reasons)
+	since := now
+	if h.state != nil && h.state.State == state {
+		since = h.state.Since
+	}
+	newState := &models.HealthState{
+		State:     state,
+		Reasons:   reasons,
+		Since:     since,
+		UpdatedAt: now,
+	}
+	oldState := h.state
+	h.state = newState
+	h.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if oldState != nil && oldState.State != state {
+		utils.Logf("[健康监督] 健康状态变化: %s → %s %v", oldState.State, state, reasons)
+	}
+	h.schedulerSvc.BroadcastHealthState(*newState)
+}
+
+// equalStrings 比较两个字符串切片是否相同
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
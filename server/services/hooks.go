@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// hookOutputLimit 日志中记录的Hook输出最大长度
+const hookOutputLimit = 512
+
+// HookRunner 事件Hook执行器
+// 仅允许执行Hook目录（启动参数指定）内的程序，防止通过配置接口执行任意命令
+type HookRunner struct {
+	dir string // Hook目录（绝对路径，已解析符号链接）
+}
+
+// NewHookRunner 创建事件Hook执行器
+func NewHookRunner(dir string) (*HookRunner, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Hook目录无效: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("Hook目录无效: %w", err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("Hook目录无效: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("Hook目录无效: %s 不是目录", dir)
+	}
+	return &HookRunner{dir: resolved}, nil
+}
+
+// Dir 获取Hook目录
+func (r *HookRunner) Dir() string {
+	return r.dir
+}
+
+// Run 异步执行订阅了指定事件的所有Hook
+func (r *HookRunner) Run(hooks []models.HookConfig, event string, data interface{}) {
+	payload, err := json.Marshal(models.HookPayload{Event: event, Timestamp: time.Now(), Data: data})
+	if err != nil {
+		utils.Logf("[事件Hook] ❌ 序列化事件失败: %v", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if hook.Event != event {
+			continue
+		}
+		go func(hook models.HookConfig) {
+			if err := r.exec(hook, payload); err != nil {
+				utils.Logf("[事件Hook] ❌ %s: %s 执行失败: %v", event, hook.Path, err)
+				return
+			}
+			utils.Logf("[事件Hook] ✅ %s: %s 执行完成", event, hook.Path)
+		}(hook)
+	}
+}
+
+// exec 执行单个Hook，事件内容写入标准输入
+func (r *HookRunner) exec(hook models.HookConfig, payload []byte) error {
+	path, err := r.resolve(hook.Path)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.GetTimeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, hook.Args...)
+	cmd.Dir = r.dir
+	cmd.Env = utils.ChildEnv("CCCMU_EVENT=" + hook.Event) // 不继承主密钥等敏感环境变量
+	cmd.Stdin = bytes.NewReader(payload)
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("执行超时(%v)", hook.GetTimeout())
+	}
+	if err != nil {
+		return fmt.Errorf("%w，输出: %s", err, truncateHookOutput(output))
+	}
+	return nil
+}
+
+// resolve 解析Hook程序路径，确保位于Hook目录内（含符号链接指向的目标）
+func (r *HookRunner) resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("程序不存在: %w", err)
+	}
+	rel, err := filepath.Rel(r.dir, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("程序不在Hook目录内: %s", path)
+	}
+	return resolved, nil
+}
+
+// truncateHookOutput 截断Hook输出用于日志记录
+func truncateHookOutput(output []byte) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > hookOutputLimit {
+		return text[:hookOutputLimit] + "..."
+	}
+	return text
+}
+
+// SetHookRunner 设置事件Hook执行器（未设置时不执行Hook）
+func (s *SchedulerService) SetHookRunner(runner *HookRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hookRunner = runner
+}
+
+// FireHook 触发事件Hook
+func (s *SchedulerService) FireHook(event string, data interface{}) {
+	s.mu.RLock()
+	runner := s.hookRunner
+	s.mu.RUnlock()
+
+	if runner == nil {
+		return
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[事件Hook] 获取配置失败: %v", err)
+		return
+	}
+	runner.Run(config.Hooks, event, data)
+}
+
+// checkBalanceLow 积分余额从阈值以上跌破自动重置阈值时触发balance_low事件
+func (s *SchedulerService) checkBalanceLow(previous, balance *models.CreditBalance) {
+	s.mu.RLock()
+	runner := s.hookRunner
+	s.mu.RUnlock()
+
+	if runner == nil || balance == nil {
+		return
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[事件Hook] 获取配置失败: %v", err)
+		return
+	}
+	threshold := config.AutoReset.Threshold
+	if threshold <= 0 || balance.Remaining > threshold {
+		return
+	}
+	if previous != nil && previous.Remaining <= threshold {
+		return
+	}
+
+	runner.Run(config.Hooks, models.HookEventBalanceLow, map[string]int{
+		"remaining": balance.Remaining,
+		"threshold": threshold,
+	})
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestHookEnvExcludesSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试Hook为shell脚本")
+	}
+	t.Setenv("MASTER_KEY", "master-secret")
+	t.Setenv("SYNC_KEY", "sync-secret")
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nenv > env.txt\n"
+	if err := os.WriteFile(filepath.Join(dir, "hook.sh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("写入Hook失败: %v", err)
+	}
+	runner, err := NewHookRunner(dir)
+	if err != nil {
+		t.Fatalf("创建Hook执行器失败: %v", err)
+	}
+
+	hook := models.HookConfig{Event: models.HookEventBalanceLow, Path: "hook.sh"}
+	if err := runner.exec(hook, []byte("{}")); err != nil {
+		t.Fatalf("执行Hook失败: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if err != nil {
+		t.Fatalf("读取Hook输出失败: %v", err)
+	}
+	env := string(data)
+	if strings.Contains(env, "MASTER_KEY") || strings.Contains(env, "master-secret") || strings.Contains(env, "SYNC_KEY") {
+		t.Errorf("Hook不应收到敏感环境变量:\n%s", env)
+	}
+	if !strings.Contains(env, "CCCMU_EVENT="+models.HookEventBalanceLow) {
+		t.Errorf("Hook应收到事件类型环境变量:\n%s", env)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// housekeepingInterval 数据清理任务执行间隔
+const housekeepingInterval = time.Hour
+
+// HousekeepingService 数据清理服务
+// 按配置中的数据保留策略降采样或归档原始使用记录；每日积分统计、积分余额历史和降采样数据在写入时设置过期时间，
+// 由数据库自动删除，保留策略变化时在此重新设置已有数据的过期时间；启用到期归档的数据类型在保留期满后由此写入归档再删除
+type HousekeepingService struct {
+	scheduler gocron.Scheduler
+	db        *database.BadgerDB
+	archiver  *UsageArchiver // 归档服务（可选）
+}
+
+// NewHousekeepingService 创建数据清理服务
+func NewHousekeepingService(db *database.BadgerDB) (*HousekeepingService, error) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建数据清理调度器失败: %w", err)
+	}
+
+	return &HousekeepingService{
+		scheduler: scheduler,
+		db:        db,
+	}, nil
+}
+
+// SetArchiver 设置归档服务，启用归档时原始记录在移除前先写入归档文件
+func (h *HousekeepingService) SetArchiver(archiver *UsageArchiver) {
+	h.archiver = archiver
+}
+
+// Start 启动数据清理任务（启动时立即执行一次）
+func (h *HousekeepingService) Start() error {
+	_, err := h.scheduler.NewJob(
+		gocron.DurationJob(housekeepingInterval),
+		gocron.NewTask(h.Run),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("创建数据清理任务失败: %w", err)
+	}
+
+	h.scheduler.Start()
+	utils.Logf("[数据清理] ✅ 数据清理任务已启动，间隔: %v", housekeepingInterval)
+	return nil
+}
+
+// Stop 停止数据清理服务
+func (h *HousekeepingService) Stop() error {
+	if err := h.scheduler.Shutdown(); err != nil {
+		return fmt.Errorf("关闭数据清理调度器失败: %w", err)
+	}
+	return nil
+}
+
+// Run 按当前数据保留策略执行一次清理
+func (h *HousekeepingService) Run() {
+	config, err := h.db.GetConfig()
+	if err != nil {
+		utils.Logf("[数据清理] ❌ 获取配置失败，跳过本次清理: %v", err)
+		return
+	}
+	retention := RetentionOf(config)
+
+	cutoff := time.Now().Add(-time.Duration(retention.UsageHours) * time.Hour)
+	if retention.ArchiveEnabled && h.archiver != nil {
+		// 原始记录按整月归档：已结束月份的记录不再等待保留时长到期，当月记录保留到月份结束
+		if _, err := h.archiver.ArchiveCompletedMonths(); err != nil {
+			utils.Logf("[数据清理] ⚠️  归档原始使用记录失败: %v", err)
+		}
+	} else if processed, err := h.db.DownsampleUsage(cutoff); err != nil {
+		utils.Logf("[数据清理] ⚠️  降采样原始使用记录失败: %v", err)
+	} else if processed > 0 {
+		utils.Logf("[数据清理] 降采样原始使用记录: %d条记录已聚合为15分钟数据（保留%d小时）", processed, retention.UsageHours)
+	}
+	// 启用到期归档的数据在宽限期内写入归档后删除（先于更新过期时间，关闭归档时宽限期内的数据随即删除）
+	if h.archiver != nil {
+		if _, err := h.archiver.ArchiveExpired(retention); err != nil {
+			utils.Logf("[数据清理] ⚠️  归档到期数据失败: %v", err)
+		}
+	}
+	if updated, err := h.db.ApplyRetentionTTL(retention); err != nil {
+		utils.Logf("[数据清理] ⚠️  更新数据过期时间失败: %v", err)
+	} else if updated > 0 {
+		utils.Logf("[数据清理] 保留策略已变化: 已按新策略更新%d条记录的过期时间（每日统计%d天、余额历史%d天、降采样数据%d天）",
+			updated, retention.DailyUsageDays, retention.BalanceHistoryDays, retention.AggregateDays)
+	}
+}
+
+// RetentionOf 获取配置中生效的数据保留策略（未配置或超出范围的字段使用默认值）
+func RetentionOf(config *models.UserConfig) models.RetentionConfig {
+	if config == nil {
+		return models.GetDefaultConfig().Retention
+	}
+	retention := config.Retention
+	retention.Validate()
+	return retention
+}
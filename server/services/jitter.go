@@ -0,0 +1,36 @@
+package services
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// jobJitter 按间隔执行的上游请求任务（使用数据、积分余额、阈值检查）的随机抖动，0表示不抖动
+var jobJitter atomic.Int64
+
+// SetJobJitter 设置上游请求任务的随机抖动：每次执行间隔在设定间隔±jitter内随机，
+// 避免多个用户或多个实例在同一时刻请求上游。只影响之后创建或更新的任务
+func SetJobJitter(jitter time.Duration) {
+	jobJitter.Store(int64(max(jitter, 0)))
+}
+
+// jitterDelay 整点任务的随机延后时长（0到jitter之间），未设置抖动时为0
+func jitterDelay() time.Duration {
+	jitter := time.Duration(jobJitter.Load())
+	if jitter <= 0 {
+		return 0
+	}
+	return rand.N(jitter)
+}
+
+// intervalJob 按间隔执行的任务定义，设置了抖动时间隔在interval±jitter内随机（抖动不超过间隔的一半）
+func intervalJob(interval time.Duration) gocron.JobDefinition {
+	jitter := min(time.Duration(jobJitter.Load()), interval/2)
+	if jitter <= 0 {
+		return gocron.DurationJob(interval)
+	}
+	return gocron.DurationRandomJob(interval-jitter, interval+jitter)
+}
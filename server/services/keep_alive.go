@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// KeepAliveService Cookie保活服务
+// 监控关闭时按独立的低频间隔请求积分接口，避免上游会话因长期闲置而失效
+type KeepAliveService struct {
+	scheduler    gocron.Scheduler        // 保活专用调度器
+	job          gocron.Job              // 保活任务
+	config       *models.KeepAliveConfig // 当前配置
+	db           *database.BadgerDB      // 数据库访问
+	schedulerSvc *SchedulerService       // 调度器服务（用于判断监控状态）
+	lastPingAt   time.Time               // 最后一次保活请求时间
+	lastPingErr  string                  // 最后一次保活请求错误
+	mu           sync.RWMutex
+}
+
+// NewKeepAliveService 创建Cookie保活服务
+func NewKeepAliveService(db *database.BadgerDB, schedulerSvc *SchedulerService) (*KeepAliveService, error) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建保活调度器失败: %w", err)
+	}
+
+	// 调度器始终运行，任务按需添加/移除
+	scheduler.Start()
+
+	return &KeepAliveService{
+		scheduler:    scheduler,
+		db:           db,
+		schedulerSvc: schedulerSvc,
+	}, nil
+}
+
+// Start 启动Cookie保活服务（从数据库加载配置）
+func (k *KeepAliveService) Start() error {
+	config, err := k.db.GetConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	keepAlive := config.KeepAlive
+	return k.UpdateConfig(&keepAlive)
+}
+
+// Stop 停止Cookie保活服务
+func (k *KeepAliveService) Stop() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.removeJob()
+
+	if k.scheduler != nil {
+		utils.Logf("[Cookie保活] 关闭调度器")
+		if err := k.scheduler.Shutdown(); err != nil {
+			return fmt.Errorf("关闭保活调度器失败: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateConfig 更新保活配置，按需重建任务
+func (k *KeepAliveService) UpdateConfig(config *models.KeepAliveConfig) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	newConfig := *config
+	newConfig.Validate()
+
+	oldConfig := k.config
+	k.config = &newConfig
+
+	// 配置无变化且任务状态一致时无需处理
+	if oldConfig != nil && *oldConfig == newConfig && (k.job != nil) == newConfig.Enabled {
+		utils.Logf("[Cookie保活] 配置无实质性变化，保持当前状态")
+		return nil
+	}
+
+	k.removeJob()
+
+	if !newConfig.Enabled {
+		utils.Logf("[Cookie保活] 保活已禁用")
+		return nil
+	}
+
+	interval := time.Duration(newConfig.IntervalHours) * time.Hour
+	job, err := k.scheduler.NewJob(
+		gocron.DurationJob(interval),
+		gocron.NewTask(k.ping),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("创建保活任务失败: %w", err)
+	}
+
+	k.job = job
+	utils.Logf("[Cookie保活] ✅ 保活任务已创建，间隔: %d小时，任务ID: %v", newConfig.IntervalHours, job.ID())
+	return nil
+}
+
+// GetLastPing 获取最后一次保活请求的时间和错误信息
+func (k *KeepAliveService) GetLastPing() (time.Time, string) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.lastPingAt, k.lastPingErr
+}
+
+// removeJob 移除保活任务（内部方法，无锁）
+func (k *KeepAliveService) removeJob() {
+	if k.job == nil {
+		return
+	}
+
+	if err := k.scheduler.RemoveJob(k.job.ID()); err != nil {
+		utils.Logf("[Cookie保活] ⚠️  移除保活任务失败: %v", err)
+	} else {
+		utils.Logf("[Cookie保活] 保活任务已移除")
+	}
+	k.job = nil
+}
+
+// ping 执行一次保活请求
+func (k *KeepAliveService) ping() {
+	// 监控运行期间上游请求本身即可保持会话，无需额外请求
+	if k.schedulerSvc != nil && k.schedulerSvc.IsRunning() {
+		utils.Logf("[Cookie保活] 监控运行中，跳过本次保活请求")
+		return
+	}
+	if k.schedulerSvc != nil && k.schedulerSvc.IsInMaintenance() {
+		utils.Logf("[Cookie保活] 维护模式中，跳过本次保活请求")
+		return
+	}
+
+	config, err := k.db.GetConfig()
+	if err != nil {
+		utils.Logf("[Cookie保活] ❌ 获取配置失败: %v", err)
+		return
+	}
+
+	if config.Cookie == "" {
+		utils.Logf("[Cookie保活] Cookie未配置，跳过保活请求")
+		return
+	}
+
+	utils.Logf("[Cookie保活] 🔄 发起保活请求")
+	apiClient := client.NewClaudeAPIClient(config.Cookie)
+	_, fetchErr := apiClient.FetchCreditBalance()
+
+	k.mu.Lock()
+	k.lastPingAt = time.Now()
+	if fetchErr != nil {
+		k.lastPingErr = fetchErr.Error()
+	} else {
+		k.lastPingErr = ""
+	}
+	k.mu.Unlock()
+
+	if fetchErr != nil {
+		utils.Logf("[Cookie保活] ❌ 保活请求失败: %v", fetchErr)
+		return
+	}
+
+	// 保活成功同时说明Cookie仍然有效
+	config.LastCookieValidTime = time.Now()
+	if err := k.db.SaveConfig(config); err != nil {
+		utils.Logf("[Cookie保活] ⚠️  保存Cookie验证时间失败: %v", err)
+	}
+
+	utils.Logf("[Cookie保活] ✅ 保活请求成功")
+}
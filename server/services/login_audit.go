@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// RecordAuthFailure 记录接口认证失败（作为auth.AuthFailureHandler使用）
+func (s *SchedulerService) RecordAuthFailure(ip, userAgent, reason string) {
+	utils.Logf("[登录审计] ⚠️  接口认证失败: IP %s, 原因 %s", ip, reason)
+	s.RecordLogin(models.LoginAuditEntry{Time: time.Now(), Reason: reason, IP: ip, UserAgent: userAgent})
+}
+
+// RecordLogin 保存登录记录；新IP登录成功且启用了新IP登录告警时推送通知、触发login_new_ip事件并调用Webhook
+func (s *SchedulerService) RecordLogin(entry models.LoginAuditEntry) {
+	if err := s.db.SaveLoginAudit(&entry); err != nil {
+		utils.Logf("[登录审计] %v", err)
+		return
+	}
+	if !entry.NewIP {
+		return
+	}
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[登录审计] 获取配置失败: %v", err)
+		return
+	}
+	loginAlert := config.LoginAlert
+	if !loginAlert.Enabled {
+		return
+	}
+
+	message := fmt.Sprintf("%s 有新的IP登录成功: %s（%s）", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.IP, entry.UserAgent)
+	data := map[string]string{
+		"ip":        entry.IP,
+		"userAgent": entry.UserAgent,
+	}
+	utils.Logf("[登录审计] 🔑 %s", message)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeLoginNewIP,
+		Title:     "新IP登录",
+		Message:   message,
+		Timestamp: entry.Time,
+	})
+
+	s.mu.RLock()
+	runner := s.hookRunner
+	s.mu.RUnlock()
+	if runner != nil {
+		runner.Run(config.Hooks, models.HookEventLoginNewIP, data)
+	}
+
+	if loginAlert.WebhookURL != "" {
+		payload := models.WebhookPayload{
+			Event:     models.WebhookEventLoginNewIP,
+			Message:   message,
+			Data:      data,
+			Timestamp: entry.Time,
+		}
+		go func() {
+			if err := SendWebhook(loginAlert.WebhookURL, payload); err != nil {
+				utils.Logf("[登录审计] ❌ %v", err)
+				return
+			}
+			utils.Logf("[登录审计] 📤 已调用Webhook")
+		}()
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// ErrMaintenanceMode 维护模式期间拒绝发起上游请求
+var ErrMaintenanceMode = errors.New("维护模式中，已暂停上游请求")
+
+// EnterMaintenance 进入维护模式：暂停所有调用上游的任务（监控、阈值检查、每日统计、保活），到期自动结束
+func (s *SchedulerService) EnterMaintenance(duration time.Duration, reason string) models.MaintenanceStatus {
+	now := time.Now()
+
+	s.maintenanceMu.Lock()
+	if s.maintenanceTimer != nil {
+		s.maintenanceTimer.Stop()
+	}
+	if !s.maintenanceActive {
+		s.maintenanceStartedAt = now
+	}
+	s.maintenanceActive = true
+	s.maintenanceUntil = now.Add(duration)
+	s.maintenanceReason = reason
+	s.maintenanceTimer = time.AfterFunc(duration, func() {
+		s.ExitMaintenance("到期自动结束")
+	})
+	s.maintenanceMu.Unlock()
+
+	utils.Logf("[维护模式] 🚧 已进入维护模式，持续%v，原因: %s", duration, reason)
+
+	status := s.GetMaintenanceStatus()
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeMaintenance,
+		Title:     "维护模式已开启",
+		Message:   fmt.Sprintf("已暂停上游请求，将于 %s 自动结束", status.ExpiresAt.Format("2006-01-02 15:04")),
+		Timestamp: now,
+	})
+	s.NotifyAutoScheduleChange()
+
+	return status
+}
+
+// ExitMaintenance 结束维护模式
+func (s *SchedulerService) ExitMaintenance(reason string) {
+	s.maintenanceMu.Lock()
+	wasActive := s.maintenanceActive
+	if s.maintenanceTimer != nil {
+		s.maintenanceTimer.Stop()
+		s.maintenanceTimer = nil
+	}
+	s.maintenanceActive = false
+	s.maintenanceUntil = time.Time{}
+	s.maintenanceStartedAt = time.Time{}
+	s.maintenanceReason = ""
+	s.maintenanceMu.Unlock()
+
+	// 定时器与手动结束可能先后触发，仅在确实处于维护模式时通知
+	if !wasActive {
+		return
+	}
+
+	utils.Logf("[维护模式] ✅ 维护模式已结束（%s）", reason)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeMaintenance,
+		Title:     "维护模式已结束",
+		Message:   fmt.Sprintf("维护模式已结束（%s），上游请求已恢复", reason),
+		Timestamp: time.Now(),
+	})
+	s.NotifyAutoScheduleChange()
+}
+
+// IsInMaintenance 是否处于维护模式
+func (s *SchedulerService) IsInMaintenance() bool {
+	s.maintenanceMu.RLock()
+	defer s.maintenanceMu.RUnlock()
+	return s.maintenanceActive && time.Now().Before(s.maintenanceUntil)
+}
+
+// GetMaintenanceStatus 获取维护模式状态
+func (s *SchedulerService) GetMaintenanceStatus() models.MaintenanceStatus {
+	s.maintenanceMu.RLock()
+	defer s.maintenanceMu.RUnlock()
+
+	if !s.maintenanceActive || !time.Now().Before(s.maintenanceUntil) {
+		return models.MaintenanceStatus{Enabled: false}
+	}
+
+	startedAt := s.maintenanceStartedAt
+	expiresAt := s.maintenanceUntil
+	return models.MaintenanceStatus{
+		Enabled:   true,
+		Reason:    s.maintenanceReason,
+		StartedAt: &startedAt,
+		ExpiresAt: &expiresAt,
+	}
+}
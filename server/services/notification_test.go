@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestDeliverNotificationReplay(t *testing.T) {
+	s := &SchedulerService{}
+	delivered := make(chan string, 4)
+
+	// 没有连接时暂存，同类型只保留最新一条
+	s.DeliverNotification(models.Notification{Type: models.NotificationTypeUpdate, Message: "v1.1.0"}, func() { delivered <- "v1.1.0" })
+	s.DeliverNotification(models.Notification{Type: models.NotificationTypeUpdate, Message: "v1.2.0"}, func() { delivered <- "v1.2.0" })
+	s.DeliverNotification(models.Notification{Type: models.NotificationTypeSchemaDrift, Message: "drift"}, nil)
+
+	select {
+	case name := <-delivered:
+		t.Fatalf("没有连接时不应标记为已送达: %s", name)
+	default:
+	}
+
+	listener := s.AddNotificationListener()
+	var messages []string
+	for len(listener) > 0 {
+		messages = append(messages, (<-listener).Message)
+	}
+	if len(messages) != 2 || messages[0] != "v1.2.0" || messages[1] != "drift" {
+		t.Fatalf("补发的通知 = %v", messages)
+	}
+
+	select {
+	case name := <-delivered:
+		if name != "v1.2.0" {
+			t.Fatalf("送达回调 = %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("补发后未调用送达回调")
+	}
+
+	// 已补发的通知不会再次推送给新连接
+	if second := s.AddNotificationListener(); len(second) != 0 {
+		t.Fatalf("新连接收到了 %d 条已补发的通知", len(second))
+	}
+}
+
+func TestDeliverNotificationConnected(t *testing.T) {
+	s := &SchedulerService{}
+	listener := s.AddNotificationListener()
+	delivered := make(chan struct{}, 1)
+
+	s.DeliverNotification(models.Notification{Type: models.NotificationTypeUpdate}, func() { delivered <- struct{}{} })
+
+	if len(listener) != 1 {
+		t.Fatal("已连接时应直接推送")
+	}
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("未调用送达回调")
+	}
+}
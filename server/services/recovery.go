@@ -0,0 +1,43 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// recoveryModel 根据自动重置配置和保留期内的积分余额历史确定积分恢复模型
+func (s *SchedulerService) recoveryModel(config *models.UserConfig, now time.Time) (models.RecoveryModel, error) {
+	history, err := s.db.GetBalanceHistorySince(now.AddDate(0, 0, -RetentionOf(config).BalanceHistoryDays))
+	if err != nil {
+		return models.RecoveryModel{}, fmt.Errorf("获取积分余额历史失败: %w", err)
+	}
+	return models.NewRecoveryModel(config.AutoReset.RecoveryPerHour, config.AutoReset.RecoveryCap, history), nil
+}
+
+// GetBalanceCurve 获取最近hours小时的实际余额与按恢复模型推算的预期余额
+// 预期余额扣除的是本机记录的使用量，受原始使用记录保留时长限制
+func (s *SchedulerService) GetBalanceCurve(hours int) (models.BalanceCurve, error) {
+	config := s.GetConfig()
+	now := time.Now()
+	model, err := s.recoveryModel(config, now)
+	if err != nil {
+		return models.BalanceCurve{}, err
+	}
+
+	since := now.Add(-time.Duration(hours) * time.Hour)
+	history, err := s.db.GetBalanceHistorySince(since)
+	if err != nil {
+		return models.BalanceCurve{}, fmt.Errorf("获取积分余额历史失败: %w", err)
+	}
+	usage, err := s.db.GetUsageData(hours * 60)
+	if err != nil {
+		return models.BalanceCurve{}, fmt.Errorf("获取使用记录失败: %w", err)
+	}
+	resets, err := s.db.GetResetRecordsSince(since)
+	if err != nil {
+		return models.BalanceCurve{}, fmt.Errorf("获取积分重置记录失败: %w", err)
+	}
+	return models.NewBalanceCurve(history, usage, resets, model), nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// ResetLock 多实例协调自动重置的锁：同一账户的多个实例中，每个重置周期仅允许一个实例执行自动重置
+// period为重置周期标识（models.ResetClockConfig.PeriodKey）
+type ResetLock interface {
+	// TryAcquire 尝试获取指定重置周期的自动重置权，返回是否获取成功以及当前持有者
+	TryAcquire(period string) (bool, string, error)
+	// Release 释放本实例持有的指定重置周期的自动重置权（重置失败时调用，允许其他实例重试）
+	Release(period string) error
+}
+
+// resetLockKeepDays 锁文件保留天数
+const resetLockKeepDays = 7
+
+// resetLockRecord 锁文件内容
+type resetLockRecord struct {
+	Owner      string    `json:"owner"`      // 持有者实例标识
+	AcquiredAt time.Time `json:"acquiredAt"` // 获取时间
+}
+
+// FileResetLock 基于共享目录的重置锁
+// 各实例挂载同一目录（如NFS、SMB共享），以独占创建每个重置周期的锁文件的方式争抢该周期的自动重置权
+type FileResetLock struct {
+	dir   string // 共享目录
+	owner string // 本实例标识
+}
+
+// NewFileResetLock 创建基于共享目录的重置锁，owner为空时使用"主机名:进程号"
+func NewFileResetLock(dir, owner string) (*FileResetLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建重置锁目录失败: %w", err)
+	}
+	if owner == "" {
+		hostname, _ := os.Hostname()
+		owner = fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	}
+	return &FileResetLock{dir: dir, owner: owner}, nil
+}
+
+// Owner 获取本实例标识
+func (l *FileResetLock) Owner() string {
+	return l.owner
+}
+
+// TryAcquire 尝试获取指定重置周期的自动重置权
+func (l *FileResetLock) TryAcquire(period string) (bool, string, error) {
+	l.cleanup(period)
+
+	path := l.path(period)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err == nil {
+		data, _ := json.Marshal(resetLockRecord{Owner: l.owner, AcquiredAt: time.Now()})
+		_, writeErr := file.Write(data)
+		if closeErr := file.Close(); writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			os.Remove(path)
+			return false, "", fmt.Errorf("写入重置锁文件失败: %w", writeErr)
+		}
+		return true, l.owner, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return false, "", fmt.Errorf("创建重置锁文件失败: %w", err)
+	}
+
+	record, err := l.read(path)
+	if err != nil {
+		return false, "", err
+	}
+	return record.Owner == l.owner, record.Owner, nil
+}
+
+// Release 释放本实例持有的指定重置周期的自动重置权
+func (l *FileResetLock) Release(period string) error {
+	path := l.path(period)
+	record, err := l.read(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if record.Owner != l.owner {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除重置锁文件失败: %w", err)
+	}
+	return nil
+}
+
+// path 获取指定重置周期的锁文件路径
+func (l *FileResetLock) path(period string) string {
+	return filepath.Join(l.dir, "reset-"+period+".lock")
+}
+
+// read 读取锁文件
+func (l *FileResetLock) read(path string) (*resetLockRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取重置锁文件失败: %w", err)
+	}
+	var record resetLockRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		// 其他实例可能正在写入，按未知持有者处理
+		return &resetLockRecord{Owner: "unknown"}, nil
+	}
+	return &record, nil
+}
+
+// cleanup 清理过期的锁文件
+func (l *FileResetLock) cleanup(period string) {
+	start, err := models.ParsePeriodKey(period)
+	if err != nil {
+		return
+	}
+	cutoff := start.AddDate(0, 0, -resetLockKeepDays)
+
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "reset-") || !strings.HasSuffix(name, ".lock") {
+			continue
+		}
+		lockStart, err := models.ParsePeriodKey(strings.TrimSuffix(strings.TrimPrefix(name, "reset-"), ".lock"))
+		if err != nil {
+			continue
+		}
+		if lockStart.Before(cutoff) {
+			if err := os.Remove(filepath.Join(l.dir, name)); err == nil {
+				utils.Logf("[重置锁] 已清理过期锁文件: %s", name)
+			}
+		}
+	}
+}
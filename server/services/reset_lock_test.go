@@ -0,0 +1,74 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestFileResetLockPerPeriod(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewFileResetLock(dir, "a")
+	if err != nil {
+		t.Fatalf("创建重置锁失败: %v", err)
+	}
+	second, _ := NewFileResetLock(dir, "b")
+
+	clock := models.ResetClockConfig{Time: "08:00", Timezone: "Asia/Shanghai"}
+	loc := clock.Location()
+	period := clock.PeriodKey(time.Date(2025, 1, 2, 9, 0, 0, 0, loc))
+
+	if ok, _, err := first.TryAcquire(period); err != nil || !ok {
+		t.Fatalf("首个实例应获得重置权: ok=%v err=%v", ok, err)
+	}
+	// 同一周期内（跨过日历日期但未到清除时刻）另一实例不能再次获得
+	samePeriod := clock.PeriodKey(time.Date(2025, 1, 3, 7, 0, 0, 0, loc))
+	ok, holder, err := second.TryAcquire(samePeriod)
+	if err != nil || ok || holder != "a" {
+		t.Fatalf("同一周期应由首个实例持有: ok=%v holder=%s err=%v", ok, holder, err)
+	}
+
+	// 释放后其他实例可重试
+	if err := first.Release(period); err != nil {
+		t.Fatalf("释放重置权失败: %v", err)
+	}
+	if ok, _, _ := second.TryAcquire(period); !ok {
+		t.Fatal("释放后其他实例应可获得重置权")
+	}
+
+	// 下一周期重新争抢
+	next := clock.PeriodKey(time.Date(2025, 1, 3, 8, 0, 0, 0, loc))
+	if ok, _, _ := first.TryAcquire(next); !ok {
+		t.Fatal("下一周期应可重新获得重置权")
+	}
+}
+
+func TestFileResetLockCleanup(t *testing.T) {
+	dir := t.TempDir()
+	lock, _ := NewFileResetLock(dir, "a")
+
+	stale := []string{"reset-2024-12-01T0000Z.lock"}
+	kept := []string{"reset-2024-12-30T0000Z.lock"}
+	for _, name := range append(stale, kept...) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ok, _, err := lock.TryAcquire("2025-01-02T0000Z"); err != nil || !ok {
+		t.Fatalf("获取重置权失败: ok=%v err=%v", ok, err)
+	}
+	for _, name := range stale {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("过期锁文件 %s 应被清理", name)
+		}
+	}
+	for _, name := range kept {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("未过期锁文件 %s 不应被清理: %v", name, err)
+		}
+	}
+}
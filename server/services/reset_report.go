@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// GetResetReport 生成最近days天（含今日）的重置效果报告：每次重置获得的积分和重置后当日实际使用的积分
+func (s *SchedulerService) GetResetReport(days int) (models.ResetReport, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	records, err := s.db.GetResetRecordsSince(today.AddDate(0, 0, -(days - 1)))
+	if err != nil {
+		return models.ResetReport{}, fmt.Errorf("获取积分重置记录失败: %w", err)
+	}
+
+	todayDate := models.GetLocalDate(now)
+	usageRecords := s.usage.Records()
+	usageByDate := make(map[string]*models.DailyUsage)
+	effects := make([]models.ResetEffect, 0, len(records))
+	for _, record := range records {
+		before, err := s.db.GetBalanceHistoryBefore(record.Time)
+		if err != nil {
+			return models.ResetReport{}, fmt.Errorf("获取积分余额历史失败: %w", err)
+		}
+		after, err := s.db.GetFirstBalanceHistorySince(record.Time)
+		if err != nil {
+			return models.ResetReport{}, fmt.Errorf("获取积分余额历史失败: %w", err)
+		}
+
+		date := models.GetLocalDate(record.Time)
+		usage, ok := usageByDate[date]
+		if !ok {
+			if date == todayDate {
+				today := s.GetTodayUsage()
+				usage = &today
+			} else if usage, err = s.db.GetDailyUsage(date); err != nil {
+				return models.ResetReport{}, fmt.Errorf("获取每日积分统计失败: %w", err)
+			}
+			usageByDate[date] = usage
+		}
+
+		effects = append(effects, models.NewResetEffect(record, before, after, usage, usageRecords, now))
+	}
+	return models.NewResetReport(days, effects), nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// ErrResetSuggestionUnavailable 缺少按小时的每日积分统计或积分余额历史，无法计算建议重置时间
+var ErrResetSuggestionUnavailable = errors.New("历史数据不足，暂无建议重置时间")
+
+// SuggestResetTime 根据最近的每日积分统计（按小时的使用曲线）和积分恢复速度计算建议的每日重置时间
+func (s *SchedulerService) SuggestResetTime() (*models.ResetSuggestion, error) {
+	config := s.GetConfig()
+	retention := RetentionOf(config)
+	now := time.Now()
+
+	// 多取一天，跳过数据不完整的今日
+	days := min(models.ResetSuggestionDays, retention.DailyUsageDays)
+	usageList, err := s.db.GetRecentDailyUsage(days + 1)
+	if err != nil {
+		return nil, fmt.Errorf("获取每日积分统计失败: %w", err)
+	}
+	hourly, counted := usageList.AverageHourlyCredits(models.GetLocalDate(now))
+	if counted == 0 {
+		return nil, ErrResetSuggestionUnavailable
+	}
+
+	model, err := s.recoveryModel(config, now)
+	if err != nil {
+		return nil, err
+	}
+	if model.Capacity <= 0 {
+		return nil, ErrResetSuggestionUnavailable
+	}
+
+	suggestion := models.SuggestResetTime(hourly, counted, model)
+	if config.AutoReset.TimeEnabled && config.AutoReset.ResetTime != "" {
+		suggestion = suggestion.WithCurrent(config.AutoReset.ResetTime)
+	}
+	return &suggestion, nil
+}
+
+// adoptSuggestedResetTime 启用自动采用时，若建议的重置时间优于当前定时重置时间（可满足更多使用量，或相同时重置补充更多积分），则改用建议时间并推送通知
+// 在每个重置周期开始时执行，当日的定时重置随之调整
+func (s *SchedulerService) adoptSuggestedResetTime() {
+	config, err := s.db.GetConfig()
+	if err != nil {
+		utils.Logf("[建议重置时间] 获取配置失败: %v", err)
+		return
+	}
+	autoReset := config.AutoReset
+	if !autoReset.Enabled || !autoReset.TimeEnabled || !autoReset.AdoptSuggestion {
+		return
+	}
+
+	suggestion, err := s.SuggestResetTime()
+	if err != nil {
+		utils.Logf("[建议重置时间] ⚠️  %v", err)
+		return
+	}
+	if current := suggestion.Current; current != nil {
+		suggested := suggestion.Suggested
+		if suggested.Usable < current.Usable || (suggested.Usable == current.Usable && suggested.ResetGain <= current.ResetGain) {
+			return
+		}
+	}
+
+	previous := autoReset.ResetTime
+	config.AutoReset.ResetTime = suggestion.Suggested.Time
+	if err := s.db.SaveConfig(config); err != nil {
+		utils.Logf("[建议重置时间] ❌ 保存配置失败: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.config != nil {
+		s.config.AutoReset = config.AutoReset
+	}
+	autoResetService := s.autoResetService
+	s.mu.Unlock()
+
+	if autoResetService != nil {
+		if err := autoResetService.UpdateConfig(&config.AutoReset); err != nil {
+			utils.Logf("[建议重置时间] ❌ 更新自动重置任务失败: %v", err)
+		}
+	}
+
+	message := fmt.Sprintf("定时重置时间已调整为建议的 %s（预计每日可用积分 %d）", suggestion.Suggested.Time, suggestion.Suggested.Usable)
+	if previous != "" {
+		message = fmt.Sprintf("定时重置时间已由 %s 调整为建议的 %s（预计每日可用积分 %d）", previous, suggestion.Suggested.Time, suggestion.Suggested.Usable)
+	}
+	utils.Logf("[建议重置时间] ✅ %s", message)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeResetTimeAdopted,
+		Title:     "已采用建议的重置时间",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// GetRuntimeState 获取运行时状态快照（调度器、自动重置、健康监督、监听器和缓存）
+func (s *SchedulerService) GetRuntimeState() models.RuntimeState {
+	now := time.Now()
+	state := models.RuntimeState{
+		Upstream:   client.GetLatencyStats(),
+		Failures:   client.GetFailureStats(),
+		Outbound:   client.GetOutboundLimitState(),
+		Goroutines: runtime.NumGoroutine(),
+		Timestamp:  now,
+	}
+
+	s.mu.RLock()
+	state.Scheduler = models.SchedulerState{
+		Running:            s.isRunning,
+		BalanceTaskRunning: s.balanceJob != nil && s.isRunning,
+		DailyResetUsed:     s.config != nil && s.config.IsDailyResetUsed(now),
+	}
+	if s.isRunning {
+		state.Scheduler.FetchInterval = s.currentInterval()
+	}
+	state.Listeners = map[string]int{
+		"usage":        len(s.listeners),
+		"balance":      len(s.balanceListeners) - s.internalBalanceCount,
+		"error":        len(s.errorListeners),
+		"resetStatus":  len(s.resetStatusListeners),
+		"autoSchedule": len(s.autoScheduleListeners),
+		"dailyUsage":   len(s.dailyUsageListeners),
+		"notification": len(s.notificationListeners),
+		"health":       len(s.healthListeners),
+		"config":       len(s.configListeners),
+		"tick":         len(s.tickListeners),
+		"annotation":   len(s.annotationListeners),
+	}
+	records, latest := s.usage.Stats()
+	state.Cache.UsageRecords = records
+	if records > 0 {
+		state.Cache.LatestUsageAt = &latest
+	}
+	if s.lastBalance != nil {
+		updatedAt := s.lastBalance.UpdatedAt
+		age := int64(now.Sub(updatedAt).Seconds())
+		state.Cache.BalanceUpdatedAt = &updatedAt
+		state.Cache.BalanceAgeSeconds = &age
+	}
+	autoResetService := s.autoResetService
+	healthSupervisor := s.healthSupervisor
+	dailyUsageTracker := s.dailyUsageTracker
+	s.mu.RUnlock()
+
+	// 以下调用各自加锁，须在释放调度器锁之后进行
+	state.Scheduler.AutoScheduleEnabled = s.IsAutoScheduleEnabled()
+	state.Scheduler.InAutoScheduleRange = s.IsInAutoScheduleTimeRange()
+	if dailyUsageTracker != nil {
+		state.Scheduler.DailyUsageActive = dailyUsageTracker.IsActive()
+	}
+	state.Maintenance = s.GetMaintenanceStatus()
+
+	if autoResetService != nil {
+		autoResetState := autoResetService.GetRuntimeState()
+		state.AutoReset = &autoResetState
+	}
+	if healthSupervisor != nil {
+		healthState := healthSupervisor.GetRuntimeState()
+		state.Health = &healthState
+	}
+
+	if lastError, lastErrorAt := s.db.LastError(); lastError != "" {
+		state.Database = models.DatabaseState{LastError: lastError, LastErrorAt: &lastErrorAt}
+	}
+
+	return state
+}
+
+// GetRuntimeState 获取自动重置服务的任务状态
+func (s *AutoResetService) GetRuntimeState() models.AutoResetState {
+	// 阈值检查随监控任务取得的积分余额执行，监控未运行时不会检查（在加锁前获取，避免与调度器锁嵌套）
+	monitoring := s.schedulerSvc.IsRunning()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return models.AutoResetState{
+		Enabled:          s.config != nil && s.config.Enabled,
+		TasksCreated:     s.tasksCreated,
+		TasksRunning:     s.tasksRunning,
+		ThresholdRunning: s.thresholdRunning,
+		ThresholdActive: s.thresholdRunning && monitoring &&
+			(!s.config.ThresholdTimeEnabled || s.config.IsInThresholdTimeRange(time.Now())),
+	}
+}
+
+// GetRuntimeState 获取健康监督服务的内部状态
+func (h *HealthSupervisor) GetRuntimeState() models.HealthDebugState {
+	state := models.HealthDebugState{HealthState: h.GetState()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	state.ConsecutiveFailures = h.consecutiveFailures
+	state.LastUpstreamError = h.lastUpstreamError
+	state.CookieExpired = h.cookieExpired
+	if !h.lastSuccessAt.IsZero() {
+		lastSuccessAt := h.lastSuccessAt
+		state.LastSuccessAt = &lastSuccessAt
+	}
+	return state
+}
+
+// GetRuntimeState 获取异步配置任务的队列状态
+func (a *AsyncConfigUpdater) GetRuntimeState() models.ConfigJobsState {
+	state := models.ConfigJobsState{
+		Running:   a.IsRunning(),
+		QueueSize: a.GetQueueSize(),
+	}
+
+	if pending, err := a.db.GetPendingConfigJobs(); err == nil {
+		state.Pending = len(pending)
+	}
+	if dead, err := a.db.GetDeadConfigJobs(); err == nil {
+		state.Dead = len(dead)
+	}
+	return state
+}
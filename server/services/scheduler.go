@@ -1,9 +1,13 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,19 +27,56 @@ type SchedulerService struct {
 	config                *models.UserConfig
 	isRunning             bool
 	mu                    sync.RWMutex
-	lastData              []models.UsageData
+	usage                 *UsageBuffer // 最近的使用记录（有界缓冲）
 	listeners             []chan []models.UsageData
 	lastBalance           *models.CreditBalance
 	balanceListeners      []chan *models.CreditBalance
-	errorListeners        []chan string
+	internalBalanceCount  int // balanceListeners中服务内部订阅（如阈值检查）的数量，不计入运行状态的监听器数
+	errorListeners        []chan models.ErrorEvent
 	resetStatusListeners  []chan bool
 	autoScheduler         *AutoSchedulerService
 	autoScheduleListeners []chan bool                // 自动调度状态变化监听器
 	dailyUsageListeners   []chan []models.DailyUsage // 每日积分统计数据监听器
+	notificationListeners []chan models.Notification // 通知消息监听器
+	pendingNotifications  []pendingNotification      // 没有SSE连接时暂存、待补发的通知
+	healthListeners       []chan models.HealthState  // 健康状态监听器
+	latestHealth          *models.HealthState        // 最新健康状态
+	healthSupervisor      *HealthSupervisor          // 健康监督服务
+	usageJob              gocron.Job                 // 使用数据任务引用
 	balanceJob            gocron.Job                 // 积分余额任务引用
-	balanceTaskPaused     bool                       // 积分余额任务暂停状态
+	fetchInterval         int                        // 当前生效的获取间隔（秒），启用自适应获取间隔时随活跃度变化
+	dailyResetJob         gocron.Job                 // 每日重置标记清除任务引用
 	autoResetService      *AutoResetService          // 自动重置服务引用
 	dailyUsageTracker     *DailyUsageTracker         // 每日积分统计跟踪服务
+	hookRunner            *HookRunner                // 事件Hook执行器
+	todayUsage            *TodayUsageCounter         // 今日积分使用量实时累计
+	notificationSinks     []NotificationSink         // 外部通知渠道
+
+	// 配置变更推送
+	configListeners   []chan *models.UserConfigResponse // 配置变更监听器
+	lastConfigPayload []byte                            // 最近一次推送的配置（用于跳过未变化的配置）
+	configPushMu      sync.Mutex                        // 保证推送串行执行
+
+	// 定时任务执行事件推送
+	tickListeners []chan models.JobTick
+
+	// 图表标注变化推送
+	annotationListeners []chan models.AnnotationEvent
+
+	// 中转站错误率告警
+	relayErrorRate    int       // 告警阈值（百分比，0表示不告警）
+	relayErrorAlertAt time.Time // 最近一次告警时间
+
+	// 外部使用告警
+	externalUsageAlertAt time.Time // 最近一次告警时间
+
+	// 维护模式状态（独立锁，避免与任务锁相互阻塞）
+	maintenanceActive    bool
+	maintenanceUntil     time.Time
+	maintenanceStartedAt time.Time
+	maintenanceReason    string
+	maintenanceTimer     *time.Timer
+	maintenanceMu        sync.RWMutex
 }
 
 // NewSchedulerService 创建新的调度服务
@@ -56,6 +97,9 @@ func NewSchedulerService(db *database.BadgerDB) (*SchedulerService, error) {
 		log.Printf("获取配置失败，使用默认配置: %v", err)
 		config = models.GetDefaultConfig()
 	}
+	models.SetModelAliases(config.ModelAliases)
+	models.SetModelGroups(config.ModelGroups)
+	client.SetTransportConfig(config.HTTPTransport)
 
 	apiClient := client.NewClaudeAPIClient(config.Cookie)
 
@@ -66,14 +110,26 @@ func NewSchedulerService(db *database.BadgerDB) (*SchedulerService, error) {
 		apiClient:             apiClient,
 		config:                config,
 		isRunning:             false,
+		usage:                 NewUsageBuffer(DefaultUsageBufferSize, DefaultUsageBufferMaxAge),
 		listeners:             make([]chan []models.UsageData, 0),
 		balanceListeners:      make([]chan *models.CreditBalance, 0),
-		errorListeners:        make([]chan string, 0),
+		errorListeners:        make([]chan models.ErrorEvent, 0),
 		resetStatusListeners:  make([]chan bool, 0),
 		autoScheduleListeners: make([]chan bool, 0),
 		dailyUsageListeners:   make([]chan []models.DailyUsage, 0),
+		notificationListeners: make([]chan models.Notification, 0),
+		healthListeners:       make([]chan models.HealthState, 0),
+		configListeners:       make([]chan *models.UserConfigResponse, 0),
+		tickListeners:         make([]chan models.JobTick, 0),
+		annotationListeners:   make([]chan models.AnnotationEvent, 0),
+		todayUsage:            NewTodayUsageCounter(db),
 	}
 
+	// 配置保存后推送给所有连接（保存时可能持有调度器锁，异步处理）
+	db.SetConfigSavedHandler(func(*models.UserConfig) {
+		go service.broadcastConfigChange()
+	})
+
 	// 创建自动调度服务
 	service.autoScheduler = NewAutoSchedulerService(service)
 
@@ -83,6 +139,7 @@ func NewSchedulerService(db *database.BadgerDB) (*SchedulerService, error) {
 		utils.Logf("[调度器] ❌ 创建每日积分统计服务失败: %v", err)
 	} else {
 		service.dailyUsageTracker = dailyUsageTracker
+		dailyUsageTracker.SetPausedFunc(service.IsInMaintenance)
 		utils.Logf("[调度器] ✅ 每日积分统计服务创建成功（独立调度器已启动）")
 
 		// 立即初始化每日积分统计服务（程序启动时就初始化）
@@ -115,22 +172,59 @@ func NewSchedulerService(db *database.BadgerDB) (*SchedulerService, error) {
 
 // createDailyResetTask 创建每日重置任务
 func (s *SchedulerService) createDailyResetTask() error {
-	// 添加每日0点重置标记的定时任务
+	config, err := s.db.GetConfig()
+	if err != nil {
+		config = models.GetDefaultConfig()
+	}
+
+	// 按配置的重置周期（默认本地0点）清除重置标记
+	cronExpr := config.ResetClock.CronExpression()
 	dailyResetJob, err := s.dailyResetScheduler.NewJob(
-		gocron.CronJob("0 0 * * *", false), // 每日0点执行
+		gocron.CronJob(cronExpr, false),
 		gocron.NewTask(s.resetDailyFlags),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
 	if err != nil {
 		return fmt.Errorf("创建每日重置标记定时任务失败: %w", err)
 	}
+	s.dailyResetJob = dailyResetJob
 
-	log.Printf("每日重置标记定时任务创建成功，任务ID: %v", dailyResetJob.ID())
+	log.Printf("每日重置标记定时任务创建成功，任务ID: %v，执行时间: %s", dailyResetJob.ID(), cronExpr)
 
 	// 启动每日重置调度器
 	s.dailyResetScheduler.Start()
 	log.Printf("每日重置调度器已启动")
 
+	// 服务停机期间错过了清除时刻时立即补清除
+	if config.DailyResetUsed && !config.IsDailyResetUsed(time.Now()) {
+		log.Printf("重置标记属于已结束的重置周期，立即清除")
+		if err := s.resetDailyFlags(); err != nil {
+			log.Printf("清除过期重置标记失败: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// updateDailyResetTask 按新的重置周期配置更新清除标记任务的执行时间
+func (s *SchedulerService) updateDailyResetTask(clock models.ResetClockConfig) error {
+	if s.dailyResetJob == nil {
+		return fmt.Errorf("每日重置标记定时任务未创建")
+	}
+
+	cronExpr := clock.CronExpression()
+	job, err := s.dailyResetScheduler.Update(
+		s.dailyResetJob.ID(),
+		gocron.CronJob(cronExpr, false),
+		gocron.NewTask(s.resetDailyFlags),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("更新每日重置标记定时任务失败: %w", err)
+	}
+	s.dailyResetJob = job
+
+	log.Printf("每日重置标记定时任务已更新，执行时间: %s", cronExpr)
 	return nil
 }
 
@@ -164,57 +258,43 @@ func (s *SchedulerService) Start() error {
 		return fmt.Errorf("cookie验证失败: %w", cookieErr)
 	}
 
-	// 添加使用数据定时任务
+	// 添加使用数据定时任务（启用自适应获取间隔时按当前活跃度选择间隔）
+	interval := s.targetInterval()
 	usageJob, err := s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
+		intervalJob(time.Duration(interval)*time.Second),
 		gocron.NewTask(s.fetchAndSaveData),
+		gocron.WithName(models.JobNameUsage),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
 	if err != nil {
 		return fmt.Errorf("创建使用数据定时任务失败: %w", err)
 	}
+	s.usageJob = usageJob
+	s.fetchInterval = interval
 
-	// 检查是否有阈值任务正在运行
-	var shouldCreateBalanceTask = true
-	if s.autoResetService != nil && s.autoResetService.IsThresholdTaskRunning() {
-		utils.Logf("[任务协调] ⚠️  检测到阈值任务正在运行，跳过积分余额任务创建")
-		shouldCreateBalanceTask = false
-		s.balanceTaskPaused = true
-		s.balanceJob = nil
-	}
-
-	if shouldCreateBalanceTask {
-		// 添加积分余额定时任务，间隔错开20秒执行
-		balanceJob, err := s.scheduler.NewJob(
-			gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
-			gocron.NewTask(s.fetchAndSaveBalance),
-			gocron.WithSingletonMode(gocron.LimitModeReschedule),
-			gocron.WithStartAt(
-				gocron.WithStartDateTime(time.Now().Add(20*time.Second)),
-			),
-		)
-		if err != nil {
-			return fmt.Errorf("创建积分余额定时任务失败: %w", err)
-		}
-
-		// 保存积分余额任务引用
-		s.balanceJob = balanceJob
-		s.balanceTaskPaused = false
-		utils.Logf("[任务协调] ✅ 积分余额定时任务创建成功，任务ID: %v，间隔: %d秒", balanceJob.ID(), s.config.Interval)
+	// 添加积分余额定时任务，间隔错开20秒执行（阈值检查订阅该任务取得的余额）
+	balanceJob, err := s.scheduler.NewJob(
+		intervalJob(time.Duration(interval)*time.Second),
+		gocron.NewTask(s.fetchAndSaveBalance),
+		gocron.WithName(models.JobNameBalance),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithStartAt(
+			gocron.WithStartDateTime(time.Now().Add(20*time.Second)),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("创建积分余额定时任务失败: %w", err)
 	}
+	s.balanceJob = balanceJob
 
-	log.Printf("使用数据定时任务创建成功，任务ID: %v，间隔: %d秒", usageJob.ID(), s.config.Interval)
-	if shouldCreateBalanceTask && s.balanceJob != nil {
-		log.Printf("积分余额定时任务创建成功，任务ID: %v，间隔: %d秒", s.balanceJob.ID(), s.config.Interval)
-	} else {
-		log.Printf("积分余额定时任务已跳过创建（检测到阈值任务冲突）")
-	}
+	log.Printf("使用数据定时任务创建成功，任务ID: %v，间隔: %d秒", usageJob.ID(), interval)
+	log.Printf("积分余额定时任务创建成功，任务ID: %v，间隔: %d秒", balanceJob.ID(), interval)
 
 	// 启动调度器
 	s.scheduler.Start()
 	s.isRunning = true
 
-	log.Printf("定时任务已启动，间隔: %d秒", s.config.Interval)
+	log.Printf("定时任务已启动，间隔: %d秒", interval)
 
 	// 每日积分统计任务已在初始化时根据配置激活，无需重复处理
 
@@ -222,13 +302,9 @@ func (s *SchedulerService) Start() error {
 	go func() {
 		time.Sleep(100 * time.Millisecond) // 短暂延迟，确保SSE连接已建立
 		s.fetchAndSaveData()
-		// 延迟5秒后获取积分余额，避免并发（仅在没有阈值任务冲突时执行）
-		if shouldCreateBalanceTask {
-			time.Sleep(5 * time.Second)
-			s.fetchAndSaveBalance()
-		} else {
-			utils.Logf("[任务协调] ⚠️  跳过立即执行积分获取（阈值任务冲突）")
-		}
+		// 延迟5秒后获取积分余额，避免并发
+		time.Sleep(5 * time.Second)
+		s.fetchAndSaveBalance()
 	}()
 
 	return nil
@@ -445,6 +521,7 @@ func (s *SchedulerService) UpdateConfigSync(newConfig *models.UserConfig) error
 		// 创建旧配置的副本
 		oldConfig = &models.UserConfig{
 			DailyUsageEnabled: s.config.DailyUsageEnabled,
+			ResetClock:        s.config.ResetClock,
 			// 只需要复制用于比较的字段
 		}
 	}
@@ -463,9 +540,30 @@ func (s *SchedulerService) UpdateConfigSync(newConfig *models.UserConfig) error
 		s.config.TimeRange = newConfig.TimeRange
 		// 更新每日积分统计配置
 		s.config.DailyUsageEnabled = newConfig.DailyUsageEnabled
+		s.config.Retention = newConfig.Retention
+		s.config.ModelAliases = newConfig.ModelAliases
+		s.config.ModelGroups = newConfig.ModelGroups
+		s.config.ResetClock = newConfig.ResetClock
+		s.config.Account = newConfig.Account
+		s.config.AdaptiveInterval = newConfig.AdaptiveInterval
 	}
 	s.mu.Unlock()
 
+	// 自适应获取间隔配置变化时直接更新任务间隔，无需重启
+	go s.adaptInterval()
+
+	// 重置周期变化时更新清除标记任务
+	if oldConfig != nil && oldConfig.ResetClock != newConfig.ResetClock {
+		if err := s.updateDailyResetTask(newConfig.ResetClock); err != nil {
+			log.Printf("[同步配置] %v", err)
+		}
+	}
+
+	// 模型别名和分组、上游连接池立即生效
+	models.SetModelAliases(newConfig.ModelAliases)
+	models.SetModelGroups(newConfig.ModelGroups)
+	client.SetTransportConfig(newConfig.HTTPTransport)
+
 	log.Printf("[同步配置] 配置已同步保存到数据库")
 
 	// 处理每日积分统计配置变更
@@ -493,20 +591,25 @@ func (s *SchedulerService) startWithoutLock() error {
 	s.scheduler = scheduler
 	log.Printf("已创建新的调度器实例")
 
-	// 添加使用数据定时任务
-	_, err = s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
+	// 添加使用数据定时任务（启用自适应获取间隔时按当前活跃度选择间隔）
+	interval := s.targetInterval()
+	usageJob, err := s.scheduler.NewJob(
+		intervalJob(time.Duration(interval)*time.Second),
 		gocron.NewTask(s.fetchAndSaveData),
+		gocron.WithName(models.JobNameUsage),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
 	if err != nil {
 		return fmt.Errorf("创建使用数据定时任务失败: %w", err)
 	}
+	s.usageJob = usageJob
+	s.fetchInterval = interval
 
 	// 添加积分余额定时任务，间隔错开30秒执行
 	balanceJob, err := s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
+		intervalJob(time.Duration(interval)*time.Second),
 		gocron.NewTask(s.fetchAndSaveBalance),
+		gocron.WithName(models.JobNameBalance),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 		gocron.WithStartAt(
 			gocron.WithStartDateTime(time.Now().Add(30*time.Second)),
@@ -518,23 +621,26 @@ func (s *SchedulerService) startWithoutLock() error {
 
 	// 保存积分余额任务引用
 	s.balanceJob = balanceJob
-	s.balanceTaskPaused = false
 
-	log.Printf("使用数据定时任务已创建，间隔: %d秒", s.config.Interval)
-	log.Printf("积分余额定时任务已创建，间隔: %d秒", s.config.Interval)
+	log.Printf("使用数据定时任务已创建，间隔: %d秒", interval)
+	log.Printf("积分余额定时任务已创建，间隔: %d秒", interval)
 
 	s.scheduler.Start()
 	s.isRunning = true
 
-	log.Printf("定时任务已启动，间隔: %d秒", s.config.Interval)
+	log.Printf("定时任务已启动，间隔: %d秒", interval)
 
 	// 重启时不立即执行，等待定时任务自然触发
 
 	return nil
 }
 
-// FetchDataManually 手动获取数据
-func (s *SchedulerService) FetchDataManually() error {
+// FetchDataManually 手动获取数据，ctx取消或超时时中止上游请求
+func (s *SchedulerService) FetchDataManually(ctx context.Context) error {
+	if s.IsInMaintenance() {
+		return ErrMaintenanceMode
+	}
+
 	// 更新配置
 	config, err := s.db.GetConfig()
 	if err == nil {
@@ -542,11 +648,15 @@ func (s *SchedulerService) FetchDataManually() error {
 		s.apiClient.UpdateCookie(config.Cookie)
 	}
 
-	return s.fetchAndSaveData()
+	return s.fetchAndSaveDataContext(ctx)
 }
 
-// FetchBalanceManually 手动获取积分余额
-func (s *SchedulerService) FetchBalanceManually() error {
+// FetchBalanceManually 手动获取积分余额，ctx取消或超时时中止上游请求
+func (s *SchedulerService) FetchBalanceManually(ctx context.Context) error {
+	if s.IsInMaintenance() {
+		return ErrMaintenanceMode
+	}
+
 	// 更新配置
 	config, err := s.db.GetConfig()
 	if err == nil {
@@ -554,11 +664,15 @@ func (s *SchedulerService) FetchBalanceManually() error {
 		s.apiClient.UpdateCookie(config.Cookie)
 	}
 
-	return s.fetchAndSaveBalance()
+	return s.fetchAndSaveBalanceContext(ctx)
 }
 
-// FetchAllDataManually 手动获取所有数据（使用数据 + 积分余额）
-func (s *SchedulerService) FetchAllDataManually() error {
+// FetchAllDataManually 手动获取所有数据（使用数据 + 积分余额），ctx取消或超时时中止两个上游请求
+func (s *SchedulerService) FetchAllDataManually(ctx context.Context) error {
+	if s.IsInMaintenance() {
+		return ErrMaintenanceMode
+	}
+
 	// 更新配置（只需要更新一次）
 	config, err := s.db.GetConfig()
 	if err != nil {
@@ -567,7 +681,7 @@ func (s *SchedulerService) FetchAllDataManually() error {
 
 	// 验证cookie是否已配置
 	if config.Cookie == "" {
-		return fmt.Errorf("Cookie未配置，请先设置Cookie")
+		return client.ErrCookieMissing
 	}
 
 	s.config = config
@@ -578,11 +692,11 @@ func (s *SchedulerService) FetchAllDataManually() error {
 	errChan := make(chan error, 2)
 
 	go func() {
-		errChan <- s.fetchAndSaveData()
+		errChan <- s.fetchAndSaveDataContext(ctx)
 	}()
 
 	go func() {
-		errChan <- s.fetchAndSaveBalance()
+		errChan <- s.fetchAndSaveBalanceContext(ctx)
 	}()
 
 	// 等待两个任务完成
@@ -601,8 +715,12 @@ func (s *SchedulerService) FetchAllDataManually() error {
 	return nil
 }
 
-// ResetCreditsManually 手动重置积分（供自动重置服务调用）
-func (s *SchedulerService) ResetCreditsManually() error {
+// ResetCreditsManually 手动重置积分（供自动重置服务调用），ctx取消或超时时中止上游请求
+func (s *SchedulerService) ResetCreditsManually(ctx context.Context) error {
+	if s.IsInMaintenance() {
+		return ErrMaintenanceMode
+	}
+
 	// 获取当前配置
 	config, err := s.db.GetConfig()
 	if err != nil {
@@ -613,12 +731,12 @@ func (s *SchedulerService) ResetCreditsManually() error {
 	// 检查Cookie是否配置
 	if config.Cookie == "" {
 		log.Printf("[手动重置] Cookie未配置")
-		return fmt.Errorf("Cookie未配置")
+		return client.ErrCookieMissing
 	}
 
 	// 调用积分重置API
 	apiClient := client.NewClaudeAPIClient(config.Cookie)
-	resetSuccess, resetInfo, err := apiClient.ResetCredits()
+	resetSuccess, resetInfo, err := apiClient.ResetCreditsContext(ctx)
 	if err != nil {
 		log.Printf("[手动重置] 调用重置积分API失败: %v", err)
 		return fmt.Errorf("调用重置积分API失败: %w", err)
@@ -630,7 +748,7 @@ func (s *SchedulerService) ResetCreditsManually() error {
 	}
 
 	// API调用成功后，标记今日已使用重置
-	config.DailyResetUsed = true
+	config.MarkDailyResetUsed(time.Now())
 
 	// 保存配置
 	if err := s.db.SaveConfig(config); err != nil {
@@ -640,14 +758,27 @@ func (s *SchedulerService) ResetCreditsManually() error {
 
 	log.Printf("[手动重置] 积分重置成功，已标记今日已使用重置。重置信息: %s", resetInfo)
 
+	// 记录当日重置次数（用于每日统计和订阅报告）
+	if err := s.db.IncrementDailyResets(models.GetLocalDate(time.Now())); err != nil {
+		log.Printf("[手动重置] 记录重置次数失败: %v", err)
+	}
+	// 记录重置时间（用于重置效果报告）
+	if err := s.db.SaveResetRecord(models.ResetRecord{Time: time.Now(), Info: resetInfo}); err != nil {
+		log.Printf("[手动重置] 保存重置记录失败: %v", err)
+	}
+
 	// 通知重置状态变化（SSE推送给前端）
 	s.NotifyResetStatusChange(true)
+	s.FireHook(models.HookEventResetExecuted, map[string]string{"info": resetInfo})
 
 	// 触发数据刷新，获取最新的积分余额
 	// 延迟10秒后查询，确保服务端处理完重置操作
+	// 调用方的请求可能已结束，使用独立的时限
 	go func() {
 		time.Sleep(10 * time.Second)
-		if err := s.FetchBalanceManually(); err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), ManualUpstreamTimeout)
+		defer cancel()
+		if err := s.FetchBalanceManually(ctx); err != nil {
 			log.Printf("[手动重置] 重置后刷新积分余额失败: %v", err)
 		}
 	}()
@@ -655,7 +786,37 @@ func (s *SchedulerService) ResetCreditsManually() error {
 	return nil
 }
 
-// resetDailyFlags 重置每日标记（每天0点执行）
+// SetDailyResetFlag 手动设置或清除当前重置周期的"已使用重置"标记
+// 用于在网站上直接重置后同步状态，或纠正错误的标记；不调用上游重置接口，也不计入每日重置次数
+func (s *SchedulerService) SetDailyResetFlag(used bool) error {
+	config, err := s.db.GetConfig()
+	if err != nil {
+		return fmt.Errorf("获取配置失败: %w", err)
+	}
+
+	if used {
+		config.MarkDailyResetUsed(time.Now())
+	} else {
+		config.DailyResetUsed = false
+		config.DailyResetUsedAt = time.Time{}
+	}
+
+	if err := s.db.SaveConfig(config); err != nil {
+		return fmt.Errorf("保存配置失败: %w", err)
+	}
+
+	action := "清除"
+	if used {
+		action = "设置"
+	}
+	log.Printf("[重置标记] 已手动%s今日重置标记", action)
+
+	// 通知重置状态变化（SSE推送给前端）
+	s.notifyResetStatusListeners(used)
+	return nil
+}
+
+// resetDailyFlags 重置每日标记（每天在配置的重置周期开始时执行，默认0点）
 func (s *SchedulerService) resetDailyFlags() error {
 	// 获取当前配置
 	config, err := s.db.GetConfig()
@@ -666,6 +827,7 @@ func (s *SchedulerService) resetDailyFlags() error {
 
 	// 简单重置每日标记为false
 	config.DailyResetUsed = false
+	config.DailyResetUsedAt = time.Time{}
 
 	// 保存配置
 	if err := s.db.SaveConfig(config); err != nil {
@@ -678,36 +840,85 @@ func (s *SchedulerService) resetDailyFlags() error {
 	// 通过SSE推送重置状态变化到前端
 	s.notifyResetStatusListeners(false)
 
+	// 新的重置周期开始，按需采用建议的重置时间
+	go s.adoptSuggestedResetTime()
+
 	return nil
 }
 
-// fetchAndSaveData 获取并保存数据
+// fetchAndSaveData 获取并保存数据（定时任务）
 func (s *SchedulerService) fetchAndSaveData() error {
-	data, err := s.apiClient.FetchUsageData()
+	return s.fetchAndSaveDataContext(context.Background())
+}
+
+// fetchAndSaveDataContext 获取并保存数据，ctx取消时中止上游请求且不视为上游故障
+func (s *SchedulerService) fetchAndSaveDataContext(ctx context.Context) (err error) {
+	defer func() { s.emitTick(models.JobNameUsage, err) }()
+
+	if s.IsInMaintenance() {
+		utils.Logf("[维护模式] 跳过使用数据获取")
+		return nil
+	}
+
+	data, err := s.apiClient.FetchUsageDataContext(ctx)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("获取数据已取消: %v", err)
+		return err
+	}
+	s.recordUpstreamResult(err)
 	if err != nil {
 		log.Printf("获取数据失败: %v", err)
 		// 通过SSE推送错误信息
-		s.notifyErrorListeners(fmt.Sprintf("获取使用数据失败: %s", err.Error()))
+		s.notifyErrorListeners(client.ErrorCode(err), client.ErrorMessage("获取使用数据", err))
 		return err
 	}
 
-	// 更新最新数据并通知监听器
-	s.mu.Lock()
-	s.lastData = data
-	s.mu.Unlock()
+	// 保存原始使用记录（按数据保留策略由数据清理服务定期清理）
+	if err := s.db.SaveUsageData(data); err != nil {
+		log.Printf("保存使用数据到数据库失败: %v", err)
+	}
 
-	s.notifyListeners(data)
+	// 累加今日积分使用量
+	s.todayUsage.Add(data)
+
+	// 检查中转站限流/故障比例
+	s.checkRelayErrorRate(data)
+
+	// 加入使用记录缓冲，有新增记录时才通知监听器（每次获取的记录大多与上次重叠）
+	if added := s.usage.Add(data); added > 0 {
+		s.notifyListeners(s.usage.Records())
+	}
+
+	// 按最新的使用活跃度调整获取间隔（任务执行期间不持有调度器锁，异步处理）
+	go s.adaptInterval()
 
 	return nil
 }
 
-// fetchAndSaveBalance 获取并保存积分余额
+// fetchAndSaveBalance 获取并保存积分余额（定时任务）
 func (s *SchedulerService) fetchAndSaveBalance() error {
-	balance, err := s.apiClient.FetchCreditBalance()
+	return s.fetchAndSaveBalanceContext(context.Background())
+}
+
+// fetchAndSaveBalanceContext 获取并保存积分余额，ctx取消时中止上游请求且不视为上游故障
+func (s *SchedulerService) fetchAndSaveBalanceContext(ctx context.Context) (err error) {
+	defer func() { s.emitTick(models.JobNameBalance, err) }()
+
+	if s.IsInMaintenance() {
+		utils.Logf("[维护模式] 跳过积分余额获取")
+		return nil
+	}
+
+	balance, err := s.apiClient.FetchCreditBalanceContext(ctx)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("获取积分余额已取消: %v", err)
+		return err
+	}
+	s.recordUpstreamResult(err)
 	if err != nil {
 		log.Printf("获取积分余额失败: %v", err)
 		// 通过SSE推送错误信息
-		s.notifyErrorListeners(fmt.Sprintf("获取积分余额失败: %s", err.Error()))
+		s.notifyErrorListeners(client.ErrorCode(err), client.ErrorMessage("获取积分余额", err))
 		return err
 	}
 
@@ -719,10 +930,14 @@ func (s *SchedulerService) fetchAndSaveBalance() error {
 
 	// 更新最新积分余额并通知监听器
 	s.mu.Lock()
+	previous := s.lastBalance
 	s.lastBalance = balance
 	s.mu.Unlock()
 
 	s.notifyBalanceListeners(balance)
+	s.checkBalanceLow(previous, balance)
+	s.checkBalanceExhausted(previous, balance)
+	s.checkExternalUsage(previous)
 
 	return nil
 }
@@ -730,7 +945,7 @@ func (s *SchedulerService) fetchAndSaveBalance() error {
 // NotifyConfigUpdateError 通知配置更新错误
 func (s *SchedulerService) NotifyConfigUpdateError(jobType, jobID, errorMsg string) {
 	message := fmt.Sprintf("配置更新失败 [%s:%s]: %s", jobType, jobID, errorMsg)
-	s.notifyErrorListeners(message)
+	s.notifyErrorListeners("", message)
 }
 
 // NotifyConfigUpdateSuccess 通知配置更新成功
@@ -741,11 +956,24 @@ func (s *SchedulerService) NotifyConfigUpdateSuccess(jobType, jobID string) {
 	s.NotifyConfigChange()
 }
 
-// GetLatestData 获取最新数据
+// GetLatestData 获取最新数据（最新在前）
 func (s *SchedulerService) GetLatestData() []models.UsageData {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.lastData
+	return s.usage.Records()
+}
+
+// GetLatestDataJSON 获取最近minutes分钟内使用数据的JSON编码（附带模型分组），没有数据时返回nil
+func (s *SchedulerService) GetLatestDataJSON(minutes int) []byte {
+	return s.usage.JSON(minutes)
+}
+
+// GetNewDataJSON 获取最近minutes分钟内cursor尚未收到的使用记录（已编码的JSON数组），用于SSE增量推送
+func (s *SchedulerService) GetNewDataJSON(minutes int, cursor *UsageCursor) []byte {
+	return s.usage.JSONSince(minutes, cursor)
+}
+
+// SetUsageBufferLimits 设置内存中使用记录的条数和时长上限（需在启动监控前调用）
+func (s *SchedulerService) SetUsageBufferLimits(maxRecords int, maxAge time.Duration) {
+	s.usage = NewUsageBuffer(maxRecords, maxAge)
 }
 
 // GetLatestBalance 获取最新积分余额
@@ -755,6 +983,36 @@ func (s *SchedulerService) GetLatestBalance() *models.CreditBalance {
 	return s.lastBalance
 }
 
+// BalanceChange 根据积分余额历史计算余额相对上一次读数和今日零点的变化量
+func (s *SchedulerService) BalanceChange(balance *models.CreditBalance) *models.CreditBalanceChange {
+	if balance == nil {
+		return nil
+	}
+	change := &models.CreditBalanceChange{
+		CreditBalance: *balance,
+		UsedToday:     s.GetTodayUsage().TotalCredits,
+	}
+
+	if previous, err := s.db.GetBalanceHistoryBefore(balance.UpdatedAt); err == nil && previous != nil {
+		delta := balance.Remaining - previous.Remaining
+		change.SincePrevious = &delta
+	}
+
+	// 零点时的余额：取零点及之前最近的读数，没有时取今日首次读数
+	updatedAt := balance.UpdatedAt.Local()
+	midnight := time.Date(updatedAt.Year(), updatedAt.Month(), updatedAt.Day(), 0, 0, 0, 0, updatedAt.Location())
+	baseline, err := s.db.GetBalanceHistoryBefore(midnight.Add(time.Second))
+	if err == nil && baseline == nil {
+		baseline, err = s.db.GetFirstBalanceHistorySince(midnight)
+	}
+	if err == nil && baseline != nil {
+		delta := balance.Remaining - baseline.Remaining
+		change.SinceMidnight = &delta
+	}
+
+	return change
+}
+
 // AddDataListener 添加数据监听器
 func (s *SchedulerService) AddDataListener() chan []models.UsageData {
 	s.mu.Lock()
@@ -775,12 +1033,34 @@ func (s *SchedulerService) AddBalanceListener() chan *models.CreditBalance {
 	return listener
 }
 
+// AddInternalBalanceListener 添加服务内部的积分余额监听器（如阈值检查），不计入运行状态中的监听器数，通过RemoveInternalBalanceListener移除
+func (s *SchedulerService) AddInternalBalanceListener() chan *models.CreditBalance {
+	listener := s.AddBalanceListener()
+	s.mu.Lock()
+	s.internalBalanceCount++
+	s.mu.Unlock()
+	return listener
+}
+
+// RemoveInternalBalanceListener 移除服务内部的积分余额监听器
+func (s *SchedulerService) RemoveInternalBalanceListener(listener chan *models.CreditBalance) {
+	s.mu.Lock()
+	for _, l := range s.balanceListeners {
+		if l == listener {
+			s.internalBalanceCount--
+			break
+		}
+	}
+	s.mu.Unlock()
+	s.RemoveBalanceListener(listener)
+}
+
 // AddErrorListener 添加错误监听器
-func (s *SchedulerService) AddErrorListener() chan string {
+func (s *SchedulerService) AddErrorListener() chan models.ErrorEvent {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	listener := make(chan string, 10)
+	listener := make(chan models.ErrorEvent, 10)
 	s.errorListeners = append(s.errorListeners, listener)
 	return listener
 }
@@ -824,7 +1104,7 @@ func (s *SchedulerService) RemoveBalanceListener(listener chan *models.CreditBal
 }
 
 // RemoveErrorListener 移除错误监听器
-func (s *SchedulerService) RemoveErrorListener(listener chan string) {
+func (s *SchedulerService) RemoveErrorListener(listener chan models.ErrorEvent) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -881,14 +1161,15 @@ func (s *SchedulerService) notifyBalanceListeners(balance *models.CreditBalance)
 	}
 }
 
-// notifyErrorListeners 通知所有错误监听器
-func (s *SchedulerService) notifyErrorListeners(errorMsg string) {
+// notifyErrorListeners 通知所有错误监听器，code为机器可读的错误码（无法归类时为空）
+func (s *SchedulerService) notifyErrorListeners(code, errorMsg string) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	event := models.ErrorEvent{Code: code, Message: errorMsg}
 	for _, listener := range s.errorListeners {
 		select {
-		case listener <- errorMsg:
+		case listener <- event:
 			// 错误信息发送成功
 		default:
 			// 通道已满，跳过通知
@@ -1038,193 +1319,7 @@ func (s *SchedulerService) NotifyAutoScheduleChange() {
 	}
 }
 
-// PauseBalanceTask 暂停积分余额获取任务
-func (s *SchedulerService) PauseBalanceTask() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// 检查是否已经暂停
-	if s.balanceTaskPaused {
-		utils.Logf("[任务协调] ⚠️  积分获取任务已暂停，无需重复操作")
-		return
-	}
-
-	// 检查任务是否存在
-	if s.balanceJob == nil {
-		utils.Logf("[任务协调] ⚠️  积分获取任务不存在，更新暂停状态")
-		s.balanceTaskPaused = true
-		return
-	}
-
-	// 检查调度器状态
-	if s.scheduler == nil || !s.isRunning {
-		utils.Logf("[任务协调] ⚠️  调度器未运行，直接更新暂停状态")
-		s.balanceTaskPaused = true
-		s.balanceJob = nil
-		return
-	}
-
-	utils.Logf("[任务协调] ⏸️  暂停积分余额获取任务 (ID: %v)", s.balanceJob.ID())
-	if err := s.scheduler.RemoveJob(s.balanceJob.ID()); err != nil {
-		utils.Logf("[任务协调] ❌ 暂停积分任务失败: %v", err)
-		// 即使失败也要清理本地状态
-		s.balanceJob = nil
-		s.balanceTaskPaused = true
-	} else {
-		s.balanceTaskPaused = true
-		s.balanceJob = nil
-		utils.Logf("[任务协调] ✅ 积分余额获取任务已暂停")
-	}
-}
-
-// RebuildBalanceTask 重建积分余额获取任务（移除+重建策略）
-func (s *SchedulerService) RebuildBalanceTask() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	utils.Logf("[任务协调] 🔄 重建积分余额获取任务")
-
-	// 第一步：移除现有任务
-	if s.balanceJob != nil {
-		utils.Logf("[任务协调] 🗑️  移除现有积分任务 (ID: %v)", s.balanceJob.ID())
-		if s.scheduler != nil {
-			if err := s.scheduler.RemoveJob(s.balanceJob.ID()); err != nil {
-				utils.Logf("[任务协调] ⚠️  移除积分任务失败: %v", err)
-			}
-		}
-		s.balanceJob = nil
-	}
-
-	// 第二步：检查调度器状态，如果异常则重建整个调度器
-	if s.scheduler == nil || !s.isRunning {
-		utils.Logf("[任务协调] 🔧 检测到调度器异常，尝试重建调度器")
-		if err := s.rebuildScheduler(); err != nil {
-			utils.Logf("[任务协调] ❌ 重建调度器失败: %v", err)
-			s.balanceTaskPaused = false
-			return
-		}
-	}
-
-	// 第三步：创建新的积分任务
-	utils.Logf("[任务协调] 🔨 创建新的积分余额获取任务")
-	balanceJob, err := s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
-		gocron.NewTask(s.fetchAndSaveBalance),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-		gocron.WithStartAt(gocron.WithStartDateTime(time.Now().Add(5*time.Second))), // 缩短延迟到5秒
-	)
-	if err != nil {
-		utils.Logf("[任务协调] ❌ 创建积分任务失败: %v", err)
-		s.balanceTaskPaused = false
-		return
-	}
-
-	s.balanceJob = balanceJob
-	s.balanceTaskPaused = false
-	utils.Logf("[任务协调] ✅ 积分余额获取任务已重建 (ID: %v)", balanceJob.ID())
-
-	// 第四步：立即执行一次获取，避免等待
-	go func() {
-		time.Sleep(1 * time.Second) // 短暂延迟确保任务已就绪
-		utils.Logf("[任务协调] 🚀 立即执行积分余额获取")
-		if err := s.fetchAndSaveBalance(); err != nil {
-			utils.Logf("[任务协调] ⚠️  立即执行积分获取失败: %v", err)
-		}
-	}()
-}
-
-// ResumeBalanceTask 恢复积分余额获取任务（优化版本）
-func (s *SchedulerService) ResumeBalanceTask() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// 检查是否已经在运行
-	if !s.balanceTaskPaused {
-		utils.Logf("[任务协调] ⚠️  积分获取任务未暂停，无需恢复")
-		return
-	}
-
-	utils.Logf("[任务协调] ▶️  恢复积分余额获取任务")
-
-	// 检查是否已经存在任务（防止重复创建）
-	if s.balanceJob != nil {
-		utils.Logf("[任务协调] ⚠️  积分获取任务已存在 (ID: %v)，更新状态", s.balanceJob.ID())
-		s.balanceTaskPaused = false
-		return
-	}
-
-	// 如果调度器不存在或未运行，使用重建策略
-	if s.scheduler == nil || !s.isRunning {
-		utils.Logf("[任务协调] 🔧 调度器状态异常，采用重建策略")
-		s.mu.Unlock() // 临时释放锁
-		s.RebuildBalanceTask()
-		s.mu.Lock() // 重新获取锁
-		return
-	}
-
-	// 重新创建积分余额任务
-	utils.Logf("[任务协调] 🔨 重新创建积分余额获取任务")
-	balanceJob, err := s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
-		gocron.NewTask(s.fetchAndSaveBalance),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-		gocron.WithStartAt(gocron.WithStartDateTime(time.Now().Add(5*time.Second))), // 缩短延迟到5秒
-	)
-	if err != nil {
-		utils.Logf("[任务协调] ❌ 恢复积分任务失败: %v", err)
-		s.balanceTaskPaused = false
-		return
-	}
-
-	s.balanceJob = balanceJob
-	s.balanceTaskPaused = false
-	utils.Logf("[任务协调] ✅ 积分余额获取任务已恢复 (ID: %v)", balanceJob.ID())
-
-	// 立即执行一次获取
-	go func() {
-		time.Sleep(1 * time.Second)
-		utils.Logf("[任务协调] 🚀 立即执行积分余额获取")
-		if err := s.fetchAndSaveBalance(); err != nil {
-			utils.Logf("[任务协调] ⚠️  立即执行积分获取失败: %v", err)
-		}
-	}()
-}
-
-// IsBalanceTaskRunning 检查积分余额获取任务是否正在运行
-func (s *SchedulerService) IsBalanceTaskRunning() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// 检查基本状态
-	if s.balanceTaskPaused || s.balanceJob == nil || !s.isRunning {
-		return false
-	}
-
-	// 检查调度器状态
-	if s.scheduler == nil {
-		return false
-	}
-
-	return true
-}
-
-// NotifyBalanceUpdate 通知积分余额更新（供阈值触发任务调用）
-func (s *SchedulerService) NotifyBalanceUpdate(balance *models.CreditBalance) {
-	// 保存到BadgerDB（持久化存储）
-	if err := s.db.SaveCreditBalance(balance); err != nil {
-		log.Printf("保存积分余额到数据库失败: %v", err)
-		// 注意：这里不返回错误，继续执行内存更新和通知
-	}
-
-	s.mu.Lock()
-	s.lastBalance = balance
-	s.mu.Unlock()
-
-	s.notifyBalanceListeners(balance)
-	utils.Logf("[任务协调] 📡 积分余额已更新并推送: %d", balance.Remaining)
-}
-
-// Shutdown 关闭服务
+// Shutdown 停止所有调度任务（监听器由CloseListeners单独关闭，以便在任务全部停止后再关闭）
 func (s *SchedulerService) Shutdown() {
 	s.Stop()
 
@@ -1244,75 +1339,47 @@ func (s *SchedulerService) Shutdown() {
 	if s.dailyUsageTracker != nil {
 		s.dailyUsageTracker.Shutdown()
 	}
+}
 
-	// 关闭所有监听器
+// CloseListeners 关闭所有监听器（在调度任务停止后调用，此后不会再有推送）
+func (s *SchedulerService) CloseListeners() {
 	s.mu.Lock()
-	for _, listener := range s.listeners {
-		close(listener)
-	}
-	for _, listener := range s.balanceListeners {
-		close(listener)
-	}
-	for _, listener := range s.errorListeners {
-		close(listener)
-	}
-	for _, listener := range s.resetStatusListeners {
-		close(listener)
-	}
-	for _, listener := range s.autoScheduleListeners {
-		close(listener)
-	}
-	for _, listener := range s.dailyUsageListeners {
-		close(listener)
-	}
+	defer s.mu.Unlock()
+
+	closeAll(s.listeners)
+	closeAll(s.balanceListeners)
+	closeAll(s.errorListeners)
+	closeAll(s.resetStatusListeners)
+	closeAll(s.autoScheduleListeners)
+	closeAll(s.dailyUsageListeners)
+	closeAll(s.notificationListeners)
+	closeAll(s.healthListeners)
+	closeAll(s.configListeners)
+	closeAll(s.tickListeners)
+	closeAll(s.annotationListeners)
+
 	s.listeners = nil
 	s.balanceListeners = nil
+	s.internalBalanceCount = 0
 	s.errorListeners = nil
 	s.resetStatusListeners = nil
 	s.autoScheduleListeners = nil
 	s.dailyUsageListeners = nil
-	s.mu.Unlock()
+	s.notificationListeners = nil
+	s.healthListeners = nil
+	s.configListeners = nil
+	s.tickListeners = nil
+	s.annotationListeners = nil
 }
 
-// rebuildScheduler 重建调度器（内部方法）
-func (s *SchedulerService) rebuildScheduler() error {
-	utils.Logf("[任务协调] 🔄 重建调度器")
-
-	// 停止并关闭现有调度器
-	if s.scheduler != nil {
-		s.scheduler.StopJobs()
-		if err := s.scheduler.Shutdown(); err != nil {
-			utils.Logf("[任务协调] ⚠️  关闭旧调度器失败: %v", err)
-		}
-	}
-
-	// 创建新调度器
-	newScheduler, err := gocron.NewScheduler()
-	if err != nil {
-		return fmt.Errorf("创建新调度器失败: %w", err)
-	}
-
-	s.scheduler = newScheduler
-
-	// 重新创建使用数据任务
-	usageJob, err := s.scheduler.NewJob(
-		gocron.DurationJob(time.Duration(s.config.Interval)*time.Second),
-		gocron.NewTask(s.fetchAndSaveData),
-		gocron.WithSingletonMode(gocron.LimitModeReschedule),
-	)
-	if err != nil {
-		return fmt.Errorf("创建使用数据任务失败: %w", err)
+// closeAll 关闭一组监听器通道
+func closeAll[T any](listeners []chan T) {
+	for _, listener := range listeners {
+		close(listener)
 	}
-
-	// 启动调度器
-	s.scheduler.Start()
-	s.isRunning = true
-
-	utils.Logf("[任务协调] ✅ 调度器重建完成，使用数据任务ID: %v", usageJob.ID())
-	return nil
 }
 
-// SetAutoResetService 设置自动重置服务引用（用于任务协调）
+// SetAutoResetService 设置自动重置服务引用（用于应用重置建议和运行状态快照）
 func (s *SchedulerService) SetAutoResetService(autoResetService *AutoResetService) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1382,6 +1449,70 @@ func (s *SchedulerService) GetWeeklyUsage() (models.DailyUsageList, error) {
 	return tracker.GetWeeklyUsage()
 }
 
+// GetWeeklyUsageFilled 获取最近一周的积分使用统计并填充缺失日期（今日使用实时累计），获取失败时返回7天0数据
+func (s *SchedulerService) GetWeeklyUsageFilled() []models.DailyUsage {
+	weeklyUsage, err := s.GetWeeklyUsage()
+	if err != nil || len(weeklyUsage) == 0 {
+		// 生成7天的0数据
+		weekDates := models.GetWeekDates()
+		weeklyUsage = make(models.DailyUsageList, len(weekDates))
+		for i, date := range weekDates {
+			weeklyUsage[i] = models.DailyUsage{
+				Date:         date,
+				TotalCredits: 0,
+			}
+		}
+	} else {
+		// 填充缺失日期
+		weeklyUsage = weeklyUsage.FillMissingDates()
+	}
+
+	// 今日数据使用实时累计
+	return weeklyUsage.WithToday(s.GetTodayUsage())
+}
+
+// GetTodayUsage 获取今日积分使用量：实时累计大于每小时统计结果时使用实时累计（按小时分布和重置次数仍取自每小时统计）
+func (s *SchedulerService) GetTodayUsage() models.DailyUsage {
+	live := s.todayUsage.Get()
+	stored, err := s.db.GetDailyUsage(live.Date)
+	if err != nil || stored == nil {
+		return live
+	}
+	if stored.TotalCredits >= live.TotalCredits {
+		return *stored
+	}
+	live.HourlyModelCredits = stored.HourlyModelCredits
+	live.Resets = stored.Resets
+	return live
+}
+
+// GetRecentDailyUsage 获取最近指定天数的积分使用统计（直接读取数据库，不依赖统计服务是否启用）
+func (s *SchedulerService) GetRecentDailyUsage(days int) (models.DailyUsageList, error) {
+	usageList, err := s.db.GetRecentDailyUsage(days)
+	if err != nil {
+		return nil, err
+	}
+	return usageList.NormalizeModels().WithGroups(), nil
+}
+
+// GetSameWeekdayComparison 获取今日与之前weeks周同一星期几的按小时积分对比
+func (s *SchedulerService) GetSameWeekdayComparison(weeks int) (models.SameDayComparison, error) {
+	now := time.Now()
+	dates := models.SameWeekdayDates(now, weeks)
+	previous := make([]models.DailyUsage, len(dates))
+	for i, date := range dates {
+		usage, err := s.db.GetDailyUsage(date)
+		if err != nil {
+			return models.SameDayComparison{}, err
+		}
+		previous[i] = models.DailyUsage{Date: date}
+		if usage != nil {
+			previous[i] = *usage
+		}
+	}
+	return models.NewSameDayComparison(s.GetTodayUsage(), s.usage.Records(), previous, now), nil
+}
+
 // GetConfig 获取当前配置
 func (s *SchedulerService) GetConfig() *models.UserConfig {
 	s.mu.RLock()
@@ -1427,3 +1558,394 @@ func (s *SchedulerService) BroadcastDailyUsage(data []models.DailyUsage) {
 		}
 	}
 }
+
+// AddNotificationListener 添加通知消息监听器
+func (s *SchedulerService) AddNotificationListener() chan models.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener := make(chan models.Notification, notificationBufferSize)
+	s.notificationListeners = append(s.notificationListeners, listener)
+
+	// 补发暂存的通知（暂存数量不超过通道容量）
+	for _, pending := range s.pendingNotifications {
+		listener <- pending.notification
+		if pending.delivered != nil {
+			go pending.delivered()
+		}
+	}
+	s.pendingNotifications = nil
+	return listener
+}
+
+// RemoveNotificationListener 移除通知消息监听器
+func (s *SchedulerService) RemoveNotificationListener(listener chan models.Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.notificationListeners {
+		if l == listener {
+			close(l)
+			s.notificationListeners = append(s.notificationListeners[:i], s.notificationListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// NotificationSink 外部通知渠道（如通知插件），推送给前端的通知同时转发
+type NotificationSink interface {
+	Name() string
+	Notify(notification models.Notification) error
+}
+
+// AddNotificationSink 添加外部通知渠道
+func (s *SchedulerService) AddNotificationSink(sink NotificationSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notificationSinks = append(s.notificationSinks, sink)
+}
+
+// notificationBufferSize 通知监听器通道容量，也是暂存待补发通知的上限
+const notificationBufferSize = 10
+
+// pendingNotification 暂存的待补发通知
+type pendingNotification struct {
+	notification models.Notification
+	delivered    func() // 通知送达某个连接后调用（可为nil）
+}
+
+// BroadcastNotification 广播通知消息（没有SSE连接时直接丢弃）
+func (s *SchedulerService) BroadcastNotification(notification models.Notification) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.sendNotification(notification)
+	s.forwardNotification(notification)
+}
+
+// DeliverNotification 推送需要确保送达的一次性通知（如新版本、上游结构变化）
+// 送达至少一个SSE连接后调用delivered；当前没有连接时暂存，在下一个连接建立时补发，同类型的暂存通知只保留最新一条
+func (s *SchedulerService) DeliverNotification(notification models.Notification, delivered func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 相同的通知已在暂存中等待补发，外部渠道也已转发过
+	for _, pending := range s.pendingNotifications {
+		if pending.notification.Type == notification.Type && pending.notification.Message == notification.Message {
+			return
+		}
+	}
+
+	s.forwardNotification(notification)
+	if s.sendNotification(notification) {
+		if delivered != nil {
+			go delivered()
+		}
+		return
+	}
+
+	for i, pending := range s.pendingNotifications {
+		if pending.notification.Type == notification.Type {
+			s.pendingNotifications = append(s.pendingNotifications[:i], s.pendingNotifications[i+1:]...)
+			break
+		}
+	}
+	if len(s.pendingNotifications) >= notificationBufferSize {
+		s.pendingNotifications = s.pendingNotifications[1:]
+	}
+	s.pendingNotifications = append(s.pendingNotifications, pendingNotification{notification: notification, delivered: delivered})
+}
+
+// sendNotification 向所有通知监听器发送通知，返回是否至少送达一个（调用方需持有锁）
+func (s *SchedulerService) sendNotification(notification models.Notification) bool {
+	sent := false
+	for _, listener := range s.notificationListeners {
+		select {
+		case listener <- notification:
+			// 通知发送成功
+			sent = true
+		default:
+			// 通道已满，跳过通知
+		}
+	}
+	return sent
+}
+
+// forwardNotification 异步转发到外部通知渠道，避免阻塞调用方（调用方需持有锁）
+func (s *SchedulerService) forwardNotification(notification models.Notification) {
+	for _, sink := range s.notificationSinks {
+		go func(sink NotificationSink) {
+			if err := sink.Notify(notification); err != nil {
+				utils.Logf("[通知渠道] ❌ %s 转发通知失败: %v", sink.Name(), err)
+			}
+		}(sink)
+	}
+}
+
+// ManualUpstreamTimeout 手动刷新、重置等由接口调用触发的上游请求的总时限（含重试和频率限制的等待）
+const ManualUpstreamTimeout = 60 * time.Second
+
+// NotifyUpstreamSlow 上游接口响应超过阈值时推送告警通知（作为client.SlowResponseHandler使用）
+func (s *SchedulerService) NotifyUpstreamSlow(endpoint string, duration, threshold time.Duration) {
+	utils.Logf("[上游耗时] 🐢 %s 响应耗时 %v，超过阈值 %v", endpoint, duration.Round(time.Millisecond), threshold)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeUpstreamSlow,
+		Title:     "上游响应缓慢",
+		Message:   fmt.Sprintf("上游接口 %s 响应耗时 %.1f 秒，超过 %.1f 秒阈值", endpoint, duration.Seconds(), threshold.Seconds()),
+		Timestamp: time.Now(),
+	})
+}
+
+// NotifySchemaDrift 上游响应结构变化时推送一次性通知（作为client.SchemaDriftHandler使用）
+func (s *SchedulerService) NotifySchemaDrift(drift models.UpstreamSchemaDrift) {
+	var changes []string
+	if len(drift.Missing) > 0 {
+		changes = append(changes, "缺少字段 "+strings.Join(drift.Missing, ", "))
+	}
+	if len(drift.Added) > 0 {
+		changes = append(changes, "新增字段 "+strings.Join(drift.Added, ", "))
+	}
+	if len(drift.Mistyped) > 0 {
+		changes = append(changes, "字段类型变化 "+strings.Join(drift.Mistyped, ", "))
+	}
+	if drift.Note != "" {
+		changes = append(changes, drift.Note)
+	}
+
+	// 同一变化只报告一次，没有SSE连接时暂存到下一个连接建立时补发
+	s.DeliverNotification(models.Notification{
+		Type:      models.NotificationTypeSchemaDrift,
+		Title:     "上游响应结构变化",
+		Message:   fmt.Sprintf("上游接口 %s 的响应结构与预期不一致（%s），已按现有字段尽量解析，数据可能不完整", drift.Endpoint, strings.Join(changes, "；")),
+		Timestamp: drift.DetectedAt,
+	}, nil)
+}
+
+// 中转站错误率告警参数
+const (
+	DefaultRelayErrorRate   = 20        // 默认告警阈值（百分比）
+	relayErrorMinRecords    = 10        // 最近1小时的记录数少于此值时不告警，避免样本过少误报
+	relayErrorAlertCooldown = time.Hour // 告警间隔
+)
+
+// SetRelayErrorRate 设置中转站错误率告警阈值（百分比，0表示不告警）
+func (s *SchedulerService) SetRelayErrorRate(percent int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.relayErrorRate = percent
+}
+
+// checkRelayErrorRate 最近1小时使用记录中429/5xx的比例超过阈值时推送告警（每小时最多一次）
+func (s *SchedulerService) checkRelayErrorRate(data []models.UsageData) {
+	now := time.Now()
+	stats := models.UsageDataList(data).RelayErrors(1, now)
+
+	s.mu.Lock()
+	threshold := s.relayErrorRate
+	if threshold <= 0 || stats.Total < relayErrorMinRecords ||
+		stats.ErrorRate*100 < float64(threshold) || now.Sub(s.relayErrorAlertAt) < relayErrorAlertCooldown {
+		s.mu.Unlock()
+		return
+	}
+	s.relayErrorAlertAt = now
+	s.mu.Unlock()
+
+	utils.Logf("[中转站] 🚦 最近1小时 %d 条记录中 429: %d、5xx: %d，比例 %.0f%% 超过阈值 %d%%",
+		stats.Total, stats.Throttled, stats.ServerErrors, stats.ErrorRate*100, threshold)
+
+	s.BroadcastNotification(models.Notification{
+		Type:      models.NotificationTypeRelayErrors,
+		Title:     "中转站错误率过高",
+		Message:   fmt.Sprintf("最近1小时 %d 条使用记录中有 %d 条被限流(429)、%d 条服务端错误(5xx)，比例 %.0f%%，超过 %d%% 阈值", stats.Total, stats.Throttled, stats.ServerErrors, stats.ErrorRate*100, threshold),
+		Timestamp: now,
+	})
+}
+
+// SetHealthSupervisor 设置健康监督服务引用
+func (s *SchedulerService) SetHealthSupervisor(supervisor *HealthSupervisor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthSupervisor = supervisor
+}
+
+// recordUpstreamResult 向健康监督服务报告上游请求结果
+func (s *SchedulerService) recordUpstreamResult(err error) {
+	s.mu.RLock()
+	supervisor := s.healthSupervisor
+	s.mu.RUnlock()
+
+	if supervisor != nil {
+		supervisor.RecordUpstreamResult(err)
+	}
+}
+
+// AddHealthListener 添加健康状态监听器
+func (s *SchedulerService) AddHealthListener() chan models.HealthState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener := make(chan models.HealthState, 10)
+	s.healthListeners = append(s.healthListeners, listener)
+	return listener
+}
+
+// RemoveHealthListener 移除健康状态监听器
+func (s *SchedulerService) RemoveHealthListener(listener chan models.HealthState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.healthListeners {
+		if l == listener {
+			close(l)
+			s.healthListeners = append(s.healthListeners[:i], s.healthListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// BroadcastHealthState 记录并广播健康状态
+func (s *SchedulerService) BroadcastHealthState(state models.HealthState) {
+	s.mu.Lock()
+	s.latestHealth = &state
+	s.mu.Unlock()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, listener := range s.healthListeners {
+		select {
+		case listener <- state:
+			// 状态发送成功
+		default:
+			// 通道已满，跳过通知
+		}
+	}
+}
+
+// AddConfigListener 添加配置变更监听器
+func (s *SchedulerService) AddConfigListener() chan *models.UserConfigResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener := make(chan *models.UserConfigResponse, 10)
+	s.configListeners = append(s.configListeners, listener)
+	return listener
+}
+
+// RemoveConfigListener 移除配置变更监听器
+func (s *SchedulerService) RemoveConfigListener(listener chan *models.UserConfigResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.configListeners {
+		if l == listener {
+			close(l)
+			s.configListeners = append(s.configListeners[:i], s.configListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// broadcastConfigChange 读取最新配置并推送（脱敏后的响应格式）
+// 串行执行并从数据库读取最新值，连续保存时最终推送的总是最新配置；仅Cookie验证时间变化时不推送
+func (s *SchedulerService) broadcastConfigChange() {
+	s.configPushMu.Lock()
+	defer s.configPushMu.Unlock()
+
+	config, err := s.db.GetConfig()
+	if err != nil {
+		return
+	}
+	response := config.ToResponse()
+
+	compared := *response
+	compared.LastCookieValidTime = time.Time{}
+	payload, err := json.Marshal(compared)
+	if err != nil || bytes.Equal(payload, s.lastConfigPayload) {
+		return
+	}
+	s.lastConfigPayload = payload
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, listener := range s.configListeners {
+		select {
+		case listener <- response:
+			// 配置发送成功
+		default:
+			// 通道已满，跳过通知
+		}
+	}
+}
+
+// AddTickListener 添加定时任务执行事件监听器
+func (s *SchedulerService) AddTickListener() chan models.JobTick {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener := make(chan models.JobTick, 10)
+	s.tickListeners = append(s.tickListeners, listener)
+	return listener
+}
+
+// RemoveTickListener 移除定时任务执行事件监听器
+func (s *SchedulerService) RemoveTickListener(listener chan models.JobTick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.tickListeners {
+		if l == listener {
+			close(l)
+			s.tickListeners = append(s.tickListeners[:i], s.tickListeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// emitTick 广播任务执行事件，附带该任务的下次计划执行时间
+func (s *SchedulerService) emitTick(job string, err error) {
+	tick := models.JobTick{
+		Job:   job,
+		RanAt: time.Now(),
+	}
+	if err != nil {
+		tick.Error = err.Error()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.config != nil {
+		tick.Interval = s.currentInterval()
+	}
+	// 监控已停止时没有下次执行时间
+	if s.isRunning && s.scheduler != nil {
+		for _, j := range s.scheduler.Jobs() {
+			if j.Name() != job {
+				continue
+			}
+			if next, err := j.NextRun(); err == nil && !next.IsZero() {
+				tick.NextRun = &next
+			}
+			break
+		}
+	}
+
+	for _, listener := range s.tickListeners {
+		select {
+		case listener <- tick:
+			// 事件发送成功
+		default:
+			// 通道已满，跳过通知
+		}
+	}
+}
+
+// GetLatestHealthState 获取最新健康状态
+func (s *SchedulerService) GetLatestHealthState() *models.HealthState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latestHealth
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/sqlitefile"
+)
+
+// sqliteTimeFormat 导出文件中的时间格式（服务器本地时间，可直接用于SQLite的日期函数和按日期分组）
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// SQLiteExportResult SQLite导出的各表行数
+type SQLiteExportResult struct {
+	Usage      int
+	Aggregates int
+	Daily      int
+	Balance    int
+}
+
+// ExportSQLite 将数据库当前快照写入SQLite文件，供在本地用SQL任意查询：
+// usage（原始使用记录）、usage_aggregate（降采样数据）、daily（每日积分统计）、balance（积分余额历史），
+// 按模型分组的积分以JSON文本保存，可用json_each展开
+func ExportSQLite(db *database.BadgerDB, w io.WriterAt) (*SQLiteExportResult, error) {
+	file := sqlitefile.NewWriter(w)
+	usage, err := file.CreateTable("usage", "id INTEGER", "created_at TEXT", "credits INTEGER", "model TEXT", "status_code INTEGER")
+	if err != nil {
+		return nil, err
+	}
+	aggregates, err := file.CreateTable("usage_aggregate", "start TEXT", "credits INTEGER", "count INTEGER", "model_credits TEXT")
+	if err != nil {
+		return nil, err
+	}
+	daily, err := file.CreateTable("daily", "date TEXT", "total_credits INTEGER", "resets INTEGER", "model_credits TEXT", "hourly_model_credits TEXT")
+	if err != nil {
+		return nil, err
+	}
+	balance, err := file.CreateTable("balance", "updated_at TEXT", "remaining INTEGER", "plan TEXT")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SQLiteExportResult{}
+	err = db.ExportSnapshot(func(r *database.ExportReader) error {
+		err := r.Usage(func(data models.UsageData) error {
+			result.Usage++
+			return usage.Insert(data.ID, data.CreatedAt.Local().Format(sqliteTimeFormat), data.CreditsUsed, data.Model, data.StatusCode)
+		})
+		if err != nil {
+			return err
+		}
+		err = r.UsageAggregates(func(aggregate models.UsageAggregate) error {
+			result.Aggregates++
+			return aggregates.Insert(aggregate.Start.Local().Format(sqliteTimeFormat), aggregate.Credits, aggregate.Count, jsonText(aggregate.ModelCredits))
+		})
+		if err != nil {
+			return err
+		}
+		err = r.DailyUsage(func(usage models.DailyUsage) error {
+			result.Daily++
+			return daily.Insert(usage.Date, usage.TotalCredits, usage.Resets, jsonText(usage.ModelCredits), jsonText(usage.HourlyModelCredits))
+		})
+		if err != nil {
+			return err
+		}
+		return r.BalanceHistory(func(credit models.CreditBalance) error {
+			result.Balance++
+			return balance.Insert(credit.UpdatedAt.Local().Format(sqliteTimeFormat), credit.Remaining, credit.Plan)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// jsonText 将按模型分组的积分编码为JSON文本，为空时返回nil（SQL中为NULL）
+func jsonText[T any](m map[string]T) any {
+	if len(m) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// TodayUsageCounter 今日积分使用量的实时累计
+// 每次获取使用数据后按原始记录累加（按记录ID去重），无需等待每小时统计任务；跨日后从数据库重新初始化
+type TodayUsageCounter struct {
+	db *database.BadgerDB
+
+	mu           sync.Mutex
+	date         string                      // 累计对应的本地日期
+	credits      int                         // 今日累计积分
+	modelCredits map[string]int              // 按模型累计的积分
+	seen         map[int]bool                // 已计入的记录ID
+	aggregated   func(models.UsageData) bool // 判断记录是否已被降采样聚合（聚合部分已计入初始值）
+}
+
+// NewTodayUsageCounter 创建今日积分使用量累计器
+func NewTodayUsageCounter(db *database.BadgerDB) *TodayUsageCounter {
+	return &TodayUsageCounter{db: db}
+}
+
+// Add 累加新获取的使用记录，已计入的记录和非今日的记录会被忽略
+func (c *TodayUsageCounter) Add(data []models.UsageData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ensureToday()
+	for _, usage := range data {
+		c.count(usage)
+	}
+}
+
+// Get 获取今日累计的积分使用量
+func (c *TodayUsageCounter) Get() models.DailyUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ensureToday()
+	modelCredits := make(map[string]int, len(c.modelCredits))
+	for model, credits := range c.modelCredits {
+		modelCredits[model] = credits
+	}
+	return models.DailyUsage{
+		Date:         c.date,
+		TotalCredits: c.credits,
+		ModelCredits: modelCredits,
+	}
+}
+
+// count 计入一条使用记录（调用方持有锁）
+func (c *TodayUsageCounter) count(usage models.UsageData) {
+	if models.GetLocalDate(usage.CreatedAt) != c.date || c.seen[usage.ID] {
+		return
+	}
+	if c.aggregated != nil && c.aggregated(usage) {
+		return
+	}
+	c.seen[usage.ID] = true
+	c.credits += usage.CreditsUsed
+	if usage.Model != "" && usage.CreditsUsed > 0 {
+		c.modelCredits[usage.Model] += usage.CreditsUsed
+	}
+}
+
+// ensureToday 跨日（或首次使用）时根据数据库中今日的降采样数据和原始记录重新初始化（调用方持有锁）
+func (c *TodayUsageCounter) ensureToday() {
+	now := time.Now()
+	today := models.GetLocalDate(now)
+	if c.date == today {
+		return
+	}
+
+	c.date = today
+	c.credits = 0
+	c.modelCredits = make(map[string]int)
+	c.seen = make(map[int]bool)
+	c.aggregated = nil
+
+	aggregated, err := c.db.GetUsageAggregatedFunc()
+	if err != nil {
+		utils.Logf("[今日积分] ⚠️  读取降采样水位失败: %v", err)
+	} else {
+		c.aggregated = aggregated
+	}
+
+	// 已降采样的部分（原始记录已删除）
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	aggregates, err := c.db.GetUsageAggregates(midnight)
+	if err != nil {
+		utils.Logf("[今日积分] ⚠️  读取今日降采样数据失败: %v", err)
+	}
+	for _, aggregate := range aggregates {
+		if aggregate.Start.Before(midnight) {
+			continue
+		}
+		c.credits += aggregate.Credits
+		for model, credits := range aggregate.ModelCredits {
+			c.modelCredits[model] += credits
+		}
+	}
+
+	// 仍保留的原始记录
+	usageList, err := c.db.GetUsageData(int(now.Sub(midnight).Minutes()) + 1)
+	if err != nil {
+		utils.Logf("[今日积分] ⚠️  读取今日使用记录失败: %v", err)
+	}
+	for _, usage := range usageList {
+		c.count(usage)
+	}
+
+	utils.Logf("[今日积分] 📅 %s 初始累计: %d 积分", today, c.credits)
+}
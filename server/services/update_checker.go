@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+const (
+	// releasesAPI GitHub最新发布版本查询地址
+	releasesAPI = "https://api.github.com/repos/leafney/cccmu/releases/latest"
+	// updateCheckInterval 新版本检查间隔
+	updateCheckInterval = 24 * time.Hour
+)
+
+// githubRelease GitHub发布版本信息
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// UpdateCheckerService 新版本检查服务
+// 每天查询一次GitHub发布版本，发现新版本时通过SSE推送一次通知
+type UpdateCheckerService struct {
+	scheduler       gocron.Scheduler
+	db              *database.BadgerDB
+	schedulerSvc    *SchedulerService // 用于广播通知
+	client          *resty.Client
+	currentVersion  string
+	latestVersion   string
+	releaseURL      string
+	updateAvailable bool
+	mu              sync.RWMutex
+}
+
+// NewUpdateCheckerService 创建新版本检查服务
+func NewUpdateCheckerService(currentVersion string, db *database.BadgerDB, schedulerSvc *SchedulerService) (*UpdateCheckerService, error) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建版本检查调度器失败: %w", err)
+	}
+
+	// 调度器始终运行，非正式版本时不添加任务
+	scheduler.Start()
+
+	return &UpdateCheckerService{
+		scheduler:      scheduler,
+		db:             db,
+		schedulerSvc:   schedulerSvc,
+		client:         resty.New().SetTimeout(15 * time.Second),
+		currentVersion: currentVersion,
+	}, nil
+}
+
+// Start 启动每日版本检查任务（启动时立即检查一次）
+func (u *UpdateCheckerService) Start() error {
+	if _, ok := parseVersion(u.currentVersion); !ok {
+		utils.Logf("[版本检查] 当前版本 %s 非正式发布版本，跳过新版本检查", u.currentVersion)
+		return nil
+	}
+
+	_, err := u.scheduler.NewJob(
+		gocron.DurationJob(updateCheckInterval),
+		gocron.NewTask(u.check),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("创建版本检查任务失败: %w", err)
+	}
+
+	utils.Logf("[版本检查] ✅ 版本检查任务已启动，间隔: %v", updateCheckInterval)
+	return nil
+}
+
+// Stop 停止版本检查服务
+func (u *UpdateCheckerService) Stop() error {
+	if err := u.scheduler.Shutdown(); err != nil {
+		return fmt.Errorf("关闭版本检查调度器失败: %w", err)
+	}
+	return nil
+}
+
+// GetStatus 获取最新版本检查结果
+func (u *UpdateCheckerService) GetStatus() (available bool, latestVersion, releaseURL string) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.updateAvailable, u.latestVersion, u.releaseURL
+}
+
+// check 查询最新发布版本并与当前版本比较
+func (u *UpdateCheckerService) check() {
+	resp, err := u.client.R().
+		SetHeader("Accept", "application/vnd.github+json").
+		SetHeader("User-Agent", "cccmu/"+u.currentVersion).
+		Get(releasesAPI)
+	if err != nil {
+		utils.Logf("[版本检查] ❌ 查询最新版本失败: %v", err)
+		return
+	}
+	if resp.StatusCode() != 200 {
+		utils.Logf("[版本检查] ❌ 查询最新版本失败: HTTP %d", resp.StatusCode())
+		return
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(resp.Body(), &release); err != nil {
+		utils.Logf("[版本检查] ❌ 解析发布信息失败: %v", err)
+		return
+	}
+
+	available := compareVersions(release.TagName, u.currentVersion) > 0
+
+	u.mu.Lock()
+	u.latestVersion = release.TagName
+	u.releaseURL = release.HTMLURL
+	u.updateAvailable = available
+	u.mu.Unlock()
+
+	if !available {
+		utils.Logf("[版本检查] 当前已是最新版本: %s", u.currentVersion)
+		return
+	}
+
+	utils.Logf("[版本检查] 🆕 发现新版本: %s（当前: %s）", release.TagName, u.currentVersion)
+
+	// 每个新版本只通知一次
+	notified, err := u.db.GetNotifiedVersion()
+	if err != nil {
+		utils.Logf("[版本检查] ⚠️  读取通知记录失败: %v", err)
+	}
+	if notified == release.TagName {
+		return
+	}
+
+	// 启动时的首次检查通常早于任何SSE连接，通知暂存到有连接时补发，送达后才记录为已通知
+	u.schedulerSvc.DeliverNotification(models.Notification{
+		Type:      models.NotificationTypeUpdate,
+		Title:     "发现新版本",
+		Message:   fmt.Sprintf("新版本 %s 已发布，当前版本 %s", release.TagName, u.currentVersion),
+		URL:       release.HTMLURL,
+		Timestamp: time.Now(),
+	}, func() {
+		if err := u.db.SaveNotifiedVersion(release.TagName); err != nil {
+			utils.Logf("[版本检查] ⚠️  保存通知记录失败: %v", err)
+		}
+	})
+}
+
+// parseVersion 解析形如 v1.2.3 的版本号，忽略预发布后缀
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+"); idx >= 0 {
+		version = version[:idx]
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// compareVersions 比较两个版本号，a>b返回1，a<b返回-1，相等或无法解析返回0
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0
+	}
+
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] > vb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/client"
+	"github.com/leafney/cccmu/server/database"
+	"github.com/leafney/cccmu/server/models"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// 上游可用性探测参数
+const (
+	DefaultUpstreamProbeInterval = 5 * time.Minute  // 默认探测间隔
+	upstreamProbeTimeout         = 10 * time.Second // 单次探测的超时时间
+)
+
+// UpstreamProbeService 上游可用性探测服务
+// 按固定间隔请求上游首页（不携带Cookie，不受监控开关和维护模式影响），记录可用性历史，用于事后查看上游不可用的时段
+type UpstreamProbeService struct {
+	scheduler gocron.Scheduler      // 探测专用调度器
+	db        *database.BadgerDB    // 数据库访问
+	interval  time.Duration         // 探测间隔
+	lastProbe *models.UpstreamProbe // 最近一次探测结果
+	mu        sync.RWMutex
+}
+
+// NewUpstreamProbeService 创建上游可用性探测服务
+func NewUpstreamProbeService(db *database.BadgerDB, interval time.Duration) (*UpstreamProbeService, error) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		return nil, fmt.Errorf("创建上游探测调度器失败: %w", err)
+	}
+
+	return &UpstreamProbeService{
+		scheduler: scheduler,
+		db:        db,
+		interval:  interval,
+	}, nil
+}
+
+// Start 启动探测任务（立即执行一次）
+func (p *UpstreamProbeService) Start() error {
+	_, err := p.scheduler.NewJob(
+		intervalJob(p.interval),
+		gocron.NewTask(p.probe),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		return fmt.Errorf("创建上游探测任务失败: %w", err)
+	}
+
+	p.scheduler.Start()
+	utils.Logf("[上游探测] ✅ 探测任务已启动，间隔: %v", p.interval)
+	return nil
+}
+
+// Stop 停止探测任务
+func (p *UpstreamProbeService) Stop() error {
+	if err := p.scheduler.Shutdown(); err != nil {
+		return fmt.Errorf("关闭上游探测调度器失败: %w", err)
+	}
+	return nil
+}
+
+// probe 执行一次探测并保存结果，上游可用状态变化时记录日志
+func (p *UpstreamProbeService) probe() {
+	now := time.Now()
+	statusCode, latency, err := client.Probe(upstreamProbeTimeout)
+	probe := models.UpstreamProbe{
+		Time:       now,
+		StatusCode: statusCode,
+		LatencyMs:  latency.Milliseconds(),
+	}
+	switch {
+	case err != nil:
+		probe.Error = err.Error()
+	case statusCode >= http.StatusInternalServerError:
+		probe.Error = fmt.Sprintf("HTTP %d", statusCode)
+	default:
+		probe.Up = true
+	}
+
+	if err := p.db.SaveUpstreamProbe(probe); err != nil {
+		utils.Logf("[上游探测] ⚠️  保存探测记录失败: %v", err)
+	}
+
+	p.mu.Lock()
+	previous := p.lastProbe
+	p.lastProbe = &probe
+	p.mu.Unlock()
+
+	switch {
+	case !probe.Up && (previous == nil || previous.Up):
+		utils.Logf("[上游探测] ❌ 上游不可用: %s", probe.Error)
+	case probe.Up && previous != nil && !previous.Up:
+		utils.Logf("[上游探测] ✅ 上游已恢复，HTTP %d，%dms", probe.StatusCode, probe.LatencyMs)
+	}
+}
+
+// GetAvailability 统计最近hours小时的上游可用率和不可用时段
+func (p *UpstreamProbeService) GetAvailability(hours int) (models.UpstreamAvailability, error) {
+	now := time.Now()
+	probes, err := p.db.GetUpstreamProbesSince(now.Add(-time.Duration(hours) * time.Hour))
+	if err != nil {
+		return models.UpstreamAvailability{}, fmt.Errorf("获取上游探测记录失败: %w", err)
+	}
+	return models.NewUpstreamAvailability(hours, probes, now), nil
+}
+
+// GetRuntimeState 获取探测状态：最近一次探测结果和最近24小时的可用率
+func (p *UpstreamProbeService) GetRuntimeState() models.UpstreamProbeState {
+	p.mu.RLock()
+	state := models.UpstreamProbeState{
+		IntervalMinutes: int(p.interval / time.Minute),
+		LastProbe:       p.lastProbe,
+	}
+	p.mu.RUnlock()
+
+	availability, err := p.GetAvailability(models.DefaultUpstreamProbeHours)
+	if err != nil {
+		utils.Logf("[上游探测] ⚠️  %v", err)
+		return state
+	}
+	state.Availability = availability.Availability
+	state.Outages = len(availability.Outages)
+	state.CurrentOutage = availability.CurrentOutage()
+	return state
+}
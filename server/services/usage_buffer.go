@@ -0,0 +1,204 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 内存使用记录缓冲的默认上限
+const (
+	DefaultUsageBufferSize   = 5000           // 最多保留的记录条数
+	DefaultUsageBufferMaxAge = 24 * time.Hour // 最长保留时长
+)
+
+// usageKey 使用记录去重键（每次获取的记录与上次有重叠）
+type usageKey struct {
+	id        int
+	createdAt int64
+	model     string
+	credits   int
+}
+
+// usageEntry 缓冲中的使用记录及其JSON编码缓存
+type usageEntry struct {
+	data  models.UsageData
+	key   usageKey
+	group string // 编码时的模型分组
+	json  []byte // 记录的JSON编码（模型分组变化时重新编码）
+}
+
+// UsageBuffer 有界环形缓冲：按获取顺序保存最近的使用记录，超出条数或时长上限时淘汰最旧的记录
+// 每条记录只编码一次，推送时直接拼接缓存的JSON，避免每次重新序列化全部记录
+type UsageBuffer struct {
+	mu      sync.Mutex
+	entries []*usageEntry // 环形数组
+	start   int           // 最旧记录的位置
+	size    int           // 当前记录数
+	maxAge  time.Duration
+	keys    map[usageKey]struct{}
+}
+
+// NewUsageBuffer 创建使用记录缓冲，上限不大于0时使用默认值
+func NewUsageBuffer(maxRecords int, maxAge time.Duration) *UsageBuffer {
+	if maxRecords <= 0 {
+		maxRecords = DefaultUsageBufferSize
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultUsageBufferMaxAge
+	}
+	return &UsageBuffer{
+		entries: make([]*usageEntry, maxRecords),
+		maxAge:  maxAge,
+		keys:    make(map[usageKey]struct{}),
+	}
+}
+
+// Add 加入新获取的使用记录，已存在或超过保留时长的记录跳过，返回新增条数
+func (b *UsageBuffer) Add(data []models.UsageData) int {
+	// 按时间从旧到新加入，淘汰时先淘汰最旧的记录
+	sorted := append([]models.UsageData(nil), data...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-b.maxAge)
+	added := 0
+	for _, record := range sorted {
+		key := usageKey{id: record.ID, createdAt: record.CreatedAt.UnixNano(), model: record.Model, credits: record.CreditsUsed}
+		if _, ok := b.keys[key]; ok || !record.CreatedAt.After(cutoff) {
+			continue
+		}
+		if b.size == len(b.entries) {
+			b.removeOldest()
+		}
+		b.entries[(b.start+b.size)%len(b.entries)] = &usageEntry{data: record, key: key}
+		b.size++
+		b.keys[key] = struct{}{}
+		added++
+	}
+	b.evictExpired(cutoff)
+	return added
+}
+
+// Records 返回缓冲中的全部记录（最新在前）
+func (b *UsageBuffer) Records() []models.UsageData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	records := make([]models.UsageData, 0, b.size)
+	for i := b.size - 1; i >= 0; i-- {
+		records = append(records, b.entries[(b.start+i)%len(b.entries)].data)
+	}
+	return records
+}
+
+// UsageCursor 某个订阅者已收到的使用记录，用于只推送新增的记录
+type UsageCursor struct {
+	sent map[usageKey]struct{}
+}
+
+// NewUsageCursor 创建增量推送游标（首次推送全部记录）
+func NewUsageCursor() *UsageCursor {
+	return &UsageCursor{sent: make(map[usageKey]struct{})}
+}
+
+// Reset 清空已收到的记录，下次推送全部记录
+func (c *UsageCursor) Reset() {
+	clear(c.sent)
+}
+
+// JSON 返回最近minutes分钟内记录（最新在前，附带当前模型分组）的JSON数组，没有记录时返回nil
+func (b *UsageBuffer) JSON(minutes int) []byte {
+	return b.JSONSince(minutes, nil)
+}
+
+// JSONSince 返回最近minutes分钟内cursor尚未收到的记录的JSON数组（最新在前，没有时返回nil），
+// 并将cursor更新为时间范围内的全部记录，移出时间范围的记录同时从cursor中删除；cursor为nil时返回全部记录
+func (b *UsageBuffer) JSONSince(minutes int, cursor *UsageCursor) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var cutoff time.Time
+	if minutes > 0 {
+		cutoff = time.Now().Add(-time.Duration(minutes) * time.Minute)
+	}
+
+	var inRange map[usageKey]struct{}
+	if cursor != nil {
+		inRange = make(map[usageKey]struct{}, len(cursor.sent))
+		defer func() { cursor.sent = inRange }()
+	}
+
+	var buf []byte
+	for i := b.size - 1; i >= 0; i-- {
+		entry := b.entries[(b.start+i)%len(b.entries)]
+		if !entry.data.CreatedAt.After(cutoff) {
+			continue
+		}
+		if cursor != nil {
+			inRange[entry.key] = struct{}{}
+			if _, ok := cursor.sent[entry.key]; ok {
+				continue
+			}
+		}
+		group := models.ModelGroupOf(entry.data.Model)
+		if entry.json == nil || entry.group != group {
+			record := entry.data
+			record.Group = group
+			encoded, err := json.Marshal(record)
+			if err != nil {
+				continue
+			}
+			entry.json = encoded
+			entry.group = group
+		}
+		if buf == nil {
+			buf = append(buf, '[')
+		} else {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, entry.json...)
+	}
+	if buf == nil {
+		return nil
+	}
+	return append(buf, ']')
+}
+
+// Stats 返回记录数和最新记录的时间（没有记录时为零值）
+func (b *UsageBuffer) Stats() (int, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var latest time.Time
+	for i := 0; i < b.size; i++ {
+		if createdAt := b.entries[(b.start+i)%len(b.entries)].data.CreatedAt; createdAt.After(latest) {
+			latest = createdAt
+		}
+	}
+	return b.size, latest
+}
+
+// evictExpired 淘汰超过保留时长的最旧记录（调用方持有锁）
+func (b *UsageBuffer) evictExpired(cutoff time.Time) {
+	for b.size > 0 && !b.entries[b.start].data.CreatedAt.After(cutoff) {
+		b.removeOldest()
+	}
+}
+
+// removeOldest 移除最旧的记录（调用方持有锁）
+func (b *UsageBuffer) removeOldest() {
+	entry := b.entries[b.start]
+	delete(b.keys, entry.key)
+	b.entries[b.start] = nil
+	b.start = (b.start + 1) % len(b.entries)
+	b.size--
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// usageRecord 生成一条测试用的使用记录
+func usageRecord(id int, createdAt time.Time) models.UsageData {
+	return models.UsageData{ID: id, CreatedAt: createdAt, Model: "claude-sonnet", CreditsUsed: 10}
+}
+
+func TestUsageBufferAddDeduplicates(t *testing.T) {
+	buffer := NewUsageBuffer(10, time.Hour)
+	now := time.Now()
+	batch := []models.UsageData{usageRecord(1, now.Add(-2*time.Minute)), usageRecord(2, now.Add(-time.Minute))}
+
+	if added := buffer.Add(batch); added != 2 {
+		t.Fatalf("首次加入应新增2条，实际 %d", added)
+	}
+	if added := buffer.Add(batch); added != 0 {
+		t.Fatalf("重复获取的记录不应新增，实际 %d", added)
+	}
+	if added := buffer.Add(append(batch, usageRecord(3, now))); added != 1 {
+		t.Fatalf("与上次重叠的获取应只新增1条，实际 %d", added)
+	}
+	if added := buffer.Add([]models.UsageData{usageRecord(4, now.Add(-2*time.Hour))}); added != 0 {
+		t.Fatalf("超过保留时长的记录不应加入，实际 %d", added)
+	}
+}
+
+func TestUsageBufferBounded(t *testing.T) {
+	buffer := NewUsageBuffer(3, time.Hour)
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		buffer.Add([]models.UsageData{usageRecord(i, now.Add(time.Duration(i-10)*time.Minute))})
+	}
+
+	records := buffer.Records()
+	if len(records) != 3 {
+		t.Fatalf("记录数应不超过上限3，实际 %d", len(records))
+	}
+	if records[0].ID != 4 || records[2].ID != 2 {
+		t.Fatalf("应淘汰最旧的记录并按最新在前返回: %+v", records)
+	}
+	// 被淘汰的记录再次出现时视为新记录
+	if added := buffer.Add([]models.UsageData{usageRecord(0, now.Add(-10*time.Minute))}); added != 1 {
+		t.Fatalf("被淘汰的记录应可重新加入，实际 %d", added)
+	}
+}
+
+// usageIDs 解析JSONSince返回的记录ID（最新在前）
+func usageIDs(t *testing.T, data []byte) []int {
+	t.Helper()
+	if data == nil {
+		return nil
+	}
+	var records []models.UsageData
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("解析记录失败: %v", err)
+	}
+	ids := make([]int, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	return ids
+}
+
+func TestUsageBufferJSONSinceCursor(t *testing.T) {
+	buffer := NewUsageBuffer(10, time.Hour)
+	now := time.Now()
+	buffer.Add([]models.UsageData{usageRecord(1, now.Add(-20*time.Minute)), usageRecord(2, now.Add(-time.Minute))})
+
+	cursor := NewUsageCursor()
+	if ids := usageIDs(t, buffer.JSONSince(60, cursor)); !slices.Equal(ids, []int{2, 1}) {
+		t.Fatalf("首次推送应包含全部记录，实际 %v", ids)
+	}
+	if data := buffer.JSONSince(60, cursor); data != nil {
+		t.Fatalf("没有新增记录时应返回nil，实际 %s", data)
+	}
+
+	buffer.Add([]models.UsageData{usageRecord(3, now)})
+	if ids := usageIDs(t, buffer.JSONSince(60, cursor)); !slices.Equal(ids, []int{3}) {
+		t.Fatalf("应只推送新增记录，实际 %v", ids)
+	}
+
+	// 缩小时间范围后移出范围的记录从游标中删除，重新进入范围时再次推送
+	if data := buffer.JSONSince(10, cursor); data != nil {
+		t.Fatalf("范围内的记录均已推送，实际 %s", data)
+	}
+	if ids := usageIDs(t, buffer.JSONSince(60, cursor)); !slices.Equal(ids, []int{1}) {
+		t.Fatalf("重新进入范围的记录应再次推送，实际 %v", ids)
+	}
+
+	cursor.Reset()
+	if ids := usageIDs(t, buffer.JSONSince(60, cursor)); !slices.Equal(ids, []int{3, 2, 1}) {
+		t.Fatalf("重置游标后应推送全部记录，实际 %v", ids)
+	}
+	if ids := usageIDs(t, buffer.JSONSince(60, nil)); len(ids) != 3 {
+		t.Fatalf("不使用游标时应返回全部记录，实际 %v", ids)
+	}
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/leafney/cccmu/server/utils"
+)
+
+// StartWatchdog 在常驻调度器中添加看门狗任务，按interval调用ping
+// 调度器卡死时ping随之停止，由systemd检测并重启服务
+func (s *SchedulerService) StartWatchdog(interval time.Duration, ping func()) error {
+	job, err := s.dailyResetScheduler.NewJob(
+		gocron.DurationJob(interval),
+		gocron.NewTask(ping),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithTags("watchdog"),
+	)
+	if err != nil {
+		return fmt.Errorf("创建看门狗任务失败: %w", err)
+	}
+
+	utils.Logf("[看门狗] ✅ 看门狗任务已创建，间隔: %v，任务ID: %v", interval, job.ID())
+	return nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/leafney/cccmu/server/models"
+)
+
+// webhookTimeout 调用Webhook的超时时间
+const webhookTimeout = 10 * time.Second
+
+// webhookClient Webhook调用共用的HTTP客户端
+// 在建立连接时检查实际连接的地址，域名解析到本机或链路本地地址（含重定向、DNS重绑定）时拒绝连接
+var webhookClient = resty.NewWithClient(&http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: guardWebhookDial,
+		}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+	},
+}).SetTimeout(webhookTimeout)
+
+// guardWebhookDial 拒绝连接本机或链路本地地址
+func guardWebhookDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || models.IsBlockedWebhookIP(ip) {
+		return fmt.Errorf("禁止访问的Webhook地址: %s", host)
+	}
+	return nil
+}
+
+// SendWebhook 以POST JSON方式调用Webhook，非2xx响应视为失败
+func SendWebhook(url string, payload models.WebhookPayload) error {
+	resp, err := webhookClient.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payload).
+		Post(url)
+	if err != nil {
+		return fmt.Errorf("调用Webhook失败: %w", err)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("调用Webhook失败: HTTP %d", resp.StatusCode())
+	}
+	return nil
+}
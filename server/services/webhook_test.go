@@ -0,0 +1,26 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+func TestSendWebhookRejectsLoopback(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	err := SendWebhook(server.URL, models.WebhookPayload{Event: models.WebhookEventThreshold, Timestamp: time.Now()})
+	if err == nil {
+		t.Fatal("指向回环地址的Webhook应被拒绝")
+	}
+	if called {
+		t.Fatal("被拒绝的Webhook不应发出请求")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "log"
+
+// 关闭阶段（按执行顺序）
+const (
+	shutdownProducers = iota // 产生事件或写入数据的后台服务（配置同步、自动重置、保活等）
+	shutdownJobs             // 调度服务的定时任务
+	shutdownListeners        // 事件监听器
+	shutdownDatabase         // 数据库
+	shutdownPhases
+)
+
+// shutdownStep 关闭流程中的一步
+type shutdownStep struct {
+	name string
+	stop func() error
+}
+
+// shutdownSequence 有序关闭流程：按阶段依次执行，同一阶段内按注册的逆序执行（与defer一致）
+// 保证数据库在所有可能写入的服务和任务停止后才关闭，监听器在不再有推送后才关闭
+type shutdownSequence struct {
+	phases [shutdownPhases][]shutdownStep
+	done   bool
+}
+
+// add 注册关闭步骤
+func (s *shutdownSequence) add(phase int, name string, stop func() error) {
+	s.phases[phase] = append(s.phases[phase], shutdownStep{name: name, stop: stop})
+}
+
+// run 按顺序执行所有关闭步骤（只执行一次）
+func (s *shutdownSequence) run() {
+	if s.done {
+		return
+	}
+	s.done = true
+
+	for _, steps := range s.phases {
+		for i := len(steps) - 1; i >= 0; i-- {
+			if err := steps[i].stop(); err != nil {
+				log.Printf("停止%s失败: %v", steps[i].name, err)
+			}
+		}
+	}
+}
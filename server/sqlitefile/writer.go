@@ -0,0 +1,369 @@
+package sqlitefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// 文件格式参数（https://www.sqlite.org/fileformat.html）
+const (
+	pageSize         = 4096
+	headerSize       = 100  // 第1页开头的数据库文件头
+	leafFlag         = 0x0d // 表B树叶子页
+	interiorFlag     = 0x05 // 表B树内部页
+	leafHeaderSize   = 8
+	interiorHeader   = 12
+	maxInteriorCell  = 4 + 9                                             // 子页号 + rowid varint
+	interiorChildren = (pageSize-interiorHeader)/(2+maxInteriorCell) + 1 // 内部页最多容纳的子页数（按最长单元格计算）
+	maxLocalPayload  = pageSize - 35                                     // 叶子页单元格内的最大载荷，超出部分写入溢出页
+	minLocalPayload  = (pageSize-12)*32/255 - 23
+	sqliteVersion    = 3045000 // 写入文件头的SQLite版本号
+)
+
+// Writer 以SQLite数据库文件格式写入导出数据，生成的文件可直接用sqlite3等工具打开查询
+// 只支持建表和按顺序追加行（不支持索引、更新和删除），各表的B树在写入行时逐页生成，
+// 内存中只保留每个表当前未写满的叶子页；所有表写完后调用Close写入文件头和表结构
+type Writer struct {
+	w      io.WriterAt
+	pages  uint32 // 已分配的页数（第1页保留给文件头和表结构，Close时写入）
+	tables []*Table
+	closed bool
+}
+
+// NewWriter 创建SQLite文件写入器
+func NewWriter(w io.WriterAt) *Writer {
+	return &Writer{w: w, pages: 1}
+}
+
+// Table 数据表，行按插入顺序分配从1开始的rowid
+type Table struct {
+	w        *Writer
+	name     string
+	sql      string
+	columns  int
+	rowid    int64
+	cells    [][]byte   // 当前叶子页的单元格
+	used     int        // 当前叶子页已使用的字节数
+	children []childRef // 已写入的叶子页
+	root     uint32
+}
+
+// childRef 已写入的B树页及其包含的最大rowid
+type childRef struct {
+	page     uint32
+	maxRowid int64
+}
+
+// CreateTable 创建数据表，columns为列定义（如 "credits INTEGER"）
+func (w *Writer) CreateTable(name string, columns ...string) (*Table, error) {
+	if w.closed {
+		return nil, errors.New("写入器已关闭")
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("数据表 %s 没有列", name)
+	}
+	for _, table := range w.tables {
+		if strings.EqualFold(table.name, name) {
+			return nil, fmt.Errorf("数据表 %s 已存在", name)
+		}
+	}
+	table := &Table{
+		w:       w,
+		name:    name,
+		sql:     fmt.Sprintf("CREATE TABLE %s (%s)", name, strings.Join(columns, ", ")),
+		columns: len(columns),
+		used:    leafHeaderSize,
+	}
+	w.tables = append(w.tables, table)
+	return table, nil
+}
+
+// Insert 追加一行，支持的值类型：nil、bool、int、int64、float64、string、[]byte
+func (t *Table) Insert(values ...any) error {
+	if t.w.closed {
+		return errors.New("写入器已关闭")
+	}
+	if len(values) != t.columns {
+		return fmt.Errorf("数据表 %s 有 %d 列，插入了 %d 个值", t.name, t.columns, len(values))
+	}
+	record, err := encodeRecord(values)
+	if err != nil {
+		return fmt.Errorf("数据表 %s: %w", t.name, err)
+	}
+
+	cell, err := t.w.leafCell(t.rowid+1, record)
+	if err != nil {
+		return err
+	}
+	if len(t.cells) > 0 && t.used+2+len(cell) > pageSize {
+		if err := t.flushLeaf(); err != nil {
+			return err
+		}
+	}
+	t.rowid++
+	t.cells = append(t.cells, cell)
+	t.used += 2 + len(cell)
+	return nil
+}
+
+// flushLeaf 写入当前叶子页（包含到目前为止插入的最后一行）
+func (t *Table) flushLeaf() error {
+	page := t.w.allocPage()
+	if err := t.w.writePage(page, buildPage(leafFlag, t.cells, 0, 0)); err != nil {
+		return err
+	}
+	t.children = append(t.children, childRef{page: page, maxRowid: t.rowid})
+	t.cells = nil
+	t.used = leafHeaderSize
+	return nil
+}
+
+// finish 写入剩余的叶子页并逐层生成内部页，记录根页号
+func (t *Table) finish() error {
+	if len(t.cells) > 0 || len(t.children) == 0 {
+		if err := t.flushLeaf(); err != nil {
+			return err
+		}
+	}
+
+	level := t.children
+	for len(level) > 1 {
+		// 平均分配各内部页的子页数，避免最后一页只有一个子页（没有单元格）
+		groups := (len(level) + interiorChildren - 1) / interiorChildren
+		var next []childRef
+		for g := 0; g < groups; g++ {
+			group := level[len(level)*g/groups : len(level)*(g+1)/groups]
+			cells := make([][]byte, 0, len(group)-1)
+			for _, child := range group[:len(group)-1] {
+				cell := binary.BigEndian.AppendUint32(nil, child.page)
+				cells = append(cells, appendVarint(cell, uint64(child.maxRowid)))
+			}
+			right := group[len(group)-1]
+			page := t.w.allocPage()
+			if err := t.w.writePage(page, buildPage(interiorFlag, cells, right.page, 0)); err != nil {
+				return err
+			}
+			next = append(next, childRef{page: page, maxRowid: right.maxRowid})
+		}
+		level = next
+	}
+	t.root = level[0].page
+	return nil
+}
+
+// Close 完成各表的B树并写入文件头和表结构（sqlite_schema），不关闭底层文件
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	cells := make([][]byte, 0, len(w.tables))
+	used := headerSize + leafHeaderSize
+	for i, table := range w.tables {
+		if err := table.finish(); err != nil {
+			return err
+		}
+		record, err := encodeRecord([]any{"table", table.name, table.name, int64(table.root), table.sql})
+		if err != nil {
+			return err
+		}
+		cell, err := w.leafCell(int64(i+1), record)
+		if err != nil {
+			return err
+		}
+		cells = append(cells, cell)
+		used += 2 + len(cell)
+	}
+	if used > pageSize {
+		return errors.New("表结构超过一页")
+	}
+
+	page := buildPage(leafFlag, cells, 0, headerSize)
+	writeHeader(page, w.pages)
+	return w.writePage(1, page)
+}
+
+// allocPage 分配新页
+func (w *Writer) allocPage() uint32 {
+	w.pages++
+	return w.pages
+}
+
+// writePage 写入指定页
+func (w *Writer) writePage(page uint32, data []byte) error {
+	if _, err := w.w.WriteAt(data, int64(page-1)*pageSize); err != nil {
+		return fmt.Errorf("写入第%d页失败: %w", page, err)
+	}
+	return nil
+}
+
+// leafCell 生成叶子页单元格，载荷超过页内上限时将超出部分写入溢出页链
+func (w *Writer) leafCell(rowid int64, payload []byte) ([]byte, error) {
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+	if len(payload) <= maxLocalPayload {
+		return append(cell, payload...), nil
+	}
+
+	local := minLocalPayload + (len(payload)-minLocalPayload)%(pageSize-4)
+	if local > maxLocalPayload {
+		local = minLocalPayload
+	}
+	cell = append(cell, payload[:local]...)
+
+	// 溢出页：4字节下一页页号（最后一页为0）+ 数据
+	rest := payload[local:]
+	page := w.allocPage()
+	cell = binary.BigEndian.AppendUint32(cell, page)
+	for len(rest) > 0 {
+		n := min(len(rest), pageSize-4)
+		var next uint32
+		if n < len(rest) {
+			next = w.allocPage()
+		}
+		data := make([]byte, pageSize)
+		binary.BigEndian.PutUint32(data, next)
+		copy(data[4:], rest[:n])
+		if err := w.writePage(page, data); err != nil {
+			return nil, err
+		}
+		rest = rest[n:]
+		page = next
+	}
+	return cell, nil
+}
+
+// buildPage 生成B树页，offset为页头在页内的偏移（第1页为100）
+func buildPage(flag byte, cells [][]byte, rightChild uint32, offset int) []byte {
+	page := make([]byte, pageSize)
+	header := page[offset:]
+	header[0] = flag
+	pointer := offset + leafHeaderSize
+	if flag == interiorFlag {
+		binary.BigEndian.PutUint32(header[8:], rightChild)
+		pointer = offset + interiorHeader
+	}
+	binary.BigEndian.PutUint16(header[3:], uint16(len(cells)))
+
+	content := pageSize
+	for _, cell := range cells {
+		content -= len(cell)
+		copy(page[content:], cell)
+		binary.BigEndian.PutUint16(page[pointer:], uint16(content))
+		pointer += 2
+	}
+	binary.BigEndian.PutUint16(header[5:], uint16(content))
+	return page
+}
+
+// writeHeader 写入数据库文件头
+func writeHeader(page []byte, pages uint32) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], pageSize)
+	page[18], page[19] = 1, 1                    // 读写版本（回滚日志模式）
+	page[21], page[22], page[23] = 64, 32, 32    // 载荷比例（固定值）
+	binary.BigEndian.PutUint32(page[24:], 1)     // 文件修改计数
+	binary.BigEndian.PutUint32(page[28:], pages) // 数据库页数
+	binary.BigEndian.PutUint32(page[40:], 1)     // 表结构版本
+	binary.BigEndian.PutUint32(page[44:], 4)     // 表结构格式
+	binary.BigEndian.PutUint32(page[56:], 1)     // 文本编码UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1)     // 页数有效对应的修改计数
+	binary.BigEndian.PutUint32(page[96:], sqliteVersion)
+}
+
+// encodeRecord 按SQLite记录格式编码一行：头部（长度及各列类型）+ 各列数据
+func encodeRecord(values []any) ([]byte, error) {
+	var types, body []byte
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = appendVarint(types, 0)
+		case bool:
+			types = appendVarint(types, map[bool]uint64{false: 8, true: 9}[v])
+		case int:
+			types, body = appendInt(types, body, int64(v))
+		case int64:
+			types, body = appendInt(types, body, v)
+		case float64:
+			types = appendVarint(types, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = appendVarint(types, uint64(len(v))*2+13)
+			body = append(body, v...)
+		case []byte:
+			types = appendVarint(types, uint64(len(v))*2+12)
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("第%d列的值类型 %T 不支持", i+1, value)
+		}
+	}
+
+	// 头部长度包含其自身的varint
+	headerLen := len(types) + 1
+	for varintLen(uint64(headerLen))+len(types) != headerLen {
+		headerLen = varintLen(uint64(headerLen)) + len(types)
+	}
+	record := appendVarint(make([]byte, 0, headerLen+len(body)), uint64(headerLen))
+	record = append(record, types...)
+	return append(record, body...), nil
+}
+
+// appendInt 以能容纳该值的最短整数类型编码
+func appendInt(types, body []byte, v int64) ([]byte, []byte) {
+	var size int
+	switch {
+	case v == 0:
+		return appendVarint(types, 8), body
+	case v == 1:
+		return appendVarint(types, 9), body
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		types, size = appendVarint(types, 1), 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		types, size = appendVarint(types, 2), 2
+	case v >= -1<<23 && v < 1<<23:
+		types, size = appendVarint(types, 3), 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		types, size = appendVarint(types, 4), 4
+	case v >= -1<<47 && v < 1<<47:
+		types, size = appendVarint(types, 5), 6
+	default:
+		types, size = appendVarint(types, 6), 8
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(v))
+	return types, append(body, buf[8-size:]...)
+}
+
+// appendVarint 追加SQLite格式的varint（大端，每字节7位，第9字节使用全部8位）
+func appendVarint(b []byte, v uint64) []byte {
+	if v <= 0x7f {
+		return append(b, byte(v))
+	}
+	var buf [9]byte
+	if v > 1<<56-1 {
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	n := 0
+	for ; v > 0; n++ {
+		buf[8-n] = byte(v&0x7f) | 0x80
+		v >>= 7
+	}
+	buf[8] &= 0x7f
+	return append(b, buf[9-n:]...)
+}
+
+// varintLen varint编码后的字节数
+func varintLen(v uint64) int {
+	return len(appendVarint(nil, v))
+}
@@ -0,0 +1,136 @@
+package sqlitefile
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// querySQLite 使用真实的SQLite读取文件并执行查询，返回以"|"分隔列、换行分隔行的结果
+// 依次尝试sqlite3命令行和python3的sqlite3模块，都不可用时跳过测试
+func querySQLite(t *testing.T, path, query string) string {
+	t.Helper()
+	var cmd *exec.Cmd
+	if _, err := exec.LookPath("sqlite3"); err == nil {
+		cmd = exec.Command("sqlite3", "-readonly", path, query)
+	} else if _, err := exec.LookPath("python3"); err == nil {
+		script := `import sqlite3, sys
+conn = sqlite3.connect("file:" + sys.argv[1] + "?mode=ro", uri=True)
+for row in conn.execute(sys.argv[2]):
+    print("|".join("" if v is None else str(v) for v in row))`
+		cmd = exec.Command("python3", "-c", script, path, query)
+	} else {
+		t.Skip("没有可用的SQLite读取工具（sqlite3或python3）")
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("执行查询 %q 失败: %v\n%s", query, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestWriterReadableBySQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.db")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w := NewWriter(file)
+
+	// 空表
+	if _, err := w.CreateTable("empty", "id INTEGER"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 载荷恰好等于页内上限、超出1字节（写入溢出页）和跨多个溢出页
+	blobs, err := w.CreateTable("blobs", "data BLOB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	atLimit := maxLocalPayload - 3 // 记录头3字节：头部长度1字节 + 列类型2字节
+	if record, _ := encodeRecord([]any{make([]byte, atLimit)}); len(record) != maxLocalPayload {
+		t.Fatalf("测试数据的载荷应为 %d 字节，实际 %d", maxLocalPayload, len(record))
+	}
+	blobSizes := []int{atLimit, atLimit + 1, 3 * pageSize}
+	for _, size := range blobSizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		if err := blobs.Insert(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 超过一个内部页可容纳的叶子页数，生成两层内部页
+	rows, err := w.CreateTable("rows", "n INTEGER", "text TEXT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const rowCount = 15000
+	for i := 1; i <= rowCount; i++ {
+		if err := rows.Insert(i, strings.Repeat("x", 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 各种整数编码长度，包括6字节和8字节整数
+	ints, err := w.CreateTable("ints", "v INTEGER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	intValues := []int64{0, 1, -1, 127, -129, 1 << 23, math.MaxInt32, 1<<47 - 1, -1 << 47, 1 << 47, math.MaxInt64, math.MinInt64}
+	for _, v := range intValues {
+		if err := ints.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows.children) <= interiorChildren {
+		t.Fatalf("测试数据应超过 %d 个叶子页，实际 %d", interiorChildren, len(rows.children))
+	}
+
+	if got := querySQLite(t, path, "PRAGMA integrity_check"); got != "ok" {
+		t.Fatalf("完整性检查失败: %s", got)
+	}
+	if got := querySQLite(t, path, "SELECT count(*) FROM empty"); got != "0" {
+		t.Errorf("空表行数 = %s", got)
+	}
+
+	// 第i个字节为byte(i)，校验长度和末尾两个字节（位于最后一个溢出页）
+	var wantLengths, wantTails []string
+	for _, size := range blobSizes {
+		wantLengths = append(wantLengths, strconv.Itoa(size))
+		wantTails = append(wantTails, fmt.Sprintf("%02X%02X", byte(size-2), byte(size-1)))
+	}
+	if got := querySQLite(t, path, "SELECT length(data) FROM blobs ORDER BY rowid"); got != strings.Join(wantLengths, "\n") {
+		t.Errorf("BLOB长度 = %q", got)
+	}
+	if got := querySQLite(t, path, "SELECT hex(substr(data, -2)) FROM blobs ORDER BY rowid"); got != strings.Join(wantTails, "\n") {
+		t.Errorf("BLOB末尾内容 = %q", got)
+	}
+
+	if got := querySQLite(t, path, "SELECT count(*), min(rowid), max(rowid), sum(n) FROM rows"); got != "15000|1|15000|112507500" {
+		t.Errorf("多层B树的统计 = %q", got)
+	}
+	if got := querySQLite(t, path, "SELECT n FROM rows WHERE rowid = 7777"); got != "7777" {
+		t.Errorf("按rowid查找 = %q", got)
+	}
+
+	var wantInts []string
+	for _, v := range intValues {
+		wantInts = append(wantInts, strconv.FormatInt(v, 10))
+	}
+	if got := querySQLite(t, path, "SELECT v FROM ints ORDER BY rowid"); got != strings.Join(wantInts, "\n") {
+		t.Errorf("整数 = %q，期望 %q", got, strings.Join(wantInts, "\n"))
+	}
+}
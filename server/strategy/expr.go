@@ -0,0 +1,565 @@
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 表达式语法（expr-lang的子集）：
+//   字面量：数字、字符串（单引号或双引号）、true、false
+//   运算符（优先级由低到高）：
+//     条件 ?:；逻辑 || or；逻辑 && and；相等 == !=；比较 < <= > >=；
+//     加减 + -；乘除 * / %；一元 ! not -
+//   括号改变优先级，变量名见Input
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+// token 词法单元
+type token struct {
+	kind  tokenKind
+	text  string
+	pos   int     // 在源码中的位置（用于错误提示）
+	num   float64 // 数字字面量的值
+	value string  // 字符串字面量的值
+}
+
+// operators 支持的运算符（长运算符在前，保证优先匹配）
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "*", "/", "%", "!", "?", ":", "(", ")"}
+
+// keywordOperators 以单词形式书写的运算符
+var keywordOperators = map[string]string{"and": "&&", "or": "||", "not": "!"}
+
+// tokenize 将源码切分为词法单元
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, fmt.Errorf("位置%d: 无效的数字 %s", start, text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, pos: start, num: num})
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("位置%d: 字符串缺少结束引号", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:i]), pos: start, value: sb.String()})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			if op, ok := keywordOperators[text]; ok {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: start})
+			}
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("位置%d: 无法识别的字符 %q", i, r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: matched, pos: i})
+			i += len([]rune(matched))
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// valueType 表达式的值类型（编译时检查）
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeString
+	typeBool
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "数字"
+	case typeString:
+		return "字符串"
+	default:
+		return "布尔值"
+	}
+}
+
+// typeOf 获取运行时值的类型
+func typeOf(value interface{}) valueType {
+	switch value.(type) {
+	case float64:
+		return typeNumber
+	case string:
+		return typeString
+	default:
+		return typeBool
+	}
+}
+
+// node 语法树节点
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+	// check 检查类型并返回表达式的值类型
+	check(vars map[string]valueType) (valueType, error)
+}
+
+// parser 递归下降语法分析器
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]valueType // 允许使用的变量
+}
+
+// parse 解析表达式源码，引用未知变量时返回错误
+func parse(src string, vars map[string]valueType) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: vars}
+	expr, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("位置%d: 多余的内容 %s", tok.pos, tok.text)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept 下一个词法单元是指定运算符之一时消费并返回
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// expect 消费指定运算符，不匹配时返回错误
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return fmt.Errorf("位置%d: 缺少 %s", tok.pos, op)
+		}
+		return fmt.Errorf("位置%d: 期望 %s，实际为 %s", tok.pos, op, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseConditional() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// binaryLevels 二元运算符优先级（由低到高）
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level >= len(binaryLevels) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(binaryLevels[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &literalNode{value: tok.num}, nil
+	case tokenString:
+		return &literalNode{value: tok.value}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		if _, ok := p.vars[tok.text]; !ok {
+			return nil, fmt.Errorf("位置%d: 未知变量 %s", tok.pos, tok.text)
+		}
+		return &variableNode{name: tok.text}, nil
+	case tokenOperator:
+		if tok.text == "(" {
+			expr, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+		return nil, fmt.Errorf("位置%d: 意外的 %s", tok.pos, tok.text)
+	default:
+		return nil, fmt.Errorf("位置%d: 表达式不完整", tok.pos)
+	}
+}
+
+// literalNode 字面量
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(_ map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *literalNode) check(_ map[string]valueType) (valueType, error) {
+	return typeOf(n.value), nil
+}
+
+// variableNode 变量引用
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(env map[string]interface{}) (interface{}, error) {
+	value, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("未知变量 %s", n.name)
+	}
+	return value, nil
+}
+
+func (n *variableNode) check(vars map[string]valueType) (valueType, error) {
+	typ, ok := vars[n.name]
+	if !ok {
+		return 0, fmt.Errorf("未知变量 %s", n.name)
+	}
+	return typ, nil
+}
+
+// unaryNode 一元运算
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! 的操作数必须是布尔值")
+		}
+		return !b, nil
+	}
+	num, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("- 的操作数必须是数字")
+	}
+	return -num, nil
+}
+
+func (n *unaryNode) check(vars map[string]valueType) (valueType, error) {
+	typ, err := n.operand.check(vars)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		if typ != typeBool {
+			return 0, fmt.Errorf("! 的操作数必须是布尔值，实际为%s", typ)
+		}
+		return typeBool, nil
+	}
+	if typ != typeNumber {
+		return 0, fmt.Errorf("- 的操作数必须是数字，实际为%s", typ)
+	}
+	return typeNumber, nil
+}
+
+// binaryNode 二元运算
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// 逻辑运算短路求值
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s 的操作数必须是布尔值", n.op)
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s 的操作数必须是布尔值", n.op)
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		if typeOf(left) != typeOf(right) {
+			return nil, fmt.Errorf("%s 两侧类型不一致（%s与%s）", n.op, typeOf(left), typeOf(right))
+		}
+		return (left == right) == (n.op == "=="), nil
+	}
+
+	// 字符串支持比较和拼接（如 time >= "22:00"）
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s 两侧类型不一致", n.op)
+		}
+		switch n.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		}
+		return nil, fmt.Errorf("字符串不支持 %s 运算", n.op)
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s 的操作数必须是数字", n.op)
+	}
+	switch n.op {
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("除数为0")
+		}
+		return l / r, nil
+	case "%":
+		if int64(r) == 0 {
+			return nil, fmt.Errorf("除数为0")
+		}
+		return float64(int64(l) % int64(r)), nil
+	}
+	return nil, fmt.Errorf("不支持的运算符 %s", n.op)
+}
+
+func (n *binaryNode) check(vars map[string]valueType) (valueType, error) {
+	left, err := n.left.check(vars)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.check(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		if left != typeBool || right != typeBool {
+			return 0, fmt.Errorf("%s 的操作数必须是布尔值，实际为%s与%s", n.op, left, right)
+		}
+		return typeBool, nil
+	}
+	if left != right {
+		return 0, fmt.Errorf("%s 两侧类型不一致（%s与%s）", n.op, left, right)
+	}
+	switch n.op {
+	case "==", "!=":
+		return typeBool, nil
+	case "<", "<=", ">", ">=":
+		if left == typeBool {
+			return 0, fmt.Errorf("布尔值不支持 %s 运算", n.op)
+		}
+		return typeBool, nil
+	case "+":
+		if left == typeBool {
+			return 0, fmt.Errorf("布尔值不支持 %s 运算", n.op)
+		}
+		return left, nil
+	}
+	if left != typeNumber {
+		return 0, fmt.Errorf("%s 的操作数必须是数字，实际为%s", n.op, left)
+	}
+	return typeNumber, nil
+}
+
+// conditionalNode 条件运算 cond ? then : otherwise
+type conditionalNode struct {
+	cond, then, otherwise node
+}
+
+func (n *conditionalNode) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := n.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	cond, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("条件运算的条件必须是布尔值")
+	}
+	if cond {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+func (n *conditionalNode) check(vars map[string]valueType) (valueType, error) {
+	cond, err := n.cond.check(vars)
+	if err != nil {
+		return 0, err
+	}
+	if cond != typeBool {
+		return 0, fmt.Errorf("条件运算的条件必须是布尔值，实际为%s", cond)
+	}
+	then, err := n.then.check(vars)
+	if err != nil {
+		return 0, err
+	}
+	otherwise, err := n.otherwise.check(vars)
+	if err != nil {
+		return 0, err
+	}
+	if then != otherwise {
+		return 0, fmt.Errorf("条件运算两个分支的类型不一致（%s与%s）", then, otherwise)
+	}
+	return then, nil
+}
@@ -0,0 +1,116 @@
+// Package strategy 自定义重置策略：用户编写的表达式根据积分余额、时间和重置状态决定是否重置
+package strategy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 策略返回的决策
+const (
+	DecisionReset  = "reset"  // 重置积分
+	DecisionNotify = "notify" // 推送通知
+	DecisionNone   = "none"   // 不处理
+)
+
+// MaxSourceLength 策略表达式的最大长度
+const MaxSourceLength = 2000
+
+// Input 策略求值时可用的变量
+type Input struct {
+	Balance        int       // balance: 当前积分余额
+	Threshold      int       // threshold: 配置的积分阈值
+	Time           time.Time // time: 当前时间（表达式中为 "HH:MM" 字符串），另提供 hour、minute、weekday(0为周日)
+	UsedToday      bool      // usedToday: 本周期是否已使用重置
+	ResetRemaining int       // resetRemaining: 本周期剩余可用重置次数
+}
+
+// variables 表达式中允许使用的变量及其类型
+var variables = map[string]valueType{
+	"balance":        typeNumber,
+	"threshold":      typeNumber,
+	"time":           typeString,
+	"hour":           typeNumber,
+	"minute":         typeNumber,
+	"weekday":        typeNumber,
+	"usedToday":      typeBool,
+	"resetRemaining": typeNumber,
+}
+
+// Program 编译后的策略
+type Program struct {
+	root node
+}
+
+// Compile 编译策略表达式并检查类型（运算符两侧类型不匹配、返回值不是字符串时返回错误）
+func Compile(src string) (*Program, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("策略表达式不能为空")
+	}
+	if len(src) > MaxSourceLength {
+		return nil, fmt.Errorf("策略表达式不能超过%d个字符", MaxSourceLength)
+	}
+	root, err := parse(src, variables)
+	if err != nil {
+		return nil, fmt.Errorf("策略表达式语法错误: %w", err)
+	}
+	typ, err := root.check(variables)
+	if err != nil {
+		return nil, fmt.Errorf("策略表达式类型错误: %w", err)
+	}
+	if typ != typeString {
+		return nil, fmt.Errorf("策略须返回 \"reset\"、\"notify\" 或 \"none\"，表达式结果为%s", typ)
+	}
+	return &Program{root: root}, nil
+}
+
+// Decide 根据输入计算决策
+func (p *Program) Decide(input Input) (string, error) {
+	env := map[string]interface{}{
+		"balance":        float64(input.Balance),
+		"threshold":      float64(input.Threshold),
+		"time":           input.Time.Format("15:04"),
+		"hour":           float64(input.Time.Hour()),
+		"minute":         float64(input.Time.Minute()),
+		"weekday":        float64(input.Time.Weekday()),
+		"usedToday":      input.UsedToday,
+		"resetRemaining": float64(input.ResetRemaining),
+	}
+
+	value, err := p.root.eval(env)
+	if err != nil {
+		return "", fmt.Errorf("策略执行失败: %w", err)
+	}
+	decision, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("策略须返回 \"reset\"、\"notify\" 或 \"none\"，实际返回 %v", value)
+	}
+	switch decision {
+	case DecisionReset, DecisionNotify, DecisionNone:
+		return decision, nil
+	}
+	return "", fmt.Errorf("策略须返回 \"reset\"、\"notify\" 或 \"none\"，实际返回 %q", decision)
+}
+
+// validationSamples 校验时试运行的示例输入（覆盖余额高低、白天夜间、是否已重置，使各分支都能被执行到）
+var validationSamples = []Input{
+	{Balance: 1000, Threshold: 100, Time: time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local), ResetRemaining: 1},
+	{Balance: 50, Threshold: 100, Time: time.Date(2025, 1, 6, 23, 30, 0, 0, time.Local), ResetRemaining: 1},
+	{Balance: 0, Threshold: 100, Time: time.Date(2025, 1, 5, 3, 0, 0, 0, time.Local), UsedToday: true},
+}
+
+// Validate 校验策略表达式：编译并以多组有代表性的示例输入试运行
+func Validate(src string) error {
+	program, err := Compile(src)
+	if err != nil {
+		return err
+	}
+	for _, sample := range validationSamples {
+		if _, err := program.Decide(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
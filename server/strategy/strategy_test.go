@@ -0,0 +1,131 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string // 错误信息应包含的内容
+	}{
+		{"空表达式", "  ", "不能为空"},
+		{"未知变量", "foo > 1 ? 'reset' : 'none'", "未知变量 foo"},
+		{"缺少右括号", "(balance > 1 ? 'reset' : 'none'", "缺少 )"},
+		{"缺少冒号", "balance > 1 ? 'reset'", "缺少 :"},
+		{"多余内容", "'none' 'reset'", "多余的内容"},
+		{"字符串缺少结束引号", "'none", "缺少结束引号"},
+		{"无法识别的字符", "balance # 1", "无法识别的字符"},
+		{"数字与字符串相等比较", "balance == 'x' ? 'reset' : 'none'", "两侧类型不一致"},
+		{"数字与字符串大小比较", "time < 5 ? 'reset' : 'none'", "两侧类型不一致"},
+		{"布尔值参与逻辑运算以外的运算", "usedToday + 1 == 2 ? 'reset' : 'none'", "两侧类型不一致"},
+		{"逻辑运算的操作数不是布尔值", "balance && usedToday ? 'reset' : 'none'", "必须是布尔值"},
+		{"条件不是布尔值", "balance ? 'reset' : 'none'", "条件必须是布尔值"},
+		{"分支类型不一致", "usedToday ? 'none' : 1", "分支的类型不一致"},
+		{"字符串不支持减法", "time - '1' == '' ? 'reset' : 'none'", "必须是数字"},
+		{"返回值不是字符串", "balance < threshold", "表达式结果为布尔值"},
+		{"一元负号作用于字符串", "-time == 1 ? 'reset' : 'none'", "必须是数字"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil {
+				t.Fatalf("编译 %q 应失败", tt.src)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误信息 %q 应包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecide(t *testing.T) {
+	night := time.Date(2025, 1, 6, 23, 0, 0, 0, time.Local)
+	noon := time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local)
+	sunday := time.Date(2025, 1, 5, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name  string
+		src   string
+		input Input
+		want  string
+	}{
+		{"比例低于阈值", "balance / threshold < 0.2 ? 'reset' : 'none'", Input{Balance: 10, Threshold: 100, Time: noon}, DecisionReset},
+		{"比例高于阈值", "balance / threshold < 0.2 ? 'reset' : 'none'", Input{Balance: 50, Threshold: 100, Time: noon}, DecisionNone},
+		{"时间字符串比较", "time >= '22:00' && balance < 50 ? 'reset' : 'none'", Input{Balance: 10, Time: night}, DecisionReset},
+		{"白天不重置", "time >= '22:00' && balance < 50 ? 'reset' : 'none'", Input{Balance: 10, Time: noon}, DecisionNone},
+		{"已重置只通知", "usedToday ? 'notify' : 'reset'", Input{Time: noon, UsedToday: true}, DecisionNotify},
+		{"not与and关键字", "not usedToday and resetRemaining > 0 ? 'reset' : 'none'", Input{Time: noon, ResetRemaining: 1}, DecisionReset},
+		{"or关键字", "hour < 6 or weekday == 0 ? 'notify' : 'none'", Input{Time: sunday}, DecisionNotify},
+		{"嵌套条件", "usedToday ? 'none' : balance < 50 ? 'reset' : balance < 200 ? 'notify' : 'none'", Input{Balance: 100, Time: noon}, DecisionNotify},
+		{"运算优先级", "balance + threshold * 2 == 210 ? 'reset' : 'none'", Input{Balance: 10, Threshold: 100, Time: noon}, DecisionReset},
+		{"取余", "minute % 15 == 0 ? 'notify' : 'none'", Input{Time: noon}, DecisionNotify},
+		{"一元负号", "-balance < -5 ? 'reset' : 'none'", Input{Balance: 10, Time: noon}, DecisionReset},
+		{"字符串相等", "time == '12:00' ? 'notify' : 'none'", Input{Time: noon}, DecisionNotify},
+		{"字符串拼接", "'re' + 'set'", Input{Time: noon}, DecisionReset},
+		{"数字分隔符", "balance < 1_000 ? 'reset' : 'none'", Input{Balance: 999, Time: noon}, DecisionReset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("编译失败: %v", err)
+			}
+			got, err := program.Decide(tt.input)
+			if err != nil {
+				t.Fatalf("求值失败: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("决策 = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecideRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		input Input
+		want  string
+	}{
+		{"除数为0", "balance / threshold < 0.2 ? 'reset' : 'none'", Input{Balance: 10}, "除数为0"},
+		{"返回未知决策", "'stop'", Input{}, "实际返回 \"stop\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("编译失败: %v", err)
+			}
+			_, err = program.Decide(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 = %v，期望包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		valid bool
+	}{
+		{"按比例重置", "balance / threshold < 0.2 ? 'reset' : 'none'", true},
+		{"README示例", "usedToday ? 'none' : balance < 50 && hour >= 22 ? 'reset' : balance < 200 ? 'notify' : 'none'", true},
+		{"仅在部分输入下返回无效决策", "balance < 100 ? 'stop' : 'none'", false},
+		{"仅在已重置时返回无效决策", "usedToday ? 'later' : 'none'", false},
+		{"类型错误", "balance == 'x' ? 'reset' : 'none'", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.src); (err == nil) != tt.valid {
+				t.Fatalf("Validate(%q) = %v，期望有效: %v", tt.src, err, tt.valid)
+			}
+		})
+	}
+}
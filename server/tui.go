@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/leafney/cccmu/server/models"
+)
+
+// 终端监控参数
+const (
+	tuiSparklineMinutes = 60              // 用量趋势图覆盖的分钟数（每分钟一格）
+	tuiMaxEvents        = 8               // 保留的最近事件条数
+	tuiReconnectDelay   = 5 * time.Second // 连接断开后的重连间隔
+)
+
+// tuiSparkChars 趋势图字符（由低到高）
+var tuiSparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// tuiEvent 终端监控中显示的事件
+type tuiEvent struct {
+	time    time.Time
+	message string
+}
+
+// tuiState 终端监控状态，由SSE事件更新
+type tuiState struct {
+	mu sync.Mutex
+
+	server      string
+	connected   bool
+	running     bool
+	resetUsed   bool
+	resetKnown  bool // 已收到连接后的首次重置状态（之后的变化才记为事件）
+	maintenance models.MaintenanceStatus
+	health      *models.HealthState
+	balance     *models.CreditBalance
+	usage       []models.UsageData
+	events      []tuiEvent
+}
+
+// runTUICommand 连接SSE数据流并在终端中实时显示积分余额、用量趋势和最近事件
+func runTUICommand(c *cliClient, _ cliOptions) error {
+	state := &tuiState{server: strings.TrimSuffix(c.baseURL, apiV1Prefix)}
+
+	// 切换到终端备用屏幕并隐藏光标，退出时恢复
+	fmt.Print("\033[?1049h\033[?25l")
+	restore := func() { fmt.Print("\033[?25h\033[?1049l") }
+	defer restore()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	redraw := make(chan struct{}, 1)
+	go state.follow(c, redraw)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		state.render()
+
+		select {
+		case <-quit:
+			return nil
+		case <-redraw:
+		case <-ticker.C:
+		}
+	}
+}
+
+// follow 持续读取SSE数据流，断开后自动重连
+func (s *tuiState) follow(c *cliClient, redraw chan<- struct{}) {
+	notify := func() {
+		select {
+		case redraw <- struct{}{}:
+		default:
+		}
+	}
+
+	for {
+		err := c.streamEvents(fmt.Sprintf("/usage/stream?minutes=%d", tuiSparklineMinutes), func(event string, data []byte) {
+			s.apply(event, data)
+			notify()
+		})
+
+		s.mu.Lock()
+		s.connected = false
+		s.resetKnown = false
+		if err != nil {
+			s.addEvent(fmt.Sprintf("连接断开: %v，%d秒后重连", err, int(tuiReconnectDelay.Seconds())))
+		} else {
+			s.addEvent(fmt.Sprintf("连接已关闭，%d秒后重连", int(tuiReconnectDelay.Seconds())))
+		}
+		s.mu.Unlock()
+		notify()
+
+		time.Sleep(tuiReconnectDelay)
+	}
+}
+
+// apply 根据SSE事件更新状态
+func (s *tuiState) apply(event string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch event {
+	case "connected":
+		s.connected = true
+		s.addEvent("已连接")
+
+	case "usage":
+		var usage []models.UsageData
+		if json.Unmarshal(data, &usage) == nil {
+			s.usage = usage
+		}
+
+	case "balance":
+		var balance models.CreditBalance
+		if json.Unmarshal(data, &balance) == nil {
+			if s.balance != nil && s.balance.Remaining != balance.Remaining {
+				s.addEvent(fmt.Sprintf("积分余额 %d → %d", s.balance.Remaining, balance.Remaining))
+			}
+			s.balance = &balance
+		}
+
+	case "reset_status":
+		var payload struct {
+			ResetUsed bool `json:"resetUsed"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			if s.resetKnown && payload.ResetUsed && !s.resetUsed {
+				s.addEvent("今日重置已使用")
+			}
+			s.resetUsed = payload.ResetUsed
+			s.resetKnown = true
+		}
+
+	case "monitoring_status":
+		var payload struct {
+			IsMonitoring bool                     `json:"isMonitoring"`
+			Maintenance  models.MaintenanceStatus `json:"maintenance"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			s.running = payload.IsMonitoring
+			s.maintenance = payload.Maintenance
+		}
+
+	case "health":
+		var health models.HealthState
+		if json.Unmarshal(data, &health) == nil {
+			if s.health != nil && s.health.State != health.State {
+				s.addEvent(fmt.Sprintf("健康状态 %s → %s", s.health.State, health.State))
+			}
+			s.health = &health
+		}
+
+	case "error":
+		var payload struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			s.addEvent("错误: " + payload.Message)
+		}
+
+	case "notification":
+		var notification models.Notification
+		if json.Unmarshal(data, &notification) == nil {
+			s.addEvent(fmt.Sprintf("通知: %s %s", notification.Title, notification.Message))
+		}
+
+	case "server_shutdown":
+		s.addEvent("服务正在重启")
+
+	case "auth_expired":
+		s.addEvent("认证已失效")
+	}
+}
+
+// addEvent 记录事件（调用方持有锁）
+func (s *tuiState) addEvent(message string) {
+	s.events = append(s.events, tuiEvent{time: time.Now(), message: message})
+	if len(s.events) > tuiMaxEvents {
+		s.events = s.events[len(s.events)-tuiMaxEvents:]
+	}
+}
+
+// render 重绘整个终端界面
+func (s *tuiState) render() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+
+	fmt.Fprintf(&b, "CCCMU 终端监控  %s  %s\n\n", s.server, time.Now().Format("2006-01-02 15:04:05"))
+
+	connection := "\033[31m未连接\033[0m"
+	if s.connected {
+		connection = "\033[32m已连接\033[0m"
+	}
+	fmt.Fprintf(&b, "连接: %s   监控: %s   今日重置: %s   健康: %s\n",
+		connection, onOff(s.running, "运行中", "已停止"), onOff(s.resetUsed, "已使用", "未使用"), s.healthText())
+	if s.maintenance.Enabled {
+		fmt.Fprintf(&b, "\033[33m维护模式中: %s\033[0m\n", valueOr(s.maintenance.Reason, "无原因"))
+	}
+
+	b.WriteString("\n")
+	if s.balance != nil {
+		fmt.Fprintf(&b, "剩余积分: \033[1m%d\033[0m  (%s，更新于 %s)\n",
+			s.balance.Remaining, valueOr(s.balance.Plan, "-"), s.balance.UpdatedAt.Local().Format("15:04:05"))
+	} else {
+		b.WriteString("剩余积分: -\n")
+	}
+
+	// 用量趋势（最近60分钟，每分钟一格）
+	buckets, total := usageBuckets(s.usage, tuiSparklineMinutes, time.Now())
+	fmt.Fprintf(&b, "\n最近%d分钟用量: %d\n%s\n", tuiSparklineMinutes, total, sparkline(buckets))
+
+	// 按模型汇总
+	byModel := make(map[string]int)
+	for _, record := range models.UsageDataList(s.usage).FilterByTimeRange(tuiSparklineMinutes) {
+		byModel[record.Model] += record.CreditsUsed
+	}
+	modelNames := make([]string, 0, len(byModel))
+	for name := range byModel {
+		modelNames = append(modelNames, name)
+	}
+	sort.Slice(modelNames, func(i, j int) bool { return byModel[modelNames[i]] > byModel[modelNames[j]] })
+	for _, name := range modelNames {
+		fmt.Fprintf(&b, "  %-32s %d\n", name, byModel[name])
+	}
+
+	b.WriteString("\n最近事件:\n")
+	if len(s.events) == 0 {
+		b.WriteString("  -\n")
+	}
+	for i := len(s.events) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "  %s  %s\n", s.events[i].time.Format("15:04:05"), s.events[i].message)
+	}
+
+	b.WriteString("\n按 Ctrl+C 退出")
+	fmt.Print(b.String())
+}
+
+// healthText 健康状态显示文本（调用方持有锁）
+func (s *tuiState) healthText() string {
+	if s.health == nil {
+		return "-"
+	}
+
+	switch s.health.State {
+	case models.HealthStateOK:
+		return "\033[32m正常\033[0m"
+	case models.HealthStateDegraded:
+		return "\033[33m降级\033[0m"
+	default:
+		return "\033[31m异常\033[0m"
+	}
+}
+
+// usageBuckets 按分钟汇总最近minutes分钟内的积分用量，返回每分钟用量（由旧到新）和总量
+func usageBuckets(usage []models.UsageData, minutes int, now time.Time) ([]int, int) {
+	buckets := make([]int, minutes)
+	total := 0
+	end := now.Truncate(time.Minute)
+
+	for _, record := range usage {
+		index := minutes - 1 - int(end.Sub(record.CreatedAt.Truncate(time.Minute))/time.Minute)
+		if index < 0 || index >= minutes {
+			continue
+		}
+		buckets[index] += record.CreditsUsed
+		total += record.CreditsUsed
+	}
+	return buckets, total
+}
+
+// sparkline 将数值序列渲染为字符趋势图
+func sparkline(values []int) string {
+	maxValue := 0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if v <= 0 || maxValue == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		index := v * (len(tuiSparkChars) - 1) / maxValue
+		b.WriteRune(tuiSparkChars[index])
+	}
+	return b.String()
+}
+
+// streamEvents 连接SSE接口并逐个回调事件，连接结束时返回
+func (c *cliClient) streamEvents(path string, handle func(event string, data []byte)) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+	req.Header.Set("Accept", "text/event-stream")
+
+	// 流式连接不设置整体超时
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	var event string
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event != "" {
+				handle(event, data)
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"os"
+	"strings"
+)
+
+// childEnvNames 传递给子进程（事件Hook、插件）的环境变量
+// SYSTEMROOT 为Windows下程序正常运行所需（如网络访问），其他平台上通常不存在
+var childEnvNames = []string{"PATH", "HOME", "LANG", "SYSTEMROOT"}
+
+// childEnvPrefix 以此为前缀的环境变量同样传递给子进程
+const childEnvPrefix = "CCCMU_"
+
+// ChildEnv 构建子进程的最小环境变量，不继承主密钥、同步密钥等敏感变量，extra 为追加的 KEY=VALUE
+func ChildEnv(extra ...string) []string {
+	var env []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(strings.ToUpper(name), childEnvPrefix) {
+			env = append(env, entry)
+			continue
+		}
+		for _, allowed := range childEnvNames {
+			if strings.EqualFold(name, allowed) {
+				env = append(env, entry)
+				break
+			}
+		}
+	}
+	return append(env, extra...)
+}
@@ -1,18 +1,32 @@
-import type { IUserConfig, IUserConfigRequest, IAPIResponse, IUsageData, ICreditBalance, IMonitoringStatus, IDailyUsage } from '../types';
+import type { IUserConfig, IUserConfigRequest, IAPIResponse, IErrorResponse, IUsageData, ICreditBalance, IMonitoringStatus, IDailyUsage, IHealthState, IJobTick, RefreshScope, IRefreshResult, ISameDayComparison, IResetSuggestion, IResetReport, IBalanceCurve, IUpstreamAvailability, IUsageDelta, IAnnotation, IAnnotationRequest, IAnnotationEvent } from '../types';
 
 // 认证相关接口类型（内部使用）
 
 interface ILoginResponse {
   message: string;
   expiresAt: string;
+  rememberMe: boolean;
 }
 
 interface IAuthStatusResponse {
   authenticated: boolean;
   expiresAt?: string;
+  proxyUser?: string;  // 反向代理认证的用户名
 }
 
-const API_BASE = '/api';
+interface ISessionInfo {
+  id: string;          // 会话ID前8位
+  createdAt: string;   // 登录时间
+  expiresAt: string;   // 过期时间
+  rememberMe: boolean; // 是否为“记住我”长期会话
+  loginIp: string;     // 登录时的客户端IP
+  ip: string;          // 最近一次请求的客户端IP
+  userAgent: string;   // 最近一次请求的User-Agent
+  lastSeenAt: string;  // 最近一次请求时间
+  current: boolean;    // 是否为当前会话
+}
+
+const API_BASE = '/api/v1';
 const DEFAULT_TIMEOUT = 30000; // 30秒超时
 
 // 创建超时控制器
@@ -22,6 +36,31 @@ function createTimeoutController(timeout: number = DEFAULT_TIMEOUT): AbortContro
   return controller;
 }
 
+// 生成幂等键（crypto.randomUUID仅在安全上下文可用，局域网HTTP访问时回退为随机字符串）
+function createIdempotencyKey(): string {
+  if (typeof crypto !== 'undefined' && typeof crypto.randomUUID === 'function') {
+    return crypto.randomUUID();
+  }
+  return `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}${Math.random().toString(36).slice(2)}`;
+}
+
+// 读取错误响应中的提示，验证错误附带各字段的说明
+async function readErrorMessage(response: Response): Promise<string> {
+  try {
+    const body: IErrorResponse = await response.json();
+    if (body.errors && body.errors.length > 0) {
+      const details = body.errors.map(e => (e.field ? `${e.field}: ${e.message}` : e.message)).join('; ');
+      return `${body.message}（${details}）`;
+    }
+    if (body.message) {
+      return body.message;
+    }
+  } catch {
+    // 响应不是JSON时使用状态码
+  }
+  return `HTTP ${response.status}: ${response.statusText}`;
+}
+
 class APIClient {
   private async request<T>(
     endpoint: string,
@@ -41,7 +80,7 @@ class APIClient {
       });
 
       if (!response.ok) {
-        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+        throw new Error(await readErrorMessage(response));
       }
 
       return response.json();
@@ -58,11 +97,29 @@ class APIClient {
     return this.request<IUserConfig>('/config');
   }
 
-  // 更新配置
+  // 更新配置（携带幂等键，网络异常时使用同一幂等键重试一次，服务端不会重复应用）
   async updateConfig(config: IUserConfigRequest): Promise<IAPIResponse> {
-    return this.request('/config', {
+    const options: RequestInit = {
       method: 'PUT',
       body: JSON.stringify(config),
+      headers: { 'Idempotency-Key': createIdempotencyKey() },
+    };
+
+    try {
+      return await this.request('/config', options);
+    } catch (error) {
+      // fetch在网络中断时抛出TypeError，此时请求可能已被服务端处理
+      if (error instanceof TypeError) {
+        return this.request('/config', options);
+      }
+      throw error;
+    }
+  }
+
+  // 撤销最近一次配置修改
+  async undoConfig(): Promise<IAPIResponse> {
+    return this.request('/config/undo', {
+      method: 'POST',
     });
   }
 
@@ -80,9 +137,13 @@ class APIClient {
     });
   }
 
-  // 手动刷新
-  async refreshData(): Promise<IAPIResponse> {
-    return this.request('/refresh', {
+  // 手动刷新（指定minutes时返回该时间窗口内的数据，不修改已保存的显示时间范围）
+  async refreshData(scope: RefreshScope = 'all', minutes?: number): Promise<IAPIResponse<IRefreshResult>> {
+    const params = new URLSearchParams({ scope });
+    if (minutes) {
+      params.set('minutes', String(minutes));
+    }
+    return this.request(`/refresh?${params}`, {
       method: 'POST',
     });
   }
@@ -95,9 +156,10 @@ class APIClient {
   }
 
   // 登录
-  async login(key: string): Promise<IAPIResponse<ILoginResponse>> {
+  async login(key: string, rememberMe: boolean = true): Promise<IAPIResponse<ILoginResponse>> {
     return this.request<ILoginResponse>('/auth/login', {
       method: 'POST',
+      body: JSON.stringify({ rememberMe }),
       headers: {
         'Authorization': `Bearer ${key}`,
       },
@@ -114,6 +176,11 @@ class APIClient {
     return this.request<IAuthStatusResponse>('/auth/status');
   }
 
+  // 获取登录会话列表
+  async getSessions(): Promise<IAPIResponse<ISessionInfo[]>> {
+    return this.request<ISessionInfo[]>('/admin/sessions');
+  }
+
   // 获取积分余额
   async getCreditBalance(): Promise<IAPIResponse<ICreditBalance>> {
     return this.request<ICreditBalance>('/balance');
@@ -131,6 +198,51 @@ class APIClient {
     return this.request<string>('/history');
   }
 
+  // 获取今日与之前几周同一星期几的按小时积分对比
+  async getSameDayComparison(weeks?: number): Promise<IAPIResponse<ISameDayComparison>> {
+    return this.request<ISameDayComparison>(weeks ? `/history/same-day?weeks=${weeks}` : '/history/same-day');
+  }
+
+  // 获取建议的每日重置时间
+  async getResetSuggestion(): Promise<IAPIResponse<IResetSuggestion>> {
+    return this.request<IResetSuggestion>('/reset/suggestion');
+  }
+
+  // 获取实际余额与预期余额曲线
+  async getBalanceCurve(hours?: number): Promise<IAPIResponse<IBalanceCurve>> {
+    return this.request<IBalanceCurve>(hours ? `/balance/curve?hours=${hours}` : '/balance/curve');
+  }
+
+  // 获取重置效果报告
+  async getResetReport(days?: number): Promise<IAPIResponse<IResetReport>> {
+    return this.request<IResetReport>(days ? `/reset/report?days=${days}` : '/reset/report');
+  }
+
+  // 获取上游可用性（探测记录和不可用时段）
+  async getUpstreamAvailability(hours?: number): Promise<IAPIResponse<IUpstreamAvailability>> {
+    return this.request<IUpstreamAvailability>(hours ? `/upstream/availability?hours=${hours}` : '/upstream/availability');
+  }
+
+  // 获取最近指定天数内的图表标注
+  async getAnnotations(days?: number): Promise<IAPIResponse<IAnnotation[]>> {
+    return this.request<IAnnotation[]>(days ? `/annotations?days=${days}` : '/annotations');
+  }
+
+  // 新建图表标注
+  async createAnnotation(annotation: IAnnotationRequest): Promise<IAPIResponse<IAnnotation>> {
+    return this.request<IAnnotation>('/annotations', {
+      method: 'POST',
+      body: JSON.stringify(annotation),
+    });
+  }
+
+  // 删除图表标注
+  async deleteAnnotation(id: string): Promise<IAPIResponse> {
+    return this.request(`/annotations/${encodeURIComponent(id)}`, {
+      method: 'DELETE',
+    });
+  }
+
 
   // 创建SSE连接
   createSSEConnection(
@@ -142,9 +254,17 @@ class APIClient {
     onMonitoringStatusUpdate?: (status: IMonitoringStatus) => void,
     onAuthExpired?: () => void,
     onDailyUsageUpdate?: (dailyUsage: IDailyUsage[]) => void,
+    onHealthUpdate?: (health: IHealthState) => void,
+    onConfigUpdate?: (config: IUserConfig) => void,
+    onTick?: (tick: IJobTick) => void,
+    onAnnotationsUpdate?: (annotations: IAnnotation[]) => void,
     timeRange: number = 60
   ): EventSource {
-    const eventSource = new EventSource(`${API_BASE}/usage/stream?minutes=${timeRange}`);
+    // 增量模式：usage为全部记录，usage_delta只包含新增记录，在本地合并
+    const eventSource = new EventSource(`${API_BASE}/usage/stream?minutes=${timeRange}&delta=true`);
+    let usageRecords: IUsageData[] = [];
+    // 连接时推送当前时间范围内的标注，之后按annotation事件在本地增删
+    let annotations: IAnnotation[] = [];
     
     eventSource.addEventListener('connected', () => {
       // 连接确认事件
@@ -152,13 +272,26 @@ class APIClient {
 
     eventSource.addEventListener('usage', (event) => {
       try {
-        const data = JSON.parse(event.data);
-        onMessage(data);
+        usageRecords = JSON.parse(event.data);
+        onMessage(usageRecords);
       } catch (error) {
         console.error('解析SSE数据失败:', error, event.data);
       }
     });
 
+    eventSource.addEventListener('usage_delta', (event) => {
+      try {
+        const delta: IUsageDelta = JSON.parse(event.data);
+        const cutoff = new Date(delta.cutoff).getTime();
+        usageRecords = [...delta.records, ...usageRecords]
+          .filter((record) => new Date(record.createdAt).getTime() > cutoff)
+          .sort((a, b) => new Date(b.createdAt).getTime() - new Date(a.createdAt).getTime());
+        onMessage(usageRecords);
+      } catch (error) {
+        console.error('解析SSE增量数据失败:', error, event.data);
+      }
+    });
+
     eventSource.addEventListener('balance', (event) => {
       try {
         const balance = JSON.parse(event.data);
@@ -188,6 +321,19 @@ class APIClient {
       }
     });
 
+    eventSource.addEventListener('notification', (event) => {
+      try {
+        const notification = JSON.parse(event.data);
+        console.info('收到通知:', notification);
+        // 复用错误回调，由Dashboard组件显示toast
+        if (onError && typeof onError === 'function') {
+          onError(new CustomEvent('api-notification', { detail: notification }) as Event);
+        }
+      } catch (error) {
+        console.error('解析通知数据失败:', error, event.data);
+      }
+    });
+
     eventSource.addEventListener('reset_status', (event) => {
       try {
         const resetData = JSON.parse(event.data);
@@ -224,6 +370,67 @@ class APIClient {
       }
     });
 
+    eventSource.addEventListener('health', (event) => {
+      try {
+        const healthData = JSON.parse(event.data);
+        console.debug('收到健康状态更新:', healthData);
+        if (onHealthUpdate) {
+          onHealthUpdate(healthData);
+        }
+      } catch (error) {
+        console.error('解析健康状态数据失败:', error, event.data);
+      }
+    });
+
+    eventSource.addEventListener('config', (event) => {
+      try {
+        const configData = JSON.parse(event.data);
+        console.debug('收到配置变更:', configData);
+        if (onConfigUpdate) {
+          onConfigUpdate(configData);
+        }
+      } catch (error) {
+        console.error('解析配置数据失败:', error, event.data);
+      }
+    });
+
+    eventSource.addEventListener('tick', (event) => {
+      try {
+        const tickData = JSON.parse(event.data);
+        if (onTick) {
+          onTick(tickData);
+        }
+      } catch (error) {
+        console.error('解析任务执行事件失败:', error, event.data);
+      }
+    });
+
+    eventSource.addEventListener('annotations', (event) => {
+      try {
+        annotations = JSON.parse(event.data) || [];
+        if (onAnnotationsUpdate) {
+          onAnnotationsUpdate(annotations);
+        }
+      } catch (error) {
+        console.error('解析标注数据失败:', error, event.data);
+      }
+    });
+
+    eventSource.addEventListener('annotation', (event) => {
+      try {
+        const annotationEvent: IAnnotationEvent = JSON.parse(event.data);
+        const others = annotations.filter(a => a.id !== annotationEvent.annotation.id);
+        annotations = annotationEvent.action === 'created'
+          ? [...others, annotationEvent.annotation].sort((a, b) => new Date(a.time).getTime() - new Date(b.time).getTime())
+          : others;
+        if (onAnnotationsUpdate) {
+          onAnnotationsUpdate(annotations);
+        }
+      } catch (error) {
+        console.error('解析标注变化失败:', error, event.data);
+      }
+    });
+
     eventSource.addEventListener('auth_expired', (event) => {
       try {
         const authData = JSON.parse(event.data);
@@ -236,6 +443,28 @@ class APIClient {
       }
     });
 
+    eventSource.addEventListener('server_shutdown', (event) => {
+      try {
+        const shutdownData = JSON.parse(event.data);
+        console.warn('服务正在关闭:', shutdownData.message);
+        // 主动关闭连接，交由外部按固定间隔重连，避免浏览器立即重试
+        eventSource.close();
+        if (onError && typeof onError === 'function') {
+          onError(new CustomEvent('server-shutdown', { detail: shutdownData.message }) as Event);
+        }
+      } catch (error) {
+        console.error('解析服务关闭数据失败:', error, event.data);
+      }
+    });
+
+    eventSource.addEventListener('server_handoff', () => {
+      // 平滑重启：新进程已接管监听套接字，关闭连接并由外部立即重连
+      eventSource.close();
+      if (onError && typeof onError === 'function') {
+        onError(new CustomEvent('server-handoff') as Event);
+      }
+    });
+
     eventSource.onerror = (error) => {
       console.error('SSE连接错误:', error);
       if (onError) {
@@ -5,6 +5,7 @@ import toast from 'react-hot-toast';
 
 export function LoginPage() {
   const [key, setKey] = useState('');
+  const [rememberMe, setRememberMe] = useState(true);
   const [isLoggingIn, setIsLoggingIn] = useState(false);
   const [error, setError] = useState('');
   const [showTooltip, setShowTooltip] = useState(false);
@@ -30,7 +31,7 @@ export function LoginPage() {
     setError('');
 
     try {
-      const success = await login(key.trim());
+      const success = await login(key.trim(), rememberMe);
       if (!success) {
         setError('访问密钥错误');
         toast.error('访问密钥错误');
@@ -117,6 +118,18 @@ export function LoginPage() {
               </div>
             </div>
 
+            {/* 记住我 */}
+            <label className="flex items-center space-x-2 mt-4 text-sm text-white/80 cursor-pointer select-none">
+              <input
+                type="checkbox"
+                checked={rememberMe}
+                onChange={(e) => setRememberMe(e.target.checked)}
+                disabled={isLoggingIn}
+                className="h-4 w-4 rounded border-white/30 bg-white/15 accent-blue-400"
+              />
+              <span>记住我（未勾选时登录状态仅短期有效）</span>
+            </label>
+
             {/* 错误提示 */}
             {error && (
               <div className="flex items-center space-x-2 text-red-300 text-sm bg-red-500/20 border border-red-400/30 rounded-lg p-3 mt-4">
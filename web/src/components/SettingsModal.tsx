@@ -11,6 +11,7 @@ interface SettingsModalProps {
   isMonitoring?: boolean;
   monitoringStatus?: IMonitoringStatus | null;
   onMonitoringChange?: (isMonitoring: boolean) => void;
+  pushedConfig?: IUserConfig | null; // 服务端推送的最新配置
 }
 
 // 标签页定义
@@ -29,7 +30,7 @@ const TABS: TabItem[] = [
   { id: 'reset', label: '自动重置', icon: RotateCcw },
 ];
 
-export function SettingsModal({ isOpen, onClose, onConfigUpdate, isMonitoring = false, monitoringStatus, onMonitoringChange }: SettingsModalProps) {
+export function SettingsModal({ isOpen, onClose, onConfigUpdate, isMonitoring = false, monitoringStatus, onMonitoringChange, pushedConfig }: SettingsModalProps) {
   const { logout } = useAuth();
   const [activeTab, setActiveTab] = useState<TabType>('status');
   const [config, setConfig] = useState<IUserConfig>({
@@ -53,13 +54,17 @@ export function SettingsModal({ isOpen, onClose, onConfigUpdate, isMonitoring =
       threshold: 0,
       thresholdTimeEnabled: false,
       thresholdStartTime: '',
-      thresholdEndTime: ''
+      thresholdEndTime: '',
+      thresholdAction: 'reset',
+      thresholdWebhookUrl: '',
+      strategy: ''
     },
     version: {
       version: 'Loading...',
       gitCommit: 'Loading...',
       buildTime: '',
-      goVersion: ''
+      goVersion: '',
+      updateAvailable: false
     },
     plan: ''
   });
@@ -90,6 +95,18 @@ export function SettingsModal({ isOpen, onClose, onConfigUpdate, isMonitoring =
     }
   }, [isOpen, isMonitoring, config?.enabled, config?.autoSchedule?.enabled]);
 
+  // 服务端推送配置变更时立即刷新设置面板（保存中不覆盖）
+  useEffect(() => {
+    if (isOpen && pushedConfig && !saving) {
+      setConfig(prev => ({
+        ...pushedConfig,
+        version: prev.version,
+        plan: prev.plan,
+        enabled: pushedConfig.autoSchedule.enabled ? true : pushedConfig.enabled
+      }));
+    }
+  }, [pushedConfig]);
+
   const loadConfig = async () => {
     try {
       const response = await apiClient.getConfig();
@@ -485,7 +502,7 @@ function BasicConfigTab({
         <div className="flex gap-2">
           <input
             type="password"
-            placeholder={config.cookie ? "Cookie已设置，留空不修改" : "请输入 ACM 网站的Cookie"}
+            placeholder={config.cookie ? "Cookie已设置，留空不修改" : "请输入 ACM 网站的Cookie（请求头或浏览器导出的JSON）"}
             className="flex-1 px-3 py-2.5 border border-gray-300 rounded-lg text-sm focus:outline-none focus:ring-2 focus:ring-blue-500/20 focus:border-blue-500 transition-all duration-200"
             value={cookieInput}
             onChange={(e) => setCookieInput(e.target.value)}
@@ -1284,6 +1301,18 @@ function StatusInfoTab({
             <span className="text-sm text-gray-700">Git提交</span>
             <span className="text-sm text-gray-900 font-mono">{config.version?.gitCommit || 'Unknown'}</span>
           </div>
+
+          {config.version?.updateAvailable && (
+            <a
+              href={config.version.releaseUrl}
+              target="_blank"
+              rel="noopener noreferrer"
+              className="flex items-center justify-between p-3 bg-blue-50 rounded-lg hover:bg-blue-100 transition-colors"
+            >
+              <span className="text-sm text-blue-700">发现新版本</span>
+              <span className="text-sm text-blue-900 font-mono">{config.version.latestVersion}</span>
+            </a>
+          )}
         </div>
       </div>
 
@@ -30,13 +30,17 @@ export function SettingsPanel({ className = '', onConfigUpdate }: SettingsPanelP
       threshold: 0,
       thresholdTimeEnabled: false,
       thresholdStartTime: '',
-      thresholdEndTime: ''
+      thresholdEndTime: '',
+      thresholdAction: 'reset',
+      thresholdWebhookUrl: '',
+      strategy: ''
     },
     version: {
       version: 'Loading...',
       gitCommit: 'Loading...',
       buildTime: '',
-      goVersion: ''
+      goVersion: '',
+      updateAvailable: false
     },
     plan: ''
   });
@@ -144,7 +148,7 @@ export function SettingsPanel({ className = '', onConfigUpdate }: SettingsPanelP
           <div className="flex gap-2">
             <input
               type="password"
-              placeholder={config.cookie ? "Cookie已设置，留空不修改" : "请输入Claude网站的Cookie"}
+              placeholder={config.cookie ? "Cookie已设置，留空不修改" : "请输入Claude网站的Cookie（请求头或浏览器导出的JSON）"}
               className="flex-1 px-3 py-2.5 border border-gray-300 rounded-lg text-sm focus:outline-none focus:ring-2 focus:ring-blue-500/20 focus:border-blue-500 transition-all duration-200 hover:border-gray-400"
               value={cookieInput}
               onChange={(e) => setCookieInput(e.target.value)}
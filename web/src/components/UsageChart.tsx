@@ -1,14 +1,18 @@
 import { useMemo } from 'react';
 import ReactECharts from 'echarts-for-react';
 import { TrendingUp } from 'lucide-react';
-import type { IUsageData } from '../types';
+import type { IUsageData, IAnnotation } from '../types';
 
 interface UsageChartProps {
   data: IUsageData[];
+  annotations?: IAnnotation[];
   className?: string;
 }
 
-export function UsageChart({ data, className = '' }: UsageChartProps) {
+// 未指定颜色时标注线的默认颜色
+const DEFAULT_ANNOTATION_COLOR = '#fbbf24';
+
+export function UsageChart({ data, annotations = [], className = '' }: UsageChartProps) {
   const chartData = useMemo(() => {
     if (!data || data.length === 0) {
       return {
@@ -56,6 +60,44 @@ export function UsageChart({ data, className = '' }: UsageChartProps) {
     return { times, series, dataMap };
   }, [data]);
 
+  // 图表标注：x轴为类目轴，标注线画在时间最接近的数据点上，超出数据时间范围的标注不显示
+  const annotationLines = useMemo(() => {
+    const { times } = chartData;
+    if (times.length === 0) return [];
+
+    const timestamps = times.map(time => new Date(time).getTime());
+    const first = timestamps[0];
+    const last = timestamps[timestamps.length - 1];
+
+    return annotations.flatMap(annotation => {
+      const at = new Date(annotation.time).getTime();
+      if (isNaN(at) || at < first || at > last) return [];
+
+      let nearest = 0;
+      timestamps.forEach((timestamp, index) => {
+        if (Math.abs(timestamp - at) < Math.abs(timestamps[nearest] - at)) {
+          nearest = index;
+        }
+      });
+
+      const color = annotation.color || DEFAULT_ANNOTATION_COLOR;
+      return [{
+        xAxis: times[nearest],
+        name: annotation.text,
+        lineStyle: { color, type: 'dashed', width: 1.5 },
+        label: {
+          formatter: annotation.text,
+          position: 'insideEndTop',
+          color,
+          fontSize: 11,
+          backgroundColor: 'rgba(0, 0, 0, 0.6)',
+          borderRadius: 4,
+          padding: [2, 6]
+        }
+      }];
+    });
+  }, [chartData, annotations]);
+
   const option = useMemo(() => ({
     backgroundColor: 'transparent',
     title: {
@@ -224,6 +266,13 @@ export function UsageChart({ data, className = '' }: UsageChartProps) {
         name: model,
         type: 'line',
         data: chartData.series[model],
+        // 标注线只挂在第一个系列上，避免重复绘制
+        markLine: index === 0 && annotationLines.length > 0 ? {
+          silent: true,
+          symbol: 'none',
+          animation: false,
+          data: annotationLines
+        } : undefined,
         smooth: true,
         symbol: 'circle',
         symbolSize: 8,
@@ -271,7 +320,7 @@ export function UsageChart({ data, className = '' }: UsageChartProps) {
     }),
     animation: true,
     animationDuration: 1000
-  }), [chartData]);
+  }), [chartData, annotationLines]);
 
   if (!data || data.length === 0) {
     return (
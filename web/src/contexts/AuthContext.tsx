@@ -4,7 +4,7 @@ import { apiClient } from '../api/client';
 interface AuthContextType {
   isAuthenticated: boolean;
   isLoading: boolean;
-  login: (key: string) => Promise<boolean>;
+  login: (key: string, rememberMe?: boolean) => Promise<boolean>;
   logout: () => Promise<void>;
   checkAuthStatus: () => Promise<void>;
 }
@@ -37,9 +37,9 @@ export function AuthProvider({ children }: AuthProviderProps) {
   };
 
   // 登录
-  const login = async (key: string): Promise<boolean> => {
+  const login = async (key: string, rememberMe: boolean = true): Promise<boolean> => {
     try {
-      const response = await apiClient.login(key);
+      const response = await apiClient.login(key, rememberMe);
       if (response.code === 200) {
         setIsAuthenticated(true);
         return true;
@@ -3,7 +3,7 @@ import { UsageChart } from '../components/UsageChart';
 import { SettingsModal } from '../components/SettingsModal';
 import { DailyUsageModal } from '../components/DailyUsageModal';
 import { LoginPage } from '../components/LoginPage';
-import type { IUsageData, IUserConfig, IUserConfigRequest, ICreditBalance, IMonitoringStatus, IDailyUsage } from '../types';
+import type { IUsageData, IUserConfig, IUserConfigRequest, ICreditBalance, IMonitoringStatus, IDailyUsage, IHealthState, INotification, IJobTick, IAnnotation } from '../types';
 import { apiClient } from '../api/client';
 import { Settings, Wifi, WifiOff, RefreshCw, BarChart3, X, History } from 'lucide-react';
 import { useAuth } from '../hooks/useAuth';
@@ -25,6 +25,11 @@ export function Dashboard() {
   const [showConfirmDialog, setShowConfirmDialog] = useState(false);
   const [isAutoResetEnabled, setIsAutoResetEnabled] = useState(false);
   const [monitoringStatus, setMonitoringStatus] = useState<IMonitoringStatus | null>(null);
+  const [healthState, setHealthState] = useState<IHealthState | null>(null);
+  const [pushedConfig, setPushedConfig] = useState<IUserConfig | null>(null);
+  const [nextRefresh, setNextRefresh] = useState<Date | null>(null);
+  const [annotations, setAnnotations] = useState<IAnnotation[]>([]);
+  const [now, setNow] = useState(() => Date.now());
   const retryTimeoutRef = useRef<number | null>(null);
 
   // 处理认证过期
@@ -72,13 +77,36 @@ export function Dashboard() {
           toast.error(customEvent.detail);
           return; // API错误不需要重新连接
         }
+
+        // 通知消息
+        if (error.type === 'api-notification') {
+          const notification = (error as CustomEvent<INotification>).detail;
+          const icons: Record<string, string> = { maintenance: '🚧', upstream_slow: '🐢', relay_errors: '🚦', exhausted: '⛔', external_usage: '🕵️', schema_drift: '🧩' };
+          const icon = icons[notification.type] ?? '🆕';
+          toast(notification.message, { icon, duration: 8000 });
+          return;
+        }
+
+        // 平滑重启，新进程已在同一端口等待，保持连接状态并立即重连
+        if (error.type === 'server-handoff') {
+          retryTimeoutRef.current = setTimeout(() => {
+            connectSSE();
+          }, 100);
+          return;
+        }
+
+        // 服务重启，提示后按常规流程延迟重连
+        if (error.type === 'server-shutdown') {
+          const customEvent = error as CustomEvent;
+          toast(`${customEvent.detail}，稍后自动重连`, { icon: '🔄' });
+        }
         
         setIsConnected(false);
         
         // SSE断开时检查后端任务状态，如果任务停止则重置监控开关
         const checkTaskStatus = async () => {
           try {
-            const statusResponse = await fetch('/api/control/status');
+            const statusResponse = await fetch('/api/v1/control/status');
             const statusResult = await statusResponse.json();
             if (statusResult.data && !statusResult.data.running) {
               // 后端任务已停止，重置UI开关状态
@@ -103,7 +131,7 @@ export function Dashboard() {
         // 连接成功后检查后端任务状态，确保UI状态同步
         const syncTaskStatus = async () => {
           try {
-            const statusResponse = await fetch('/api/control/status');
+            const statusResponse = await fetch('/api/v1/control/status');
             const statusResult = await statusResponse.json();
             if (statusResult.data) {
               setIsMonitoring(statusResult.data.running);
@@ -132,6 +160,31 @@ export function Dashboard() {
         console.debug('收到每日积分统计数据:', dailyUsage);
         setDailyUsageData(dailyUsage);
       },
+      (health: IHealthState) => {
+        // 处理系统健康状态更新
+        setHealthState(health);
+      },
+      (pushed: IUserConfig) => {
+        // 处理其他设备或自动化任务修改的配置（推送内容不含版本和订阅等级信息）
+        setConfig(prev => prev ? {
+          ...pushed,
+          version: prev.version,
+          plan: prev.plan,
+          enabled: pushed.autoSchedule.enabled ? true : pushed.enabled
+        } : prev);
+        setIsAutoResetEnabled(pushed.autoReset?.enabled || false);
+        setPushedConfig(pushed);
+      },
+      (tick: IJobTick) => {
+        // 使用数据任务执行后更新下次刷新时间
+        if (tick.job === 'usage') {
+          setNextRefresh(tick.nextRun ? new Date(tick.nextRun) : null);
+        }
+      },
+      (updated: IAnnotation[]) => {
+        // 处理图表标注更新（包括其他设备或脚本新建、删除的标注）
+        setAnnotations(updated);
+      },
       timeRange
     );
 
@@ -139,6 +192,17 @@ export function Dashboard() {
   }, [config?.timeRange, handleAuthExpired]);
 
 
+  // 下次刷新倒计时（每秒更新）
+  useEffect(() => {
+    if (!nextRefresh || !isMonitoring) return;
+    const timer = setInterval(() => setNow(Date.now()), 1000);
+    return () => clearInterval(timer);
+  }, [nextRefresh, isMonitoring]);
+
+  const refreshCountdown = nextRefresh && isMonitoring
+    ? Math.max(0, Math.ceil((nextRefresh.getTime() - now) / 1000))
+    : null;
+
   // 加载初始配置和任务状态（仅在已认证时执行）
   useEffect(() => {
     if (!isAuthenticated) return;
@@ -162,7 +226,7 @@ export function Dashboard() {
         }
 
         // 加载任务运行状态
-        const statusResponse = await fetch('/api/control/status');
+        const statusResponse = await fetch('/api/v1/control/status');
         const statusResult = await statusResponse.json();
         if (statusResult.data) {
           setIsMonitoring(statusResult.data.running);
@@ -207,7 +271,7 @@ export function Dashboard() {
     
     // 检查实际的任务运行状态，确保状态同步
     try {
-      const statusResponse = await fetch('/api/control/status');
+      const statusResponse = await fetch('/api/v1/control/status');
       const statusResult = await statusResponse.json();
       if (statusResult.data) {
         setIsMonitoring(statusResult.data.running);
@@ -248,7 +312,7 @@ export function Dashboard() {
           }
           
           // 停止任务后检查实际状态
-          const statusResponse = await fetch('/api/control/status');
+          const statusResponse = await fetch('/api/v1/control/status');
           const statusResult = await statusResponse.json();
           if (statusResult.data) {
             // 如果实际状态与UI状态不一致，恢复UI状态
@@ -295,7 +359,7 @@ export function Dashboard() {
           }
           
           // 启动任务后检查实际状态
-          const statusResponse = await fetch('/api/control/status');
+          const statusResponse = await fetch('/api/v1/control/status');
           const statusResult = await statusResponse.json();
           if (statusResult.data) {
             // 如果实际状态与UI状态不一致，恢复UI状态
@@ -332,7 +396,7 @@ export function Dashboard() {
     } catch (error) {
       // 最终错误处理：重新加载实际状态
       try {
-        const statusResponse = await fetch('/api/control/status');
+        const statusResponse = await fetch('/api/v1/control/status');
         const statusResult = await statusResponse.json();
         if (statusResult.data) {
           setIsMonitoring(statusResult.data.running);
@@ -367,7 +431,7 @@ export function Dashboard() {
 
     try {
       // 使用统一刷新接口，一次请求同时刷新使用数据和积分余额
-      await fetch('/api/refresh', { method: 'POST' });
+      await fetch('/api/v1/refresh', { method: 'POST' });
       
       // 刷新后的数据会通过SSE自动推送，无需额外HTTP请求
       toast.success('数据刷新成功', { id: loadingToastId });
@@ -479,14 +543,33 @@ export function Dashboard() {
                 <WifiOff className="w-5 h-5 text-red-400" />
               )}
             </div>
+            {/* 系统健康状态指示灯 */}
+            {healthState && (
+              <div
+                className={`w-2.5 h-2.5 rounded-full ${
+                  healthState.state === 'ok'
+                    ? 'bg-green-400'
+                    : healthState.state === 'degraded'
+                      ? 'bg-yellow-400'
+                      : 'bg-red-500'
+                }`}
+                title={healthState.state === 'ok' ? '系统运行正常' : healthState.reasons.join('\n')}
+              />
+            )}
           </div>
+          {monitoringStatus?.maintenance?.enabled && (
+            <p className="text-sm text-yellow-300 mt-1">
+              🚧 维护模式中，上游请求已暂停
+              {monitoringStatus.maintenance.expiresAt && `，将于 ${new Date(monitoringStatus.maintenance.expiresAt).toLocaleTimeString()} 自动结束`}
+            </p>
+          )}
           <p className="text-sm text-white/70 mt-1">
             {!config?.cookie 
               ? '请先配置Cookie' 
               : !isConnected 
                 ? '连接中...' 
                 : lastUpdate 
-                  ? `最后更新: ${lastUpdate.toLocaleTimeString()}` 
+                  ? `最后更新: ${lastUpdate.toLocaleTimeString()}${refreshCountdown !== null ? `，${refreshCountdown}秒后刷新` : ''}` 
                   : '请启用监控或手动刷新获取数据'
             }
           </p>
@@ -520,6 +603,11 @@ export function Dashboard() {
               <div className="text-lg font-mono font-bold text-yellow-400 min-w-[4ch] text-center">
                 {creditBalance.remaining.toLocaleString()}
               </div>
+              {creditBalance.usedToday !== undefined && (
+                <div className="text-[10px] text-white/60 text-center">
+                  今日已用 {creditBalance.usedToday.toLocaleString()}
+                </div>
+              )}
             </button>
           )}
 
@@ -646,7 +734,7 @@ export function Dashboard() {
               </div>
             </div>
           ) : (
-            <UsageChart data={usageData} className="h-full" />
+            <UsageChart data={usageData} annotations={annotations} className="h-full" />
           )}
         </div>
       </div>
@@ -659,6 +747,7 @@ export function Dashboard() {
         isMonitoring={isMonitoring}
         monitoringStatus={monitoringStatus}
         onMonitoringChange={setIsMonitoring}
+        pushedConfig={pushedConfig}
       />
 
       {/* 每日积分统计弹窗 */}
@@ -4,6 +4,65 @@ export interface IUsageData {
   creditsUsed: number;
   createdAt: string;
   model: string;
+  statusCode?: number;             // 中转站记录的请求状态码
+  group?: string;                  // 所属模型分组（未归入分组时省略）
+}
+
+// SSE增量使用数据（usage_delta事件）
+export interface IUsageDelta {
+  records: IUsageData[];           // 新增的使用记录（最新在前）
+  cutoff: string;                  // 时间范围起点，早于该时间的已有记录应移除
+}
+
+// 模型统计分组
+export interface IModelGroup {
+  name: string;                    // 分组名称
+  models: string[];                // 归入该分组的模型名，以*结尾时按前缀匹配
+}
+
+// 账户标签
+export interface IAccountLabel {
+  name: string;                    // 显示名称
+  color: string;                   // 标识颜色 "#RRGGBB"
+  notes: string;                   // 备注
+}
+
+// 积分耗尽告警配置
+export interface IExhaustionConfig {
+  enabled: boolean;                // 是否启用
+  floor: number;                   // 耗尽下限，余额小于等于该值视为耗尽
+  webhookUrl?: string;             // 耗尽时调用的Webhook地址（可选）
+}
+
+// 外部使用告警配置
+export interface IExternalUsageConfig {
+  enabled: boolean;                // 是否启用
+  threshold: number;               // 无法解释的余额下降达到该值时告警，0表示默认值(100)
+  webhookUrl?: string;             // 告警时调用的Webhook地址（可选）
+}
+
+// 新IP登录告警配置
+export interface ILoginAlertConfig {
+  enabled: boolean;                // 是否启用
+  webhookUrl?: string;             // 告警时调用的Webhook地址（可选）
+}
+
+// 上游HTTP连接池配置
+export interface IHTTPTransportConfig {
+  maxIdleConns: number;            // 保留的空闲连接数，0表示默认值(2)
+  idleConnTimeout: number;         // 空闲连接保留秒数，0表示默认值(90)
+  disableKeepAlives: boolean;      // 每次请求使用新连接
+  disableHTTP2: boolean;           // 只使用HTTP/1.1
+  hosts?: Record<string, string[]>; // 指定主机名对应的IP（按顺序尝试）
+  dnsServer: string;               // 自定义DNS服务器："IP[:端口]"或DoH JSON接口地址，为空时使用系统解析
+}
+
+// 事件Hook配置
+export interface IHookConfig {
+  event: 'balance_low' | 'reset_executed' | 'cookie_invalid' | 'balance_exhausted' | 'external_usage' | 'login_new_ip'; // 触发事件
+  path: string;                    // 程序路径（相对Hook目录）
+  args?: string[];                 // 命令行参数
+  timeout: number;                 // 超时时间(秒)，0表示默认值
 }
 
 // 自动调度配置
@@ -24,6 +83,45 @@ export interface IAutoResetConfig {
   thresholdTimeEnabled: boolean; // 阈值时间范围是否启用
   thresholdStartTime: string;    // 阈值检查开始时间 "HH:MM"
   thresholdEndTime: string;      // 阈值检查结束时间 "HH:MM"
+  thresholdAction: string;       // 低于阈值时的动作：reset、notify、stop、webhook
+  thresholdWebhookUrl: string;   // 动作为webhook时调用的地址
+  thresholdRearm: boolean;       // 已重置或已执行动作后，余额回升到阈值以上时重新启用阈值检查
+  strategy: string;              // 自定义重置策略表达式，设置后替代阈值判断
+  recoveryPerHour: number;       // 每小时恢复的积分，0表示根据积分余额历史估算
+  recoveryCap: number;           // 积分上限，0表示取积分余额历史中的最大值
+  adoptSuggestion: boolean;      // 每个重置周期开始时自动采用更优的建议重置时间
+}
+
+// 每日重置周期配置
+export interface IResetClockConfig {
+  time: string;     // "今日已使用重置"标记的清除时刻 "HH:MM"
+  timezone: string; // IANA时区名，为空表示服务器本地时区
+}
+
+// Cookie保活配置
+export interface IKeepAliveConfig {
+  enabled: boolean;       // 是否启用Cookie保活
+  intervalHours: number;  // 保活请求间隔(小时)
+}
+
+// 自适应获取间隔配置
+export interface IAdaptiveIntervalConfig {
+  enabled: boolean;        // 是否启用（启用后不再使用固定的interval）
+  activeInterval: number;  // 活跃时的获取间隔(秒)
+  idleInterval: number;    // 空闲时的获取间隔(秒)
+  idleMinutes: number;     // 最近一条使用记录距今超过该时长(分钟)视为空闲
+}
+
+// 数据保留策略
+export interface IRetentionConfig {
+  usageHours: number;         // 原始使用记录保留时长(小时)
+  dailyUsageDays: number;     // 每日积分统计保留天数
+  balanceHistoryDays: number; // 积分余额历史保留天数
+  aggregateDays: number;      // 降采样聚合数据保留天数
+  archiveEnabled: boolean;    // 是否按月归档原始记录
+  archiveDailyUsage?: boolean;     // 每日积分统计和积分重置记录到期后写入归档
+  archiveBalanceHistory?: boolean; // 积分余额历史到期后写入归档
+  archiveAggregates?: boolean;     // 降采样数据到期后写入归档
 }
 
 // 版本信息
@@ -32,6 +130,9 @@ export interface IVersionInfo {
   gitCommit: string; // Git提交短哈希
   buildTime: string; // 构建时间
   goVersion: string; // Go版本
+  updateAvailable: boolean; // 是否有新版本
+  latestVersion?: string;   // 最新发布版本
+  releaseUrl?: string;      // 最新发布页面地址
 }
 
 // 用户配置（API响应）
@@ -44,8 +145,21 @@ export interface IUserConfig {
   dailyUsageEnabled: boolean;       // 是否启用每日积分使用量统计
   autoSchedule: IAutoScheduleConfig; // 自动调度配置
   autoReset: IAutoResetConfig;       // 自动重置配置
+  resetClock: IResetClockConfig;     // 每日重置周期配置
+  keepAlive: IKeepAliveConfig;       // Cookie保活配置
+  adaptiveInterval: IAdaptiveIntervalConfig; // 自适应获取间隔配置
+  retention: IRetentionConfig;       // 数据保留策略
+  modelAliases: Record<string, string> | null; // 模型别名映射（原始模型名 → 统一名称）
+  modelGroups: IModelGroup[] | null; // 模型统计分组
+  hooks: IHookConfig[] | null;      // 事件Hook
+  account: IAccountLabel;           // 账户标签
+  exhaustion: IExhaustionConfig;    // 积分耗尽告警
+  externalUsage: IExternalUsageConfig; // 外部使用告警
+  loginAlert: ILoginAlertConfig;    // 新IP登录告警
+  httpTransport: IHTTPTransportConfig; // 上游HTTP连接池
   version: IVersionInfo;            // 版本信息
   plan: string;                     // 订阅等级
+  warnings?: string[];              // 配置提示（如阈值重置依赖的监控未运行）
 }
 
 // 用户配置（API请求）
@@ -57,6 +171,18 @@ export interface IUserConfigRequest {
   dailyUsageEnabled?: boolean;       // 是否启用每日积分使用量统计（可选）
   autoSchedule?: IAutoScheduleConfig; // 自动调度配置（可选）
   autoReset?: IAutoResetConfig;       // 自动重置配置（可选）
+  resetClock?: IResetClockConfig;     // 每日重置周期配置（可选）
+  keepAlive?: IKeepAliveConfig;       // Cookie保活配置（可选）
+  adaptiveInterval?: IAdaptiveIntervalConfig; // 自适应获取间隔配置（可选）
+  retention?: IRetentionConfig;       // 数据保留策略（可选）
+  modelAliases?: Record<string, string>; // 模型别名映射（可选，传空对象表示清空）
+  modelGroups?: IModelGroup[];       // 模型统计分组（可选，传空数组表示清空）
+  hooks?: IHookConfig[];             // 事件Hook（可选，传空数组表示清空）
+  account?: IAccountLabel;           // 账户标签（可选）
+  exhaustion?: IExhaustionConfig;    // 积分耗尽告警（可选）
+  externalUsage?: IExternalUsageConfig; // 外部使用告警（可选）
+  loginAlert?: ILoginAlertConfig;    // 新IP登录告警（可选）
+  httpTransport?: IHTTPTransportConfig; // 上游HTTP连接池（可选）
 }
 
 // API响应格式
@@ -71,8 +197,27 @@ export interface IErrorResponse {
   code: number;
   message: string;
   error?: string;
+  errorCode?: ErrorCode; // 机器可读的错误码
+  errors?: IFieldError[]; // 字段级验证错误
+}
+
+// 字段级验证错误
+export interface IFieldError {
+  field: string;   // 字段路径，如 autoReset.resetTime
+  message: string;
 }
 
+// 机器可读的错误码（接口错误响应的errorCode和SSE错误事件的code）
+export type ErrorCode =
+  | 'COOKIE_INVALID'
+  | 'COOKIE_MISSING'
+  | 'UPSTREAM_TIMEOUT'
+  | 'RATE_LIMITED'
+  | 'RESET_LIMIT_REACHED'
+  | 'API_REQUEST_ERROR'
+  | 'DATA_PARSING_ERROR'
+  | 'VALIDATION_FAILED';
+
 // SSE事件类型
 export interface ISSEEvent {
   type: 'usage' | 'config' | 'status';
@@ -92,6 +237,9 @@ export interface ICreditBalance {
   remaining: number;
   plan: string;       // 订阅等级
   updatedAt: string;
+  sincePrevious?: number | null; // 与上一次读数相比的变化量
+  sinceMidnight?: number | null; // 与今日零点相比的变化量
+  usedToday?: number;            // 今日已使用积分（实时累计）
 }
 
 // 监控状态信息（SSE推送）
@@ -100,18 +248,243 @@ export interface IMonitoringStatus {
   isMonitoring: boolean;          // 当前监控是否运行
   autoScheduleEnabled: boolean;   // 自动调度是否启用
   autoScheduleActive: boolean;    // 当前是否在自动调度时间范围内
+  maintenance?: IMaintenanceStatus; // 维护模式状态
+  account?: IAccountLabel;        // 账户标签
   timestamp: string;
 }
 
+// 维护模式状态
+export interface IMaintenanceStatus {
+  enabled: boolean;    // 是否处于维护模式
+  reason?: string;     // 维护原因
+  startedAt?: string;  // 开始时间
+  expiresAt?: string;  // 自动结束时间
+}
+
 // 每日积分使用统计
 export interface IDailyUsage {
   date: string;                    // 日期 (YYYY-MM-DD)
   totalCredits: number;            // 当日总积分使用量
   modelCredits: { [key: string]: number }; // 按模型分组的积分使用量
+  groupCredits?: { [key: string]: number }; // 按自定义模型分组汇总的积分使用量
+  hourlyModelCredits?: { [hour: string]: { [model: string]: number } }; // 按小时(00-23)和模型分组的积分使用量
+  resets?: number; // 当日成功重置积分的次数
   lastUpdated: string;             // 最后更新时间
 }
 
 // 每日积分统计响应
 export interface IDailyUsageResponse {
   data: IDailyUsage[];
-}
\ No newline at end of file
+}
+
+// 通知消息（SSE notification 事件）
+export interface INotification {
+  type: string;       // 通知类型
+  title: string;      // 标题
+  message: string;    // 内容
+  url?: string;       // 相关链接
+  timestamp: string;  // 通知时间
+}
+
+// 系统健康状态（SSE health 事件）
+export interface IHealthState {
+  state: 'ok' | 'degraded' | 'error'; // 整体状态
+  reasons: string[];                  // 非ok状态的原因
+  since: string;                      // 进入当前状态的时间
+  updatedAt: string;                  // 最后评估时间
+}
+
+// 配置字段变更
+export interface IConfigChange {
+  field: string;  // 字段路径，如 autoReset.threshold
+  old: unknown;   // 变更前的值（新增字段为null）
+  new: unknown;   // 变更后的值（删除字段为null）
+}
+
+// 配置审计记录（GET /api/v1/config/audit）
+export interface IConfigAuditEntry {
+  time: string;             // 保存时间
+  changes: IConfigChange[]; // 字段级变更
+}
+
+// 登录记录（GET /api/v1/admin/logins）
+export interface ILoginAuditEntry {
+  time: string;             // 登录时间
+  success: boolean;         // 是否登录成功
+  reason?: 'missing_key' | 'empty_key' | 'invalid_key' | 'session' | 'api_key' | 'basic_auth' | 'grpc'; // 失败原因
+  ip: string;               // 客户端IP
+  userAgent: string;        // 客户端User-Agent
+  newIp?: boolean;          // 是否为新IP登录
+}
+
+// 使用会话（GET /api/v1/sessions）
+export interface IUsageSession {
+  start: string;                           // 第一条记录的时间
+  end: string;                             // 最后一条记录的时间
+  durationSeconds: number;                 // 持续时长(秒)
+  credits: number;                         // 积分使用量
+  records: number;                         // 记录数
+  dominantModel: string;                   // 积分使用最多的模型
+  modelCredits: { [key: string]: number }; // 按模型分组的积分使用量
+}
+
+// 定时任务执行事件（SSE tick 事件）
+export interface IJobTick {
+  job: 'usage' | 'balance'; // 任务名称
+  ranAt: string;            // 本次执行完成时间
+  nextRun?: string;         // 下次计划执行时间（任务未调度时为空）
+  interval: number;         // 执行间隔(秒)
+  error?: string;           // 本次执行失败的原因
+}
+
+// 图表标注（SSE annotations 事件为当前时间范围内的标注列表）
+export interface IAnnotation {
+  id: string;        // 标注ID
+  time: string;      // 标注的时间点
+  text: string;      // 标注文字
+  color?: string;    // 颜色（#RGB或#RRGGBB），为空时使用默认颜色
+  createdAt: string; // 创建时间
+}
+
+// 创建图表标注的请求
+export interface IAnnotationRequest {
+  time?: string;  // 标注的时间点，为空表示当前时间
+  text: string;   // 标注文字（最多200个字符）
+  color?: string; // 颜色（#RGB或#RRGGBB）
+}
+
+// 标注变化事件（SSE annotation 事件）
+export interface IAnnotationEvent {
+  action: 'created' | 'deleted'; // 新建或删除
+  annotation: IAnnotation;       // 新建或被删除的标注
+}
+
+// 重置状态（SSE reset_status 事件）
+export interface IResetStatus {
+  type: 'reset_status';
+  resetUsed: boolean; // 当日重置是否已使用
+  timestamp: string;
+}
+
+// 当前状态快照（GET /api/v1/snapshot，与SSE连接建立时推送的事件一致，以事件名为键）
+export interface ISnapshot {
+  usage: IUsageData[];
+  balance: ICreditBalance | null;
+  reset_status?: IResetStatus;
+  monitoring_status: IMonitoringStatus;
+  daily_usage: IDailyUsage[];
+  health: IHealthState | null;
+}
+
+// 手动刷新范围
+export type RefreshScope = 'usage' | 'balance' | 'all';
+
+// 指定时间窗口的手动刷新结果（POST /api/v1/refresh?minutes=N）
+export interface IRefreshResult {
+  scope: RefreshScope;
+  minutes: number;
+  usage?: IUsageData[];              // scope为usage或all时返回
+  balance?: ICreditBalance | null;   // scope为balance或all时返回
+}
+
+// 某一天按小时的积分使用曲线
+export interface ISameDayCurve {
+  date: string;
+  total: number;
+  hourly: number[];      // 各小时的积分使用量，今日只到当前小时
+  cumulative: number[];  // 截至各小时结束的累计积分
+  hasData: boolean;      // 是否有按小时统计
+}
+
+// 今日与之前几周同一星期几的对比（GET /api/v1/history/same-day）
+export interface ISameDayComparison {
+  weekday: string;
+  hour: number;
+  today: ISameDayCurve;
+  previous: ISameDayCurve[];  // 由近到远
+  average: number[];          // 之前各周各小时的平均积分
+  averageToHour: number;      // 之前各周截至当前小时的平均累计积分
+}
+
+// 某一重置时间的模拟结果
+export interface IResetCandidate {
+  time: string;       // 重置时间 "HH:MM"
+  usable: number;     // 预计每日可满足的积分使用量
+  resetGain: number;  // 重置时补充的积分
+}
+
+// 建议的每日重置时间（GET /api/v1/reset/suggestion）
+export interface IResetSuggestion {
+  suggested: IResetCandidate;
+  current?: IResetCandidate;          // 当前定时重置时间的模拟结果
+  capacity: number;                   // 积分上限
+  recoveryPerHour: number;            // 每小时恢复的积分
+  recoverySource: 'config' | 'history' | 'none';
+  demand: number;                     // 平均每日积分使用量
+  days: number;                       // 参与计算的天数
+  candidates: IResetCandidate[];      // 各整点重置的模拟结果
+}
+
+// 某次余额读数时的实际余额与预期余额
+export interface IBalancePoint {
+  time: string;
+  actual: number;      // 实际余额
+  expected: number;    // 按恢复模型和本机使用记录推算的预期余额
+  divergence: number;  // 实际减预期，明显为负说明有本机以外的使用
+}
+
+// 实际余额与预期余额曲线（GET /api/v1/balance/curve）
+export interface IBalanceCurve {
+  recoveryPerHour: number;
+  capacity: number;
+  recoverySource: 'config' | 'history' | 'none';
+  points: IBalancePoint[];  // 按时间升序
+  maxShortfall: number;     // 实际余额低于预期余额的最大差值
+}
+
+// 单次重置的效果
+export interface IResetEffect {
+  time: string;                    // 重置时间
+  balanceBefore?: ICreditBalance;  // 重置前最近的余额读数
+  balanceAfter?: ICreditBalance;   // 重置后首次的余额读数
+  creditsGained?: number;          // 获得的积分，前后一小时内没有读数时为空
+  creditsConsumed: number;         // 重置后至当日结束实际使用的积分
+  approximate: boolean;            // 重置所在小时的使用量按整小时计入
+  partial: boolean;                // 重置发生在今日，使用量只统计到当前
+}
+
+// 重置效果报告（GET /api/v1/reset/report）
+export interface IResetReport {
+  days: number;
+  resets: IResetEffect[];  // 按重置时间倒序
+  creditsGained: number;   // 获得的积分合计
+  creditsConsumed: number; // 重置后使用的积分合计
+}
+
+// 一次上游可用性探测结果
+export interface IUpstreamProbe {
+  time: string;
+  up: boolean;          // 有响应且不是5xx
+  statusCode?: number;
+  latencyMs: number;
+  error?: string;       // 不可用原因
+}
+
+// 上游不可用时段（连续探测失败）
+export interface IUpstreamOutage {
+  start: string;        // 首次探测失败时间
+  end?: string;         // 恢复后首次探测成功时间，仍不可用时为空
+  minutes: number;
+  probes: number;       // 失败的探测次数
+  lastError: string;
+}
+
+// 上游可用性（GET /api/v1/upstream/availability）
+export interface IUpstreamAvailability {
+  hours: number;
+  total: number;
+  up: number;
+  availability: number | null;  // 可用率（百分比），没有探测记录时为空
+  outages: IUpstreamOutage[];   // 按时间升序
+  probes: IUpstreamProbe[];     // 按时间升序
+}